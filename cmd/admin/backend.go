@@ -0,0 +1,20 @@
+package main
+
+import (
+	"context"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+)
+
+// backend answers the CLI's commands, either straight from Redis and
+// PostgreSQL (direct) or through a server's admin API (http)
+type backend interface {
+	Top(limit int) ([]models.LeaderboardEntry, error)
+	UserRank(userID uint) (*models.UserSnapshot, error)
+	SyncStatus() (*models.SyncStatus, error)
+	StreamGroups() ([]models.StreamGroupInfo, error)
+	Rebuild(ctx context.Context, overwrite bool) (*service.RebuildResult, error)
+	Reconcile(ctx context.Context, opts service.ReconcileOptions) (*models.ReconcileReport, error)
+	Close()
+}
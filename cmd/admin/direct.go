@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// directBackend reads Redis and PostgreSQL with the server's configuration
+// (.env / environment). Connections are opened on first use, so read-only
+// Redis commands work while PostgreSQL is unreachable.
+type directBackend struct {
+	cfg *config.Config
+
+	redis *redis.Client
+	db    *gorm.DB
+}
+
+func newDirectBackend() (*directBackend, error) {
+	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return &directBackend{cfg: cfg}, nil
+}
+
+func (b *directBackend) redisClient() (*redis.Client, error) {
+	if b.redis == nil {
+		client, err := database.ConnectRedis(&b.cfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		b.redis = client
+	}
+	return b.redis, nil
+}
+
+func (b *directBackend) postgres() (*gorm.DB, error) {
+	if b.db == nil {
+		db, err := database.ConnectPostgres(&b.cfg.Database)
+		if err != nil {
+			return nil, err
+		}
+		b.db = db
+	}
+	return b.db, nil
+}
+
+// repos returns the user and leaderboard repositories, connecting both stores
+func (b *directBackend) repos() (repository.UserRepository, repository.LeaderboardRepository, error) {
+	redisClient, err := b.redisClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := b.postgres()
+	if err != nil {
+		return nil, nil, err
+	}
+	return repository.NewUserRepository(db), repository.NewLeaderboardRepository(redisClient), nil
+}
+
+func (b *directBackend) Top(limit int) ([]models.LeaderboardEntry, error) {
+	redisClient, err := b.redisClient()
+	if err != nil {
+		return nil, err
+	}
+	leaderboardRepo := repository.NewLeaderboardRepository(redisClient)
+
+	entries, err := leaderboardRepo.GetTopUsers(limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if user, err := leaderboardRepo.GetCachedUser(entries[i].UserID); err == nil {
+			entries[i].Username = user.Username
+		}
+	}
+	return entries, nil
+}
+
+func (b *directBackend) UserRank(userID uint) (*models.UserSnapshot, error) {
+	redisClient, err := b.redisClient()
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewLeaderboardRepository(redisClient).GetUserSnapshot(userID)
+}
+
+func (b *directBackend) monitor() (service.StreamMonitor, error) {
+	redisClient, err := b.redisClient()
+	if err != nil {
+		return nil, err
+	}
+	return service.NewStreamMonitor(redisClient, b.cfg.Streams.Consumer, 0), nil
+}
+
+func (b *directBackend) SyncStatus() (*models.SyncStatus, error) {
+	monitor, err := b.monitor()
+	if err != nil {
+		return nil, err
+	}
+	return monitor.SyncStatus()
+}
+
+func (b *directBackend) StreamGroups() ([]models.StreamGroupInfo, error) {
+	monitor, err := b.monitor()
+	if err != nil {
+		return nil, err
+	}
+	return monitor.Groups()
+}
+
+func (b *directBackend) Rebuild(ctx context.Context, overwrite bool) (*service.RebuildResult, error) {
+	userRepo, leaderboardRepo, err := b.repos()
+	if err != nil {
+		return nil, err
+	}
+	rebuildSvc := service.NewRebuildService(b.cfg.Rebuild, userRepo, leaderboardRepo, nil, nil)
+	return rebuildSvc.Rebuild(ctx, overwrite, &service.JobProgress{})
+}
+
+func (b *directBackend) Reconcile(ctx context.Context, opts service.ReconcileOptions) (*models.ReconcileReport, error) {
+	userRepo, leaderboardRepo, err := b.repos()
+	if err != nil {
+		return nil, err
+	}
+
+	// Not started: only consulted for the DB sync backlog
+	dbSync := service.NewDBSyncService(b.redis, b.db, b.cfg.Streams, b.cfg.DBSync, b.cfg.PGOutage, nil, "prod")
+	reconcileSvc := service.NewReconcileService(b.cfg.Reconcile, b.cfg.Jobs.Node, userRepo, leaderboardRepo, dbSync, nil)
+	return reconcileSvc.Reconcile(ctx, opts, "cli", &service.JobProgress{})
+}
+
+func (b *directBackend) Close() {
+	if b.redis != nil {
+		database.CloseRedis()
+	}
+	if b.db != nil {
+		database.CloseDB()
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/middleware"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+)
+
+// How often a submitted job is polled until it finishes
+const jobPollInterval = 2 * time.Second
+
+// httpBackend calls a server's API with an admin API key or token.
+// Rebuilds and reconciles run as jobs on that server; the CLI waits for them.
+type httpBackend struct {
+	baseURL string // e.g. https://leaderboard.example.com/api/v1
+	apiKey  string
+	token   string
+	client  *http.Client
+}
+
+func newHTTPBackend(server, apiKey, token string) *httpBackend {
+	return &httpBackend{
+		baseURL: strings.TrimRight(server, "/") + "/api/v1",
+		apiKey:  apiKey,
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// call sends a request and decodes the response's data field into out
+func (b *httpBackend) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.apiKey != "" {
+		req.Header.Set(middleware.APIKeyHeader, b.apiKey)
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Data  json.RawMessage `json:"data"`
+		Error string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		if envelope.Error == "" {
+			envelope.Error = resp.Status
+		}
+		return fmt.Errorf("%s %s: %s", method, path, envelope.Error)
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+func (b *httpBackend) Top(limit int) ([]models.LeaderboardEntry, error) {
+	var entries []models.LeaderboardEntry
+	err := b.call(context.Background(), http.MethodGet, "/leaderboard?limit="+strconv.Itoa(limit), nil, &entries)
+	return entries, err
+}
+
+func (b *httpBackend) UserRank(userID uint) (*models.UserSnapshot, error) {
+	var profile models.UserProfile
+	if err := b.call(context.Background(), http.MethodGet, fmt.Sprintf("/users/%d/profile", userID), nil, &profile); err != nil {
+		return nil, err
+	}
+	return &models.UserSnapshot{
+		UserID:   profile.UserID,
+		Username: profile.Username,
+		Rating:   profile.Rating,
+		Rank:     profile.GlobalRank,
+		Total:    profile.TotalPlayers,
+	}, nil
+}
+
+func (b *httpBackend) SyncStatus() (*models.SyncStatus, error) {
+	var status models.SyncStatus
+	if err := b.call(context.Background(), http.MethodGet, "/admin/sync/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (b *httpBackend) StreamGroups() ([]models.StreamGroupInfo, error) {
+	var groups []models.StreamGroupInfo
+	err := b.call(context.Background(), http.MethodGet, "/admin/streams", nil, &groups)
+	return groups, err
+}
+
+func (b *httpBackend) Rebuild(ctx context.Context, overwrite bool) (*service.RebuildResult, error) {
+	var result service.RebuildResult
+	if err := b.runJob(ctx, "/admin/rebuild", map[string]interface{}{"overwrite": overwrite}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (b *httpBackend) Reconcile(ctx context.Context, opts service.ReconcileOptions) (*models.ReconcileReport, error) {
+	var report models.ReconcileReport
+	if err := b.runJob(ctx, "/admin/reconcile", opts, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// runJob submits a job and waits for it, decoding its result into out.
+// Interrupting the wait leaves the job running on the server.
+func (b *httpBackend) runJob(ctx context.Context, path string, body, out interface{}) error {
+	var job models.Job
+	if err := b.call(ctx, http.MethodPost, path, body, &job); err != nil {
+		return err
+	}
+	logf("⏳ Job %s queued on %s", job.ID, job.Node)
+
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for !job.Finished() {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting, job %s keeps running (GET /api/admin/jobs/%s)", job.ID, job.ID)
+		case <-ticker.C:
+		}
+		if err := b.call(ctx, http.MethodGet, "/admin/jobs/"+job.ID, nil, &job); err != nil {
+			return err
+		}
+		if job.Total > 0 {
+			logf("   %s: %d/%d", job.Status, job.Processed, job.Total)
+		}
+	}
+
+	if job.Status != models.JobCompleted {
+		return fmt.Errorf("job %s %s: %s", job.ID, job.Status, job.Error)
+	}
+	if len(job.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(job.Result, out)
+}
+
+func (b *httpBackend) Close() {}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/secrets"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// Operator CLI. Without --server it reads Redis and PostgreSQL directly
+// with the server's configuration; with --server it goes through that
+// server's admin API using --api-key or --token.
+//
+//	admin leaderboard top -n 20
+//	admin user rank 42
+//	admin sync status
+//	admin stream pending
+//	admin rebuild [--overwrite]
+//	admin reconcile [--mode full] [--source postgres]
+//	admin token --subject alice --role operator [--ttl 8h]
+var (
+	serverURL  string
+	apiKey     string
+	token      string
+	jsonOutput bool
+)
+
+func main() {
+	root := &cobra.Command{
+		Use:           "admin",
+		Short:         "Inspect and repair the leaderboard",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.PersistentFlags().StringVar(&serverURL, "server", os.Getenv("LEADERBOARD_SERVER"),
+		"server base URL to use the admin API instead of Redis/PostgreSQL (env LEADERBOARD_SERVER)")
+	root.PersistentFlags().StringVar(&apiKey, "api-key", os.Getenv("LEADERBOARD_API_KEY"),
+		"admin API key for --server (env LEADERBOARD_API_KEY)")
+	root.PersistentFlags().StringVar(&token, "token", os.Getenv("LEADERBOARD_ADMIN_TOKEN"),
+		"admin bearer token for --server, instead of an API key (env LEADERBOARD_ADMIN_TOKEN)")
+	root.PersistentFlags().BoolVar(&jsonOutput, "json", false, "print JSON instead of tables")
+
+	root.AddCommand(leaderboardCmd(), userCmd(), syncCmd(), streamCmd(), rebuildCmd(), reconcileCmd(), tokenCmd())
+
+	if err := root.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// withBackend opens the backend selected by --server, runs fn and closes it
+func withBackend(fn func(b backend) error) error {
+	var b backend
+	if serverURL != "" {
+		b = newHTTPBackend(serverURL, apiKey, token)
+	} else {
+		direct, err := newDirectBackend()
+		if err != nil {
+			return err
+		}
+		b = direct
+	}
+	defer b.Close()
+	return fn(b)
+}
+
+func leaderboardCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "leaderboard", Short: "Leaderboard queries"}
+
+	var limit int
+	top := &cobra.Command{
+		Use:   "top",
+		Short: "Show the top of the global leaderboard",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 1 || limit > 1000 {
+				return fmt.Errorf("-n must be between 1 and 1000")
+			}
+			return withBackend(func(b backend) error {
+				entries, err := b.Top(limit)
+				if err != nil {
+					return err
+				}
+				return render(entries, func(w *tabwriter.Writer) {
+					fmt.Fprintln(w, "RANK\tUSER ID\tUSERNAME\tRATING")
+					for _, e := range entries {
+						fmt.Fprintf(w, "%d\t%d\t%s\t%d\n", e.Rank, e.UserID, e.Username, e.Rating)
+					}
+				})
+			})
+		},
+	}
+	top.Flags().IntVarP(&limit, "limit", "n", 10, "number of players")
+
+	cmd.AddCommand(top)
+	return cmd
+}
+
+func userCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "user", Short: "User queries"}
+
+	rank := &cobra.Command{
+		Use:   "rank <user-id>",
+		Short: "Show a user's rating and global rank",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userID, err := strconv.ParseUint(args[0], 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid user ID %q", args[0])
+			}
+			return withBackend(func(b backend) error {
+				snapshot, err := b.UserRank(uint(userID))
+				if err != nil {
+					return err
+				}
+				return render(snapshot, func(w *tabwriter.Writer) {
+					fmt.Fprintf(w, "User:\t%d (%s)\n", snapshot.UserID, snapshot.Username)
+					fmt.Fprintf(w, "Rating:\t%d\n", snapshot.Rating)
+					fmt.Fprintf(w, "Rank:\t%d of %d\n", snapshot.Rank, snapshot.Total)
+				})
+			})
+		},
+	}
+
+	cmd.AddCommand(rank)
+	return cmd
+}
+
+func syncCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "sync", Short: "Redis → PostgreSQL DB sync"}
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "Show the DB sync backlog and lag",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withBackend(func(b backend) error {
+				status, err := b.SyncStatus()
+				if err != nil {
+					return err
+				}
+				return render(status, func(w *tabwriter.Writer) {
+					fmt.Fprintf(w, "Stream:\t%s (%d entries)\n", status.Stream, status.Length)
+					fmt.Fprintf(w, "Unsynced:\t%d (%d pending, %d lag)\n", status.Unsynced, status.Pending, status.Lag)
+					if status.OldestPendingID != "" {
+						fmt.Fprintf(w, "Oldest pending:\t%s (%s old)\n", status.OldestPendingID, seconds(status.OldestPendingAge))
+					}
+					if status.LastSyncAt != nil {
+						fmt.Fprintf(w, "Last sync:\t%s (%s ago)\n", status.LastSyncAt.Format(time.RFC3339), seconds(status.LastSyncAge))
+					}
+					if len(status.Consumers) > 0 {
+						fmt.Fprintln(w, "\nCONSUMER\tLAST SYNC")
+						for _, c := range status.Consumers {
+							fmt.Fprintf(w, "%s%s\t%s\n", c.Name, self(c.Self), c.LastSyncAt.Format(time.RFC3339))
+						}
+					}
+				})
+			})
+		},
+	}
+
+	cmd.AddCommand(status)
+	return cmd
+}
+
+func streamCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "stream", Short: "Redis stream consumer groups"}
+
+	pending := &cobra.Command{
+		Use:   "pending",
+		Short: "Show pending and undelivered entries per consumer group and consumer",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withBackend(func(b backend) error {
+				groups, err := b.StreamGroups()
+				if err != nil {
+					return err
+				}
+				return render(groups, func(w *tabwriter.Writer) {
+					fmt.Fprintln(w, "STREAM\tGROUP\tCONSUMER\tPENDING\tLAG\tIDLE")
+					for _, g := range groups {
+						fmt.Fprintf(w, "%s\t%s\t\t%d\t%d\t\n", g.Stream, g.Group, g.Pending, g.Lag)
+						for _, c := range g.Consumers {
+							fmt.Fprintf(w, "\t\t%s%s\t%d\t\t%s\n", c.Name, self(c.Self), c.Pending, c.Idle)
+						}
+					}
+				})
+			})
+		},
+	}
+
+	cmd.AddCommand(pending)
+	return cmd
+}
+
+func rebuildCmd() *cobra.Command {
+	var overwrite bool
+	cmd := &cobra.Command{
+		Use:   "rebuild",
+		Short: "Repopulate the Redis boards and user cache from PostgreSQL",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return withBackend(func(b backend) error {
+				result, err := b.Rebuild(ctx, overwrite)
+				if err != nil {
+					return err
+				}
+				return render(result, func(w *tabwriter.Writer) {
+					fmt.Fprintf(w, "Users read:\t%d\n", result.Users)
+					fmt.Fprintf(w, "Board / shadow:\t%d / %d\n", result.Board, result.Shadow)
+					fmt.Fprintf(w, "Cache entries:\t%d\n", result.Cached)
+					fmt.Fprintf(w, "Took:\t%.0fms\n", result.DurationMs)
+				})
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "replace Redis ratings and cache entries with PostgreSQL's (default: only add missing ones)")
+	return cmd
+}
+
+func reconcileCmd() *cobra.Command {
+	var opts service.ReconcileOptions
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Compare Redis with PostgreSQL and repair confirmed drift",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return withBackend(func(b backend) error {
+				report, err := b.Reconcile(ctx, opts)
+				if err != nil {
+					return err
+				}
+				return render(report, func(w *tabwriter.Writer) {
+					fmt.Fprintf(w, "Mode / source:\t%s / %s\n", report.Mode, report.Source)
+					fmt.Fprintf(w, "Checked:\t%d users in %s\n", report.Checked, report.Duration)
+					fmt.Fprintf(w, "In flight:\t%d\n", report.InFlight)
+					fmt.Fprintf(w, "Repaired / failed:\t%d / %d\n", report.Repaired, report.Failed)
+					for kind, count := range report.Mismatches {
+						fmt.Fprintf(w, "Mismatch %s:\t%d\n", kind, count)
+					}
+				})
+			})
+		},
+	}
+	cmd.Flags().StringVar(&opts.Mode, "mode", "", "sample or full (default RECONCILE_MODE)")
+	cmd.Flags().StringVar(&opts.Source, "source", "", "repair rating drift from redis, postgres or none (default RECONCILE_SOURCE)")
+	return cmd
+}
+
+func tokenCmd() *cobra.Command {
+	var (
+		subject string
+		role    string
+		ttl     time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Issue an admin bearer token signed with the server's JWT secret",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if subject == "" {
+				return fmt.Errorf("--subject is required")
+			}
+			if !auth.ValidRole(role) {
+				return fmt.Errorf("--role must be %s, %s or %s", auth.RoleViewer, auth.RoleOperator, auth.RoleAdmin)
+			}
+			if ttl <= 0 {
+				return fmt.Errorf("--ttl must be positive")
+			}
+
+			cfg := config.LoadConfig()
+			if err := cfg.Validate(); err != nil {
+				return fmt.Errorf("invalid configuration: %w", err)
+			}
+			secret, err := adminJWTSecret(cfg)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			claims := auth.AdminClaims{
+				Subject:   subject,
+				Role:      role,
+				Issuer:    cfg.Auth.AdminJWTIssuer,
+				IssuedAt:  now.Unix(),
+				ExpiresAt: now.Add(ttl).Unix(),
+			}
+			signed, err := auth.SignAdminToken(claims, []byte(secret))
+			if err != nil {
+				return err
+			}
+			fmt.Println(signed)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&subject, "subject", "", "admin the token names (shown in audit records as jwt:<subject>)")
+	cmd.Flags().StringVar(&role, "role", auth.RoleViewer, "viewer, operator or admin")
+	cmd.Flags().DurationVar(&ttl, "ttl", 8*time.Hour, "token lifetime")
+	return cmd
+}
+
+// adminJWTSecret resolves the secret admin tokens are signed with as the
+// server does: the JWT secret from the secrets provider, else ADMIN_JWT_SECRET
+func adminJWTSecret(cfg *config.Config) (string, error) {
+	mgr, err := secrets.NewManager(&cfg.Secrets)
+	if err != nil {
+		return "", err
+	}
+	if err := mgr.Load(context.Background()); err != nil {
+		return "", err
+	}
+	secret := mgr.Lookup(secrets.JWTSecret, cfg.Auth.AdminJWTSecret)()
+	switch {
+	case secret == "":
+		return "", fmt.Errorf("no JWT secret: set %s or ADMIN_JWT_SECRET", cfg.Secrets.JWTSecretRef)
+	case len(secret) < 32:
+		return "", fmt.Errorf("the JWT secret must be at least 32 bytes, got %d", len(secret))
+	}
+	return secret, nil
+}
+
+// render prints v as JSON with --json, else the table written by table
+func render(v interface{}, table func(w *tabwriter.Writer)) error {
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// logf reports progress on stderr, keeping stdout for the result
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+func seconds(s float64) time.Duration {
+	return (time.Duration(s * float64(time.Second))).Round(time.Second)
+}
+
+// self marks the consumer of the answering server (or, direct, of this
+// configuration)
+func self(isSelf bool) string {
+	if isSelf {
+		return " (self)"
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/pressly/goose/v3"
+)
+
+// Applies, rolls back or lists the versioned SQL migrations embedded in
+// the binary:
+//
+//	migrate up             apply every pending migration
+//	migrate -to 2 up       apply pending migrations up to version 2
+//	migrate down           roll back the latest applied migration
+//	migrate -to 1 down     roll back every migration above version 1
+//	migrate status         list migrations and when they were applied
+func main() {
+	to := flag.Int64("to", -1, "target version for up/down (default: all pending for up, one step for down)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: migrate [-to version] up|down|status\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command := flag.Arg(0)
+
+	// Load configuration
+	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// Connect to PostgreSQL
+	db, err := database.ConnectPostgres(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer database.CloseDB()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	// Ctrl+C stops after the migration in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "up":
+		var results []*goose.MigrationResult
+		if *to >= 0 {
+			results, err = migrator.UpTo(ctx, *to)
+		} else {
+			results, err = migrator.Up(ctx)
+		}
+		report(results)
+		if err != nil {
+			log.Fatalf("❌ Migration failed: %v", err)
+		}
+		if len(results) == 0 {
+			log.Println("✅ Schema is up to date")
+		}
+
+	case "down":
+		var results []*goose.MigrationResult
+		if *to >= 0 {
+			results, err = migrator.DownTo(ctx, *to)
+		} else {
+			var result *goose.MigrationResult
+			if result, err = migrator.Down(ctx); result != nil {
+				results = append(results, result)
+			}
+		}
+		report(results)
+		if err != nil {
+			log.Fatalf("❌ Rollback failed: %v", err)
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("❌ Failed to read migration status: %v", err)
+		}
+		fmt.Printf("%-8s %-20s %s\n", "STATE", "APPLIED AT", "MIGRATION")
+		for _, status := range statuses {
+			applied := "-"
+			if status.State == goose.StateApplied {
+				applied = status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-8s %-20s %s\n", status.State, applied, status.Source.Path)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func report(results []*goose.MigrationResult) {
+	for _, result := range results {
+		if result.Error != nil {
+			log.Printf("❌ %s: %v", result.Source.Path, result.Error)
+			continue
+		}
+		log.Printf("✅ %s", result)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Repopulates the Redis leaderboard and user cache from PostgreSQL, e.g.
+// after Redis lost its data. Safe to run against a live system: without
+// -overwrite only missing entries are added.
+func main() {
+	overwrite := flag.Bool("overwrite", false, "replace Redis ratings and cache entries with PostgreSQL's (default: only add missing ones)")
+	batch := flag.Int("batch", 0, "users per pipelined batch (default REDIS_REBUILD_BATCH)")
+	sandbox := flag.Bool("sandbox", false, "rebuild the sandbox tenant (SANDBOX_KEY_PREFIX / SANDBOX_SCHEMA)")
+	tenant := flag.String("tenant", "", "rebuild a tenant from TENANTS instead of the default one")
+	flag.Parse()
+
+	log.Println("🧱 Rebuilding Redis from PostgreSQL...")
+
+	// Load configuration
+	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if *batch > 0 {
+		cfg.Rebuild.BatchSize = *batch
+	}
+
+	var (
+		db          *gorm.DB
+		redisClient *redis.Client
+		err         error
+	)
+	if *sandbox && *tenant != "" {
+		log.Fatalf("-sandbox and -tenant cannot be combined")
+	}
+	if *tenant != "" && !cfg.Tenants.Has(*tenant) {
+		log.Fatalf("Unknown tenant %q (TENANTS: %v)", *tenant, cfg.Tenants.IDs)
+	}
+
+	if *tenant != "" {
+		if db, err = database.ConnectTenantPostgres(&cfg.Database, cfg.Tenants.Schema(*tenant)); err != nil {
+			log.Fatalf("Failed to connect to tenant PostgreSQL: %v", err)
+		}
+		defer database.CloseTenantDB(db)
+		if redisClient, err = database.ConnectTenantRedis(&cfg.Redis, cfg.Tenants.KeyPrefix(*tenant)); err != nil {
+			log.Fatalf("Failed to connect to tenant Redis: %v", err)
+		}
+		defer redisClient.Close()
+	} else if *sandbox {
+		if db, err = database.ConnectTenantPostgres(&cfg.Database, cfg.Sandbox.Schema); err != nil {
+			log.Fatalf("Failed to connect to sandbox PostgreSQL: %v", err)
+		}
+		defer database.CloseTenantDB(db)
+		if redisClient, err = database.ConnectTenantRedis(&cfg.Redis, cfg.Sandbox.KeyPrefix); err != nil {
+			log.Fatalf("Failed to connect to sandbox Redis: %v", err)
+		}
+		defer redisClient.Close()
+	} else {
+		if db, err = database.ConnectPostgres(&cfg.Database); err != nil {
+			log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+		}
+		defer database.CloseDB()
+		if redisClient, err = database.ConnectRedis(&cfg.Redis); err != nil {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		defer database.CloseRedis()
+	}
+
+	// Initialize repositories
+	userRepo := repository.NewUserRepository(db)
+	leaderboardRepo := repository.NewLeaderboardRepository(redisClient)
+
+	// Ctrl+C stops after the batch in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rebuildSvc := service.NewRebuildService(cfg.Rebuild, userRepo, leaderboardRepo, nil, nil)
+	result, err := rebuildSvc.Rebuild(ctx, *overwrite, &service.JobProgress{})
+	if err != nil {
+		if result == nil {
+			log.Fatalf("❌ Rebuild failed: %v", err)
+		}
+		log.Fatalf("❌ Rebuild stopped after %d users: %v", result.Users, err)
+	}
+
+	log.Printf("✅ Done: %d users read, %d written to the board, %d to the shadow board, %d cache entries (%.0fms)",
+		result.Users, result.Board, result.Shadow, result.Cached, result.DurationMs)
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// copyOptions configures the COPY-based seeder for multi-million user datasets
+type copyOptions struct {
+	Users          int
+	Workers        int
+	ChunkSize      int
+	CheckpointPath string
+	Rating         ratingSampler
+	RatingDesc     string
+	Swap           bool // build the board under a staging key, RENAME it in at the end
+}
+
+// copyCheckpoint records finished work so an interrupted run can resume.
+// PostgreSQL progress is tracked per COPY chunk, Redis per ID range.
+type copyCheckpoint struct {
+	Users      int          `json:"users"`
+	ChunkSize  int          `json:"chunk_size"`
+	BaseNum    int          `json:"base_num"` // userNum offset so reruns keep usernames unique
+	PGDone     map[int]bool `json:"pg_done"`
+	RedisDone  map[int]bool `json:"redis_done"`
+	MinID      uint         `json:"min_id"`
+	MaxID      uint         `json:"max_id"`
+	StartedAt  time.Time    `json:"started_at"`
+	CompleteAt *time.Time   `json:"complete_at,omitempty"`
+
+	// Staging board token of a -swap run (kept without TTL so resumes find it)
+	StagingToken string `json:"staging_token,omitempty"`
+
+	mu   sync.Mutex
+	path string
+}
+
+const redisRangeSize = 50000
+
+func runCopySeeder(db *gorm.DB, redisClient *redis.Client, opts copyOptions) {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.ChunkSize < 1 {
+		opts.ChunkSize = 100000
+	}
+
+	cp := loadCheckpoint(db, opts)
+	if cp.CompleteAt != nil {
+		log.Printf("✅ Checkpoint %s already complete, delete it to seed again", opts.CheckpointPath)
+		return
+	}
+
+	startTime := time.Now()
+	ctx := context.Background()
+
+	// STEP 1: Generate CSV rows and stream them through COPY
+	log.Printf("\n📊 STEP 1: COPY %d users into PostgreSQL (%d workers)...", cp.Users, opts.Workers)
+	log.Printf("Ratings: %s", opts.RatingDesc)
+	log.Println("─────────────────────────────────")
+
+	totalChunks := (cp.Users + cp.ChunkSize - 1) / cp.ChunkSize
+	runParallel(opts.Workers, totalChunks, func(chunk int) bool {
+		cp.mu.Lock()
+		done := cp.PGDone[chunk]
+		cp.mu.Unlock()
+		return done
+	}, func(chunk int) error {
+		rows, err := copyChunk(ctx, db, cp, chunk, opts.Rating)
+		if err != nil {
+			return err
+		}
+		cp.markPG(chunk)
+		log.Printf("  ✅ Chunk %d/%d copied (%d new rows)", chunk+1, totalChunks, rows)
+		return nil
+	})
+
+	pgElapsed := time.Since(startTime)
+	log.Printf("✅ PostgreSQL COPY completed in %v", pgElapsed)
+
+	// STEP 2: Pipeline users into Redis by ID range
+	log.Printf("\n🔄 STEP 2: Pipelining users into Redis (%d workers)...", opts.Workers)
+	log.Println("─────────────────────────────────")
+
+	if cp.MaxID == 0 {
+		var bounds struct {
+			MinID uint
+			MaxID uint
+		}
+		if err := db.Raw("SELECT COALESCE(MIN(id), 0) AS min_id, COALESCE(MAX(id), 0) AS max_id FROM users WHERE deleted_at IS NULL").
+			Scan(&bounds).Error; err != nil {
+			log.Fatalf("Failed to read user id range: %v", err)
+		}
+		cp.MinID, cp.MaxID = bounds.MinID, bounds.MaxID
+		cp.save()
+	}
+
+	// A resumed run keeps writing where its first ranges went
+	if opts.Swap && cp.StagingToken == "" {
+		if len(cp.RedisDone) > 0 {
+			log.Println("  ⚠️  Ranges were already written to the live board, not swapping")
+		} else {
+			cp.StagingToken = fmt.Sprintf("seed-%d", time.Now().UnixNano())
+			cp.save()
+		}
+	}
+	boardKey := database.LeaderboardKey
+	if cp.StagingToken != "" {
+		boardKey = repository.StagingKey(database.LeaderboardKey, cp.StagingToken)
+		log.Printf("  🔀 Building under %s", boardKey)
+	}
+
+	syncStart := time.Now()
+	totalRanges := int((cp.MaxID-cp.MinID)/redisRangeSize) + 1
+	runParallel(opts.Workers, totalRanges, func(r int) bool {
+		cp.mu.Lock()
+		done := cp.RedisDone[r]
+		cp.mu.Unlock()
+		return done
+	}, func(r int) error {
+		from := cp.MinID + uint(r)*redisRangeSize
+		to := from + redisRangeSize
+		synced, err := pipelineRange(ctx, db, redisClient, boardKey, from, to)
+		if err != nil {
+			return err
+		}
+		cp.markRedis(r)
+		log.Printf("  📊 Range %d/%d synced (%d users)", r+1, totalRanges, synced)
+		return nil
+	})
+
+	// Once every range is staged, swap it in. A staging key that is gone
+	// means a previous run swapped it in before being interrupted.
+	if cp.StagingToken != "" {
+		staged, err := redisClient.Exists(ctx, boardKey).Result()
+		if err != nil {
+			log.Fatalf("Failed to check the staged board: %v", err)
+		}
+		if staged == 1 {
+			if err := repository.NewLeaderboardRepository(redisClient).SwapStaged(cp.StagingToken, database.LeaderboardKey); err != nil {
+				log.Fatalf("Failed to swap in the seeded board: %v", err)
+			}
+			log.Println("  🔀 Swapped the seeded board in")
+		}
+	}
+
+	syncElapsed := time.Since(syncStart)
+	now := time.Now()
+	cp.CompleteAt = &now
+	cp.save()
+
+	totalTime := time.Since(startTime)
+	perMillion := time.Duration(float64(totalTime) / float64(cp.Users) * 1_000_000)
+	leaderboardSize, _ := redisClient.ZCard(ctx, database.LeaderboardKey).Result()
+
+	log.Println("\n═══════════════════════════════════")
+	log.Println("🎉 COPY SEEDING COMPLETE!")
+	log.Println("═══════════════════════════════════")
+	log.Printf("🏆 Redis leaderboard: %d", leaderboardSize)
+	log.Printf("⏱️  Total time:       %v (this run)", totalTime)
+	log.Printf("   ├─ PostgreSQL:     %v", pgElapsed)
+	log.Printf("   ├─ Redis sync:     %v", syncElapsed)
+	log.Printf("   └─ Per million:    %v", perMillion)
+}
+
+// runParallel fans work units 0..total-1 out to N workers, skipping done units.
+// Any failure is fatal; the checkpoint lets the next run pick up where we stopped.
+func runParallel(workers, total int, isDone func(int) bool, work func(int) error) {
+	units := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for unit := range units {
+				if err := work(unit); err != nil {
+					log.Fatalf("❌ Unit %d failed (rerun to resume): %v", unit, err)
+				}
+			}
+		}()
+	}
+
+	for unit := 0; unit < total; unit++ {
+		if isDone(unit) {
+			continue
+		}
+		units <- unit
+	}
+	close(units)
+	wg.Wait()
+}
+
+// copyChunk generates one chunk of users as CSV and streams it via COPY FROM
+// STDIN into a temporary table, then inserts the rows whose username is not
+// taken. Each chunk is its own transaction, so a crash never leaves a partial
+// chunk behind. A chunk committed just before a crash, but not yet marked in
+// the checkpoint, is copied again on resume and inserts nothing.
+func copyChunk(ctx context.Context, db *gorm.DB, cp *copyCheckpoint, chunk int, nextRating ratingSampler) (int, error) {
+	first := chunk * cp.ChunkSize
+	last := first + cp.ChunkSize
+	if last > cp.Users {
+		last = cp.Users
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	pr, pw := io.Pipe()
+
+	// Producer: write CSV rows into the pipe while COPY consumes them
+	go func() {
+		w := csv.NewWriter(pw)
+		now := time.Now().UTC().Format(time.RFC3339)
+		for i := first; i < last; i++ {
+			userNum := cp.BaseNum + i + 1
+			if err := w.Write([]string{
+				generateUsername(userNum),
+				strconv.Itoa(nextRating()),
+				now,
+				now,
+			}); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		w.Flush()
+		pw.CloseWithError(w.Error())
+	}()
+
+	var inserted int64
+	err = conn.Raw(func(driverConn any) error {
+		tx, err := driverConn.(*stdlib.Conn).Conn().Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if _, err := tx.Exec(ctx, `CREATE TEMP TABLE seed_users (
+			username varchar(50), rating bigint, created_at timestamptz, updated_at timestamptz
+		) ON COMMIT DROP`); err != nil {
+			return err
+		}
+		if _, err := tx.Conn().PgConn().CopyFrom(ctx, pr,
+			"COPY seed_users (username, rating, created_at, updated_at) FROM STDIN WITH (FORMAT csv)"); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `INSERT INTO users (username, rating, created_at, updated_at)
+			SELECT username, rating, created_at, updated_at FROM seed_users
+			ON CONFLICT (username) DO NOTHING`)
+		if err != nil {
+			return err
+		}
+		inserted = tag.RowsAffected()
+		return tx.Commit(ctx)
+	})
+	if err != nil {
+		pr.CloseWithError(err)
+		return 0, fmt.Errorf("COPY chunk %d: %w", chunk, err)
+	}
+
+	return int(inserted), nil
+}
+
+// pipelineRange loads users with from <= id < to and writes them to Redis
+// with a multi-member ZADD onto boardKey plus pipelined HSETs (a handful of
+// round trips)
+func pipelineRange(ctx context.Context, db *gorm.DB, redisClient *redis.Client, boardKey string, from, to uint) (int, error) {
+	const pageSize = 5000
+	synced := 0
+	cursor := from
+
+	for cursor < to {
+		var users []struct {
+			ID       uint
+			Username string
+			Rating   int
+		}
+		if err := db.Raw(`SELECT id, username, rating FROM users
+			WHERE id >= ? AND id < ? AND deleted_at IS NULL
+			ORDER BY id LIMIT ?`, cursor, to, pageSize).
+			Scan(&users).Error; err != nil {
+			return synced, err
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		members := make([]redis.Z, 0, len(users))
+		index := make([]redis.Z, 0, len(users))
+		pipe := redisClient.Pipeline()
+		for _, u := range users {
+			members = append(members, redis.Z{
+				Score:  float64(u.Rating),
+				Member: fmt.Sprintf("user:%d", u.ID),
+			})
+			pipe.HSet(ctx, fmt.Sprintf(database.UserCacheKey, u.ID),
+				"id", u.ID,
+				"username", u.Username,
+				"rating", u.Rating,
+			)
+			index = append(index, redis.Z{Member: repository.UsernameIndexMember(u.ID, u.Username)})
+		}
+		pipe.ZAdd(ctx, boardKey, members...)
+		pipe.ZAdd(ctx, database.UsernameIndexKey, index...) // live, not staged: autocomplete skips users off the board
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			return synced, err
+		}
+
+		synced += len(users)
+		cursor = users[len(users)-1].ID + 1
+	}
+
+	return synced, nil
+}
+
+// loadCheckpoint resumes from an existing checkpoint or starts a new one
+func loadCheckpoint(db *gorm.DB, opts copyOptions) *copyCheckpoint {
+	cp := &copyCheckpoint{path: opts.CheckpointPath}
+
+	data, err := os.ReadFile(opts.CheckpointPath)
+	if err == nil {
+		if err := json.Unmarshal(data, cp); err != nil {
+			log.Fatalf("Corrupt checkpoint %s: %v", opts.CheckpointPath, err)
+		}
+		log.Printf("♻️  Resuming from checkpoint %s (%d/%d COPY chunks done)",
+			opts.CheckpointPath, len(cp.PGDone), (cp.Users+cp.ChunkSize-1)/cp.ChunkSize)
+		return cp
+	}
+	if !os.IsNotExist(err) {
+		log.Fatalf("Failed to read checkpoint: %v", err)
+	}
+
+	// New run: offset usernames past existing rows so they stay unique
+	var existing int64
+	db.Table("users").Count(&existing)
+
+	cp.Users = opts.Users
+	cp.ChunkSize = opts.ChunkSize
+	cp.BaseNum = int(existing)
+	cp.PGDone = map[int]bool{}
+	cp.RedisDone = map[int]bool{}
+	cp.StartedAt = time.Now()
+	cp.save()
+
+	return cp
+}
+
+func (cp *copyCheckpoint) markPG(chunk int) {
+	cp.mu.Lock()
+	cp.PGDone[chunk] = true
+	cp.mu.Unlock()
+	cp.save()
+}
+
+func (cp *copyCheckpoint) markRedis(r int) {
+	cp.mu.Lock()
+	cp.RedisDone[r] = true
+	cp.mu.Unlock()
+	cp.save()
+}
+
+// save writes the checkpoint atomically (temp file + rename)
+func (cp *copyCheckpoint) save() {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		log.Printf("⚠️  Failed to encode checkpoint: %v", err)
+		return
+	}
+
+	tmp := cp.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		log.Printf("⚠️  Failed to write checkpoint: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, cp.path); err != nil {
+		log.Printf("⚠️  Failed to save checkpoint: %v", err)
+	}
+}
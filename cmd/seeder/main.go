@@ -1,9 +1,9 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
-	"math"
 	"math/rand"
 	"time"
 
@@ -14,10 +14,36 @@ import (
 )
 
 func main() {
+	mode := flag.String("mode", "batch", "seeding mode: batch (GORM inserts) or copy (COPY + parallel Redis pipelines)")
+	users := flag.Int("users", 10000, "number of users to create")
+	workers := flag.Int("workers", 8, "parallel workers for copy mode")
+	chunkSize := flag.Int("chunk", 100000, "rows per COPY chunk in copy mode")
+	checkpointPath := flag.String("checkpoint", ".seeder_checkpoint.json", "checkpoint file for resumable copy mode")
+	redisBatch := flag.Int("redis-batch", 5000, "users per pipelined Redis write in batch mode")
+	swap := flag.Bool("swap", true, "build the Redis board under a staging key and RENAME it over leaderboard:global when done")
+
+	var ratingOpts ratingOptions
+	flag.StringVar(&ratingOpts.Dist, "rating-dist", distNormal, "rating distribution: normal, uniform, pareto or csv")
+	flag.Float64Var(&ratingOpts.Mean, "rating-mean", 2500, "mean rating (normal, pareto)")
+	flag.Float64Var(&ratingOpts.StdDev, "rating-stddev", 800, "rating standard deviation (normal)")
+	flag.IntVar(&ratingOpts.Min, "rating-min", 100, "lowest rating")
+	flag.IntVar(&ratingOpts.Max, "rating-max", 5000, "highest rating")
+	flag.Float64Var(&ratingOpts.Alpha, "pareto-alpha", 1.5, "pareto tail index, above 1 (lower = more whales)")
+	flag.StringVar(&ratingOpts.File, "rating-file", "", "CSV of rating[,weight] rows to sample from (csv)")
+	flag.Parse()
+
+	nextRating, ratingDesc, err := newRatingSampler(ratingOpts)
+	if err != nil {
+		log.Fatalf("Invalid rating distribution: %v", err)
+	}
+
 	log.Println("🌱 Starting Complete Database Seeder (PostgreSQL + Redis)...")
 
 	// Load configuration
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Connect to PostgreSQL
 	db, err := database.ConnectPostgres(&cfg.Database)
@@ -42,6 +68,20 @@ func main() {
 	userRepo := repository.NewUserRepository(db)
 	leaderboardRepo := repository.NewLeaderboardRepository(redisClient)
 
+	// Copy mode handles its own resume logic via checkpoints
+	if *mode == "copy" {
+		runCopySeeder(db, redisClient, copyOptions{
+			Users:          *users,
+			Workers:        *workers,
+			ChunkSize:      *chunkSize,
+			CheckpointPath: *checkpointPath,
+			Rating:         nextRating,
+			RatingDesc:     ratingDesc,
+			Swap:           *swap,
+		})
+		return
+	}
+
 	// Check if data already exists
 	count, _ := userRepo.Count()
 	if count > 0 {
@@ -69,24 +109,13 @@ func main() {
 	}
 
 	// Configuration
-	numUsers := 10000
+	numUsers := *users
 	log.Printf("Creating %d users...\n", numUsers)
+	log.Printf("Ratings: %s", ratingDesc)
 
 	// Initialize random seed
 	rand.Seed(time.Now().UnixNano())
 
-	// Common name prefixes for realistic usernames
-	prefixes := []string{
-		"pro", "ninja", "gamer", "killer", "shadow", "master", "legend",
-		"dark", "fire", "ice", "thunder", "storm", "dragon", "phoenix",
-		"rahul", "amit", "priya", "rohan", "sneha", "vikram", "ananya",
-	}
-
-	suffixes := []string{
-		"x", "king", "queen", "lord", "god", "pro", "elite", "prime",
-		"123", "007", "gamer", "player", "master", "legend", "warrior",
-	}
-
 	// STEP 1: Seed PostgreSQL
 	log.Println("\n📊 STEP 1: Seeding PostgreSQL...")
 	log.Println("─────────────────────────────────")
@@ -102,27 +131,10 @@ func main() {
 			userNum := batch*batchSize + i + 1
 
 			// Generate UNIQUE username (always include userNum to ensure uniqueness)
-			var username string
-			randChoice := rand.Float64()
-
-			if randChoice < 0.3 {
-				// 30% chance: prefix_suffix_NUM
-				username = fmt.Sprintf("%s_%s_%d",
-					prefixes[rand.Intn(len(prefixes))],
-					suffixes[rand.Intn(len(suffixes))],
-					userNum)
-			} else if randChoice < 0.6 {
-				// 30% chance: prefix_NUM
-				username = fmt.Sprintf("%s_%d",
-					prefixes[rand.Intn(len(prefixes))],
-					userNum)
-			} else {
-				// 40% chance: user_NUM format
-				username = fmt.Sprintf("user_%d", userNum)
-			}
-
-			// Generate rating with bell curve distribution
-			rating := generateBellCurveRating()
+			username := generateUsername(userNum)
+
+			// Draw rating from the chosen distribution
+			rating := nextRating()
 
 			users = append(users, models.User{
 				Username: username,
@@ -153,13 +165,23 @@ func main() {
 	log.Println("─────────────────────────────────")
 
 	syncStart := time.Now()
-	offset := 0
+	var afterID uint
 	totalSynced := 0
-	syncBatchSize := 500
+	syncBatchSize := *redisBatch
+	if syncBatchSize <= 0 {
+		syncBatchSize = 5000
+	}
+
+	// With -swap, readers keep the old board until the new one is complete
+	var stagingToken string
+	if *swap {
+		stagingToken = fmt.Sprintf("seed-%d", time.Now().UnixNano())
+		log.Printf("  🔀 Building under %s", repository.StagingKey(database.LeaderboardKey, stagingToken))
+	}
 
 	for {
-		// Fetch users from PostgreSQL
-		users, err := userRepo.GetAll(syncBatchSize, offset)
+		// Fetch users from PostgreSQL, paging by ID
+		users, err := userRepo.GetCachePage(afterID, syncBatchSize)
 		if err != nil {
 			log.Fatalf("Failed to fetch users: %v", err)
 		}
@@ -168,27 +190,22 @@ func main() {
 			break
 		}
 
-		// Sync to Redis
-		for _, user := range users {
-			// Add to leaderboard (1 Redis operation)
-			if err := leaderboardRepo.AddUser(user.ID, user.Rating); err != nil {
-				log.Printf("  ⚠️  Failed to add user %d to leaderboard: %v", user.ID, err)
-				continue
-			}
-
-			// Cache user data (1 Redis operation)
-			if err := leaderboardRepo.CacheUser(&user); err != nil {
-				log.Printf("  ⚠️  Failed to cache user %d: %v", user.ID, err)
-			}
-
-			// NO username indexing (uses PostgreSQL for search)
+		// One multi-member ZADD plus pipelined HSETs: a single round trip
+		// per page instead of two per user
+		if *swap {
+			_, err = leaderboardRepo.StageUsers(stagingToken, users)
+		} else {
+			_, err = leaderboardRepo.RestoreUsers(users, true)
+		}
+		if err != nil {
+			log.Fatalf("Failed to sync users %d-%d to Redis: %v", users[0].ID, users[len(users)-1].ID, err)
 		}
 
 		totalSynced += len(users)
 		progress := float64(totalSynced) / float64(totalUsers) * 100
 		log.Printf("  📊 Synced %d/%d users (%.1f%%)", totalSynced, totalUsers, progress)
 
-		offset += syncBatchSize
+		afterID = users[len(users)-1].ID
 
 		// Break if we got less than batch size
 		if len(users) < syncBatchSize {
@@ -196,6 +213,13 @@ func main() {
 		}
 	}
 
+	if *swap {
+		if err := leaderboardRepo.SwapStaged(stagingToken, database.LeaderboardKey, database.ShadowBoardKey, database.UsernameIndexKey); err != nil {
+			log.Fatalf("Failed to swap in the seeded board: %v", err)
+		}
+		log.Println("  🔀 Swapped the seeded board in")
+	}
+
 	syncElapsed := time.Since(syncStart)
 	leaderboardSize, _ := leaderboardRepo.GetLeaderboardSize()
 
@@ -220,25 +244,35 @@ func main() {
 	log.Println("\n🚀 Start server with: go run cmd/server/main.go")
 }
 
-// generateBellCurveRating generates rating with normal distribution
-func generateBellCurveRating() int {
-	mean := 2500.0
-	stdDev := 800.0
-
-	// Box-Muller transform for normal distribution
-	u1 := rand.Float64()
-	u2 := rand.Float64()
+// Common name prefixes for realistic usernames
+var prefixes = []string{
+	"pro", "ninja", "gamer", "killer", "shadow", "master", "legend",
+	"dark", "fire", "ice", "thunder", "storm", "dragon", "phoenix",
+	"rahul", "amit", "priya", "rohan", "sneha", "vikram", "ananya",
+}
 
-	z := math.Sqrt(-2.0*math.Log(u1)) * math.Cos(2.0*math.Pi*u2)
-	rating := int(mean + stdDev*z)
+var suffixes = []string{
+	"x", "king", "queen", "lord", "god", "pro", "elite", "prime",
+	"123", "007", "gamer", "player", "master", "legend", "warrior",
+}
 
-	// Clamp to valid range
-	if rating < 100 {
-		rating = 100
-	}
-	if rating > 5000 {
-		rating = 5000
+// generateUsername builds a realistic username that is unique per userNum
+func generateUsername(userNum int) string {
+	randChoice := rand.Float64()
+
+	if randChoice < 0.3 {
+		// 30% chance: prefix_suffix_NUM
+		return fmt.Sprintf("%s_%s_%d",
+			prefixes[rand.Intn(len(prefixes))],
+			suffixes[rand.Intn(len(suffixes))],
+			userNum)
+	} else if randChoice < 0.6 {
+		// 30% chance: prefix_NUM
+		return fmt.Sprintf("%s_%d",
+			prefixes[rand.Intn(len(prefixes))],
+			userNum)
 	}
 
-	return rating
+	// 40% chance: user_NUM format
+	return fmt.Sprintf("user_%d", userNum)
 }
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Rating distributions for generated users
+const (
+	distNormal  = "normal"
+	distUniform = "uniform"
+	distPareto  = "pareto"
+	distCSV     = "csv"
+)
+
+// ratingOptions selects the distribution generated ratings are drawn from
+type ratingOptions struct {
+	Dist   string
+	Mean   float64 // normal: mean; pareto: mean before truncation at Max
+	StdDev float64 // normal only
+	Min    int
+	Max    int
+	Alpha  float64 // pareto tail index: lower = heavier top end
+	File   string  // csv: rating[,weight] rows
+}
+
+// ratingSampler draws one rating; safe for concurrent use
+type ratingSampler func() int
+
+// newRatingSampler validates opts and returns the sampler with a short
+// description for the log
+func newRatingSampler(opts ratingOptions) (ratingSampler, string, error) {
+	if opts.Dist != distCSV && opts.Min >= opts.Max {
+		return nil, "", fmt.Errorf("-rating-min (%d) must be below -rating-max (%d)", opts.Min, opts.Max)
+	}
+
+	switch opts.Dist {
+	case distNormal:
+		if opts.StdDev <= 0 {
+			return nil, "", errors.New("-rating-stddev must be positive")
+		}
+		return normalSampler(opts), fmt.Sprintf("normal (mean %.0f, stddev %.0f, clamped to %d-%d)",
+			opts.Mean, opts.StdDev, opts.Min, opts.Max), nil
+
+	case distUniform:
+		return func() int {
+			return opts.Min + rand.Intn(opts.Max-opts.Min+1)
+		}, fmt.Sprintf("uniform (%d-%d)", opts.Min, opts.Max), nil
+
+	case distPareto:
+		if opts.Alpha <= 1 {
+			return nil, "", errors.New("-pareto-alpha must be above 1")
+		}
+		if opts.Mean <= float64(opts.Min) {
+			return nil, "", fmt.Errorf("-rating-mean (%.0f) must be above -rating-min (%d) for pareto", opts.Mean, opts.Min)
+		}
+		return paretoSampler(opts), fmt.Sprintf("pareto (alpha %.2f, mean %.0f, %d-%d)",
+			opts.Alpha, opts.Mean, opts.Min, opts.Max), nil
+
+	case distCSV:
+		if opts.File == "" {
+			return nil, "", errors.New("-rating-file is required for the csv distribution")
+		}
+		sampler, count, err := csvSampler(opts.File)
+		if err != nil {
+			return nil, "", err
+		}
+		return sampler, fmt.Sprintf("csv (%d ratings from %s)", count, opts.File), nil
+	}
+
+	return nil, "", fmt.Errorf("unknown rating distribution %q (normal, uniform, pareto or csv)", opts.Dist)
+}
+
+// normalSampler uses the Box-Muller transform, clamping to [Min, Max]
+func normalSampler(opts ratingOptions) ratingSampler {
+	return func() int {
+		u1 := 1 - rand.Float64() // (0, 1], so the log is finite
+		u2 := rand.Float64()
+
+		z := math.Sqrt(-2.0*math.Log(u1)) * math.Cos(2.0*math.Pi*u2)
+		rating := int(opts.Mean + opts.StdDev*z)
+		return max(opts.Min, min(opts.Max, rating))
+	}
+}
+
+// paretoSampler draws from a Pareto (Lomax) distribution starting at Min:
+// most players sit near the bottom and a thin "whale" tail reaches the
+// top. Scale is set so the untruncated mean is Mean. Draws above Max are
+// redrawn, so the tail thins out instead of piling up at Max.
+func paretoSampler(opts ratingOptions) ratingSampler {
+	scale := (opts.Mean - float64(opts.Min)) * (opts.Alpha - 1)
+	return func() int {
+		for {
+			u := 1 - rand.Float64() // (0, 1]
+			rating := float64(opts.Min) + scale*(math.Pow(u, -1/opts.Alpha)-1)
+			if rating <= float64(opts.Max) {
+				return int(rating)
+			}
+		}
+	}
+}
+
+// csvSampler draws from the empirical distribution in path: one rating
+// per row, with an optional weight column (default 1). A non-numeric
+// first row is treated as a header.
+func csvSampler(path string) (ratingSampler, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var ratings []int
+	var cumulative []float64 // running weight total, for binary search
+	total := 0.0
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		rating, err := strconv.Atoi(strings.TrimSpace(record[0]))
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, 0, fmt.Errorf("%s:%d: invalid rating %q", path, line, record[0])
+		}
+		weight := 1.0
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			if weight, err = strconv.ParseFloat(strings.TrimSpace(record[1]), 64); err != nil || weight < 0 {
+				return nil, 0, fmt.Errorf("%s:%d: invalid weight %q", path, line, record[1])
+			}
+		}
+		if weight == 0 {
+			continue
+		}
+
+		total += weight
+		ratings = append(ratings, rating)
+		cumulative = append(cumulative, total)
+	}
+	if len(ratings) == 0 {
+		return nil, 0, fmt.Errorf("%s: no ratings", path)
+	}
+
+	return func() int {
+		target := rand.Float64() * total
+		i := sort.Search(len(cumulative), func(i int) bool { return cumulative[i] > target })
+		return ratings[min(i, len(ratings)-1)]
+	}, len(ratings), nil
+}
@@ -3,93 +3,243 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/anticheat"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apiversion"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/fairqueue"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/handler"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/health"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/logging"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/middleware"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/notify"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/playground"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/ratelimit"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/scheduler"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/secrets"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
-	"github.com/SSujoy-Samanta/leaderboard-backend/internal/websocket"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/workerpool"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// Leveled logs, as text or JSON (LOG_LEVEL, LOG_FORMAT)
+	logging.Setup(cfg.Log)
 
 	// Set Gin mode
 	gin.SetMode(cfg.Server.GinMode)
 
+	// Load secrets (env vars by default, or AWS/GCP/Vault) with rotation
+	secretsMgr, err := secrets.NewManager(&cfg.Secrets)
+	if err != nil {
+		logging.Fatal("Failed to initialize secrets provider", "error", err)
+	}
+	if err := secretsMgr.Load(context.Background()); err != nil {
+		logging.Fatal("Failed to load secrets", "error", err)
+	}
+	secretsMgr.Start()
+	defer secretsMgr.Stop()
+
+	cfg.Database.PasswordFunc = secretsMgr.Lookup(secrets.DBPassword, "")
+	cfg.Redis.PasswordFunc = secretsMgr.Lookup(secrets.RedisPassword, cfg.Redis.Password)
+	jwtSecret := secretsMgr.Lookup(secrets.JWTSecret, cfg.Auth.AdminJWTSecret)
+	if secret := jwtSecret(); secret != "" && len(secret) < 32 {
+		logging.Fatal("JWT secret must be at least 32 bytes", "length", len(secret))
+	}
+
 	// Connect to PostgreSQL
 	db, err := database.ConnectPostgres(&cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+		logging.Fatal("Failed to connect to PostgreSQL", "error", err)
 	}
 	defer database.CloseDB()
 
-	// if !config.IsProduction() {
-	// 	log.Println("🧱 Running AutoMigrate (non-production)")
-	// 	if err := database.AutoMigrate(db); err != nil {
-	// 		log.Fatalf("Failed to run migrations: %v", err)
-	// 	}
-	// } else {
-	// 	log.Println("🚫 Skipping AutoMigrate in production")
-	// }
+	// The schema is managed by cmd/migrate; only warn when it is behind
+	if pending, err := database.PendingMigrations(context.Background(), db); err != nil {
+		slog.Warn("⚠️  Could not check schema migrations", "error", err)
+	} else if pending > 0 {
+		slog.Warn("⚠️  Schema migrations pending: run `migrate up`", "pending", pending)
+	}
 
 	// Connect to Redis
 	redisClient, err := database.ConnectRedis(&cfg.Redis)
 	if err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
+		logging.Fatal("Failed to connect to Redis", "error", err)
 	}
 	defer database.CloseRedis()
 
-	// Initialize repositories
-	userRepo := repository.NewUserRepository(db)
-	scoreUpdateRepo := repository.NewScoreUpdateRepository(db)
-	leaderboardRepo := repository.NewLeaderboardRepository(redisClient)
-
-	// Initialize WebSocket hub
-	hub := websocket.NewHub()
-	go hub.Run()
-
-	// Initialize Redis Pub/Sub service (handles multi-server broadcasting)
-	pubSubService := service.NewPubSubService(redisClient)
-	// Subscribe to Redis channel and broadcast to local WebSocket clients
-	pubSubService.Start(func(payload *models.ScoreUpdatePayload) {
-		// When ANY server publishes, this server receives it
-		// and broadcasts to ITS WebSocket clients
-		hub.BroadcastScoreUpdate(payload)
-		log.Printf("📨 Received broadcast: User %d, rank %+d", 
-			payload.UserID, payload.RankDelta)
+	// Readiness: Redis answers a PING and PostgreSQL a trivial query
+	health.Register("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	health.Register("postgres", func(ctx context.Context) error {
+		return db.WithContext(ctx).Exec("SELECT 1").Error
 	})
-	defer pubSubService.Stop()
 
-	// Initialize DB sync service (Redis queue-based, async PostgreSQL writes)
-	dbSyncService := service.NewDBSyncService(redisClient, db)
-	dbSyncService.Start()
-	defer dbSyncService.Stop()
+	// Workers for long-running admin jobs (imports, spikes, ...)
+	jobSvc := service.NewJobService(cfg.Jobs, repository.NewJobRepository(db))
+	jobSvc.Start()
+	defer jobSvc.Stop()
+
+	// Bounded worker pool for enrichment of large pages
+	enrichPool := workerpool.New(cfg.App.EnrichWorkers, cfg.App.EnrichParallelism)
+	defer enrichPool.Stop()
+
+	// Anti-cheat detectors inspect every applied score update off the hot path
+	var detectors []anticheat.Detector
+	if cfg.AntiCheat.Enabled {
+		detectors = append(detectors,
+			&anticheat.RatingJumpDetector{MaxDelta: cfg.AntiCheat.MaxRatingJump},
+			&anticheat.UpdateRateDetector{MaxPerMinute: cfg.AntiCheat.MaxUpdatesPerMinute, Counter: repository.NewLeaderboardRepository(redisClient)},
+		)
+	}
+	anomalySvc := service.NewAnomalyService(repository.NewAnomalyRepository(db), cfg.AntiCheat.QueueSize, detectors...)
+	anomalySvc.Start()
+	defer anomalySvc.Stop()
+
+	// Outbound notification channels (webhooks signed with the HMAC secret,
+	// sent to user-supplied URLs so only public addresses are reachable)
+	webhookSender := notify.NewPublicWebhookSender(10*time.Second, secretsMgr.Lookup(secrets.HMACSecret, ""))
+	var emailSender notify.Sender = &notify.LogSender{ChannelName: "email"}
+	if cfg.Digest.SMTPHost != "" {
+		emailSender = notify.NewEmailSender(notify.SMTPConfig{
+			Host:     cfg.Digest.SMTPHost,
+			Port:     cfg.Digest.SMTPPort,
+			Username: cfg.Digest.SMTPUsername,
+			Password: cfg.Digest.SMTPPassword,
+			From:     cfg.Digest.SMTPFrom,
+		})
+	}
+
+	// The default stack: the same services as every tenant, on the
+	// unprefixed keyspace and default schema
+	prod, err := newTenant(cfg, enrichPool, prodSpec(cfg, db, redisClient, anomalySvc, jobSvc, webhookSender, emailSender))
+	if err != nil {
+		logging.Fatal("Failed to build the production stack", "error", err)
+	}
+
+	// Production-only admin tooling, on top of the stack's services
+	scoreModel := service.NewScoreModel(cfg.Simulator, prod.leaderboardRepo, prod.userRepo)
+	simulatorSvc := service.NewSimulatorService(cfg.Simulator, redisClient, prod.leaderboard, prod.userRepo, scoreModel, cfg.Jobs.Node)
+	importSvc := service.NewImportService(cfg.Import, prod.leaderboard, prod.audit, jobSvc)
+	spikeSvc := service.NewSpikeService(cfg.Spike, prod.leaderboard, prod.userRepo, prod.dbSync, prod.handlers.hub, jobSvc, scoreModel)
+	rollbackSvc := service.NewRollbackService(repository.NewProtectionRepository(redisClient), prod.scoreUpdateRepo, prod.leaderboard, prod.audit)
+	integritySvc := service.NewIntegrityService(cfg.Integrity, cfg.Jobs.Node, redisClient, prod.leaderboardRepo, prod.userRepo)
+	streamMonitor := service.NewStreamMonitor(redisClient, cfg.Streams.Consumer, cfg.DBSync.StatusEvery)
+	benchmarkSvc := service.NewBenchmarkService(cfg.Benchmark, redisClient, db, cfg.Jobs.Node)
+	reconcileSvc := service.NewReconcileService(cfg.Reconcile, cfg.Jobs.Node, prod.userRepo, prod.leaderboardRepo, prod.dbSync, jobSvc)
+	impersonationSvc := service.NewImpersonationService(cfg.Impersonate, redisClient, prod.userRepo, repository.NewImpersonationRepository(db))
+	canarySvc := service.NewCanaryService(cfg.Canary, cfg.Jobs.Node, prod.leaderboard, prod.userRepo)
+
+	// Periodic Redis vs PostgreSQL reconcile, next to the stack's jobs
+	prod.sched.Add("reconcile", scheduler.Every(cfg.Reconcile.Interval), reconcileSvc.Run)
+
+	// Admin webhook subscriptions (rank milestones, top entries, user updates)
+	webhookSvc := service.NewWebhookService(cfg.Webhooks, repository.NewWebhookRepository(db))
+	prod.bus.Subscribe(models.EventScoreUpdate, webhookSvc.HandleScoreUpdate)
+
+	// When ANY server publishes, this server receives it; the stack
+	// broadcasts it to its WebSocket clients
+	prod.bus.SubscribeAll(models.EventScoreUpdate, func(event eventbus.Event) {
+		payload := event.Payload.(*models.ScoreUpdatePayload)
+		slog.Debug("📨 Received broadcast",
+			"user_id", payload.UserID, "rank_delta", payload.RankDelta, "request_id", event.RequestID)
+	})
+
+	// DB sync, the event transport (readiness fails while it is
+	// disconnected), ingest, replay, notifications and scheduled jobs
+	prod.start()
+	defer prod.stop()
+	health.Register("db_sync", prod.dbSync.Check)
+	health.Register("event_bus", prod.events.Check)
+
+	// Periodic Redis vs PostgreSQL (and cross-server) checksums
+	integritySvc.Start()
+	defer integritySvc.Stop()
+
+	// DB sync backlog gauges (db_sync_* on /metrics)
+	streamMonitor.Start()
+	defer streamMonitor.Stop()
+
+	// Synthetic end-to-end probe (fails readiness when too slow). Probe
+	// users are shadow-banned, so the canary watches for its own probes
+	// among the shadow updates, fanned out to every server only while it runs.
+	if cfg.Canary.Enabled {
+		prod.bus.SubscribeAll(models.EventShadowScoreUpdate, func(event eventbus.Event) {
+			canarySvc.ObserveBroadcast(event.Payload.(*models.ScoreUpdatePayload))
+		})
+	}
+	canarySvc.Start()
+	defer canarySvc.Stop()
+	health.Register("canary", canarySvc.Check)
+
+	// Daily rank-change digests, sent per user timezone
+	prod.digest.Start()
+	defer prod.digest.Stop()
 
-	// Initialize services
-	leaderboardSvc := service.NewLeaderboardService(userRepo, leaderboardRepo, scoreUpdateRepo, dbSyncService, pubSubService)
-	searchSvc := service.NewSearchService(userRepo, leaderboardRepo, leaderboardSvc)
-	simulatorSvc := service.NewSimulatorService(leaderboardSvc, userRepo)
+	// Signed webhook deliveries, retried with backoff
+	webhookSvc.Start()
+	defer webhookSvc.Stop()
 
 	// Initialize handlers
-	leaderboardHandler := handler.NewLeaderboardHandler(leaderboardSvc)
-	searchHandler := handler.NewSearchHandler(searchSvc)
-	wsHandler := handler.NewWebSocketHandler(hub)
+	tenantRouter := &tenants{prod: prod.handlers}
+	healthHandler := handler.NewHealthHandler()
+	adminHandler := handler.NewAdminHandler(prod.users, prod.audit, spikeSvc, anomalySvc, importSvc, cfg.Import, jobSvc, rollbackSvc, integritySvc, streamMonitor, impersonationSvc, benchmarkSvc, prod.rebuild, reconcileSvc, prod.dbSync, simulatorSvc, webhookSvc, prod.sched, cfg.Jobs.Node)
+
+	// Sandbox tenant for partner integration (sandbox-tier API keys)
+	if cfg.Sandbox.Enabled {
+		sandboxHandlers, stopSandbox, err := startTenant(cfg, enrichPool, sandboxSpec(cfg))
+		if err != nil {
+			logging.Fatal("Failed to start sandbox", "error", err)
+		}
+		defer stopSandbox()
+		tenantRouter.sandbox = sandboxHandlers
+	}
+
+	// Named tenants: isolated boards for other games or customers
+	tenantRouter.named = make(map[string]*tenantHandlers, len(cfg.Tenants.IDs))
+	for _, id := range cfg.Tenants.IDs {
+		handlers, stopTenant, err := startTenant(cfg, enrichPool, namedTenantSpec(cfg, id, webhookSender, emailSender))
+		if err != nil {
+			logging.Fatal("Failed to start tenant", "tenant", id, "error", err)
+		}
+		defer stopTenant()
+		tenantRouter.named[id] = handlers
+	}
+
+	// Per-API-key weighted fair queue around expensive operations
+	fairQueue := fairqueue.NewScheduler(cfg.FairQueue.Capacity, cfg.FairQueue.TierWeights, cfg.FairQueue.WaitTimeout)
+
+	// Per-caller request quotas, counted in Redis across servers
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		limiter = ratelimit.New(redisClient, cfg.RateLimit.Window, cfg.RateLimit.TierLimits)
+	}
+	limitsHandler := handler.NewLimitsHandler(limiter, fairQueue)
 
 	// Setup router
-	router := setupRouter(leaderboardHandler, searchHandler, wsHandler)
+	router := setupRouter(&cfg.Auth, jwtSecret, &cfg.Tenants, cfg.Compression, fairQueue, limiter, impersonationSvc, tenantRouter, healthHandler, limitsHandler, adminHandler)
 
-	// Start score simulator
+	// Start score simulator (follows SIMULATOR_* until an admin changes it)
 	simulatorSvc.Start()
 	defer simulatorSvc.Stop()
 
@@ -101,13 +251,14 @@ func main() {
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("🚀 Server starting on port %s", cfg.Server.Port)
-		log.Printf("📊 Leaderboard API: http://localhost:%s/api/leaderboard", cfg.Server.Port)
-		log.Printf("🔍 Search API: http://localhost:%s/api/search?q=user", cfg.Server.Port)
-		log.Printf("🌐 WebSocket: ws://localhost:%s/ws", cfg.Server.Port)
+		slog.Info("🚀 Server starting", "port", cfg.Server.Port)
+		slog.Info("📊 Leaderboard API", "url", "http://localhost:"+cfg.Server.Port+"/api/leaderboard")
+		slog.Info("🔍 Search API", "url", "http://localhost:"+cfg.Server.Port+"/api/search?q=user")
+		slog.Info("🌐 WebSocket", "url", "ws://localhost:"+cfg.Server.Port+"/ws")
+		slog.Info("🧪 Playground", "url", "http://localhost:"+cfg.Server.Port+"/playground/")
 
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			logging.Fatal("Failed to start server", "error", err)
 		}
 	}()
 
@@ -116,57 +267,192 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("🛑 Shutting down server...")
+	slog.Info("🛑 Shutting down server...")
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		logging.Fatal("Server forced to shutdown", "error", err)
 	}
 
-	log.Println("✅ Server stopped")
+	// srv.Shutdown leaves hijacked WebSocket connections alone; close them
+	// with a reconnect hint
+	for _, hub := range tenantRouter.hubs() {
+		if err := hub.Shutdown(ctx); err != nil {
+			slog.Warn("⚠️  WebSocket clients not closed cleanly", "error", err)
+		}
+	}
+
+	slog.Info("✅ Server stopped")
 }
 
 func setupRouter(
-	leaderboardHandler *handler.LeaderboardHandler,
-	searchHandler *handler.SearchHandler,
-	wsHandler *handler.WebSocketHandler,
-) *gin.Engine {
+	authCfg *config.AuthConfig,
+	jwtSecret func() string,
+	tenantCfg *config.TenantConfig,
+	compressionCfg config.CompressionConfig,
+	fairQueue *fairqueue.Scheduler,
+	limiter *ratelimit.Limiter,
+	impersonationSvc service.ImpersonationService,
+	t *tenants,
+	healthHandler *handler.HealthHandler,
+	limitsHandler *handler.LimitsHandler,
+	adminHandler *handler.AdminHandler,
+) http.Handler {
 	router := gin.New()
 
 	// Middleware
-	router.Use(gin.Recovery())
+	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		apierror.Abort(c, http.StatusInternalServerError, "Internal server error")
+	}))
+	router.Use(middleware.RequestIDMiddleware())
 	router.Use(middleware.LoggerMiddleware())
+	router.Use(middleware.ErrorMiddleware())
+	router.Use(middleware.CompressionMiddleware(compressionCfg))
 	router.Use(middleware.CORSMiddleware())
+	router.Use(middleware.APIKeyMiddleware(authCfg))
+	router.Use(middleware.TenantMiddleware(tenantCfg))
+	router.Use(middleware.ImpersonationMiddleware(impersonationSvc))
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "healthy",
-			"time":   time.Now().Format(time.RFC3339),
-		})
+	// Health checks: liveness (the process) and readiness (its
+	// dependencies); /health is kept for existing probes
+	router.GET("/health", healthHandler.Live)
+	router.GET("/health/live", healthHandler.Live)
+	router.GET("/health/ready", healthHandler.Ready)
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Interactive API playground (built from the embedded OpenAPI spec)
+	playground.Register(router)
+
+	router.NoRoute(func(c *gin.Context) {
+		apierror.Abort(c, http.StatusNotFound, "Route not found")
 	})
 
-	// API routes
-	api := router.Group("/api")
-	{
-		// Leaderboard routes
-		api.GET("/leaderboard", leaderboardHandler.GetLeaderboard)
-		api.GET("/leaderboard/stats", leaderboardHandler.GetStats)
-		api.GET("/leaderboard/user/:user_id/rank", leaderboardHandler.GetUserRank)
-		api.PUT("/leaderboard/user/:user_id/score", leaderboardHandler.UpdateUserScore)
+	// API routes (served by the caller's tenant, the sandbox for sandbox-tier
+	// keys), under /api/v1 and its alias /api
+	versions := apiversion.New(router)
+	versions.Register(1, func(api *gin.RouterGroup) {
+		if limiter != nil {
+			api.Use(middleware.RateLimitMiddleware(limiter))
+		}
+		queued := middleware.FairQueueMiddleware(fairQueue)
+		{
+			// Caller quotas
+			api.GET("/limits", limitsHandler.GetLimits)
 
-		// Search routes
-		api.GET("/search", searchHandler.SearchUsers)
+			// Leaderboard routes
+			api.GET("/leaderboard", queued, t.leaderboard((*handler.LeaderboardHandler).GetLeaderboard))
+			api.GET("/leaderboard/stats", t.leaderboard((*handler.LeaderboardHandler).GetStats))
+			api.GET("/leaderboard/period/:period", t.leaderboard((*handler.LeaderboardHandler).GetPeriodBoard))
+			api.GET("/leaderboard/most-improved", t.leaderboard((*handler.LeaderboardHandler).GetMostImproved))
+			api.GET("/leaderboard/tiers", t.leaderboard((*handler.LeaderboardHandler).GetTiers))
+			api.GET("/leaderboard/tier/:tier", t.leaderboard((*handler.LeaderboardHandler).GetTierBoard))
+			api.GET("/leaderboard/user/:user_id/rank", t.leaderboard((*handler.LeaderboardHandler).GetUserRank))
+			api.PUT("/leaderboard/user/:user_id/score", t.leaderboard((*handler.LeaderboardHandler).UpdateUserScore))
+			api.POST("/leaderboard/user/:user_id/results", t.leaderboard((*handler.LeaderboardHandler).SubmitResult))
+			api.GET("/leaderboard/updates/:id", t.leaderboard((*handler.LeaderboardHandler).GetUpdateStatus))
 
-		// WebSocket stats
-		api.GET("/ws/stats", wsHandler.GetConnectionStats)
-	}
+			// Bulk routes (fair-queued per API key)
+			api.POST("/leaderboard/ranks", queued, t.leaderboard((*handler.LeaderboardHandler).GetUserRanks))
+			api.POST("/leaderboard/scores", queued, t.leaderboard((*handler.LeaderboardHandler).BulkUpdateScores))
+
+			// User routes
+			api.POST("/users", t.user((*handler.UserHandler).CreateUser))
+			api.PATCH("/users/:user_id", t.user((*handler.UserHandler).UpdateUser))
+			api.PUT("/users/:user_id/username", t.user((*handler.UserHandler).RenameUser))
+			api.DELETE("/users/:user_id", t.user((*handler.UserHandler).DeleteUser))
+			api.DELETE("/users/:user_id/purge", t.user((*handler.UserHandler).PurgeUser))
+			api.GET("/users/:user_id/profile", t.user((*handler.UserHandler).GetProfile))
+			api.GET("/users/:user_id/rank-history", t.user((*handler.UserHandler).GetRankHistory))
+			api.GET("/users/:user_id/rank-explanations", t.user((*handler.UserHandler).GetRankExplanations))
+			api.GET("/users/:user_id/history", t.user((*handler.UserHandler).GetScoreHistory))
+			api.GET("/users/:user_id/digest", t.user((*handler.UserHandler).GetDigestSubscription))
+			api.PUT("/users/:user_id/digest", t.user((*handler.UserHandler).UpdateDigestSubscription))
+			api.GET("/users/:user_id/notifications", t.user((*handler.UserHandler).GetNotificationPreferences))
+			api.PUT("/users/:user_id/notifications", t.user((*handler.UserHandler).UpdateNotificationPreferences))
+			api.DELETE("/users/:user_id/notifications", t.user((*handler.UserHandler).ResetNotificationPreferences))
+
+			// Team routes
+			api.GET("/teams/leaderboard", t.team((*handler.TeamHandler).GetTeamLeaderboard))
+			api.POST("/teams", t.team((*handler.TeamHandler).CreateTeam))
+			api.GET("/teams/:team_id", t.team((*handler.TeamHandler).GetTeam))
+			api.PATCH("/teams/:team_id", t.team((*handler.TeamHandler).UpdateTeam))
+			api.DELETE("/teams/:team_id", t.team((*handler.TeamHandler).DeleteTeam))
+			api.PUT("/teams/:team_id/members/:user_id", t.team((*handler.TeamHandler).AddTeamMember))
+			api.DELETE("/teams/:team_id/members/:user_id", t.team((*handler.TeamHandler).RemoveTeamMember))
+
+			// Matchmaking
+			api.GET("/matchmaking/:user_id", t.matchmaking((*handler.MatchmakingHandler).GetOpponents))
+			api.POST("/matchmaking/:user_id/matches", t.matchmaking((*handler.MatchmakingHandler).RecordMatch))
+
+			// Search routes
+			api.GET("/search", t.search((*handler.SearchHandler).SearchUsers))
+			api.GET("/search/autocomplete", t.search((*handler.SearchHandler).Autocomplete))
+
+			// WebSocket stats
+			api.GET("/ws/stats", t.ws((*handler.WebSocketHandler).GetConnectionStats))
+			api.GET("/ws/schema", t.prod.ws.GetSchema)
+		}
+
+		// Admin routes (admin-tier API key or admin token). Every admin role
+		// may read; changes need the operator or admin role.
+		admin := api.Group("/admin", middleware.AdminMiddleware(authCfg, jwtSecret))
+		operator := middleware.RequireRole(auth.RoleOperator)
+		superuser := middleware.RequireRole(auth.RoleAdmin)
+		{
+			admin.PUT("/users/:user_id/status", superuser, adminHandler.SetUserStatus)
+			admin.GET("/audit", adminHandler.ListAdjustments)
+			admin.POST("/leaderboard/reset", superuser, adminHandler.ResetLeaderboard)
+			admin.POST("/spikes", operator, adminHandler.StartSpike)
+			admin.GET("/spikes/:id", adminHandler.GetSpike)
+			admin.GET("/spikes/:id/report", adminHandler.DownloadSpikeReport)
+			admin.DELETE("/spikes/:id", operator, adminHandler.StopSpike)
+			admin.GET("/simulator", adminHandler.GetSimulator)
+			admin.POST("/simulator/start", operator, adminHandler.StartSimulator)
+			admin.POST("/simulator/stop", operator, adminHandler.StopSimulator)
+			admin.GET("/anomalies", adminHandler.ListAnomalies)
+			admin.PUT("/anomalies/:id", operator, adminHandler.ReviewAnomaly)
+			admin.POST("/scores/import", superuser, adminHandler.ImportScores)
+			admin.POST("/scores/revert", superuser, adminHandler.RevertScores)
+			admin.GET("/state", adminHandler.GetState)
+			admin.GET("/streams", adminHandler.ListStreamConsumers)
+			admin.GET("/sync/status", adminHandler.GetSyncStatus)
+			admin.GET("/benchmark", adminHandler.GetBenchmarkBaseline)
+			admin.POST("/benchmark", operator, adminHandler.RunBenchmark)
+			admin.POST("/rebuild", operator, adminHandler.RebuildRedis)
+			admin.POST("/reconcile", operator, adminHandler.StartReconcile)
+			admin.GET("/reconcile", adminHandler.GetReconcileReport)
+			admin.GET("/schedule", adminHandler.ListScheduledJobs)
+			admin.GET("/protection", adminHandler.GetProtection)
+			admin.POST("/protection", superuser, adminHandler.MarkProtection)
+			admin.DELETE("/protection", superuser, adminHandler.ClearProtection)
+			admin.GET("/impersonations", adminHandler.ListImpersonations)
+			admin.POST("/impersonations", superuser, adminHandler.StartImpersonation)
+			admin.DELETE("/impersonations/:id", superuser, adminHandler.EndImpersonation)
+			admin.GET("/jobs", adminHandler.ListJobs)
+			admin.GET("/jobs/:id", adminHandler.GetJob)
+			admin.DELETE("/jobs/:id", operator, adminHandler.CancelJob)
+			admin.GET("/jobs/:id/errors", adminHandler.DownloadJobErrors)
+			admin.GET("/websocket/clients", t.prod.ws.ListClients)
+			admin.DELETE("/websocket/clients", operator, t.prod.ws.DisconnectAllClients)
+			admin.DELETE("/websocket/clients/:id", operator, t.prod.ws.DisconnectClient)
+			admin.DELETE("/websocket/users/:user_id", operator, t.prod.ws.DisconnectUser)
+			admin.POST("/websocket/announcements", operator, t.prod.ws.Announce)
+			admin.GET("/webhooks", adminHandler.ListWebhooks)
+			admin.POST("/webhooks", superuser, adminHandler.CreateWebhook)
+			admin.GET("/webhooks/deliveries", adminHandler.ListWebhookDeliveries)
+			admin.GET("/webhooks/:id", adminHandler.GetWebhook)
+			admin.PUT("/webhooks/:id", superuser, adminHandler.UpdateWebhook)
+			admin.DELETE("/webhooks/:id", superuser, adminHandler.DeleteWebhook)
+		}
+	})
 
 	// WebSocket endpoint
-	router.GET("/ws", wsHandler.HandleWebSocket)
+	router.GET("/ws", t.ws((*handler.WebSocketHandler).HandleWebSocket))
 
-	return router
+	return versions.Handler()
 }
@@ -0,0 +1,487 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/handler"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/notify"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/ranking"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/scheduler"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/tiers"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/websocket"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/workerpool"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// tenantHandlers are the handlers of one tenant's stack. Production, the
+// sandbox and each named tenant run the same code against different stores.
+type tenantHandlers struct {
+	leaderboard *handler.LeaderboardHandler
+	search      *handler.SearchHandler
+	ws          *handler.WebSocketHandler
+	user        *handler.UserHandler
+	team        *handler.TeamHandler
+	matchmaking *handler.MatchmakingHandler
+
+	hub *websocket.Hub // shut down with the server
+}
+
+// tenants routes each request to the stack of its tenant: a named tenant
+// (TENANTS), the sandbox for sandbox-tier keys, or production
+type tenants struct {
+	prod    *tenantHandlers
+	sandbox *tenantHandlers            // nil unless SANDBOX_ENABLED
+	named   map[string]*tenantHandlers // tenant ID -> stack
+}
+
+// hubs returns the WebSocket hub of every stack
+func (t *tenants) hubs() []*websocket.Hub {
+	hubs := []*websocket.Hub{t.prod.hub}
+	if t.sandbox != nil {
+		hubs = append(hubs, t.sandbox.hub)
+	}
+	for _, h := range t.named {
+		hubs = append(hubs, h.hub)
+	}
+	return hubs
+}
+
+func (t *tenants) route(serve func(h *tenantHandlers, c *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.FromContext(c)
+		switch {
+		case principal.IsTenant():
+			h, ok := t.named[principal.Tenant]
+			if !ok {
+				apierror.Abort(c, http.StatusNotFound, "Unknown tenant")
+				return
+			}
+			serve(h, c)
+		case principal.IsSandbox():
+			if t.sandbox == nil {
+				apierror.Abort(c, http.StatusServiceUnavailable, "Sandbox is not enabled on this server")
+				return
+			}
+			serve(t.sandbox, c)
+		default:
+			serve(t.prod, c)
+		}
+	}
+}
+
+func (t *tenants) leaderboard(method func(*handler.LeaderboardHandler, *gin.Context)) gin.HandlerFunc {
+	return t.route(func(h *tenantHandlers, c *gin.Context) { method(h.leaderboard, c) })
+}
+
+func (t *tenants) search(method func(*handler.SearchHandler, *gin.Context)) gin.HandlerFunc {
+	return t.route(func(h *tenantHandlers, c *gin.Context) { method(h.search, c) })
+}
+
+func (t *tenants) ws(method func(*handler.WebSocketHandler, *gin.Context)) gin.HandlerFunc {
+	return t.route(func(h *tenantHandlers, c *gin.Context) { method(h.ws, c) })
+}
+
+func (t *tenants) user(method func(*handler.UserHandler, *gin.Context)) gin.HandlerFunc {
+	return t.route(func(h *tenantHandlers, c *gin.Context) { method(h.user, c) })
+}
+
+func (t *tenants) team(method func(*handler.TeamHandler, *gin.Context)) gin.HandlerFunc {
+	return t.route(func(h *tenantHandlers, c *gin.Context) { method(h.team, c) })
+}
+
+func (t *tenants) matchmaking(method func(*handler.MatchmakingHandler, *gin.Context)) gin.HandlerFunc {
+	return t.route(func(h *tenantHandlers, c *gin.Context) { method(h.matchmaking, c) })
+}
+
+// tenantSpec describes the stores and defaults of one isolated stack
+type tenantSpec struct {
+	name      string // tenant ID or "sandbox", for logs and metrics
+	keyPrefix string
+	schema    string
+	notify    config.NotificationConfig
+	ranking   config.RankingConfig
+
+	// Outbound notification channels
+	webhookSender notify.Sender
+	emailSender   notify.Sender
+
+	// Production passes its own connections, which stay open when the
+	// stack stops, and the anti-cheat inspector and job service of its
+	// admin tooling; other tenants connect to their prefix and schema
+	db        *gorm.DB
+	redis     *redis.Client
+	inspector service.ScoreInspector
+	jobs      service.JobService
+}
+
+// prodSpec is the default stack, on the unprefixed keyspace and the
+// default schema
+func prodSpec(cfg *config.Config, db *gorm.DB, redisClient *redis.Client, inspector service.ScoreInspector, jobs service.JobService, webhookSender, emailSender notify.Sender) tenantSpec {
+	return tenantSpec{
+		name:          "prod",
+		notify:        cfg.Notify,
+		ranking:       cfg.Ranking,
+		webhookSender: webhookSender,
+		emailSender:   emailSender,
+		db:            db,
+		redis:         redisClient,
+		inspector:     inspector,
+		jobs:          jobs,
+	}
+}
+
+// sandboxSpec is the sandbox tenant: nothing is really sent from it, and
+// notifications that pass the sandbox preferences (and defaults) are logged
+func sandboxSpec(cfg *config.Config) tenantSpec {
+	return tenantSpec{
+		name:          "sandbox",
+		keyPrefix:     cfg.Sandbox.KeyPrefix,
+		schema:        cfg.Sandbox.Schema,
+		notify:        cfg.Sandbox.Notify,
+		ranking:       cfg.Sandbox.Ranking,
+		webhookSender: &notify.LogSender{ChannelName: "sandbox-webhook"},
+		emailSender:   &notify.LogSender{ChannelName: "sandbox-email"},
+	}
+}
+
+// namedTenantSpec is a tenant from TENANTS, with production's defaults and
+// notification channels
+func namedTenantSpec(cfg *config.Config, id string, webhookSender, emailSender notify.Sender) tenantSpec {
+	return tenantSpec{
+		name:          id,
+		keyPrefix:     cfg.Tenants.KeyPrefix(id),
+		schema:        cfg.Tenants.Schema(id),
+		notify:        cfg.Notify,
+		ranking:       cfg.Ranking,
+		webhookSender: webhookSender,
+		emailSender:   emailSender,
+	}
+}
+
+// tenantStack is a built tenant. Production adds its admin tooling on top
+// of the services kept here before calling start.
+type tenantStack struct {
+	handlers *tenantHandlers
+
+	db              *gorm.DB
+	redis           *redis.Client
+	bus             *eventbus.Bus
+	events          service.MessageBus
+	userRepo        repository.UserRepository
+	leaderboardRepo repository.LeaderboardRepository
+	scoreUpdateRepo repository.ScoreUpdateRepository
+
+	dbSync        service.DBSyncService
+	leaderboard   service.LeaderboardService
+	users         service.UserService
+	audit         service.AuditService
+	rebuild       service.RebuildService
+	digest        service.DigestService
+	sched         *scheduler.Scheduler
+	startServices func()
+	stopServices  func()
+	stopHub       context.CancelFunc
+}
+
+// startTenant builds an isolated tenant and starts it. The returned func
+// stops it.
+func startTenant(cfg *config.Config, enrichPool *workerpool.Pool, spec tenantSpec) (*tenantHandlers, func(), error) {
+	stack, err := newTenant(cfg, enrichPool, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	stack.start()
+	return stack.handlers, stack.stop, nil
+}
+
+// newTenant builds a tenant: its own Redis keyspace, Postgres schema,
+// event channel and WebSocket hub, so its score updates run end to end
+// without touching any other tenant. Admin tooling, anti-cheat, the
+// simulator, digests and the canary stay production-only. Nothing runs
+// until start.
+func newTenant(cfg *config.Config, enrichPool *workerpool.Pool, spec tenantSpec) (*tenantStack, error) {
+	strategy, err := ranking.New(spec.ranking)
+	if err != nil {
+		return nil, fmt.Errorf("%s ranking: %w", spec.name, err)
+	}
+	slog.Info("🎯 Ranking strategy", "tenant", spec.name, "strategy", strategy.Name())
+	ladder, err := tiers.New(cfg.Tiers)
+	if err != nil {
+		return nil, fmt.Errorf("%s tiers: %w", spec.name, err)
+	}
+
+	db, redisClient := spec.db, spec.redis
+	closeStores := func() {}
+	if db == nil {
+		if db, err = database.ConnectTenantPostgres(&cfg.Database, spec.schema); err != nil {
+			return nil, fmt.Errorf("%s database: %w", spec.name, err)
+		}
+		if redisClient, err = database.ConnectTenantRedis(&cfg.Redis, spec.keyPrefix); err != nil {
+			database.CloseTenantDB(db)
+			return nil, fmt.Errorf("%s redis: %w", spec.name, err)
+		}
+		closeStores = func() {
+			redisClient.Close()
+			database.CloseTenantDB(db)
+		}
+	}
+
+	// Initialize repositories
+	userRepo := repository.NewUserRepository(db)
+	scoreUpdateRepo := repository.NewScoreUpdateRepository(db)
+	leaderboardRepo := repository.NewLeaderboardRepository(redisClient)
+	rankHistoryRepo := repository.NewRankHistoryRepository(db)
+	digestRepo := repository.NewDigestRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	deferredRepo := repository.NewDeferredRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	teamBoardRepo := repository.NewTeamBoardRepository(redisClient)
+	decayRepo := repository.NewDecayRepository(redisClient)
+	achievementRepo := repository.NewAchievementRepository(db)
+	achievementProgressRepo := repository.NewAchievementProgressRepository(redisClient)
+	streakRepo := repository.NewStreakRepository(db)
+	streakCacheRepo := repository.NewStreakCacheRepository(redisClient)
+	matchmakingRepo := repository.NewMatchmakingRepository(redisClient)
+	schedulerRepo := repository.NewSchedulerRepository(redisClient)
+	wsStatsRepo := repository.NewWSStatsRepository(redisClient)
+
+	hub := websocket.NewHub(cfg.WebSocket)
+	hubCtx, stopHub := context.WithCancel(context.Background())
+	go hub.Run(hubCtx)
+
+	// Pub/sub channels are not keys, so they get the prefix explicitly
+	pubSubService, err := newEventTransport(cfg, redisClient, spec.keyPrefix, hub)
+	if err != nil {
+		stopHub()
+		closeStores()
+		return nil, fmt.Errorf("%s event transport: %w", spec.name, err)
+	}
+	bus := eventbus.New(pubSubService)
+	bus.Define(models.EventScoreUpdate, func() interface{} { return &models.ScoreUpdatePayload{} })
+	bus.Define(models.EventShadowScoreUpdate, func() interface{} { return &models.ScoreUpdatePayload{} })
+	bus.Define(models.EventUserRenamed, func() interface{} { return &models.UserRenamedPayload{} })
+	bus.Define(models.EventUserRemoved, func() interface{} { return &models.UserRemovedPayload{} })
+	bus.Define(models.EventLeaderboardReset, func() interface{} { return &models.LeaderboardResetPayload{} })
+	bus.Define(models.EventMilestone, func() interface{} { return &models.MilestonePayload{} })
+	bus.Define(models.EventPromotion, func() interface{} { return &models.TierChangePayload{} })
+	bus.Define(models.EventDemotion, func() interface{} { return &models.TierChangePayload{} })
+	bus.Define(models.EventAchievement, func() interface{} { return &models.AchievementPayload{} })
+	bus.Define(models.EventAnnouncement, func() interface{} { return &models.AnnouncementPayload{} })
+
+	postgresHealth := service.NewPostgresHealth(cfg.PGOutage, db, spec.name)
+	dbSyncService := service.NewDBSyncService(redisClient, db, cfg.Streams, cfg.DBSync, cfg.PGOutage, postgresHealth, spec.name)
+	bus.Subscribe(models.EventScoreUpdate, dbSyncService.HandleScoreUpdate)
+	bus.Subscribe(models.EventShadowScoreUpdate, dbSyncService.HandleScoreUpdate)
+
+	periodSvc := service.NewPeriodBoardService(cfg.Periods, leaderboardRepo, userRepo)
+	bus.Subscribe(models.EventScoreUpdate, periodSvc.HandleScoreUpdate)
+
+	teamSvc := service.NewTeamService(cfg.Teams, teamRepo, teamBoardRepo, userRepo, leaderboardRepo)
+	bus.Subscribe(models.EventScoreUpdate, teamSvc.HandleScoreUpdate)
+	bus.Subscribe(models.EventUserRemoved, teamSvc.HandleUserRemoved)
+	bus.Subscribe(models.EventLeaderboardReset, teamSvc.HandleReset)
+
+	redisHealth := service.NewRedisHealth(cfg.Fallback, redisClient, spec.name)
+
+	// Initialize services (the anti-cheat inspector watches production only)
+	streakSvc := service.NewStreakService(cfg.Streaks, cfg.Periods.LocalTime, streakRepo, streakCacheRepo, leaderboardRepo)
+	bus.Subscribe(models.EventUserRemoved, streakSvc.HandleUserRemoved)
+	milestones := service.NewMilestoneDetector(cfg.Milestones, ladder, streakSvc, leaderboardRepo, bus)
+	achievementSvc := service.NewAchievementService(cfg.Achievement, achievementRepo, achievementProgressRepo, bus)
+	bus.Subscribe(models.EventUserRemoved, achievementSvc.HandleUserRemoved)
+	scoreEnricher := service.NewScoreEnricher(cfg.Ingest, leaderboardRepo, bus, spec.inspector, milestones, achievementSvc)
+	leaderboardSvc := service.NewLeaderboardService(cfg.RatingLimit, strategy, ladder, userRepo, leaderboardRepo, scoreUpdateRepo, dbSyncService, bus, hub, enrichPool, spec.inspector, redisHealth, scoreEnricher, milestones, achievementSvc)
+	matchmakingSvc := service.NewMatchmakingService(cfg.Matchmaking, matchmakingRepo, leaderboardRepo, userRepo, leaderboardSvc)
+	bus.Subscribe(models.EventUserRemoved, matchmakingSvc.HandleUserRemoved)
+	searchSvc := service.NewSearchService(cfg.Search, userRepo, leaderboardRepo, leaderboardSvc)
+	userSvc := service.NewUserService(userRepo, leaderboardRepo, scoreUpdateRepo, rankHistoryRepo, leaderboardSvc, bus, dbSyncService, achievementSvc, streakSvc)
+	auditSvc := service.NewAuditService(auditRepo, redisClient, cfg.PGOutage.CheckEvery)
+	replaySvc := service.NewScoreReplayService(cfg.Fallback, deferredRepo, userRepo, leaderboardSvc, auditSvc, spec.name)
+	ingestSvc := service.NewIngestService(cfg.Ingest, cfg.Streams, redisClient, leaderboardSvc, auditSvc)
+	rankHistorySvc := service.NewRankHistoryService(cfg.History, leaderboardRepo, rankHistoryRepo)
+	scoreHistorySvc := service.NewScoreHistoryService(cfg.History, scoreUpdateRepo)
+	rankExplainSvc := service.NewRankExplainService(cfg.History, userRepo, scoreUpdateRepo, auditRepo, leaderboardRepo)
+	decaySvc := service.NewDecayService(cfg.Decay, ladder, decayRepo, userRepo, leaderboardRepo, auditSvc)
+	bus.Subscribe(models.EventScoreUpdate, decaySvc.HandleScoreUpdate)
+	bus.Subscribe(models.EventShadowScoreUpdate, decaySvc.HandleScoreUpdate)
+	bus.Subscribe(models.EventUserRemoved, decaySvc.HandleUserRemoved)
+	sched := scheduler.New(schedulerRepo, cfg.Jobs.Node, cfg.Scheduler.Enabled)
+	scheduleJobs(sched, cfg, rankHistorySvc, scoreHistorySvc, decaySvc)
+
+	// Cold cache (Redis restarted empty): repopulate it before serving.
+	// Tenant keys live in the same Redis, so they go cold together.
+	rebuildSvc := service.NewRebuildService(cfg.Rebuild, userRepo, leaderboardRepo, spec.jobs, bus)
+	if cfg.Rebuild.OnStart {
+		if err := rebuildSvc.EnsureWarm(); err != nil {
+			pubSubService.Stop() // the rebuild may have published through it
+			stopHub()
+			closeStores()
+			return nil, fmt.Errorf("%s rebuild: %w", spec.name, err)
+		}
+	}
+
+	notificationSvc := service.NewNotificationService(spec.notify, redisClient, notificationRepo, digestRepo,
+		userRepo, leaderboardRepo, spec.webhookSender, spec.emailSender)
+	bus.Subscribe(models.EventScoreUpdate, notificationSvc.HandleScoreUpdate)
+	bus.Subscribe(models.EventShadowScoreUpdate, notificationSvc.HandleScoreUpdate)
+	digestSvc := service.NewDigestService(cfg.Digest, digestRepo, rankHistoryRepo, scoreUpdateRepo,
+		leaderboardRepo, leaderboardSvc, notificationSvc, spec.webhookSender, spec.emailSender)
+
+	bus.SubscribeAll(models.EventScoreUpdate, func(event eventbus.Event) {
+		hub.BroadcastScoreUpdate(event.Payload.(*models.ScoreUpdatePayload))
+	})
+	relay := func(event eventbus.Event) {
+		hub.BroadcastEvent(event.Type, event.Payload)
+	}
+	bus.SubscribeAll(models.EventUserRenamed, relay)
+	bus.SubscribeAll(models.EventUserRemoved, relay)
+	bus.SubscribeAll(models.EventLeaderboardReset, relay)
+	bus.SubscribeAll(models.EventMilestone, relay)
+	bus.SubscribeAll(models.EventPromotion, relay)
+	bus.SubscribeAll(models.EventDemotion, relay)
+	bus.SubscribeAll(models.EventAchievement, relay)
+	bus.SubscribeAll(models.EventAnnouncement, relay)
+
+	// Top-N diffs, folded over TOP_DIFF_INTERVAL
+	topDiffSvc := service.NewTopDiffService(cfg.TopDiff, leaderboardSvc, hub)
+	wsStatsSvc := service.NewConnectionStatsService(cfg.Jobs.Node, cfg.WebSocket.StatsInterval, hub, wsStatsRepo)
+	announceSvc := service.NewAnnouncementService(bus)
+	for _, event := range []string{models.EventScoreUpdate, models.EventUserRenamed, models.EventUserRemoved, models.EventLeaderboardReset} {
+		bus.SubscribeAll(event, topDiffSvc.HandleEvent)
+	}
+
+	startServices := func() {
+		redisHealth.Start()
+		postgresHealth.Start()
+		auditSvc.Start()
+		replaySvc.Start()
+		scoreEnricher.Start()
+		dbSyncService.Start()
+		pubSubService.Start()
+		ingestSvc.Start()
+		notificationSvc.Start()
+		teamSvc.Start()
+		topDiffSvc.Start()
+		wsStatsSvc.Start()
+		sched.Start()
+		slog.Info("🏢 Tenant ready", "tenant", spec.name, "keys", spec.keyPrefix, "schema", spec.schema)
+	}
+	stopServices := func() {
+		sched.Stop()
+		topDiffSvc.Stop()
+		wsStatsSvc.Stop()
+		teamSvc.Stop()
+		notificationSvc.Stop()
+		ingestSvc.Stop()
+		scoreEnricher.Stop()
+		pubSubService.Stop()
+		dbSyncService.Stop()
+		replaySvc.Stop()
+		auditSvc.Stop()
+		postgresHealth.Stop()
+		redisHealth.Stop()
+		closeStores()
+	}
+
+	return &tenantStack{
+		handlers: &tenantHandlers{
+			leaderboard: handler.NewLeaderboardHandler(leaderboardSvc, auditSvc, periodSvc, ingestSvc, replaySvc, matchmakingSvc, cfg.Ingest),
+			search:      handler.NewSearchHandler(searchSvc),
+			ws:          handler.NewWebSocketHandler(hub, leaderboardSvc, wsStatsSvc, announceSvc, cfg.WebSocket),
+			user:        handler.NewUserHandler(userSvc, rankHistorySvc, scoreHistorySvc, rankExplainSvc, digestSvc, notificationSvc),
+			team:        handler.NewTeamHandler(teamSvc),
+			matchmaking: handler.NewMatchmakingHandler(matchmakingSvc, cfg.Matchmaking),
+			hub:         hub,
+		},
+		db:              db,
+		redis:           redisClient,
+		bus:             bus,
+		events:          pubSubService,
+		userRepo:        userRepo,
+		leaderboardRepo: leaderboardRepo,
+		scoreUpdateRepo: scoreUpdateRepo,
+		dbSync:          dbSyncService,
+		leaderboard:     leaderboardSvc,
+		users:           userSvc,
+		audit:           auditSvc,
+		rebuild:         rebuildSvc,
+		digest:          digestSvc,
+		sched:           sched,
+		startServices:   startServices,
+		stopServices:    stopServices,
+		stopHub:         stopHub,
+	}, nil
+}
+
+// start runs the tenant's background services, the event transport
+// included
+func (t *tenantStack) start() {
+	t.startServices()
+}
+
+// stop ends the tenant's services and its WebSocket hub, and closes the
+// connections it opened
+func (t *tenantStack) stop() {
+	t.stopHub()
+	t.stopServices()
+}
+
+// newEventTransport picks the message bus fanning events out to every
+// server (EVENT_TRANSPORT). channelPrefix keeps a tenant's channel,
+// subject or topic apart; stream keys get the key prefix instead. When the stream
+// transport finds it missed events, the hub's clients are told to refetch
+// the board.
+func newEventTransport(cfg *config.Config, redisClient *redis.Client, channelPrefix string, hub *websocket.Hub) (service.MessageBus, error) {
+	switch cfg.Events.Transport {
+	case config.EventTransportStream:
+		return service.NewEventStreamService(redisClient, cfg.Events.StreamMaxLen, hub.BroadcastLeaderboardUpdate), nil
+	case config.EventTransportNATS:
+		return service.NewNATSBus(cfg.Events.NATSURL, cfg.Jobs.Node, channelPrefix+cfg.Events.NATSSubject)
+	case config.EventTransportKafka:
+		// Topic names cannot hold the ':' key prefixes end with
+		topic := strings.ReplaceAll(channelPrefix, ":", ".") + cfg.Events.KafkaTopic
+		return service.NewKafkaBus(cfg.Events.KafkaBrokers, topic)
+	default:
+		return service.NewPubSubService(redisClient, channelPrefix), nil
+	}
+}
+
+// scheduleJobs adds the periodic jobs every stack runs; production adds
+// reconcile on top
+func scheduleJobs(
+	sched *scheduler.Scheduler,
+	cfg *config.Config,
+	rankHistorySvc service.RankHistoryService,
+	scoreHistorySvc service.ScoreHistoryService,
+	decaySvc service.DecayService,
+) {
+	sched.Add("rank_snapshot", scheduler.Every(cfg.History.RankSnapshotInterval), rankHistorySvc.Run)
+	sched.Add("score_compaction", scheduler.Every(cfg.History.CompactionInterval), func(ctx context.Context) error {
+		_, err := scoreHistorySvc.Compact(ctx)
+		return err
+	})
+
+	var decaySchedule scheduler.Schedule
+	if cfg.Decay.Enabled {
+		decaySchedule, _ = scheduler.Parse(cfg.Decay.Schedule) // checked by Validate
+	}
+	sched.Add("decay", decaySchedule, func(ctx context.Context) error {
+		_, err := decaySvc.Run(ctx)
+		return err
+	})
+}
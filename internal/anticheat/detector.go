@@ -0,0 +1,92 @@
+// Package anticheat inspects score updates for suspicious patterns. Detectors
+// are pluggable: each one looks at a single update and reports any flags.
+package anticheat
+
+import (
+	"fmt"
+	"time"
+)
+
+// Rule names reported on flags
+const (
+	RuleRatingJump = "rating_jump"
+	RuleUpdateRate = "update_rate"
+)
+
+// Observation is one applied score update
+type Observation struct {
+	UserID    uint
+	OldRating int
+	NewRating int
+	At        time.Time
+}
+
+// Flag is a suspicious pattern found in an observation
+type Flag struct {
+	Rule   string
+	Detail string
+}
+
+// Detector inspects one update; it returns no flags when nothing is wrong
+type Detector interface {
+	Name() string
+	Inspect(obs Observation) ([]Flag, error)
+}
+
+// RatingJumpDetector flags a single update that moves a rating more than MaxDelta
+type RatingJumpDetector struct {
+	MaxDelta int
+}
+
+func (d *RatingJumpDetector) Name() string {
+	return RuleRatingJump
+}
+
+func (d *RatingJumpDetector) Inspect(obs Observation) ([]Flag, error) {
+	delta := obs.NewRating - obs.OldRating
+	if delta < 0 {
+		delta = -delta
+	}
+	if d.MaxDelta <= 0 || delta <= d.MaxDelta {
+		return nil, nil
+	}
+	return []Flag{{
+		Rule:   RuleRatingJump,
+		Detail: fmt.Sprintf("rating changed by %d (limit %d)", obs.NewRating-obs.OldRating, d.MaxDelta),
+	}}, nil
+}
+
+// RateCounter counts a user's updates in the current fixed window, shared
+// across servers
+type RateCounter interface {
+	CountUpdate(userID uint, window time.Duration) (int64, error)
+}
+
+// UpdateRateDetector flags users making more than MaxPerMinute updates in a
+// minute. It flags once per window, when the limit is first exceeded.
+type UpdateRateDetector struct {
+	MaxPerMinute int
+	Counter      RateCounter
+}
+
+func (d *UpdateRateDetector) Name() string {
+	return RuleUpdateRate
+}
+
+func (d *UpdateRateDetector) Inspect(obs Observation) ([]Flag, error) {
+	if d.MaxPerMinute <= 0 {
+		return nil, nil
+	}
+
+	count, err := d.Counter.CountUpdate(obs.UserID, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if count != int64(d.MaxPerMinute)+1 {
+		return nil, nil
+	}
+	return []Flag{{
+		Rule:   RuleUpdateRate,
+		Detail: fmt.Sprintf("more than %d updates in one minute", d.MaxPerMinute),
+	}}, nil
+}
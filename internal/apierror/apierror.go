@@ -0,0 +1,90 @@
+// Package apierror is the body of every API error response: a message for
+// people, a stable machine-readable code for programs and the request ID to
+// quote when reporting it.
+package apierror
+
+import (
+	"net/http"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// Codes for errors that have no more specific one (service errors have
+// their own, e.g. user_not_found); each is tied to one HTTP status
+const (
+	CodeInvalidRequest     = "invalid_request"     // 400
+	CodeUnauthorized       = "unauthorized"        // 401
+	CodeForbidden          = "forbidden"           // 403
+	CodeNotFound           = "not_found"           // 404
+	CodeConflict           = "conflict"            // 409
+	CodePayloadTooLarge    = "payload_too_large"   // 413
+	CodeUnprocessable      = "unprocessable"       // 422
+	CodeRateLimited        = "rate_limited"        // 429
+	CodeInternal           = "internal_error"      // 500
+	CodeServiceUnavailable = "service_unavailable" // 503
+)
+
+// Response is the error envelope
+type Response struct {
+	Error     string      `json:"error"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"request_id,omitempty"`
+	Details   interface{} `json:"details,omitempty"` // extra fields some errors carry
+}
+
+// CodeFor returns the generic code of an HTTP status
+func CodeFor(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
+
+// Abort answers with status and the generic code of the status
+func Abort(c *gin.Context, status int, message string) {
+	AbortCode(c, status, CodeFor(status), message)
+}
+
+// AbortCode answers with status and a specific code
+func AbortCode(c *gin.Context, status int, code, message string) {
+	AbortDetails(c, status, code, message, nil)
+}
+
+// AbortDetails answers with status, code and details
+func AbortDetails(c *gin.Context, status int, code, message string, details interface{}) {
+	c.AbortWithStatusJSON(status, Response{
+		Error:     message,
+		Code:      code,
+		RequestID: requestid.FromContext(c.Request.Context()),
+		Details:   details,
+	})
+}
+
+// Fail hands a service error to the error middleware, which answers with
+// the status and code the error maps to; message is the answer for errors
+// it does not know (500)
+func Fail(c *gin.Context, err error, message string) {
+	c.Error(err).SetMeta(message)
+	c.Abort()
+}
@@ -0,0 +1,139 @@
+// Package apiversion serves the REST API under /api/v{n}, one route group
+// per version, so breaking changes ship as a new version while clients of
+// the old one keep working. /api is an alias of the default version, and a
+// request to it may ask for another version in the X-API-Version header.
+package apiversion
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// Header asks for a version on /api paths, and tells the version that
+// served every versioned response
+const Header = "X-API-Version"
+
+// Default is the version served under the unversioned /api prefix
+const Default = 1
+
+// CodeUnsupportedVersion is the error code of a request for a version the
+// server does not have
+const CodeUnsupportedVersion = "unsupported_api_version"
+
+const (
+	prefix     = "/api"
+	contextKey = "api_version"
+)
+
+// Set is the API versions a router serves
+type Set struct {
+	engine   *gin.Engine
+	versions map[int]bool
+}
+
+// New creates an empty set of versions served by engine
+func New(engine *gin.Engine) *Set {
+	return &Set{engine: engine, versions: make(map[int]bool)}
+}
+
+// Register serves a version's routes under /api/v{version}, and under /api
+// too for Default. routes is called once per prefix with the group to add
+// them (and their middleware) to.
+func (s *Set) Register(version int, routes func(api *gin.RouterGroup)) {
+	s.versions[version] = true
+	routes(s.engine.Group(fmt.Sprintf("%s/v%d", prefix, version), served(version)))
+	if version == Default {
+		routes(s.engine.Group(prefix, s.alias()))
+	}
+}
+
+// served records the version of a request and returns it in the response
+func served(version int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(contextKey, version)
+		c.Header(Header, strconv.Itoa(version))
+		c.Next()
+	}
+}
+
+// alias serves the default version on /api, refusing requests that asked
+// for a version the server does not have (Handler rewrites the others)
+func (s *Set) alias() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if asked := c.GetHeader(Header); asked != "" && asked != strconv.Itoa(Default) {
+			apierror.AbortCode(c, http.StatusBadRequest, CodeUnsupportedVersion,
+				fmt.Sprintf("Unsupported API version %q; supported: %s", asked, s.list()))
+			return
+		}
+		c.Set(contextKey, Default)
+		c.Header(Header, strconv.Itoa(Default))
+		c.Next()
+	}
+}
+
+func (s *Set) list() string {
+	var versions []int
+	for version := range s.versions {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	names := make([]string, len(versions))
+	for i, version := range versions {
+		names[i] = strconv.Itoa(version)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Handler returns the engine with version negotiation: a request to an
+// unversioned /api path whose X-API-Version names a registered version is
+// routed to that version's path
+func (s *Set) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if version, ok := s.asked(r); ok {
+			r.URL.Path = fmt.Sprintf("%s/v%d%s", prefix, version, strings.TrimPrefix(r.URL.Path, prefix))
+			r.URL.RawPath = ""
+		}
+		s.engine.ServeHTTP(w, r)
+	})
+}
+
+// asked returns the version an unversioned /api request asked for, if the
+// server has it and it is not the default
+func (s *Set) asked(r *http.Request) (int, bool) {
+	path := r.URL.Path
+	if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+		return 0, false
+	}
+	if rest := strings.TrimPrefix(path, prefix+"/"); strings.HasPrefix(rest, "v") {
+		if _, err := strconv.Atoi(strings.SplitN(rest[1:], "/", 2)[0]); err == nil {
+			return 0, false // already versioned
+		}
+	}
+	version, err := strconv.Atoi(r.Header.Get(Header))
+	if err != nil || version == Default || !s.versions[version] {
+		return 0, false
+	}
+	return version, true
+}
+
+// FromContext returns the API version serving a request, or 0 outside the
+// versioned API
+func FromContext(c *gin.Context) int {
+	return c.GetInt(contextKey)
+}
+
+// Path returns the path of an API route (e.g. "/leaderboard") in the
+// version serving the request, for links such as Location headers
+func Path(c *gin.Context, route string) string {
+	version := FromContext(c)
+	if version == 0 {
+		version = Default
+	}
+	return fmt.Sprintf("%s/v%d%s", prefix, version, route)
+}
@@ -0,0 +1,144 @@
+// Package auth defines the caller identity attached to each request.
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "auth.principal"
+
+// AnonymousTier is used for requests without a recognised API key
+const AnonymousTier = "free"
+
+// AdminTier grants access to the /api/admin routes
+const AdminTier = "admin"
+
+// SandboxTier routes a key's requests to the sandbox tenant
+const SandboxTier = "sandbox"
+
+// Admin roles; each may do everything the roles before it may
+const (
+	RoleViewer   = "viewer"   // read-only admin routes
+	RoleOperator = "operator" // simulator, spikes, jobs, rebuilds, reconciles, WebSocket clients
+	RoleAdmin    = "admin"    // bans, imports, reverts, protection, impersonation, resets
+)
+
+var roleRank = map[string]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ValidRole reports whether role is a known admin role
+func ValidRole(role string) bool {
+	return roleRank[role] > 0
+}
+
+// Principal identifies the caller of a request
+type Principal struct {
+	// Key identifies the caller for quotas: the API key, or "ip:<addr>" when anonymous
+	Key string
+	// Tier selects quota/queue sizes (e.g. free, standard, premium)
+	Tier string
+	// Authenticated is true when a valid API key or admin token was presented
+	Authenticated bool
+	// Role is the admin role of an admin token (admin-tier keys are admins)
+	Role string
+	// Subject names the admin of an admin token
+	Subject string
+	// UserID is the end user the game backend is acting for (0 = unknown)
+	UserID uint
+	// Impersonator is the admin viewing the API as UserID, with the
+	// session it uses (empty unless impersonating)
+	Impersonator    string
+	ImpersonationID string
+	// Tenant is the tenant whose data the request reads and writes
+	// (empty = the default tenant)
+	Tenant string
+}
+
+// SetPrincipal attaches the principal to the request context
+func SetPrincipal(c *gin.Context, p *Principal) {
+	c.Set(principalKey, p)
+}
+
+// FromContext returns the request principal (anonymous if none was set)
+func FromContext(c *gin.Context) *Principal {
+	if value, ok := c.Get(principalKey); ok {
+		if p, ok := value.(*Principal); ok {
+			return p
+		}
+	}
+	return &Principal{Key: "ip:" + c.ClientIP(), Tier: AnonymousTier}
+}
+
+// IsAdmin reports whether the principal may use admin routes
+func (p *Principal) IsAdmin() bool {
+	return p.AdminRole() != ""
+}
+
+// AdminRole returns the principal's admin role ("" = none)
+func (p *Principal) AdminRole() string {
+	switch {
+	case !p.Authenticated:
+		return ""
+	case p.Role != "":
+		return p.Role
+	case p.Tier == AdminTier:
+		return RoleAdmin
+	}
+	return ""
+}
+
+// HasRole reports whether the principal's admin role includes role
+func (p *Principal) HasRole(role string) bool {
+	return roleRank[p.AdminRole()] >= roleRank[role] && roleRank[role] > 0
+}
+
+// IsSandbox reports whether the principal is served by the sandbox tenant
+func (p *Principal) IsSandbox() bool {
+	return p.Authenticated && p.Tier == SandboxTier
+}
+
+// IsTenant reports whether the principal is served by a named tenant
+func (p *Principal) IsTenant() bool {
+	return p.Tenant != ""
+}
+
+// IsImpersonated reports whether an admin is acting as UserID
+func (p *Principal) IsImpersonated() bool {
+	return p.Impersonator != ""
+}
+
+// ActsFor reports whether the request is made as userID: by a keyed caller
+// acting for it, or by an impersonation session of it
+func (p *Principal) ActsFor(userID uint) bool {
+	return userID != 0 && p.UserID == userID && (p.Authenticated || p.IsImpersonated())
+}
+
+// Fingerprint identifies the caller's quota key without exposing the raw
+// API key: "key:<fingerprint>", "jwt:<subject>" for admin tokens, or the
+// key itself when anonymous
+func (p *Principal) Fingerprint() string {
+	if !p.Authenticated {
+		return p.Key
+	}
+	if p.Subject != "" {
+		return "jwt:" + p.Subject
+	}
+	sum := sha256.Sum256([]byte(p.Key))
+	return "key:" + hex.EncodeToString(sum[:6])
+}
+
+// Actor identifies the caller in audit records without exposing the raw
+// API key: "key:<fingerprint>" or "ip:<addr>", plus the acting user if known
+func (p *Principal) Actor() string {
+	if p.IsImpersonated() {
+		return fmt.Sprintf("%s/impersonating:%d", p.Impersonator, p.UserID)
+	}
+	actor := p.Fingerprint()
+	if p.UserID != 0 {
+		actor = fmt.Sprintf("%s/user:%d", actor, p.UserID)
+	}
+	return actor
+}
@@ -1,20 +1,72 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/scheduler"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Env      string 
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	App      AppConfig
+	Env         string
+	Server      ServerConfig
+	Log         LogConfig
+	Compression CompressionConfig
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	App         AppConfig
+	Secrets     SecretsConfig
+	Canary      CanaryConfig
+	History     HistoryConfig
+	Digest      DigestConfig
+	Auth        AuthConfig
+	FairQueue   FairQueueConfig
+	Spike       SpikeConfig
+	AntiCheat   AntiCheatConfig
+	RatingLimit RatingLimitConfig
+	Periods     PeriodConfig
+	Search      SearchConfig
+	Import      ImportConfig
+	Jobs        JobConfig
+	Scheduler   SchedulerConfig
+	Ingest      IngestConfig
+	Integrity   IntegrityConfig
+	Simulator   SimulatorConfig
+	Streams     StreamConfig
+	Events      EventConfig
+	Sandbox     SandboxConfig
+	Tenants     TenantConfig
+	Impersonate ImpersonationConfig
+	RateLimit   RateLimitConfig
+	DBSync      DBSyncConfig
+	Notify      NotificationConfig
+	Webhooks    WebhookConfig
+	Milestones  MilestoneConfig
+	Achievement AchievementConfig
+	Streaks     StreakConfig
+	TopDiff     TopDiffConfig
+	WebSocket   WebSocketConfig
+	Tiers       TierConfig
+	Teams       TeamConfig
+	Matchmaking MatchmakingConfig
+	Decay       DecayConfig
+	Benchmark   BenchmarkConfig
+	Ranking     RankingConfig
+	Fallback    FallbackConfig
+	Rebuild     RebuildConfig
+	Reconcile   ReconcileConfig
+	PGOutage    PostgresOutageConfig
 }
 
 type ServerConfig struct {
@@ -22,21 +74,618 @@ type ServerConfig struct {
 	GinMode string
 }
 
+// LogConfig sets the server's log verbosity and output format
+type LogConfig struct {
+	Level  string // debug, info, warn or error
+	Format string // LogFormatText or LogFormatJSON
+}
+
+// CompressionConfig controls gzip compression of API responses
+type CompressionConfig struct {
+	Enabled  bool
+	MinBytes int // smaller responses are sent as they are
+	Level    int // gzip level, 1 (fastest) to 9 (smallest)
+}
+
+// Log output formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json" // one object per line, for log collectors
+)
+
 type DatabaseConfig struct {
 	URL string
+
+	// Read replicas for read-only queries (search, exports, history);
+	// writes and the DB sync stay on URL
+	ReplicaURLs []string
+
+	// TLS: sslmode (disable, require, verify-ca, verify-full) and the CA
+	// bundle for the verify modes; empty keeps what the URL says
+	SSLMode     string
+	SSLRootCert string
+
+	// Pool of each connection (primary and every replica)
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration // 0 = connections are reused forever
+	ConnMaxIdleTime time.Duration // 0 = idle connections are kept
+
+	ConnectTimeout   time.Duration // 0 = wait as long as the OS does
+	StatementTimeout time.Duration // server-side cap per statement (0 = none)
+
+	LogLevel      string        // GORM logger: silent, error, warn or info
+	SlowThreshold time.Duration // queries slower than this are logged at warn
+
+	// PasswordFunc, when set, supplies the password for every new
+	// connection (used for secret-manager rotation). Not env-driven.
+	PasswordFunc func() string
 }
 
+// GORM log levels
+const (
+	DBLogSilent = "silent"
+	DBLogError  = "error"
+	DBLogWarn   = "warn"
+	DBLogInfo   = "info" // every statement
+)
+
 type RedisConfig struct {
 	Host     string
 	Port     string
+	Username string // ACL user (empty = default user)
 	Password string
 	DB       int
+
+	// TLS for managed instances; CA cert is a PEM file (empty = system roots)
+	TLS           bool
+	TLSCACert     string
+	TLSSkipVerify bool
+
+	PoolSize     int
+	MinIdleConns int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration // blocking stream reads add their block time
+	WriteTimeout time.Duration
+	PoolTimeout  time.Duration // wait for a free connection when the pool is exhausted
+
+	// PasswordFunc, when set, overrides Password on every new connection
+	PasswordFunc func() string
 }
 
 type AppConfig struct {
 	AllowedOrigins      []string
 	ScoreUpdateInterval time.Duration
 	MaxSearchResults    int
+
+	// Shared worker pool for per-item work on large pages (username
+	// enrichment, rank lookups); one request uses at most EnrichParallelism
+	EnrichWorkers     int
+	EnrichParallelism int
+}
+
+// SecretsConfig selects where sensitive values come from. Each *Ref is a
+// provider-specific reference: an env var name for "env", a secret id for
+// "aws"/"gcp" and a KV path for "vault" (append "#field" for JSON secrets).
+type SecretsConfig struct {
+	Provider        string // env | aws | gcp | vault
+	RefreshInterval time.Duration
+
+	DBPasswordRef    string
+	RedisPasswordRef string
+	JWTSecretRef     string
+	HMACSecretRef    string
+
+	AWSRegion  string
+	GCPProject string
+	VaultAddr  string
+	VaultToken string
+	VaultMount string
+}
+
+// CanaryConfig controls the synthetic end-to-end latency probe
+type CanaryConfig struct {
+	Enabled            bool
+	Interval           time.Duration
+	Username           string
+	BroadcastThreshold time.Duration // max update -> pub/sub receipt
+	CommitThreshold    time.Duration // max update -> Postgres commit
+}
+
+// HistoryConfig controls rank snapshots and history retention
+type HistoryConfig struct {
+	RankSnapshotInterval time.Duration
+	RankSnapshotTopN     int
+	RankRetention        time.Duration
+
+	// Users outside the top N are snapshotted once their history is
+	// requested: at most RankTrackedMax of them (0 = none), each for
+	// RankTrackedTTL after their last request
+	RankTrackedMax int
+	RankTrackedTTL time.Duration
+
+	// Raw score_updates older than CompactAfter are rolled into daily aggregates
+	CompactAfter       time.Duration
+	CompactionInterval time.Duration
+}
+
+// DigestConfig controls the daily rank-change digest
+type DigestConfig struct {
+	Enabled       bool
+	SendHour      int           // local hour (0-23) in each user's timezone
+	CheckInterval time.Duration // how often due timezones are evaluated
+	BatchSize     int
+	Workers       int
+	NearbyRadius  int
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
+	SMTPFrom      string
+}
+
+// NotificationConfig holds a tenant's notification defaults: what users who
+// saved no preferences get, and how score update notifications are sent
+type NotificationConfig struct {
+	DefaultEvents   []string // daily_digest, score_update
+	DefaultChannels []string // webhook, email
+	QuietHours      string   // "22:00-07:00" in the user's timezone, empty = none
+	CacheTTL        time.Duration
+	Workers         int // score update notification senders
+	QueueSize       int
+}
+
+// WebhookConfig controls delivery of admin-registered webhook subscriptions.
+// Deliveries are queued in PostgreSQL, so any server's worker may send them.
+type WebhookConfig struct {
+	Enabled      bool
+	Workers      int           // concurrent deliveries per server
+	PollEvery    time.Duration // how often due deliveries are claimed
+	Timeout      time.Duration // per POST
+	MaxAttempts  int           // attempts before a delivery is marked failed
+	Backoff      time.Duration // delay before the first retry, doubled after each
+	MaxBackoff   time.Duration
+	RefreshEvery time.Duration // reload of subscriptions changed on other servers
+	QueueSize    int           // matched events waiting to be stored
+	Retention    time.Duration // how long the delivery log is kept (0 = forever)
+}
+
+// AchievementConfig sets the thresholds of the achievements users unlock
+// through score updates
+type AchievementConfig struct {
+	Enabled bool
+	Rating  int // high_rating: rating to reach
+	Streak  int // win_streak: rating gains in a row
+}
+
+// StreakConfig turns daily improvement streaks on or off
+type StreakConfig struct {
+	Enabled bool
+}
+
+// TopDiffConfig controls the leaderboard_diff messages patching the top N
+// shown by WebSocket clients
+type TopDiffConfig struct {
+	Enabled  bool
+	Size     int           // N
+	Interval time.Duration // how often changes are folded into one diff
+	Resync   time.Duration // diff even without events this often (0 = only after events)
+}
+
+// Slow WebSocket client policies, applied when a client's send buffer is
+// full
+const (
+	SlowClientDisconnect = "disconnect"  // close the connection
+	SlowClientDropOldest = "drop_oldest" // discard the oldest queued message
+	SlowClientLagged     = "lagged"      // discard the queue and send a lagged notice
+)
+
+// WebSocketConfig controls how score updates are framed for WebSocket
+// clients
+type WebSocketConfig struct {
+	CoalesceWindow time.Duration // batch score updates over this window (0 = one frame per update)
+	MaxBatch       int           // updates that flush a batch early
+
+	// Updates sent to every client; the rest only reach clients watching
+	// the user. Both 0 = every update is public.
+	BroadcastTopN int // updates moving a user into, within or out of the top N
+	MinRankDelta  int // updates moving a user at least this many places
+	MaxWatch      int // users one client may watch
+
+	Shards int // hub shards, each delivering to its clients on its own goroutine
+
+	SendBuffer       int    // messages queued per client
+	SlowClientPolicy string // disconnect | drop_oldest | lagged
+
+	// Concurrent connections accepted by one server (0 = no limit)
+	MaxConnections      int
+	MaxConnectionsPerIP int
+
+	// Browser origins that may open a connection: "*", exact origins or
+	// https://*.example.com. Same-origin and non-browser clients (no Origin
+	// header) are always allowed.
+	AllowedOrigins []string
+
+	StatsInterval time.Duration // how often each server shares its client count
+
+	// permessage-deflate, for clients that offer it: messages of at least
+	// CompressionMinSize bytes are compressed at CompressionLevel (1-9)
+	Compression        bool
+	CompressionLevel   int
+	CompressionMinSize int
+}
+
+// MilestoneConfig says which score updates also emit a milestone event
+type MilestoneConfig struct {
+	Ranks        []int // entering the top N for each N listed (empty = none)
+	PersonalBest bool  // a user's highest rating so far
+	Streaks      []int // reaching a daily improvement streak of N days (empty = none)
+}
+
+// TierConfig names the rating tiers shown next to a player's rating
+type TierConfig struct {
+	Tiers     []string // "Name:min_rating", ascending (empty = no tiers)
+	Divisions int      // divisions per tier, except the top one
+}
+
+// Team score aggregations
+const (
+	TeamScoreSum     = "sum"
+	TeamScoreAverage = "average"
+)
+
+// DecayConfig lowers the ratings of players who stopped playing, so the top
+// of the board reflects active players
+type DecayConfig struct {
+	Enabled       bool
+	Schedule      string         // when decay runs (cron, @daily or @every <duration>)
+	InactiveAfter time.Duration  // no score update for this long = inactive
+	Amount        int            // rating taken per run
+	Floor         int            // no rating decays below this
+	TierFloors    map[string]int // tier name -> rating its players never decay below
+	BatchSize     int            // users per pipelined batch
+}
+
+// TeamConfig sets how team scores are aggregated from member ratings
+type TeamConfig struct {
+	Scoring     string        // sum or average of the ranked members' ratings
+	MaxMembers  int           // members per team (0 = unlimited)
+	ResyncEvery time.Duration // full rebuild of team scores from PostgreSQL (0 = only at startup)
+}
+
+// MatchmakingConfig bounds opponent suggestions
+type MatchmakingConfig struct {
+	Range        int           // default rating band either side of the player
+	MaxRange     int           // largest band a request may ask for
+	Count        int           // default number of candidates
+	MaxCount     int           // most candidates a request may ask for
+	RecentWindow time.Duration // how long after a match the pair is not suggested again (0 = never excluded)
+}
+
+// AuthConfig maps API keys to their quota tier and verifies admin JWTs
+type AuthConfig struct {
+	APIKeys map[string]string // key -> tier
+
+	// HS256 secret of admin bearer tokens (empty = only admin-tier API keys)
+	AdminJWTSecret string
+	AdminJWTIssuer string // required iss claim (empty = any)
+}
+
+// FairQueueConfig sizes the weighted fair queue around expensive operations.
+// Each API key may hold up to its tier's weight in concurrent slots; when the
+// global capacity is contended, waiting keys are served in weighted order.
+type FairQueueConfig struct {
+	Capacity    int
+	WaitTimeout time.Duration
+	TierWeights map[string]int
+}
+
+// RateLimitConfig sets per-tier request quotas (requests per Window, shared
+// by all servers). Tiers not listed get the free tier's limit; 0 = unlimited.
+type RateLimitConfig struct {
+	Enabled    bool
+	Window     time.Duration
+	TierLimits map[string]int
+}
+
+// SpikeConfig bounds admin-triggered traffic spikes (capacity rehearsals)
+type SpikeConfig struct {
+	MaxMultiplier int
+	MaxDuration   time.Duration
+	Workers       int // concurrent score updates while spiking
+}
+
+// AntiCheatConfig sets the thresholds of the score anomaly detectors
+type AntiCheatConfig struct {
+	Enabled             bool
+	MaxRatingJump       int // max rating change in one update (0 disables)
+	MaxUpdatesPerMinute int // max updates per user per minute (0 disables)
+	QueueSize           int // pending inspections before updates are skipped
+}
+
+// Rating limit modes
+const (
+	RatingLimitReject = "reject"
+	RatingLimitClamp  = "clamp"
+)
+
+// RatingLimitConfig caps how far a rating may move, per update and per
+// rolling window (net change, tracked in Redis); 0 disables a limit
+type RatingLimitConfig struct {
+	MaxPerUpdate int
+	MaxPerWindow int
+	Window       time.Duration
+	Mode         string // reject | clamp
+}
+
+// SearchConfig controls how username search matches and orders users
+type SearchConfig struct {
+	SimilarityThreshold float64 // pg_trgm similarity (0-1) a non-substring match needs
+	SimilarityWeight    float64 // share of similarity vs rating in the order (0-1)
+}
+
+// PeriodConfig controls the daily/weekly gain boards
+type PeriodConfig struct {
+	LocalTime bool          // bucket by each user's timezone instead of UTC
+	Retention time.Duration // how long a finished period stays readable
+
+	// Most improved board: rating gained over a rolling window of hours
+	ImprovedWindow   time.Duration
+	ImprovedCacheTTL time.Duration // how long a computed board is reused
+}
+
+// ImportConfig bounds admin score uploads
+type ImportConfig struct {
+	MaxBytes int64 // upload size limit
+	MaxRows  int
+}
+
+// JobConfig sizes the admin job worker pool
+type JobConfig struct {
+	Node             string // identifies this server on jobs it runs
+	Workers          int
+	QueueSize        int
+	ProgressInterval time.Duration // how often progress is saved and cancellation checked
+}
+
+// SchedulerConfig controls the periodic jobs (decay, reconcile, snapshots,
+// compaction) run once per occurrence across all servers
+type SchedulerConfig struct {
+	Enabled bool // false = this server never runs scheduled jobs
+}
+
+// IngestConfig controls 202-accepted score updates
+type IngestConfig struct {
+	DefaultAsync bool          // queue score updates unless ?async=false
+	DefaultFast  bool          // answer before ranks are computed unless ?fast=false
+	BatchSize    int           // stream entries read per round
+	MaxQueued    int64         // approximate stream length cap
+	StatusTTL    time.Duration // how long tracking IDs stay readable
+
+	// Fast-mode rank enrichment: updates of one user always go to the
+	// same worker so their events stay in order
+	EnrichWorkers int
+	EnrichQueue   int // per worker; when full the request enriches inline
+}
+
+// IntegrityConfig controls the periodic leaderboard checksum
+type IntegrityConfig struct {
+	Interval  time.Duration // 0 disables; runs are aligned so servers check together
+	ChunkSize int           // user IDs per chunk hash
+	Settle    time.Duration // wait before re-checking differing users (> DB sync lag)
+}
+
+// Simulator score models
+const (
+	SimModelWalk = "walk" // bounded random delta, reverting toward a mean
+	SimModelElo  = "elo"  // matches won by hidden skill, scored by Elo
+)
+
+// Win probability curves of the simulator's score model
+const (
+	WinCurveLogistic = "logistic" // Elo: 1 / (1 + 10^(-diff/scale))
+	WinCurveNormal   = "normal"   // Thurstone: Φ(diff / scale)
+	WinCurveFlat     = "flat"     // always 50%: pure random walk
+)
+
+// SimulatorConfig shapes the simulated matches behind simulator and spike
+// score updates
+type SimulatorConfig struct {
+	Model string // walk | elo
+
+	// walk: each update moves the rating by at most MaxDelta, with a pull
+	// toward ReversionMean sized so ratings settle at about
+	// ReversionMean ± ReversionSpread (0 = no pull: pure random walk)
+	MaxDelta        int
+	ReversionMean   float64
+	ReversionSpread float64
+
+	// elo
+	KFactor        int     // rating points at stake per match (volatility)
+	WinCurve       string  // logistic | normal | flat
+	CurveScale     float64 // rating difference that sets the curve's steepness
+	SkillMean      float64 // hidden skill each user's rating drifts toward
+	SkillSpread    float64 // stddev of hidden skill across users (0 = everyone equal)
+	OpponentSpread float64 // stddev of the opponent's rating around the player's
+
+	// Runtime defaults until an admin changes them (shared through Redis)
+	Enabled     bool
+	Interval    time.Duration // between ticks
+	Concurrency int           // parallel workers
+	Burst       int           // updates per tick, shared by the workers (0 = one per worker)
+	Users       int           // random user pool sampled at start (0 = any active user)
+
+	MaxConcurrency int
+	MaxBurst       int
+	MaxUsers       int
+	SyncEvery      time.Duration // how often servers pick up changed settings
+}
+
+// StreamConfig names this process in the Redis stream consumer groups and
+// sets how long processed DB sync events are kept
+type StreamConfig struct {
+	Consumer       string        // unique per process; defaults to hostname-pid
+	ConsumerExpiry time.Duration // idle consumers with nothing pending are removed after this
+
+	// Processed events beyond either limit are trimmed (0 = no limit);
+	// unprocessed ones are never trimmed
+	RetainEntries int64
+	RetainAge     time.Duration
+
+	// Shutdown waits this long for the DB sync batch in flight
+	DrainTimeout time.Duration
+}
+
+// Event transports
+const (
+	EventTransportPubSub = "pubsub" // Redis Pub/Sub: fire-and-forget
+	EventTransportStream = "stream" // Redis Stream: servers catch up after a disconnect
+	EventTransportNATS   = "nats"   // NATS core publish/subscribe: fire-and-forget
+	EventTransportKafka  = "kafka"  // Kafka topic: servers catch up after a disconnect
+)
+
+// EventConfig chooses how events reach every server
+type EventConfig struct {
+	Transport    string // pubsub | stream | nats | kafka
+	StreamMaxLen int64  // events the stream keeps, i.e. how far behind a server may fall
+
+	NATSURL     string // nats://[user:pass@]host:4222 or tls://...
+	NATSSubject string
+
+	KafkaBrokers []string // host:9092
+	KafkaTopic   string
+}
+
+var kafkaTopic = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,200}$`)
+
+// DBSyncConfig sizes the batches the DB sync worker writes to PostgreSQL
+type DBSyncConfig struct {
+	BatchSize     int           // events per transaction (max 10000)
+	FlushInterval time.Duration // wait to fill a batch once an event arrived (0 = write what is there)
+	StatusEvery   time.Duration // refresh of the db_sync_* gauges
+}
+
+// SandboxConfig isolates partner test traffic: sandbox API keys hit the
+// same binary but a prefixed Redis keyspace and a separate Postgres schema
+type SandboxConfig struct {
+	Enabled   bool
+	KeyPrefix string
+	Schema    string
+	Notify    NotificationConfig // defaults for sandbox users
+	Ranking   RankingConfig      // the sandbox board's strategy
+}
+
+// TenantConfig hosts isolated leaderboards for several games or customers
+// in one deployment. Each tenant gets its own Redis key prefix and Postgres
+// schema, like the sandbox.
+type TenantConfig struct {
+	IDs     []string          // tenant IDs (lower-case letters, digits, underscores)
+	APIKeys map[string]string // key -> tenant; these keys always act for that tenant
+	Header  bool              // let other callers pick a tenant with X-Tenant-ID
+}
+
+// KeyPrefix is the Redis key prefix of a tenant
+func (TenantConfig) KeyPrefix(id string) string {
+	return "tenant:" + id + ":"
+}
+
+// Schema is the Postgres schema of a tenant
+func (TenantConfig) Schema(id string) string {
+	return "tenant_" + id
+}
+
+// Has reports whether id is a configured tenant
+func (t TenantConfig) Has(id string) bool {
+	for _, known := range t.IDs {
+		if known == id {
+			return true
+		}
+	}
+	return false
+}
+
+var tenantID = regexp.MustCompile(`^[a-z0-9][a-z0-9_]{0,31}$`)
+
+// ImpersonationConfig bounds support impersonation sessions
+type ImpersonationConfig struct {
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+}
+
+// RankingConfig selects and tunes the strategy that turns submitted
+// results into ratings on a board
+type RankingConfig struct {
+	Strategy       string  // absolute, delta, elo, glicko, trueskill
+	KFactor        float64 // Elo: points at stake per match
+	Scale          float64 // Elo: rating difference for 10:1 odds
+	GlickoRD       float64 // Glicko: deviation of a new player
+	GlickoMinRD    float64 // Glicko: deviation never shrinks below this
+	TrueSkillSigma float64 // TrueSkill: sigma of a new player
+	TrueSkillBeta  float64 // TrueSkill: performance variability
+	TrueSkillTau   float64 // TrueSkill: sigma added before each match
+}
+
+// FallbackConfig controls degraded mode: serving reads from PostgreSQL and
+// queueing score writes while Redis is unreachable
+type FallbackConfig struct {
+	Enabled     bool
+	CheckEvery  time.Duration // Redis ping interval
+	FailAfter   int           // consecutive failed pings before degrading
+	ReplayEvery time.Duration // how often queued writes are replayed once Redis is back
+	ReplayBatch int           // queued writes replayed per transaction
+}
+
+// RebuildConfig controls repopulating Redis from PostgreSQL
+type RebuildConfig struct {
+	OnStart   bool          // rebuild when the server starts with an empty leaderboard
+	BatchSize int           // users per pipelined batch
+	Wait      time.Duration // how long a starting server waits for another's rebuild
+	Swap      bool          // build overwrites under staging keys, then RENAME them over the boards
+
+	ArchiveTTL time.Duration // how long a reset keeps the archived board (0 = forever)
+}
+
+// Reconcile scan modes
+const (
+	ReconcileSample = "sample"
+	ReconcileFull   = "full"
+)
+
+// Reconcile sources of truth for rating drift
+const (
+	ReconcileFromRedis    = "redis"    // PostgreSQL ratings are set to Redis's
+	ReconcileFromPostgres = "postgres" // Redis ratings are set to PostgreSQL's
+	ReconcileReportOnly   = "none"     // nothing is repaired
+)
+
+// ReconcileConfig controls the periodic Redis vs PostgreSQL reconciler
+type ReconcileConfig struct {
+	Interval   time.Duration // 0 disables scheduled runs (admin runs still work)
+	Mode       string        // sample or full
+	SampleSize int           // users read from each side per sampled run
+	Source     string        // redis, postgres or none
+	Settle     time.Duration // wait before re-checking differing users (> DB sync lag)
+	BatchSize  int           // users per page in full scans
+}
+
+// PostgresOutageConfig controls riding out a PostgreSQL outage: score
+// writes keep landing in Redis and the DB sync stream, and the backlog is
+// drained with adaptive batches once PostgreSQL is back
+type PostgresOutageConfig struct {
+	Enabled        bool
+	CheckEvery     time.Duration // PostgreSQL ping interval
+	FailAfter      int           // consecutive failed pings before the DB sync pauses
+	BackpressureAt int64         // backlog above which write responses ask clients to slow down
+	MaxBacklog     int64         // backlog at which score writes are refused (0 = never)
+	TargetCommit   time.Duration // catch-up batches grow while commits take less than this
+	MaxBatch       int           // largest catch-up batch (max 10000)
+}
+
+// BenchmarkConfig sizes the admin data store benchmark
+type BenchmarkConfig struct {
+	Ops           int           // operations per benchmark step
+	DegradedRatio float64       // p50 this many times the baseline's = degraded
+	Timeout       time.Duration // whole run
 }
 
 var AppCfg *Config
@@ -44,33 +693,378 @@ var AppCfg *Config
 func LoadConfig() *Config {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
+		slog.Info("No .env file found, using environment variables")
+	}
+
+
+	env := getEnv("APP_ENV", "development")
+
+	// Browser origins allowed by CORS and, unless WS_ALLOWED_ORIGINS is
+	// set, WebSocket upgrades
+	allowedOrigins := getEnvList("ALLOWED_ORIGINS", []string{
+		"http://localhost:8081",
+		"http://localhost:19006",
+		"https://yourdomain.vercel.app",
+	})
+
+	// Every SQL statement is logged in development only
+	dbLogLevel := DBLogWarn
+	if env == "development" {
+		dbLogLevel = DBLogInfo
 	}
 
+	// Log collectors in production read JSON; people read text
+	logFormat := LogFormatText
+	if env == "production" {
+		logFormat = LogFormatJSON
+	}
 
 	cfg := &Config{
-		Env:  getEnv("APP_ENV", "development"),
+		Env: env,
 		Server: ServerConfig{
 			Port:    getEnv("PORT", "8080"),
 			GinMode: getEnv("GIN_MODE", "debug"),
 		},
+		Log: LogConfig{
+			Level:  getEnv("LOG_LEVEL", "info"),
+			Format: getEnv("LOG_FORMAT", logFormat),
+		},
+		Compression: CompressionConfig{
+			Enabled:  getEnvBool("COMPRESSION_ENABLED", true),
+			MinBytes: getEnvInt("COMPRESSION_MIN_BYTES", 1024),
+			Level:    getEnvInt("COMPRESSION_LEVEL", 5),
+		},
 		Database: DatabaseConfig{
-			URL: getEnv("DB_URL", "localhost"),
+			URL:         getEnv("DB_URL", "localhost"),
+			ReplicaURLs: getEnvList("DB_REPLICA_URL", nil),
+			SSLMode:     getEnv("DB_SSLMODE", ""),
+			SSLRootCert: getEnv("DB_SSLROOTCERT", ""),
+
+			MaxOpenConns:     getEnvInt("DB_MAX_OPEN_CONNS", 100),
+			MaxIdleConns:     getEnvInt("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime:  getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			ConnMaxIdleTime:  getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+			ConnectTimeout:   getEnvDuration("DB_CONNECT_TIMEOUT", 5*time.Second),
+			StatementTimeout: getEnvDuration("DB_STATEMENT_TIMEOUT", 0),
+			LogLevel:         getEnv("DB_LOG_LEVEL", dbLogLevel),
+			SlowThreshold:    getEnvDuration("DB_SLOW_QUERY", 200*time.Millisecond),
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnv("REDIS_PORT", "6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       0,
+			Host:          getEnv("REDIS_HOST", "localhost"),
+			Port:          getEnv("REDIS_PORT", "6379"),
+			Username:      getEnv("REDIS_USERNAME", ""),
+			Password:      getEnv("REDIS_PASSWORD", ""),
+			DB:            0,
+			TLS:           getEnvBool("REDIS_TLS", false),
+			TLSCACert:     getEnv("REDIS_TLS_CA_CERT", ""),
+			TLSSkipVerify: getEnvBool("REDIS_TLS_SKIP_VERIFY", false),
+
+			PoolSize:     getEnvInt("REDIS_POOL_SIZE", 20),
+			MinIdleConns: getEnvInt("REDIS_MIN_IDLE_CONNS", 2),
+			DialTimeout:  getEnvDuration("REDIS_DIAL_TIMEOUT", 5*time.Second),
+			ReadTimeout:  getEnvDuration("REDIS_READ_TIMEOUT", 3*time.Second),
+			WriteTimeout: getEnvDuration("REDIS_WRITE_TIMEOUT", 3*time.Second),
+			PoolTimeout:  getEnvDuration("REDIS_POOL_TIMEOUT", 4*time.Second),
 		},
 		App: AppConfig{
-			AllowedOrigins: []string{
-				"http://localhost:8081",
-				"http://localhost:19006",
-				"https://yourdomain.vercel.app",
-			},
-			ScoreUpdateInterval: 3 * time.Second,
+			AllowedOrigins:      allowedOrigins,
+			ScoreUpdateInterval: getEnvDuration("SCORE_UPDATE_INTERVAL", 3*time.Second),
 			MaxSearchResults:    100,
+			EnrichWorkers:       getEnvInt("ENRICH_WORKERS", 32),
+			EnrichParallelism:   getEnvInt("ENRICH_PARALLELISM", 8),
+		},
+		Secrets: SecretsConfig{
+			Provider:         getEnv("SECRETS_PROVIDER", "env"),
+			RefreshInterval:  getEnvDuration("SECRETS_REFRESH_INTERVAL", 0),
+			DBPasswordRef:    getEnv("SECRET_DB_PASSWORD_REF", "DB_PASSWORD"),
+			RedisPasswordRef: getEnv("SECRET_REDIS_PASSWORD_REF", "REDIS_PASSWORD"),
+			JWTSecretRef:     getEnv("SECRET_JWT_REF", "JWT_SECRET"),
+			HMACSecretRef:    getEnv("SECRET_HMAC_REF", "HMAC_SECRET"),
+			AWSRegion:        getEnv("SECRETS_AWS_REGION", os.Getenv("AWS_REGION")),
+			GCPProject:       getEnv("SECRETS_GCP_PROJECT", ""),
+			VaultAddr:        getEnv("VAULT_ADDR", ""),
+			VaultToken:       getEnv("VAULT_TOKEN", ""),
+			VaultMount:       getEnv("VAULT_MOUNT", "secret"),
+		},
+		Canary: CanaryConfig{
+			Enabled:            getEnvBool("CANARY_ENABLED", false),
+			Interval:           getEnvDuration("CANARY_INTERVAL", 30*time.Second),
+			Username:           getEnv("CANARY_USERNAME", "__canary__"),
+			BroadcastThreshold: getEnvDuration("CANARY_BROADCAST_THRESHOLD", 500*time.Millisecond),
+			CommitThreshold:    getEnvDuration("CANARY_COMMIT_THRESHOLD", 10*time.Second),
+		},
+		History: HistoryConfig{
+			RankSnapshotInterval: getEnvDuration("RANK_SNAPSHOT_INTERVAL", 5*time.Minute),
+			RankSnapshotTopN:     getEnvInt("RANK_SNAPSHOT_TOP_N", 1000),
+			RankRetention:        getEnvDuration("RANK_HISTORY_RETENTION", 30*24*time.Hour),
+			RankTrackedMax:       getEnvInt("RANK_TRACKED_MAX", 10000),
+			RankTrackedTTL:       getEnvDuration("RANK_TRACKED_TTL", 7*24*time.Hour),
+			CompactAfter:         getEnvDuration("SCORE_COMPACT_AFTER", 7*24*time.Hour),
+			CompactionInterval:   getEnvDuration("SCORE_COMPACTION_INTERVAL", time.Hour),
+		},
+		Digest: DigestConfig{
+			Enabled:       getEnvBool("DIGEST_ENABLED", false),
+			SendHour:      getEnvInt("DIGEST_SEND_HOUR", 9),
+			CheckInterval: getEnvDuration("DIGEST_CHECK_INTERVAL", 5*time.Minute),
+			BatchSize:     getEnvInt("DIGEST_BATCH_SIZE", 100),
+			Workers:       getEnvInt("DIGEST_WORKERS", 4),
+			NearbyRadius:  getEnvInt("DIGEST_NEARBY_RADIUS", 3),
+			SMTPHost:      getEnv("SMTP_HOST", ""),
+			SMTPPort:      getEnv("SMTP_PORT", "587"),
+			SMTPUsername:  getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:  getEnv("SMTP_PASSWORD", ""),
+			SMTPFrom:      getEnv("SMTP_FROM", "leaderboard@localhost"),
+		},
+		Auth: AuthConfig{
+			APIKeys:        getEnvMap("API_KEYS"),
+			AdminJWTSecret: getEnv("ADMIN_JWT_SECRET", ""),
+			AdminJWTIssuer: getEnv("ADMIN_JWT_ISSUER", ""),
+		},
+		Spike: SpikeConfig{
+			MaxMultiplier: getEnvInt("SPIKE_MAX_MULTIPLIER", 1000),
+			MaxDuration:   getEnvDuration("SPIKE_MAX_DURATION", 15*time.Minute),
+			Workers:       getEnvInt("SPIKE_WORKERS", 32),
+		},
+		AntiCheat: AntiCheatConfig{
+			Enabled:             getEnvBool("ANTICHEAT_ENABLED", true),
+			MaxRatingJump:       getEnvInt("ANTICHEAT_MAX_RATING_JUMP", 1000),
+			MaxUpdatesPerMinute: getEnvInt("ANTICHEAT_MAX_UPDATES_PER_MINUTE", 30),
+			QueueSize:           getEnvInt("ANTICHEAT_QUEUE_SIZE", 10000),
+		},
+		RatingLimit: RatingLimitConfig{
+			MaxPerUpdate: getEnvInt("RATING_MAX_DELTA_PER_UPDATE", 500),
+			MaxPerWindow: getEnvInt("RATING_MAX_DELTA_PER_WINDOW", 1500),
+			Window:       getEnvDuration("RATING_DELTA_WINDOW", time.Hour),
+			Mode:         getEnv("RATING_LIMIT_MODE", RatingLimitReject),
+		},
+		Periods: PeriodConfig{
+			LocalTime: getEnvBool("PERIOD_BOARDS_LOCAL_TIME", true),
+			Retention: getEnvDuration("PERIOD_BOARD_RETENTION", 8*24*time.Hour),
+
+			ImprovedWindow:   getEnvDuration("MOST_IMPROVED_WINDOW", 24*time.Hour),
+			ImprovedCacheTTL: getEnvDuration("MOST_IMPROVED_CACHE_TTL", 30*time.Second),
+		},
+		Search: SearchConfig{
+			SimilarityThreshold: getEnvFloat("SEARCH_SIMILARITY_THRESHOLD", 0.3),
+			SimilarityWeight:    getEnvFloat("SEARCH_SIMILARITY_WEIGHT", 0.7),
+		},
+		Import: ImportConfig{
+			MaxBytes: int64(getEnvInt("IMPORT_MAX_BYTES", 20<<20)),
+			MaxRows:  getEnvInt("IMPORT_MAX_ROWS", 100000),
+		},
+		Jobs: JobConfig{
+			Node:             getEnv("NODE_NAME", hostname()),
+			Workers:          getEnvInt("JOB_WORKERS", 2),
+			QueueSize:        getEnvInt("JOB_QUEUE_SIZE", 100),
+			ProgressInterval: getEnvDuration("JOB_PROGRESS_INTERVAL", time.Second),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled: getEnvBool("SCHEDULER_ENABLED", true),
+		},
+		Ingest: IngestConfig{
+			DefaultAsync:  getEnv("SCORE_UPDATE_MODE", "sync") == "async",
+			DefaultFast:   getEnv("SCORE_UPDATE_MODE", "sync") == "fast",
+			BatchSize:     getEnvInt("INGEST_BATCH_SIZE", 100),
+			MaxQueued:     int64(getEnvInt("INGEST_MAX_QUEUED", 1000000)),
+			StatusTTL:     getEnvDuration("INGEST_STATUS_TTL", time.Hour),
+			EnrichWorkers: getEnvInt("FAST_ENRICH_WORKERS", 4),
+			EnrichQueue:   getEnvInt("FAST_ENRICH_QUEUE", 1000),
+		},
+		Simulator: SimulatorConfig{
+			Model:           getEnv("SIM_MODEL", SimModelWalk),
+			MaxDelta:        getEnvInt("SIM_MAX_DELTA", 25),
+			ReversionMean:   getEnvFloat("SIM_REVERSION_MEAN", 2500),
+			ReversionSpread: getEnvFloat("SIM_REVERSION_SPREAD", 800),
+
+			KFactor:        getEnvInt("SIM_K_FACTOR", 32),
+			WinCurve:       getEnv("SIM_WIN_CURVE", WinCurveLogistic),
+			CurveScale:     getEnvFloat("SIM_CURVE_SCALE", 400),
+			SkillMean:      getEnvFloat("SIM_SKILL_MEAN", 1500),
+			SkillSpread:    getEnvFloat("SIM_SKILL_SPREAD", 350),
+			OpponentSpread: getEnvFloat("SIM_OPPONENT_SPREAD", 150),
+
+			Enabled:        getEnvBool("SIMULATOR_ENABLED", true),
+			Interval:       getEnvDuration("SIMULATOR_INTERVAL", getEnvDuration("SCORE_UPDATE_INTERVAL", 3*time.Second)),
+			Concurrency:    getEnvInt("SIMULATOR_CONCURRENCY", 1),
+			Burst:          getEnvInt("SIMULATOR_BURST", 0),
+			Users:          getEnvInt("SIMULATOR_USERS", 0),
+			MaxConcurrency: getEnvInt("SIMULATOR_MAX_CONCURRENCY", 64),
+			MaxBurst:       getEnvInt("SIMULATOR_MAX_BURST", 10000),
+			MaxUsers:       getEnvInt("SIMULATOR_MAX_USERS", 100000),
+			SyncEvery:      getEnvDuration("SIMULATOR_SYNC_INTERVAL", 5*time.Second),
+		},
+		Streams: StreamConfig{
+			Consumer:       getEnv("STREAM_CONSUMER", fmt.Sprintf("%s-%d", hostname(), os.Getpid())),
+			ConsumerExpiry: getEnvDuration("STREAM_CONSUMER_EXPIRY", 24*time.Hour),
+			RetainEntries:  int64(getEnvInt("STREAM_RETAIN_ENTRIES", 10000)),
+			RetainAge:      getEnvDuration("STREAM_RETAIN_AGE", 24*time.Hour),
+			DrainTimeout:   getEnvDuration("STREAM_DRAIN_TIMEOUT", 10*time.Second),
+		},
+		Events: EventConfig{
+			Transport:    getEnv("EVENT_TRANSPORT", EventTransportPubSub),
+			StreamMaxLen: int64(getEnvInt("EVENT_STREAM_MAXLEN", 100000)),
+			NATSURL:      getEnv("NATS_URL", "nats://localhost:4222"),
+			NATSSubject:  getEnv("NATS_SUBJECT", "leaderboard.events"),
+			KafkaBrokers: getEnvList("KAFKA_BROKERS", []string{"localhost:9092"}),
+			KafkaTopic:   getEnv("KAFKA_TOPIC", "leaderboard.events"),
+		},
+		DBSync: DBSyncConfig{
+			BatchSize:     getEnvInt("DB_SYNC_BATCH_SIZE", 500),
+			FlushInterval: getEnvDuration("DB_SYNC_FLUSH_INTERVAL", 100*time.Millisecond),
+			StatusEvery:   getEnvDuration("DB_SYNC_STATUS_INTERVAL", 15*time.Second),
+		},
+		Sandbox: SandboxConfig{
+			Enabled:   getEnvBool("SANDBOX_ENABLED", false),
+			KeyPrefix: getEnv("SANDBOX_KEY_PREFIX", "sandbox:"),
+			Schema:    getEnv("SANDBOX_SCHEMA", "sandbox"),
+			Notify:    loadNotificationConfig("SANDBOX_"),
+			Ranking:   loadRankingConfig("SANDBOX_"),
+		},
+		Tenants: TenantConfig{
+			IDs:     getEnvList("TENANTS", nil),
+			APIKeys: getEnvMap("TENANT_API_KEYS"),
+			Header:  getEnvBool("TENANT_HEADER_ENABLED", true),
+		},
+		Notify:  loadNotificationConfig(""),
+		Milestones: MilestoneConfig{
+			Ranks:        getEnvIntList("MILESTONE_RANKS", []int{10, 100, 1000}),
+			PersonalBest: getEnvBool("MILESTONE_PERSONAL_BEST", true),
+			Streaks:      getEnvIntList("MILESTONE_STREAKS", []int{3, 7, 30, 100}),
+		},
+		Achievement: AchievementConfig{
+			Enabled: getEnvBool("ACHIEVEMENTS_ENABLED", true),
+			Rating:  getEnvInt("ACHIEVEMENT_RATING", 3000),
+			Streak:  getEnvInt("ACHIEVEMENT_STREAK", 10),
+		},
+		Streaks: StreakConfig{
+			Enabled: getEnvBool("STREAKS_ENABLED", true),
+		},
+		WebSocket: WebSocketConfig{
+			CoalesceWindow:      getEnvDuration("WS_COALESCE_WINDOW", 0),
+			MaxBatch:            getEnvInt("WS_COALESCE_MAX_BATCH", 500),
+			BroadcastTopN:       getEnvInt("WS_BROADCAST_TOP_N", 0),
+			MinRankDelta:        getEnvInt("WS_BROADCAST_MIN_RANK_DELTA", 0),
+			MaxWatch:            getEnvInt("WS_MAX_WATCH", 100),
+			Shards:              getEnvInt("WS_HUB_SHARDS", runtime.NumCPU()),
+			SendBuffer:          getEnvInt("WS_SEND_BUFFER", 256),
+			SlowClientPolicy:    getEnv("WS_SLOW_CLIENT_POLICY", SlowClientDisconnect),
+			MaxConnections:      getEnvInt("WS_MAX_CONNECTIONS", 0),
+			MaxConnectionsPerIP: getEnvInt("WS_MAX_CONNECTIONS_PER_IP", 0),
+			AllowedOrigins:      getEnvList("WS_ALLOWED_ORIGINS", allowedOrigins),
+			StatsInterval:       getEnvDuration("WS_STATS_INTERVAL", 10*time.Second),
+			Compression:         getEnvBool("WS_COMPRESSION", true),
+			CompressionLevel:    getEnvInt("WS_COMPRESSION_LEVEL", 1),
+			CompressionMinSize:  getEnvInt("WS_COMPRESSION_MIN_SIZE", 512),
+		},
+		TopDiff: TopDiffConfig{
+			Enabled:  getEnvBool("TOP_DIFF_ENABLED", true),
+			Size:     getEnvInt("TOP_DIFF_SIZE", 100),
+			Interval: getEnvDuration("TOP_DIFF_INTERVAL", 250*time.Millisecond),
+			Resync:   getEnvDuration("TOP_DIFF_RESYNC", 30*time.Second),
+		},
+		Tiers: TierConfig{
+			Tiers:     getEnvList("TIERS", []string{"Bronze:0", "Silver:1200", "Gold:1600", "Platinum:2000", "Diamond:2400", "Master:2800"}),
+			Divisions: getEnvInt("TIER_DIVISIONS", 3),
+		},
+		Teams: TeamConfig{
+			Scoring:     getEnv("TEAM_SCORING", TeamScoreSum),
+			MaxMembers:  getEnvInt("TEAM_MAX_MEMBERS", 50),
+			ResyncEvery: getEnvDuration("TEAM_RESYNC_INTERVAL", 10*time.Minute),
+		},
+		Matchmaking: MatchmakingConfig{
+			Range:        getEnvInt("MATCHMAKING_RANGE", 100),
+			MaxRange:     getEnvInt("MATCHMAKING_MAX_RANGE", 1000),
+			Count:        getEnvInt("MATCHMAKING_COUNT", 10),
+			MaxCount:     getEnvInt("MATCHMAKING_MAX_COUNT", 50),
+			RecentWindow: getEnvDuration("MATCHMAKING_RECENT_WINDOW", time.Hour),
+		},
+		Decay: DecayConfig{
+			Enabled:       getEnvBool("DECAY_ENABLED", false),
+			Schedule:      getEnv("DECAY_SCHEDULE", "@daily"),
+			InactiveAfter: getEnvDuration("DECAY_INACTIVE_AFTER", 14*24*time.Hour),
+			Amount:        getEnvInt("DECAY_AMOUNT", 25),
+			Floor:         getEnvInt("DECAY_FLOOR", 1200),
+			TierFloors:    getEnvIntMap("DECAY_TIER_FLOORS", map[string]int{}),
+			BatchSize:     getEnvInt("DECAY_BATCH_SIZE", 500),
+		},
+		Webhooks: WebhookConfig{
+			Enabled:      getEnvBool("WEBHOOKS_ENABLED", true),
+			Workers:      getEnvInt("WEBHOOK_WORKERS", 4),
+			PollEvery:    getEnvDuration("WEBHOOK_POLL_INTERVAL", time.Second),
+			Timeout:      getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 6),
+			Backoff:      getEnvDuration("WEBHOOK_BACKOFF", 10*time.Second),
+			MaxBackoff:   getEnvDuration("WEBHOOK_MAX_BACKOFF", 30*time.Minute),
+			RefreshEvery: getEnvDuration("WEBHOOK_REFRESH_INTERVAL", 30*time.Second),
+			QueueSize:    getEnvInt("WEBHOOK_QUEUE_SIZE", 10000),
+			Retention:    getEnvDuration("WEBHOOK_LOG_RETENTION", 7*24*time.Hour),
+		},
+		Ranking: loadRankingConfig(""),
+		Benchmark: BenchmarkConfig{
+			Ops:           getEnvInt("BENCHMARK_OPS", 200),
+			DegradedRatio: getEnvFloat("BENCHMARK_DEGRADED_RATIO", 2),
+			Timeout:       getEnvDuration("BENCHMARK_TIMEOUT", 30*time.Second),
+		},
+		Fallback: FallbackConfig{
+			Enabled:     getEnvBool("REDIS_FALLBACK_ENABLED", true),
+			CheckEvery:  getEnvDuration("REDIS_HEALTH_INTERVAL", time.Second),
+			FailAfter:   getEnvInt("REDIS_HEALTH_FAILURES", 3),
+			ReplayEvery: getEnvDuration("SCORE_REPLAY_INTERVAL", 5*time.Second),
+			ReplayBatch: getEnvInt("SCORE_REPLAY_BATCH", 200),
+		},
+		Rebuild: RebuildConfig{
+			OnStart:   getEnvBool("REDIS_REBUILD_ON_START", true),
+			BatchSize: getEnvInt("REDIS_REBUILD_BATCH", 5000),
+			Wait:      getEnvDuration("REDIS_REBUILD_WAIT", 5*time.Minute),
+			Swap:      getEnvBool("REDIS_REBUILD_SWAP", true),
+
+			ArchiveTTL: getEnvDuration("RESET_ARCHIVE_TTL", 0),
+		},
+		Reconcile: ReconcileConfig{
+			Interval:   getEnvDuration("RECONCILE_INTERVAL", time.Hour),
+			Mode:       getEnv("RECONCILE_MODE", ReconcileSample),
+			SampleSize: getEnvInt("RECONCILE_SAMPLE_SIZE", 1000),
+			Source:     getEnv("RECONCILE_SOURCE", ReconcileFromRedis),
+			Settle:     getEnvDuration("RECONCILE_SETTLE", 10*time.Second),
+			BatchSize:  getEnvInt("RECONCILE_BATCH", 1000),
+		},
+		PGOutage: PostgresOutageConfig{
+			Enabled:        getEnvBool("PG_OUTAGE_ENABLED", true),
+			CheckEvery:     getEnvDuration("PG_HEALTH_INTERVAL", 2*time.Second),
+			FailAfter:      getEnvInt("PG_HEALTH_FAILURES", 3),
+			BackpressureAt: int64(getEnvInt("PG_BACKPRESSURE_BACKLOG", 100000)),
+			MaxBacklog:     int64(getEnvInt("PG_OUTAGE_MAX_BACKLOG", 5000000)),
+			TargetCommit:   getEnvDuration("PG_CATCHUP_TARGET", 500*time.Millisecond),
+			MaxBatch:       getEnvInt("PG_CATCHUP_MAX_BATCH", 10000),
+		},
+		Impersonate: ImpersonationConfig{
+			DefaultTTL: getEnvDuration("IMPERSONATION_TTL", 15*time.Minute),
+			MaxTTL:     getEnvDuration("IMPERSONATION_MAX_TTL", time.Hour),
+		},
+		Integrity: IntegrityConfig{
+			Interval:  getEnvDuration("INTEGRITY_CHECK_INTERVAL", 15*time.Minute),
+			ChunkSize: getEnvInt("INTEGRITY_CHUNK_SIZE", 1000),
+			Settle:    getEnvDuration("INTEGRITY_SETTLE", 10*time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: getEnvBool("RATE_LIMIT_ENABLED", true),
+			Window:  getEnvDuration("RATE_LIMIT_WINDOW", time.Minute),
+			TierLimits: getEnvIntMap("RATE_LIMIT_TIERS", map[string]int{
+				"free":     60,
+				"standard": 600,
+				"premium":  6000,
+				"sandbox":  120,
+				"admin":    0,
+			}),
+		},
+		FairQueue: FairQueueConfig{
+			Capacity:    getEnvInt("FAIR_QUEUE_CAPACITY", 32),
+			WaitTimeout: getEnvDuration("FAIR_QUEUE_WAIT_TIMEOUT", 5*time.Second),
+			TierWeights: getEnvIntMap("FAIR_QUEUE_TIERS", map[string]int{
+				"free":     1,
+				"standard": 4,
+				"premium":  16,
+			}),
 		},
 	}
 
@@ -78,6 +1072,198 @@ func LoadConfig() *Config {
 	return cfg
 }
 
+// Validate checks the connection settings, reporting every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil,
+		"LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level)
+	check(c.Log.Format == LogFormatText || c.Log.Format == LogFormatJSON,
+		"LOG_FORMAT must be text or json, got %q", c.Log.Format)
+
+	check(c.Compression.MinBytes >= 0, "COMPRESSION_MIN_BYTES must not be negative, got %d", c.Compression.MinBytes)
+	check(c.Compression.Level >= 1 && c.Compression.Level <= 9,
+		"COMPRESSION_LEVEL must be between 1 and 9, got %d", c.Compression.Level)
+
+	check(c.History.RankTrackedMax >= 0, "RANK_TRACKED_MAX must not be negative, got %d", c.History.RankTrackedMax)
+	check(c.History.RankTrackedTTL > 0, "RANK_TRACKED_TTL must be positive")
+
+	db := c.Database
+	check(db.MaxOpenConns >= 1, "DB_MAX_OPEN_CONNS must be at least 1, got %d", db.MaxOpenConns)
+	check(db.MaxIdleConns >= 0 && db.MaxIdleConns <= db.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS (%d), got %d", db.MaxOpenConns, db.MaxIdleConns)
+	check(db.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+	check(db.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME must not be negative")
+	check(db.ConnectTimeout >= 0, "DB_CONNECT_TIMEOUT must not be negative")
+	check(db.StatementTimeout >= 0, "DB_STATEMENT_TIMEOUT must not be negative")
+	check(db.SlowThreshold >= 0, "DB_SLOW_QUERY must not be negative")
+	switch db.LogLevel {
+	case DBLogSilent, DBLogError, DBLogWarn, DBLogInfo:
+	default:
+		check(false, "DB_LOG_LEVEL must be silent, error, warn or info, got %q", db.LogLevel)
+	}
+
+	rdb := c.Redis
+	check(rdb.PoolSize >= 1, "REDIS_POOL_SIZE must be at least 1, got %d", rdb.PoolSize)
+	check(rdb.MinIdleConns >= 0 && rdb.MinIdleConns <= rdb.PoolSize,
+		"REDIS_MIN_IDLE_CONNS must be between 0 and REDIS_POOL_SIZE (%d), got %d", rdb.PoolSize, rdb.MinIdleConns)
+	check(rdb.DialTimeout > 0, "REDIS_DIAL_TIMEOUT must be positive")
+	check(rdb.ReadTimeout > 0, "REDIS_READ_TIMEOUT must be positive")
+	check(rdb.WriteTimeout > 0, "REDIS_WRITE_TIMEOUT must be positive")
+	check(rdb.PoolTimeout > 0, "REDIS_POOL_TIMEOUT must be positive")
+
+	for _, rank := range c.Milestones.Ranks {
+		check(rank >= 1, "MILESTONE_RANKS must be ranks of at least 1, got %d", rank)
+	}
+	for _, days := range c.Milestones.Streaks {
+		check(days >= 1, "MILESTONE_STREAKS must be streaks of at least 1 day, got %d", days)
+	}
+	check(c.Achievement.Rating >= 100 && c.Achievement.Rating <= 5000,
+		"ACHIEVEMENT_RATING must be between 100 and 5000, got %d", c.Achievement.Rating)
+	check(c.WebSocket.CoalesceWindow >= 0, "WS_COALESCE_WINDOW must not be negative")
+	check(c.WebSocket.MaxBatch >= 1, "WS_COALESCE_MAX_BATCH must be at least 1, got %d", c.WebSocket.MaxBatch)
+	check(c.WebSocket.BroadcastTopN >= 0, "WS_BROADCAST_TOP_N must not be negative, got %d", c.WebSocket.BroadcastTopN)
+	check(c.WebSocket.MinRankDelta >= 0, "WS_BROADCAST_MIN_RANK_DELTA must not be negative, got %d", c.WebSocket.MinRankDelta)
+	check(c.WebSocket.MaxWatch >= 0, "WS_MAX_WATCH must not be negative, got %d", c.WebSocket.MaxWatch)
+	check(c.WebSocket.Shards >= 1, "WS_HUB_SHARDS must be at least 1, got %d", c.WebSocket.Shards)
+	check(c.WebSocket.MaxConnections >= 0, "WS_MAX_CONNECTIONS must not be negative, got %d", c.WebSocket.MaxConnections)
+	check(c.WebSocket.MaxConnectionsPerIP >= 0, "WS_MAX_CONNECTIONS_PER_IP must not be negative, got %d", c.WebSocket.MaxConnectionsPerIP)
+	check(c.Env != "production" || !slices.Contains(c.WebSocket.AllowedOrigins, "*"),
+		"WS_ALLOWED_ORIGINS must not allow every origin (*) in production")
+	check(c.WebSocket.StatsInterval > 0, "WS_STATS_INTERVAL must be positive")
+	check(c.WebSocket.SendBuffer >= 1, "WS_SEND_BUFFER must be at least 1, got %d", c.WebSocket.SendBuffer)
+	if c.WebSocket.Compression {
+		check(c.WebSocket.CompressionLevel >= 1 && c.WebSocket.CompressionLevel <= 9,
+			"WS_COMPRESSION_LEVEL must be between 1 and 9, got %d", c.WebSocket.CompressionLevel)
+		check(c.WebSocket.CompressionMinSize >= 0, "WS_COMPRESSION_MIN_SIZE must not be negative, got %d", c.WebSocket.CompressionMinSize)
+	}
+	switch c.Events.Transport {
+	case EventTransportPubSub:
+	case EventTransportStream:
+		check(c.Events.StreamMaxLen >= 1, "EVENT_STREAM_MAXLEN must be at least 1, got %d", c.Events.StreamMaxLen)
+	case EventTransportNATS:
+		u, err := url.Parse(c.Events.NATSURL)
+		check(err == nil && (u.Scheme == "nats" || u.Scheme == "tls") && u.Host != "",
+			"NATS_URL must look like nats://host:4222 or tls://host:4222, got %q", c.Events.NATSURL)
+		check(c.Events.NATSSubject != "" && !strings.ContainsAny(c.Events.NATSSubject, " \t*>"),
+			"NATS_SUBJECT must be a subject without spaces or wildcards, got %q", c.Events.NATSSubject)
+	case EventTransportKafka:
+		check(len(c.Events.KafkaBrokers) > 0, "KAFKA_BROKERS must list at least one broker")
+		for _, broker := range c.Events.KafkaBrokers {
+			_, port, err := net.SplitHostPort(broker)
+			check(err == nil && port != "", "KAFKA_BROKERS entries must look like host:9092, got %q", broker)
+		}
+		check(kafkaTopic.MatchString(c.Events.KafkaTopic),
+			"KAFKA_TOPIC must be letters, digits, '.', '_' or '-', got %q", c.Events.KafkaTopic)
+	default:
+		check(false, "EVENT_TRANSPORT must be pubsub, stream, nats or kafka, got %q", c.Events.Transport)
+	}
+	switch c.WebSocket.SlowClientPolicy {
+	case SlowClientDisconnect, SlowClientDropOldest, SlowClientLagged:
+	default:
+		check(false, "WS_SLOW_CLIENT_POLICY must be disconnect, drop_oldest or lagged, got %q", c.WebSocket.SlowClientPolicy)
+	}
+	if c.TopDiff.Enabled {
+		check(c.TopDiff.Size >= 1 && c.TopDiff.Size <= 1000, "TOP_DIFF_SIZE must be between 1 and 1000, got %d", c.TopDiff.Size)
+		check(c.TopDiff.Interval > 0, "TOP_DIFF_INTERVAL must be positive")
+		check(c.TopDiff.Resync >= 0, "TOP_DIFF_RESYNC must not be negative")
+	}
+	check(c.Achievement.Streak >= 2, "ACHIEVEMENT_STREAK must be at least 2, got %d", c.Achievement.Streak)
+
+	improved := c.Periods.ImprovedWindow
+	check(improved >= time.Hour && improved <= 7*24*time.Hour && improved%time.Hour == 0,
+		"MOST_IMPROVED_WINDOW must be whole hours between 1h and 168h, got %s", improved)
+	check(c.Periods.ImprovedCacheTTL > 0, "MOST_IMPROVED_CACHE_TTL must be positive")
+	check(c.Search.SimilarityThreshold > 0 && c.Search.SimilarityThreshold <= 1,
+		"SEARCH_SIMILARITY_THRESHOLD must be above 0 and at most 1, got %g", c.Search.SimilarityThreshold)
+	check(c.Search.SimilarityWeight >= 0 && c.Search.SimilarityWeight <= 1,
+		"SEARCH_SIMILARITY_WEIGHT must be between 0 and 1, got %g", c.Search.SimilarityWeight)
+
+	check(c.Teams.Scoring == TeamScoreSum || c.Teams.Scoring == TeamScoreAverage,
+		"TEAM_SCORING must be sum or average, got %q", c.Teams.Scoring)
+	check(c.Teams.MaxMembers >= 0, "TEAM_MAX_MEMBERS must not be negative, got %d", c.Teams.MaxMembers)
+	check(c.Tiers.Divisions >= 1, "TIER_DIVISIONS must be at least 1, got %d", c.Tiers.Divisions)
+
+	mm := c.Matchmaking
+	check(mm.MaxRange >= 1, "MATCHMAKING_MAX_RANGE must be at least 1, got %d", mm.MaxRange)
+	check(mm.Range >= 1 && mm.Range <= mm.MaxRange,
+		"MATCHMAKING_RANGE must be between 1 and MATCHMAKING_MAX_RANGE (%d), got %d", mm.MaxRange, mm.Range)
+	check(mm.MaxCount >= 1, "MATCHMAKING_MAX_COUNT must be at least 1, got %d", mm.MaxCount)
+	check(mm.Count >= 1 && mm.Count <= mm.MaxCount,
+		"MATCHMAKING_COUNT must be between 1 and MATCHMAKING_MAX_COUNT (%d), got %d", mm.MaxCount, mm.Count)
+	check(mm.RecentWindow >= 0, "MATCHMAKING_RECENT_WINDOW must not be negative")
+
+	decay := c.Decay
+	decaySchedule, err := scheduler.Parse(decay.Schedule)
+	check(err == nil && decaySchedule != nil, "DECAY_SCHEDULE must be a cron expression, @daily or @every <duration>, got %q", decay.Schedule)
+	check(decay.InactiveAfter > 0, "DECAY_INACTIVE_AFTER must be positive")
+	check(decay.Amount >= 1, "DECAY_AMOUNT must be at least 1, got %d", decay.Amount)
+	check(decay.Floor >= 100 && decay.Floor <= 5000, "DECAY_FLOOR must be between 100 and 5000, got %d", decay.Floor)
+	check(decay.BatchSize >= 1 && decay.BatchSize <= 10000, "DECAY_BATCH_SIZE must be between 1 and 10000, got %d", decay.BatchSize)
+	tierNames := make(map[string]bool, len(c.Tiers.Tiers))
+	for _, tier := range c.Tiers.Tiers {
+		name, _, _ := strings.Cut(tier, ":")
+		tierNames[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	for name, floor := range decay.TierFloors {
+		check(tierNames[strings.ToLower(name)], "DECAY_TIER_FLOORS names unknown tier %q", name)
+		check(floor >= 100 && floor <= 5000, "DECAY_TIER_FLOORS floor of %s must be between 100 and 5000, got %d", name, floor)
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range c.Tenants.IDs {
+		check(tenantID.MatchString(id), "TENANTS must be lower-case letters, digits and underscores (at most 32), got %q", id)
+		check(!seen[id], "TENANTS lists %q twice", id)
+		seen[id] = true
+	}
+	for key, id := range c.Tenants.APIKeys {
+		check(c.Tenants.Has(id), "TENANT_API_KEYS maps a key to unknown tenant %q", id)
+		tier, ok := c.Auth.APIKeys[key]
+		check(ok, "TENANT_API_KEYS has a key for tenant %q that is not in API_KEYS", id)
+		check(!ok || (tier != "admin" && tier != "sandbox"),
+			"TENANT_API_KEYS may not bind %s-tier keys to a tenant (tenant %q)", tier, id)
+	}
+
+	secret := c.Auth.AdminJWTSecret
+	check(secret == "" || len(secret) >= 32, "ADMIN_JWT_SECRET must be at least 32 bytes, got %d", len(secret))
+
+	return errors.Join(errs...)
+}
+
+// loadNotificationConfig reads a tenant's notification defaults from
+// <prefix>NOTIFY_* variables
+func loadNotificationConfig(prefix string) NotificationConfig {
+	return NotificationConfig{
+		DefaultEvents:   getEnvList(prefix+"NOTIFY_DEFAULT_EVENTS", []string{"daily_digest"}),
+		DefaultChannels: getEnvList(prefix+"NOTIFY_DEFAULT_CHANNELS", []string{"webhook", "email"}),
+		QuietHours:      getEnv(prefix+"NOTIFY_QUIET_HOURS", ""),
+		CacheTTL:        getEnvDuration(prefix+"NOTIFY_CACHE_TTL", 5*time.Minute),
+		Workers:         getEnvInt(prefix+"NOTIFY_WORKERS", 4),
+		QueueSize:       getEnvInt(prefix+"NOTIFY_QUEUE_SIZE", 10000),
+	}
+}
+
+// loadRankingConfig reads a board's ranking strategy from <prefix>RANKING_*
+// variables
+func loadRankingConfig(prefix string) RankingConfig {
+	return RankingConfig{
+		Strategy:       getEnv(prefix+"RANKING_STRATEGY", "absolute"),
+		KFactor:        getEnvFloat(prefix+"RANKING_K_FACTOR", 32),
+		Scale:          getEnvFloat(prefix+"RANKING_SCALE", 400),
+		GlickoRD:       getEnvFloat(prefix+"RANKING_GLICKO_RD", 350),
+		GlickoMinRD:    getEnvFloat(prefix+"RANKING_GLICKO_MIN_RD", 30),
+		TrueSkillSigma: getEnvFloat(prefix+"RANKING_TRUESKILL_SIGMA", 500),
+		TrueSkillBeta:  getEnvFloat(prefix+"RANKING_TRUESKILL_BETA", 250),
+		TrueSkillTau:   getEnvFloat(prefix+"RANKING_TRUESKILL_TAU", 5),
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -85,8 +1271,166 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		slog.Warn("⚠️  Invalid bool, using default", "key", key, "value", value, "default", defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+		slog.Warn("⚠️  Invalid int, using default", "key", key, "value", value, "default", defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+		slog.Warn("⚠️  Invalid float, using default", "key", key, "value", value, "default", defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvList parses "a,b,c"; set but empty means an empty list
+func getEnvList(key string, defaultValue []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+
+	result := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvIntList parses "1,2,3", falling back to the default on a bad entry
+func getEnvIntList(key string, defaultValue []int) []int {
+	items := getEnvList(key, nil)
+	if items == nil {
+		return defaultValue
+	}
+
+	result := make([]int, 0, len(items))
+	for _, item := range items {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			slog.Warn("⚠️  Invalid int, using default", "key", key, "value", item, "default", defaultValue)
+			return defaultValue
+		}
+		result = append(result, n)
+	}
+	return result
+}
+
+// getEnvMap parses "k1:v1,k2:v2" into a map
+func getEnvMap(key string) map[string]string {
+	result := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if ok && k != "" {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// getEnvIntMap parses "k1:1,k2:2" into a map, falling back to defaults
+func getEnvIntMap(key string, defaultValue map[string]int) map[string]int {
+	raw := getEnvMap(key)
+	if len(raw) == 0 {
+		return defaultValue
+	}
+
+	result := make(map[string]int, len(raw))
+	for k, v := range raw {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			slog.Warn("⚠️  Invalid int, using defaults", "key", key+"["+k+"]", "value", v)
+			return defaultValue
+		}
+		result[k] = n
+	}
+	return result
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+		slog.Warn("⚠️  Invalid duration, using default", "key", key, "value", value, "default", defaultValue)
+	}
+	return defaultValue
+}
+
+// DSN is the primary connection string with the TLS settings applied;
+// they replace any sslmode/sslrootcert already in the URL
 func (c *DatabaseConfig) DSN() string {
-	return c.URL
+	return c.withTLS(c.URL)
+}
+
+// ReplicaDSNs are the replica connection strings with the TLS settings applied
+func (c *DatabaseConfig) ReplicaDSNs() []string {
+	dsns := make([]string, len(c.ReplicaURLs))
+	for i, replica := range c.ReplicaURLs {
+		dsns[i] = c.withTLS(replica)
+	}
+	return dsns
+}
+
+// withTLS applies the TLS and timeout settings to a connection string
+func (c *DatabaseConfig) withTLS(dsn string) string {
+	params := [][2]string{{"sslmode", c.SSLMode}, {"sslrootcert", c.SSLRootCert}}
+	if c.ConnectTimeout > 0 {
+		// Whole seconds, rounded up so a sub-second timeout isn't "no timeout"
+		seconds := int64((c.ConnectTimeout + time.Second - 1) / time.Second)
+		params = append(params, [2]string{"connect_timeout", strconv.FormatInt(seconds, 10)})
+	}
+	if c.StatementTimeout > 0 {
+		params = append(params, [2]string{"statement_timeout", strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)})
+	}
+
+	for _, param := range params {
+		if param[1] != "" {
+			dsn = withDSNParam(dsn, param[0], param[1])
+		}
+	}
+	return dsn
+}
+
+// withDSNParam sets a parameter in a URL or keyword/value DSN
+func withDSNParam(dsn, key, value string) string {
+	if strings.Contains(dsn, "://") {
+		if u, err := url.Parse(dsn); err == nil {
+			query := u.Query()
+			query.Set(key, value)
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+
+	fields := strings.Fields(dsn)
+	kept := fields[:0]
+	for _, field := range fields {
+		if !strings.HasPrefix(field, key+"=") {
+			kept = append(kept, field)
+		}
+	}
+	return strings.Join(append(kept, key+"="+value), " ")
 }
 
 func (c *RedisConfig) Address() string {
@@ -103,4 +1447,13 @@ func IsDevelopment() bool {
 
 func IsStaging() bool {
 	return AppCfg != nil && AppCfg.Env == "staging"
-}
\ No newline at end of file
+}
+
+// hostname is the default node name (unique per container/pod)
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "server"
+	}
+	return name
+}
@@ -0,0 +1,171 @@
+-- Baseline: the schema GORM's AutoMigrate creates for the models. Every
+-- statement is IF NOT EXISTS so databases created by AutoMigrate (the
+-- seeder) can adopt versioned migrations by running "migrate up".
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS users (
+    id         bigserial PRIMARY KEY,
+    username   varchar(50) NOT NULL,
+    rating     bigint NOT NULL DEFAULT 1500,
+    status     varchar(16) NOT NULL DEFAULT 'active',
+    timezone   varchar(64) NOT NULL DEFAULT 'UTC',
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_username ON users (username);
+CREATE INDEX IF NOT EXISTS idx_rating_desc ON users (rating DESC);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS score_updates (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    old_rating bigint,
+    new_rating bigint,
+    change     bigint,
+    updated_at timestamptz,
+    event_id   varchar(32),
+    CONSTRAINT fk_score_updates_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_user_updates ON score_updates (user_id);
+CREATE INDEX IF NOT EXISTS idx_update_time ON score_updates (updated_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_score_update_event ON score_updates (event_id);
+
+CREATE TABLE IF NOT EXISTS rank_history (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    rank        bigint NOT NULL,
+    rating      bigint NOT NULL,
+    captured_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_rank_history_user_time ON rank_history (user_id, captured_at);
+CREATE INDEX IF NOT EXISTS idx_rank_history_time ON rank_history (captured_at);
+
+CREATE TABLE IF NOT EXISTS score_update_daily (
+    user_id      bigint NOT NULL,
+    day          date NOT NULL,
+    open_rating  bigint NOT NULL,
+    close_rating bigint NOT NULL,
+    min_rating   bigint NOT NULL,
+    max_rating   bigint NOT NULL,
+    update_count bigint NOT NULL,
+    PRIMARY KEY (user_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS digest_subscriptions (
+    user_id      bigint PRIMARY KEY,
+    opted_in     boolean NOT NULL DEFAULT false,
+    timezone     varchar(64) NOT NULL DEFAULT 'UTC',
+    webhook_url  varchar(500),
+    email        varchar(255),
+    last_sent_on date,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    CONSTRAINT fk_digest_subscriptions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_digest_due ON digest_subscriptions (opted_in, timezone);
+
+CREATE TABLE IF NOT EXISTS admin_adjustments (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    actor      varchar(100) NOT NULL,
+    reason     varchar(500),
+    source     varchar(20) NOT NULL,
+    old_rating bigint,
+    new_rating bigint,
+    change     bigint,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_adjustment_user ON admin_adjustments (user_id);
+CREATE INDEX IF NOT EXISTS idx_adjustment_actor ON admin_adjustments (actor);
+CREATE INDEX IF NOT EXISTS idx_adjustment_time ON admin_adjustments (created_at);
+
+CREATE TABLE IF NOT EXISTS anomaly_flags (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    rule        varchar(50) NOT NULL,
+    detail      varchar(255),
+    old_rating  bigint,
+    new_rating  bigint,
+    status      varchar(16) NOT NULL DEFAULT 'open',
+    reviewed_by varchar(100),
+    review_note varchar(500),
+    reviewed_at timestamptz,
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_anomaly_user ON anomaly_flags (user_id);
+CREATE INDEX IF NOT EXISTS idx_anomaly_status ON anomaly_flags (status);
+CREATE INDEX IF NOT EXISTS idx_anomaly_time ON anomaly_flags (created_at);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id               varchar(24) PRIMARY KEY,
+    type             varchar(50) NOT NULL,
+    status           varchar(16) NOT NULL,
+    actor            varchar(100),
+    node             varchar(100),
+    params           text,
+    total            bigint,
+    processed        bigint,
+    result           text,
+    error            varchar(1000),
+    cancel_requested boolean NOT NULL DEFAULT false,
+    created_at       timestamptz,
+    started_at       timestamptz,
+    finished_at      timestamptz,
+    updated_at       timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_job_type ON jobs (type);
+CREATE INDEX IF NOT EXISTS idx_job_status ON jobs (status);
+CREATE INDEX IF NOT EXISTS idx_job_created ON jobs (created_at);
+
+CREATE TABLE IF NOT EXISTS impersonation_events (
+    id         bigserial PRIMARY KEY,
+    session_id varchar(32) NOT NULL,
+    user_id    bigint NOT NULL,
+    admin      varchar(100) NOT NULL,
+    event      varchar(20) NOT NULL,
+    method     varchar(10),
+    path       varchar(500),
+    status     bigint,
+    reason     varchar(500),
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_impersonation_session ON impersonation_events (session_id);
+CREATE INDEX IF NOT EXISTS idx_impersonation_user ON impersonation_events (user_id);
+CREATE INDEX IF NOT EXISTS idx_impersonation_admin ON impersonation_events (admin);
+CREATE INDEX IF NOT EXISTS idx_impersonation_time ON impersonation_events (created_at);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id     bigint PRIMARY KEY,
+    events      text NOT NULL,
+    channels    text NOT NULL,
+    quiet_hours varchar(11),
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    CONSTRAINT fk_notification_preferences_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS deferred_score_updates (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    new_rating bigint NOT NULL,
+    actor      varchar(100) NOT NULL,
+    reason     varchar(500),
+    source     varchar(20) NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_deferred_score_updates_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_deferred_user ON deferred_score_updates (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS deferred_score_updates;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS impersonation_events;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS anomaly_flags;
+DROP TABLE IF EXISTS admin_adjustments;
+DROP TABLE IF EXISTS digest_subscriptions;
+DROP TABLE IF EXISTS score_update_daily;
+DROP TABLE IF EXISTS rank_history;
+DROP TABLE IF EXISTS score_updates;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,15 @@
+-- Fuzzy username search (pg_trgm) and the rating + username ordering.
+-- Built CONCURRENTLY so a large users table stays writable, which cannot
+-- run inside a transaction.
+
+-- +goose NO TRANSACTION
+
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_username_trgm ON users USING gin (username gin_trgm_ops);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_rating_username ON users (rating DESC, username);
+
+-- +goose Down
+-- pg_trgm is kept: other schemas (the sandbox) may use it
+DROP INDEX CONCURRENTLY IF EXISTS idx_rating_username;
+DROP INDEX CONCURRENTLY IF EXISTS idx_username_trgm;
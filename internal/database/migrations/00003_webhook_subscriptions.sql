@@ -0,0 +1,39 @@
+-- Admin-registered webhook subscriptions and their delivery log
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id         bigserial PRIMARY KEY,
+    url        varchar(500) NOT NULL,
+    secret     varchar(128) NOT NULL,
+    events     text NOT NULL,
+    milestones text,
+    top_n      bigint NOT NULL DEFAULT 10,
+    user_ids   text,
+    active     boolean NOT NULL DEFAULT true,
+    created_by varchar(100),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              bigserial PRIMARY KEY,
+    subscription_id bigint NOT NULL,
+    event           varchar(32) NOT NULL,
+    user_id         bigint NOT NULL,
+    milestone       bigint,
+    payload         text,
+    status          varchar(16) NOT NULL,
+    attempts        bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz,
+    last_error      varchar(500),
+    created_at      timestamptz,
+    delivered_at    timestamptz,
+    CONSTRAINT fk_webhook_deliveries_subscription FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_sub ON webhook_deliveries (subscription_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_time ON webhook_deliveries (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
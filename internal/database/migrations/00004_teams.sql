@@ -0,0 +1,26 @@
+-- Teams and their members, ranked on the team leaderboard
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS teams (
+    id          bigserial PRIMARY KEY,
+    name        varchar(50) NOT NULL,
+    description varchar(500),
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_name ON teams (name);
+
+CREATE TABLE IF NOT EXISTS team_memberships (
+    id        bigserial PRIMARY KEY,
+    team_id   bigint NOT NULL,
+    user_id   bigint NOT NULL,
+    joined_at timestamptz,
+    CONSTRAINT fk_team_memberships_team FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+    CONSTRAINT fk_team_memberships_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_team_membership_team ON team_memberships (team_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_membership_user ON team_memberships (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS team_memberships;
+DROP TABLE IF EXISTS teams;
@@ -0,0 +1,16 @@
+-- Achievements (badges) unlocked by users, at most one of each code per user
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS achievements (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    code        varchar(32) NOT NULL,
+    threshold   bigint,
+    rating      bigint,
+    unlocked_at timestamptz NOT NULL,
+    CONSTRAINT fk_achievements_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_achievement_user_code ON achievements (user_id, code);
+
+-- +goose Down
+DROP TABLE IF EXISTS achievements;
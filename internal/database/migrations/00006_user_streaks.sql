@@ -0,0 +1,14 @@
+-- Daily improvement streaks, one row per user
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_streaks (
+    user_id    bigint PRIMARY KEY,
+    current    bigint NOT NULL DEFAULT 0,
+    longest    bigint NOT NULL DEFAULT 0,
+    last_day   varchar(10),
+    updated_at timestamptz,
+    CONSTRAINT fk_user_streaks_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_streaks;
@@ -1,33 +1,91 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
 
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
 var DB *gorm.DB
 
+// ReplicaResolver names the read-replica route. Queries only go to the
+// replicas when they opt in with dbresolver.Use(ReplicaResolver); all
+// others, and every write, use the primary.
+const ReplicaResolver = "replica"
+
 // ConnectPostgres initializes PostgreSQL connection
 func ConnectPostgres(cfg *config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := cfg.DSN()
+	db, err := openPostgres(cfg, cfg.DSN(), cfg.ReplicaDSNs())
+	if err != nil {
+		return nil, err
+	}
 
-	// Configure GORM logger
+	slog.Info("✅ PostgreSQL connected successfully")
+	if len(cfg.ReplicaURLs) > 0 {
+		slog.Info("📖 Read-only queries routed to PostgreSQL replicas", "replicas", len(cfg.ReplicaURLs))
+	}
+
+	DB = db
+	return db, nil
+}
+
+// openPostgres opens a pooled connection for dsn, with the replica route
+// when replica DSNs are given
+func openPostgres(cfg *config.DatabaseConfig, dsn string, replicaDSNs []string) (*gorm.DB, error) {
+	// Configure GORM logger; its lines go through the structured logger,
+	// so they come out as JSON too (without colours)
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: logger.New(slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo), logger.Config{
+			SlowThreshold: cfg.SlowThreshold,
+			LogLevel:      gormLogLevel(cfg.LogLevel),
+		}),
 	}
 
 	// Connect to database
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	dialector, err := openDialector(cfg, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if len(replicaDSNs) > 0 {
+		replicas := make([]gorm.Dialector, len(replicaDSNs))
+		for i, replicaDSN := range replicaDSNs {
+			if replicas[i], err = openDialector(cfg, replicaDSN); err != nil {
+				closeGorm(db)
+				return nil, fmt.Errorf("replica %d: %w", i+1, err)
+			}
+		}
+		resolver := dbresolver.Register(dbresolver.Config{
+			Replicas: replicas,
+			Policy:   dbresolver.RandomPolicy{},
+		}, ReplicaResolver).
+			SetMaxIdleConns(cfg.MaxIdleConns).
+			SetMaxOpenConns(cfg.MaxOpenConns).
+			SetConnMaxLifetime(cfg.ConnMaxLifetime).
+			SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+		if err := db.Use(resolver); err != nil {
+			closeGorm(db)
+			return nil, fmt.Errorf("failed to connect to read replicas: %w", err)
+		}
+	}
+
 	// Get underlying SQL DB to configure connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -35,23 +93,78 @@ func ConnectPostgres(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 	}
 
 	// Set connection pool settings
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-
-	log.Println("✅ PostgreSQL connected successfully")
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	DB = db
 	return db, nil
 }
 
+// gormLogLevel maps DB_LOG_LEVEL to the GORM logger level
+func gormLogLevel(level string) logger.LogLevel {
+	switch level {
+	case config.DBLogSilent:
+		return logger.Silent
+	case config.DBLogError:
+		return logger.Error
+	case config.DBLogInfo:
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+// openDialector returns the PostgreSQL dialector for dsn
+func openDialector(cfg *config.DatabaseConfig, dsn string) (gorm.Dialector, error) {
+	if cfg.PasswordFunc == nil {
+		return postgres.Open(dsn), nil
+	}
+
+	// Resolve the password per connection so rotated secrets apply
+	// to new pool connections without restarting the process
+	pgxConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+	sqlDB := stdlib.OpenDB(*pgxConfig, stdlib.OptionBeforeConnect(
+		func(ctx context.Context, cc *pgx.ConnConfig) error {
+			if password := cfg.PasswordFunc(); password != "" {
+				cc.Password = password
+			}
+			return nil
+		},
+	))
+	return postgres.New(postgres.Config{Conn: sqlDB}), nil
+}
+
+// The models stored in PostgreSQL; the embedded migrations create a table
+// for each
+var migratedModels = []interface{}{
+	&models.User{},
+	&models.ScoreUpdate{},
+	&models.RankHistory{},
+	&models.ScoreUpdateDaily{},
+	&models.DigestSubscription{},
+	&models.AdminAdjustment{},
+	&models.AnomalyFlag{},
+	&models.Job{},
+	&models.ImpersonationEvent{},
+	&models.NotificationPreference{},
+	&models.DeferredScoreUpdate{},
+	&models.WebhookSubscription{},
+	&models.WebhookDelivery{},
+	&models.Team{},
+	&models.TeamMembership{},
+	&models.Achievement{},
+	&models.Streak{},
+}
+
 // AutoMigrate runs database migrations
 func AutoMigrate(db *gorm.DB) error {
-	log.Println("Running database migrations...")
+	slog.Info("Running database migrations...")
 
-	err := db.AutoMigrate(
-		&models.User{},
-		&models.ScoreUpdate{},
-	)
+	err := db.AutoMigrate(migratedModels...)
 
 	if err != nil {
 		return fmt.Errorf("migration failed: %w", err)
@@ -62,7 +175,7 @@ func AutoMigrate(db *gorm.DB) error {
 		return err
 	}
 
-	log.Println("✅ Database migrations completed")
+	slog.Info("✅ Database migrations completed")
 	return nil
 }
 
@@ -83,14 +196,18 @@ func createIndexes(db *gorm.DB) error {
 		ON users(rating DESC, username)
 	`)
 
-	log.Println("✅ Additional indexes created")
+	slog.Info("✅ Additional indexes created")
 	return nil
 }
 
 // CloseDB closes the database connection
 func CloseDB() error {
-	if DB != nil {
-		sqlDB, err := DB.DB()
+	return closeGorm(DB)
+}
+
+func closeGorm(db *gorm.DB) error {
+	if db != nil {
+		sqlDB, err := db.DB()
 		if err != nil {
 			return err
 		}
@@ -98,3 +215,59 @@ func CloseDB() error {
 	}
 	return nil
 }
+
+// ConnectTenantPostgres opens a second connection whose search_path puts
+// schema first, creating the schema if needed and applying the embedded
+// migrations to it. Unqualified table names then resolve to the tenant's
+// (or the sandbox's) copies.
+func ConnectTenantPostgres(cfg *config.DatabaseConfig, schema string) (*gorm.DB, error) {
+	if !schemaName.MatchString(schema) {
+		return nil, fmt.Errorf("invalid tenant schema name %q", schema)
+	}
+
+	// Make sure the schema exists before connecting with it on the path
+	if DB == nil {
+		return nil, fmt.Errorf("production database must be connected first")
+	}
+	if err := DB.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema)).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tenant schema: %w", err)
+	}
+
+	replicaDSNs := cfg.ReplicaDSNs()
+	for i, replicaDSN := range replicaDSNs {
+		replicaDSNs[i] = withSearchPath(replicaDSN, schema)
+	}
+	db, err := openPostgres(cfg, withSearchPath(cfg.DSN(), schema), replicaDSNs)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := MigrateUp(context.Background(), db)
+	if err != nil {
+		closeGorm(db)
+		return nil, fmt.Errorf("failed to migrate tenant schema: %w", err)
+	}
+
+	slog.Info("✅ Tenant PostgreSQL schema ready", "schema", schema, "migrations_applied", applied)
+	return db, nil
+}
+
+var schemaName = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// withSearchPath adds a search_path setting to a URL or keyword/value DSN.
+// public stays on the path so shared extensions (pg_trgm) resolve.
+func withSearchPath(dsn, schema string) string {
+	path := schema + ",public"
+	if strings.Contains(dsn, "://") {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		return dsn + sep + "search_path=" + url.QueryEscape(path)
+	}
+	return dsn + " search_path=" + path
+}
+
+// CloseTenantDB closes a connection opened by ConnectTenantPostgres
+func CloseTenantDB(db *gorm.DB) error {
+	return closeGorm(db)
+}
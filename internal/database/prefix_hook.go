@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefixHook prepends a prefix to every key of every command, so a
+// tenant sharing a Redis server (the sandbox) gets its own keyspace while
+// the repositories keep using the plain key constants. It fails closed:
+// a command without a known key layout is refused rather than run
+// unprefixed against production keys.
+type keyPrefixHook struct {
+	prefix string
+}
+
+// NewKeyPrefixHook returns a go-redis hook that namespaces keys under prefix
+func NewKeyPrefixHook(prefix string) redis.Hook {
+	return keyPrefixHook{prefix: prefix}
+}
+
+// ErrCommandNotPrefixed is returned for a command the hook does not know
+// the key layout of
+var ErrCommandNotPrefixed = errors.New("key prefix: command is not allowed on a prefixed client")
+
+// Commands without keys (PUBLISH channels are namespaced by the caller)
+var keylessCommands = map[string]bool{
+	"ping": true, "hello": true, "client": true, "info": true, "auth": true,
+	"select": true, "echo": true, "time": true, "multi": true, "exec": true,
+	"discard": true, "publish": true,
+}
+
+// Commands whose only key is the first argument
+var firstKeyCommands = map[string]bool{
+	"get": true, "set": true, "setnx": true, "incr": true, "expire": true, "ttl": true,
+	"hset": true, "hget": true, "hgetall": true, "hdel": true, "hincrby": true,
+	"sadd": true, "srem": true, "smembers": true, "sismember": true,
+	"rpush": true, "lpush": true, "lpop": true, "llen": true,
+	"zadd": true, "zrem": true, "zscore": true, "zcard": true, "zcount": true,
+	"zincrby": true, "zrank": true, "zrevrank": true, "zrange": true, "zrevrange": true,
+	"zrangebyscore": true, "zrevrangebyscore": true, "zremrangebyscore": true, "zremrangebyrank": true,
+	"zrandmember": true, "zrangebylex": true,
+	"xadd": true, "xack": true, "xdel": true, "xlen": true, "xrange": true,
+	"xrevrange": true, "xtrim": true, "xpending": true, "xclaim": true, "xautoclaim": true,
+}
+
+func (h keyPrefixHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h keyPrefixHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.prefixKeys(cmd.Args()); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h keyPrefixHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if err := h.prefixKeys(cmd.Args()); err != nil {
+				cmd.SetErr(err)
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// prefixKeys rewrites the key arguments of a command in place
+func (h keyPrefixHook) prefixKeys(args []interface{}) error {
+	if len(args) == 0 {
+		return nil
+	}
+	name := strings.ToLower(fmt.Sprint(args[0]))
+
+	switch {
+	case keylessCommands[name]:
+		return nil
+	case firstKeyCommands[name]:
+		return h.prefixRange(args, 1, 2)
+	case name == "del" || name == "unlink" || name == "exists":
+		return h.prefixRange(args, 1, len(args))
+	case name == "rename" || name == "renamenx":
+		return h.prefixRange(args, 1, 3)
+	case name == "xgroup" || name == "xinfo":
+		// XGROUP CREATE <key> ..., XINFO GROUPS <key> ...
+		return h.prefixRange(args, 2, 3)
+	case name == "zunionstore" || name == "zinterstore":
+		// <dest> <numkeys> <key>...
+		n, err := argInt(args, 2)
+		if err != nil {
+			return err
+		}
+		if err := h.prefixRange(args, 1, 2); err != nil {
+			return err
+		}
+		return h.prefixRange(args, 3, 3+n)
+	case name == "eval" || name == "evalsha" || name == "eval_ro" || name == "evalsha_ro":
+		// <script> <numkeys> <key>...
+		n, err := argInt(args, 2)
+		if err != nil {
+			return err
+		}
+		return h.prefixRange(args, 3, 3+n)
+	case name == "xread" || name == "xreadgroup":
+		// ... STREAMS <key>... <id>...
+		for i, arg := range args {
+			if strings.EqualFold(fmt.Sprint(arg), "streams") {
+				n := (len(args) - i - 1) / 2
+				return h.prefixRange(args, i+1, i+1+n)
+			}
+		}
+		return fmt.Errorf("key prefix: %s without STREAMS", name)
+	}
+	return fmt.Errorf("%w: %q", ErrCommandNotPrefixed, name)
+}
+
+func (h keyPrefixHook) prefixRange(args []interface{}, from, to int) error {
+	if to > len(args) || from > to {
+		return fmt.Errorf("key prefix: malformed %v command", args[0])
+	}
+	for i := from; i < to; i++ {
+		args[i] = h.prefix + fmt.Sprint(args[i])
+	}
+	return nil
+}
+
+func argInt(args []interface{}, i int) (int, error) {
+	if i >= len(args) {
+		return 0, fmt.Errorf("key prefix: malformed %v command", args[0])
+	}
+	return strconv.Atoi(fmt.Sprint(args[i]))
+}
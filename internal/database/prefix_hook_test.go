@@ -0,0 +1,117 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPrefixKeys(t *testing.T) {
+	h := keyPrefixHook{prefix: "sandbox:"}
+
+	for _, tc := range []struct {
+		name string
+		args []interface{}
+		want []interface{}
+	}{
+		{"keyless", []interface{}{"ping"}, []interface{}{"ping"}},
+		{"publish channel left alone", []interface{}{"publish", "sandbox:score:updates", "{}"},
+			[]interface{}{"publish", "sandbox:score:updates", "{}"}},
+		{"first key", []interface{}{"zadd", "leaderboard:global", 1500, "user:1"},
+			[]interface{}{"zadd", "sandbox:leaderboard:global", 1500, "user:1"}},
+		{"upper case", []interface{}{"HINCRBY", "ws:stats", "connected", 1},
+			[]interface{}{"HINCRBY", "sandbox:ws:stats", "connected", 1}},
+		{"every key", []interface{}{"del", "a", "b", "c"},
+			[]interface{}{"del", "sandbox:a", "sandbox:b", "sandbox:c"}},
+		{"rename", []interface{}{"rename", "staging", "live"},
+			[]interface{}{"rename", "sandbox:staging", "sandbox:live"}},
+		{"xgroup", []interface{}{"xgroup", "create", "score:stream", "db-sync", "$", "mkstream"},
+			[]interface{}{"xgroup", "create", "sandbox:score:stream", "db-sync", "$", "mkstream"}},
+		{"zunionstore", []interface{}{"zunionstore", "dest", 2, "h1", "h2", "aggregate", "sum"},
+			[]interface{}{"zunionstore", "sandbox:dest", 2, "sandbox:h1", "sandbox:h2", "aggregate", "sum"}},
+		{"evalsha keys only", []interface{}{"evalsha", "abc123", 2, "k1", "k2", "arg"},
+			[]interface{}{"evalsha", "abc123", 2, "sandbox:k1", "sandbox:k2", "arg"}},
+		{"xreadgroup streams", []interface{}{"xreadgroup", "group", "g", "c", "streams", "s1", "s2", ">", ">"},
+			[]interface{}{"xreadgroup", "group", "g", "c", "streams", "sandbox:s1", "sandbox:s2", ">", ">"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := h.prefixKeys(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.args, tc.want) {
+				t.Errorf("args = %v, want %v", tc.args, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrefixKeysRefusesUnknownCommands(t *testing.T) {
+	h := keyPrefixHook{prefix: "sandbox:"}
+
+	// A command without a known key layout must not run unprefixed
+	for _, name := range []string{"keys", "scan", "flushdb", "sunionstore", "objectx"} {
+		args := []interface{}{name, "leaderboard:global"}
+		err := h.prefixKeys(args)
+		if !errors.Is(err, ErrCommandNotPrefixed) {
+			t.Errorf("%s: error = %v, want ErrCommandNotPrefixed", name, err)
+		}
+		if args[1] != "leaderboard:global" {
+			t.Errorf("%s: args rewritten to %v", name, args)
+		}
+	}
+
+	// Malformed commands are refused rather than partly prefixed
+	for _, args := range [][]interface{}{
+		{"evalsha", "abc123", 3, "k1"},
+		{"evalsha", "abc123", "two", "k1"},
+		{"xread", "count", 10, "s1", "0"},
+		{"rename", "only-one"},
+	} {
+		if err := h.prefixKeys(args); err == nil {
+			t.Errorf("%v: no error", args)
+		}
+	}
+}
+
+// TestPrefixAllowlistsDisjoint checks no command is in two layouts, where
+// one of them would silently win
+func TestPrefixAllowlistsDisjoint(t *testing.T) {
+	for name := range firstKeyCommands {
+		if keylessCommands[name] {
+			t.Errorf("%s is both keyless and first-key", name)
+		}
+	}
+	for _, name := range []string{"del", "unlink", "exists", "rename", "renamenx", "xgroup", "xinfo",
+		"zunionstore", "zinterstore", "eval", "evalsha", "xread", "xreadgroup"} {
+		if firstKeyCommands[name] || keylessCommands[name] {
+			t.Errorf("%s has its own layout but is also allowlisted", name)
+		}
+	}
+}
+
+func TestWithSearchPath(t *testing.T) {
+	for _, tc := range []struct {
+		dsn  string
+		want string
+	}{
+		{"postgres://u:p@db:5432/app", "postgres://u:p@db:5432/app?search_path=sandbox%2Cpublic"},
+		{"postgres://u:p@db:5432/app?sslmode=require", "postgres://u:p@db:5432/app?sslmode=require&search_path=sandbox%2Cpublic"},
+		{"host=db user=u dbname=app", "host=db user=u dbname=app search_path=sandbox,public"},
+	} {
+		if got := withSearchPath(tc.dsn, "sandbox"); got != tc.want {
+			t.Errorf("withSearchPath(%q) = %q, want %q", tc.dsn, got, tc.want)
+		}
+	}
+
+	for _, schema := range []string{"sandbox", "tenant_acme", "_t1"} {
+		if !schemaName.MatchString(schema) {
+			t.Errorf("schema %q rejected", schema)
+		}
+	}
+	for _, schema := range []string{"", "Acme", "1tenant", "acme;drop", "a-b", fmt.Sprintf("t%063d", 0)} {
+		if schemaName.MatchString(schema) {
+			t.Errorf("schema %q accepted", schema)
+		}
+	}
+}
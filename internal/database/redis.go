@@ -2,8 +2,11 @@ package database
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
@@ -14,21 +17,100 @@ var Ctx = context.Background()
 
 // ConnectRedis initializes Redis connection
 func ConnectRedis(cfg *config.RedisConfig) (*redis.Client, error) {
-	client := redis.NewClient(&redis.Options{
+	client, err := newRedisClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("✅ Redis connected successfully")
+
+	RedisClient = client
+	return client, nil
+}
+
+// newRedisClient creates a client and checks the connection
+func newRedisClient(cfg *config.RedisConfig) (*redis.Client, error) {
+	opts := &redis.Options{
 		Addr:     cfg.Address(),
+		Username: cfg.Username,
 		Password: cfg.Password,
 		DB:       cfg.DB,
-		PoolSize: 20,
-	})
+
+		PoolSize:     cfg.PoolSize,
+		MinIdleConns: cfg.MinIdleConns,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		PoolTimeout:  cfg.PoolTimeout,
+	}
+
+	// Rotated passwords are picked up on the next new connection
+	if cfg.PasswordFunc != nil {
+		opts.CredentialsProvider = func() (string, string) {
+			if password := cfg.PasswordFunc(); password != "" {
+				return cfg.Username, password
+			}
+			return cfg.Username, cfg.Password
+		}
+	}
+
+	if cfg.TLS {
+		tlsConfig, err := redisTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	client := redis.NewClient(opts)
 
 	// Test connection
 	if err := client.Ping(Ctx).Err(); err != nil {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
+	return client, nil
+}
 
-	log.Println("✅ Redis connected successfully")
+// redisTLSConfig verifies the server against the configured CA bundle (or
+// the system roots) unless verification is explicitly skipped
+func redisTLSConfig(cfg *config.RedisConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.Host,
+		InsecureSkipVerify: cfg.TLSSkipVerify,
+	}
+	if cfg.TLSSkipVerify {
+		slog.Warn("⚠️  Redis TLS certificate verification is disabled")
+	}
 
-	RedisClient = client
+	if cfg.TLSCACert != "" {
+		pem, err := os.ReadFile(cfg.TLSCACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Redis CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.TLSCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// ConnectTenantRedis creates a separate client whose keys are all
+// rewritten under prefix, so a tenant (or the sandbox) can share the Redis
+// server without seeing or touching other tenants' keys
+func ConnectTenantRedis(cfg *config.RedisConfig, prefix string) (*redis.Client, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("tenant key prefix must not be empty")
+	}
+	client, err := newRedisClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client.AddHook(NewKeyPrefixHook(prefix))
+
+	slog.Info("✅ Tenant Redis keyspace ready", "prefix", prefix)
 	return client, nil
 }
 
@@ -43,8 +125,47 @@ func CloseRedis() error {
 // Redis key constants
 const (
 	LeaderboardKey     = "leaderboard:global"
+	ShadowBoardKey     = "leaderboard:shadow" // shadow-banned users, visible only to themselves
 	UserCacheKey       = "user:cache:%d" // user:cache:123
 	UsernamePrefixKey  = "prefix:%s"     // prefix:rahul
 	RankCacheKey       = "rank:cache:%d" // rank:cache:123
 	ScoreUpdateChannel = "score:updates"
+	RankTrackedKey     = "rank:tracked:at" // users snapshotted beyond the top N, scored by when last requested
+	UpdateRateKey      = "anticheat:rate:%d:%d" // anticheat:rate:<user>:<window start>
+	RatingDeltaKey     = "ratelimit:delta:%d"    // applied rating changes, scored by time
+	PeriodBoardKey     = "leaderboard:period:%s" // leaderboard:period:daily:+05:30:2026-10-18
+	ImprovedBucketKey  = "leaderboard:improved:%d" // rating gained in one hour (hours since the epoch)
+	ImprovedBoardKey   = "leaderboard:improved"    // gains of the last MOST_IMPROVED_WINDOW, cached
+	UserLockKey        = "lock:user:%d"          // serializes score updates of one user
+	IngestStatusKey    = "ingest:status:%s"      // outcome of a 202-accepted score update
+	ProtectionMarkKey  = "protection:mark"       // active rollback protection mark (JSON)
+	ProtectionFloorKey = "protection:floors"     // ratings snapshotted at the mark
+	IntegrityReportKey = "integrity:reports"     // hash: node -> latest checksum report
+	ImpersonationKey   = "impersonation:%s"      // active impersonation session by ID
+	RequestRateKey     = "ratelimit:req:%s:%d"   // ratelimit:req:<caller>:<window start>
+	NotifyPrefsKey     = "notify:prefs:%d"       // effective notification preferences (JSON)
+	BenchBaselineKey   = "benchmark:baselines"   // hash: node -> baseline benchmark report
+	BenchScratchKey    = "benchmark:scratch:%s"  // sorted set written by a benchmark run
+	SyncLastKey        = "dbsync:last_sync"      // hash: consumer -> last DB sync commit (unix ms)
+	RankStateKey       = "ranking:state:%s"      // hash per strategy: user -> rating uncertainty
+	RebuildLockKey     = "lock:rebuild"          // held while a server rebuilds Redis from PostgreSQL
+	ReconcileLockKey   = "lock:reconcile"        // held while a server reconciles Redis and PostgreSQL
+	ReconcileReportKey = "reconcile:last"        // latest reconcile report (JSON)
+	AuditSpoolKey      = "audit:spool"           // audit entries waiting for PostgreSQL (JSON list)
+	SimulatorStateKey  = "simulator:state"       // simulator settings shared by all servers (JSON)
+	ArchiveBoardKey    = "leaderboard:archive:%s" // global board renamed away by a reset, by UTC time
+	StagingBoardKey    = "%s:staging:%s"          // <board>:staging:<token>, built by a blue/green rebuild
+	BestRatingKey      = "rating:best"            // hash: user -> highest rating reached (personal best)
+	TeamBoardKey       = "leaderboard:teams"      // team:<id> scored by the aggregate of its members' ratings
+	TeamMembersKey     = "team:members:%d"        // set of a team's member user IDs
+	UserTeamKey        = "team:of"                // hash: user -> team ID
+	ActivityKey        = "activity:last"          // user:<id> scored by the unix time of their last score update
+	SchedulerClaimKey  = "scheduler:claim:%s"     // latest occurrence of a job claimed by a server (unix seconds)
+	SchedulerRunsKey   = "scheduler:runs"         // hash: job -> its last run (JSON)
+	AchievementsKey    = "achievements:%d"        // set of a user's unlocked achievement codes
+	WinStreakKey       = "achievement:streaks"    // hash: user -> rating gains in a row
+	StreakKey          = "streak:%d"              // hash: current, longest and last day of a user's daily improvement streak
+	MatchRecentKey     = "matchmaking:recent:%d"  // zset: opponent -> unix time of the user's last match with them
+	UsernameIndexKey   = "search:usernames"       // zset, all scored 0: <lowercase username>\x00<id>\x00<username>, for ZRANGEBYLEX
+	WSConnectionsKey   = "ws:connections"         // hash: node -> its WebSocket client count (JSON heartbeat)
 )
\ No newline at end of file
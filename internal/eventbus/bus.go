@@ -0,0 +1,153 @@
+// Package eventbus decouples producers of domain events (score updates,
+// renames, removals) from the services that react to them.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/requestid"
+)
+
+// Event is a domain event; Payload is a pointer to the type given to Define
+type Event struct {
+	Type    string
+	Payload interface{}
+
+	// ID of the HTTP request that caused the event ("" for background
+	// work), on this server and on the others
+	RequestID string
+}
+
+// Handler reacts to an event. Handlers run synchronously, so they should be
+// quick or hand work off (e.g. to a queue).
+type Handler func(event Event)
+
+// Transport fans events out to every server (implemented by
+// service.MessageBus); ctx carries the request ID to send along
+type Transport interface {
+	PublishEvent(ctx context.Context, eventType string, payload interface{}) error
+	OnEvent(handler func(*models.PubSubEvent))
+}
+
+type definition struct {
+	newPayload func() interface{}
+}
+
+// Bus dispatches events in-process to Subscribe handlers, and through the
+// transport to SubscribeAll handlers on every server (including this one)
+type Bus struct {
+	transport Transport
+
+	mu      sync.RWMutex
+	types   map[string]definition
+	local   map[string][]Handler
+	cluster map[string][]Handler
+}
+
+// New creates a bus; a nil transport delivers cluster handlers in-process only
+func New(transport Transport) *Bus {
+	b := &Bus{
+		transport: transport,
+		types:     make(map[string]definition),
+		local:     make(map[string][]Handler),
+		cluster:   make(map[string][]Handler),
+	}
+	if transport != nil {
+		transport.OnEvent(b.receive)
+	}
+	return b
+}
+
+// Define declares an event type and how to decode its payload when it
+// arrives from another server (e.g. func() interface{} { return &T{} })
+func (b *Bus) Define(eventType string, newPayload func() interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.types[eventType] = definition{newPayload: newPayload}
+}
+
+// Subscribe runs handler on the server that publishes the event
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.local[eventType] = append(b.local[eventType], handler)
+}
+
+// SubscribeAll runs handler on every server whenever any server publishes the event
+func (b *Bus) SubscribeAll(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cluster[eventType] = append(b.cluster[eventType], handler)
+}
+
+// Publish dispatches to local subscribers, then fans out to all servers
+// when the event type has cluster subscribers
+func (b *Bus) Publish(eventType string, payload interface{}) error {
+	return b.PublishContext(context.Background(), eventType, payload)
+}
+
+// PublishContext is Publish for an event caused by the request whose ID
+// ctx carries (requestid.FromContext); subscribers on every server see it
+// in Event.RequestID. ctx does not cancel publishing.
+func (b *Bus) PublishContext(ctx context.Context, eventType string, payload interface{}) error {
+	b.mu.RLock()
+	_, defined := b.types[eventType]
+	local := b.local[eventType]
+	cluster := b.cluster[eventType]
+	b.mu.RUnlock()
+
+	if !defined {
+		return fmt.Errorf("event type %q is not defined", eventType)
+	}
+
+	event := Event{Type: eventType, Payload: payload, RequestID: requestid.FromContext(ctx)}
+	dispatch(local, event)
+
+	if len(cluster) == 0 {
+		return nil
+	}
+	if b.transport == nil {
+		dispatch(cluster, event)
+		return nil
+	}
+	return b.transport.PublishEvent(ctx, eventType, payload)
+}
+
+// receive decodes an event from the transport and runs cluster subscribers
+func (b *Bus) receive(msg *models.PubSubEvent) {
+	b.mu.RLock()
+	def, ok := b.types[msg.Type]
+	cluster := b.cluster[msg.Type]
+	b.mu.RUnlock()
+
+	if !ok || len(cluster) == 0 {
+		return
+	}
+
+	payload := def.newPayload()
+	if err := json.Unmarshal(msg.Payload, payload); err != nil {
+		slog.Warn("⚠️  Failed to decode event", "event", msg.Type, "error", err)
+		return
+	}
+
+	dispatch(cluster, Event{Type: msg.Type, Payload: payload, RequestID: msg.RequestID})
+}
+
+// dispatch runs handlers in order; a panicking handler doesn't stop the rest
+func dispatch(handlers []Handler, event Event) {
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("❌ Event handler panicked", "event", event.Type, "panic", r)
+				}
+			}()
+			handler(event)
+		}()
+	}
+}
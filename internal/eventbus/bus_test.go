@@ -0,0 +1,111 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+type testPayload struct {
+	UserID uint `json:"user_id"`
+}
+
+// loopTransport stands in for the message bus: everything published is
+// received back, as every server (including this one) would receive it
+type loopTransport struct {
+	published []string
+	handler   func(*models.PubSubEvent)
+}
+
+func (l *loopTransport) OnEvent(handler func(*models.PubSubEvent)) { l.handler = handler }
+
+func (l *loopTransport) PublishEvent(ctx context.Context, eventType string, payload interface{}) error {
+	l.published = append(l.published, eventType)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	l.handler(&models.PubSubEvent{Type: eventType, Payload: body})
+	return nil
+}
+
+func TestBusDispatch(t *testing.T) {
+	transport := &loopTransport{}
+	bus := New(transport)
+	bus.Define("score_update", func() interface{} { return &testPayload{} })
+	bus.Define("user_removed", func() interface{} { return &testPayload{} })
+
+	var got []string
+	bus.Subscribe("score_update", func(e Event) { got = append(got, "local:"+e.Type) })
+	bus.Subscribe("score_update", func(e Event) { panic("broken handler") })
+	bus.Subscribe("score_update", func(e Event) { got = append(got, "local after panic") })
+	bus.SubscribeAll("score_update", func(e Event) {
+		// Cluster handlers get the payload decoded into the defined type
+		if p, ok := e.Payload.(*testPayload); !ok || p.UserID != 7 {
+			t.Errorf("cluster payload = %#v, want *testPayload for user 7", e.Payload)
+		}
+		got = append(got, "cluster:"+e.Type)
+	})
+	bus.Subscribe("user_removed", func(e Event) { got = append(got, "local:"+e.Type) })
+
+	if err := bus.Publish("score_update", &testPayload{UserID: 7}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"local:score_update", "local after panic", "cluster:score_update"}
+	if len(got) != len(want) {
+		t.Fatalf("handled %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("handled %v, want %v", got, want)
+			break
+		}
+	}
+
+	// Events without cluster subscribers stay on this server
+	got = nil
+	if err := bus.Publish("user_removed", &testPayload{UserID: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || len(transport.published) != 1 {
+		t.Errorf("handled %v, published %v; want one local handler and nothing sent", got, transport.published)
+	}
+
+	if err := bus.Publish("not_defined", &testPayload{}); err == nil {
+		t.Error("publishing an undefined event succeeded")
+	}
+}
+
+func TestBusReceiveIgnoresUnknownEvents(t *testing.T) {
+	transport := &loopTransport{}
+	bus := New(transport)
+	bus.Define("score_update", func() interface{} { return &testPayload{} })
+
+	calls := 0
+	bus.SubscribeAll("score_update", func(e Event) { calls++ })
+
+	// From a newer server, or undecodable: dropped, not dispatched
+	transport.handler(&models.PubSubEvent{Type: "unknown", Payload: json.RawMessage(`{}`)})
+	transport.handler(&models.PubSubEvent{Type: "score_update", Payload: json.RawMessage(`"not an object"`)})
+	if calls != 0 {
+		t.Errorf("cluster handler called %d times, want 0", calls)
+	}
+}
+
+func TestBusWithoutTransport(t *testing.T) {
+	bus := New(nil)
+	bus.Define("score_update", func() interface{} { return &testPayload{} })
+
+	var cluster *testPayload
+	bus.SubscribeAll("score_update", func(e Event) { cluster = e.Payload.(*testPayload) })
+
+	sent := &testPayload{UserID: 3}
+	if err := bus.Publish("score_update", sent); err != nil {
+		t.Fatal(err)
+	}
+	if cluster != sent {
+		t.Errorf("cluster handler got %v, want the published payload in-process", cluster)
+	}
+}
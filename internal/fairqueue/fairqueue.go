@@ -0,0 +1,208 @@
+// Package fairqueue implements weighted fair queueing of expensive
+// operations across callers, so one heavy consumer cannot starve others.
+package fairqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+)
+
+// ErrQueueTimeout is returned when a caller waits longer than allowed
+var ErrQueueTimeout = errors.New("timed out waiting for a fair-queue slot")
+
+var (
+	queueWait = metrics.NewHistogramVec("fairqueue_wait_seconds",
+		"Time spent waiting for a fair-queue slot", nil, "tier")
+	queueInflight = metrics.NewGauge("fairqueue_inflight",
+		"Operations currently holding a fair-queue slot")
+	queueWaiting = metrics.NewGauge("fairqueue_waiting",
+		"Operations waiting for a fair-queue slot")
+	queueTimeouts = metrics.NewCounterVec("fairqueue_timeouts_total",
+		"Operations that gave up waiting for a slot", "tier")
+)
+
+// Scheduler hands out a fixed number of slots. Each key is limited to its
+// tier weight in concurrent slots (its semaphore pool), and contended slots
+// go to the waiting key with the lowest weighted virtual time.
+type Scheduler struct {
+	capacity    int
+	weights     map[string]int
+	waitTimeout time.Duration
+
+	mu      sync.Mutex
+	inUse   int
+	vclock  float64 // virtual time of the last grant
+	keys    map[string]*keyState
+	waiting int
+}
+
+type keyState struct {
+	tier     string
+	weight   int
+	inflight int
+	vtime    float64
+	waiters  []chan struct{}
+}
+
+// NewScheduler creates a scheduler with a global capacity and tier weights
+func NewScheduler(capacity int, weights map[string]int, waitTimeout time.Duration) *Scheduler {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Scheduler{
+		capacity:    capacity,
+		weights:     weights,
+		waitTimeout: waitTimeout,
+		keys:        make(map[string]*keyState),
+	}
+}
+
+// Acquire blocks until the key is granted a slot; call release when done
+func (s *Scheduler) Acquire(ctx context.Context, key, tier string) (release func(), err error) {
+	start := time.Now()
+
+	s.mu.Lock()
+	ks := s.keyState(key, tier)
+
+	// Fast path: spare capacity, key under its pool size, nobody queued
+	if s.waiting == 0 && s.inUse < s.capacity && ks.inflight < ks.weight {
+		s.grant(ks)
+		s.mu.Unlock()
+		queueWait.WithLabelValues(tier).Observe(0)
+		return s.releaseFunc(key), nil
+	}
+
+	ch := make(chan struct{})
+	ks.waiters = append(ks.waiters, ch)
+	s.waiting++
+	queueWaiting.Set(float64(s.waiting))
+	s.mu.Unlock()
+
+	if s.waitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.waitTimeout)
+		defer cancel()
+	}
+
+	select {
+	case <-ch:
+		queueWait.WithLabelValues(tier).Observe(time.Since(start).Seconds())
+		return s.releaseFunc(key), nil
+
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// Granted concurrently with the timeout: hand the slot back
+		select {
+		case <-ch:
+			s.release(key)
+		default:
+			s.removeWaiter(ks, ch)
+			s.forget(key, ks)
+		}
+
+		queueTimeouts.WithLabelValues(tier).Inc()
+		return nil, ErrQueueTimeout
+	}
+}
+
+func (s *Scheduler) keyState(key, tier string) *keyState {
+	ks, ok := s.keys[key]
+	if !ok {
+		ks = &keyState{tier: tier, weight: s.Weight(tier), vtime: s.vclock}
+		s.keys[key] = ks
+	}
+	return ks
+}
+
+// grant gives ks one slot and advances its virtual time by 1/weight
+func (s *Scheduler) grant(ks *keyState) {
+	// Idle keys don't bank credit: start from the current virtual clock
+	if ks.vtime < s.vclock {
+		ks.vtime = s.vclock
+	}
+	ks.vtime += 1 / float64(ks.weight)
+	s.vclock = ks.vtime - 1/float64(ks.weight)
+	ks.inflight++
+	s.inUse++
+	queueInflight.Set(float64(s.inUse))
+}
+
+func (s *Scheduler) releaseFunc(key string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.release(key)
+		})
+	}
+}
+
+// release frees a slot and dispatches waiters (caller holds mu)
+func (s *Scheduler) release(key string) {
+	ks := s.keys[key]
+	ks.inflight--
+	s.inUse--
+	queueInflight.Set(float64(s.inUse))
+
+	s.dispatch()
+	s.forget(key, ks)
+}
+
+// forget drops an idle key so the map doesn't grow with every caller ever
+// seen, including ones that only timed out (caller holds mu)
+func (s *Scheduler) forget(key string, ks *keyState) {
+	if ks.inflight == 0 && len(ks.waiters) == 0 {
+		delete(s.keys, key)
+	}
+}
+
+// dispatch grants free slots to the eligible key with the lowest virtual time
+func (s *Scheduler) dispatch() {
+	for s.inUse < s.capacity {
+		var next *keyState
+		for _, ks := range s.keys {
+			if len(ks.waiters) == 0 || ks.inflight >= ks.weight {
+				continue
+			}
+			if next == nil || ks.vtime < next.vtime {
+				next = ks
+			}
+		}
+		if next == nil {
+			return
+		}
+
+		ch := next.waiters[0]
+		next.waiters = next.waiters[1:]
+		s.waiting--
+		queueWaiting.Set(float64(s.waiting))
+		s.grant(next)
+		close(ch)
+	}
+}
+
+func (s *Scheduler) removeWaiter(ks *keyState, ch chan struct{}) {
+	for i, waiter := range ks.waiters {
+		if waiter == ch {
+			ks.waiters = append(ks.waiters[:i], ks.waiters[i+1:]...)
+			s.waiting--
+			queueWaiting.Set(float64(s.waiting))
+			return
+		}
+	}
+}
+
+// Weight is the number of concurrent slots a key of tier may hold
+func (s *Scheduler) Weight(tier string) int {
+	if weight := s.weights[tier]; weight >= 1 {
+		return weight
+	}
+	return 1
+}
@@ -0,0 +1,179 @@
+package fairqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitQueued waits until n callers are queued
+func waitQueued(t *testing.T, s *Scheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.mu.Lock()
+		waiting := s.waiting
+		s.mu.Unlock()
+		if waiting == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d callers queued, want %d", waiting, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// grantOrder queues callers behind a held slot of a capacity-1 scheduler,
+// one by one, then frees the slot and returns the keys in the order they
+// were granted; each caller releases as soon as it is granted
+func grantOrder(t *testing.T, weights map[string]int, callers []string) []string {
+	t.Helper()
+	s := NewScheduler(1, weights, 0)
+	hold, err := s.Acquire(context.Background(), "holder", "holder")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu    sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	for i, key := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := s.Acquire(context.Background(), key, key)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, key)
+			mu.Unlock()
+			release()
+		}()
+		waitQueued(t, s, i+1)
+	}
+
+	hold()
+	wg.Wait()
+	return order
+}
+
+func count(keys []string, key string) int {
+	n := 0
+	for _, k := range keys {
+		if k == key {
+			n++
+		}
+	}
+	return n
+}
+
+func TestWeightedShares(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		weights map[string]int
+		window  int // first grants to count
+		want    map[string]int
+	}{
+		{"equal weights alternate", map[string]int{"free": 1, "pro": 1}, 4, map[string]int{"free": 2, "pro": 2}},
+		{"three to one", map[string]int{"free": 1, "pro": 3}, 4, map[string]int{"free": 1, "pro": 3}},
+		{"two to one", map[string]int{"free": 2, "pro": 4}, 6, map[string]int{"free": 2, "pro": 4}},
+		{"unknown tier weighs one", map[string]int{"pro": 3}, 4, map[string]int{"free": 1, "pro": 3}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var callers []string
+			for range 8 {
+				callers = append(callers, "free", "pro")
+			}
+			order := grantOrder(t, tc.weights, callers)
+			if len(order) != len(callers) {
+				t.Fatalf("granted %d callers, want %d", len(order), len(callers))
+			}
+			first := order[:tc.window]
+			for key, want := range tc.want {
+				if got := count(first, key); got != want {
+					t.Errorf("%s got %d of the first %d slots, want %d (order %v)", key, got, tc.window, want, order)
+				}
+			}
+		})
+	}
+}
+
+func TestHeavyKeyDoesNotStarveLightKey(t *testing.T) {
+	// 20 queued requests of a heavy caller, then one of a light caller
+	callers := make([]string, 0, 21)
+	for range 20 {
+		callers = append(callers, "heavy")
+	}
+	callers = append(callers, "light")
+
+	order := grantOrder(t, map[string]int{"heavy": 3, "light": 1}, callers)
+	for i, key := range order {
+		if key == "light" {
+			if i > 3 {
+				t.Errorf("light caller granted %dth, after %d heavy requests", i+1, i)
+			}
+			return
+		}
+	}
+	t.Fatal("light caller never granted")
+}
+
+func TestKeyLimitedToItsWeight(t *testing.T) {
+	s := NewScheduler(10, map[string]int{"free": 2}, 20*time.Millisecond)
+
+	for i := range 2 {
+		if _, err := s.Acquire(context.Background(), "caller", "free"); err != nil {
+			t.Fatalf("slot %d: %v", i+1, err)
+		}
+	}
+	if _, err := s.Acquire(context.Background(), "caller", "free"); !errors.Is(err, ErrQueueTimeout) {
+		t.Fatalf("third slot error = %v, want ErrQueueTimeout", err)
+	}
+	// Other keys still get the spare capacity
+	if _, err := s.Acquire(context.Background(), "other", "free"); err != nil {
+		t.Fatalf("other key: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.waiting != 0 {
+		t.Errorf("%d waiters left after a timeout, want 0", s.waiting)
+	}
+}
+
+func TestCancelledWaiterLeavesQueue(t *testing.T) {
+	s := NewScheduler(1, nil, 0)
+	release, err := s.Acquire(context.Background(), "a", "free")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := s.Acquire(ctx, "b", "free")
+		done <- err
+	}()
+	waitQueued(t, s, 1)
+	cancel()
+	if err := <-done; !errors.Is(err, ErrQueueTimeout) {
+		t.Fatalf("error = %v, want ErrQueueTimeout", err)
+	}
+
+	// Releasing twice frees one slot only
+	release()
+	release()
+	s.mu.Lock()
+	inUse, waiting, keys := s.inUse, s.waiting, len(s.keys)
+	s.mu.Unlock()
+	if inUse != 0 || waiting != 0 || keys != 0 {
+		t.Errorf("inUse=%d waiting=%d keys=%d, want all 0", inUse, waiting, keys)
+	}
+}
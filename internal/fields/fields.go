@@ -0,0 +1,120 @@
+// Package fields implements ?fields= selection: list responses carry only
+// the requested JSON fields of each item, for clients that need a few of
+// them (watch apps, embedded scoreboards).
+package fields
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Set is the fields a client asked for; nil means all of them
+type Set map[string]bool
+
+// field is a JSON field of a struct type
+type field struct {
+	index     int
+	omitEmpty bool
+}
+
+// structFields caches the JSON fields of each item type
+var structFields sync.Map // reflect.Type -> map[string]field
+
+// Parse reads a comma-separated field list for items of type T, e.g.
+// "rank,username". An empty list selects all fields; an unknown name is an
+// error listing the valid ones.
+func Parse[T any](raw string) (Set, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	known := fieldsOf(reflect.TypeFor[T]())
+	set := make(Set)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q; use %s", name, strings.Join(names(known), ", "))
+		}
+		set[name] = true
+	}
+	if len(set) == 0 {
+		return nil, nil
+	}
+	return set, nil
+}
+
+// Has reports whether a field was asked for
+func (s Set) Has(name string) bool {
+	return s == nil || s[name]
+}
+
+// Select returns items with only the selected fields (omitempty fields
+// that are empty stay out, as in the full item), or items unchanged when
+// all fields are selected
+func Select[T any](items []T, s Set) interface{} {
+	if s == nil {
+		return items
+	}
+
+	known := fieldsOf(reflect.TypeFor[T]())
+	selected := make(map[string]field, len(s))
+	for name := range s {
+		selected[name] = known[name]
+	}
+
+	shaped := make([]map[string]interface{}, len(items))
+	for i := range items {
+		value := reflect.ValueOf(&items[i]).Elem()
+		row := make(map[string]interface{}, len(selected))
+		for name, f := range selected {
+			v := value.Field(f.index)
+			if f.omitEmpty && v.IsZero() {
+				continue
+			}
+			row[name] = v.Interface()
+		}
+		shaped[i] = row
+	}
+	return shaped
+}
+
+// fieldsOf returns the JSON fields of a struct type by name
+func fieldsOf(t reflect.Type) map[string]field {
+	if cached, ok := structFields.Load(t); ok {
+		return cached.(map[string]field)
+	}
+
+	known := make(map[string]field)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		known[name] = field{index: i, omitEmpty: strings.Contains(options, "omitempty")}
+	}
+	structFields.Store(t, known)
+	return known
+}
+
+func names(known map[string]field) []string {
+	list := make([]string, 0, len(known))
+	for name := range known {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,1490 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/logging"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/scheduler"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	userSvc       service.UserService
+	auditSvc      service.AuditService
+	spikeSvc      service.SpikeService
+	anomalySvc    service.AnomalyService
+	importSvc     service.ImportService
+	importCfg     config.ImportConfig
+	jobSvc        service.JobService
+	rollbackSvc   service.RollbackService
+	integritySvc  service.IntegrityService
+	streamMonitor service.StreamMonitor
+	impersonation service.ImpersonationService
+	benchmarkSvc  service.BenchmarkService
+	rebuildSvc    service.RebuildService
+	reconcileSvc  service.ReconcileService
+	dbSync        service.DBSyncService
+	simulatorSvc  service.SimulatorService
+	webhookSvc    service.WebhookService
+	scheduler     *scheduler.Scheduler
+	node          string
+}
+
+func NewAdminHandler(
+	userSvc service.UserService,
+	auditSvc service.AuditService,
+	spikeSvc service.SpikeService,
+	anomalySvc service.AnomalyService,
+	importSvc service.ImportService,
+	importCfg config.ImportConfig,
+	jobSvc service.JobService,
+	rollbackSvc service.RollbackService,
+	integritySvc service.IntegrityService,
+	streamMonitor service.StreamMonitor,
+	impersonation service.ImpersonationService,
+	benchmarkSvc service.BenchmarkService,
+	rebuildSvc service.RebuildService,
+	reconcileSvc service.ReconcileService,
+	dbSync service.DBSyncService,
+	simulatorSvc service.SimulatorService,
+	webhookSvc service.WebhookService,
+	sched *scheduler.Scheduler,
+	node string,
+) *AdminHandler {
+	return &AdminHandler{
+		userSvc:       userSvc,
+		auditSvc:      auditSvc,
+		spikeSvc:      spikeSvc,
+		anomalySvc:    anomalySvc,
+		importSvc:     importSvc,
+		importCfg:     importCfg,
+		jobSvc:        jobSvc,
+		rollbackSvc:   rollbackSvc,
+		integritySvc:  integritySvc,
+		streamMonitor: streamMonitor,
+		impersonation: impersonation,
+		benchmarkSvc:  benchmarkSvc,
+		rebuildSvc:    rebuildSvc,
+		reconcileSvc:  reconcileSvc,
+		dbSync:        dbSync,
+		simulatorSvc:  simulatorSvc,
+		webhookSvc:    webhookSvc,
+		scheduler:     sched,
+		node:          node,
+	}
+}
+
+// SetUserStatus godoc
+// @Summary Ban, shadow-ban or reinstate a user
+// @Description banned: score frozen and hidden from everyone; shadow_banned: hidden from everyone but the user; active: restored to the public leaderboard
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param body body map[string]string true "Status (active, banned, shadow_banned)"
+// @Success 200 {object} models.User
+// @Router /admin/users/{user_id}/status [put]
+func (h *AdminHandler) SetUserStatus(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Status string `json:"status" binding:"required,oneof=active banned shadow_banned"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. status must be one of active, banned, shadow_banned")
+		return
+	}
+
+	user, err := h.userSvc.SetStatus(uint(userID), req.Status)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeUserNotFound, "User not found")
+			return
+		}
+		apierror.Fail(c, err, "Failed to update user status")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    user,
+	})
+}
+
+// ListAdjustments godoc
+// @Summary List manual score adjustments
+// @Description Returns the score change audit trail (who, what, why), newest first. Page with before_id.
+// @Tags admin
+// @Produce json
+// @Param user_id query int false "Only adjustments of this user"
+// @Param actor query string false "Only adjustments by this actor"
+// @Param before_id query int false "Only entries older than this ID"
+// @Param limit query int false "Maximum entries" default(100)
+// @Success 200 {array} models.AdminAdjustment
+// @Router /admin/audit [get]
+func (h *AdminHandler) ListAdjustments(c *gin.Context) {
+	var filter repository.AuditFilter
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		filter.UserID = uint(userID)
+	}
+	if beforeStr := c.Query("before_id"); beforeStr != "" {
+		beforeID, err := strconv.ParseUint(beforeStr, 10, 32)
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid before_id")
+			return
+		}
+		filter.BeforeID = uint(beforeID)
+	}
+	filter.Actor = c.Query("actor")
+
+	// Parse limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000 // Max limit
+	}
+	filter.Limit = limit
+
+	entries, err := h.auditSvc.ListAdjustments(filter)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch audit log")
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"count":   len(entries),
+		"data":    entries,
+	}
+	if len(entries) == limit {
+		response["next_before_id"] = entries[len(entries)-1].ID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// StartSpike godoc
+// @Summary Start a simulated traffic spike
+// @Description Multiplies the simulator rate by multiplier for duration, spread over a random subset of users. Only one spike runs at a time.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} true "multiplier, duration (e.g. 2m) and users"
+// @Success 202 {object} service.SpikeReport
+// @Router /admin/spikes [post]
+func (h *AdminHandler) StartSpike(c *gin.Context) {
+	// Parse request body
+	var req struct {
+		Multiplier int    `json:"multiplier" binding:"required,min=1"`
+		Duration   string `json:"duration" binding:"required"`
+		Users      int    `json:"users" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. multiplier, duration and users are required")
+		return
+	}
+
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid duration. Use Go duration syntax (e.g. 90s, 5m)")
+		return
+	}
+
+	report, err := h.spikeSvc.Start(auth.FromContext(c).Actor(), service.SpikeRequest{
+		Multiplier: req.Multiplier,
+		Duration:   duration,
+		Users:      req.Users,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrSpikeRunning) {
+			apierror.AbortCode(c, http.StatusConflict, service.CodeSpikeRunning, err.Error())
+			return
+		}
+		apierror.Abort(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// GetSpike godoc
+// @Summary Get a traffic spike's status and capacity report
+// @Tags admin
+// @Produce json
+// @Param id path string true "Spike ID"
+// @Success 200 {object} service.SpikeReport
+// @Router /admin/spikes/{id} [get]
+func (h *AdminHandler) GetSpike(c *gin.Context) {
+	report, err := h.spikeSvc.Get(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, http.StatusNotFound, "Spike not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// DownloadSpikeReport godoc
+// @Summary Download a finished spike's capacity report
+// @Description Latencies, queue depths and dropped WebSocket messages as a JSON attachment
+// @Tags admin
+// @Produce json
+// @Param id path string true "Spike ID"
+// @Success 200 {object} service.SpikeReport
+// @Router /admin/spikes/{id}/report [get]
+func (h *AdminHandler) DownloadSpikeReport(c *gin.Context) {
+	report, err := h.spikeSvc.Get(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, http.StatusNotFound, "Spike not found")
+		return
+	}
+	if report.Status == "queued" || report.Status == "running" {
+		apierror.Abort(c, http.StatusConflict, "Spike is still running")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="spike-%s.json"`, report.ID))
+	c.IndentedJSON(http.StatusOK, report)
+}
+
+// StopSpike godoc
+// @Summary Stop a running traffic spike early
+// @Tags admin
+// @Produce json
+// @Param id path string true "Spike ID"
+// @Success 202 {object} map[string]interface{}
+// @Router /admin/spikes/{id} [delete]
+func (h *AdminHandler) StopSpike(c *gin.Context) {
+	if err := h.spikeSvc.Stop(c.Param("id")); err != nil {
+		apierror.Abort(c, http.StatusNotFound, "No running spike with that ID")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Spike stopping; the report is available once it finishes",
+	})
+}
+
+// GetSimulator godoc
+// @Summary Get the score simulator's settings and status
+// @Description Returns the settings shared by all servers (running, interval, concurrency, user pool) and what the simulator on the answering server is doing
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.SimulatorStatus
+// @Router /admin/simulator [get]
+func (h *AdminHandler) GetSimulator(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.simulatorSvc.Status(),
+	})
+}
+
+// StartSimulator godoc
+// @Summary Start the score simulator
+// @Description Starts the simulator on every server (within SIMULATOR_SYNC_INTERVAL), or changes its settings while it runs. Omitted fields keep their current values. burst is the number of updates per tick, shared by concurrency workers (0 = one per worker). users is the size of the random user pool sampled at start; 0 picks any active user per update.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} false "Optional interval (e.g. 500ms), concurrency, burst and users"
+// @Success 200 {object} models.SimulatorStatus
+// @Router /admin/simulator/start [post]
+func (h *AdminHandler) StartSimulator(c *gin.Context) {
+	// Parse request body (optional)
+	var req struct {
+		Interval    string `json:"interval"`
+		Concurrency int    `json:"concurrency"`
+		Burst       *int   `json:"burst"`
+		Users       *int   `json:"users"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	settings := service.SimulatorRequest{Concurrency: req.Concurrency, Burst: req.Burst, Users: req.Users}
+	if req.Interval != "" {
+		interval, err := time.ParseDuration(req.Interval)
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid interval. Use Go duration syntax (e.g. 500ms, 3s)")
+			return
+		}
+		settings.Interval = interval
+	}
+
+	status, err := h.simulatorSvc.Enable(auth.FromContext(c).Actor(), settings)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSimulator) {
+			apierror.AbortCode(c, http.StatusBadRequest, service.CodeValidation, err.Error())
+			return
+		}
+		apierror.Fail(c, err, "Failed to start simulator")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// StopSimulator godoc
+// @Summary Stop the score simulator
+// @Description Stops the simulator on every server (within SIMULATOR_SYNC_INTERVAL). Its settings are kept for the next start.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.SimulatorStatus
+// @Router /admin/simulator/stop [post]
+func (h *AdminHandler) StopSimulator(c *gin.Context) {
+	status, err := h.simulatorSvc.Disable(auth.FromContext(c).Actor())
+	if err != nil {
+		apierror.Fail(c, err, "Failed to stop simulator")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// ListAnomalies godoc
+// @Summary List anti-cheat flags
+// @Description Suspicious score updates (rating jumps, update bursts), newest first. Page with before_id.
+// @Tags admin
+// @Produce json
+// @Param status query string false "open, confirmed or dismissed"
+// @Param user_id query int false "Only flags of this user"
+// @Param before_id query int false "Only flags older than this ID"
+// @Param limit query int false "Maximum flags" default(100)
+// @Success 200 {array} models.AnomalyFlag
+// @Router /admin/anomalies [get]
+func (h *AdminHandler) ListAnomalies(c *gin.Context) {
+	var filter repository.AnomalyFilter
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		filter.UserID = uint(userID)
+	}
+	if beforeStr := c.Query("before_id"); beforeStr != "" {
+		beforeID, err := strconv.ParseUint(beforeStr, 10, 32)
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid before_id")
+			return
+		}
+		filter.BeforeID = uint(beforeID)
+	}
+
+	switch status := c.Query("status"); status {
+	case "", models.AnomalyStatusOpen, models.AnomalyStatusConfirmed, models.AnomalyStatusDismissed:
+		filter.Status = status
+	default:
+		apierror.Abort(c, http.StatusBadRequest, "Invalid status. Use open, confirmed or dismissed")
+		return
+	}
+
+	// Parse limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000 // Max limit
+	}
+	filter.Limit = limit
+
+	flags, err := h.anomalySvc.ListFlags(filter)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch anomaly flags")
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"count":   len(flags),
+		"data":    flags,
+	}
+	if len(flags) == limit {
+		response["next_before_id"] = flags[len(flags)-1].ID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ReviewAnomaly godoc
+// @Summary Confirm or dismiss an anti-cheat flag
+// @Description Records the reviewer and note. Confirming does not ban; use the user status endpoint for that.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Flag ID"
+// @Param body body map[string]string true "status (confirmed, dismissed, open) and optional note"
+// @Success 200 {object} models.AnomalyFlag
+// @Router /admin/anomalies/{id} [put]
+func (h *AdminHandler) ReviewAnomaly(c *gin.Context) {
+	// Parse flag ID
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid flag ID")
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Status string `json:"status" binding:"required,oneof=open confirmed dismissed"`
+		Note   string `json:"note" binding:"max=500"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. status must be one of open, confirmed, dismissed")
+		return
+	}
+
+	flag, err := h.anomalySvc.ReviewFlag(uint(id), req.Status, auth.FromContext(c).Actor(), req.Note)
+	if err != nil {
+		if errors.Is(err, service.ErrFlagNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeFlagNotFound, "Flag not found")
+			return
+		}
+		apierror.Fail(c, err, "Failed to review flag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    flag,
+	})
+}
+
+// ImportScores godoc
+// @Summary Import scores from a CSV or NDJSON upload
+// @Description Validates every row, then applies valid rows through the bulk update pipeline in the background. Upload as multipart field "file" or as the raw body. CSV needs a user_id,new_rating header; NDJSON one {"user_id","new_rating"} object per line.
+// @Tags admin
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "csv or ndjson (default: from file name / content type)"
+// @Param reason query string false "Reason recorded in the audit log"
+// @Success 202 {object} models.Job
+// @Router /admin/scores/import [post]
+func (h *AdminHandler) ImportScores(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.importCfg.MaxBytes)
+
+	var (
+		upload   io.Reader = c.Request.Body
+		filename string
+	)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, fmt.Sprintf("Upload a file of at most %d bytes in the \"file\" field", h.importCfg.MaxBytes))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Failed to read upload")
+			return
+		}
+		defer file.Close()
+		upload, filename = file, fileHeader.Filename
+	}
+
+	reason := c.Query("reason")
+	if len(reason) > 500 {
+		apierror.Abort(c, http.StatusBadRequest, "reason must be at most 500 characters")
+		return
+	}
+
+	job, err := h.importSvc.Start(auth.FromContext(c).Actor(), reason, importFormat(c, filename), upload)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// importFormat picks the upload format from ?format, the file name or the content type
+func importFormat(c *gin.Context, filename string) string {
+	if format := c.Query("format"); format != "" {
+		return strings.ToLower(format)
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ndjson", ".jsonl":
+		return service.ImportNDJSON
+	case ".csv":
+		return service.ImportCSV
+	}
+	if strings.Contains(c.ContentType(), "ndjson") || strings.Contains(c.ContentType(), "jsonl") {
+		return service.ImportNDJSON
+	}
+	return service.ImportCSV
+}
+
+// ListJobs godoc
+// @Summary List admin jobs
+// @Description Imports, traffic spikes and other long-running admin operations, newest first
+// @Tags admin
+// @Produce json
+// @Param type query string false "Only jobs of this type (e.g. score_import, traffic_spike)"
+// @Param status query string false "queued, running, completed, failed or cancelled"
+// @Param limit query int false "Maximum jobs" default(50)
+// @Success 200 {array} models.Job
+// @Router /admin/jobs [get]
+func (h *AdminHandler) ListJobs(c *gin.Context) {
+	// Parse limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500 // Max limit
+	}
+
+	jobs, err := h.jobSvc.List(repository.JobFilter{
+		Type:   c.Query("type"),
+		Status: c.Query("status"),
+		Limit:  limit,
+	})
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch jobs")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"count":   len(jobs),
+		"data":    jobs,
+	})
+}
+
+// GetJob godoc
+// @Summary Get the status of an admin job
+// @Description Status, progress (processed/total) and, once finished, the result
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.Job
+// @Router /admin/jobs/{id} [get]
+func (h *AdminHandler) GetJob(c *gin.Context) {
+	job, err := h.jobSvc.Get(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeJobNotFound, "Job not found")
+			return
+		}
+		apierror.Fail(c, err, "Failed to fetch job")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// CancelJob godoc
+// @Summary Cancel a queued or running admin job
+// @Description The server running the job stops it at its next progress check; work already done is kept
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 202 {object} map[string]interface{}
+// @Router /admin/jobs/{id} [delete]
+func (h *AdminHandler) CancelJob(c *gin.Context) {
+	if err := h.jobSvc.Cancel(c.Param("id")); err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeJobNotFound, "No queued or running job with that ID")
+			return
+		}
+		apierror.Fail(c, err, "Failed to cancel job")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Cancellation requested",
+	})
+}
+
+// DownloadJobErrors godoc
+// @Summary Download the per-row error report of an import
+// @Description CSV of rejected rows (row, user_id, error)
+// @Tags admin
+// @Produce text/csv
+// @Param id path string true "Job ID"
+// @Success 200 {string} string "CSV report"
+// @Router /admin/jobs/{id}/errors [get]
+func (h *AdminHandler) DownloadJobErrors(c *gin.Context) {
+	rejects, err := h.importSvc.Errors(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeJobNotFound, "Import job not found")
+			return
+		}
+		apierror.Fail(c, err, "Failed to read error report")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="job-%s-errors.csv"`, c.Param("id")))
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"row", "user_id", "error"})
+	for _, reject := range rejects {
+		userID := ""
+		if reject.UserID != 0 {
+			userID = strconv.FormatUint(uint64(reject.UserID), 10)
+		}
+		writer.Write([]string{strconv.Itoa(reject.Row), userID, reject.Error})
+	}
+	writer.Flush()
+}
+
+// GetProtection godoc
+// @Summary Get the rollback protection mark
+// @Description Returns the active point-in-time protection mark, or null when protection is off
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.ProtectionMark
+// @Router /admin/protection [get]
+func (h *AdminHandler) GetProtection(c *gin.Context) {
+	mark, err := h.rollbackSvc.GetMark()
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch protection mark")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    mark,
+	})
+}
+
+// MarkProtection godoc
+// @Summary Mark a rollback protection point
+// @Description Snapshots every current rating; until cleared, reverts never take a user below it unless overridden. Replaces any previous mark.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]string false "Optional reason"
+// @Success 201 {object} models.ProtectionMark
+// @Router /admin/protection [post]
+func (h *AdminHandler) MarkProtection(c *gin.Context) {
+	// Parse request body (optional)
+	var req struct {
+		Reason string `json:"reason" binding:"max=500"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid request body. reason must be at most 500 characters")
+			return
+		}
+	}
+
+	mark, err := h.rollbackSvc.Mark(auth.FromContext(c).Actor(), req.Reason)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to set protection mark")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    mark,
+	})
+}
+
+// ClearProtection godoc
+// @Summary Clear the rollback protection mark
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/protection [delete]
+func (h *AdminHandler) ClearProtection(c *gin.Context) {
+	if err := h.rollbackSvc.ClearMark(auth.FromContext(c).Actor()); err != nil {
+		if errors.Is(err, service.ErrNoProtection) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeNoProtection, err.Error())
+			return
+		}
+		apierror.Fail(c, err, "Failed to clear protection mark")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// RevertScores godoc
+// @Summary Revert users to their rating at a point in time
+// @Description Restores each user's rating as of "to" from their raw score history, bypassing rating limits. While a protection mark is set the result never goes below the rating at the mark unless override is true. Every change is audited with source revert.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} true "to (RFC 3339), user_ids, optional override and reason"
+// @Success 200 {array} models.RevertResult
+// @Router /admin/scores/revert [post]
+func (h *AdminHandler) RevertScores(c *gin.Context) {
+	// Parse request body
+	var req struct {
+		To       time.Time `json:"to" binding:"required"`
+		UserIDs  []uint    `json:"user_ids" binding:"required,min=1"`
+		Override bool      `json:"override"`
+		Reason   string    `json:"reason" binding:"max=500"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. to (RFC 3339) and a non-empty user_ids array are required")
+		return
+	}
+	if len(req.UserIDs) > maxBulkScoreUpdates {
+		apierror.Abort(c, http.StatusBadRequest, fmt.Sprintf("At most %d users per request", maxBulkScoreUpdates))
+		return
+	}
+	if req.To.After(time.Now()) {
+		apierror.Abort(c, http.StatusBadRequest, "to must be in the past")
+		return
+	}
+
+	results, err := h.rollbackSvc.Revert(c.Request.Context(), auth.FromContext(c).Actor(), service.RevertRequest{
+		To:       req.To,
+		UserIDs:  req.UserIDs,
+		Override: req.Override,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		apierror.Fail(c, err, "Failed to revert scores")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    results,
+	})
+}
+
+// GetState godoc
+// @Summary Get operational state of this server
+// @Description Returns this server's node name, its last leaderboard integrity check (Redis vs PostgreSQL and vs other servers), the rollback protection mark, and PostgreSQL availability with DB sync catch-up progress
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/state [get]
+func (h *AdminHandler) GetState(c *gin.Context) {
+	mark, err := h.rollbackSvc.GetMark()
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch protection mark")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"node":       h.node,
+			"integrity":  h.integritySvc.LastReport(),
+			"protection": mark,
+			"postgres":   h.dbSync.CatchUpStatus(),
+		},
+	})
+}
+
+// ListStreamConsumers godoc
+// @Summary List Redis stream consumers
+// @Description Returns each consumer group (DB sync, async ingest) with its length, pending and lag, and every consumer with its pending count and idle time
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.StreamGroupInfo
+// @Router /admin/streams [get]
+func (h *AdminHandler) ListStreamConsumers(c *gin.Context) {
+	groups, err := h.streamMonitor.Groups()
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch stream consumers")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    groups,
+	})
+}
+
+// GetSyncStatus godoc
+// @Summary Get DB sync backlog and lag
+// @Description Shows how far PostgreSQL is behind Redis: DB sync stream length, pending and undelivered events, age of the oldest pending event, and the last successful sync of each consumer
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.SyncStatus
+// @Router /admin/sync/status [get]
+func (h *AdminHandler) GetSyncStatus(c *gin.Context) {
+	status, err := h.streamMonitor.SyncStatus()
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch DB sync status")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// RunBenchmark godoc
+// @Summary Benchmark Redis and PostgreSQL from this server
+// @Description Times ops ZADD and ZRANGE calls on a scratch sorted set and ops simple PostgreSQL selects, and compares each p50 with this server's stored baseline. degraded lists the stores that are slower than BENCHMARK_DEGRADED_RATIO times the baseline or failing. save_baseline stores this run as the new baseline.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} false "Optional ops (per operation, max 5000) and save_baseline"
+// @Success 200 {object} models.BenchmarkReport
+// @Router /admin/benchmark [post]
+func (h *AdminHandler) RunBenchmark(c *gin.Context) {
+	// Parse request body (optional)
+	var req struct {
+		Ops          int  `json:"ops" binding:"min=0"`
+		SaveBaseline bool `json:"save_baseline"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid request body. ops must be a non-negative integer")
+			return
+		}
+	}
+	if req.Ops > service.MaxBenchmarkOps {
+		apierror.Abort(c, http.StatusBadRequest, fmt.Sprintf("ops must be at most %d", service.MaxBenchmarkOps))
+		return
+	}
+
+	report, err := h.benchmarkSvc.Run(req.Ops, req.SaveBaseline)
+	if err != nil {
+		if errors.Is(err, service.ErrBenchmarkRunning) {
+			apierror.AbortCode(c, http.StatusConflict, service.CodeBenchmarkRunning, err.Error())
+			return
+		}
+		apierror.Fail(c, err, "Failed to run benchmark")
+		return
+	}
+	if req.SaveBaseline {
+		logging.FromContext(c.Request.Context()).Info("⏱️  Benchmark baseline saved", "node", h.node, "actor", auth.FromContext(c).Actor())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// GetBenchmarkBaseline godoc
+// @Summary Get this server's benchmark baseline
+// @Description Returns the stored baseline benchmark run, or null when none was saved
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.BenchmarkReport
+// @Router /admin/benchmark [get]
+func (h *AdminHandler) GetBenchmarkBaseline(c *gin.Context) {
+	baseline, err := h.benchmarkSvc.Baseline()
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch benchmark baseline")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    baseline,
+	})
+}
+
+// RebuildRedis godoc
+// @Summary Rebuild the Redis leaderboard and user cache from PostgreSQL
+// @Description Runs as a job (poll /admin/jobs/{id}). Users are written in pipelined batches onto the board matching their status and into the user cache. By default only missing entries are added, so updates served meanwhile are kept; overwrite replaces Redis ratings and cache entries with PostgreSQL's.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} false "Optional overwrite"
+// @Success 202 {object} models.Job
+// @Router /admin/rebuild [post]
+func (h *AdminHandler) RebuildRedis(c *gin.Context) {
+	// Parse request body (optional)
+	var req struct {
+		Overwrite bool `json:"overwrite"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid request body. overwrite must be a boolean")
+			return
+		}
+	}
+
+	job, err := h.rebuildSvc.Start(auth.FromContext(c).Actor(), req.Overwrite)
+	if err != nil {
+		if errors.Is(err, service.ErrJobQueueFull) {
+			apierror.AbortCode(c, http.StatusServiceUnavailable, service.CodeJobQueueFull, err.Error())
+			return
+		}
+		apierror.Fail(c, err, "Failed to start rebuild")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// ResetLeaderboard godoc
+// @Summary Archive and reset the leaderboard
+// @Description Runs as a job (poll /admin/jobs/{id}). Renames the global board to leaderboard:archive:<UTC time>, sets every user's rating to rating in PostgreSQL when given, re-seeds the Redis boards and user cache from PostgreSQL and broadcasts a leaderboard_reset event to WebSocket clients. Refused with 409 while the DB sync backlog is not empty, since queued events would write older ratings back; stop the simulator first. Requires the admin role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]int false "Optional rating (100-5000) to give every user"
+// @Success 202 {object} models.Job
+// @Router /admin/leaderboard/reset [post]
+func (h *AdminHandler) ResetLeaderboard(c *gin.Context) {
+	// Parse request body (optional)
+	var req struct {
+		Rating *int `json:"rating" binding:"omitempty,min=100,max=5000"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid request body. rating must be between 100 and 5000")
+			return
+		}
+	}
+
+	status, err := h.streamMonitor.SyncStatus()
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch DB sync status")
+		return
+	}
+	if status.Unsynced > 0 {
+		apierror.AbortDetails(c, http.StatusConflict, apierror.CodeConflict,
+			"DB sync backlog is not empty; stop score updates and retry",
+			gin.H{"unsynced": status.Unsynced})
+		return
+	}
+
+	job, err := h.rebuildSvc.StartReset(auth.FromContext(c).Actor(), req.Rating)
+	if err != nil {
+		if errors.Is(err, service.ErrJobQueueFull) {
+			apierror.AbortCode(c, http.StatusServiceUnavailable, service.CodeJobQueueFull, err.Error())
+			return
+		}
+		apierror.Fail(c, err, "Failed to start reset")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// StartReconcile godoc
+// @Summary Reconcile Redis with PostgreSQL
+// @Description Runs as a job (poll /admin/jobs/{id}). Compares a random sample (or, with mode full, every user) of PostgreSQL users with the Redis boards, re-checks the differing ones after the settle delay and repairs the drift that stayed: rating drift from the chosen source (redis, postgres, or none to only report), missing and banned users from PostgreSQL, orphaned board members by removal. Mode and source default to RECONCILE_MODE and RECONCILE_SOURCE.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} false "Optional mode and source"
+// @Success 202 {object} models.Job
+// @Router /admin/reconcile [post]
+func (h *AdminHandler) StartReconcile(c *gin.Context) {
+	// Parse request body (optional)
+	var req service.ReconcileOptions
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	job, err := h.reconcileSvc.Submit(auth.FromContext(c).Actor(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidReconcile):
+			apierror.AbortCode(c, http.StatusBadRequest, service.CodeValidation, err.Error())
+		case errors.Is(err, service.ErrJobQueueFull):
+			apierror.AbortCode(c, http.StatusServiceUnavailable, service.CodeJobQueueFull, err.Error())
+		default:
+			apierror.Fail(c, err, "Failed to start reconcile")
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// GetReconcileReport godoc
+// @Summary Latest reconcile report
+// @Description Returns the last reconcile run of any server: users checked, confirmed drift by kind, in-flight users, repairs and sample mismatches. data is null before the first run.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.ReconcileReport
+// @Router /admin/reconcile [get]
+func (h *AdminHandler) GetReconcileReport(c *gin.Context) {
+	report, err := h.reconcileSvc.LastReport()
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch reconcile report")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// ListScheduledJobs godoc
+// @Summary List scheduled jobs
+// @Description Returns every scheduled job with its schedule, next occurrence and last run by any server (null before the first). running is about this server only.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.ScheduledJob
+// @Router /admin/schedule [get]
+func (h *AdminHandler) ListScheduledJobs(c *gin.Context) {
+	jobs, err := h.scheduler.Jobs()
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch scheduled jobs")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"count":   len(jobs),
+		"data":    jobs,
+	})
+}
+
+// StartImpersonation godoc
+// @Summary Start a support impersonation session
+// @Description Returns a short-lived token; requests sent with it in X-Impersonation-Token see the API exactly as the user would. Sessions are read-only and every request is audited. The token is shown only once.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} true "user_id, reason and optional ttl (e.g. 15m)"
+// @Success 201 {object} map[string]interface{}
+// @Router /admin/impersonations [post]
+func (h *AdminHandler) StartImpersonation(c *gin.Context) {
+	// Parse request body
+	var req struct {
+		UserID uint   `json:"user_id" binding:"required"`
+		Reason string `json:"reason" binding:"required,max=500"`
+		TTL    string `json:"ttl"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. user_id and reason (max 500 characters) are required")
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		parsed, err := time.ParseDuration(req.TTL)
+		if err != nil || parsed <= 0 {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid ttl. Use Go duration syntax (e.g. 15m)")
+			return
+		}
+		ttl = parsed
+	}
+
+	session, token, err := h.impersonation.Start(auth.FromContext(c).Actor(), req.UserID, req.Reason, ttl)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeUserNotFound, "User not found")
+			return
+		}
+		apierror.Fail(c, err, "Failed to start impersonation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data": gin.H{
+			"session": session,
+			"token":   token,
+		},
+	})
+}
+
+// EndImpersonation godoc
+// @Summary End an impersonation session
+// @Tags admin
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/impersonations/{id} [delete]
+func (h *AdminHandler) EndImpersonation(c *gin.Context) {
+	if err := h.impersonation.End(c.Param("id"), auth.FromContext(c).Actor()); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeSessionNotFound, "Impersonation session not found or already expired")
+			return
+		}
+		apierror.Fail(c, err, "Failed to end impersonation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// ListImpersonations godoc
+// @Summary List impersonation audit events
+// @Description Session starts, ends and every request made while impersonating, newest first
+// @Tags admin
+// @Produce json
+// @Param user_id query int false "Impersonated user"
+// @Param admin query string false "Admin actor"
+// @Param session_id query string false "Session ID"
+// @Param before_id query int false "Keyset cursor (next_before_id)"
+// @Param limit query int false "Max events (default 100, max 1000)"
+// @Success 200 {array} models.ImpersonationEvent
+// @Router /admin/impersonations [get]
+func (h *AdminHandler) ListImpersonations(c *gin.Context) {
+	var filter repository.ImpersonationFilter
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		filter.UserID = uint(userID)
+	}
+	if beforeStr := c.Query("before_id"); beforeStr != "" {
+		beforeID, err := strconv.ParseUint(beforeStr, 10, 32)
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid before_id")
+			return
+		}
+		filter.BeforeID = uint(beforeID)
+	}
+	filter.Admin = c.Query("admin")
+	filter.SessionID = c.Query("session_id")
+
+	// Parse limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000 // Max limit
+	}
+	filter.Limit = limit
+
+	events, err := h.impersonation.ListEvents(filter)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch impersonation events")
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"count":   len(events),
+		"data":    events,
+	}
+	if len(events) == limit {
+		response["next_before_id"] = events[len(events)-1].ID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// webhookRequest is the body of webhook subscription creates and updates
+type webhookRequest struct {
+	URL        string   `json:"url" binding:"required,max=500"`
+	Secret     string   `json:"secret" binding:"max=128"`
+	Events     []string `json:"events" binding:"required"`
+	Milestones []int64  `json:"milestones"`
+	TopN       int64    `json:"top_n"`
+	UserIDs    []uint   `json:"user_ids"`
+	Active     *bool    `json:"active"`
+}
+
+func (r *webhookRequest) subscription() *models.WebhookSubscription {
+	sub := &models.WebhookSubscription{
+		URL:        r.URL,
+		Secret:     r.Secret,
+		Events:     r.Events,
+		Milestones: r.Milestones,
+		TopN:       r.TopN,
+		UserIDs:    r.UserIDs,
+		Active:     true,
+	}
+	if r.Active != nil {
+		sub.Active = *r.Active
+	}
+	return sub
+}
+
+// ListWebhooks godoc
+// @Summary List webhook subscriptions
+// @Description Secrets are never returned
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.WebhookSubscription
+// @Router /admin/webhooks [get]
+func (h *AdminHandler) ListWebhooks(c *gin.Context) {
+	subs, err := h.webhookSvc.List()
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch webhook subscriptions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"count":   len(subs),
+		"data":    subs,
+	})
+}
+
+// CreateWebhook godoc
+// @Summary Register a webhook subscription
+// @Description Score updates matching the filters are POSTed to url, signed with HMAC-SHA256 of the secret (X-Leaderboard-Signature over timestamp + "." + body). Events: rank_milestone (the user reached one of milestones), top_entry (the user entered the top top_n, default 10), user_update (any update of user_ids). user_ids, when set, limits every event to those users. A secret is generated when none is given; it is only returned here. Requires the admin role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} true "url, events and filters, optional secret"
+// @Success 201 {object} models.WebhookSubscription
+// @Router /admin/webhooks [post]
+func (h *AdminHandler) CreateWebhook(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. url and events are required")
+		return
+	}
+
+	sub := req.subscription()
+	sub.CreatedBy = auth.FromContext(c).Actor()
+	if err := h.webhookSvc.Create(sub); err != nil {
+		if errors.Is(err, service.ErrInvalidWebhook) {
+			apierror.AbortCode(c, http.StatusBadRequest, service.CodeValidation, err.Error())
+			return
+		}
+		apierror.Fail(c, err, "Failed to create webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook subscription
+// @Tags admin
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} models.WebhookSubscription
+// @Router /admin/webhooks/{id} [get]
+func (h *AdminHandler) GetWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid subscription ID")
+		return
+	}
+
+	sub, err := h.webhookSvc.Get(uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeWebhookNotFound, "Webhook subscription not found")
+			return
+		}
+		apierror.Fail(c, err, "Failed to fetch webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// UpdateWebhook godoc
+// @Summary Replace a webhook subscription
+// @Description Replaces the url, filters and active flag. An omitted secret keeps the current one. Pending deliveries keep their original target. Requires the admin role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param body body map[string]interface{} true "url, events and filters, optional secret and active"
+// @Success 200 {object} models.WebhookSubscription
+// @Router /admin/webhooks/{id} [put]
+func (h *AdminHandler) UpdateWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid subscription ID")
+		return
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. url and events are required")
+		return
+	}
+
+	sub := req.subscription()
+	sub.ID = uint(id)
+	updated, err := h.webhookSvc.Update(sub)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWebhookNotFound):
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeWebhookNotFound, "Webhook subscription not found")
+		case errors.Is(err, service.ErrInvalidWebhook):
+			apierror.AbortCode(c, http.StatusBadRequest, service.CodeValidation, err.Error())
+		default:
+			apierror.Fail(c, err, "Failed to update webhook subscription")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook subscription
+// @Description Also deletes its pending deliveries and delivery log. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/webhooks/{id} [delete]
+func (h *AdminHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid subscription ID")
+		return
+	}
+
+	if err := h.webhookSvc.Delete(uint(id)); err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeWebhookNotFound, "Webhook subscription not found")
+			return
+		}
+		apierror.Fail(c, err, "Failed to delete webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Description The delivery log, newest first: pending deliveries with their next attempt, delivered ones and those that failed after WEBHOOK_MAX_ATTEMPTS, with the last error
+// @Tags admin
+// @Produce json
+// @Param subscription_id query int false "Only deliveries of this subscription"
+// @Param user_id query int false "Only deliveries about this user"
+// @Param status query string false "pending, delivered or failed"
+// @Param before_id query int false "Keyset cursor (next_before_id)"
+// @Param limit query int false "Max deliveries (default 100, max 1000)"
+// @Success 200 {array} models.WebhookDelivery
+// @Router /admin/webhooks/deliveries [get]
+func (h *AdminHandler) ListWebhookDeliveries(c *gin.Context) {
+	var filter repository.WebhookDeliveryFilter
+
+	for param, target := range map[string]*uint{
+		"subscription_id": &filter.SubscriptionID,
+		"user_id":         &filter.UserID,
+		"before_id":       &filter.BeforeID,
+	} {
+		if value := c.Query(param); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				apierror.Abort(c, http.StatusBadRequest, "Invalid "+param)
+				return
+			}
+			*target = uint(parsed)
+		}
+	}
+
+	switch status := c.Query("status"); status {
+	case "", models.WebhookPending, models.WebhookDelivered, models.WebhookFailed:
+		filter.Status = status
+	default:
+		apierror.Abort(c, http.StatusBadRequest, "Invalid status. Use pending, delivered or failed")
+		return
+	}
+
+	// Parse limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000 // Max limit
+	}
+	filter.Limit = limit
+
+	deliveries, err := h.webhookSvc.Deliveries(filter)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch webhook deliveries")
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"count":   len(deliveries),
+		"data":    deliveries,
+	}
+	if len(deliveries) == limit {
+		response["next_before_id"] = deliveries[len(deliveries)-1].ID
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/fields"
+	"github.com/gin-gonic/gin"
+)
+
+// parseFields reads ?fields= for a list of T (400 for an unknown field)
+func parseFields[T any](c *gin.Context) (fields.Set, bool) {
+	set, err := fields.Parse[T](c.Query("fields"))
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid fields: "+err.Error())
+		return nil, false
+	}
+	return set, true
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	started time.Time
+}
+
+func NewHealthHandler() *HealthHandler {
+	return &HealthHandler{started: time.Now()}
+}
+
+// Live godoc
+// @Summary Liveness check
+// @Description Reports that the process is up and serving requests. Dependencies are not checked (see /health/ready), so an outage does not get the process restarted.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /health/live [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "alive",
+		"uptime": time.Since(h.started).Round(time.Second).String(),
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// Ready godoc
+// @Summary Readiness check
+// @Description Runs all registered readiness checks (Redis, PostgreSQL, the event bus subscription, the DB sync worker, the canary) and returns 503 if any fail, with each check's result
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /health/ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	results, healthy := health.RunAll(ctx)
+
+	status := http.StatusOK
+	state := "ready"
+	if !healthy {
+		status = http.StatusServiceUnavailable
+		state = "not_ready"
+	}
+
+	c.JSON(status, gin.H{
+		"status": state,
+		"checks": results,
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
@@ -1,30 +1,99 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apiversion"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/fields"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/logging"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/ranking"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/schedule"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
+// Maximum items accepted by the bulk endpoints
+const (
+	maxBulkRankLookups  = 1000
+	maxBulkScoreUpdates = 500
+)
+
+// degradedHeader marks responses served while Redis is unavailable
+const degradedHeader = "X-Degraded-Mode"
+
+// Write responses while PostgreSQL is behind: the backpressure level
+// (slow or full) and the DB sync backlog behind it
+const (
+	backpressureHeader = "X-Backpressure"
+	syncBacklogHeader  = "X-DB-Sync-Backlog"
+)
+
 type LeaderboardHandler struct {
 	leaderboardSvc service.LeaderboardService
+	auditSvc       service.AuditService
+	periodSvc      service.PeriodBoardService
+	ingestSvc      service.IngestService
+	replaySvc      service.ScoreReplayService
+	matchmakingSvc service.MatchmakingService
+	asyncDefault   bool
+	fastDefault    bool
 }
 
-func NewLeaderboardHandler(leaderboardSvc service.LeaderboardService) *LeaderboardHandler {
+func NewLeaderboardHandler(
+	leaderboardSvc service.LeaderboardService,
+	auditSvc service.AuditService,
+	periodSvc service.PeriodBoardService,
+	ingestSvc service.IngestService,
+	replaySvc service.ScoreReplayService,
+	matchmakingSvc service.MatchmakingService,
+	ingestCfg config.IngestConfig,
+) *LeaderboardHandler {
 	return &LeaderboardHandler{
 		leaderboardSvc: leaderboardSvc,
+		auditSvc:       auditSvc,
+		periodSvc:      periodSvc,
+		ingestSvc:      ingestSvc,
+		replaySvc:      replaySvc,
+		matchmakingSvc: matchmakingSvc,
+		asyncDefault:   ingestCfg.DefaultAsync,
+		fastDefault:    ingestCfg.DefaultFast,
+	}
+}
+
+// markDegraded flags a response served from PostgreSQL because Redis is down
+func (h *LeaderboardHandler) markDegraded(c *gin.Context) {
+	if h.leaderboardSvc.Degraded() {
+		c.Header(degradedHeader, "redis-unavailable")
+	}
+}
+
+// markPressure flags write responses while the DB sync backlog is high and
+// reports whether it is full (score writes are refused)
+func (h *LeaderboardHandler) markPressure(c *gin.Context) bool {
+	level, backlog := h.leaderboardSvc.SyncPressure()
+	if level == models.PressureNone {
+		return false
 	}
+	c.Header(backpressureHeader, level)
+	c.Header(syncBacklogHeader, strconv.FormatInt(backlog, 10))
+	return level == models.PressureFull
 }
 
 // GetLeaderboard godoc
 // @Summary Get top users leaderboard
-// @Description Returns the top N users with their ranks
+// @Description Returns the top N users with their ranks. While Redis is unavailable the board is read from PostgreSQL and the response carries X-Degraded-Mode.
 // @Tags leaderboard
 // @Accept json
 // @Produce json
 // @Param limit query int false "Number of users to return" default(100)
+// @Param fields query string false "Comma-separated fields of each entry, e.g. rank,username (default all)"
 // @Success 200 {array} models.LeaderboardEntry
 // @Router /leaderboard [get]
 func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
@@ -37,20 +106,23 @@ func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
 	if limit > 1000 {
 		limit = 1000 // Max limit
 	}
+	selected, ok := parseFields[models.LeaderboardEntry](c)
+	if !ok {
+		return
+	}
 
-	// Get leaderboard
-	entries, err := h.leaderboardSvc.GetLeaderboard(limit)
+	// Get leaderboard (usernames come from the user cache, skipped when not asked for)
+	entries, err := h.leaderboardSvc.GetLeaderboard(limit, auth.FromContext(c).UserID, selected.Has("username"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to fetch leaderboard",
-		})
+		apierror.Fail(c, err, "Failed to fetch leaderboard")
 		return
 	}
 
+	h.markDegraded(c)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"count":   len(entries),
-		"data":    entries,
+		"data":    fields.Select(entries, selected),
 	})
 }
 
@@ -68,21 +140,18 @@ func (h *LeaderboardHandler) GetUserRank(c *gin.Context) {
 	userIDStr := c.Param("user_id")
 	userID, err := strconv.ParseUint(userIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid user ID",
-		})
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 
 	// Get rank
-	rank, err := h.leaderboardSvc.GetUserRank(uint(userID))
+	rank, err := h.leaderboardSvc.GetUserRankAs(uint(userID), auth.FromContext(c).UserID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found in leaderboard",
-		})
+		apierror.Fail(c, err, "Failed to get user rank")
 		return
 	}
 
+	h.markDegraded(c)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"user_id": userID,
@@ -92,49 +161,117 @@ func (h *LeaderboardHandler) GetUserRank(c *gin.Context) {
 
 // UpdateUserScore godoc
 // @Summary Update user's score
-// @Description Updates a user's rating and recalculates their rank
+// @Description Updates a user's rating and recalculates their rank. The change is recorded in the audit log with the caller and optional reason. With async=true the update is only queued and 202 is returned with a tracking ID. With fast=true the rating is applied and 202 is returned with it at once; the old/new rank and deltas follow in the score_update WebSocket broadcast. While Redis is unavailable the update is stored for replay and 202 is returned with deferred=true.
 // @Tags leaderboard
 // @Accept json
 // @Produce json
 // @Param user_id path int true "User ID"
-// @Param body body map[string]int true "New Rating"
+// @Param async query bool false "Queue the update instead of applying it (default from SCORE_UPDATE_MODE)"
+// @Param fast query bool false "Apply the update and answer before ranks are computed (default from SCORE_UPDATE_MODE)"
+// @Param body body map[string]interface{} true "New rating and optional reason"
 // @Success 200 {object} map[string]interface{}
+// @Success 202 {object} models.IngestStatus
 // @Router /leaderboard/user/{user_id}/score [put]
 func (h *LeaderboardHandler) UpdateUserScore(c *gin.Context) {
 	// Parse user ID
 	userIDStr := c.Param("user_id")
 	userID, err := strconv.ParseUint(userIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid user ID",
-		})
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 
 	// Parse request body
 	var req struct {
-		NewRating int `json:"new_rating" binding:"required,min=100,max=5000"`
+		NewRating int    `json:"new_rating" binding:"required,min=100,max=5000"`
+		Reason    string `json:"reason" binding:"max=500"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body. new_rating must be between 100 and 5000",
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. new_rating must be between 100 and 5000")
+		return
+	}
+
+	actor := auth.FromContext(c).Actor()
+
+	// Redis down (or older queued updates not replayed yet): queue for replay
+	if h.replaySvc.ShouldDefer() {
+		h.deferScore(c, uint(userID), req.NewRating, actor, req.Reason)
+		return
+	}
+	full := h.markPressure(c)
+
+	// Queue only; the caller polls the status endpoint for the outcome
+	async := h.asyncDefault
+	if v, err := strconv.ParseBool(c.Query("async")); err == nil {
+		async = v
+	}
+	if async {
+		if full {
+			apierror.Fail(c, service.ErrSyncBacklogFull, "Failed to queue score update")
+			return
+		}
+		status, err := h.ingestSvc.Enqueue(c.Request.Context(), uint(userID), req.NewRating, actor, req.Reason)
+		if err != nil {
+			apierror.Abort(c, http.StatusServiceUnavailable, "Failed to queue score update")
+			return
+		}
+		c.Header("Location", apiversion.Path(c, "/leaderboard/updates/"+status.ID))
+		c.JSON(http.StatusAccepted, gin.H{
+			"success": true,
+			"data":    status,
+		})
+		return
+	}
+
+	// Apply now, answer before ranks are read; they follow over the WebSocket
+	fast := h.fastDefault
+	if v, err := strconv.ParseBool(c.Query("fast")); err == nil {
+		fast = v
+	}
+	if fast {
+		payload, err := h.leaderboardSvc.UpdateUserScoreFast(c.Request.Context(), uint(userID), req.NewRating)
+		if err != nil {
+			if errors.Is(err, service.ErrRedisUnavailable) {
+				h.deferScore(c, uint(userID), req.NewRating, actor, req.Reason)
+				return
+			}
+			apierror.Fail(c, err, "Failed to update score")
+			return
+		}
+		h.auditScore(c, actor, req.Reason, payload)
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"success":       true,
+			"ranks_pending": true,
+			"clamped":       payload.NewRating != req.NewRating,
+			"user_id":       payload.UserID,
+			"username":      payload.Username,
+			"old_rating":    payload.OldRating,
+			"new_rating":    payload.NewRating,
+			"rating_delta":  payload.RatingDelta,
+			"timestamp":     payload.Timestamp,
 		})
 		return
 	}
 
 	// Update score (Redis-first, returns payload with rank delta)
-	payload, err := h.leaderboardSvc.UpdateUserScore(uint(userID), req.NewRating)
+	payload, err := h.leaderboardSvc.UpdateUserScore(c.Request.Context(), uint(userID), req.NewRating)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update score",
-		})
+		if errors.Is(err, service.ErrRedisUnavailable) {
+			h.deferScore(c, uint(userID), req.NewRating, actor, req.Reason)
+			return
+		}
+		apierror.Fail(c, err, "Failed to update score")
 		return
 	}
 
-	// Return full payload with rank delta
+	h.auditScore(c, actor, req.Reason, payload)
+
+	// Return full payload with rank delta (clamped when the limit cut the change)
 	c.JSON(http.StatusOK, gin.H{
 		"success":      true,
+		"clamped":      payload.NewRating != req.NewRating,
 		"user_id":      payload.UserID,
 		"username":     payload.Username,
 		"old_rating":   payload.OldRating,
@@ -147,6 +284,371 @@ func (h *LeaderboardHandler) UpdateUserScore(c *gin.Context) {
 	})
 }
 
+// auditScore records an applied single score update
+func (h *LeaderboardHandler) auditScore(c *gin.Context, actor, reason string, payload *models.ScoreUpdatePayload) {
+	if err := h.auditSvc.RecordAdjustments(actor, reason, service.AdjustmentSingle,
+		[]*models.ScoreUpdatePayload{payload}); err != nil {
+		logging.FromContext(c.Request.Context()).Warn("⚠️  Failed to audit score change", "user_id", payload.UserID, "actor", actor, "error", err)
+	}
+}
+
+// deferScore queues a score update for replay once Redis is back (202)
+func (h *LeaderboardHandler) deferScore(c *gin.Context, userID uint, newRating int, actor, reason string) {
+	update, err := h.replaySvc.Defer(userID, newRating, actor, reason, service.AdjustmentSingle)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to queue score update")
+		return
+	}
+
+	h.markDegraded(c)
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":  true,
+		"deferred": true,
+		"data":     update,
+	})
+}
+
+// SubmitResult godoc
+// @Summary Submit a game result
+// @Description Turns a result into the user's new rating with the board's ranking strategy (RANKING_STRATEGY, shown in /leaderboard/stats): rating for absolute, delta for delta, score (1 win, 0.5 draw, 0 loss) and opponent_id or opponent_rating for elo, glicko and trueskill. The new rating is applied like a score update, rating limits included, and audited with source result. An opponent_id also counts as a match for matchmaking.
+// @Tags leaderboard
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param body body map[string]interface{} true "Result fields for the board's strategy and optional reason"
+// @Success 200 {object} map[string]interface{}
+// @Router /leaderboard/user/{user_id}/results [post]
+func (h *LeaderboardHandler) SubmitResult(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		ranking.Result
+		Reason string `json:"reason" binding:"max=500"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	h.markPressure(c)
+	payload, err := h.leaderboardSvc.SubmitResult(c.Request.Context(), uint(userID), req.Result)
+	if err != nil {
+		if errors.Is(err, service.ErrRedisUnavailable) {
+			h.markDegraded(c)
+		}
+		apierror.Fail(c, err, "Failed to apply result")
+		return
+	}
+
+	actor := auth.FromContext(c).Actor()
+	if err := h.auditSvc.RecordAdjustments(actor, req.Reason, service.AdjustmentResult,
+		[]*models.ScoreUpdatePayload{payload}); err != nil {
+		logging.FromContext(c.Request.Context()).Warn("⚠️  Failed to audit result", "user_id", payload.UserID, "actor", actor, "error", err)
+	}
+	if req.OpponentID != nil {
+		if err := h.matchmakingSvc.RecordMatch(payload.UserID, *req.OpponentID); err != nil {
+			logging.FromContext(c.Request.Context()).Warn("⚠️  Failed to record match", "user_id", payload.UserID, "opponent_id", *req.OpponentID, "error", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"user_id":      payload.UserID,
+		"username":     payload.Username,
+		"old_rating":   payload.OldRating,
+		"new_rating":   payload.NewRating,
+		"rating_delta": payload.RatingDelta,
+		"old_rank":     payload.OldRank,
+		"new_rank":     payload.NewRank,
+		"rank_delta":   payload.RankDelta,
+		"timestamp":    payload.Timestamp,
+	})
+}
+
+// GetUpdateStatus godoc
+// @Summary Get the status of a queued score update
+// @Description Returns queued, applied (with the rank delta) or failed for a tracking ID returned by an async score update
+// @Tags leaderboard
+// @Produce json
+// @Param id path string true "Tracking ID"
+// @Success 200 {object} models.IngestStatus
+// @Router /leaderboard/updates/{id} [get]
+func (h *LeaderboardHandler) GetUpdateStatus(c *gin.Context) {
+	status, err := h.ingestSvc.GetStatus(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrUpdateNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeUpdateNotFound, "Score update not found or expired")
+			return
+		}
+		apierror.Fail(c, err, "Failed to fetch score update status")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// GetUserRanks godoc
+// @Summary Get ranks for many users
+// @Description Returns the global rank of up to 1000 users in one call
+// @Tags leaderboard
+// @Accept json
+// @Produce json
+// @Param body body map[string][]int true "User IDs"
+// @Success 200 {array} models.UserRankResult
+// @Router /leaderboard/ranks [post]
+func (h *LeaderboardHandler) GetUserRanks(c *gin.Context) {
+	// Parse request body
+	var req struct {
+		UserIDs []uint `json:"user_ids" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. user_ids must be a non-empty array")
+		return
+	}
+	if len(req.UserIDs) > maxBulkRankLookups {
+		apierror.Abort(c, http.StatusBadRequest, fmt.Sprintf("At most %d user_ids per request", maxBulkRankLookups))
+		return
+	}
+
+	results := h.leaderboardSvc.GetUserRanks(req.UserIDs)
+
+	h.markDegraded(c)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"count":   len(results),
+		"data":    results,
+	})
+}
+
+// BulkUpdateScores godoc
+// @Summary Update many users' scores
+// @Description Applies up to 500 score updates in order and reports the outcome of each. While Redis is unavailable updates are stored for replay and reported as deferred.
+// @Tags leaderboard
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} true "Score updates and optional reason"
+// @Success 200 {array} models.BulkScoreResult
+// @Router /leaderboard/scores [post]
+func (h *LeaderboardHandler) BulkUpdateScores(c *gin.Context) {
+	// Parse request body
+	var req struct {
+		Updates []models.ScoreUpdateRequest `json:"updates" binding:"required,min=1,dive"`
+		Reason  string                      `json:"reason" binding:"max=500"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. Each update needs user_id and new_rating between 100 and 5000")
+		return
+	}
+	if len(req.Updates) > maxBulkScoreUpdates {
+		apierror.Abort(c, http.StatusBadRequest, fmt.Sprintf("At most %d updates per request", maxBulkScoreUpdates))
+		return
+	}
+
+	// Redis down (or older queued updates not replayed yet): queue them all
+	var results []models.BulkScoreResult
+	if h.replaySvc.ShouldDefer() {
+		results = make([]models.BulkScoreResult, len(req.Updates))
+		for i, update := range req.Updates {
+			results[i] = models.BulkScoreResult{UserID: update.UserID, Code: service.CodeRedisUnavailable}
+		}
+	} else {
+		results = h.leaderboardSvc.BulkUpdateScores(c.Request.Context(), req.Updates)
+	}
+
+	actor := auth.FromContext(c).Actor()
+
+	failed, deferred := 0, 0
+	applied := make([]*models.ScoreUpdatePayload, 0, len(results))
+	for i := range results {
+		result := &results[i]
+		if result.Code == service.CodeRedisUnavailable {
+			update, err := h.replaySvc.Defer(result.UserID, req.Updates[i].NewRating, actor, req.Reason, service.AdjustmentBulk)
+			if err == nil {
+				*result = models.BulkScoreResult{UserID: result.UserID, Deferred: update}
+				deferred++
+				continue
+			}
+			result.Error = err.Error()
+		}
+		if result.Error != "" {
+			failed++
+			continue
+		}
+		applied = append(applied, result.Update)
+	}
+
+	if err := h.auditSvc.RecordAdjustments(actor, req.Reason, service.AdjustmentBulk, applied); err != nil {
+		logging.FromContext(c.Request.Context()).Warn("⚠️  Failed to audit bulk score changes", "count", len(applied), "actor", actor, "error", err)
+	}
+
+	h.markDegraded(c)
+	h.markPressure(c)
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"count":    len(results),
+		"failed":   failed,
+		"deferred": deferred,
+		"data":     results,
+	})
+}
+
+// GetPeriodBoard godoc
+// @Summary Get the daily or weekly board
+// @Description Top rating gainers of the current day/week. Periods start at local midnight (weeks on Monday) of the given timezone, else of the X-User-ID caller's timezone, else UTC.
+// @Tags leaderboard
+// @Produce json
+// @Param period path string true "daily or weekly"
+// @Param tz query string false "IANA timezone, e.g. Asia/Kolkata"
+// @Param limit query int false "Number of users to return" default(100)
+// @Param fields query string false "Comma-separated fields of each entry, e.g. rank,username (default all)"
+// @Success 200 {array} models.PeriodEntry
+// @Router /leaderboard/period/{period} [get]
+func (h *LeaderboardHandler) GetPeriodBoard(c *gin.Context) {
+	period, err := schedule.ParsePeriod(c.Param("period"))
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid period. Use daily or weekly")
+		return
+	}
+
+	// Parse limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000 // Max limit
+	}
+
+	selected, ok := parseFields[models.PeriodEntry](c)
+	if !ok {
+		return
+	}
+
+	window, entries, err := h.periodSvc.GetBoard(period, c.Query("tz"), auth.FromContext(c).UserID, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTimezone) {
+			apierror.AbortCode(c, http.StatusBadRequest, service.CodeValidation, "Invalid timezone. Use an IANA name such as Asia/Kolkata")
+			return
+		}
+		apierror.Fail(c, err, "Failed to fetch board")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"window":  window,
+		"count":   len(entries),
+		"data":    fields.Select(entries, selected),
+	})
+}
+
+// GetMostImproved godoc
+// @Summary Get the most improved players
+// @Description Players with the biggest net rating gain over the last MOST_IMPROVED_WINDOW (24 hours by default, counted in whole hours). Only players who gained rating are listed; the board is recomputed at most every MOST_IMPROVED_CACHE_TTL.
+// @Tags leaderboard
+// @Produce json
+// @Param limit query int false "Number of users to return" default(100)
+// @Success 200 {array} models.PeriodEntry
+// @Router /leaderboard/most-improved [get]
+func (h *LeaderboardHandler) GetMostImproved(c *gin.Context) {
+	// Parse limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000 // Max limit
+	}
+
+	entries, err := h.periodSvc.GetMostImproved(limit)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch most improved board")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"count":   len(entries),
+		"data":    entries,
+	})
+}
+
+// GetTiers godoc
+// @Summary List the rating tiers
+// @Description The tier ladder from the lowest tier up: each tier's name, rating range and number of divisions (division 1 is the highest). Empty when tiers are disabled.
+// @Tags leaderboard
+// @Produce json
+// @Success 200 {array} tiers.Tier
+// @Router /leaderboard/tiers [get]
+func (h *LeaderboardHandler) GetTiers(c *gin.Context) {
+	ladder := h.leaderboardSvc.Tiers()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"count":   len(ladder),
+		"data":    ladder,
+	})
+}
+
+// GetTierBoard godoc
+// @Summary Get the players in a tier
+// @Description The players rated within a tier, highest first, with their global ranks. Page with limit and offset; total is the number of players in the tier.
+// @Tags leaderboard
+// @Produce json
+// @Param tier path string true "Tier name, e.g. gold"
+// @Param limit query int false "Number of users to return" default(100)
+// @Param offset query int false "Players of the tier to skip" default(0)
+// @Param fields query string false "Comma-separated fields of each entry, e.g. rank,username (default all)"
+// @Success 200 {array} models.LeaderboardEntry
+// @Router /leaderboard/tier/{tier} [get]
+func (h *LeaderboardHandler) GetTierBoard(c *gin.Context) {
+	// Parse limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000 // Max limit
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	selected, ok := parseFields[models.LeaderboardEntry](c)
+	if !ok {
+		return
+	}
+
+	board, err := h.leaderboardSvc.GetTierBoard(c.Param("tier"), offset, limit, selected.Has("username"))
+	if err != nil {
+		if errors.Is(err, service.ErrRedisUnavailable) {
+			h.markDegraded(c)
+		}
+		apierror.Fail(c, err, "Failed to fetch tier")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"tier":    board.Tier,
+		"total":   board.Total,
+		"count":   len(board.Entries),
+		"data":    fields.Select(board.Entries, selected),
+	})
+}
+
 // GetStats godoc
 // @Summary Get leaderboard statistics
 // @Description Returns statistics about the leaderboard
@@ -158,9 +660,7 @@ func (h *LeaderboardHandler) UpdateUserScore(c *gin.Context) {
 func (h *LeaderboardHandler) GetStats(c *gin.Context) {
 	stats, err := h.leaderboardSvc.GetLeaderboardStats()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to fetch stats",
-		})
+		apierror.Fail(c, err, "Failed to fetch stats")
 		return
 	}
 
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/fairqueue"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+type LimitsHandler struct {
+	limiter   *ratelimit.Limiter // nil when rate limiting is disabled
+	fairQueue *fairqueue.Scheduler
+}
+
+func NewLimitsHandler(limiter *ratelimit.Limiter, fairQueue *fairqueue.Scheduler) *LimitsHandler {
+	return &LimitsHandler{
+		limiter:   limiter,
+		fairQueue: fairQueue,
+	}
+}
+
+// GetLimits godoc
+// @Summary Describe the caller's quotas
+// @Description Returns the caller's tier, its request rate limit with what is left in the current window, and how many fair-queued (bulk) requests it may run at once
+// @Tags limits
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /limits [get]
+func (h *LimitsHandler) GetLimits(c *gin.Context) {
+	principal := auth.FromContext(c)
+
+	rate := gin.H{"enabled": false}
+	if h.limiter != nil {
+		result, err := h.limiter.Peek(c.Request.Context(), principal.Fingerprint(), principal.Tier)
+		if err != nil {
+			apierror.Fail(c, err, "Failed to fetch rate limit state")
+			return
+		}
+
+		rate = gin.H{
+			"enabled":        true,
+			"limit":          result.Limit, // 0 = unlimited
+			"window_seconds": int(h.limiter.Window().Seconds()),
+		}
+		if result.Limit > 0 {
+			rate["remaining"] = result.Remaining
+			rate["reset"] = result.Reset.Unix()
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"caller":        principal.Fingerprint(),
+			"tier":          principal.Tier,
+			"authenticated": principal.Authenticated,
+			"rate_limit":    rate,
+			"concurrency": gin.H{
+				"bulk_slots": h.fairQueue.Weight(principal.Tier),
+			},
+		},
+	})
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/fairqueue"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/ratelimit"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+type limitsResponse struct {
+	Data struct {
+		Caller        string                 `json:"caller"`
+		Tier          string                 `json:"tier"`
+		Authenticated bool                   `json:"authenticated"`
+		RateLimit     map[string]interface{} `json:"rate_limit"`
+		Concurrency   struct {
+			BulkSlots int `json:"bulk_slots"`
+		} `json:"concurrency"`
+	} `json:"data"`
+}
+
+// getLimits calls GET /limits as principal and decodes the reply
+func getLimits(t *testing.T, h *LimitsHandler, principal *auth.Principal) limitsResponse {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/limits", func(c *gin.Context) { auth.SetPrincipal(c, principal) }, h.GetLimits)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/limits", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", rec.Code, rec.Body)
+	}
+	var resp limitsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestGetLimits(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	limiter := ratelimit.New(client, time.Minute, map[string]int{auth.AnonymousTier: 10, "premium": 0})
+	fairQueue := fairqueue.NewScheduler(8, map[string]int{"premium": 4}, time.Second)
+	anonymous := &auth.Principal{Key: "ip:1.2.3.4", Tier: auth.AnonymousTier}
+	premium := &auth.Principal{Key: "key-1", Tier: "premium", Authenticated: true}
+
+	// Looking at the limits does not use up a request
+	for range 3 {
+		if _, err := limiter.Allow(context.Background(), anonymous.Fingerprint(), anonymous.Tier); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 2 {
+		resp := getLimits(t, NewLimitsHandler(limiter, fairQueue), anonymous)
+		if got := resp.Data.RateLimit["remaining"]; got != float64(7) {
+			t.Errorf("remaining = %v, want 7", got)
+		}
+	}
+
+	resp := getLimits(t, NewLimitsHandler(limiter, fairQueue), anonymous)
+	if resp.Data.Caller != "ip:1.2.3.4" || resp.Data.Authenticated || resp.Data.Concurrency.BulkSlots != 1 {
+		t.Errorf("anonymous limits = %+v", resp.Data)
+	}
+	if resp.Data.RateLimit["limit"] != float64(10) || resp.Data.RateLimit["window_seconds"] != float64(60) {
+		t.Errorf("anonymous rate limit = %v", resp.Data.RateLimit)
+	}
+
+	// Unlimited tiers report no window state
+	resp = getLimits(t, NewLimitsHandler(limiter, fairQueue), premium)
+	if _, ok := resp.Data.RateLimit["remaining"]; ok || resp.Data.RateLimit["limit"] != float64(0) {
+		t.Errorf("premium rate limit = %v, want unlimited", resp.Data.RateLimit)
+	}
+	if resp.Data.Tier != "premium" || resp.Data.Concurrency.BulkSlots != 4 {
+		t.Errorf("premium limits = %+v", resp.Data)
+	}
+
+	// Rate limiting disabled
+	resp = getLimits(t, NewLimitsHandler(nil, fairQueue), anonymous)
+	if len(resp.Data.RateLimit) != 1 || resp.Data.RateLimit["enabled"] != false {
+		t.Errorf("rate limit without a limiter = %v, want only enabled: false", resp.Data.RateLimit)
+	}
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type MatchmakingHandler struct {
+	matchmakingSvc service.MatchmakingService
+	cfg            config.MatchmakingConfig
+}
+
+func NewMatchmakingHandler(matchmakingSvc service.MatchmakingService, cfg config.MatchmakingConfig) *MatchmakingHandler {
+	return &MatchmakingHandler{
+		matchmakingSvc: matchmakingSvc,
+		cfg:            cfg,
+	}
+}
+
+// GetOpponents godoc
+// @Summary Suggest opponents
+// @Description Returns up to count players rated within range of the user, closest first, leaving out anyone they played within MATCHMAKING_RECENT_WINDOW
+// @Tags matchmaking
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param range query int false "Rating band either side of the user (default MATCHMAKING_RANGE, at most MATCHMAKING_MAX_RANGE)"
+// @Param count query int false "Number of candidates (default MATCHMAKING_COUNT, at most MATCHMAKING_MAX_COUNT)"
+// @Success 200 {array} models.MatchCandidate
+// @Router /matchmaking/{user_id} [get]
+func (h *MatchmakingHandler) GetOpponents(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	band, err := strconv.Atoi(c.DefaultQuery("range", strconv.Itoa(h.cfg.Range)))
+	if err != nil || band <= 0 {
+		band = h.cfg.Range
+	}
+	if band > h.cfg.MaxRange {
+		band = h.cfg.MaxRange
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(h.cfg.Count)))
+	if err != nil || count <= 0 {
+		count = h.cfg.Count
+	}
+	if count > h.cfg.MaxCount {
+		count = h.cfg.MaxCount
+	}
+
+	rating, candidates, err := h.matchmakingSvc.FindOpponents(uint(userID), band, count)
+	if err != nil {
+		writeMatchmakingError(c, err, "Failed to find opponents")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"user_id": userID,
+		"rating":  rating,
+		"range":   band,
+		"count":   len(candidates),
+		"data":    candidates,
+	})
+}
+
+// RecordMatch godoc
+// @Summary Record a match
+// @Description Records that two users played, so neither is suggested to the other for MATCHMAKING_RECENT_WINDOW. Results submitted with an opponent_id are recorded automatically.
+// @Tags matchmaking
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param body body map[string]interface{} true "opponent_id"
+// @Success 200 {object} map[string]interface{}
+// @Router /matchmaking/{user_id}/matches [post]
+func (h *MatchmakingHandler) RecordMatch(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req struct {
+		OpponentID uint `json:"opponent_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. opponent_id is required")
+		return
+	}
+
+	if err := h.matchmakingSvc.RecordMatch(uint(userID), req.OpponentID); err != nil {
+		writeMatchmakingError(c, err, "Failed to record match")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"user_id":     userID,
+		"opponent_id": req.OpponentID,
+	})
+}
+
+// writeMatchmakingError maps matchmaking service errors to responses
+func writeMatchmakingError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		apierror.AbortCode(c, http.StatusNotFound, service.CodeUserNotFound, "User not found")
+	case errors.Is(err, service.ErrSelfMatch):
+		apierror.AbortCode(c, http.StatusBadRequest, service.CodeValidation, "A user cannot be matched with themselves")
+	case errors.Is(err, service.ErrRedisUnavailable):
+		apierror.AbortCode(c, http.StatusServiceUnavailable, service.CodeRedisUnavailable, "Matchmaking is unavailable while Redis is down, retry later")
+	default:
+		apierror.Fail(c, err, fallback)
+	}
+}
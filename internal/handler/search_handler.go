@@ -3,8 +3,13 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/fields"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
 )
 
@@ -20,21 +25,20 @@ func NewSearchHandler(searchSvc service.SearchService) *SearchHandler {
 
 // SearchUsers godoc
 // @Summary Search users by username
-// @Description Searches for users by username and returns results with global ranks
+// @Description Fuzzy username search (pg_trgm), ordered by a blend of similarity and rating, with global ranks and similarity
 // @Tags search
 // @Accept json
 // @Produce json
 // @Param q query string true "Search query"
 // @Param limit query int false "Maximum results" default(100)
+// @Param fields query string false "Comma-separated fields of each result, e.g. global_rank,username (default all)"
 // @Success 200 {array} models.SearchResult
 // @Router /search [get]
 func (h *SearchHandler) SearchUsers(c *gin.Context) {
 	// Get search query
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Search query 'q' is required",
-		})
+		apierror.Abort(c, http.StatusBadRequest, "Search query 'q' is required")
 		return
 	}
 
@@ -47,13 +51,15 @@ func (h *SearchHandler) SearchUsers(c *gin.Context) {
 	if limit > 200 {
 		limit = 200 // Max limit for search
 	}
+	selected, ok := parseFields[models.SearchResult](c)
+	if !ok {
+		return
+	}
 
 	// Search users
-	results, err := h.searchSvc.SearchUsers(query, limit)
+	results, err := h.searchSvc.SearchUsers(query, limit, auth.FromContext(c).UserID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Search failed",
-		})
+		apierror.Fail(c, err, "Search failed")
 		return
 	}
 
@@ -61,6 +67,53 @@ func (h *SearchHandler) SearchUsers(c *gin.Context) {
 		"success": true,
 		"query":   query,
 		"count":   len(results),
-		"data":    results,
+		"data":    fields.Select(results, selected),
+	})
+}
+
+// Autocomplete godoc
+// @Summary Suggest usernames by prefix
+// @Description Type-ahead: users whose username starts with the prefix (case-insensitive), best rated first, from a Redis index
+// @Tags search
+// @Produce json
+// @Param prefix query string true "Start of the username, e.g. rah"
+// @Param limit query int false "Maximum suggestions" default(10)
+// @Param fields query string false "Comma-separated fields of each suggestion, e.g. username (default all)"
+// @Success 200 {array} models.Suggestion
+// @Router /search/autocomplete [get]
+func (h *SearchHandler) Autocomplete(c *gin.Context) {
+	prefix := c.Query("prefix")
+	if strings.TrimSpace(prefix) == "" {
+		apierror.Abort(c, http.StatusBadRequest, "Query parameter 'prefix' is required")
+		return
+	}
+	if len(prefix) > 50 {
+		apierror.Abort(c, http.StatusBadRequest, "Prefix must be at most 50 characters")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50 // Max limit for autocomplete
+	}
+	selected, ok := parseFields[models.Suggestion](c)
+	if !ok {
+		return
+	}
+
+	suggestions, err := h.searchSvc.Autocomplete(prefix, limit, auth.FromContext(c).UserID)
+	if err != nil {
+		apierror.Fail(c, err, "Autocomplete failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"prefix":  prefix,
+		"count":   len(suggestions),
+		"data":    fields.Select(suggestions, selected),
 	})
 }
\ No newline at end of file
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type TeamHandler struct {
+	teamSvc service.TeamService
+}
+
+func NewTeamHandler(teamSvc service.TeamService) *TeamHandler {
+	return &TeamHandler{teamSvc: teamSvc}
+}
+
+// GetTeamLeaderboard godoc
+// @Summary Get the team leaderboard
+// @Description Returns the top teams, ranked by the sum or average (TEAM_SCORING) of their ranked members' ratings
+// @Tags teams
+// @Produce json
+// @Param limit query int false "Number of teams to return" default(100)
+// @Success 200 {array} models.TeamEntry
+// @Router /teams/leaderboard [get]
+func (h *TeamHandler) GetTeamLeaderboard(c *gin.Context) {
+	// Parse limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000 // Max limit
+	}
+
+	entries, err := h.teamSvc.GetLeaderboard(limit)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch team leaderboard")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"count":   len(entries),
+		"data":    entries,
+	})
+}
+
+// CreateTeam godoc
+// @Summary Create a team
+// @Description Creates a team and puts it on the team leaderboard
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} true "Name (3-50 chars) and optional description"
+// @Success 201 {object} models.Team
+// @Router /teams [post]
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	var req struct {
+		Name        string `json:"name" binding:"required,min=3,max=50"`
+		Description string `json:"description" binding:"max=500"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. name (3-50 chars) is required; description is at most 500 chars")
+		return
+	}
+
+	team, err := h.teamSvc.CreateTeam(strings.TrimSpace(req.Name), req.Description)
+	if err != nil {
+		if errors.Is(err, service.ErrTeamNameTaken) {
+			apierror.AbortCode(c, http.StatusConflict, service.CodeTeamNameTaken, "Team name already taken")
+			return
+		}
+		apierror.Fail(c, err, "Failed to create team")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    team,
+	})
+}
+
+// GetTeam godoc
+// @Summary Get a team
+// @Description Returns a team with its score, rank on the team leaderboard and members (highest rated first)
+// @Tags teams
+// @Produce json
+// @Param team_id path int true "Team ID"
+// @Success 200 {object} models.TeamProfile
+// @Router /teams/{team_id} [get]
+func (h *TeamHandler) GetTeam(c *gin.Context) {
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.teamSvc.GetTeam(teamID)
+	if err != nil {
+		writeTeamError(c, err, "Failed to fetch team")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// UpdateTeam godoc
+// @Summary Update a team
+// @Description Renames a team and/or changes its description
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param team_id path int true "Team ID"
+// @Param body body map[string]interface{} true "Fields to change (name, description)"
+// @Success 200 {object} models.Team
+// @Router /teams/{team_id} [patch]
+func (h *TeamHandler) UpdateTeam(c *gin.Context) {
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        *string `json:"name" binding:"omitempty,min=3,max=50"`
+		Description *string `json:"description" binding:"omitempty,max=500"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Name == nil && req.Description == nil) {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. Provide name (3-50 chars) and/or description (at most 500 chars)")
+		return
+	}
+	if req.Name != nil {
+		trimmed := strings.TrimSpace(*req.Name)
+		req.Name = &trimmed
+	}
+
+	team, err := h.teamSvc.UpdateTeam(teamID, req.Name, req.Description)
+	if err != nil {
+		writeTeamError(c, err, "Failed to update team")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    team,
+	})
+}
+
+// DeleteTeam godoc
+// @Summary Delete a team
+// @Description Deletes a team and its memberships and removes it from the team leaderboard
+// @Tags teams
+// @Produce json
+// @Param team_id path int true "Team ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /teams/{team_id} [delete]
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.teamSvc.DeleteTeam(teamID); err != nil {
+		writeTeamError(c, err, "Failed to delete team")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"team_id": teamID,
+	})
+}
+
+// AddTeamMember godoc
+// @Summary Add a user to a team
+// @Description Adds a user to a team (up to TEAM_MAX_MEMBERS) and recomputes the team's score. A user is in at most one team.
+// @Tags teams
+// @Produce json
+// @Param team_id path int true "Team ID"
+// @Param user_id path int true "User ID"
+// @Success 201 {object} models.TeamMembership
+// @Router /teams/{team_id}/members/{user_id} [put]
+func (h *TeamHandler) AddTeamMember(c *gin.Context) {
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	membership, err := h.teamSvc.AddMember(teamID, uint(userID))
+	if err != nil {
+		writeTeamError(c, err, "Failed to add team member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    membership,
+	})
+}
+
+// RemoveTeamMember godoc
+// @Summary Remove a user from a team
+// @Description Removes a user from a team and recomputes the team's score
+// @Tags teams
+// @Produce json
+// @Param team_id path int true "Team ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /teams/{team_id}/members/{user_id} [delete]
+func (h *TeamHandler) RemoveTeamMember(c *gin.Context) {
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.teamSvc.RemoveMember(teamID, uint(userID)); err != nil {
+		writeTeamError(c, err, "Failed to remove team member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"team_id": teamID,
+		"user_id": userID,
+	})
+}
+
+func parseTeamID(c *gin.Context) (uint, bool) {
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid team ID")
+		return 0, false
+	}
+	return uint(teamID), true
+}
+
+// writeTeamError maps team service errors to responses
+func writeTeamError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTeamNotFound):
+		apierror.AbortCode(c, http.StatusNotFound, service.CodeTeamNotFound, "Team not found")
+	case errors.Is(err, service.ErrUserNotFound):
+		apierror.AbortCode(c, http.StatusNotFound, service.CodeUserNotFound, "User not found")
+	case errors.Is(err, service.ErrNotTeamMember):
+		apierror.AbortCode(c, http.StatusNotFound, service.CodeNotTeamMember, "User is not a member of this team")
+	case errors.Is(err, service.ErrTeamNameTaken):
+		apierror.AbortCode(c, http.StatusConflict, service.CodeTeamNameTaken, "Team name already taken")
+	case errors.Is(err, service.ErrAlreadyInTeam):
+		apierror.AbortCode(c, http.StatusConflict, service.CodeAlreadyInTeam, "User is already in a team; leave it first")
+	case errors.Is(err, service.ErrTeamFull):
+		apierror.AbortCode(c, http.StatusConflict, service.CodeTeamFull, "Team is full")
+	default:
+		apierror.Fail(c, err, fallback)
+	}
+}
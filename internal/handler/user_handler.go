@@ -0,0 +1,660 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Longest period a history query may cover
+const maxHistoryPeriod = 90 * 24 * time.Hour
+
+type UserHandler struct {
+	userSvc         service.UserService
+	rankHistorySvc  service.RankHistoryService
+	scoreHistorySvc service.ScoreHistoryService
+	rankExplainSvc  service.RankExplainService
+	digestSvc       service.DigestService
+	notificationSvc service.NotificationService
+}
+
+func NewUserHandler(
+	userSvc service.UserService,
+	rankHistorySvc service.RankHistoryService,
+	scoreHistorySvc service.ScoreHistoryService,
+	rankExplainSvc service.RankExplainService,
+	digestSvc service.DigestService,
+	notificationSvc service.NotificationService,
+) *UserHandler {
+	return &UserHandler{
+		userSvc:         userSvc,
+		rankHistorySvc:  rankHistorySvc,
+		scoreHistorySvc: scoreHistorySvc,
+		rankExplainSvc:  rankExplainSvc,
+		digestSvc:       digestSvc,
+		notificationSvc: notificationSvc,
+	}
+}
+
+// CreateUser godoc
+// @Summary Create a user
+// @Description Creates a user in PostgreSQL and adds them to the live leaderboard
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} true "Username, optional rating (default 1500) and timezone (IANA, default UTC)"
+// @Success 201 {object} models.User
+// @Router /users [post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	// Parse request body
+	var req struct {
+		Username string `json:"username" binding:"required,min=3,max=50"`
+		Rating   *int   `json:"rating" binding:"omitempty,min=100,max=5000"`
+		Timezone string `json:"timezone" binding:"max=64"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. username (3-50 chars) is required; rating must be between 100 and 5000")
+		return
+	}
+
+	rating := 1500
+	if req.Rating != nil {
+		rating = *req.Rating
+	}
+
+	user, err := h.userSvc.CreateUser(strings.TrimSpace(req.Username), rating, req.Timezone)
+	if err != nil {
+		if errors.Is(err, service.ErrUsernameTaken) {
+			apierror.AbortCode(c, http.StatusConflict, service.CodeUsernameTaken, "Username already taken")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTimezone) {
+			apierror.AbortCode(c, http.StatusBadRequest, service.CodeValidation, "Invalid timezone. Use an IANA name such as Asia/Kolkata")
+			return
+		}
+		apierror.Fail(c, err, "Failed to create user")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    user,
+	})
+}
+
+// UpdateUser godoc
+// @Summary Update a user
+// @Description Renames a user, sets their rating and/or timezone; rating changes are broadcast like any score update
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param body body map[string]interface{} true "Fields to change (username, rating, timezone)"
+// @Success 200 {object} models.User
+// @Router /users/{user_id} [patch]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Username *string `json:"username" binding:"omitempty,min=3,max=50"`
+		Rating   *int    `json:"rating" binding:"omitempty,min=100,max=5000"`
+		Timezone *string `json:"timezone" binding:"omitempty,max=64"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Username == nil && req.Rating == nil && req.Timezone == nil) {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. Provide username (3-50 chars), rating (100-5000) and/or timezone")
+		return
+	}
+	if req.Username != nil {
+		trimmed := strings.TrimSpace(*req.Username)
+		req.Username = &trimmed
+	}
+
+	user, err := h.userSvc.UpdateUser(c.Request.Context(), uint(userID), req.Username, req.Rating, req.Timezone)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeUserNotFound, "User not found")
+		case errors.Is(err, service.ErrUsernameTaken):
+			apierror.AbortCode(c, http.StatusConflict, service.CodeUsernameTaken, "Username already taken")
+		case errors.Is(err, service.ErrInvalidTimezone):
+			apierror.AbortCode(c, http.StatusBadRequest, service.CodeValidation, "Invalid timezone. Use an IANA name such as Asia/Kolkata")
+		case errors.Is(err, service.ErrUserBanned):
+			apierror.AbortCode(c, http.StatusForbidden, service.CodeUserBanned, "User is banned")
+		case errors.Is(err, service.ErrRatingDeltaExceeded):
+			apierror.AbortCode(c, http.StatusUnprocessableEntity, service.CodeRatingDeltaExceeded, "Rating change exceeds the allowed limit")
+		case errors.Is(err, service.ErrUserBusy):
+			apierror.AbortCode(c, http.StatusConflict, service.CodeUserBusy, "Another update of this user is in progress, retry")
+		default:
+			apierror.Fail(c, err, "Failed to update user")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    user,
+	})
+}
+
+// RenameUser godoc
+// @Summary Change a username
+// @Description Renames a user, refreshes the cached name and pushes a user_renamed event to all WebSocket clients
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param body body map[string]string true "New username"
+// @Success 200 {object} models.User
+// @Router /users/{user_id}/username [put]
+func (h *UserHandler) RenameUser(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Username string `json:"username" binding:"required,min=3,max=50"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. username must be 3-50 characters")
+		return
+	}
+
+	user, err := h.userSvc.RenameUser(uint(userID), strings.TrimSpace(req.Username))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeUserNotFound, "User not found")
+		case errors.Is(err, service.ErrUsernameTaken):
+			apierror.AbortCode(c, http.StatusConflict, service.CodeUsernameTaken, "Username already taken")
+		default:
+			apierror.Fail(c, err, "Failed to rename user")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    user,
+	})
+}
+
+// DeleteUser godoc
+// @Summary Delete a user
+// @Description Soft-deletes a user and removes them from the live leaderboard
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /users/{user_id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.userSvc.DeleteUser(uint(userID)); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeUserNotFound, "User not found")
+			return
+		}
+		apierror.Fail(c, err, "Failed to delete user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"user_id": userID,
+	})
+}
+
+// PurgeUser godoc
+// @Summary Permanently erase a user
+// @Description Hard-deletes the user and all their history from PostgreSQL, removes them from Redis (leaderboard, cache, queued updates) and notifies clients with user_removed
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /users/{user_id}/purge [delete]
+func (h *UserHandler) PurgeUser(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.userSvc.PurgeUser(uint(userID)); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeUserNotFound, "User not found")
+			return
+		}
+		apierror.Fail(c, err, "Failed to purge user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"user_id": userID,
+		"purged":  true,
+	})
+}
+
+// GetProfile godoc
+// @Summary Get user profile
+// @Description Returns username, rating, global rank, percentile, 24h rank delta, recent history, achievements and daily streaks in one response
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} models.UserProfile
+// @Router /users/{user_id}/profile [get]
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	profile, err := h.userSvc.GetProfile(uint(userID))
+	if err != nil {
+		apierror.Abort(c, http.StatusNotFound, "User not found in leaderboard")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// GetRankHistory godoc
+// @Summary Get user's rank over time
+// @Description Returns periodic rank snapshots for charting rank over time
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param period query string false "Lookback period (e.g. 24h, 7d, 30d)" default(7d)
+// @Success 200 {array} models.RankHistory
+// @Router /users/{user_id}/rank-history [get]
+func (h *UserHandler) GetRankHistory(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse period
+	period, err := parsePeriod(c.DefaultQuery("period", "7d"))
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	history, err := h.rankHistorySvc.GetRankHistory(uint(userID), period)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch rank history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"user_id": userID,
+		"period":  c.DefaultQuery("period", "7d"),
+		"count":   len(history),
+		"data":    history,
+	})
+}
+
+// GetRankExplanations godoc
+// @Summary Explain a user's rank change
+// @Description Attributes the rank change since a point in time to the user's own score updates (split into results and admin adjustments) and to specific players passing them or falling behind. Covers the raw score history window only
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param since query string false "RFC3339 time or lookback period (e.g. 6h, 1d)" default(24h)
+// @Success 200 {object} models.RankExplanation
+// @Router /users/{user_id}/rank-explanations [get]
+func (h *UserHandler) GetRankExplanations(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse since: a timestamp or a lookback period
+	sinceStr := c.DefaultQuery("since", "24h")
+	since, err := time.Parse(time.RFC3339, sinceStr)
+	if err != nil {
+		period, perr := parsePeriod(sinceStr)
+		if perr != nil {
+			apierror.Abort(c, http.StatusBadRequest, "since must be an RFC3339 time or a period like 24h or 7d")
+			return
+		}
+		since = time.Now().Add(-period)
+	}
+	if since.After(time.Now()) {
+		apierror.Abort(c, http.StatusBadRequest, "since must be in the past")
+		return
+	}
+
+	explanation, err := h.rankExplainSvc.Explain(uint(userID), since)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to explain rank change")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    explanation,
+	})
+}
+
+// GetScoreHistory godoc
+// @Summary Get user's score history
+// @Description Returns raw score updates for recent days and daily aggregates (open/close/min/max) for compacted days, in one chronological list
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param period query string false "Lookback period (e.g. 24h, 7d, 30d)" default(30d)
+// @Success 200 {array} models.HistoryEntry
+// @Router /users/{user_id}/history [get]
+func (h *UserHandler) GetScoreHistory(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse period
+	period, err := parsePeriod(c.DefaultQuery("period", "30d"))
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	history, err := h.scoreHistorySvc.GetHistory(uint(userID), period)
+	if err != nil {
+		apierror.Fail(c, err, "Failed to fetch score history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"user_id": userID,
+		"period":  c.DefaultQuery("period", "30d"),
+		"count":   len(history),
+		"data":    history,
+	})
+}
+
+// GetDigestSubscription godoc
+// @Summary Get daily digest preferences
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} models.DigestSubscription
+// @Router /users/{user_id}/digest [get]
+func (h *UserHandler) GetDigestSubscription(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Subscriptions hold the user's contact details and delivery URL
+	if !auth.FromContext(c).ActsFor(uint(userID)) {
+		apierror.Abort(c, http.StatusForbidden, "Digest settings are only available to the user (X-User-ID)")
+		return
+	}
+
+	sub, err := h.digestSvc.GetSubscription(uint(userID))
+	if err != nil {
+		apierror.Abort(c, http.StatusNotFound, "No digest subscription for user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// UpdateDigestSubscription godoc
+// @Summary Opt in/out of the daily digest
+// @Description Stores timezone and delivery channels (webhook and/or email) for the daily rank digest
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param body body models.DigestSubscription true "Digest preferences"
+// @Success 200 {object} models.DigestSubscription
+// @Router /users/{user_id}/digest [put]
+func (h *UserHandler) UpdateDigestSubscription(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Subscriptions hold the user's contact details and delivery URL
+	if !auth.FromContext(c).ActsFor(uint(userID)) {
+		apierror.Abort(c, http.StatusForbidden, "Digest settings are only available to the user (X-User-ID)")
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		OptedIn    bool   `json:"opted_in"`
+		Timezone   string `json:"timezone"`
+		WebhookURL string `json:"webhook_url" binding:"omitempty,url"`
+		Email      string `json:"email" binding:"omitempty,email"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	sub := &models.DigestSubscription{
+		UserID:     uint(userID),
+		OptedIn:    req.OptedIn,
+		Timezone:   req.Timezone,
+		WebhookURL: req.WebhookURL,
+		Email:      req.Email,
+	}
+	if err := h.digestSvc.Subscribe(sub); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// GetNotificationPreferences godoc
+// @Summary Get notification preferences
+// @Description Events, channels and quiet hours; the tenant defaults (default=true) until the user saves their own
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} models.NotificationPreference
+// @Router /users/{user_id}/notifications [get]
+func (h *UserHandler) GetNotificationPreferences(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	pref, err := h.notificationSvc.GetPreferences(uint(userID))
+	if err != nil {
+		apierror.Fail(c, err, "Failed to load notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    pref,
+	})
+}
+
+// UpdateNotificationPreferences godoc
+// @Summary Set notification preferences
+// @Description Replaces which events (daily_digest, score_update) are sent over which channels (webhook, email), and the quiet hours (HH:MM-HH:MM in the user's timezone) during which nothing is sent
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param body body models.NotificationPreference true "Notification preferences"
+// @Success 200 {object} models.NotificationPreference
+// @Router /users/{user_id}/notifications [put]
+func (h *UserHandler) UpdateNotificationPreferences(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Events     []string `json:"events" binding:"required"`
+		Channels   []string `json:"channels" binding:"required"`
+		QuietHours string   `json:"quiet_hours"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	pref := &models.NotificationPreference{
+		UserID:     uint(userID),
+		Events:     req.Events,
+		Channels:   req.Channels,
+		QuietHours: req.QuietHours,
+	}
+	if err := h.notificationSvc.UpdatePreferences(pref); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			apierror.AbortCode(c, http.StatusNotFound, service.CodeUserNotFound, "User not found")
+		case errors.Is(err, service.ErrInvalidPreferences):
+			apierror.AbortCode(c, http.StatusBadRequest, service.CodeValidation, err.Error())
+		default:
+			apierror.Fail(c, err, "Failed to save notification preferences")
+		}
+		return
+	}
+
+	saved, err := h.notificationSvc.GetPreferences(uint(userID))
+	if err != nil {
+		saved = pref
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    saved,
+	})
+}
+
+// ResetNotificationPreferences godoc
+// @Summary Reset notification preferences
+// @Description Deletes the user's preferences so the tenant defaults apply again
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} models.NotificationPreference
+// @Router /users/{user_id}/notifications [delete]
+func (h *UserHandler) ResetNotificationPreferences(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.notificationSvc.ResetPreferences(uint(userID)); err != nil {
+		apierror.Fail(c, err, "Failed to reset notification preferences")
+		return
+	}
+
+	pref, err := h.notificationSvc.GetPreferences(uint(userID))
+	if err != nil {
+		apierror.Fail(c, err, "Failed to load notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    pref,
+	})
+}
+
+// parsePeriod accepts Go durations ("36h") plus day suffixes ("7d")
+func parsePeriod(value string) (time.Duration, error) {
+	var period time.Duration
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid period %q", value)
+		}
+		period = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return 0, fmt.Errorf("invalid period %q (use e.g. 24h, 7d)", value)
+		}
+		period = d
+	}
+
+	if period > maxHistoryPeriod {
+		return 0, fmt.Errorf("period must be at most %dd", int(maxHistoryPeriod.Hours()/24))
+	}
+	return period, nil
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeDigestService struct {
+	service.DigestService
+	saved []*models.DigestSubscription
+}
+
+func (f *fakeDigestService) GetSubscription(userID uint) (*models.DigestSubscription, error) {
+	return &models.DigestSubscription{UserID: userID, WebhookURL: "https://hooks.example.com/7"}, nil
+}
+
+func (f *fakeDigestService) Subscribe(sub *models.DigestSubscription) error {
+	f.saved = append(f.saved, sub)
+	return nil
+}
+
+// TestDigestSubscriptionRequiresUser checks that only the user may read or
+// change their digest settings
+func TestDigestSubscriptionRequiresUser(t *testing.T) {
+	keyed := func(userID uint) *auth.Principal {
+		return &auth.Principal{Key: "key-1", Tier: "standard", Authenticated: true, UserID: userID}
+	}
+
+	for _, tc := range []struct {
+		name      string
+		principal *auth.Principal
+		method    string
+		status    int
+	}{
+		{"anonymous read", nil, http.MethodGet, http.StatusForbidden},
+		{"anonymous update", nil, http.MethodPut, http.StatusForbidden},
+		{"unverified user id", &auth.Principal{Key: "ip:1.2.3.4", Tier: auth.AnonymousTier, UserID: 7}, http.MethodPut, http.StatusForbidden},
+		{"key without user", keyed(0), http.MethodPut, http.StatusForbidden},
+		{"other user", keyed(8), http.MethodGet, http.StatusForbidden},
+		{"other user update", keyed(8), http.MethodPut, http.StatusForbidden},
+		{"same user read", keyed(7), http.MethodGet, http.StatusOK},
+		{"same user update", keyed(7), http.MethodPut, http.StatusOK},
+		{"impersonating user", &auth.Principal{Key: "impersonation:s1", UserID: 7, Impersonator: "jwt:alice"}, http.MethodGet, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			digest := &fakeDigestService{}
+			h := NewUserHandler(nil, nil, nil, nil, digest, nil)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				if tc.principal != nil {
+					auth.SetPrincipal(c, tc.principal)
+				}
+			})
+			r.GET("/users/:user_id/digest", h.GetDigestSubscription)
+			r.PUT("/users/:user_id/digest", h.UpdateDigestSubscription)
+
+			req := httptest.NewRequest(tc.method, "/users/7/digest",
+				strings.NewReader(`{"opted_in":true,"webhook_url":"https://hooks.example.com/7"}`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d (%s), want %d", rec.Code, rec.Body, tc.status)
+			}
+			if stored := len(digest.saved) > 0; stored != (tc.method == http.MethodPut && tc.status == http.StatusOK) {
+				t.Errorf("subscription stored = %v", stored)
+			}
+		})
+	}
+}
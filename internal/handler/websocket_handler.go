@@ -1,45 +1,149 @@
 package handler
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/logging"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/origins"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	ws "github.com/SSujoy-Samanta/leaderboard-backend/internal/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		// Allow all origins for development
-		// In production, check against allowed origins
-		return true
-	},
-}
+// CodeOriginNotAllowed is the reason of upgrades refused for their Origin
+const CodeOriginNotAllowed = "ws_origin_not_allowed"
 
 type WebSocketHandler struct {
-	hub *ws.Hub
+	hub         *ws.Hub
+	commands    *ws.Commands
+	statsSvc    service.ConnectionStatsService
+	announceSvc service.AnnouncementService
+	maxWatch    int
+	origins     *origins.Matcher
+	upgrader    websocket.Upgrader
 }
 
-func NewWebSocketHandler(hub *ws.Hub) *WebSocketHandler {
-	return &WebSocketHandler{
-		hub: hub,
+func NewWebSocketHandler(
+	hub *ws.Hub,
+	leaderboardSvc service.LeaderboardService,
+	statsSvc service.ConnectionStatsService,
+	announceSvc service.AnnouncementService,
+	cfg config.WebSocketConfig,
+) *WebSocketHandler {
+	h := &WebSocketHandler{
+		hub:         hub,
+		commands:    ws.NewCommands(leaderboardSvc, cfg.MaxWatch),
+		statsSvc:    statsSvc,
+		announceSvc: announceSvc,
+		maxWatch:    cfg.MaxWatch,
+		origins:     origins.New(cfg.AllowedOrigins),
+	}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:    1024,
+		WriteBufferSize:   1024,
+		CheckOrigin:       h.checkOrigin,
+		Subprotocols:      ws.Subprotocols,
+		EnableCompression: cfg.Compression,
+	}
+	return h
+}
+
+// checkOrigin allows requests without an Origin header (non-browser
+// clients), same-origin requests and the origins in WS_ALLOWED_ORIGINS,
+// so other sites cannot open connections with a visitor's credentials
+func (h *WebSocketHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
 	}
+	return h.origins.Allowed(origin)
 }
 
-// HandleWebSocket upgrades HTTP connection to WebSocket
+// HandleWebSocket upgrades HTTP connection to WebSocket. ?watch=1,2,3 asks
+// for every score update of those users, including the ones kept off the
+// public stream. Keyed callers name the user the connection is for with
+// X-User-ID or ?user_id=, so admins can find and disconnect it. Messages
+// from the client are run as commands (see websocket.Commands).
+// ?encoding=msgpack, or the leaderboard.msgpack subprotocol, sends
+// MessagePack binary frames instead of JSON; the query parameter takes
+// precedence. Browser origins outside WS_ALLOWED_ORIGINS are refused with
+// 403, connections beyond WS_MAX_CONNECTIONS_PER_IP with 429 and beyond
+// WS_MAX_CONNECTIONS with 503.
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
+	var watch []uint
+	if raw := c.Query("watch"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			userID, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil || userID == 0 {
+				apierror.Abort(c, http.StatusBadRequest, "Invalid watch list. Use comma-separated user IDs")
+				return
+			}
+			watch = append(watch, uint(userID))
+		}
+		if len(watch) > h.maxWatch {
+			apierror.Abort(c, http.StatusBadRequest, fmt.Sprintf("At most %d users can be watched", h.maxWatch))
+			return
+		}
+	}
+
+	encoding, ok := ws.ParseEncoding(c.Query("encoding"))
+	if !ok {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid encoding. Use json or msgpack")
+		return
+	}
+
+	principal := auth.FromContext(c)
+	userID := principal.UserID
+	if raw := c.Query("user_id"); raw != "" && principal.Authenticated {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		userID = uint(id)
+	}
+
+	if !h.checkOrigin(c.Request) {
+		apierror.AbortCode(c, http.StatusForbidden, CodeOriginNotAllowed, "Origin not allowed")
+		return
+	}
+
+	ip := c.ClientIP()
+	if err := h.hub.Admit(ip); err != nil {
+		if errors.Is(err, ws.ErrTooManyFromClient) {
+			apierror.AbortCode(c, http.StatusTooManyRequests, ws.CodeTooManyFromClient, "Too many WebSocket connections from this address")
+		} else {
+			apierror.AbortCode(c, http.StatusServiceUnavailable, ws.CodeServerFull, "Too many WebSocket connections on this server, retry later")
+		}
+		return
+	}
+
 	// Upgrade HTTP connection to WebSocket
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
-		log.Printf("Failed to upgrade to WebSocket: %v", err)
+		h.hub.Release(ip)
+		logging.FromContext(c.Request.Context()).Warn("Failed to upgrade to WebSocket", "error", err)
 		return
 	}
 
+	if c.Query("encoding") == "" {
+		encoding, _ = ws.ParseEncoding(conn.Subprotocol())
+	}
+
 	// Create new client
-	client := ws.NewClient(h.hub, conn)
+	client := ws.NewClient(h.hub, h.commands, conn, encoding, ip, userID, watch)
 	h.hub.Register(client)
 
 	// Start client goroutines
@@ -47,10 +151,163 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	go client.ReadPump()
 }
 
-// GetConnectionStats returns WebSocket connection statistics
+// GetConnectionStats godoc
+// @Summary WebSocket connection stats
+// @Description Clients connected to this server, their ping round trip times and, from the servers' heartbeats (WS_STATS_INTERVAL), the clients of every server with a per-server breakdown. cluster is null while Redis is unavailable.
+// @Tags websocket
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /ws/stats [get]
 func (h *WebSocketHandler) GetConnectionStats(c *gin.Context) {
+	cluster, err := h.statsSvc.Cluster()
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Warn("⚠️  Failed to read cluster WebSocket stats", "error", err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success":           true,
+		"node":              h.statsSvc.Node(),
 		"connected_clients": h.hub.GetClientCount(),
+		"latency":           h.hub.Latency(),
+		"cluster":           cluster,
 	})
 }
+
+// ListClients godoc
+// @Summary List WebSocket clients
+// @Description Clients connected to this server, oldest first, with their address, connect time, user, watched users, queued messages and ping round trip
+// @Tags admin
+// @Produce json
+// @Param user_id query int false "Only clients connected for this user"
+// @Success 200 {array} websocket.ClientInfo
+// @Router /admin/websocket/clients [get]
+func (h *WebSocketHandler) ListClients(c *gin.Context) {
+	clients := h.hub.Clients()
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		filtered := clients[:0]
+		for _, client := range clients {
+			if client.UserID == uint(userID) {
+				filtered = append(filtered, client)
+			}
+		}
+		clients = filtered
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"count":   len(clients),
+		"data":    clients,
+	})
+}
+
+// DisconnectClient godoc
+// @Summary Disconnect a WebSocket client
+// @Description Closes the client's connection on this server. Requires the operator role.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/websocket/clients/{id} [delete]
+func (h *WebSocketHandler) DisconnectClient(c *gin.Context) {
+	if !h.hub.Disconnect(c.Param("id")) {
+		apierror.Abort(c, http.StatusNotFound, "Client not connected to this server")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// DisconnectUser godoc
+// @Summary Disconnect a user's WebSocket clients
+// @Description Closes every connection on this server made for the user (X-User-ID or ?user_id= on connect). Requires the operator role.
+// @Tags admin
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/websocket/users/{user_id} [delete]
+func (h *WebSocketHandler) DisconnectUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	disconnected := h.hub.DisconnectUser(uint(userID))
+	if disconnected == 0 {
+		apierror.Abort(c, http.StatusNotFound, "User has no client connected to this server")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"disconnected": disconnected,
+	})
+}
+
+// Announce godoc
+// @Summary Send an announcement
+// @Description Sends an announcement message to the WebSocket clients of every server. Requires the operator role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body map[string]interface{} true "message and level (info, warning or critical; default info)"
+// @Success 200 {object} models.AnnouncementPayload
+// @Router /admin/websocket/announcements [post]
+func (h *WebSocketHandler) Announce(c *gin.Context) {
+	var req struct {
+		Message string `json:"message" binding:"required"`
+		Level   string `json:"level"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, http.StatusBadRequest, "Invalid request body. message is required")
+		return
+	}
+
+	announcement, err := h.announceSvc.Announce(auth.FromContext(c).Actor(), req.Message, req.Level)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAnnouncement) {
+			apierror.AbortCode(c, http.StatusBadRequest, service.CodeValidation, err.Error())
+			return
+		}
+		apierror.Fail(c, err, "Failed to send announcement")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    announcement,
+	})
+}
+
+// DisconnectAllClients godoc
+// @Summary Disconnect every WebSocket client
+// @Description Closes all client connections on this server, e.g. to make clients reconnect elsewhere. Requires the operator role.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/websocket/clients [delete]
+func (h *WebSocketHandler) DisconnectAllClients(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"disconnected": h.hub.DisconnectAll(),
+	})
+}
+
+// GetSchema godoc
+// @Summary WebSocket message schema
+// @Description JSON Schema (draft 2020-12) of every message sent on /ws, generated from the Go payload types. Use it to generate client types or validate messages at runtime.
+// @Tags websocket
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /ws/schema [get]
+func (h *WebSocketHandler) GetSchema(c *gin.Context) {
+	c.Header("X-Protocol-Version", ws.ProtocolVersion)
+	c.JSON(http.StatusOK, ws.Schema())
+}
@@ -0,0 +1,79 @@
+// Package health keeps a registry of readiness checks that subsystems
+// contribute to, evaluated together by the /health/ready endpoint.
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CheckFunc returns nil when the dependency/subsystem is healthy
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of a single check
+type Result struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency"`
+}
+
+var (
+	mu     sync.RWMutex
+	checks = map[string]CheckFunc{}
+)
+
+// Register adds (or replaces) a named readiness check
+func Register(name string, check CheckFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks[name] = check
+}
+
+// RunAll evaluates every registered check concurrently
+func RunAll(ctx context.Context) ([]Result, bool) {
+	mu.RLock()
+	snapshot := make(map[string]CheckFunc, len(checks))
+	for name, check := range checks {
+		snapshot[name] = check
+	}
+	mu.RUnlock()
+
+	results := make([]Result, 0, len(snapshot))
+	var (
+		wg      sync.WaitGroup
+		resMu   sync.Mutex
+		healthy = true
+	)
+
+	for name, check := range snapshot {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check(ctx)
+			result := Result{
+				Name:    name,
+				Healthy: err == nil,
+				Latency: time.Since(start).String(),
+			}
+			if err != nil {
+				result.Error = err.Error()
+			}
+
+			resMu.Lock()
+			results = append(results, result)
+			if err != nil {
+				healthy = false
+			}
+			resMu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results, healthy
+}
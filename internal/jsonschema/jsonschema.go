@@ -0,0 +1,138 @@
+// Package jsonschema derives JSON Schema (draft 2020-12) documents from Go
+// types using their encoding/json tags, so wire formats have one source of truth.
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect produced by Reflect
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Schema is a JSON Schema object
+type Schema map[string]interface{}
+
+// Reflect returns the schema of v's type as encoding/json would marshal it.
+// Fields are required unless tagged omitempty; a `description` struct tag
+// becomes the property description.
+func Reflect(v interface{}) Schema {
+	return reflectType(reflect.TypeOf(v))
+}
+
+func reflectType(t reflect.Type) Schema {
+	if t == nil {
+		return Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Schema{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": reflectType(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": reflectType(t.Elem())}
+	case reflect.Struct:
+		return reflectStruct(t)
+	default:
+		return Schema{} // interface{} and friends accept anything
+	}
+}
+
+func reflectStruct(t reflect.Type) Schema {
+	properties := Schema{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without a name are flattened, like encoding/json does
+		if field.Anonymous && name == "" {
+			embedded := reflectType(field.Type)
+			if props, ok := embedded["properties"].(Schema); ok {
+				for k, v := range props {
+					properties[k] = v
+				}
+				if req, ok := embedded["required"].([]string); ok {
+					required = append(required, req...)
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := reflectType(field.Type)
+		if desc := field.Tag.Get("description"); desc != "" {
+			prop["description"] = desc
+		}
+		properties[name] = prop
+
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// jsonName reads the encoding/json tag of a field
+func jsonName(field reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty, false
+}
@@ -0,0 +1,51 @@
+// Package logging sets up the structured logger (log/slog) the server logs
+// through: leveled, as text or JSON, with request-scoped fields carried in
+// the request context.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+)
+
+type contextKey struct{}
+
+// Setup makes the configured logger the default, for slog and for the
+// standard log package (whose callers, the CLIs' shared code, log at INFO)
+func Setup(cfg config.LogConfig) {
+	var level slog.Level
+	level.UnmarshalText([]byte(cfg.Level)) // validated by config
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if cfg.Format == config.LogFormatJSON {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// WithLogger returns a context carrying logger, for FromContext
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger of a request (with its request ID, method
+// and path), or the default logger outside of one
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// Fatal logs msg at ERROR and exits, for failures the server cannot start
+// or stop cleanly after
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,234 @@
+// Package metrics is a small, dependency-free metrics registry that renders
+// the Prometheus text exposition format at /metrics.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// collector is anything that can render itself in exposition format
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]collector{}
+)
+
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[c.name()]; exists {
+		panic("metrics: duplicate metric " + c.name())
+	}
+	registry[c.name()] = c
+}
+
+// Handler serves all registered metrics in Prometheus text format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteAll(w)
+	})
+}
+
+// WriteAll renders every registered metric, sorted by name
+func WriteAll(w io.Writer) {
+	registryMu.RLock()
+	collectors := make([]collector, 0, len(registry))
+	for _, c := range registry {
+		collectors = append(collectors, c)
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].name() < collectors[j].name()
+	})
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// ─── Counter ───────────────────────────────────────────────
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	n, h   string
+	labels string
+	bits   uint64
+}
+
+// NewCounter registers a counter
+func NewCounter(name, help string) *Counter {
+	c := &Counter{n: name, h: help}
+	register(c)
+	return c
+}
+
+func (c *Counter) Inc()          { c.Add(1) }
+func (c *Counter) Add(v float64) { addFloat(&c.bits, v) }
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&c.bits))
+}
+
+func (c *Counter) name() string { return c.n }
+func (c *Counter) write(w io.Writer) {
+	writeHeader(w, c.n, c.h, "counter")
+	fmt.Fprintf(w, "%s%s %s\n", c.n, c.labels, formatFloat(c.Value()))
+}
+
+// ─── Gauge ─────────────────────────────────────────────────
+
+// Gauge is a value that can go up and down
+type Gauge struct {
+	n, h   string
+	labels string
+	bits   uint64
+}
+
+// NewGauge registers a gauge
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{n: name, h: help}
+	register(g)
+	return g
+}
+
+func (g *Gauge) Set(v float64) { atomic.StoreUint64(&g.bits, math.Float64bits(v)) }
+func (g *Gauge) Add(v float64) { addFloat(&g.bits, v) }
+func (g *Gauge) Inc()          { g.Add(1) }
+func (g *Gauge) Dec()          { g.Add(-1) }
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&g.bits))
+}
+
+func (g *Gauge) name() string { return g.n }
+func (g *Gauge) write(w io.Writer) {
+	writeHeader(w, g.n, g.h, "gauge")
+	fmt.Fprintf(w, "%s%s %s\n", g.n, g.labels, formatFloat(g.Value()))
+}
+
+// GaugeFunc is a gauge whose value is computed at scrape time
+type GaugeFunc struct {
+	n, h string
+	fn   func() float64
+}
+
+// NewGaugeFunc registers a gauge backed by a callback
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{n: name, h: help, fn: fn}
+	register(g)
+	return g
+}
+
+func (g *GaugeFunc) name() string { return g.n }
+func (g *GaugeFunc) write(w io.Writer) {
+	writeHeader(w, g.n, g.h, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.n, formatFloat(g.fn()))
+}
+
+// ─── Histogram ─────────────────────────────────────────────
+
+// DefaultLatencyBuckets covers 1ms..10s, in seconds
+var DefaultLatencyBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram counts observations into cumulative buckets
+type Histogram struct {
+	n, h    string
+	labels  string
+	buckets []float64
+
+	mu     sync.Mutex
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram registers a histogram (nil buckets = DefaultLatencyBuckets)
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := newHistogram(name, help, buckets)
+	register(h)
+	return h
+}
+
+func newHistogram(name, help string, buckets []float64) *Histogram {
+	if buckets == nil {
+		buckets = DefaultLatencyBuckets
+	}
+	return &Histogram{n: name, h: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+// Observe records one value
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// Snapshot returns count and sum of all observations
+func (h *Histogram) Snapshot() (count uint64, sum float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count, h.sum
+}
+
+func (h *Histogram) name() string { return h.n }
+func (h *Histogram) write(w io.Writer) {
+	writeHeader(w, h.n, h.h, "histogram")
+	h.writeSamples(w)
+}
+
+func (h *Histogram) writeSamples(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, upper := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.n, mergeLabels(h.labels, `le="`+formatFloat(upper)+`"`), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", h.n, mergeLabels(h.labels, `le="+Inf"`), h.count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", h.n, h.labels, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", h.n, h.labels, h.count)
+}
+
+// ─── helpers ───────────────────────────────────────────────
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func addFloat(bits *uint64, delta float64) {
+	for {
+		old := atomic.LoadUint64(bits)
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(bits, old, updated) {
+			return
+		}
+	}
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%g", v), ".0")
+}
+
+func mergeLabels(existing, extra string) string {
+	if existing == "" {
+		return "{" + extra + "}"
+	}
+	return existing[:len(existing)-1] + "," + extra + "}"
+}
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// vec holds one child metric per distinct label-value combination
+type vec[T any] struct {
+	n, h       string
+	kind       string
+	labelNames []string
+	newChild   func(labels string) T
+	writeChild func(w io.Writer, child T)
+
+	mu       sync.RWMutex
+	children map[string]T
+}
+
+func (v *vec[T]) with(values ...string) T {
+	if len(values) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.n, len(v.labelNames), len(values)))
+	}
+
+	pairs := make([]string, len(values))
+	for i, value := range values {
+		pairs[i] = fmt.Sprintf("%s=%q", v.labelNames[i], value)
+	}
+	labels := "{" + strings.Join(pairs, ",") + "}"
+
+	v.mu.RLock()
+	child, ok := v.children[labels]
+	v.mu.RUnlock()
+	if ok {
+		return child
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok := v.children[labels]; ok {
+		return child
+	}
+	child = v.newChild(labels)
+	v.children[labels] = child
+	return child
+}
+
+func (v *vec[T]) name() string { return v.n }
+func (v *vec[T]) write(w io.Writer) {
+	writeHeader(w, v.n, v.h, v.kind)
+
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.children))
+	for k := range v.children {
+		keys = append(keys, k)
+	}
+	v.mu.RUnlock()
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v.mu.RLock()
+		child := v.children[k]
+		v.mu.RUnlock()
+		v.writeChild(w, child)
+	}
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct{ *vec[*Counter] }
+
+// NewCounterVec registers a labelled counter
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	v := &vec[*Counter]{
+		n: name, h: help, kind: "counter", labelNames: labelNames,
+		children: map[string]*Counter{},
+		newChild: func(labels string) *Counter { return &Counter{n: name, labels: labels} },
+		writeChild: func(w io.Writer, c *Counter) {
+			fmt.Fprintf(w, "%s%s %s\n", c.n, c.labels, formatFloat(c.Value()))
+		},
+	}
+	register(v)
+	return &CounterVec{v}
+}
+
+// WithLabelValues returns the counter for the given label values
+func (v *CounterVec) WithLabelValues(values ...string) *Counter { return v.with(values...) }
+
+// GaugeVec is a gauge partitioned by labels
+type GaugeVec struct{ *vec[*Gauge] }
+
+// NewGaugeVec registers a labelled gauge
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	v := &vec[*Gauge]{
+		n: name, h: help, kind: "gauge", labelNames: labelNames,
+		children: map[string]*Gauge{},
+		newChild: func(labels string) *Gauge { return &Gauge{n: name, labels: labels} },
+		writeChild: func(w io.Writer, g *Gauge) {
+			fmt.Fprintf(w, "%s%s %s\n", g.n, g.labels, formatFloat(g.Value()))
+		},
+	}
+	register(v)
+	return &GaugeVec{v}
+}
+
+// WithLabelValues returns the gauge for the given label values
+func (v *GaugeVec) WithLabelValues(values ...string) *Gauge { return v.with(values...) }
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct{ *vec[*Histogram] }
+
+// NewHistogramVec registers a labelled histogram (nil buckets = DefaultLatencyBuckets)
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	v := &vec[*Histogram]{
+		n: name, h: help, kind: "histogram", labelNames: labelNames,
+		children: map[string]*Histogram{},
+		newChild: func(labels string) *Histogram {
+			h := newHistogram(name, help, buckets)
+			h.labels = labels
+			return h
+		},
+		writeChild: func(w io.Writer, h *Histogram) { h.writeSamples(w) },
+	}
+	register(v)
+	return &HistogramVec{v}
+}
+
+// WithLabelValues returns the histogram for the given label values
+func (v *HistogramVec) WithLabelValues(values ...string) *Histogram { return v.with(values...) }
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware restricts a route group to admins: an admin-tier API key,
+// or a bearer token signed with the JWT secret whose role claim names an
+// admin role. jwtSecret is read on every request, so a rotated secret takes
+// effect without a restart. Any admin role may read; use RequireRole on
+// routes that change state. Admin routes always act on the default tenant.
+func AdminMiddleware(cfg *config.AuthConfig, jwtSecret func() string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.FromContext(c)
+
+		if principal.IsTenant() {
+			apierror.Abort(c, http.StatusBadRequest, "Admin routes are not available to tenants")
+			return
+		}
+
+		if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && !principal.IsAdmin() {
+			secret := jwtSecret()
+			if secret == "" {
+				apierror.Abort(c, http.StatusUnauthorized, "Admin tokens are not enabled on this server")
+				return
+			}
+			claims, err := auth.ParseAdminToken(bearer, []byte(secret), cfg.AdminJWTIssuer, time.Now())
+			if err != nil {
+				apierror.Abort(c, http.StatusUnauthorized, err.Error())
+				return
+			}
+			principal = &auth.Principal{
+				Key:           "jwt:" + claims.Subject,
+				Tier:          auth.AdminTier,
+				Authenticated: true,
+				Role:          claims.Role,
+				Subject:       claims.Subject,
+			}
+			auth.SetPrincipal(c, principal)
+		}
+
+		if !principal.Authenticated {
+			apierror.Abort(c, http.StatusUnauthorized, "Admin API key or token required")
+			return
+		}
+		if !principal.IsAdmin() {
+			apierror.Abort(c, http.StatusForbidden, "Admin access required")
+			return
+		}
+		c.Header("X-Admin-Role", principal.AdminRole())
+		c.Next()
+	}
+}
+
+// RequireRole limits a route to admins whose role includes role
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.FromContext(c).HasRole(role) {
+			apierror.Abort(c, http.StatusForbidden, "This operation requires the "+role+" role")
+			return
+		}
+		c.Next()
+	}
+}
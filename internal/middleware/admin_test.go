@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func adminToken(t *testing.T, secret, role string) string {
+	t.Helper()
+	token, err := auth.SignAdminToken(auth.AdminClaims{
+		Subject: "alice", Role: role, ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}, []byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// TestAdminMiddlewareUsesRotatedSecret checks that tokens are verified with
+// the secret current at request time
+func TestAdminMiddlewareUsesRotatedSecret(t *testing.T) {
+	oldSecret := strings.Repeat("o", 32)
+	newSecret := strings.Repeat("n", 32)
+	current := oldSecret
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin", AdminMiddleware(&config.AuthConfig{}, func() string { return current }), func(c *gin.Context) {
+		c.String(http.StatusOK, auth.FromContext(c).Key)
+	})
+	call := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	oldToken := adminToken(t, oldSecret, auth.RoleViewer)
+	newToken := adminToken(t, newSecret, auth.RoleViewer)
+
+	for _, tc := range []struct {
+		name    string
+		secret  string
+		token   string
+		status  int
+		message string
+	}{
+		{"before rotation", oldSecret, oldToken, http.StatusOK, "jwt:alice"},
+		{"new token before rotation", oldSecret, newToken, http.StatusUnauthorized, ""},
+		{"after rotation", newSecret, newToken, http.StatusOK, "jwt:alice"},
+		{"old token after rotation", newSecret, oldToken, http.StatusUnauthorized, ""},
+		{"tokens disabled", "", newToken, http.StatusUnauthorized, "not enabled"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			current = tc.secret
+			rec := call(tc.token)
+			if rec.Code != tc.status || !strings.Contains(rec.Body.String(), tc.message) {
+				t.Errorf("status %d body %s, want %d containing %q", rec.Code, rec.Body, tc.status, tc.message)
+			}
+		})
+	}
+}
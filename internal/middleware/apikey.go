@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the caller's API key
+const APIKeyHeader = "X-API-Key"
+
+// UserIDHeader names the end user a keyed game backend is acting for
+const UserIDHeader = "X-User-ID"
+
+// APIKeyMiddleware resolves the caller's API key to a principal with a tier.
+// Requests without a key continue as anonymous (free tier); unknown keys are rejected.
+func APIKeyMiddleware(cfg *config.AuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			key = c.Query("api_key") // browsers can't set headers on WebSocket upgrades
+		}
+
+		if key == "" {
+			auth.SetPrincipal(c, &auth.Principal{
+				Key:  "ip:" + c.ClientIP(),
+				Tier: auth.AnonymousTier,
+			})
+			c.Next()
+			return
+		}
+
+		tier, ok := cfg.APIKeys[key]
+		if !ok {
+			apierror.Abort(c, http.StatusUnauthorized, "Invalid API key")
+			return
+		}
+
+		principal := &auth.Principal{
+			Key:           key,
+			Tier:          tier,
+			Authenticated: true,
+		}
+		// Only trusted (keyed) callers may say which user they act for
+		if userID, err := strconv.ParseUint(c.GetHeader(UserIDHeader), 10, 32); err == nil {
+			principal.UserID = uint(userID)
+		}
+
+		auth.SetPrincipal(c, principal)
+		c.Next()
+	}
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+var compressedResponses = metrics.NewCounter("http_responses_compressed_total",
+	"Responses sent gzip-compressed")
+
+// CompressionMiddleware gzips responses of at least cfg.MinBytes for
+// clients that accept it (Accept-Encoding: gzip). Only text-like content
+// (JSON, NDJSON, CSV, HTML, ...) is compressed; WebSocket upgrades and
+// already-encoded responses are left alone. The response is held back until
+// it reaches MinBytes, so small ones go out unchanged.
+func CompressionMiddleware(cfg config.CompressionConfig) gin.HandlerFunc {
+	pool := sync.Pool{New: func() any {
+		gz, _ := gzip.NewWriterLevel(io.Discard, cfg.Level) // level validated by config
+		return gz
+	}}
+
+	return func(c *gin.Context) {
+		if !cfg.Enabled || c.Request.Method == http.MethodHead ||
+			c.GetHeader("Upgrade") != "" || !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, minBytes: cfg.MinBytes, pool: &pool}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compressible reports whether a content type is worth compressing
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") || // application/json, application/x-ndjson, ...
+		mediaType == "application/javascript" ||
+		mediaType == "application/xml"
+}
+
+// compressWriter buffers a response until it reaches minBytes (or is
+// flushed), then decides whether to gzip it
+type compressWriter struct {
+	gin.ResponseWriter
+	minBytes int
+	pool     *sync.Pool
+	buf      []byte
+	decided  bool
+	gz       *gzip.Writer // set once decided to compress
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= w.minBytes {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written counts a buffered response as written, so nothing else answers
+// the request
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends what is buffered, compressed if the content type allows it
+// whatever its size, since a streamed response's size is unknown
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide writes the buffered bytes, compressed when largeEnough and the
+// response can be compressed
+func (w *compressWriter) decide(largeEnough bool) error {
+	w.decided = true
+	header := w.Header()
+	status := w.Status()
+	if !compressible(header.Get("Content-Type")) || header.Get("Content-Encoding") != "" ||
+		status == http.StatusNoContent || status == http.StatusNotModified {
+		largeEnough = false
+	} else {
+		header.Add("Vary", "Accept-Encoding") // the response depends on it
+	}
+	if largeEnough {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = w.pool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+		compressedResponses.Inc()
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// finish sends a response that stayed under minBytes as it is, and ends
+// the gzip stream of a compressed one
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		w.pool.Put(w.gz)
+		w.gz = nil
+	}
+}
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+const testMinBytes = 64
+
+// compressRouter serves body at /body with the given content type, setting
+// Content-Length as a handler writing a known payload would
+func compressRouter(cfg config.CompressionConfig, contentType, body string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CompressionMiddleware(cfg))
+	serve := func(c *gin.Context) {
+		c.Header("Content-Length", strconv.Itoa(len(body)))
+		c.Data(http.StatusOK, contentType, []byte(body))
+	}
+	r.GET("/body", serve)
+	r.HEAD("/body", serve)
+	r.GET("/empty", func(c *gin.Context) {
+		c.Header("Content-Type", "application/json")
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+// readBody returns the response body, gunzipped if it is compressed
+func readBody(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		return rec.Body.String()
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("gunzip: %v", err)
+	}
+	return string(body)
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	small := `{"rank":1}`
+	large := `{"users":[` + strings.Repeat(`{"rank":1},`, 20) + `{"rank":2}]}`
+	enabled := config.CompressionConfig{Enabled: true, MinBytes: testMinBytes, Level: gzip.DefaultCompression}
+
+	for _, tc := range []struct {
+		name           string
+		cfg            config.CompressionConfig
+		method         string
+		path           string
+		contentType    string
+		body           string
+		header         map[string]string
+		wantCompressed bool
+		wantVary       bool
+	}{
+		{
+			name: "above MinBytes", cfg: enabled, contentType: "application/json", body: large,
+			header: map[string]string{"Accept-Encoding": "gzip"}, wantCompressed: true, wantVary: true,
+		},
+		{
+			name: "below MinBytes", cfg: enabled, contentType: "application/json", body: small,
+			header: map[string]string{"Accept-Encoding": "gzip"}, wantVary: true,
+		},
+		{
+			name: "exactly MinBytes", cfg: enabled, contentType: "application/json", body: strings.Repeat("x", testMinBytes),
+			header: map[string]string{"Accept-Encoding": "gzip"}, wantCompressed: true, wantVary: true,
+		},
+		{
+			name: "no Accept-Encoding", cfg: enabled, contentType: "application/json", body: large,
+		},
+		{
+			name: "gzip among others", cfg: enabled, contentType: "application/json", body: large,
+			header: map[string]string{"Accept-Encoding": "br, gzip;q=0.8"}, wantCompressed: true, wantVary: true,
+		},
+		{
+			name: "wildcard", cfg: enabled, contentType: "application/json", body: large,
+			header: map[string]string{"Accept-Encoding": "*"}, wantCompressed: true, wantVary: true,
+		},
+		{
+			name: "gzip refused with q=0", cfg: enabled, contentType: "application/json", body: large,
+			header: map[string]string{"Accept-Encoding": "gzip;q=0"},
+		},
+		{
+			name: "other encodings only", cfg: enabled, contentType: "application/json", body: large,
+			header: map[string]string{"Accept-Encoding": "br, deflate"},
+		},
+		{
+			name: "upgrade bypassed", cfg: enabled, contentType: "application/json", body: large,
+			header: map[string]string{"Accept-Encoding": "gzip", "Upgrade": "websocket"},
+		},
+		{
+			name: "HEAD bypassed", cfg: enabled, method: http.MethodHead, contentType: "application/json", body: large,
+			header: map[string]string{"Accept-Encoding": "gzip"},
+		},
+		{
+			name: "binary content", cfg: enabled, contentType: "image/png", body: large,
+			header: map[string]string{"Accept-Encoding": "gzip"},
+		},
+		{
+			name: "csv with charset", cfg: enabled, contentType: "text/csv; charset=utf-8", body: large,
+			header: map[string]string{"Accept-Encoding": "gzip"}, wantCompressed: true, wantVary: true,
+		},
+		{
+			name: "disabled", cfg: config.CompressionConfig{MinBytes: testMinBytes, Level: gzip.DefaultCompression},
+			contentType: "application/json", body: large, header: map[string]string{"Accept-Encoding": "gzip"},
+		},
+		{
+			name: "no content", cfg: enabled, path: "/empty",
+			header: map[string]string{"Accept-Encoding": "gzip"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			method, path := tc.method, tc.path
+			if method == "" {
+				method = http.MethodGet
+			}
+			if path == "" {
+				path = "/body"
+			}
+			req := httptest.NewRequest(method, path, nil)
+			for name, value := range tc.header {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			compressRouter(tc.cfg, tc.contentType, tc.body).ServeHTTP(rec, req)
+
+			header := rec.Header()
+			if compressed := header.Get("Content-Encoding") == "gzip"; compressed != tc.wantCompressed {
+				t.Fatalf("compressed = %v, want %v", compressed, tc.wantCompressed)
+			}
+			if vary := header.Get("Vary") == "Accept-Encoding"; vary != tc.wantVary {
+				t.Errorf("Vary = %q, want Accept-Encoding: %v", header.Get("Vary"), tc.wantVary)
+			}
+			// A compressed body's length differs from the one the handler set
+			if length := header.Get("Content-Length"); tc.wantCompressed && length != "" {
+				t.Errorf("Content-Length = %s on a compressed response, want none", length)
+			} else if !tc.wantCompressed && path == "/body" && length != strconv.Itoa(len(tc.body)) {
+				t.Errorf("Content-Length = %q, want %d", length, len(tc.body))
+			}
+			if method == http.MethodHead || path == "/empty" {
+				return
+			}
+			if got := readBody(t, rec); got != tc.body {
+				t.Errorf("body = %q, want %q", got, tc.body)
+			}
+		})
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip", true},
+		{"gzip;q=0.5", true},
+		{"gzip; q=0", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"identity", false},
+		{"x-gzip", false},
+	} {
+		if got := acceptsGzip(tc.header); got != tc.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tc.header, got, tc.want)
+		}
+	}
+}
+
+// TestCompressionFlushStreams checks that a flushed response is compressed
+// whatever its size, since a stream's final size is unknown
+func TestCompressionFlushStreams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CompressionMiddleware(config.CompressionConfig{Enabled: true, MinBytes: 1 << 20, Level: gzip.BestSpeed}))
+	r.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "application/x-ndjson")
+		for i := range 3 {
+			c.Writer.WriteString(`{"line":` + strconv.Itoa(i) + "}\n")
+			c.Writer.Flush()
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatal("streamed response not compressed")
+	}
+	if want := "{\"line\":0}\n{\"line\":1}\n{\"line\":2}\n"; readBody(t, rec) != want {
+		t.Errorf("body = %q, want %q", readBody(t, rec), want)
+	}
+}
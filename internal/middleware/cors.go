@@ -2,33 +2,27 @@ package middleware
 
 import (
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/origins"
 	"github.com/gin-gonic/gin"
 )
 
 // CORSMiddleware handles Cross-Origin Resource Sharing
 func CORSMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		allowedOrigins := config.AppCfg.App.AllowedOrigins
+	allowedOrigins := config.AppCfg.App.AllowedOrigins
+	matcher := origins.New(allowedOrigins)
 
+	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
 
 		// Check if origin is allowed
-		isAllowed := false
-		for _, allowedOrigin := range allowedOrigins {
-			if origin == allowedOrigin || allowedOrigin == "*" {
-				isAllowed = true
-				break
-			}
-		}
-
-		if isAllowed {
+		if matcher.Allowed(origin) {
 			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-		} else {
+		} else if len(allowedOrigins) > 0 {
 			c.Writer.Header().Set("Access-Control-Allow-Origin", allowedOrigins[0])
 		}
 
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Tenant-ID, X-Request-ID, X-API-Version")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
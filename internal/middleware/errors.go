@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/logging"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/ranking"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// serviceErrors maps the errors services return to a status and code; the
+// first match (errors.Is) wins
+var serviceErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{service.ErrValidation, http.StatusBadRequest, service.CodeValidation},
+	{ranking.ErrInvalidResult, http.StatusBadRequest, service.CodeValidation},
+	{service.ErrUserNotFound, http.StatusNotFound, service.CodeUserNotFound},
+	{service.ErrUserNotOnBoard, http.StatusNotFound, service.CodeUserNotOnBoard},
+	{service.ErrUsernameTaken, http.StatusConflict, service.CodeUsernameTaken},
+	{service.ErrUserBanned, http.StatusForbidden, service.CodeUserBanned},
+	{service.ErrRatingDeltaExceeded, http.StatusUnprocessableEntity, service.CodeRatingDeltaExceeded},
+	{service.ErrUserBusy, http.StatusConflict, service.CodeUserBusy},
+	{service.ErrRedisUnavailable, http.StatusServiceUnavailable, service.CodeRedisUnavailable},
+	{service.ErrSyncBacklogFull, http.StatusServiceUnavailable, service.CodeSyncBacklogFull},
+	{service.ErrTierNotFound, http.StatusNotFound, service.CodeTierNotFound},
+	{service.ErrUpdateNotFound, http.StatusNotFound, service.CodeUpdateNotFound},
+	{service.ErrTeamNotFound, http.StatusNotFound, service.CodeTeamNotFound},
+	{service.ErrTeamNameTaken, http.StatusConflict, service.CodeTeamNameTaken},
+	{service.ErrTeamFull, http.StatusConflict, service.CodeTeamFull},
+	{service.ErrAlreadyInTeam, http.StatusConflict, service.CodeAlreadyInTeam},
+	{service.ErrNotTeamMember, http.StatusNotFound, service.CodeNotTeamMember},
+	{service.ErrJobNotFound, http.StatusNotFound, service.CodeJobNotFound},
+	{service.ErrJobQueueFull, http.StatusServiceUnavailable, service.CodeJobQueueFull},
+	{service.ErrSpikeNotFound, http.StatusNotFound, service.CodeSpikeNotFound},
+	{service.ErrSpikeRunning, http.StatusConflict, service.CodeSpikeRunning},
+	{service.ErrBenchmarkRunning, http.StatusConflict, service.CodeBenchmarkRunning},
+	{service.ErrReconcileRunning, http.StatusConflict, service.CodeReconcileRunning},
+	{service.ErrFlagNotFound, http.StatusNotFound, service.CodeFlagNotFound},
+	{service.ErrWebhookNotFound, http.StatusNotFound, service.CodeWebhookNotFound},
+	{service.ErrSessionNotFound, http.StatusNotFound, service.CodeSessionNotFound},
+	{service.ErrNoProtection, http.StatusNotFound, service.CodeNoProtection},
+}
+
+// retryAfter is the Retry-After (seconds) sent with errors that clear up
+// on their own
+var retryAfter = map[error]string{
+	service.ErrSyncBacklogFull: "30",
+}
+
+// ErrorMiddleware answers requests whose handler failed with a service
+// error (apierror.Fail) in the error envelope. Known errors get their
+// status and code, with the error's message (validation errors keep their
+// details); anything else is logged and answered with 500 and the
+// handler's message, so internal details don't leak to callers.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		last := c.Errors.Last()
+
+		for _, known := range serviceErrors {
+			if !errors.Is(last.Err, known.err) {
+				continue
+			}
+			message := known.err.Error()
+			if known.status == http.StatusBadRequest {
+				message = last.Err.Error()
+			}
+			if seconds, ok := retryAfter[known.err]; ok {
+				c.Header("Retry-After", seconds)
+			}
+			apierror.AbortCode(c, known.status, known.code, message)
+			return
+		}
+
+		logging.FromContext(c.Request.Context()).Error("❌ Request failed", "error", last.Err)
+		message, ok := last.Meta.(string)
+		if !ok {
+			message = "Internal server error"
+		}
+		apierror.Abort(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/fairqueue"
+	"github.com/gin-gonic/gin"
+)
+
+// FairQueueMiddleware holds a fair-queue slot for the caller's API key while
+// the handler runs, so bulk callers can't starve interactive traffic
+func FairQueueMiddleware(scheduler *fairqueue.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.FromContext(c)
+
+		release, err := scheduler.Acquire(c.Request.Context(), principal.Key, principal.Tier)
+		if err != nil {
+			c.Header("Retry-After", "1")
+			apierror.AbortDetails(c, http.StatusTooManyRequests, apierror.CodeRateLimited,
+				"Server busy, retry later", gin.H{"retry_after": 1})
+			return
+		}
+		defer release()
+
+		c.Next()
+	}
+}
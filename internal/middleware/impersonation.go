@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonationHeader carries a support impersonation token
+const ImpersonationHeader = "X-Impersonation-Token"
+
+// ImpersonationMiddleware lets a request carrying an impersonation token see
+// the API as the session's user. Sessions are read-only, and every request
+// made with one is recorded in the impersonation audit trail.
+func ImpersonationMiddleware(impersonationSvc service.ImpersonationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(ImpersonationHeader)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		session, err := impersonationSvc.Resolve(token)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidImpersonation) {
+				apierror.Abort(c, http.StatusUnauthorized, err.Error())
+				return
+			}
+			apierror.Fail(c, err, "Failed to check impersonation token")
+			return
+		}
+
+		auth.SetPrincipal(c, &auth.Principal{
+			Key:             "impersonation:" + session.ID,
+			Tier:            auth.AnonymousTier,
+			UserID:          session.UserID,
+			Impersonator:    session.Admin,
+			ImpersonationID: session.ID,
+		})
+		c.Header("X-Impersonated-User", strconv.FormatUint(uint64(session.UserID), 10))
+
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			apierror.Abort(c, http.StatusForbidden, "Impersonation sessions are read-only")
+		} else {
+			c.Next()
+		}
+
+		impersonationSvc.RecordRequest(session, c.Request.Method, c.Request.URL.Path, c.Writer.Status())
+	}
+}
@@ -1,41 +1,60 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/logging"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/requestid"
 	"github.com/gin-gonic/gin"
 )
 
-// LoggerMiddleware logs HTTP requests
+// LoggerMiddleware gives each request a logger carrying its request ID (set
+// by RequestIDMiddleware), method and path (logging.FromContext), and logs
+// the request when done: at ERROR for 5xx responses, WARN for 4xx and INFO
+// otherwise
 func LoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
-		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
+
+		logger := slog.Default().With(
+			"request_id", requestid.FromContext(c.Request.Context()),
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+		)
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), logger))
 
 		// Process request
 		c.Next()
 
-		// Calculate latency
-		latency := time.Since(start)
-
-		// Get status code
 		statusCode := c.Writer.Status()
+		attrs := []any{
+			"status", statusCode,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip", c.ClientIP(),
+		}
+		if raw := c.Request.URL.RawQuery; raw != "" {
+			attrs = append(attrs, "query", raw)
+		}
 
-		// Build query string
-		if raw != "" {
-			path = path + "?" + raw
+		// Flag requests made by support on behalf of a user
+		if p := auth.FromContext(c); p.IsImpersonated() {
+			attrs = append(attrs,
+				"impersonation", p.ImpersonationID,
+				"impersonator", p.Impersonator,
+				"user_id", p.UserID,
+			)
 		}
 
-		// Log format: [TIME] STATUS METHOD PATH LATENCY
-		log.Printf("[%s] %d %s %s %v",
-			start.Format("2006-01-02 15:04:05"),
-			statusCode,
-			c.Request.Method,
-			path,
-			latency,
-		)
+		level := slog.LevelInfo
+		switch {
+		case statusCode >= 500:
+			level = slog.LevelError
+		case statusCode >= 400:
+			level = slog.LevelWarn
+		}
+		logger.Log(c.Request.Context(), level, "request", attrs...)
 	}
 }
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/logging"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware counts each request against the caller's per-window
+// quota. Every limited response carries X-RateLimit-Limit/Remaining/Reset
+// (Reset in Unix seconds); over-quota requests get 429 with retry_after.
+// If Redis is unavailable requests are let through.
+func RateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.FromContext(c)
+
+		result, err := limiter.Allow(c.Request.Context(), principal.Fingerprint(), principal.Tier)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Warn("⚠️ Rate limiter unavailable, allowing request", "error", err)
+			c.Next()
+			return
+		}
+		SetRateLimitHeaders(c, result)
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter().Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			apierror.AbortDetails(c, http.StatusTooManyRequests, apierror.CodeRateLimited,
+				"Rate limit exceeded", gin.H{"retry_after": retryAfter})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SetRateLimitHeaders describes a quota in the response headers
+// (nothing is set for unlimited tiers)
+func SetRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	if result.Limit <= 0 {
+		return
+	}
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(result.Reset.Unix(), 10))
+}
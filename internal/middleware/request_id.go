@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDMiddleware gives each request an ID (the caller's X-Request-ID,
+// or a new one), returns it in the X-Request-ID response header and puts it
+// in the request context. Score updates carry it through the event bus to
+// the DB sync and to the WebSocket broadcast on every server.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if id == "" || len(id) > requestid.MaxLength {
+			id = requestid.New()
+		}
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Next()
+	}
+}
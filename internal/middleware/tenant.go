@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/apierror"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// TenantHeader selects the tenant of a request
+const TenantHeader = "X-Tenant-ID"
+
+// TenantMiddleware resolves the tenant a request is served by. Keys bound in
+// TENANT_API_KEYS always act for their tenant; other callers may pick one
+// with X-Tenant-ID when TENANT_HEADER_ENABLED. Must run after
+// APIKeyMiddleware.
+func TenantMiddleware(cfg *config.TenantConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.FromContext(c)
+
+		requested := c.GetHeader(TenantHeader)
+		if requested == "" {
+			requested = c.Query("tenant") // browsers can't set headers on WebSocket upgrades
+		}
+
+		bound, isBound := "", false
+		if principal.Authenticated {
+			bound, isBound = cfg.APIKeys[principal.Key]
+		}
+
+		switch {
+		case isBound:
+			if requested != "" && requested != bound {
+				apierror.Abort(c, http.StatusForbidden, "API key is not valid for this tenant")
+				return
+			}
+			principal.Tenant = bound
+		case requested == "":
+		case principal.IsSandbox():
+			apierror.Abort(c, http.StatusBadRequest, "Sandbox keys cannot select a tenant")
+			return
+		case !cfg.Header:
+			apierror.Abort(c, http.StatusBadRequest, "Tenants are selected by API key on this server")
+			return
+		case !cfg.Has(requested):
+			apierror.Abort(c, http.StatusNotFound, "Unknown tenant")
+			return
+		default:
+			principal.Tenant = requested
+		}
+
+		c.Next()
+	}
+}
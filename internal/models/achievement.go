@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Achievement codes
+const (
+	AchievementFirstWin   = "first_win"   // first score update that raised the rating
+	AchievementHighRating = "high_rating" // reached ACHIEVEMENT_RATING
+	AchievementWinStreak  = "win_streak"  // ACHIEVEMENT_STREAK rating gains in a row
+)
+
+// Achievement is a badge a user unlocked; each is unlocked at most once
+type Achievement struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	UserID     uint      `gorm:"uniqueIndex:idx_achievement_user_code;not null" json:"user_id"`
+	User       User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Code       string    `gorm:"uniqueIndex:idx_achievement_user_code;size:32;not null" json:"code"`
+	Threshold  int       `json:"threshold,omitempty"` // high_rating: the rating; win_streak: the streak
+	Rating     int       `json:"rating"`              // rating when unlocked
+	UnlockedAt time.Time `gorm:"not null" json:"unlocked_at"`
+}
+
+func (Achievement) TableName() string {
+	return "achievements"
+}
+
+// AchievementPayload represents an achievement_unlocked event
+type AchievementPayload struct {
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Code      string `json:"code"`
+	Threshold int    `json:"threshold,omitempty"`
+	Rank      int64  `json:"rank"`   // rank after the update
+	Rating    int    `json:"rating"` // rating after the update
+	Timestamp int64  `json:"timestamp"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Anomaly flag review states
+const (
+	AnomalyStatusOpen      = "open"
+	AnomalyStatusConfirmed = "confirmed"
+	AnomalyStatusDismissed = "dismissed"
+)
+
+// AnomalyFlag is a suspicious score update awaiting (or after) admin review
+type AnomalyFlag struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index:idx_anomaly_user;not null" json:"user_id"`
+	Rule       string     `gorm:"size:50;not null" json:"rule"`
+	Detail     string     `gorm:"size:255" json:"detail"`
+	OldRating  int        `json:"old_rating"`
+	NewRating  int        `json:"new_rating"`
+	Status     string     `gorm:"size:16;not null;default:open;index:idx_anomaly_status" json:"status"`
+	ReviewedBy string     `gorm:"size:100" json:"reviewed_by,omitempty"`
+	ReviewNote string     `gorm:"size:500" json:"review_note,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"index:idx_anomaly_time" json:"created_at"`
+}
+
+func (AnomalyFlag) TableName() string {
+	return "anomaly_flags"
+}
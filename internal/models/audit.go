@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// AdminAdjustment records who changed a score through the API, and why
+type AdminAdjustment struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index:idx_adjustment_user;not null" json:"user_id"`
+	Actor     string    `gorm:"size:100;index:idx_adjustment_actor;not null" json:"actor"`
+	Reason    string    `gorm:"size:500" json:"reason,omitempty"`
+	Source    string    `gorm:"size:20;not null" json:"source"` // single | bulk | import | async | revert
+	OldRating int       `json:"old_rating"`
+	NewRating int       `json:"new_rating"`
+	Change    int       `json:"change"`
+	CreatedAt time.Time `gorm:"index:idx_adjustment_time" json:"created_at"`
+}
+
+func (AdminAdjustment) TableName() string {
+	return "admin_adjustments"
+}
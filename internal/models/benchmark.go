@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Benchmark verdicts: how this run compares to the stored baseline
+const (
+	BenchmarkOK         = "ok"
+	BenchmarkDegraded   = "degraded" // slower than the baseline by the configured ratio
+	BenchmarkFailed     = "failed"   // some operations errored or timed out
+	BenchmarkNoBaseline = "no_baseline"
+)
+
+// BenchmarkReport is one run of the data store micro benchmark on one server
+type BenchmarkReport struct {
+	Node       string            `json:"node"`
+	StartedAt  time.Time         `json:"started_at"`
+	DurationMs float64           `json:"duration_ms"`
+	Ops        int               `json:"ops"` // per operation
+	Results    []BenchmarkResult `json:"results"`
+
+	// Filled in by comparing against the baseline (absent on the baseline itself)
+	Verdict    string     `json:"verdict,omitempty"`
+	Degraded   []string   `json:"degraded,omitempty"` // stores whose operations are slow or failing
+	BaselineAt *time.Time `json:"baseline_at,omitempty"`
+}
+
+// BenchmarkResult is the latency of one operation, in milliseconds
+type BenchmarkResult struct {
+	Name   string  `json:"name"`  // e.g. redis_zadd, postgres_select
+	Store  string  `json:"store"` // redis, postgres
+	Errors int     `json:"errors"`
+	Mean   float64 `json:"mean_ms"`
+	P50    float64 `json:"p50_ms"`
+	P95    float64 `json:"p95_ms"`
+	P99    float64 `json:"p99_ms"`
+	Max    float64 `json:"max_ms"`
+
+	BaselineP50 float64 `json:"baseline_p50_ms,omitempty"`
+	Ratio       float64 `json:"ratio,omitempty"` // p50 / baseline p50
+	Verdict     string  `json:"verdict,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// UserRankResult is one entry of a bulk rank lookup
+type UserRankResult struct {
+	UserID uint   `json:"user_id"`
+	Rank   int64  `json:"rank,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkScoreResult is one entry of a bulk score update
+type BulkScoreResult struct {
+	UserID uint                `json:"user_id"`
+	Update *ScoreUpdatePayload `json:"update,omitempty"`
+	Error  string              `json:"error,omitempty"`
+	Code   string              `json:"code,omitempty"` // machine-readable error code
+
+	// Queued for replay because Redis was unreachable
+	Deferred *DeferredScoreUpdate `json:"deferred,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// DeferredScoreUpdate is a score update accepted while Redis was
+// unreachable, kept in PostgreSQL until it can be replayed through the
+// normal update path
+type DeferredScoreUpdate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index:idx_deferred_user;not null" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	NewRating int       `gorm:"not null" json:"new_rating"`
+	Actor     string    `gorm:"size:100;not null" json:"actor"`
+	Reason    string    `gorm:"size:500" json:"reason,omitempty"`
+	Source    string    `gorm:"size:20;not null" json:"source"` // audit source once replayed
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (DeferredScoreUpdate) TableName() string {
+	return "deferred_score_updates"
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// DigestSubscription is a user's opt-in for the daily rank digest
+type DigestSubscription struct {
+	UserID     uint       `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	OptedIn    bool       `gorm:"not null;default:false;index:idx_digest_due,priority:1" json:"opted_in"`
+	Timezone   string     `gorm:"size:64;not null;default:'UTC';index:idx_digest_due,priority:2" json:"timezone"`
+	WebhookURL string     `gorm:"size:500" json:"webhook_url,omitempty"`
+	Email      string     `gorm:"size:255" json:"email,omitempty"`
+	LastSentOn *time.Time `gorm:"type:date" json:"last_sent_on,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (DigestSubscription) TableName() string {
+	return "digest_subscriptions"
+}
+
+// DigestSummary is the daily summary event delivered to a subscriber
+type DigestSummary struct {
+	UserID       uint               `json:"user_id"`
+	Username     string             `json:"username"`
+	Date         string             `json:"date"` // local date in the user's timezone
+	Rating       int                `json:"rating"`
+	RatingChange int                `json:"rating_change"`
+	Rank         int64              `json:"rank"`
+	PreviousRank int64              `json:"previous_rank,omitempty"`
+	RankChange   int64              `json:"rank_change"` // positive = improved
+	Nearby       []LeaderboardEntry `json:"nearby_competitors"`
+	Milestones   []string           `json:"milestones,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// Domain event types published on the event bus. Cluster-wide events are
+// forwarded to WebSocket clients under the same name.
+const (
+	EventScoreUpdate       = "score_update"         // *ScoreUpdatePayload
+	EventShadowScoreUpdate = "shadow_score_update"  // *ScoreUpdatePayload, never broadcast
+	EventUserRenamed       = "user_renamed"         // *UserRenamedPayload
+	EventUserRemoved       = "user_removed"         // *UserRemovedPayload
+	EventLeaderboardReset  = "leaderboard_reset"    // *LeaderboardResetPayload
+	EventMilestone         = "milestone"            // *MilestonePayload
+	EventPromotion         = "promotion"            // *TierChangePayload
+	EventDemotion          = "demotion"             // *TierChangePayload
+	EventAchievement       = "achievement_unlocked" // *AchievementPayload
+	EventAnnouncement      = "announcement"         // *AnnouncementPayload
+)
+
+// Milestone kinds
+const (
+	MilestoneTopRank      = "top_rank"      // entered the top N
+	MilestonePersonalBest = "personal_best" // highest rating the user ever had
+	MilestoneStreak       = "streak"        // N days in a row with a rating gain
+)
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Impersonation event kinds
+const (
+	ImpersonationStarted = "started"
+	ImpersonationRequest = "request"
+	ImpersonationEnded   = "ended"
+)
+
+// ImpersonationSession lets support view the API as a user. Sessions live
+// in Redis until they expire or are ended; the token is never stored.
+type ImpersonationSession struct {
+	ID        string    `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Admin     string    `json:"admin"` // actor of the admin who started it
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ImpersonationEvent is the audit trail of a session: its start, end and
+// every request made with it
+type ImpersonationEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SessionID string    `gorm:"size:32;index:idx_impersonation_session;not null" json:"session_id"`
+	UserID    uint      `gorm:"index:idx_impersonation_user;not null" json:"user_id"`
+	Admin     string    `gorm:"size:100;index:idx_impersonation_admin;not null" json:"admin"`
+	Event     string    `gorm:"size:20;not null" json:"event"` // started | request | ended
+	Method    string    `gorm:"size:10" json:"method,omitempty"`
+	Path      string    `gorm:"size:500" json:"path,omitempty"`
+	Status    int       `json:"status,omitempty"`
+	Reason    string    `gorm:"size:500" json:"reason,omitempty"`
+	CreatedAt time.Time `gorm:"index:idx_impersonation_time" json:"created_at"`
+}
+
+func (ImpersonationEvent) TableName() string {
+	return "impersonation_events"
+}
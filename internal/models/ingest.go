@@ -0,0 +1,19 @@
+package models
+
+// Async score update states
+const (
+	IngestQueued  = "queued"
+	IngestApplied = "applied"
+	IngestFailed  = "failed"
+)
+
+// IngestStatus tracks a score update accepted with 202 and applied later
+type IngestStatus struct {
+	ID        string              `json:"id"`
+	Status    string              `json:"status"`
+	UserID    uint                `json:"user_id"`
+	NewRating int                 `json:"new_rating"`
+	Update    *ScoreUpdatePayload `json:"update,omitempty"` // set once applied
+	Error     string              `json:"error,omitempty"`
+	Code      string              `json:"code,omitempty"` // machine-readable error code
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// IntegrityReport is the outcome of one leaderboard checksum run
+type IntegrityReport struct {
+	Node             string    `json:"node"`
+	Round            time.Time `json:"round"` // interval boundary shared by all servers
+	CheckedAt        time.Time `json:"checked_at"`
+	Duration         string    `json:"duration"`
+	RedisChecksum    string    `json:"redis_checksum"`
+	PostgresChecksum string    `json:"postgres_checksum"`
+	RedisUsers       int       `json:"redis_users"`
+	PostgresUsers    int       `json:"postgres_users"`
+	Chunks           int       `json:"chunks"`
+	MismatchedChunks int       `json:"mismatched_chunks"`
+
+	// Users that still differed after the settle re-check, vs ones that
+	// changed in between (in-flight updates, not corruption)
+	Mismatches int                 `json:"mismatches"`
+	InFlight   int                 `json:"in_flight"`
+	Samples    []IntegrityMismatch `json:"samples,omitempty"`
+
+	Peers []IntegrityPeer `json:"peers,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// IntegrityMismatch is one user whose Redis and PostgreSQL ratings differ
+// (nil = missing on that side)
+type IntegrityMismatch struct {
+	UserID   uint `json:"user_id"`
+	Redis    *int `json:"redis"`
+	Postgres *int `json:"postgres"`
+}
+
+// IntegrityPeer compares another server's view of the sorted set in the
+// same round
+type IntegrityPeer struct {
+	Node             string    `json:"node"`
+	CheckedAt        time.Time `json:"checked_at"`
+	RedisChecksum    string    `json:"redis_checksum"`
+	Match            bool      `json:"match"`
+	MismatchedChunks int       `json:"mismatched_chunks"`
+}
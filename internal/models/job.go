@@ -0,0 +1,85 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Job states
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is a long-running admin operation (import, compaction, spike, ...)
+// executed by the job worker of the server that accepted it
+type Job struct {
+	ID              string     `gorm:"primaryKey;size:24" json:"id"`
+	Type            string     `gorm:"size:50;not null;index:idx_job_type" json:"type"`
+	Status          string     `gorm:"size:16;not null;index:idx_job_status" json:"status"`
+	Actor           string     `gorm:"size:100" json:"actor,omitempty"`
+	Node            string     `gorm:"size:100" json:"node"` // server running the job
+	Params          RawJSON    `gorm:"type:text" json:"params,omitempty"`
+	Total           int64      `json:"total"`
+	Processed       int64      `json:"processed"`
+	Result          RawJSON    `gorm:"type:text" json:"result,omitempty"`
+	Error           string     `gorm:"size:1000" json:"error,omitempty"`
+	CancelRequested bool       `gorm:"not null;default:false" json:"cancel_requested"`
+	CreatedAt       time.Time  `gorm:"index:idx_job_created" json:"created_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// Finished reports whether the job reached a terminal state
+func (j *Job) Finished() bool {
+	return j.Status == JobCompleted || j.Status == JobFailed || j.Status == JobCancelled
+}
+
+// RawJSON is pre-encoded JSON stored in a text column and emitted verbatim
+type RawJSON []byte
+
+func (j RawJSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *RawJSON) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*j = nil
+	case string:
+		*j = RawJSON(v)
+	case []byte:
+		*j = append(RawJSON(nil), v...)
+	default:
+		return fmt.Errorf("cannot scan %T into RawJSON", src)
+	}
+	return nil
+}
+
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *RawJSON) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*j = nil
+		return nil
+	}
+	*j = append(RawJSON(nil), data...)
+	return nil
+}
@@ -0,0 +1,12 @@
+package models
+
+// MatchCandidate is an opponent suggested to a player, within the rating
+// band asked for
+type MatchCandidate struct {
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Rating    int    `json:"rating"`
+	RatingGap int    `json:"rating_gap"` // candidate's rating minus the player's
+	Tier      string `json:"tier,omitempty"`
+	Division  int    `json:"division,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Notification events a user can opt into
+const (
+	NotifyDailyDigest = "daily_digest"
+	NotifyScoreUpdate = "score_update"
+)
+
+// Notification channels (notify.Sender channel names)
+const (
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+// NotificationPreference says which notifications a user receives, over
+// which channels, and when not to send them. Delivery endpoints (webhook
+// URL, email) are those of the user's digest subscription.
+type NotificationPreference struct {
+	UserID     uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	User       User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Events     []string  `gorm:"serializer:json;type:text;not null" json:"events"`
+	Channels   []string  `gorm:"serializer:json;type:text;not null" json:"channels"`
+	QuietHours string    `gorm:"size:11" json:"quiet_hours"` // "22:00-07:00" local, empty = none
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Resolved on read, not stored
+	Timezone string `gorm:"-" json:"timezone"` // the user's; quiet hours are in it
+	Default  bool   `gorm:"-" json:"default"`  // nothing saved: tenant defaults apply
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
@@ -0,0 +1,27 @@
+package models
+
+// UserSnapshot is a user's live leaderboard position read from Redis
+type UserSnapshot struct {
+	UserID   uint
+	Username string
+	Rating   int
+	Rank     int64
+	Total    int64
+}
+
+// UserProfile aggregates everything a profile screen needs in one response
+type UserProfile struct {
+	UserID        uint          `json:"user_id"`
+	Username      string        `json:"username"`
+	Rating        int           `json:"rating"`
+	Tier          string        `json:"tier,omitempty"`
+	Division      int           `json:"division,omitempty"`
+	GlobalRank    int64         `json:"global_rank"`
+	TotalPlayers  int64         `json:"total_players"`
+	Percentile    float64       `json:"percentile"`     // share of players at or below this rank
+	RankDelta24h  int64         `json:"rank_delta_24h"` // positive = improved
+	RecentHistory []ScoreUpdate `json:"recent_history"`
+	Achievements  []Achievement `json:"achievements"`
+	CurrentStreak int           `json:"current_streak"` // days in a row with a rating gain, up to today or yesterday
+	LongestStreak int           `json:"longest_streak"`
+}
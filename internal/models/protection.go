@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ProtectionMark is an active point-in-time rating floor: reverts never take
+// a user below the rating they had at MarkedAt unless overridden
+type ProtectionMark struct {
+	MarkedAt time.Time `json:"marked_at"`
+	Actor    string    `json:"actor"`
+	Reason   string    `json:"reason,omitempty"`
+	Users    int64     `json:"users"` // ratings snapshotted
+}
+
+// RevertResult is the per-user outcome of an admin revert
+type RevertResult struct {
+	UserID   uint                `json:"user_id"`
+	Target   int                 `json:"target,omitempty"`   // rating at the revert time
+	Floor    int                 `json:"floor,omitempty"`    // protection floor, when one applied
+	Held     bool                `json:"held,omitempty"`     // target raised to the floor
+	Override bool                `json:"override,omitempty"` // floor ignored on request
+	Update   *ScoreUpdatePayload `json:"update,omitempty"`
+	Skipped  string              `json:"skipped,omitempty"` // why nothing was changed
+	Error    string              `json:"error,omitempty"`
+}
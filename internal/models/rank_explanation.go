@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Causes of a user's own rating changes
+const (
+	CauseScoreUpdate     = "score_update"     // score endpoint, bulk, async or unaudited updates
+	CauseMatchResult     = "match_result"     // submitted game result
+	CauseAdminAdjustment = "admin_adjustment" // revert or import by an admin
+	CauseDecay           = "decay"            // inactivity decay
+)
+
+// RankExplanation breaks a user's rank change over a window down into the
+// events that caused it. Rank changes are positive when the rank improved.
+type RankExplanation struct {
+	UserID     uint      `json:"user_id"`
+	Since      time.Time `json:"since"`
+	Until      time.Time `json:"until"`
+	RatingThen int       `json:"rating_then"`
+	RatingNow  int       `json:"rating_now"`
+	RankThen   int64     `json:"rank_then"`
+	RankNow    int64     `json:"rank_now"`
+	RankChange int64     `json:"rank_change"`
+
+	Summary    RankChangeSummary  `json:"summary"`
+	OwnUpdates []OwnRankChange    `json:"own_updates"` // newest last
+	Players    []PlayerRankImpact `json:"players"`     // largest impact first
+	Truncated  bool               `json:"truncated,omitempty"`
+}
+
+// RankChangeSummary adds up the rank change by cause
+type RankChangeSummary struct {
+	OwnUpdates       int64 `json:"own_updates"`
+	AdminAdjustments int64 `json:"admin_adjustments"`
+	Decay            int64 `json:"decay"`
+	Overtaken        int64 `json:"overtaken"`      // players who moved above the user
+	OthersDropped    int64 `json:"others_dropped"` // players who fell below the user
+	Unexplained      int64 `json:"unexplained"`    // e.g. updates not yet in PostgreSQL
+}
+
+// OwnRankChange is one of the user's own rating changes
+type OwnRankChange struct {
+	At         time.Time `json:"at"`
+	OldRating  int       `json:"old_rating"`
+	NewRating  int       `json:"new_rating"`
+	RankChange int64     `json:"rank_change"`
+	Cause      string    `json:"cause"`
+	Source     string    `json:"source,omitempty"` // audit source when audited
+	Reason     string    `json:"reason,omitempty"`
+}
+
+// PlayerRankImpact is how another player's updates moved the user's rank
+type PlayerRankImpact struct {
+	UserID     uint      `json:"user_id"`
+	Username   string    `json:"username"`
+	Overtook   int       `json:"overtook"`    // times they moved above the user
+	FellBehind int       `json:"fell_behind"` // times they fell below the user
+	RankChange int64     `json:"rank_change"`
+	LastAt     time.Time `json:"last_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RankHistory is a point-in-time snapshot of a user's global rank
+type RankHistory struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	UserID     uint      `gorm:"index:idx_rank_history_user_time,priority:1;not null" json:"user_id"`
+	Rank       int64     `gorm:"not null" json:"rank"`
+	Rating     int       `gorm:"not null" json:"rating"`
+	CapturedAt time.Time `gorm:"index:idx_rank_history_user_time,priority:2;index:idx_rank_history_time;not null" json:"captured_at"`
+}
+
+func (RankHistory) TableName() string {
+	return "rank_history"
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Kinds of drift found by the reconciler
+const (
+	DriftRating        = "rating"          // on both sides with different ratings
+	DriftMissingRedis  = "missing_redis"   // belongs on a board but is on neither
+	DriftBannedOnBoard = "banned_on_board" // banned in PostgreSQL but still on a board
+	DriftOrphan        = "orphan"          // on a board but not (or no longer) in PostgreSQL
+)
+
+// ReconcileReport is the outcome of one reconciler run
+type ReconcileReport struct {
+	Node      string    `json:"node"`
+	Trigger   string    `json:"trigger"` // "schedule" or the admin who started it
+	Mode      string    `json:"mode"`    // sample or full
+	Source    string    `json:"source"`  // side rating drift was repaired from, or none
+	StartedAt time.Time `json:"started_at"`
+	Duration  string    `json:"duration"`
+	Checked   int       `json:"checked"` // users compared
+
+	// Users that still differed after the settle re-check, by kind, vs ones
+	// that changed in between (in-flight updates, not drift)
+	Mismatches map[string]int      `json:"mismatches"`
+	InFlight   int                 `json:"in_flight"`
+	Repaired   int                 `json:"repaired"`
+	Failed     int                 `json:"failed"`
+	Samples    []ReconcileMismatch `json:"samples,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// ReconcileMismatch is one drifted user (nil = missing on that side)
+type ReconcileMismatch struct {
+	UserID   uint   `json:"user_id"`
+	Kind     string `json:"kind"`
+	Redis    *int   `json:"redis"`
+	Postgres *int   `json:"postgres"`
+	Repaired bool   `json:"repaired"`
+}
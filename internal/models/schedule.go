@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ScheduledRun is one occurrence of a scheduled job, run by the server that
+// claimed it
+type ScheduledRun struct {
+	Job         string    `json:"job"`
+	Node        string    `json:"node"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	StartedAt   time.Time `json:"started_at"`
+	Duration    string    `json:"duration"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// ScheduledJob is a job registered with the scheduler
+type ScheduledJob struct {
+	Name     string        `json:"name"`
+	Schedule string        `json:"schedule"`
+	NextRun  time.Time     `json:"next_run"`
+	Running  bool          `json:"running"`  // on this server
+	LastRun  *ScheduledRun `json:"last_run"` // by any server; null before the first
+}
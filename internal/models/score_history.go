@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// ScoreUpdateDaily is a per-user daily rollup of compacted score_updates rows
+type ScoreUpdateDaily struct {
+	UserID      uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	Day         time.Time `gorm:"primaryKey;type:date" json:"day"`
+	OpenRating  int       `gorm:"not null" json:"open_rating"`
+	CloseRating int       `gorm:"not null" json:"close_rating"`
+	MinRating   int       `gorm:"not null" json:"min_rating"`
+	MaxRating   int       `gorm:"not null" json:"max_rating"`
+	UpdateCount int       `gorm:"not null" json:"update_count"`
+}
+
+func (ScoreUpdateDaily) TableName() string {
+	return "score_update_daily"
+}
+
+// HistoryEntry is one point in a user's stitched score history: either a
+// raw update or a compacted daily aggregate (Type "update" or "daily")
+type HistoryEntry struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// Raw update fields
+	OldRating int `json:"old_rating,omitempty"`
+	NewRating int `json:"new_rating,omitempty"`
+	Change    int `json:"change,omitempty"`
+
+	// Daily aggregate fields
+	OpenRating  int `json:"open_rating,omitempty"`
+	CloseRating int `json:"close_rating,omitempty"`
+	MinRating   int `json:"min_rating,omitempty"`
+	MaxRating   int `json:"max_rating,omitempty"`
+	UpdateCount int `json:"update_count,omitempty"`
+}
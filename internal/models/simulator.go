@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// SimulatorState is the simulator's desired state, shared by all servers
+type SimulatorState struct {
+	Running     bool      `json:"running"`
+	Interval    string    `json:"interval"`    // between ticks, e.g. "3s"
+	Concurrency int       `json:"concurrency"` // parallel workers
+	Burst       int       `json:"burst"`       // updates per tick (0 = one per worker)
+	Users       int       `json:"users"`       // random user pool (0 = any active user)
+	UpdatedBy   string    `json:"updated_by,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SimulatorStatus is the shared state plus what this server's simulator is doing
+type SimulatorStatus struct {
+	SimulatorState
+	Node      string     `json:"node"`
+	Active    bool       `json:"active"`    // simulating on this server
+	PoolSize  int        `json:"pool_size"` // users sampled for the pool
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Updates   int64      `json:"updates"`  // since started here
+	Failures  int64      `json:"failures"` // since started here
+	Skipped   int64      `json:"skipped"`  // ticks skipped while the last was still running
+	Rate      float64    `json:"updates_per_sec"`
+}
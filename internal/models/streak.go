@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Streak counts a user's consecutive days (in their timezone) with at least
+// one score update that raised their rating
+type Streak struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Current   int       `gorm:"not null;default:0" json:"current"`
+	Longest   int       `gorm:"not null;default:0" json:"longest"`
+	LastDay   string    `gorm:"size:10" json:"last_day,omitempty"` // YYYY-MM-DD of the last day counted
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Streak) TableName() string {
+	return "user_streaks"
+}
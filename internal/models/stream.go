@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// StreamGroupInfo describes a Redis stream consumer group and its consumers
+type StreamGroupInfo struct {
+	Stream          string               `json:"stream"`
+	Group           string               `json:"group"`
+	Length          int64                `json:"length"`
+	Pending         int64                `json:"pending"` // delivered, not yet acked
+	Lag             int64                `json:"lag"`     // not yet delivered (-1 if unknown)
+	LastDeliveredID string               `json:"last_delivered_id"`
+	Consumers       []StreamConsumerInfo `json:"consumers"`
+}
+
+// StreamConsumerInfo is one consumer of a group
+type StreamConsumerInfo struct {
+	Name    string `json:"name"`
+	Pending int64  `json:"pending"`
+	Idle    string `json:"idle"` // since its last read or claim
+	Self    bool   `json:"self"` // this server
+}
+
+// StreamDrainReport is the outcome of draining a stream worker on shutdown
+type StreamDrainReport struct {
+	Completed bool          `json:"completed"` // in-flight batch finished before the deadline
+	Duration  time.Duration `json:"duration"`
+	Pending   int64         `json:"pending"`   // delivered to this consumer, not acked (peers reclaim them)
+	Remaining int64         `json:"remaining"` // not yet synced by any consumer, including Pending
+}
+
+// SyncStatus shows how far PostgreSQL is behind Redis: what the DB sync
+// consumer group still has to write, and when it last wrote anything
+type SyncStatus struct {
+	Stream           string               `json:"stream"`
+	Length           int64                `json:"length"`   // entries in the stream, synced ones included until trimmed
+	Pending          int64                `json:"pending"`  // delivered to a consumer, not yet acked
+	Lag              int64                `json:"lag"`      // not yet delivered (-1 if unknown)
+	Unsynced         int64                `json:"unsynced"` // pending + lag
+	OldestPendingID  string               `json:"oldest_pending_id,omitempty"`
+	OldestPendingAge float64              `json:"oldest_pending_age_seconds"` // since it was queued
+	LastSyncAt       *time.Time           `json:"last_sync_at"`               // newest commit by any server
+	LastSyncAge      float64              `json:"last_sync_age_seconds"`
+	Consumers        []SyncConsumerStatus `json:"consumers"`
+}
+
+// SyncConsumerStatus is the last successful sync of one DB sync consumer
+type SyncConsumerStatus struct {
+	Name       string    `json:"name"`
+	LastSyncAt time.Time `json:"last_sync_at"`
+	Self       bool      `json:"self"` // this server
+}
+
+// DB sync states around a PostgreSQL outage
+const (
+	SyncStateHealthy    = "healthy"     // PostgreSQL up, backlog normal
+	SyncStateOutage     = "outage"      // PostgreSQL unreachable, events accumulate in the stream
+	SyncStateCatchingUp = "catching_up" // PostgreSQL back, draining the backlog
+)
+
+// Write backpressure levels, from the DB sync backlog
+const (
+	PressureNone = "none"
+	PressureSlow = "slow" // backlog above PG_BACKPRESSURE_BACKLOG: clients should slow down
+	PressureFull = "full" // backlog at PG_OUTAGE_MAX_BACKLOG: score writes are refused
+)
+
+// CatchUpStatus is this server's view of PostgreSQL availability and of the
+// DB sync backlog it has to drain
+type CatchUpStatus struct {
+	State          string     `json:"state"`
+	PostgresDown   bool       `json:"postgres_down"`
+	Backlog        int64      `json:"backlog"` // unsynced events (pending + lag)
+	MaxBacklog     int64      `json:"max_backlog"`
+	Pressure       string     `json:"pressure"`
+	BatchSize      int        `json:"batch_size"` // current DB sync batch size
+	OutageSince    *time.Time `json:"outage_since,omitempty"`
+	CatchUpSince   *time.Time `json:"catch_up_since,omitempty"`
+	BacklogAtStart int64      `json:"backlog_at_start,omitempty"` // when the catch-up began
+	Synced         int64      `json:"synced"`                     // events written by this server during the catch-up
+	Rate           float64    `json:"rate"`                       // events per second written by this server
+	ETASeconds     float64    `json:"eta_seconds"`                // at the current rate (-1 = unknown)
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Team is a named group of users ranked on the team leaderboard by the
+// aggregate of its members' ratings
+type Team struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"uniqueIndex:idx_team_name;size:50;not null" json:"name"`
+	Description string    `gorm:"size:500" json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Team) TableName() string {
+	return "teams"
+}
+
+// TeamMembership places a user in a team; a user is in at most one team
+type TeamMembership struct {
+	ID       uint      `gorm:"primaryKey" json:"id"`
+	TeamID   uint      `gorm:"index:idx_team_membership_team;not null" json:"team_id"`
+	Team     Team      `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE" json:"-"`
+	UserID   uint      `gorm:"uniqueIndex:idx_team_membership_user;not null" json:"user_id"`
+	User     User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	JoinedAt time.Time `gorm:"autoCreateTime" json:"joined_at"`
+}
+
+func (TeamMembership) TableName() string {
+	return "team_memberships"
+}
+
+// TeamEntry is a row of the team leaderboard
+type TeamEntry struct {
+	Rank    int64   `json:"rank"`
+	TeamID  uint    `json:"team_id"`
+	Name    string  `json:"name"`
+	Score   float64 `json:"score"` // sum or average of the ranked members' ratings
+	Members int64   `json:"members"`
+}
+
+// TeamMember is a member as listed on a team's profile
+type TeamMember struct {
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username"`
+	Rating   int       `json:"rating"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// TeamProfile is a team with its standing and members
+type TeamProfile struct {
+	Team
+	Score   float64      `json:"score"`
+	Rank    int64        `json:"rank,omitempty"` // 0 = not on the team board yet
+	Members []TeamMember `json:"members"`
+}
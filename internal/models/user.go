@@ -1,15 +1,25 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Moderation states of a user
+const (
+	UserStatusActive       = "active"
+	UserStatusBanned       = "banned"        // score frozen, hidden from everyone
+	UserStatusShadowBanned = "shadow_banned" // visible only to themselves
+)
+
 type User struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
 	Username  string         `gorm:"uniqueIndex:idx_username;size:50;not null" json:"username"`
 	Rating    int            `gorm:"index:idx_rating_desc,sort:desc;not null;default:1500" json:"rating"`
+	Status    string         `gorm:"size:16;not null;default:active" json:"status"`
+	Timezone  string         `gorm:"size:64;not null;default:'UTC'" json:"timezone"` // IANA name, used for daily/weekly boards
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -51,6 +61,10 @@ type ScoreUpdate struct {
 	NewRating int       `json:"new_rating"`
 	Change    int       `json:"change"`
 	UpdatedAt time.Time `gorm:"index:idx_update_time" json:"updated_at"`
+
+	// EventID of the DB sync event that wrote the row; unique so a
+	// redelivered event is applied once (NULL for rows predating it)
+	EventID *string `gorm:"size:32;uniqueIndex:idx_score_update_event" json:"-"`
 }
 
 func (ScoreUpdate) TableName() string {
@@ -63,14 +77,39 @@ type LeaderboardEntry struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
 	Rating   int    `json:"rating"`
+	Tier     string `json:"tier,omitempty"`
+	Division int    `json:"division,omitempty"` // 1 = highest division of the tier
+}
+
+// PeriodEntry is a row of a daily/weekly board, ranked by rating gained
+type PeriodEntry struct {
+	Rank     int64  `json:"rank"`
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Gain     int    `json:"gain"`
 }
 
 // SearchResult represents search result with global rank
 type SearchResult struct {
-	GlobalRank int64  `json:"global_rank"`
-	UserID     uint   `json:"user_id"`
-	Username   string `json:"username"`
-	Rating     int    `json:"rating"`
+	GlobalRank int64   `json:"global_rank"`
+	UserID     uint    `json:"user_id"`
+	Username   string  `json:"username"`
+	Rating     int     `json:"rating"`
+	Similarity float64 `json:"similarity"` // pg_trgm similarity of the username to the query, 0-1
+}
+
+// UserMatch is a user found by username search, with how similar their
+// username is to the query (pg_trgm, 0-1)
+type UserMatch struct {
+	User       `gorm:"embedded"`
+	Similarity float64
+}
+
+// Suggestion is a username autocomplete match
+type Suggestion struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Rating   int    `json:"rating"`
 }
 
 // ScoreUpdateRequest represents a score update request
@@ -98,8 +137,135 @@ type ScoreUpdatePayload struct {
 	Timestamp  int64  `json:"timestamp"`
 }
 
+// PubSubEvent is a typed event fanned out to every server over Redis Pub/Sub
+type PubSubEvent struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+
+	// ID of the HTTP request that caused the event, when there was one
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// LeaderboardRefreshPayload represents a leaderboard_refresh message
+type LeaderboardRefreshPayload struct {
+	Action string `json:"action"`
+}
+
+// WebSocket message types that are not bus events
+const (
+	MessageLeaderboardDiff  = "leaderboard_diff"   // *LeaderboardDiffPayload
+	MessageScoreUpdateBatch = "score_update_batch" // []*ScoreUpdatePayload, oldest first
+	MessageLagged           = "lagged"             // *LaggedPayload
+	MessageCommandResult    = "command_result"     // *CommandResultPayload
+	MessageCommandError     = "command_error"      // *CommandErrorPayload
+)
+
+// ClientCommand is a request a WebSocket client sends to the server
+type ClientCommand struct {
+	ID      string          `json:"id,omitempty"` // echoed in the reply
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// CommandResultPayload represents a command_result message: the answer to
+// a client command
+type CommandResultPayload struct {
+	ID      string      `json:"id,omitempty"`
+	Command string      `json:"command"`
+	Data    interface{} `json:"data"`
+}
+
+// CommandErrorPayload represents a command_error message: a client command
+// that failed
+type CommandErrorPayload struct {
+	ID      string `json:"id,omitempty"`
+	Command string `json:"command"`
+	Code    string `json:"code"`
+	Error   string `json:"error"`
+}
+
+// LaggedPayload represents a lagged message: the client fell behind and
+// its queued messages were discarded
+type LaggedPayload struct {
+	Dropped   int   `json:"dropped"` // messages discarded, including the one that did not fit
+	Timestamp int64 `json:"timestamp"`
+}
+
+// LeaderboardDiffPayload represents a leaderboard_diff message: the rows
+// of the top N that changed since the previous diff
+type LeaderboardDiffPayload struct {
+	Size      int                `json:"size"`              // N
+	Changed   []LeaderboardEntry `json:"changed"`           // rows new to the top N or with a new rank, rating or username
+	Removed   []uint             `json:"removed,omitempty"` // users who left the top N
+	Timestamp int64              `json:"timestamp"`
+}
+
+// AnnouncementPayload represents an announcement event: a message from an
+// admin to every client
+type AnnouncementPayload struct {
+	Message   string `json:"message"`
+	Level     string `json:"level"` // info | warning | critical
+	Timestamp int64  `json:"timestamp"`
+}
+
+// UserRenamedPayload represents a user_renamed event
+type UserRenamedPayload struct {
+	UserID      uint   `json:"user_id"`
+	OldUsername string `json:"old_username"`
+	NewUsername string `json:"new_username"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// UserRemovedPayload represents a user_removed event
+type UserRemovedPayload struct {
+	UserID    uint  `json:"user_id"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+// LeaderboardResetPayload represents a leaderboard_reset event
+type LeaderboardResetPayload struct {
+	Archive   string `json:"archive,omitempty"` // Redis key of the archived board
+	Rating    *int   `json:"rating,omitempty"`  // every rating was set to this, if given
+	Timestamp int64  `json:"timestamp"`
+}
+
+// MilestonePayload represents a milestone event: a score update that took
+// a user into a top N, past their personal best or to an N-day streak
+type MilestonePayload struct {
+	UserID       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	Kind         string `json:"kind"`                    // top_rank, personal_best or streak
+	Threshold    int64  `json:"threshold,omitempty"`     // top_rank: the N entered; streak: the days reached
+	Rank         int64  `json:"rank"`                    // rank after the update
+	Rating       int    `json:"rating"`                  // rating after the update
+	PreviousBest int    `json:"previous_best,omitempty"` // personal_best: the record beaten
+	Timestamp    int64  `json:"timestamp"`
+}
+
+// TierChangePayload represents a promotion or demotion event: a score
+// update that moved a user to another tier or division
+type TierChangePayload struct {
+	UserID      uint   `json:"user_id"`
+	Username    string `json:"username"`
+	OldTier     string `json:"old_tier"`
+	OldDivision int    `json:"old_division,omitempty"`
+	NewTier     string `json:"new_tier"`
+	NewDivision int    `json:"new_division,omitempty"`
+	Rank        int64  `json:"rank"`
+	Rating      int    `json:"rating"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// UserActivity is when a user last had a score update, for decay
+type UserActivity struct {
+	UserID     uint
+	LastActive time.Time
+}
+
 // DBSyncQueueItem represents an item in the async DB sync queue
 type DBSyncQueueItem struct {
+	EventID   string // unique per accepted update; set on enqueue
+	RequestID string // of the HTTP request behind the update, if any
 	UserID    uint
 	OldRating int
 	NewRating int
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Score events a webhook subscription can filter on
+const (
+	WebhookRankMilestone = "rank_milestone" // the user reached one of the subscription's ranks
+	WebhookTopEntry      = "top_entry"      // the user entered the top N
+	WebhookUserUpdate    = "user_update"    // any score update of a listed user
+)
+
+// Webhook delivery states
+const (
+	WebhookPending   = "pending" // waiting for its first attempt or a retry
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed" // gave up after the last attempt
+)
+
+// WebhookSubscription is an admin-registered endpoint receiving signed
+// POSTs for the score events it filters on
+type WebhookSubscription struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	URL        string    `gorm:"size:500;not null" json:"url"`
+	Secret     string    `gorm:"size:128;not null" json:"secret,omitempty"` // only returned when created
+	Events     []string  `gorm:"serializer:json;type:text;not null" json:"events"`
+	Milestones []int64   `gorm:"serializer:json;type:text" json:"milestones,omitempty"` // rank_milestone: ranks to report reaching
+	TopN       int64     `gorm:"not null;default:10" json:"top_n"`                      // top_entry: size of the top
+	UserIDs    []uint    `gorm:"serializer:json;type:text" json:"user_ids,omitempty"`   // only these users (required by user_update)
+	Active     bool      `gorm:"not null;default:true" json:"active"`
+	CreatedBy  string    `gorm:"size:100" json:"created_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// WebhookDelivery is one event queued for, or sent to, a subscription.
+// Deliveries double as the delivery log.
+type WebhookDelivery struct {
+	ID             uint                `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint                `gorm:"index:idx_webhook_delivery_sub;not null" json:"subscription_id"`
+	Subscription   WebhookSubscription `gorm:"foreignKey:SubscriptionID;constraint:OnDelete:CASCADE" json:"-"`
+	Event          string              `gorm:"size:32;not null" json:"event"`
+	UserID         uint                `gorm:"not null" json:"user_id"`
+	Milestone      int64               `json:"milestone,omitempty"`      // rank reached (rank_milestone, top_entry)
+	Payload        RawJSON             `gorm:"type:text" json:"payload"` // the *ScoreUpdatePayload
+	Status         string              `gorm:"size:16;not null;index:idx_webhook_delivery_due,priority:1" json:"status"`
+	Attempts       int                 `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time           `gorm:"index:idx_webhook_delivery_due,priority:2" json:"next_attempt_at"`
+	LastError      string              `gorm:"size:500" json:"last_error,omitempty"`
+	CreatedAt      time.Time           `gorm:"index:idx_webhook_delivery_time" json:"created_at"`
+	DeliveredAt    *time.Time          `json:"delivered_at,omitempty"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// InstanceConnections is one server's WebSocket client count as of its last
+// heartbeat
+type InstanceConnections struct {
+	Node      string    `json:"node"`
+	Clients   int       `json:"connected_clients"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ClusterConnections sums the WebSocket clients of every live server
+type ClusterConnections struct {
+	Clients   int                   `json:"connected_clients"`
+	Instances []InstanceConnections `json:"instances"` // by node
+}
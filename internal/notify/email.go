@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPConfig configures the SMTP email sender
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// EmailSender delivers messages over SMTP with a plain-text JSON body
+type EmailSender struct {
+	cfg SMTPConfig
+}
+
+func NewEmailSender(cfg SMTPConfig) *EmailSender {
+	return &EmailSender{cfg: cfg}
+}
+
+func (s *EmailSender) Channel() string {
+	return "email"
+}
+
+func (s *EmailSender) Send(ctx context.Context, msg Message) error {
+	body, err := json.MarshalIndent(msg.Payload, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&sb, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&sb, "Subject: %s\r\n", msg.Subject)
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	sb.Write(body)
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	return smtp.SendMail(addr, auth, s.cfg.From, []string{msg.To}, []byte(sb.String()))
+}
@@ -0,0 +1,36 @@
+// Package notify delivers outbound notifications (webhooks, email) on behalf
+// of the digest and other notification subsystems.
+package notify
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Message is a single outbound notification
+type Message struct {
+	Event   string      // e.g. "daily_digest"
+	To      string      // webhook URL or email address
+	Subject string      // used by email senders
+	Payload interface{} // JSON-encoded for webhooks, rendered for email
+}
+
+// Sender delivers messages over one channel
+type Sender interface {
+	Channel() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender just logs messages; used when no real channel is configured
+type LogSender struct {
+	ChannelName string
+}
+
+func (s *LogSender) Channel() string {
+	return s.ChannelName
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	slog.Info("📨 Notification logged", "channel", s.ChannelName, "event", msg.Event, "to", msg.To)
+	return nil
+}
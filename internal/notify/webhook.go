@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Leaderboard-Signature"
+	EventHeader     = "X-Leaderboard-Event"
+	TimestampHeader = "X-Leaderboard-Timestamp"
+)
+
+// WebhookSender POSTs JSON payloads, signed with HMAC-SHA256 when a secret is set
+type WebhookSender struct {
+	client *http.Client
+	secret func() string
+}
+
+// NewWebhookSender creates a webhook sender; secret is read per request so
+// rotated HMAC secrets apply immediately
+func NewWebhookSender(timeout time.Duration, secret func() string) *WebhookSender {
+	return &WebhookSender{
+		client: &http.Client{Timeout: timeout},
+		secret: secret,
+	}
+}
+
+// NewPublicWebhookSender creates a webhook sender for user-supplied URLs. It
+// only connects to public addresses, checked after DNS resolution, so a
+// hostname cannot be re-pointed at an internal service once validated.
+func NewPublicWebhookSender(timeout time.Duration, secret func() string) *WebhookSender {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%w: %s is not a public address", ErrUnsafeWebhookURL, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // a proxy would be dialled instead of the target
+	transport.DialContext = dialer.DialContext
+
+	return &WebhookSender{
+		client: &http.Client{Timeout: timeout, Transport: transport},
+		secret: secret,
+	}
+}
+
+// ErrUnsafeWebhookURL rejects user-supplied webhook URLs that could reach
+// internal services
+var ErrUnsafeWebhookURL = errors.New("webhook URL must be https and point at a public host")
+
+// ValidatePublicURL checks a user-supplied webhook URL: https, and not a
+// loopback, private or link-local address. Hostnames are checked again when
+// NewPublicWebhookSender's senders connect.
+func ValidatePublicURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return ErrUnsafeWebhookURL
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrUnsafeWebhookURL
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return ErrUnsafeWebhookURL
+	}
+	return nil
+}
+
+// publicIP reports whether ip is routable on the internet
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598)
+var sharedAddressSpace = &net.IPNet{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(10, 32)}
+
+func (s *WebhookSender) Channel() string {
+	return "webhook"
+}
+
+func (s *WebhookSender) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event":   msg.Event,
+		"payload": msg.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.Post(ctx, msg.To, msg.Event, body, s.secret())
+}
+
+// Post delivers a raw body to url with signature headers for the given secret
+func (s *WebhookSender) Post(ctx context.Context, url, event string, body []byte, secret string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(TimestampHeader, timestamp)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(secret, timestamp, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign computes hex(HMAC-SHA256(secret, timestamp + "." + body)); receivers
+// recompute it to verify authenticity and reject stale timestamps
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,62 @@
+// Package origins matches browser Origin headers against a configured
+// list, for CORS and WebSocket upgrades.
+package origins
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Matcher holds the allowed origins. Each entry is "*" (any origin), an
+// exact origin such as "https://example.com", or "https://*.example.com" for
+// any subdomain of example.com (at any depth, not example.com itself).
+type Matcher struct {
+	any      bool
+	exact    map[string]bool
+	suffixes []suffix // wildcard entries
+}
+
+// suffix is a wildcard entry: its scheme and the host suffix after "*"
+type suffix struct {
+	scheme string
+	host   string // ".example.com"
+}
+
+// New builds a Matcher from the configured entries
+func New(allowed []string) *Matcher {
+	m := &Matcher{exact: make(map[string]bool, len(allowed))}
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimRight(strings.TrimSpace(entry), "/"))
+		switch {
+		case entry == "*":
+			m.any = true
+		case strings.Contains(entry, "://*."):
+			scheme, host, _ := strings.Cut(entry, "://*")
+			m.suffixes = append(m.suffixes, suffix{scheme: scheme, host: host})
+		case entry != "":
+			m.exact[entry] = true
+		}
+	}
+	return m
+}
+
+// Allowed reports whether origin (scheme://host[:port]) matches an entry
+func (m *Matcher) Allowed(origin string) bool {
+	if m.any {
+		return true
+	}
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+	origin = u.Scheme + "://" + u.Host
+	if m.exact[origin] {
+		return true
+	}
+	for _, s := range m.suffixes {
+		if u.Scheme == s.scheme && strings.HasSuffix(u.Host, s.host) {
+			return true
+		}
+	}
+	return false
+}
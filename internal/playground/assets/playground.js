@@ -0,0 +1,143 @@
+// Builds request forms from /playground/openapi.json and tails the /ws feed.
+(function () {
+  const keyInput = document.getElementById("api-key");
+  keyInput.value = localStorage.getItem("leaderboard.apiKey") || "";
+  keyInput.addEventListener("change", () => localStorage.setItem("leaderboard.apiKey", keyInput.value));
+
+  const el = (tag, attrs = {}, ...children) => {
+    const node = document.createElement(tag);
+    Object.entries(attrs).forEach(([k, v]) => (k === "class" ? (node.className = v) : node.setAttribute(k, v)));
+    children.forEach((c) => node.append(c));
+    return node;
+  };
+
+  // ---- REST endpoints ----
+
+  async function loadSpec() {
+    const res = await fetch("openapi.json");
+    const spec = await res.json();
+    const base = (spec.servers && spec.servers[0].url) || "";
+    const container = document.getElementById("endpoints");
+    container.innerHTML = "";
+
+    const byTag = {};
+    Object.entries(spec.paths).forEach(([path, ops]) => {
+      Object.entries(ops).forEach(([method, op]) => {
+        const tag = (op.tags && op.tags[0]) || "other";
+        (byTag[tag] = byTag[tag] || []).push({ path, method, op });
+      });
+    });
+
+    Object.entries(byTag).forEach(([tag, ops]) => {
+      container.append(el("h2", {}, tag));
+      ops.forEach((entry) => container.append(renderOperation(base, entry)));
+    });
+  }
+
+  function renderOperation(base, { path, method, op }) {
+    const inputs = {};
+    const form = el("div", { class: "op" });
+
+    (op.parameters || []).forEach((p) => {
+      const input = el("input", { placeholder: p.description || p.in });
+      const example = p.example !== undefined ? p.example : p.schema && p.schema.default;
+      if (example !== undefined) input.value = example;
+      inputs[p.name] = { param: p, input };
+      form.append(el("label", {}, el("span", {}, p.name + (p.required ? "*" : "")), input));
+    });
+
+    let bodyInput = null;
+    if (op.requestBody) {
+      const media = op.requestBody.content["application/json"] || {};
+      bodyInput = el("textarea");
+      bodyInput.value = media.example ? JSON.stringify(media.example, null, 2) : "{}";
+      form.append(bodyInput);
+    }
+
+    const output = el("pre");
+    const button = el("button", {}, "Send");
+    button.addEventListener("click", async () => {
+      let url = base + path;
+      const query = new URLSearchParams();
+      Object.values(inputs).forEach(({ param, input }) => {
+        if (input.value === "") return;
+        if (param.in === "path") url = url.replace("{" + param.name + "}", encodeURIComponent(input.value));
+        if (param.in === "query") query.set(param.name, input.value);
+      });
+      if ([...query].length) url += "?" + query;
+
+      const headers = { "Content-Type": "application/json" };
+      if (keyInput.value) headers["X-API-Key"] = keyInput.value;
+
+      const started = performance.now();
+      output.textContent = "…";
+      try {
+        const res = await fetch(url, {
+          method: method.toUpperCase(),
+          headers,
+          body: bodyInput ? bodyInput.value : undefined,
+        });
+        const text = await res.text();
+        let pretty = text;
+        try { pretty = JSON.stringify(JSON.parse(text), null, 2); } catch (_) { /* not JSON */ }
+        const ms = Math.round(performance.now() - started);
+        output.className = res.ok ? "status-ok" : "status-err";
+        output.textContent = `${res.status} ${res.statusText} · ${ms} ms\n\n${pretty}`;
+      } catch (err) {
+        output.className = "status-err";
+        output.textContent = String(err);
+      }
+    });
+    form.append(button, output);
+
+    return el("details", {},
+      el("summary", {},
+        el("span", { class: "method " + method }, method.toUpperCase()),
+        el("span", { class: "path" }, path), " — " + (op.summary || "")),
+      form);
+  }
+
+  // ---- WebSocket feed ----
+
+  const log = document.getElementById("ws-log");
+  const status = document.getElementById("ws-status");
+  const toggle = document.getElementById("ws-toggle");
+  let socket = null;
+
+  function append(line) {
+    log.textContent = line + "\n" + log.textContent.slice(0, 50000);
+  }
+
+  toggle.addEventListener("click", () => {
+    if (socket) {
+      socket.close();
+      return;
+    }
+    const scheme = location.protocol === "https:" ? "wss:" : "ws:";
+    let url = `${scheme}//${location.host}/ws`;
+    if (keyInput.value) url += "?api_key=" + encodeURIComponent(keyInput.value);
+
+    socket = new WebSocket(url);
+    status.textContent = "connecting…";
+    socket.onopen = () => { status.textContent = "connected"; toggle.textContent = "Disconnect"; };
+    socket.onclose = () => { status.textContent = "disconnected"; toggle.textContent = "Connect"; socket = null; };
+    socket.onerror = () => append("⚠️ socket error");
+    socket.onmessage = (event) => {
+      const time = new Date().toLocaleTimeString();
+      event.data.split("\n").forEach((line) => {
+        try {
+          const msg = JSON.parse(line);
+          append(`[${time}] ${msg.type} ${JSON.stringify(msg.payload)}`);
+        } catch (_) {
+          append(`[${time}] ${line}`);
+        }
+      });
+    };
+  });
+
+  document.getElementById("ws-clear").addEventListener("click", () => (log.textContent = ""));
+
+  loadSpec().catch((err) => {
+    document.getElementById("endpoints").textContent = "Failed to load spec: " + err;
+  });
+})();
@@ -0,0 +1,25 @@
+// Package playground serves an interactive API explorer built from the
+// embedded OpenAPI spec, plus a live view of the WebSocket feed.
+package playground
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// Register mounts the playground at /playground (spec at /playground/openapi.json).
+// Keep assets/openapi.json in sync when adding or changing routes.
+func Register(router *gin.Engine) {
+	static, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err) // embedded at build time; cannot fail at runtime
+	}
+
+	router.StaticFS("/playground", http.FS(static))
+}
@@ -0,0 +1,248 @@
+// Package ranking turns submitted game results into new ratings. Strategies
+// are pluggable: each board picks one, so a new game can choose its rating
+// model without changes to the score update path.
+package ranking
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+)
+
+// Strategy names
+const (
+	StrategyAbsolute  = "absolute"
+	StrategyDelta     = "delta"
+	StrategyElo       = "elo"
+	StrategyGlicko    = "glicko"
+	StrategyTrueSkill = "trueskill"
+)
+
+// ErrInvalidResult is returned when a result lacks what the strategy needs
+var ErrInvalidResult = errors.New("invalid result")
+
+// Result is one submitted result. Which fields are required depends on the
+// strategy: Rating for absolute, Delta for delta, Score plus an opponent for
+// the match-based ones.
+type Result struct {
+	Rating *int     `json:"rating,omitempty"`
+	Delta  *int     `json:"delta,omitempty"`
+	Score  *float64 `json:"score,omitempty"` // 1 win, 0.5 draw, 0 loss
+
+	// Opponent, by user (their current rating is used) or by rating
+	OpponentID     *uint `json:"opponent_id,omitempty"`
+	OpponentRating *int  `json:"opponent_rating,omitempty"`
+}
+
+// Player is a rating and, for strategies that track it, its uncertainty
+// (Glicko rating deviation, TrueSkill sigma). Zero means no history yet.
+type Player struct {
+	Rating      float64
+	Uncertainty float64
+}
+
+// Strategy computes a player's new rating from a result. Only the
+// submitting player changes; opponents report their own results.
+type Strategy interface {
+	Name() string
+	// NeedsOpponent reports whether results are matches against an opponent
+	NeedsOpponent() bool
+	// Apply returns the player after the result; opponent is nil when the
+	// result names none
+	Apply(player Player, opponent *Player, result Result) (Player, error)
+}
+
+// New builds a strategy from its configuration
+func New(cfg config.RankingConfig) (Strategy, error) {
+	if cfg.Scale <= 0 {
+		cfg.Scale = 400
+	}
+	if cfg.GlickoRD <= 0 {
+		cfg.GlickoRD = 350
+	}
+	if cfg.TrueSkillSigma <= 0 {
+		cfg.TrueSkillSigma = 500
+	}
+	if cfg.TrueSkillBeta <= 0 {
+		cfg.TrueSkillBeta = cfg.TrueSkillSigma / 2
+	}
+
+	switch cfg.Strategy {
+	case StrategyAbsolute, "":
+		return &Absolute{}, nil
+	case StrategyDelta:
+		return &Delta{}, nil
+	case StrategyElo:
+		return &Elo{KFactor: cfg.KFactor, Scale: cfg.Scale}, nil
+	case StrategyGlicko:
+		return &Glicko{DefaultRD: cfg.GlickoRD, MinRD: cfg.GlickoMinRD}, nil
+	case StrategyTrueSkill:
+		return &TrueSkillLite{Sigma: cfg.TrueSkillSigma, Beta: cfg.TrueSkillBeta, Tau: cfg.TrueSkillTau}, nil
+	}
+	return nil, fmt.Errorf("unknown ranking strategy %q (use absolute, delta, elo, glicko or trueskill)", cfg.Strategy)
+}
+
+// ─── Absolute / delta ──────────────────────────────────────
+
+// Absolute sets the rating the result names (the classic PUT score)
+type Absolute struct{}
+
+func (s *Absolute) Name() string        { return StrategyAbsolute }
+func (s *Absolute) NeedsOpponent() bool { return false }
+
+func (s *Absolute) Apply(player Player, _ *Player, result Result) (Player, error) {
+	if result.Rating == nil {
+		return player, fmt.Errorf("%w: rating is required", ErrInvalidResult)
+	}
+	player.Rating = float64(*result.Rating)
+	return player, nil
+}
+
+// Delta adds the result's points to the current rating
+type Delta struct{}
+
+func (s *Delta) Name() string        { return StrategyDelta }
+func (s *Delta) NeedsOpponent() bool { return false }
+
+func (s *Delta) Apply(player Player, _ *Player, result Result) (Player, error) {
+	if result.Delta == nil {
+		return player, fmt.Errorf("%w: delta is required", ErrInvalidResult)
+	}
+	player.Rating += float64(*result.Delta)
+	return player, nil
+}
+
+// ─── Elo ───────────────────────────────────────────────────
+
+// Elo moves the rating by KFactor times the surprise of the result
+type Elo struct {
+	KFactor float64
+	Scale   float64
+}
+
+func (s *Elo) Name() string        { return StrategyElo }
+func (s *Elo) NeedsOpponent() bool { return true }
+
+func (s *Elo) Apply(player Player, opponent *Player, result Result) (Player, error) {
+	score, err := matchScore(result, true)
+	if err != nil {
+		return player, err
+	}
+	expected := 1 / (1 + math.Pow(10, (opponent.Rating-player.Rating)/s.Scale))
+	player.Rating += s.KFactor * (score - expected)
+	return player, nil
+}
+
+// ─── Glicko ────────────────────────────────────────────────
+
+// glickoQ is ln(10)/400, the Glicko scale constant
+var glickoQ = math.Ln10 / 400
+
+// Glicko is Glicko-1 with every result as its own rating period. The
+// rating deviation shrinks as a player plays, so new players move fast and
+// established ones slowly. Deviations do not grow back with inactivity.
+type Glicko struct {
+	DefaultRD float64
+	MinRD     float64
+}
+
+func (s *Glicko) Name() string        { return StrategyGlicko }
+func (s *Glicko) NeedsOpponent() bool { return true }
+
+func (s *Glicko) Apply(player Player, opponent *Player, result Result) (Player, error) {
+	score, err := matchScore(result, true)
+	if err != nil {
+		return player, err
+	}
+	rd := s.deviation(player.Uncertainty)
+	g := glickoG(s.deviation(opponent.Uncertainty))
+	expected := 1 / (1 + math.Pow(10, -g*(player.Rating-opponent.Rating)/400))
+
+	dSquared := 1 / (glickoQ * glickoQ * g * g * expected * (1 - expected))
+	precision := 1/(rd*rd) + 1/dSquared
+
+	player.Rating += glickoQ / precision * g * (score - expected)
+	player.Uncertainty = math.Max(math.Sqrt(1/precision), s.MinRD)
+	return player, nil
+}
+
+func (s *Glicko) deviation(rd float64) float64 {
+	if rd <= 0 {
+		return s.DefaultRD
+	}
+	return rd
+}
+
+func glickoG(rd float64) float64 {
+	return 1 / math.Sqrt(1+3*glickoQ*glickoQ*rd*rd/(math.Pi*math.Pi))
+}
+
+// ─── TrueSkill-lite ────────────────────────────────────────
+
+// TrueSkillLite is TrueSkill for one-on-one wins and losses (no draws, no
+// teams). The displayed rating is the skill mean; sigma is the uncertainty.
+type TrueSkillLite struct {
+	Sigma float64
+	Beta  float64
+	Tau   float64
+}
+
+func (s *TrueSkillLite) Name() string        { return StrategyTrueSkill }
+func (s *TrueSkillLite) NeedsOpponent() bool { return true }
+
+func (s *TrueSkillLite) Apply(player Player, opponent *Player, result Result) (Player, error) {
+	score, err := matchScore(result, false)
+	if err != nil {
+		return player, err
+	}
+
+	// Dynamics: a little uncertainty is added before every match
+	sigmaSq := sq(s.sigma(player.Uncertainty)) + sq(s.Tau)
+	opponentSq := sq(s.sigma(opponent.Uncertainty)) + sq(s.Tau)
+
+	c := math.Sqrt(2*sq(s.Beta) + sigmaSq + opponentSq)
+	sign := 1.0 // from the winner's side
+	if score == 0 {
+		sign = -1
+	}
+	t := sign * (player.Rating - opponent.Rating) / c
+	v := normPDF(t) / math.Max(normCDF(t), 1e-12)
+	w := v * (v + t)
+
+	player.Rating += sign * sigmaSq / c * v
+	player.Uncertainty = math.Sqrt(sigmaSq * math.Max(1-sigmaSq/(c*c)*w, 1e-4))
+	return player, nil
+}
+
+func (s *TrueSkillLite) sigma(sigma float64) float64 {
+	if sigma <= 0 {
+		return s.Sigma
+	}
+	return sigma
+}
+
+// matchScore validates a match result's score and opponent
+func matchScore(result Result, draws bool) (float64, error) {
+	if result.Score == nil {
+		return 0, fmt.Errorf("%w: score is required (1 win, 0.5 draw, 0 loss)", ErrInvalidResult)
+	}
+	score := *result.Score
+	if score != 0 && score != 1 && !(draws && score == 0.5) {
+		if draws {
+			return 0, fmt.Errorf("%w: score must be 1, 0.5 or 0", ErrInvalidResult)
+		}
+		return 0, fmt.Errorf("%w: score must be 1 or 0 (draws are not supported)", ErrInvalidResult)
+	}
+	if result.OpponentID == nil && result.OpponentRating == nil {
+		return 0, fmt.Errorf("%w: opponent_id or opponent_rating is required", ErrInvalidResult)
+	}
+	return score, nil
+}
+
+func sq(x float64) float64 { return x * x }
+
+func normPDF(x float64) float64 { return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi) }
+
+func normCDF(x float64) float64 { return math.Erfc(-x/math.Sqrt2) / 2 }
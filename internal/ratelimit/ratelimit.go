@@ -0,0 +1,121 @@
+// Package ratelimit enforces per-caller request quotas. Requests are counted
+// in fixed windows in Redis, so the quota is shared by every server.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/auth"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+var rateLimited = metrics.NewCounterVec("ratelimit_rejected_total",
+	"Requests rejected for exceeding the caller's rate limit", "tier")
+
+// Result is the caller's quota state after (or without) a request
+type Result struct {
+	Limit     int       // requests per window; 0 = unlimited
+	Remaining int       // requests left in the current window
+	Reset     time.Time // end of the current window
+	Allowed   bool
+}
+
+// RetryAfter is how long a rejected caller should wait
+func (r Result) RetryAfter() time.Duration {
+	if wait := time.Until(r.Reset); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// Limiter counts requests per caller key against its tier's limit
+type Limiter struct {
+	redis  *redis.Client
+	window time.Duration
+	limits map[string]int // tier -> requests per window (0 = unlimited)
+}
+
+// New creates a limiter; tiers missing from limits get the free tier's limit
+func New(redisClient *redis.Client, window time.Duration, limits map[string]int) *Limiter {
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &Limiter{
+		redis:  redisClient,
+		window: window,
+		limits: limits,
+	}
+}
+
+// Window is the length of a counting window
+func (l *Limiter) Window() time.Duration {
+	return l.window
+}
+
+// Limit returns the requests per window of a tier (0 = unlimited)
+func (l *Limiter) Limit(tier string) int {
+	if limit, ok := l.limits[tier]; ok {
+		return limit
+	}
+	return l.limits[auth.AnonymousTier]
+}
+
+// Allow counts one request for key and reports whether it is within quota
+func (l *Limiter) Allow(ctx context.Context, key, tier string) (Result, error) {
+	result, counterKey := l.current(key, tier)
+	if result.Limit <= 0 {
+		return result, nil
+	}
+
+	pipe := l.redis.TxPipeline()
+	count := pipe.Incr(ctx, counterKey)
+	pipe.Expire(ctx, counterKey, l.window+time.Second)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return result, err
+	}
+
+	result.fill(int(count.Val()))
+	if !result.Allowed {
+		rateLimited.WithLabelValues(tier).Inc()
+	}
+	return result, nil
+}
+
+// Peek reports the quota state of key without counting a request
+func (l *Limiter) Peek(ctx context.Context, key, tier string) (Result, error) {
+	result, counterKey := l.current(key, tier)
+	if result.Limit <= 0 {
+		return result, nil
+	}
+
+	used, err := l.redis.Get(ctx, counterKey).Int()
+	if err != nil && err != redis.Nil {
+		return result, err
+	}
+	result.fill(used)
+	result.Allowed = used < result.Limit
+	return result, nil
+}
+
+// current starts a result for the current window and names its counter
+func (l *Limiter) current(key, tier string) (Result, string) {
+	start := time.Now().Truncate(l.window)
+	result := Result{
+		Limit:   l.Limit(tier),
+		Reset:   start.Add(l.window),
+		Allowed: true,
+	}
+	return result, fmt.Sprintf(database.RequestRateKey, key, start.Unix())
+}
+
+func (r *Result) fill(used int) {
+	r.Remaining = r.Limit - used
+	if r.Remaining < 0 {
+		r.Remaining = 0
+	}
+	r.Allowed = used <= r.Limit
+}
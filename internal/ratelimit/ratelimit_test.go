@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestLimiter(t *testing.T, window time.Duration, limits map[string]int) (*Limiter, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return New(client, window, limits), mr
+}
+
+func TestLimit(t *testing.T) {
+	l := New(nil, time.Minute, map[string]int{"free": 60, "pro": 600, "internal": 0})
+
+	for _, tc := range []struct {
+		tier string
+		want int
+	}{
+		{"free", 60},
+		{"pro", 600},
+		{"internal", 0},    // unlimited
+		{"enterprise", 60}, // unknown tiers get the free tier's limit
+	} {
+		if got := l.Limit(tc.tier); got != tc.want {
+			t.Errorf("Limit(%q) = %d, want %d", tc.tier, got, tc.want)
+		}
+	}
+}
+
+func TestNewDefaultsWindow(t *testing.T) {
+	if got := New(nil, 0, nil).Window(); got != time.Minute {
+		t.Errorf("Window() = %s, want 1m", got)
+	}
+}
+
+func TestResultFill(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		limit, used   int
+		wantRemaining int
+		wantAllowed   bool
+	}{
+		{"first request", 3, 1, 2, true},
+		{"last request", 3, 3, 0, true},
+		{"over quota", 3, 4, 0, false},
+		{"far over quota", 3, 10, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Result{Limit: tc.limit}
+			r.fill(tc.used)
+			if r.Remaining != tc.wantRemaining || r.Allowed != tc.wantAllowed {
+				t.Errorf("fill(%d) = remaining %d allowed %v, want %d %v",
+					tc.used, r.Remaining, r.Allowed, tc.wantRemaining, tc.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		reset time.Time
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"window ahead", time.Now().Add(30 * time.Second), 29 * time.Second, 30 * time.Second},
+		{"window over", time.Now().Add(-time.Second), 0, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Result{Reset: tc.reset}.RetryAfter()
+			if got < tc.min || got > tc.max {
+				t.Errorf("RetryAfter() = %s, want between %s and %s", got, tc.min, tc.max)
+			}
+		})
+	}
+}
+
+func TestAllow(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(t, time.Hour, map[string]int{"free": 3, "internal": 0})
+
+	// The fourth request of a window is refused, and keys count apart
+	for i, want := range []bool{true, true, true, false, false} {
+		result, err := l.Allow(ctx, "key-a", "free")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != want {
+			t.Errorf("request %d allowed = %v, want %v", i+1, result.Allowed, want)
+		}
+	}
+	if result, err := l.Allow(ctx, "key-b", "free"); err != nil || !result.Allowed || result.Remaining != 2 {
+		t.Errorf("other key = %+v, %v; want allowed with 2 remaining", result, err)
+	}
+
+	// Unlimited tiers are never counted
+	for range 10 {
+		result, err := l.Allow(ctx, "key-c", "internal")
+		if err != nil || !result.Allowed || result.Limit != 0 {
+			t.Fatalf("unlimited tier = %+v, %v", result, err)
+		}
+	}
+}
+
+func TestPeekDoesNotCount(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(t, time.Hour, map[string]int{"free": 2})
+
+	for range 3 {
+		result, err := l.Peek(ctx, "key", "free")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || result.Remaining != 2 {
+			t.Fatalf("Peek before any request = %+v, want allowed with 2 remaining", result)
+		}
+	}
+
+	l.Allow(ctx, "key", "free")
+	l.Allow(ctx, "key", "free")
+	result, err := l.Peek(ctx, "key", "free")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.Remaining != 0 {
+		t.Errorf("Peek at quota = %+v, want refused with 0 remaining", result)
+	}
+}
+
+func TestCounterExpires(t *testing.T) {
+	ctx := context.Background()
+	l, mr := newTestLimiter(t, time.Minute, map[string]int{"free": 1})
+
+	if _, err := l.Allow(ctx, "key", "free"); err != nil {
+		t.Fatal(err)
+	}
+	keys := mr.Keys()
+	if len(keys) != 1 {
+		t.Fatalf("keys = %v, want one counter", keys)
+	}
+	if ttl := mr.TTL(keys[0]); ttl <= time.Minute || ttl > time.Minute+time.Second {
+		t.Errorf("counter TTL = %s, want just over the window", ttl)
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/redis/go-redis/v9"
+)
+
+// AchievementProgressRepository keeps what achievement checks need on the
+// hot path in Redis: each user's win streak and the codes they unlocked
+type AchievementProgressRepository interface {
+	RecordResult(userID uint, delta int) (int64, error)
+	MarkUnlocked(userID uint, code string) (bool, error)
+	UnmarkUnlocked(userID uint, code string) error
+	Forget(userID uint) error
+}
+
+type achievementProgressRepository struct {
+	redis *redis.Client
+	ctx   context.Context
+}
+
+func NewAchievementProgressRepository(redisClient *redis.Client) AchievementProgressRepository {
+	return &achievementProgressRepository{
+		redis: redisClient,
+		ctx:   database.Ctx,
+	}
+}
+
+// RecordResult extends the user's win streak on a rating gain and ends it
+// on a loss; it returns the streak after the update
+func (r *achievementProgressRepository) RecordResult(userID uint, delta int) (int64, error) {
+	field := strconv.FormatUint(uint64(userID), 10)
+	switch {
+	case delta > 0:
+		return r.redis.HIncrBy(r.ctx, database.WinStreakKey, field, 1).Result()
+	case delta < 0:
+		return 0, r.redis.HDel(r.ctx, database.WinStreakKey, field).Err()
+	}
+	streak, err := r.redis.HGet(r.ctx, database.WinStreakKey, field).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return streak, err
+}
+
+// MarkUnlocked adds code to the user's unlocked set; false if it was there
+func (r *achievementProgressRepository) MarkUnlocked(userID uint, code string) (bool, error) {
+	added, err := r.redis.SAdd(r.ctx, fmt.Sprintf(database.AchievementsKey, userID), code).Result()
+	return added > 0, err
+}
+
+// UnmarkUnlocked undoes MarkUnlocked when the unlock could not be stored
+func (r *achievementProgressRepository) UnmarkUnlocked(userID uint, code string) error {
+	return r.redis.SRem(r.ctx, fmt.Sprintf(database.AchievementsKey, userID), code).Err()
+}
+
+// Forget drops a removed user's streak and unlocked set
+func (r *achievementProgressRepository) Forget(userID uint) error {
+	pipe := r.redis.TxPipeline()
+	pipe.HDel(r.ctx, database.WinStreakKey, strconv.FormatUint(uint64(userID), 10))
+	pipe.Del(r.ctx, fmt.Sprintf(database.AchievementsKey, userID))
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
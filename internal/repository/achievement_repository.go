@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AchievementRepository stores unlocked achievements
+type AchievementRepository interface {
+	Create(achievement *models.Achievement) (bool, error)
+	ListByUser(userID uint) ([]models.Achievement, error)
+}
+
+type achievementRepository struct {
+	db *gorm.DB
+}
+
+func NewAchievementRepository(db *gorm.DB) AchievementRepository {
+	return &achievementRepository{db: db}
+}
+
+// Create records an unlock; false when the user already had it
+func (r *achievementRepository) Create(achievement *models.Achievement) (bool, error) {
+	result := r.db.Omit("User").Clauses(clause.OnConflict{DoNothing: true}).Create(achievement)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListByUser returns a user's achievements, oldest first
+func (r *achievementRepository) ListByUser(userID uint) ([]models.Achievement, error) {
+	var achievements []models.Achievement
+	err := replica(r.db).Where("user_id = ?", userID).Order("unlocked_at ASC, id ASC").Find(&achievements).Error
+	return achievements, err
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AnomalyFilter narrows a flag listing; zero values are ignored
+type AnomalyFilter struct {
+	UserID   uint
+	Status   string
+	BeforeID uint // keyset pagination: only flags with a smaller ID
+	Limit    int
+}
+
+// AnomalyRepository stores anti-cheat flags and their review outcome
+type AnomalyRepository interface {
+	CreateBatch(flags []models.AnomalyFlag) error
+	List(filter AnomalyFilter) ([]models.AnomalyFlag, error)
+	Review(id uint, status, reviewer, note string) (*models.AnomalyFlag, error)
+}
+
+type anomalyRepository struct {
+	db *gorm.DB
+}
+
+func NewAnomalyRepository(db *gorm.DB) AnomalyRepository {
+	return &anomalyRepository{db: db}
+}
+
+func (r *anomalyRepository) CreateBatch(flags []models.AnomalyFlag) error {
+	if len(flags) == 0 {
+		return nil
+	}
+	return r.db.Create(&flags).Error
+}
+
+// List returns flags newest first
+func (r *anomalyRepository) List(filter AnomalyFilter) ([]models.AnomalyFlag, error) {
+	query := r.db.Model(&models.AnomalyFlag{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var flags []models.AnomalyFlag
+	err := query.Order("id DESC").
+		Limit(filter.Limit).
+		Find(&flags).Error
+	return flags, err
+}
+
+// Review records an admin decision on a flag
+func (r *anomalyRepository) Review(id uint, status, reviewer, note string) (*models.AnomalyFlag, error) {
+	var flag models.AnomalyFlag
+	if err := r.db.First(&flag, id).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	flag.Status = status
+	flag.ReviewedBy = reviewer
+	flag.ReviewNote = note
+	flag.ReviewedAt = &now
+
+	err := r.db.Model(&flag).Updates(map[string]interface{}{
+		"status":      status,
+		"reviewed_by": reviewer,
+		"review_note": note,
+		"reviewed_at": now,
+	}).Error
+	return &flag, err
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditFilter narrows an adjustment listing; zero values are ignored
+type AuditFilter struct {
+	UserID   uint
+	Actor    string
+	BeforeID uint // keyset pagination: only entries with a smaller ID
+	Limit    int
+}
+
+// AuditRepository stores the manual score adjustment audit trail
+type AuditRepository interface {
+	CreateBatch(entries []models.AdminAdjustment) error
+	List(filter AuditFilter) ([]models.AdminAdjustment, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) CreateBatch(entries []models.AdminAdjustment) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(entries, 500).Error
+}
+
+// List returns adjustments newest first
+func (r *auditRepository) List(filter AuditFilter) ([]models.AdminAdjustment, error) {
+	query := r.db.Model(&models.AdminAdjustment{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var entries []models.AdminAdjustment
+	err := query.Order("id DESC").
+		Limit(filter.Limit).
+		Find(&entries).Error
+	return entries, err
+}
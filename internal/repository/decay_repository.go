@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Lowers one inactive user's rating by ARGV[3], never below ARGV[4], on
+// whichever board holds them, keeps the user cache in step and appends the
+// change to the DB sync stream. Doing all of it in one step keeps the stream
+// in the order Redis saw the changes. A user with an update in flight (lock
+// held) or since the run picked them (activity after ARGV[2]) is left alone.
+// Returns {old, new}, or nil when nothing changed.
+var decayScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[5]) == 1 then
+	return false
+end
+local last = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if last and tonumber(last) > tonumber(ARGV[2]) then
+	return false
+end
+local board = KEYS[2]
+local score = redis.call("ZSCORE", board, ARGV[1])
+if not score then
+	board = KEYS[3]
+	score = redis.call("ZSCORE", board, ARGV[1])
+	if not score then
+		return false
+	end
+end
+score = tonumber(score)
+local target = math.max(tonumber(ARGV[4]), score - tonumber(ARGV[3]))
+if target >= score then
+	return false
+end
+redis.call("ZINCRBY", board, target - score, ARGV[1])
+if redis.call("EXISTS", KEYS[4]) == 1 then
+	redis.call("HSET", KEYS[4], "rating", target)
+end
+redis.call("XADD", KEYS[6], "*", "data", cjson.encode({
+	EventID = ARGV[5],
+	UserID = tonumber(ARGV[6]),
+	OldRating = score,
+	NewRating = target,
+	Timestamp = ARGV[7],
+}))
+return {tostring(score), tostring(target)}
+`)
+
+// DecayTarget is a user to decay, the rating they may not fall below and
+// the DB sync event ID of the change
+type DecayTarget struct {
+	UserID  uint
+	Floor   int
+	EventID string
+}
+
+// DecayResult is a rating a decay batch lowered
+type DecayResult struct {
+	UserID    uint
+	OldRating int
+	NewRating int
+}
+
+// DecayRepository tracks when users last had a score update and lowers the
+// ratings of inactive ones in Redis
+type DecayRepository interface {
+	Touch(userID uint, at time.Time) error
+	Forget(userID uint) error
+	CountActivity() (int64, error)
+	Backfill(activity []models.UserActivity) error
+	GetInactive(before time.Time, offset, limit int) ([]uint, error)
+	Apply(stream string, targets []DecayTarget, amount int, before time.Time) ([]DecayResult, error)
+}
+
+type decayRepository struct {
+	redis *redis.Client
+	ctx   context.Context
+}
+
+func NewDecayRepository(redisClient *redis.Client) DecayRepository {
+	return &decayRepository{
+		redis: redisClient,
+		ctx:   database.Ctx,
+	}
+}
+
+// Touch records a score update of a user at at
+func (r *decayRepository) Touch(userID uint, at time.Time) error {
+	return r.redis.ZAdd(r.ctx, database.ActivityKey, redis.Z{
+		Score:  float64(at.Unix()),
+		Member: fmt.Sprintf("user:%d", userID),
+	}).Err()
+}
+
+// Forget drops a removed user's activity
+func (r *decayRepository) Forget(userID uint) error {
+	return r.redis.ZRem(r.ctx, database.ActivityKey, fmt.Sprintf("user:%d", userID)).Err()
+}
+
+func (r *decayRepository) CountActivity() (int64, error) {
+	return r.redis.ZCard(r.ctx, database.ActivityKey).Result()
+}
+
+// Backfill adds activity read from PostgreSQL, keeping any newer entry an
+// update has written meanwhile
+func (r *decayRepository) Backfill(activity []models.UserActivity) error {
+	if len(activity) == 0 {
+		return nil
+	}
+	members := make([]redis.Z, len(activity))
+	for i, a := range activity {
+		members[i] = redis.Z{
+			Score:  float64(a.LastActive.Unix()),
+			Member: fmt.Sprintf("user:%d", a.UserID),
+		}
+	}
+	return r.redis.ZAddNX(r.ctx, database.ActivityKey, members...).Err()
+}
+
+// GetInactive pages through the users whose last update was before before,
+// least recently active first
+func (r *decayRepository) GetInactive(before time.Time, offset, limit int) ([]uint, error) {
+	members, err := r.redis.ZRangeByScore(r.ctx, database.ActivityKey, &redis.ZRangeBy{
+		Min:    "-inf",
+		Max:    strconv.FormatInt(before.Unix(), 10),
+		Offset: int64(offset),
+		Count:  int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		userID, err := strconv.ParseUint(strings.TrimPrefix(member, "user:"), 10, 32)
+		if err != nil {
+			continue
+		}
+		userIDs = append(userIDs, uint(userID))
+	}
+	return userIDs, nil
+}
+
+// Apply decays a batch of users in one pipeline, queueing each change on
+// the DB sync stream, and returns the ratings it lowered
+func (r *decayRepository) Apply(stream string, targets []DecayTarget, amount int, before time.Time) ([]DecayResult, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().Format(time.RFC3339Nano)
+	pipe := r.redis.Pipeline()
+	cmds := make([]*redis.Cmd, len(targets))
+	for i, target := range targets {
+		keys := []string{
+			database.ActivityKey,
+			database.LeaderboardKey,
+			database.ShadowBoardKey,
+			fmt.Sprintf(database.UserCacheKey, target.UserID),
+			fmt.Sprintf(database.UserLockKey, target.UserID),
+			stream,
+		}
+		cmds[i] = decayScript.Eval(r.ctx, pipe, keys,
+			fmt.Sprintf("user:%d", target.UserID), before.Unix(), amount, target.Floor,
+			target.EventID, target.UserID, now)
+	}
+	if _, err := pipe.Exec(r.ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	var results []DecayResult
+	for i, cmd := range cmds {
+		ratings, err := cmd.StringSlice()
+		if err != nil || len(ratings) != 2 {
+			continue
+		}
+		oldRating, _ := strconv.ParseFloat(ratings[0], 64)
+		newRating, _ := strconv.ParseFloat(ratings[1], 64)
+		results = append(results, DecayResult{
+			UserID:    targets[i].UserID,
+			OldRating: int(oldRating),
+			NewRating: int(newRating),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// deferredReplayLock is the advisory lock key held while replaying, so
+// servers never replay (and reorder) the same user's updates concurrently
+const deferredReplayLock = 0x6c62_7270 // "lbrp"
+
+// DeferredRepository queues score updates accepted while Redis was down
+type DeferredRepository interface {
+	Create(update *models.DeferredScoreUpdate) error
+	Count() (int64, error)
+	Replay(limit int, apply func(updates []models.DeferredScoreUpdate) []uint) (int, error)
+}
+
+type deferredRepository struct {
+	db *gorm.DB
+}
+
+func NewDeferredRepository(db *gorm.DB) DeferredRepository {
+	return &deferredRepository{db: db}
+}
+
+func (r *deferredRepository) Create(update *models.DeferredScoreUpdate) error {
+	return r.db.Create(update).Error
+}
+
+func (r *deferredRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.DeferredScoreUpdate{}).Count(&count).Error
+	return count, err
+}
+
+// Replay hands the oldest queued updates to apply, in order, and deletes
+// the IDs it returns as done. It returns how many were deleted; 0 when
+// another server holds the replay lock.
+func (r *deferredRepository) Replay(limit int, apply func(updates []models.DeferredScoreUpdate) []uint) (int, error) {
+	done := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", deferredReplayLock).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		var updates []models.DeferredScoreUpdate
+		if err := tx.Order("id ASC").Limit(limit).Find(&updates).Error; err != nil {
+			return err
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+
+		ids := apply(updates)
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Delete(&models.DeferredScoreUpdate{}, ids).Error; err != nil {
+			return err
+		}
+		done = len(ids)
+		return nil
+	})
+	return done, err
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DigestRepository stores daily digest subscriptions
+type DigestRepository interface {
+	Upsert(sub *models.DigestSubscription) error
+	GetByUserID(userID uint) (*models.DigestSubscription, error)
+	GetTimezones() ([]string, error)
+	ClaimDue(timezone string, localDate time.Time, limit int) ([]models.DigestSubscription, error)
+}
+
+type digestRepository struct {
+	db *gorm.DB
+}
+
+func NewDigestRepository(db *gorm.DB) DigestRepository {
+	return &digestRepository{db: db}
+}
+
+func (r *digestRepository) Upsert(sub *models.DigestSubscription) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"opted_in", "timezone", "webhook_url", "email", "updated_at"}),
+	}).Create(sub).Error
+}
+
+func (r *digestRepository) GetByUserID(userID uint) (*models.DigestSubscription, error) {
+	var sub models.DigestSubscription
+	err := r.db.First(&sub, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// GetTimezones returns the distinct timezones of opted-in subscribers
+func (r *digestRepository) GetTimezones() ([]string, error) {
+	var timezones []string
+	err := r.db.Model(&models.DigestSubscription{}).
+		Where("opted_in = ?", true).
+		Distinct("timezone").
+		Pluck("timezone", &timezones).Error
+	return timezones, err
+}
+
+// ClaimDue returns subscribers in a timezone not yet sent a digest for
+// localDate and marks them sent in the same transaction, so servers running
+// the digest at once each get different subscribers. A digest claimed by a
+// server that dies before delivering it is skipped for the day.
+func (r *digestRepository) ClaimDue(timezone string, localDate time.Time, limit int) ([]models.DigestSubscription, error) {
+	day := localDate.Format("2006-01-02")
+
+	var subs []models.DigestSubscription
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("opted_in = ? AND timezone = ?", true, timezone).
+			Where("last_sent_on IS NULL OR last_sent_on < ?", day).
+			Order("user_id").
+			Limit(limit).
+			Find(&subs).Error
+		if err != nil || len(subs) == 0 {
+			return err
+		}
+
+		userIDs := make([]uint, len(subs))
+		for i := range subs {
+			userIDs[i] = subs[i].UserID
+		}
+		return tx.Model(&models.DigestSubscription{}).
+			Where("user_id IN ?", userIDs).
+			Update("last_sent_on", day).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
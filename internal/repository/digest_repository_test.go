@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newMockDB returns a PostgreSQL-dialect gorm DB backed by sqlmock
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
+
+// TestDigestClaimDue checks that due rows are locked, skipping ones another
+// server holds, and marked sent before the transaction commits
+func TestDigestClaimDue(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewDigestRepository(db)
+	day := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "digest_subscriptions" WHERE .*timezone = .*ORDER BY user_id LIMIT \$\d+ FOR UPDATE SKIP LOCKED`).
+		WithArgs(true, "Europe/Paris", "2024-03-10", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "timezone", "opted_in"}).
+			AddRow(3, "Europe/Paris", true).
+			AddRow(8, "Europe/Paris", true))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "digest_subscriptions" SET "last_sent_on"=$1`)).
+		WithArgs("2024-03-10", sqlmock.AnyArg(), 3, 8).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	subs, err := repo.ClaimDue("Europe/Paris", day, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 || subs[0].UserID != 3 || subs[1].UserID != 8 {
+		t.Errorf("claimed %+v, want users 3 and 8", subs)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDigestClaimDueNoneDue(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewDigestRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectCommit()
+
+	subs, err := repo.ClaimDue("UTC", time.Now(), 50)
+	if err != nil || len(subs) != 0 {
+		t.Errorf("ClaimDue = %v, %v; want none", subs, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
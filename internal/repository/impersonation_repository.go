@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ImpersonationFilter narrows an impersonation event listing; zero values are ignored
+type ImpersonationFilter struct {
+	UserID    uint
+	Admin     string
+	SessionID string
+	BeforeID  uint // keyset pagination: only events with a smaller ID
+	Limit     int
+}
+
+// ImpersonationRepository stores the impersonation audit trail
+type ImpersonationRepository interface {
+	Create(event *models.ImpersonationEvent) error
+	List(filter ImpersonationFilter) ([]models.ImpersonationEvent, error)
+}
+
+type impersonationRepository struct {
+	db *gorm.DB
+}
+
+func NewImpersonationRepository(db *gorm.DB) ImpersonationRepository {
+	return &impersonationRepository{db: db}
+}
+
+func (r *impersonationRepository) Create(event *models.ImpersonationEvent) error {
+	return r.db.Create(event).Error
+}
+
+// List returns events newest first
+func (r *impersonationRepository) List(filter ImpersonationFilter) ([]models.ImpersonationEvent, error) {
+	query := r.db.Model(&models.ImpersonationEvent{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Admin != "" {
+		query = query.Where("admin = ?", filter.Admin)
+	}
+	if filter.SessionID != "" {
+		query = query.Where("session_id = ?", filter.SessionID)
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var events []models.ImpersonationEvent
+	err := query.Order("id DESC").
+		Limit(filter.Limit).
+		Find(&events).Error
+	return events, err
+}
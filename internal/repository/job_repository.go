@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// JobFilter narrows a job listing; zero values are ignored
+type JobFilter struct {
+	Type   string
+	Status string
+	Limit  int
+}
+
+// JobRepository persists admin jobs so status and cancellation work from
+// any server
+type JobRepository interface {
+	Create(job *models.Job) error
+	GetByID(id string) (*models.Job, error)
+	List(filter JobFilter) ([]models.Job, error)
+	MarkRunning(id string) error
+	UpdateProgress(id string, processed, total int64) (cancelRequested bool, err error)
+	Finish(id, status string, result models.RawJSON, errMsg string) error
+	RequestCancel(id string) error
+	FailOrphaned(node string) (int64, error)
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(job *models.Job) error {
+	return r.db.Create(job).Error
+}
+
+func (r *jobRepository) GetByID(id string) (*models.Job, error) {
+	var job models.Job
+	err := r.db.First(&job, "id = ?", id).Error
+	return &job, err
+}
+
+// List returns jobs newest first (without their potentially large results)
+func (r *jobRepository) List(filter JobFilter) ([]models.Job, error) {
+	query := r.db.Model(&models.Job{}).Omit("result")
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var jobs []models.Job
+	err := query.Order("created_at DESC").
+		Limit(filter.Limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *jobRepository) MarkRunning(id string) error {
+	now := time.Now()
+	return r.db.Model(&models.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"status": models.JobRunning, "started_at": now}).Error
+}
+
+// UpdateProgress stores progress and reports whether cancellation was requested
+func (r *jobRepository) UpdateProgress(id string, processed, total int64) (bool, error) {
+	var job models.Job
+	err := r.db.Model(&job).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"processed": processed, "total": total}).Error
+	if err != nil {
+		return false, err
+	}
+	err = r.db.Select("cancel_requested").First(&job, "id = ?", id).Error
+	return job.CancelRequested, err
+}
+
+func (r *jobRepository) Finish(id, status string, result models.RawJSON, errMsg string) error {
+	now := time.Now()
+	return r.db.Model(&models.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"result":      result,
+			"error":       errMsg,
+			"finished_at": now,
+		}).Error
+}
+
+// RequestCancel flags a job; the server running it stops at its next check
+func (r *jobRepository) RequestCancel(id string) error {
+	res := r.db.Model(&models.Job{}).
+		Where("id = ? AND status IN ?", id, []string{models.JobQueued, models.JobRunning}).
+		Update("cancel_requested", true)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FailOrphaned marks jobs a previous run of this server left unfinished
+func (r *jobRepository) FailOrphaned(node string) (int64, error) {
+	now := time.Now()
+	res := r.db.Model(&models.Job{}).
+		Where("node = ? AND status IN ?", node, []string{models.JobQueued, models.JobRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.JobFailed,
+			"error":       "interrupted by server restart",
+			"finished_at": now,
+		})
+	return res.RowsAffected, res.Error
+}
@@ -2,27 +2,120 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrNotOnBoard is returned when a user has no score on the public board
+var ErrNotOnBoard = errors.New("user not found in leaderboard")
+
 type LeaderboardRepository interface {
 	AddUser(userID uint, rating int) error
 	UpdateUserScore(userID uint, rating int) error
+	SetUserScore(userID uint, rating int) (bool, error)
 	GetUserRank(userID uint) (int64, error)
+	GetUserRanks(userIDs []uint) (map[uint]int64, error)
+	GetBoardEntries(userIDs []uint) ([]models.LeaderboardEntry, error)
 	GetTopUsers(limit int) ([]models.LeaderboardEntry, error)
+	GetNeighbors(userID uint, radius int) ([]models.LeaderboardEntry, error)
+	GetRatingRange(min, max int, offset, limit int) ([]models.LeaderboardEntry, error)
+	CountRatingRange(min, max int) (int64, error)
 	GetUsersByRating(rating int) ([]uint, error)
 	RemoveUser(userID uint) error
 	GetLeaderboardSize() (int64, error)
+	GetRatingBounds() (min, max, median float64, err error)
 	CacheUser(user *models.User) error
+	IndexUsername(userID uint, username string) error
+	UnindexUsername(userID uint, username string) error
+	SearchUsernames(prefix string, limit int) ([]models.Suggestion, error)
+	GetBoardScores(userIDs []uint) (map[uint]int, error)
 	GetCachedUser(userID uint) (*models.User, error)
+	GetUserSnapshot(userID uint) (*models.UserSnapshot, error)
+	TrackUser(userID uint, maxUsers int) error
+	GetTrackedUsers(since time.Time) ([]uint, error)
+	SetShadowScore(userID uint, rating int) error
+	GetShadowScore(userID uint) (int, error)
+	CountAbove(rating int) (int64, error)
+	CountUpdate(userID uint, window time.Duration) (int64, error)
+	GetRecentRatingChange(userID uint, window time.Duration) (int, error)
+	RecordRatingChange(userID uint, delta int, window time.Duration) error
+	GetCachedTimezone(userID uint) (string, error)
+	AddPeriodGain(windowID string, userID uint, gain int, ttl time.Duration) error
+	GetPeriodTop(windowID string, limit int) ([]models.PeriodEntry, error)
+	AddImprovedGain(userID uint, gain int, at time.Time, ttl time.Duration) error
+	GetMostImproved(window time.Duration, limit int, cacheTTL time.Duration) ([]models.PeriodEntry, error)
+	LockUser(userID uint, token string, ttl time.Duration) (bool, error)
+	UnlockUser(userID uint, token string) error
+	GetAllScores(pageSize int64) (map[uint]int, error)
+	GetScores(userIDs []uint) (map[uint]int, error)
+	GetUncertainty(strategy string, userID uint) (float64, error)
+	SetUncertainty(strategy string, userID uint, value float64) error
+	RecordBestRating(userID uint, oldRating, newRating int) (previous int, improved bool, err error)
+	ClearBestRatings() error
+	RestoreUsers(users []models.User, overwrite bool) (*RestoreCounts, error)
+	StageUsers(token string, users []models.User) (*RestoreCounts, error)
+	SwapStaged(token string, boards ...string) error
+	DropStaged(token string, boards ...string) error
+	LockRebuild(token string, ttl time.Duration) (bool, error)
+	ArchiveBoard(at time.Time, ttl time.Duration) (string, error)
+	UnlockRebuild(token string) error
+	SampleBoardUsers(n int) ([]uint, error)
+	LockReconcile(token string, ttl time.Duration) (bool, error)
+	UnlockReconcile(token string) error
+	SaveReconcileReport(report *models.ReconcileReport) error
+	GetReconcileReport() (*models.ReconcileReport, error)
+}
+
+// RestoreCounts says where a batch of restored users went
+type RestoreCounts struct {
+	Board  int `json:"board"`  // added to (or, overwriting, set on) the public board
+	Shadow int `json:"shadow"` // same, shadow board
+	Cached int `json:"cached"` // user cache entries written
 }
 
+// Deletes the lock only if it still holds our token (it may have expired and
+// been taken by another updater)
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Writes a user cache hash only if there is none: a rebuild that keeps live
+// data must not roll back an entry written by an update since
+var cacheIfMissingScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+redis.call("HSET", KEYS[1], unpack(ARGV))
+return 1
+`)
+
+// Raises a user's personal best to ARGV[3] and returns {1, previous best},
+// or {0, best}. A user without one starts from their old rating (ARGV[2]).
+var bestRatingScript = redis.NewScript(`
+local stored = redis.call("HGET", KEYS[1], ARGV[1])
+local best = tonumber(stored or ARGV[2])
+local rating = tonumber(ARGV[3])
+if rating > best then
+	redis.call("HSET", KEYS[1], ARGV[1], rating)
+	return {1, best}
+end
+if not stored then
+	redis.call("HSET", KEYS[1], ARGV[1], best)
+end
+return {0, best}
+`)
+
 type leaderboardRepository struct {
 	redis *redis.Client
 	ctx   context.Context
@@ -35,12 +128,18 @@ func NewLeaderboardRepository(redisClient *redis.Client) LeaderboardRepository {
 	}
 }
 
-// AddUser adds a user to the leaderboard sorted set
+// AddUser adds a user to the leaderboard sorted set (and off the shadow board)
 func (r *leaderboardRepository) AddUser(userID uint, rating int) error {
-	return r.redis.ZAdd(r.ctx, database.LeaderboardKey, redis.Z{
+	member := fmt.Sprintf("user:%d", userID)
+
+	pipe := r.redis.TxPipeline()
+	pipe.ZAdd(r.ctx, database.LeaderboardKey, redis.Z{
 		Score:  float64(rating),
-		Member: fmt.Sprintf("user:%d", userID),
-	}).Err()
+		Member: member,
+	})
+	pipe.ZRem(r.ctx, database.ShadowBoardKey, member)
+	_, err := pipe.Exec(r.ctx)
+	return err
 }
 
 // UpdateUserScore updates user's score in leaderboard
@@ -48,6 +147,24 @@ func (r *leaderboardRepository) UpdateUserScore(userID uint, rating int) error {
 	return r.AddUser(userID, rating) // ZAdd handles both add and update
 }
 
+// SetUserScore is UpdateUserScore for fast-mode updates, which skip the
+// rank lookup before the write: it reports whether the user was new to
+// the board (no old rank)
+func (r *leaderboardRepository) SetUserScore(userID uint, rating int) (bool, error) {
+	member := fmt.Sprintf("user:%d", userID)
+
+	pipe := r.redis.TxPipeline()
+	added := pipe.ZAdd(r.ctx, database.LeaderboardKey, redis.Z{
+		Score:  float64(rating),
+		Member: member,
+	})
+	pipe.ZRem(r.ctx, database.ShadowBoardKey, member)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return false, err
+	}
+	return added.Val() == 1, nil
+}
+
 // GetUserRank returns the global rank of a user (1-indexed, handles ties)
 func (r *leaderboardRepository) GetUserRank(userID uint) (int64, error) {
 	member := fmt.Sprintf("user:%d", userID)
@@ -56,7 +173,7 @@ func (r *leaderboardRepository) GetUserRank(userID uint) (int64, error) {
 	score, err := r.redis.ZScore(r.ctx, database.LeaderboardKey, member).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return 0, fmt.Errorf("user not found in leaderboard")
+			return 0, ErrNotOnBoard
 		}
 		return 0, err
 	}
@@ -72,6 +189,83 @@ func (r *leaderboardRepository) GetUserRank(userID uint) (int64, error) {
 	return higherCount + 1, nil
 }
 
+// GetUserRanks returns the global ranks of many users in two pipelined
+// round trips (their scores, then one ZCOUNT per distinct score) instead of
+// two per user. Users not on the board are left out.
+func (r *leaderboardRepository) GetUserRanks(userIDs []uint) (map[uint]int64, error) {
+	ranks := make(map[uint]int64, len(userIDs))
+	if len(userIDs) == 0 {
+		return ranks, nil
+	}
+
+	scores, err := r.GetBoardScores(userIDs)
+	if err != nil || len(scores) == 0 {
+		return ranks, err
+	}
+
+	pipe := r.redis.Pipeline()
+	higher := make(map[int]*redis.IntCmd)
+	for _, rating := range scores {
+		if _, ok := higher[rating]; !ok {
+			higher[rating] = pipe.ZCount(r.ctx, database.LeaderboardKey, fmt.Sprintf("(%d", rating), "+inf")
+		}
+	}
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return nil, err
+	}
+
+	for userID, rating := range scores {
+		ranks[userID] = higher[rating].Val() + 1
+	}
+	return ranks, nil
+}
+
+// Returns {user ID, rating, rank, ...} for the users in ARGV that are on the
+// board KEYS[1]; ranks count higher scores, so ties share a rank
+var boardEntriesScript = redis.NewScript(`
+local out = {}
+for _, id in ipairs(ARGV) do
+	local score = redis.call("ZSCORE", KEYS[1], "user:" .. id)
+	if score then
+		local higher = redis.call("ZCOUNT", KEYS[1], "(" .. score, "+inf")
+		table.insert(out, tonumber(id))
+		table.insert(out, tonumber(score))
+		table.insert(out, higher + 1)
+	end
+end
+return out
+`)
+
+// Users looked up per boardEntriesScript call, so one call never blocks
+// Redis for long
+const boardEntriesChunk = 500
+
+// GetBoardEntries returns the rating and global rank of many users in one
+// round trip per 500 users. Users not on the board are left out.
+func (r *leaderboardRepository) GetBoardEntries(userIDs []uint) ([]models.LeaderboardEntry, error) {
+	entries := make([]models.LeaderboardEntry, 0, len(userIDs))
+	for start := 0; start < len(userIDs); start += boardEntriesChunk {
+		chunk := userIDs[start:min(start+boardEntriesChunk, len(userIDs))]
+		args := make([]interface{}, len(chunk))
+		for i, userID := range chunk {
+			args[i] = userID
+		}
+
+		values, err := boardEntriesScript.Run(r.ctx, r.redis, []string{database.LeaderboardKey}, args...).Int64Slice()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+2 < len(values); i += 3 {
+			entries = append(entries, models.LeaderboardEntry{
+				UserID: uint(values[i]),
+				Rating: int(values[i+1]),
+				Rank:   values[i+2],
+			})
+		}
+	}
+	return entries, nil
+}
+
 // GetTopUsers returns top N users from leaderboard with ranks
 func (r *leaderboardRepository) GetTopUsers(limit int) ([]models.LeaderboardEntry, error) {
 	results, err := r.redis.ZRevRangeWithScores(r.ctx, database.LeaderboardKey, 0, int64(limit-1)).Result()
@@ -103,6 +297,101 @@ func (r *leaderboardRepository) GetTopUsers(limit int) ([]models.LeaderboardEntr
 	return entries, nil
 }
 
+// GetNeighbors returns the users ranked within radius positions of a user
+// (including the user), with tie-aware ranks
+func (r *leaderboardRepository) GetNeighbors(userID uint, radius int) ([]models.LeaderboardEntry, error) {
+	member := fmt.Sprintf("user:%d", userID)
+
+	index, err := r.redis.ZRevRank(r.ctx, database.LeaderboardKey, member).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrNotOnBoard
+		}
+		return nil, err
+	}
+
+	start := index - int64(radius)
+	if start < 0 {
+		start = 0
+	}
+	results, err := r.redis.ZRevRangeWithScores(r.ctx, database.LeaderboardKey, start, index+int64(radius)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// One ZCOUNT per distinct score gives tie-aware ranks
+	pipe := r.redis.Pipeline()
+	higher := make(map[float64]*redis.IntCmd)
+	for _, z := range results {
+		if _, ok := higher[z.Score]; !ok {
+			higher[z.Score] = pipe.ZCount(r.ctx, database.LeaderboardKey, fmt.Sprintf("(%f", z.Score), "+inf")
+		}
+	}
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.LeaderboardEntry, 0, len(results))
+	for _, z := range results {
+		userIDStr := strings.TrimPrefix(z.Member.(string), "user:")
+		id, _ := strconv.ParseUint(userIDStr, 10, 32)
+
+		entries = append(entries, models.LeaderboardEntry{
+			Rank:   higher[z.Score].Val() + 1,
+			UserID: uint(id),
+			Rating: int(z.Score),
+		})
+	}
+
+	return entries, nil
+}
+
+// GetRatingRange returns a page of the users rated min..max (inclusive),
+// highest first, with their global tie-aware ranks
+func (r *leaderboardRepository) GetRatingRange(min, max int, offset, limit int) ([]models.LeaderboardEntry, error) {
+	results, err := r.redis.ZRevRangeByScoreWithScores(r.ctx, database.LeaderboardKey, &redis.ZRangeBy{
+		Min:    strconv.Itoa(min),
+		Max:    strconv.Itoa(max),
+		Offset: int64(offset),
+		Count:  int64(limit),
+	}).Result()
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+
+	// One ZCOUNT per distinct score gives tie-aware ranks
+	pipe := r.redis.Pipeline()
+	higher := make(map[float64]*redis.IntCmd)
+	for _, z := range results {
+		if _, ok := higher[z.Score]; !ok {
+			higher[z.Score] = pipe.ZCount(r.ctx, database.LeaderboardKey, fmt.Sprintf("(%f", z.Score), "+inf")
+		}
+	}
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.LeaderboardEntry, 0, len(results))
+	for _, z := range results {
+		userIDStr := strings.TrimPrefix(z.Member.(string), "user:")
+		id, _ := strconv.ParseUint(userIDStr, 10, 32)
+
+		entries = append(entries, models.LeaderboardEntry{
+			Rank:   higher[z.Score].Val() + 1,
+			UserID: uint(id),
+			Rating: int(z.Score),
+		})
+	}
+
+	return entries, nil
+}
+
+// CountRatingRange returns how many public leaderboard users are rated
+// min..max (inclusive)
+func (r *leaderboardRepository) CountRatingRange(min, max int) (int64, error) {
+	return r.redis.ZCount(r.ctx, database.LeaderboardKey, strconv.Itoa(min), strconv.Itoa(max)).Result()
+}
+
 // GetUsersByRating returns all users with a specific rating
 func (r *leaderboardRepository) GetUsersByRating(rating int) ([]uint, error) {
 	score := float64(rating)
@@ -126,10 +415,27 @@ func (r *leaderboardRepository) GetUsersByRating(rating int) ([]uint, error) {
 	return userIDs, nil
 }
 
-// RemoveUser removes a user from leaderboard
+// RemoveUser removes a user from the leaderboard, user cache and rank tracking
 func (r *leaderboardRepository) RemoveUser(userID uint) error {
 	member := fmt.Sprintf("user:%d", userID)
-	return r.redis.ZRem(r.ctx, database.LeaderboardKey, member).Err()
+
+	// The autocomplete entry is named after the cached username
+	username, err := r.redis.HGet(r.ctx, fmt.Sprintf(database.UserCacheKey, userID), "username").Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := r.redis.TxPipeline()
+	if username != "" {
+		pipe.ZRem(r.ctx, database.UsernameIndexKey, UsernameIndexMember(userID, username))
+	}
+	pipe.ZRem(r.ctx, database.LeaderboardKey, member)
+	pipe.ZRem(r.ctx, database.ShadowBoardKey, member)
+	pipe.Del(r.ctx, fmt.Sprintf(database.UserCacheKey, userID))
+	pipe.ZRem(r.ctx, database.RankTrackedKey, userID)
+	pipe.HDel(r.ctx, database.BestRatingKey, strconv.FormatUint(uint64(userID), 10))
+	_, err = pipe.Exec(r.ctx)
+	return err
 }
 
 // GetLeaderboardSize returns total number of users in leaderboard
@@ -137,14 +443,54 @@ func (r *leaderboardRepository) GetLeaderboardSize() (int64, error) {
 	return r.redis.ZCard(r.ctx, database.LeaderboardKey).Result()
 }
 
+// Returns the lowest, highest and two middle scores of KEYS[1] (the middle
+// ones are equal for odd sizes), or nothing when it is empty. Scores are
+// returned as strings, since Lua numbers would be truncated to integers.
+var ratingBoundsScript = redis.NewScript(`
+local size = redis.call("ZCARD", KEYS[1])
+if size == 0 then
+	return {}
+end
+local lowest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+local highest = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+local middle = redis.call("ZRANGE", KEYS[1], math.floor((size - 1) / 2), math.floor(size / 2), "WITHSCORES")
+return {lowest[2], highest[2], middle[2], middle[#middle]}
+`)
+
+// GetRatingBounds returns the lowest, highest and median rating in one round
+// trip; all are 0 when the board is empty
+func (r *leaderboardRepository) GetRatingBounds() (float64, float64, float64, error) {
+	values, err := ratingBoundsScript.Run(r.ctx, r.redis, []string{database.LeaderboardKey}).StringSlice()
+	if err != nil || len(values) != 4 {
+		return 0, 0, 0, err
+	}
+
+	scores := make([]float64, len(values))
+	for i, value := range values {
+		if scores[i], err = strconv.ParseFloat(value, 64); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+
+	// Even-sized sets average the two middle scores
+	return scores[0], scores[1], (scores[2] + scores[3]) / 2, nil
+}
+
 // CacheUser caches user data in Redis hash
 func (r *leaderboardRepository) CacheUser(user *models.User) error {
 	key := fmt.Sprintf(database.UserCacheKey, user.ID)
 
+	status := user.Status
+	if status == "" {
+		status = models.UserStatusActive
+	}
+
 	return r.redis.HSet(r.ctx, key,
 		"id", user.ID,
 		"username", user.Username,
 		"rating", user.Rating,
+		"status", status,
+		"tz", user.Timezone,
 	).Err()
 }
 
@@ -160,9 +506,712 @@ func (r *leaderboardRepository) GetCachedUser(userID uint) (*models.User, error)
 	id, _ := strconv.ParseUint(result["id"], 10, 32)
 	rating, _ := strconv.Atoi(result["rating"])
 
+	status := result["status"]
+	if status == "" {
+		status = models.UserStatusActive // cached before statuses existed
+	}
+
 	return &models.User{
 		ID:       uint(id),
 		Username: result["username"],
 		Rating:   rating,
+		Status:   status,
+		Timezone: result["tz"],
 	}, nil
-}
\ No newline at end of file
+}
+
+// UsernameIndexMember is a user's entry in the autocomplete index: the
+// lowercase username first, so ZRANGEBYLEX finds it by prefix
+func UsernameIndexMember(userID uint, username string) string {
+	return strings.ToLower(username) + "\x00" + strconv.FormatUint(uint64(userID), 10) + "\x00" + username
+}
+
+// IndexUsername adds a user to the autocomplete index
+func (r *leaderboardRepository) IndexUsername(userID uint, username string) error {
+	return r.redis.ZAdd(r.ctx, database.UsernameIndexKey, redis.Z{Member: UsernameIndexMember(userID, username)}).Err()
+}
+
+// UnindexUsername removes a user's entry for username (e.g. their old name)
+// from the autocomplete index
+func (r *leaderboardRepository) UnindexUsername(userID uint, username string) error {
+	return r.redis.ZRem(r.ctx, database.UsernameIndexKey, UsernameIndexMember(userID, username)).Err()
+}
+
+// SearchUsernames returns up to limit users whose username starts with
+// prefix (case-insensitive), in alphabetical order. Ratings are not set.
+func (r *leaderboardRepository) SearchUsernames(prefix string, limit int) ([]models.Suggestion, error) {
+	prefix = strings.ToLower(prefix)
+	members, err := r.redis.ZRangeByLex(r.ctx, database.UsernameIndexKey, &redis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff", // no UTF-8 byte is 0xff
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]models.Suggestion, 0, len(members))
+	for _, member := range members {
+		parts := strings.SplitN(member, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		id, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		matches = append(matches, models.Suggestion{UserID: uint(id), Username: parts[2]})
+	}
+	return matches, nil
+}
+
+// GetBoardScores returns the ratings of those of userIDs on the public
+// board (not the shadow board, unlike GetScores)
+func (r *leaderboardRepository) GetBoardScores(userIDs []uint) (map[uint]int, error) {
+	pipe := r.redis.Pipeline()
+	cmds := make([]*redis.FloatCmd, len(userIDs))
+	for i, userID := range userIDs {
+		cmds[i] = pipe.ZScore(r.ctx, database.LeaderboardKey, fmt.Sprintf("user:%d", userID))
+	}
+	if _, err := pipe.Exec(r.ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	scores := make(map[uint]int, len(userIDs))
+	for i, userID := range userIDs {
+		if score, err := cmds[i].Result(); err == nil {
+			scores[userID] = int(score)
+		}
+	}
+	return scores, nil
+}
+
+// GetUserSnapshot reads a user's score, rank, board size and cached profile
+// in two pipelined round trips instead of four sequential calls
+func (r *leaderboardRepository) GetUserSnapshot(userID uint) (*models.UserSnapshot, error) {
+	member := fmt.Sprintf("user:%d", userID)
+
+	pipe := r.redis.Pipeline()
+	scoreCmd := pipe.ZScore(r.ctx, database.LeaderboardKey, member)
+	sizeCmd := pipe.ZCard(r.ctx, database.LeaderboardKey)
+	cacheCmd := pipe.HGetAll(r.ctx, fmt.Sprintf(database.UserCacheKey, userID))
+	if _, err := pipe.Exec(r.ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	score, err := scoreCmd.Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrNotOnBoard
+		}
+		return nil, err
+	}
+
+	// Rank depends on the score, so it needs a second round trip
+	higherCount, err := r.redis.ZCount(r.ctx, database.LeaderboardKey,
+		fmt.Sprintf("(%f", score), "+inf").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserSnapshot{
+		UserID:   userID,
+		Username: cacheCmd.Val()["username"],
+		Rating:   int(score),
+		Rank:     higherCount + 1,
+		Total:    sizeCmd.Val(),
+	}, nil
+}
+
+// TrackUser marks a user for rank snapshots even outside the top N. Users
+// are kept by when they were last tracked; only the maxUsers most recent stay.
+func (r *leaderboardRepository) TrackUser(userID uint, maxUsers int) error {
+	pipe := r.redis.TxPipeline()
+	pipe.ZAdd(r.ctx, database.RankTrackedKey, redis.Z{Score: float64(time.Now().Unix()), Member: userID})
+	pipe.ZRemRangeByRank(r.ctx, database.RankTrackedKey, 0, int64(-maxUsers-1))
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// GetTrackedUsers returns users tracked for rank snapshots since the given
+// time, dropping those tracked before it
+func (r *leaderboardRepository) GetTrackedUsers(since time.Time) ([]uint, error) {
+	pipe := r.redis.TxPipeline()
+	pipe.ZRemRangeByScore(r.ctx, database.RankTrackedKey, "-inf", fmt.Sprintf("(%d", since.Unix()))
+	membersCmd := pipe.ZRange(r.ctx, database.RankTrackedKey, 0, -1)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return nil, err
+	}
+
+	members := membersCmd.Val()
+	userIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		userID, err := strconv.ParseUint(member, 10, 32)
+		if err != nil {
+			continue
+		}
+		userIDs = append(userIDs, uint(userID))
+	}
+	return userIDs, nil
+}
+
+// SetShadowScore moves a user off the public leaderboard onto the shadow board
+func (r *leaderboardRepository) SetShadowScore(userID uint, rating int) error {
+	member := fmt.Sprintf("user:%d", userID)
+
+	pipe := r.redis.TxPipeline()
+	pipe.ZRem(r.ctx, database.LeaderboardKey, member)
+	pipe.ZAdd(r.ctx, database.ShadowBoardKey, redis.Z{Score: float64(rating), Member: member})
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// GetShadowScore returns a shadow-banned user's rating
+func (r *leaderboardRepository) GetShadowScore(userID uint) (int, error) {
+	score, err := r.redis.ZScore(r.ctx, database.ShadowBoardKey, fmt.Sprintf("user:%d", userID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, fmt.Errorf("user not on shadow board")
+		}
+		return 0, err
+	}
+	return int(score), nil
+}
+
+// GetUncertainty returns a user's rating uncertainty under a ranking
+// strategy, or 0 when they have none yet
+func (r *leaderboardRepository) GetUncertainty(strategy string, userID uint) (float64, error) {
+	value, err := r.redis.HGet(r.ctx, fmt.Sprintf(database.RankStateKey, strategy), strconv.FormatUint(uint64(userID), 10)).Float64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return value, err
+}
+
+// SetUncertainty stores a user's rating uncertainty under a ranking strategy
+func (r *leaderboardRepository) SetUncertainty(strategy string, userID uint, value float64) error {
+	return r.redis.HSet(r.ctx, fmt.Sprintf(database.RankStateKey, strategy), strconv.FormatUint(uint64(userID), 10), value).Err()
+}
+
+// RecordBestRating raises the user's personal best to newRating when it is
+// higher, returning the previous best and whether it was beaten. The first
+// update of a user counts from their old rating.
+func (r *leaderboardRepository) RecordBestRating(userID uint, oldRating, newRating int) (int, bool, error) {
+	result, err := bestRatingScript.Run(r.ctx, r.redis, []string{database.BestRatingKey},
+		strconv.FormatUint(uint64(userID), 10), oldRating, newRating).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	return int(result[1]), result[0] == 1, nil
+}
+
+// ClearBestRatings forgets every personal best (a reset starts a new season)
+func (r *leaderboardRepository) ClearBestRatings() error {
+	return r.redis.Del(r.ctx, database.BestRatingKey).Err()
+}
+
+// CountAbove returns how many public leaderboard users have a higher rating
+func (r *leaderboardRepository) CountAbove(rating int) (int64, error) {
+	return r.redis.ZCount(r.ctx, database.LeaderboardKey, fmt.Sprintf("(%d", rating), "+inf").Result()
+}
+
+// CountUpdate increments and returns the user's update count for the current
+// fixed window (shared by all servers)
+func (r *leaderboardRepository) CountUpdate(userID uint, window time.Duration) (int64, error) {
+	windowStart := time.Now().Truncate(window).Unix()
+	key := fmt.Sprintf(database.UpdateRateKey, userID, windowStart)
+
+	pipe := r.redis.TxPipeline()
+	incr := pipe.Incr(r.ctx, key)
+	pipe.Expire(r.ctx, key, 2*window)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// GetRecentRatingChange sums the rating changes applied within the rolling window
+func (r *leaderboardRepository) GetRecentRatingChange(userID uint, window time.Duration) (int, error) {
+	key := fmt.Sprintf(database.RatingDeltaKey, userID)
+	since := time.Now().Add(-window).UnixMilli()
+
+	members, err := r.redis.ZRangeByScore(r.ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, member := range members {
+		// Member format: "<unix nanos>:<delta>" (unique per change)
+		if i := strings.LastIndexByte(member, ':'); i >= 0 {
+			delta, err := strconv.Atoi(member[i+1:])
+			if err == nil {
+				total += delta
+			}
+		}
+	}
+	return total, nil
+}
+
+// RecordRatingChange remembers an applied rating change and trims entries
+// that fell out of the window
+func (r *leaderboardRepository) RecordRatingChange(userID uint, delta int, window time.Duration) error {
+	key := fmt.Sprintf(database.RatingDeltaKey, userID)
+	now := time.Now()
+
+	pipe := r.redis.TxPipeline()
+	pipe.ZAdd(r.ctx, key, redis.Z{
+		Score:  float64(now.UnixMilli()),
+		Member: fmt.Sprintf("%d:%d", now.UnixNano(), delta),
+	})
+	pipe.ZRemRangeByScore(r.ctx, key, "-inf", strconv.FormatInt(now.Add(-window).UnixMilli(), 10))
+	pipe.Expire(r.ctx, key, window)
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// GetCachedTimezone returns the user's cached timezone ("" when unknown)
+func (r *leaderboardRepository) GetCachedTimezone(userID uint) (string, error) {
+	key := fmt.Sprintf(database.UserCacheKey, userID)
+	tz, err := r.redis.HGet(r.ctx, key, "tz").Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return tz, err
+}
+
+// AddPeriodGain adds a rating change to a user's total on a period board
+func (r *leaderboardRepository) AddPeriodGain(windowID string, userID uint, gain int, ttl time.Duration) error {
+	key := fmt.Sprintf(database.PeriodBoardKey, windowID)
+
+	pipe := r.redis.TxPipeline()
+	pipe.ZIncrBy(r.ctx, key, float64(gain), fmt.Sprintf("user:%d", userID))
+	pipe.Expire(r.ctx, key, ttl)
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// GetPeriodTop returns the biggest gainers of a period board
+func (r *leaderboardRepository) GetPeriodTop(windowID string, limit int) ([]models.PeriodEntry, error) {
+	key := fmt.Sprintf(database.PeriodBoardKey, windowID)
+
+	results, err := r.redis.ZRevRangeWithScores(r.ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	return gainEntries(results), nil
+}
+
+// AddImprovedGain adds a rating change to the hourly bucket of at
+func (r *leaderboardRepository) AddImprovedGain(userID uint, gain int, at time.Time, ttl time.Duration) error {
+	key := fmt.Sprintf(database.ImprovedBucketKey, at.Unix()/3600)
+
+	pipe := r.redis.TxPipeline()
+	pipe.ZIncrBy(r.ctx, key, float64(gain), fmt.Sprintf("user:%d", userID))
+	pipe.Expire(r.ctx, key, ttl)
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// GetMostImproved returns the users with the biggest net rating gain over
+// the last window (in whole hours, the current one included). The union of
+// the hourly buckets is cached for cacheTTL; users who lost rating are left out.
+func (r *leaderboardRepository) GetMostImproved(window time.Duration, limit int, cacheTTL time.Duration) ([]models.PeriodEntry, error) {
+	cached, err := r.redis.Exists(r.ctx, database.ImprovedBoardKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if cached == 0 {
+		current := time.Now().Unix() / 3600
+		hours := int64(window / time.Hour)
+		keys := make([]string, 0, hours)
+		for hour := current - hours + 1; hour <= current; hour++ {
+			keys = append(keys, fmt.Sprintf(database.ImprovedBucketKey, hour))
+		}
+
+		pipe := r.redis.TxPipeline()
+		pipe.ZUnionStore(r.ctx, database.ImprovedBoardKey, &redis.ZStore{Keys: keys})
+		pipe.Expire(r.ctx, database.ImprovedBoardKey, cacheTTL)
+		if _, err := pipe.Exec(r.ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	results, err := r.redis.ZRevRangeByScoreWithScores(r.ctx, database.ImprovedBoardKey, &redis.ZRangeBy{
+		Min:   "(0",
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return gainEntries(results), nil
+}
+
+// gainEntries turns a board of rating gains into tie-aware ranked entries
+func gainEntries(results []redis.Z) []models.PeriodEntry {
+	entries := make([]models.PeriodEntry, 0, len(results))
+	currentRank := int64(1)
+	var previousScore float64
+
+	for i, z := range results {
+		if i > 0 && z.Score != previousScore {
+			currentRank = int64(i) + 1
+		}
+
+		userIDStr := strings.TrimPrefix(z.Member.(string), "user:")
+		userID, _ := strconv.ParseUint(userIDStr, 10, 32)
+
+		entries = append(entries, models.PeriodEntry{
+			Rank:   currentRank,
+			UserID: uint(userID),
+			Gain:   int(z.Score),
+		})
+
+		previousScore = z.Score
+	}
+
+	return entries
+}
+
+// LockUser takes the per-user update lock; false means another update holds it
+func (r *leaderboardRepository) LockUser(userID uint, token string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf(database.UserLockKey, userID)
+	return r.redis.SetNX(r.ctx, key, token, ttl).Result()
+}
+
+// UnlockUser releases the per-user lock if token still owns it
+func (r *leaderboardRepository) UnlockUser(userID uint, token string) error {
+	key := fmt.Sprintf(database.UserLockKey, userID)
+	return unlockScript.Run(r.ctx, r.redis, []string{key}, token).Err()
+}
+
+// GetAllScores reads every rating on the public and shadow boards, paging
+// through each sorted set
+func (r *leaderboardRepository) GetAllScores(pageSize int64) (map[uint]int, error) {
+	scores := make(map[uint]int)
+	for _, key := range []string{database.LeaderboardKey, database.ShadowBoardKey} {
+		for start := int64(0); ; start += pageSize {
+			page, err := r.redis.ZRangeWithScores(r.ctx, key, start, start+pageSize-1).Result()
+			if err != nil {
+				return nil, err
+			}
+			for _, z := range page {
+				userID, err := strconv.ParseUint(strings.TrimPrefix(z.Member.(string), "user:"), 10, 32)
+				if err != nil {
+					continue
+				}
+				scores[uint(userID)] = int(z.Score)
+			}
+			if int64(len(page)) < pageSize {
+				break
+			}
+		}
+	}
+	return scores, nil
+}
+
+// GetScores reads the ratings of the given users from either board
+// (users on neither are left out)
+func (r *leaderboardRepository) GetScores(userIDs []uint) (map[uint]int, error) {
+	pipe := r.redis.Pipeline()
+	public := make([]*redis.FloatCmd, len(userIDs))
+	shadow := make([]*redis.FloatCmd, len(userIDs))
+	for i, userID := range userIDs {
+		member := fmt.Sprintf("user:%d", userID)
+		public[i] = pipe.ZScore(r.ctx, database.LeaderboardKey, member)
+		shadow[i] = pipe.ZScore(r.ctx, database.ShadowBoardKey, member)
+	}
+	if _, err := pipe.Exec(r.ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	scores := make(map[uint]int, len(userIDs))
+	for i, userID := range userIDs {
+		if score, err := public[i].Result(); err == nil {
+			scores[userID] = int(score)
+		} else if score, err := shadow[i].Result(); err == nil {
+			scores[userID] = int(score)
+		}
+	}
+	return scores, nil
+}
+
+// RestoreUsers writes users from PostgreSQL onto the boards matching their
+// status, and into the user cache, in one pipeline. Unless overwrite is
+// set only missing entries are added (ZADD NX, cache if absent), so
+// updates made while a rebuild runs are kept.
+func (r *leaderboardRepository) RestoreUsers(users []models.User, overwrite bool) (*RestoreCounts, error) {
+	pipe := r.redis.Pipeline()
+	var board, shadow, index []redis.Z
+	var cached []*redis.Cmd
+	var cachedOverwrite int
+
+	for _, user := range users {
+		member := fmt.Sprintf("user:%d", user.ID)
+		z := redis.Z{Score: float64(user.Rating), Member: member}
+		indexed := redis.Z{Member: UsernameIndexMember(user.ID, user.Username)}
+
+		switch user.Status {
+		case models.UserStatusBanned:
+			if overwrite {
+				pipe.ZRem(r.ctx, database.LeaderboardKey, member)
+				pipe.ZRem(r.ctx, database.ShadowBoardKey, member)
+				pipe.ZRem(r.ctx, database.UsernameIndexKey, indexed.Member)
+			}
+		case models.UserStatusShadowBanned:
+			shadow = append(shadow, z)
+			index = append(index, indexed)
+			if overwrite {
+				pipe.ZRem(r.ctx, database.LeaderboardKey, member)
+			}
+		default:
+			board = append(board, z)
+			index = append(index, indexed)
+			if overwrite {
+				pipe.ZRem(r.ctx, database.ShadowBoardKey, member)
+			}
+		}
+
+		status := user.Status
+		if status == "" {
+			status = models.UserStatusActive
+		}
+		key := fmt.Sprintf(database.UserCacheKey, user.ID)
+		fields := []interface{}{
+			"id", user.ID,
+			"username", user.Username,
+			"rating", user.Rating,
+			"status", status,
+			"tz", user.Timezone,
+		}
+		if overwrite {
+			pipe.HSet(r.ctx, key, fields...)
+			cachedOverwrite++
+		} else {
+			cached = append(cached, cacheIfMissingScript.Eval(r.ctx, pipe, []string{key}, fields...))
+		}
+	}
+
+	add := func(key string, members []redis.Z) *redis.IntCmd {
+		if len(members) == 0 {
+			return nil
+		}
+		if overwrite {
+			pipe.ZAdd(r.ctx, key, members...)
+			return nil
+		}
+		return pipe.ZAddNX(r.ctx, key, members...)
+	}
+	boardCmd := add(database.LeaderboardKey, board)
+	shadowCmd := add(database.ShadowBoardKey, shadow)
+	if len(index) > 0 {
+		pipe.ZAdd(r.ctx, database.UsernameIndexKey, index...)
+	}
+
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return nil, err
+	}
+
+	counts := &RestoreCounts{Board: len(board), Shadow: len(shadow), Cached: cachedOverwrite}
+	if boardCmd != nil {
+		counts.Board = int(boardCmd.Val())
+	}
+	if shadowCmd != nil {
+		counts.Shadow = int(shadowCmd.Val())
+	}
+	for _, cmd := range cached {
+		if written, _ := cmd.Int(); written == 1 {
+			counts.Cached++
+		}
+	}
+	return counts, nil
+}
+
+// How long staged boards outlive the last page written to them, so an
+// abandoned rebuild cleans up after itself
+const stagingTTL = time.Hour
+
+// StagingKey is the key board is built under by the blue/green rebuild token
+func StagingKey(board, token string) string {
+	return fmt.Sprintf(database.StagingBoardKey, board, token)
+}
+
+// Renames each staged board (KEYS[i]) over its live key (KEYS[i+1]) in one
+// step; a board nothing was staged for is emptied. RENAME keeps the staging
+// TTL, so it is removed.
+var swapStagedScript = redis.NewScript(`
+for i = 1, #KEYS, 2 do
+	if redis.call("EXISTS", KEYS[i]) == 1 then
+		redis.call("RENAME", KEYS[i], KEYS[i + 1])
+		redis.call("PERSIST", KEYS[i + 1])
+	else
+		redis.call("DEL", KEYS[i + 1])
+	end
+end
+return 1
+`)
+
+// StageUsers writes users onto the staging boards of token, matching their
+// status, and the staging autocomplete index, and overwrites their user
+// cache entries. The live boards are not touched until SwapStaged.
+func (r *leaderboardRepository) StageUsers(token string, users []models.User) (*RestoreCounts, error) {
+	boardKey := StagingKey(database.LeaderboardKey, token)
+	shadowKey := StagingKey(database.ShadowBoardKey, token)
+	indexKey := StagingKey(database.UsernameIndexKey, token)
+
+	pipe := r.redis.Pipeline()
+	var board, shadow, index []redis.Z
+	for _, user := range users {
+		z := redis.Z{Score: float64(user.Rating), Member: fmt.Sprintf("user:%d", user.ID)}
+		indexed := redis.Z{Member: UsernameIndexMember(user.ID, user.Username)}
+		switch user.Status {
+		case models.UserStatusBanned:
+		case models.UserStatusShadowBanned:
+			shadow = append(shadow, z)
+			index = append(index, indexed)
+		default:
+			board = append(board, z)
+			index = append(index, indexed)
+		}
+
+		status := user.Status
+		if status == "" {
+			status = models.UserStatusActive
+		}
+		pipe.HSet(r.ctx, fmt.Sprintf(database.UserCacheKey, user.ID),
+			"id", user.ID,
+			"username", user.Username,
+			"rating", user.Rating,
+			"status", status,
+			"tz", user.Timezone,
+		)
+	}
+	if len(board) > 0 {
+		pipe.ZAdd(r.ctx, boardKey, board...)
+		pipe.Expire(r.ctx, boardKey, stagingTTL)
+	}
+	if len(shadow) > 0 {
+		pipe.ZAdd(r.ctx, shadowKey, shadow...)
+		pipe.Expire(r.ctx, shadowKey, stagingTTL)
+	}
+	if len(index) > 0 {
+		pipe.ZAdd(r.ctx, indexKey, index...)
+		pipe.Expire(r.ctx, indexKey, stagingTTL)
+	}
+
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return nil, err
+	}
+	return &RestoreCounts{Board: len(board), Shadow: len(shadow), Cached: len(users)}, nil
+}
+
+// SwapStaged atomically replaces each of boards with what token staged for it
+func (r *leaderboardRepository) SwapStaged(token string, boards ...string) error {
+	keys := make([]string, 0, len(boards)*2)
+	for _, board := range boards {
+		keys = append(keys, StagingKey(board, token), board)
+	}
+	return swapStagedScript.Run(r.ctx, r.redis, keys).Err()
+}
+
+// DropStaged deletes what token staged for boards (an abandoned rebuild)
+func (r *leaderboardRepository) DropStaged(token string, boards ...string) error {
+	keys := make([]string, 0, len(boards))
+	for _, board := range boards {
+		keys = append(keys, StagingKey(board, token))
+	}
+	return r.redis.Del(r.ctx, keys...).Err()
+}
+
+// LockRebuild takes the lock that keeps servers from rebuilding at once
+func (r *leaderboardRepository) LockRebuild(token string, ttl time.Duration) (bool, error) {
+	return r.redis.SetNX(r.ctx, database.RebuildLockKey, token, ttl).Result()
+}
+
+// UnlockRebuild releases the rebuild lock if token still owns it
+func (r *leaderboardRepository) UnlockRebuild(token string) error {
+	return unlockScript.Run(r.ctx, r.redis, []string{database.RebuildLockKey}, token).Err()
+}
+
+// ArchiveBoard renames the global board to a key stamped with at, expiring
+// after ttl (0 = kept). It returns the archive key, or "" when the board
+// was empty.
+func (r *leaderboardRepository) ArchiveBoard(at time.Time, ttl time.Duration) (string, error) {
+	key := fmt.Sprintf(database.ArchiveBoardKey, at.UTC().Format("20060102T150405Z"))
+
+	renamed, err := r.redis.RenameNX(r.ctx, database.LeaderboardKey, key).Result()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return "", nil
+		}
+		return "", err
+	}
+	if !renamed {
+		return "", fmt.Errorf("archive %s already exists", key)
+	}
+	if ttl > 0 {
+		if err := r.redis.Expire(r.ctx, key, ttl).Err(); err != nil {
+			return key, err
+		}
+	}
+	return key, nil
+}
+
+// SampleBoardUsers returns up to n distinct random users from each board
+func (r *leaderboardRepository) SampleBoardUsers(n int) ([]uint, error) {
+	pipe := r.redis.Pipeline()
+	public := pipe.ZRandMember(r.ctx, database.LeaderboardKey, n)
+	shadow := pipe.ZRandMember(r.ctx, database.ShadowBoardKey, n)
+	if _, err := pipe.Exec(r.ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	var userIDs []uint
+	for _, member := range append(public.Val(), shadow.Val()...) {
+		userID, err := strconv.ParseUint(strings.TrimPrefix(member, "user:"), 10, 32)
+		if err != nil {
+			continue
+		}
+		userIDs = append(userIDs, uint(userID))
+	}
+	return userIDs, nil
+}
+
+// LockReconcile takes the reconcile lock; false if another server holds it
+func (r *leaderboardRepository) LockReconcile(token string, ttl time.Duration) (bool, error) {
+	return r.redis.SetNX(r.ctx, database.ReconcileLockKey, token, ttl).Result()
+}
+
+// UnlockReconcile releases the reconcile lock if token still owns it
+func (r *leaderboardRepository) UnlockReconcile(token string) error {
+	return unlockScript.Run(r.ctx, r.redis, []string{database.ReconcileLockKey}, token).Err()
+}
+
+// SaveReconcileReport stores the latest reconcile report for every server
+func (r *leaderboardRepository) SaveReconcileReport(report *models.ReconcileReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return r.redis.Set(r.ctx, database.ReconcileReportKey, data, 0).Err()
+}
+
+// GetReconcileReport returns the latest reconcile report (nil if none ran)
+func (r *leaderboardRepository) GetReconcileReport() (*models.ReconcileReport, error) {
+	data, err := r.redis.Get(r.ctx, database.ReconcileReportKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report models.ReconcileReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
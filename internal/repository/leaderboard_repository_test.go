@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestBoard(t *testing.T) LeaderboardRepository {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewLeaderboardRepository(client)
+}
+
+func TestGetBoardEntries(t *testing.T) {
+	board := newTestBoard(t)
+	for userID, rating := range map[uint]int{1: 1800, 2: 1500, 3: 1500, 4: 1200} {
+		if err := board.AddUser(userID, rating); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	userIDs := []uint{4, 3, 99, 2}
+	for i := 0; len(userIDs) <= boardEntriesChunk; i++ {
+		userIDs = append(userIDs, uint(1000+i)) // spills into a second script call
+	}
+	userIDs = append(userIDs, 1)
+
+	entries, err := board.GetBoardEntries(userIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.LeaderboardEntry{
+		{UserID: 4, Rating: 1200, Rank: 4},
+		{UserID: 3, Rating: 1500, Rank: 2},
+		{UserID: 2, Rating: 1500, Rank: 2},
+		{UserID: 1, Rating: 1800, Rank: 1},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", entries, want)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entries[%d] = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+
+	if entries, err := board.GetBoardEntries(nil); err != nil || len(entries) != 0 {
+		t.Errorf("GetBoardEntries(nil) = %v, %v", entries, err)
+	}
+}
+
+func TestTrackedUsersCappedAndExpired(t *testing.T) {
+	board := newTestBoard(t)
+	for userID := uint(1); userID <= 5; userID++ {
+		if err := board.TrackUser(userID, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tracked, err := board.GetTrackedUsers(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 3 {
+		t.Errorf("tracked = %v, want the 3 most recent users", tracked)
+	}
+
+	// Users not requested since the cutoff are dropped
+	if tracked, err := board.GetTrackedUsers(time.Now().Add(time.Hour)); err != nil || len(tracked) != 0 {
+		t.Errorf("tracked after expiry = %v, %v; want none", tracked, err)
+	}
+	if tracked, err := board.GetTrackedUsers(time.Now().Add(-time.Hour)); err != nil || len(tracked) != 0 {
+		t.Errorf("expired users still stored: %v, %v", tracked, err)
+	}
+}
+
+func TestGetRatingBounds(t *testing.T) {
+	board := newTestBoard(t)
+
+	// An empty board has no bounds, and no NaN median
+	if low, high, median, err := board.GetRatingBounds(); err != nil || low != 0 || high != 0 || median != 0 {
+		t.Errorf("empty board = %v, %v, %v, %v; want zeros", low, high, median, err)
+	}
+
+	for _, tc := range []struct {
+		userID uint
+		rating int
+		median float64
+	}{
+		{1, 1500, 1500},
+		{2, 1000, 1250}, // even sizes average the middle two
+		{3, 2200, 1500},
+		{4, 1601, 1550.5},
+	} {
+		if err := board.AddUser(tc.userID, tc.rating); err != nil {
+			t.Fatal(err)
+		}
+		low, high, median, err := board.GetRatingBounds()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if median != tc.median || low > high {
+			t.Errorf("after adding user %d: bounds %v..%v median %v, want median %v", tc.userID, low, high, median, tc.median)
+		}
+	}
+
+	if low, high, _, _ := board.GetRatingBounds(); low != 1000 || high != 2200 {
+		t.Errorf("bounds = %v..%v, want 1000..2200", low, high)
+	}
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// MatchmakingRepository finds players near a rating on the public board
+// and remembers who played whom recently
+type MatchmakingRepository interface {
+	GetNearest(rating, band, limit int) ([]models.MatchCandidate, error)
+	GetRecentOpponents(userID uint, since time.Time) (map[uint]bool, error)
+	RecordMatch(userID, opponentID uint, at time.Time, ttl time.Duration) error
+	Forget(userID uint) error
+}
+
+type matchmakingRepository struct {
+	redis *redis.Client
+	ctx   context.Context
+}
+
+func NewMatchmakingRepository(redisClient *redis.Client) MatchmakingRepository {
+	return &matchmakingRepository{
+		redis: redisClient,
+		ctx:   database.Ctx,
+	}
+}
+
+// GetNearest returns up to limit players on each side of rating, at most
+// band away, closest first. Both sides are read with ZRANGEBYSCORE from the
+// rating outwards, so a crowded band costs no more than a sparse one.
+func (r *matchmakingRepository) GetNearest(rating, band, limit int) ([]models.MatchCandidate, error) {
+	pipe := r.redis.Pipeline()
+	above := pipe.ZRangeByScoreWithScores(r.ctx, database.LeaderboardKey, &redis.ZRangeBy{
+		Min:   strconv.Itoa(rating),
+		Max:   strconv.Itoa(rating + band),
+		Count: int64(limit),
+	})
+	below := pipe.ZRevRangeByScoreWithScores(r.ctx, database.LeaderboardKey, &redis.ZRangeBy{
+		Min:   strconv.Itoa(rating - band),
+		Max:   "(" + strconv.Itoa(rating),
+		Count: int64(limit),
+	})
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return nil, err
+	}
+
+	// Merge the two sides, each already ordered by distance from rating
+	up, down := above.Val(), below.Val()
+	candidates := make([]models.MatchCandidate, 0, len(up)+len(down))
+	for len(up) > 0 || len(down) > 0 {
+		var z redis.Z
+		if len(down) == 0 || (len(up) > 0 && int(up[0].Score)-rating <= rating-int(down[0].Score)) {
+			z, up = up[0], up[1:]
+		} else {
+			z, down = down[0], down[1:]
+		}
+
+		userID, err := strconv.ParseUint(strings.TrimPrefix(z.Member.(string), "user:"), 10, 32)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, models.MatchCandidate{
+			UserID:    uint(userID),
+			Rating:    int(z.Score),
+			RatingGap: int(z.Score) - rating,
+		})
+	}
+	return candidates, nil
+}
+
+// GetRecentOpponents returns the users a user played since since
+func (r *matchmakingRepository) GetRecentOpponents(userID uint, since time.Time) (map[uint]bool, error) {
+	members, err := r.redis.ZRangeByScore(r.ctx, fmt.Sprintf(database.MatchRecentKey, userID), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	opponents := make(map[uint]bool, len(members))
+	for _, member := range members {
+		opponentID, err := strconv.ParseUint(member, 10, 32)
+		if err != nil {
+			continue
+		}
+		opponents[uint(opponentID)] = true
+	}
+	return opponents, nil
+}
+
+// RecordMatch notes a match on both players' sets, trims entries older than
+// ttl and lets an idle set expire after ttl
+func (r *matchmakingRepository) RecordMatch(userID, opponentID uint, at time.Time, ttl time.Duration) error {
+	cutoff := strconv.FormatInt(at.Add(-ttl).Unix(), 10)
+	pipe := r.redis.TxPipeline()
+	for _, pair := range [][2]uint{{userID, opponentID}, {opponentID, userID}} {
+		key := fmt.Sprintf(database.MatchRecentKey, pair[0])
+		pipe.ZAdd(r.ctx, key, redis.Z{Score: float64(at.Unix()), Member: strconv.FormatUint(uint64(pair[1]), 10)})
+		pipe.ZRemRangeByScore(r.ctx, key, "-inf", "("+cutoff)
+		pipe.Expire(r.ctx, key, ttl)
+	}
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// Forget drops a removed user's recent matches; entries naming them on
+// other users' sets expire on their own
+func (r *matchmakingRepository) Forget(userID uint) error {
+	return r.redis.Del(r.ctx, fmt.Sprintf(database.MatchRecentKey, userID)).Err()
+}
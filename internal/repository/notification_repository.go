@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository stores per-user notification preferences
+type NotificationRepository interface {
+	Upsert(pref *models.NotificationPreference) error
+	GetByUserID(userID uint) (*models.NotificationPreference, error)
+	Delete(userID uint) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Upsert(pref *models.NotificationPreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"events", "channels", "quiet_hours", "updated_at"}),
+	}).Create(pref).Error
+}
+
+func (r *notificationRepository) GetByUserID(userID uint) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	err := r.db.First(&pref, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (r *notificationRepository) Delete(userID uint) error {
+	return r.db.Delete(&models.NotificationPreference{}, "user_id = ?", userID).Error
+}
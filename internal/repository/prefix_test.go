@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const testPrefix = "tenant:acme:"
+
+// rejectedHook records the commands the key prefix hook refused, including
+// those of methods that only log Redis errors
+type rejectedHook struct {
+	mu       sync.Mutex
+	rejected []string
+}
+
+func (h *rejectedHook) record(cmd redis.Cmder) {
+	if errors.Is(cmd.Err(), database.ErrCommandNotPrefixed) {
+		h.mu.Lock()
+		h.rejected = append(h.rejected, cmd.Name())
+		h.mu.Unlock()
+	}
+}
+
+func (h *rejectedHook) take() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	rejected := h.rejected
+	h.rejected = nil
+	return rejected
+}
+
+func (h *rejectedHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *rejectedHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		h.record(cmd)
+		return err
+	}
+}
+
+func (h *rejectedHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		for _, cmd := range cmds {
+			h.record(cmd)
+		}
+		return err
+	}
+}
+
+// newPrefixedClient returns a client on miniredis whose keys are rewritten
+// under testPrefix, as a tenant's (or the sandbox's) client is
+func newPrefixedClient(t *testing.T) (*redis.Client, *miniredis.Miniredis, *rejectedHook) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	hook := &rejectedHook{}
+	client.AddHook(hook) // outermost, so it sees what the prefix hook refused
+	client.AddHook(database.NewKeyPrefixHook(testPrefix))
+	return client, mr, hook
+}
+
+type repoCall struct {
+	name string
+	call func() error
+}
+
+// ignore drops a method's results but its error
+func ignore[T any](_ T, err error) error { return err }
+
+func ignore2[A, B any](_ A, _ B, err error) error { return err }
+
+func ignore3[A, B, C any](_ A, _ B, _ C, err error) error { return err }
+
+// TestRepositoriesOnPrefixedClient runs every Redis repository method on a
+// prefixed client: each command they send must be one the prefix hook
+// knows, and every key they touch must land under the prefix
+func TestRepositoriesOnPrefixedClient(t *testing.T) {
+	client, mr, hook := newPrefixedClient(t)
+	now := time.Now()
+	today, yesterday := now.Format("2006-01-02"), now.AddDate(0, 0, -1).Format("2006-01-02")
+	users := []models.User{
+		{ID: 1, Username: "alice", Rating: 1500, Status: models.UserStatusActive},
+		{ID: 2, Username: "bob", Rating: 1400, Status: models.UserStatusActive},
+		{ID: 3, Username: "carol", Rating: 1300, Status: models.UserStatusShadowBanned},
+	}
+
+	board := NewLeaderboardRepository(client)
+	achievements := NewAchievementProgressRepository(client)
+	decay := NewDecayRepository(client)
+	matchmaking := NewMatchmakingRepository(client)
+	protection := NewProtectionRepository(client)
+	scheduler := NewSchedulerRepository(client)
+	streaks := NewStreakCacheRepository(client)
+	teams := NewTeamBoardRepository(client)
+	wsStats := NewWSStatsRepository(client)
+
+	calls := []repoCall{
+		{"leaderboard.AddUser", func() error { return board.AddUser(1, 1500) }},
+		{"leaderboard.RestoreUsers", func() error { return ignore(board.RestoreUsers(users, true)) }},
+		{"leaderboard.UpdateUserScore", func() error { return board.UpdateUserScore(2, 1450) }},
+		{"leaderboard.SetUserScore", func() error { return ignore(board.SetUserScore(2, 1400)) }},
+		{"leaderboard.GetUserRank", func() error { return ignore(board.GetUserRank(1)) }},
+		{"leaderboard.GetUserRanks", func() error { return ignore(board.GetUserRanks([]uint{1, 2})) }},
+		{"leaderboard.GetTopUsers", func() error { return ignore(board.GetTopUsers(10)) }},
+		{"leaderboard.GetNeighbors", func() error { return ignore(board.GetNeighbors(1, 2)) }},
+		{"leaderboard.GetRatingRange", func() error { return ignore(board.GetRatingRange(1000, 2000, 0, 10)) }},
+		{"leaderboard.CountRatingRange", func() error { return ignore(board.CountRatingRange(1000, 2000)) }},
+		{"leaderboard.GetUsersByRating", func() error { return ignore(board.GetUsersByRating(1500)) }},
+		{"leaderboard.GetLeaderboardSize", func() error { return ignore(board.GetLeaderboardSize()) }},
+		{"leaderboard.GetRatingBounds", func() error { return ignore3(board.GetRatingBounds()) }},
+		{"leaderboard.CacheUser", func() error { return board.CacheUser(&users[0]) }},
+		{"leaderboard.IndexUsername", func() error { return board.IndexUsername(1, "alice") }},
+		{"leaderboard.SearchUsernames", func() error { return ignore(board.SearchUsernames("al", 5)) }},
+		{"leaderboard.UnindexUsername", func() error { return board.UnindexUsername(1, "alice") }},
+		{"leaderboard.GetBoardScores", func() error { return ignore(board.GetBoardScores([]uint{1, 2})) }},
+		{"leaderboard.GetCachedUser", func() error { return ignore(board.GetCachedUser(1)) }},
+		{"leaderboard.GetUserSnapshot", func() error { return ignore(board.GetUserSnapshot(1)) }},
+		{"leaderboard.TrackUser", func() error { return board.TrackUser(1, 10) }},
+		{"leaderboard.GetTrackedUsers", func() error { return ignore(board.GetTrackedUsers(time.Now().Add(-time.Hour))) }},
+		{"leaderboard.GetBoardEntries", func() error { return ignore(board.GetBoardEntries([]uint{1, 2})) }},
+		{"leaderboard.SetShadowScore", func() error { return board.SetShadowScore(3, 1350) }},
+		{"leaderboard.GetShadowScore", func() error { return ignore(board.GetShadowScore(3)) }},
+		{"leaderboard.CountAbove", func() error { return ignore(board.CountAbove(1400)) }},
+		{"leaderboard.CountUpdate", func() error { return ignore(board.CountUpdate(1, time.Minute)) }},
+		{"leaderboard.RecordRatingChange", func() error { return board.RecordRatingChange(1, 50, time.Hour) }},
+		{"leaderboard.GetRecentRatingChange", func() error { return ignore(board.GetRecentRatingChange(1, time.Hour)) }},
+		{"leaderboard.GetCachedTimezone", func() error { return ignore(board.GetCachedTimezone(1)) }},
+		{"leaderboard.AddPeriodGain", func() error { return board.AddPeriodGain("daily:2024-03-10", 1, 50, time.Hour) }},
+		{"leaderboard.GetPeriodTop", func() error { return ignore(board.GetPeriodTop("daily:2024-03-10", 10)) }},
+		{"leaderboard.AddImprovedGain", func() error { return board.AddImprovedGain(1, 50, now, time.Hour) }},
+		{"leaderboard.GetMostImproved", func() error { return ignore(board.GetMostImproved(24*time.Hour, 10, time.Minute)) }},
+		{"leaderboard.LockUser", func() error { return ignore(board.LockUser(1, "token", time.Second)) }},
+		{"leaderboard.UnlockUser", func() error { return board.UnlockUser(1, "token") }},
+		{"leaderboard.GetAllScores", func() error { return ignore(board.GetAllScores(100)) }},
+		{"leaderboard.GetScores", func() error { return ignore(board.GetScores([]uint{1, 2})) }},
+		{"leaderboard.SetUncertainty", func() error { return board.SetUncertainty("glicko", 1, 350) }},
+		{"leaderboard.GetUncertainty", func() error { return ignore(board.GetUncertainty("glicko", 1)) }},
+		{"leaderboard.RecordBestRating", func() error { return ignore2(board.RecordBestRating(1, 1500, 1550)) }},
+		{"leaderboard.ClearBestRatings", func() error { return board.ClearBestRatings() }},
+		{"leaderboard.StageUsers", func() error { return ignore(board.StageUsers("stage", users)) }},
+		{"leaderboard.SwapStaged", func() error {
+			return board.SwapStaged("stage", database.LeaderboardKey, database.ShadowBoardKey, database.UsernameIndexKey)
+		}},
+		{"leaderboard.DropStaged", func() error { return board.DropStaged("stage", database.LeaderboardKey) }},
+		{"leaderboard.LockRebuild", func() error { return ignore(board.LockRebuild("token", time.Second)) }},
+		{"leaderboard.UnlockRebuild", func() error { return board.UnlockRebuild("token") }},
+		{"leaderboard.ArchiveBoard", func() error { return ignore(board.ArchiveBoard(now, time.Hour)) }},
+		{"leaderboard.SampleBoardUsers", func() error { return ignore(board.SampleBoardUsers(2)) }},
+		{"leaderboard.LockReconcile", func() error { return ignore(board.LockReconcile("token", time.Second)) }},
+		{"leaderboard.UnlockReconcile", func() error { return board.UnlockReconcile("token") }},
+		{"leaderboard.SaveReconcileReport", func() error { return board.SaveReconcileReport(&models.ReconcileReport{Node: "a"}) }},
+		{"leaderboard.GetReconcileReport", func() error { return ignore(board.GetReconcileReport()) }},
+		{"leaderboard.RemoveUser", func() error { return board.RemoveUser(2) }},
+
+		{"achievements.RecordResult", func() error { return ignore(achievements.RecordResult(1, 10)) }},
+		{"achievements.MarkUnlocked", func() error { return ignore(achievements.MarkUnlocked(1, "first_win")) }},
+		{"achievements.UnmarkUnlocked", func() error { return achievements.UnmarkUnlocked(1, "first_win") }},
+		{"achievements.Forget", func() error { return achievements.Forget(1) }},
+
+		{"decay.Touch", func() error { return decay.Touch(1, now.Add(-48*time.Hour)) }},
+		{"decay.Backfill", func() error {
+			return decay.Backfill([]models.UserActivity{{UserID: 1, LastActive: now.Add(-48 * time.Hour)}})
+		}},
+		{"decay.CountActivity", func() error { return ignore(decay.CountActivity()) }},
+		{"decay.GetInactive", func() error { return ignore(decay.GetInactive(now, 0, 10)) }},
+		{"decay.Apply", func() error {
+			return ignore(decay.Apply("stream:score_updates", []DecayTarget{{UserID: 1, Floor: 1000, EventID: "e1"}}, 10, now))
+		}},
+		{"decay.Forget", func() error { return decay.Forget(1) }},
+
+		{"matchmaking.GetNearest", func() error { return ignore(matchmaking.GetNearest(1500, 100, 5)) }},
+		{"matchmaking.RecordMatch", func() error { return matchmaking.RecordMatch(1, 2, now, time.Hour) }},
+		{"matchmaking.GetRecentOpponents", func() error { return ignore(matchmaking.GetRecentOpponents(1, now.Add(-time.Hour))) }},
+		{"matchmaking.Forget", func() error { return matchmaking.Forget(1) }},
+
+		{"protection.Mark", func() error { return protection.Mark(&models.ProtectionMark{MarkedAt: now, Actor: "admin"}) }},
+		{"protection.GetMark", func() error { return ignore(protection.GetMark()) }},
+		{"protection.GetFloor", func() error { return ignore2(protection.GetFloor(1)) }},
+		{"protection.Clear", func() error { return protection.Clear() }},
+
+		{"scheduler.Claim", func() error { return ignore(scheduler.Claim("decay", now)) }},
+		{"scheduler.SaveRun", func() error { return scheduler.SaveRun(&models.ScheduledRun{Job: "decay", Node: "a"}) }},
+		{"scheduler.LastRuns", func() error { return ignore(scheduler.LastRuns()) }},
+
+		{"streaks.Seed", func() error { return streaks.Seed(&models.Streak{UserID: 1, Current: 2, LastDay: yesterday}) }},
+		{"streaks.Advance", func() error { return ignore2(streaks.Advance(1, today, yesterday)) }},
+		{"streaks.Get", func() error { return ignore(streaks.Get(1)) }},
+		{"streaks.Forget", func() error { return streaks.Forget(1) }},
+
+		{"teams.AddMember", func() error { return teams.AddMember(10, 1) }},
+		{"teams.GetUserTeam", func() error { return ignore2(teams.GetUserTeam(1)) }},
+		{"teams.Recompute", func() error { return ignore(teams.Recompute(10, true)) }},
+		{"teams.GetTopTeams", func() error { return ignore(teams.GetTopTeams(10)) }},
+		{"teams.GetStanding", func() error { return ignore2(teams.GetStanding(10)) }},
+		{"teams.Resync", func() error { return teams.Resync(map[uint][]uint{10: {1}, 11: {3}}) }},
+		{"teams.RemoveMember", func() error { return teams.RemoveMember(10, 1) }},
+		{"teams.RemoveTeam", func() error { return teams.RemoveTeam(10) }},
+
+		{"wsStats.Heartbeat", func() error {
+			return wsStats.Heartbeat(models.InstanceConnections{Node: "a", Clients: 3, UpdatedAt: now}, time.Minute)
+		}},
+		{"wsStats.List", func() error { return ignore(wsStats.List()) }},
+		{"wsStats.Remove", func() error { return wsStats.Remove("a") }},
+	}
+
+	for _, c := range calls {
+		err := c.call()
+		if rejected := hook.take(); len(rejected) > 0 || errors.Is(err, database.ErrCommandNotPrefixed) {
+			t.Errorf("%s: commands refused on a prefixed client: %v (%v)", c.name, rejected, err)
+		}
+	}
+
+	for _, key := range mr.Keys() {
+		if !strings.HasPrefix(key, testPrefix) {
+			t.Errorf("key %q written outside the prefix", key)
+		}
+	}
+}
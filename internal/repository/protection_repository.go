@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// ProtectionRepository stores the rollback protection mark and the ratings
+// snapshotted with it
+type ProtectionRepository interface {
+	Mark(mark *models.ProtectionMark) error
+	GetMark() (*models.ProtectionMark, error)
+	GetFloor(userID uint) (int, bool, error)
+	Clear() error
+}
+
+type protectionRepository struct {
+	redis *redis.Client
+	ctx   context.Context
+}
+
+func NewProtectionRepository(redisClient *redis.Client) ProtectionRepository {
+	return &protectionRepository{
+		redis: redisClient,
+		ctx:   database.Ctx,
+	}
+}
+
+// Mark snapshots every rating (public and shadow boards) server-side and
+// stores the mark, replacing any previous one. mark.Users is filled in.
+func (r *protectionRepository) Mark(mark *models.ProtectionMark) error {
+	users, err := r.redis.ZUnionStore(r.ctx, database.ProtectionFloorKey, &redis.ZStore{
+		Keys:      []string{database.LeaderboardKey, database.ShadowBoardKey},
+		Aggregate: "MAX",
+	}).Result()
+	if err != nil {
+		return err
+	}
+	mark.Users = users
+
+	data, err := json.Marshal(mark)
+	if err != nil {
+		return err
+	}
+	return r.redis.Set(r.ctx, database.ProtectionMarkKey, data, 0).Err()
+}
+
+// GetMark returns the active mark, or nil when protection is off
+func (r *protectionRepository) GetMark() (*models.ProtectionMark, error) {
+	data, err := r.redis.Get(r.ctx, database.ProtectionMarkKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var mark models.ProtectionMark
+	if err := json.Unmarshal(data, &mark); err != nil {
+		return nil, err
+	}
+	return &mark, nil
+}
+
+// GetFloor returns a user's rating at the mark (false if they had none)
+func (r *protectionRepository) GetFloor(userID uint) (int, bool, error) {
+	score, err := r.redis.ZScore(r.ctx, database.ProtectionFloorKey, fmt.Sprintf("user:%d", userID)).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return int(score), true, nil
+}
+
+func (r *protectionRepository) Clear() error {
+	return r.redis.Del(r.ctx, database.ProtectionMarkKey, database.ProtectionFloorKey).Err()
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// RankHistoryRepository stores periodic rank snapshots
+type RankHistoryRepository interface {
+	CreateBatch(entries []models.RankHistory) error
+	GetByUserSince(userID uint, since time.Time) ([]models.RankHistory, error)
+	GetFirstSince(userID uint, since time.Time) (*models.RankHistory, error)
+	DeleteOlderThan(cutoff time.Time) (int64, error)
+}
+
+type rankHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewRankHistoryRepository(db *gorm.DB) RankHistoryRepository {
+	return &rankHistoryRepository{db: db}
+}
+
+func (r *rankHistoryRepository) CreateBatch(entries []models.RankHistory) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(entries, 500).Error
+}
+
+func (r *rankHistoryRepository) GetByUserSince(userID uint, since time.Time) ([]models.RankHistory, error) {
+	var entries []models.RankHistory
+	err := replica(r.db).Where("user_id = ? AND captured_at >= ?", userID, since).
+		Order("captured_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// GetFirstSince returns the earliest snapshot for a user at or after since
+func (r *rankHistoryRepository) GetFirstSince(userID uint, since time.Time) (*models.RankHistory, error) {
+	var entry models.RankHistory
+	err := replica(r.db).Where("user_id = ? AND captured_at >= ?", userID, since).
+		Order("captured_at ASC").
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *rankHistoryRepository) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result := r.db.Where("captured_at < ?", cutoff).Delete(&models.RankHistory{})
+	return result.RowsAffected, result.Error
+}
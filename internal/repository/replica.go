@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// replica routes a read-only query to the PostgreSQL read replicas (the
+// primary when none are configured). Replicas lag the primary, so only
+// reads that tolerate slightly stale rows use it: search, exports and
+// history, never anything a write or the DB sync depends on.
+func replica(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Use(database.ReplicaResolver))
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Claims outlive any clock skew between servers by far; the key only has
+// to remember the latest occurrence taken
+const schedulerClaimTTL = 7 * 24 * time.Hour
+
+// Takes the occurrence ARGV[1] (unix seconds) of a job unless it or a later
+// one was already taken. Returns 1 when claimed.
+var claimScript = redis.NewScript(`
+local last = redis.call("GET", KEYS[1])
+if last and tonumber(last) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "EX", ARGV[2])
+return 1
+`)
+
+// SchedulerRepository claims scheduled job occurrences and keeps their
+// last runs in Redis; it is the scheduler.Store of a tenant
+type SchedulerRepository interface {
+	Claim(job string, at time.Time) (bool, error)
+	SaveRun(run *models.ScheduledRun) error
+	LastRuns() (map[string]*models.ScheduledRun, error)
+}
+
+type schedulerRepository struct {
+	redis *redis.Client
+	ctx   context.Context
+}
+
+func NewSchedulerRepository(redisClient *redis.Client) SchedulerRepository {
+	return &schedulerRepository{
+		redis: redisClient,
+		ctx:   database.Ctx,
+	}
+}
+
+func (r *schedulerRepository) Claim(job string, at time.Time) (bool, error) {
+	claimed, err := claimScript.Run(r.ctx, r.redis,
+		[]string{fmt.Sprintf(database.SchedulerClaimKey, job)},
+		at.Unix(), int(schedulerClaimTTL.Seconds())).Int()
+	return claimed == 1, err
+}
+
+// SaveRun replaces the job's last run
+func (r *schedulerRepository) SaveRun(run *models.ScheduledRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return r.redis.HSet(r.ctx, database.SchedulerRunsKey, run.Job, data).Err()
+}
+
+// LastRuns returns the last run of every job that has run, by job name
+func (r *schedulerRepository) LastRuns() (map[string]*models.ScheduledRun, error) {
+	values, err := r.redis.HGetAll(r.ctx, database.SchedulerRunsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	runs := make(map[string]*models.ScheduledRun, len(values))
+	for job, data := range values {
+		var run models.ScheduledRun
+		if err := json.Unmarshal([]byte(data), &run); err != nil {
+			continue
+		}
+		runs[job] = &run
+	}
+	return runs, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Counts ARGV[1] (today) for a user whose last counted day was ARGV[2]
+// (yesterday) or earlier. Returns {current, longest, advanced}, or nil when
+// the hash is missing and has to be seeded from PostgreSQL first.
+var streakScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return false
+end
+local last = redis.call("HGET", KEYS[1], "last")
+local current = tonumber(redis.call("HGET", KEYS[1], "current") or "0")
+local longest = tonumber(redis.call("HGET", KEYS[1], "longest") or "0")
+if last == ARGV[1] then
+	return {current, longest, 0}
+end
+if last == ARGV[2] then
+	current = current + 1
+else
+	current = 1
+end
+if current > longest then
+	longest = current
+end
+redis.call("HSET", KEYS[1], "current", current, "longest", longest, "last", ARGV[1])
+return {current, longest, 1}
+`)
+
+// StreakCacheRepository keeps each user's streak in a Redis hash, so
+// counting a day needs no PostgreSQL read
+type StreakCacheRepository interface {
+	Advance(userID uint, today, yesterday string) (streak *models.Streak, advanced bool, err error)
+	Get(userID uint) (*models.Streak, error)
+	Seed(streak *models.Streak) error
+	Forget(userID uint) error
+}
+
+type streakCacheRepository struct {
+	redis *redis.Client
+	ctx   context.Context
+}
+
+func NewStreakCacheRepository(redisClient *redis.Client) StreakCacheRepository {
+	return &streakCacheRepository{
+		redis: redisClient,
+		ctx:   database.Ctx,
+	}
+}
+
+// Advance counts today; streak is nil when the user has to be seeded
+func (r *streakCacheRepository) Advance(userID uint, today, yesterday string) (*models.Streak, bool, error) {
+	values, err := streakScript.Run(r.ctx, r.redis,
+		[]string{fmt.Sprintf(database.StreakKey, userID)}, today, yesterday).Int64Slice()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil || len(values) != 3 {
+		return nil, false, err
+	}
+	return &models.Streak{
+		UserID:  userID,
+		Current: int(values[0]),
+		Longest: int(values[1]),
+		LastDay: today,
+	}, values[2] == 1, nil
+}
+
+// Get returns the cached streak, or nil when there is none
+func (r *streakCacheRepository) Get(userID uint) (*models.Streak, error) {
+	values, err := r.redis.HGetAll(r.ctx, fmt.Sprintf(database.StreakKey, userID)).Result()
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	current, _ := strconv.Atoi(values["current"])
+	longest, _ := strconv.Atoi(values["longest"])
+	return &models.Streak{
+		UserID:  userID,
+		Current: current,
+		Longest: longest,
+		LastDay: values["last"],
+	}, nil
+}
+
+// Seed caches a streak read from PostgreSQL unless an update cached one
+// meanwhile
+func (r *streakCacheRepository) Seed(streak *models.Streak) error {
+	return cacheIfMissingScript.Run(r.ctx, r.redis,
+		[]string{fmt.Sprintf(database.StreakKey, streak.UserID)},
+		"current", streak.Current, "longest", streak.Longest, "last", streak.LastDay).Err()
+}
+
+// Forget drops a removed user's cached streak
+func (r *streakCacheRepository) Forget(userID uint) error {
+	return r.redis.Del(r.ctx, fmt.Sprintf(database.StreakKey, userID)).Err()
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StreakRepository stores daily improvement streaks
+type StreakRepository interface {
+	Get(userID uint) (*models.Streak, error)
+	Upsert(streak *models.Streak) error
+}
+
+type streakRepository struct {
+	db *gorm.DB
+}
+
+func NewStreakRepository(db *gorm.DB) StreakRepository {
+	return &streakRepository{db: db}
+}
+
+// Get returns a user's streak; a user who never had one gets a zero streak
+func (r *streakRepository) Get(userID uint) (*models.Streak, error) {
+	streak := models.Streak{UserID: userID}
+	err := r.db.Where("user_id = ?", userID).First(&streak).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.Streak{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &streak, nil
+}
+
+// Upsert inserts or overwrites a user's streak
+func (r *streakRepository) Upsert(streak *models.Streak) error {
+	return r.db.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"current", "longest", "last_day", "updated_at"}),
+	}).Create(streak).Error
+}
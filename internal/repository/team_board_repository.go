@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Recomputes a team's score from its members' current ratings in one step,
+// so concurrent updates of two members cannot both write a stale total.
+// Members off the public board (banned, shadow-banned, deleted) don't count.
+var teamScoreScript = redis.NewScript(`
+local members = redis.call("SMEMBERS", KEYS[1])
+local sum, count = 0, 0
+for _, id in ipairs(members) do
+	local score = redis.call("ZSCORE", KEYS[2], "user:" .. id)
+	if score then
+		sum = sum + tonumber(score)
+		count = count + 1
+	end
+end
+local value = sum
+if ARGV[2] == "1" then
+	value = 0
+	if count > 0 then
+		value = math.floor(sum / count * 100 + 0.5) / 100
+	end
+end
+redis.call("ZADD", KEYS[3], value, ARGV[1])
+return tostring(value)
+`)
+
+// TeamBoardRepository keeps the team leaderboard and the team membership
+// index in Redis
+type TeamBoardRepository interface {
+	AddMember(teamID, userID uint) error
+	RemoveMember(teamID, userID uint) error
+	GetUserTeam(userID uint) (uint, bool, error)
+	Recompute(teamID uint, average bool) (float64, error)
+	RemoveTeam(teamID uint) error
+	GetTopTeams(limit int) ([]models.TeamEntry, error)
+	GetStanding(teamID uint) (score float64, rank int64, err error)
+	Resync(members map[uint][]uint) error
+}
+
+type teamBoardRepository struct {
+	redis *redis.Client
+	ctx   context.Context
+}
+
+func NewTeamBoardRepository(redisClient *redis.Client) TeamBoardRepository {
+	return &teamBoardRepository{
+		redis: redisClient,
+		ctx:   database.Ctx,
+	}
+}
+
+func teamMember(teamID uint) string {
+	return fmt.Sprintf("team:%d", teamID)
+}
+
+func (r *teamBoardRepository) AddMember(teamID, userID uint) error {
+	pipe := r.redis.TxPipeline()
+	pipe.SAdd(r.ctx, fmt.Sprintf(database.TeamMembersKey, teamID), userID)
+	pipe.HSet(r.ctx, database.UserTeamKey, strconv.FormatUint(uint64(userID), 10), teamID)
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+func (r *teamBoardRepository) RemoveMember(teamID, userID uint) error {
+	pipe := r.redis.TxPipeline()
+	pipe.SRem(r.ctx, fmt.Sprintf(database.TeamMembersKey, teamID), userID)
+	pipe.HDel(r.ctx, database.UserTeamKey, strconv.FormatUint(uint64(userID), 10))
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// GetUserTeam returns the team of a user; false when they are in none
+func (r *teamBoardRepository) GetUserTeam(userID uint) (uint, bool, error) {
+	value, err := r.redis.HGet(r.ctx, database.UserTeamKey, strconv.FormatUint(uint64(userID), 10)).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	teamID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(teamID), true, nil
+}
+
+// Recompute sets a team's score to the sum (or average) of its ranked
+// members' ratings and returns it
+func (r *teamBoardRepository) Recompute(teamID uint, average bool) (float64, error) {
+	aggregate := "0"
+	if average {
+		aggregate = "1"
+	}
+	value, err := teamScoreScript.Run(r.ctx, r.redis,
+		[]string{fmt.Sprintf(database.TeamMembersKey, teamID), database.LeaderboardKey, database.TeamBoardKey},
+		teamMember(teamID), aggregate).Text()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// RemoveTeam drops a team from the board and forgets its members
+func (r *teamBoardRepository) RemoveTeam(teamID uint) error {
+	key := fmt.Sprintf(database.TeamMembersKey, teamID)
+	members, err := r.redis.SMembers(r.ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	pipe := r.redis.TxPipeline()
+	if len(members) > 0 {
+		pipe.HDel(r.ctx, database.UserTeamKey, members...)
+	}
+	pipe.Del(r.ctx, key)
+	pipe.ZRem(r.ctx, database.TeamBoardKey, teamMember(teamID))
+	_, err = pipe.Exec(r.ctx)
+	return err
+}
+
+// GetTopTeams returns the top teams with tie-aware ranks; names and member
+// counts are left to the caller
+func (r *teamBoardRepository) GetTopTeams(limit int) ([]models.TeamEntry, error) {
+	results, err := r.redis.ZRevRangeWithScores(r.ctx, database.TeamBoardKey, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.TeamEntry, 0, len(results))
+	currentRank := int64(1)
+	var previousScore float64
+
+	for i, z := range results {
+		if i > 0 && z.Score != previousScore {
+			currentRank = int64(i) + 1
+		}
+
+		teamIDStr := strings.TrimPrefix(z.Member.(string), "team:")
+		teamID, _ := strconv.ParseUint(teamIDStr, 10, 32)
+
+		entries = append(entries, models.TeamEntry{
+			Rank:   currentRank,
+			TeamID: uint(teamID),
+			Score:  z.Score,
+		})
+
+		previousScore = z.Score
+	}
+
+	return entries, nil
+}
+
+// GetStanding returns a team's score and tie-aware rank; rank 0 when the
+// team is not on the board
+func (r *teamBoardRepository) GetStanding(teamID uint) (float64, int64, error) {
+	score, err := r.redis.ZScore(r.ctx, database.TeamBoardKey, teamMember(teamID)).Result()
+	if err == redis.Nil {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	higher, err := r.redis.ZCount(r.ctx, database.TeamBoardKey, fmt.Sprintf("(%f", score), "+inf").Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	return score, higher + 1, nil
+}
+
+// Resync replaces the membership index with members (team -> user IDs, as
+// stored in PostgreSQL) and drops teams that no longer exist from the
+// board. Each team's set is swapped atomically, so readers never see it
+// empty; scores are left to Recompute.
+func (r *teamBoardRepository) Resync(members map[uint][]uint) error {
+	// Teams on the board that are gone
+	onBoard, err := r.redis.ZRange(r.ctx, database.TeamBoardKey, 0, -1).Result()
+	if err != nil {
+		return err
+	}
+	var stale []interface{}
+	for _, member := range onBoard {
+		teamID, _ := strconv.ParseUint(strings.TrimPrefix(member, "team:"), 10, 32)
+		if _, ok := members[uint(teamID)]; !ok {
+			stale = append(stale, member)
+			if err := r.redis.Del(r.ctx, fmt.Sprintf(database.TeamMembersKey, teamID)).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(stale) > 0 {
+		if err := r.redis.ZRem(r.ctx, database.TeamBoardKey, stale...).Err(); err != nil {
+			return err
+		}
+	}
+
+	// Users mapped to a team they are no longer in
+	indexed, err := r.redis.HGetAll(r.ctx, database.UserTeamKey).Result()
+	if err != nil {
+		return err
+	}
+	want := make(map[string]bool)
+	for _, userIDs := range members {
+		for _, userID := range userIDs {
+			want[strconv.FormatUint(uint64(userID), 10)] = true
+		}
+	}
+	var gone []string
+	for userID := range indexed {
+		if !want[userID] {
+			gone = append(gone, userID)
+		}
+	}
+	if len(gone) > 0 {
+		if err := r.redis.HDel(r.ctx, database.UserTeamKey, gone...).Err(); err != nil {
+			return err
+		}
+	}
+
+	for teamID, userIDs := range members {
+		key := fmt.Sprintf(database.TeamMembersKey, teamID)
+		pipe := r.redis.TxPipeline()
+		pipe.Del(r.ctx, key)
+		if len(userIDs) > 0 {
+			ids := make([]interface{}, len(userIDs))
+			mapping := make([]interface{}, 0, 2*len(userIDs))
+			for i, userID := range userIDs {
+				ids[i] = userID
+				mapping = append(mapping, userID, teamID)
+			}
+			pipe.SAdd(r.ctx, key, ids...)
+			pipe.HSet(r.ctx, database.UserTeamKey, mapping...)
+		}
+		if _, err := pipe.Exec(r.ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
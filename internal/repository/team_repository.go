@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TeamRepository stores teams and their memberships
+type TeamRepository interface {
+	Create(team *models.Team) error
+	Save(team *models.Team) error
+	GetByID(id uint) (*models.Team, error)
+	GetByName(name string) (*models.Team, error)
+	GetByIDs(ids []uint) ([]models.Team, error)
+	ListIDs() ([]uint, error)
+	Delete(id uint) (bool, error)
+
+	AddMember(membership *models.TeamMembership, maxMembers int) (bool, error)
+	RemoveMember(teamID, userID uint) (bool, error)
+	GetMembership(userID uint) (*models.TeamMembership, error)
+	ListMembers(teamID uint) ([]models.TeamMember, error)
+	CountMembers(teamIDs []uint) (map[uint]int64, error)
+	ListMemberships() ([]models.TeamMembership, error)
+}
+
+type teamRepository struct {
+	db *gorm.DB
+}
+
+func NewTeamRepository(db *gorm.DB) TeamRepository {
+	return &teamRepository{db: db}
+}
+
+func (r *teamRepository) Create(team *models.Team) error {
+	return r.db.Create(team).Error
+}
+
+func (r *teamRepository) Save(team *models.Team) error {
+	return r.db.Save(team).Error
+}
+
+func (r *teamRepository) GetByID(id uint) (*models.Team, error) {
+	var team models.Team
+	if err := r.db.First(&team, id).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// GetByName looks a team up by name, ignoring case
+func (r *teamRepository) GetByName(name string) (*models.Team, error) {
+	var team models.Team
+	if err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&team).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *teamRepository) GetByIDs(ids []uint) ([]models.Team, error) {
+	var teams []models.Team
+	if len(ids) == 0 {
+		return teams, nil
+	}
+	err := replica(r.db).Where("id IN ?", ids).Find(&teams).Error
+	return teams, err
+}
+
+func (r *teamRepository) ListIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Team{}).Order("id ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Delete removes a team and (by cascade) its memberships; false when there
+// was no such team
+func (r *teamRepository) Delete(id uint) (bool, error) {
+	result := r.db.Delete(&models.Team{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// AddMember inserts a membership unless the team already has maxMembers
+// members (0 = no limit); false when it is full. The team row is locked so
+// concurrent joins cannot overfill it.
+func (r *teamRepository) AddMember(membership *models.TeamMembership, maxMembers int) (bool, error) {
+	added := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var team models.Team
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&team, membership.TeamID).Error; err != nil {
+			return err
+		}
+
+		if maxMembers > 0 {
+			var members int64
+			err := tx.Model(&models.TeamMembership{}).
+				Joins("JOIN users ON users.id = team_memberships.user_id AND users.deleted_at IS NULL").
+				Where("team_memberships.team_id = ?", membership.TeamID).
+				Count(&members).Error
+			if err != nil || members >= int64(maxMembers) {
+				return err
+			}
+		}
+
+		if err := tx.Omit("Team", "User").Create(membership).Error; err != nil {
+			return err
+		}
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+// RemoveMember deletes a membership; false when the user was not in the team
+func (r *teamRepository) RemoveMember(teamID, userID uint) (bool, error) {
+	result := r.db.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMembership{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *teamRepository) GetMembership(userID uint) (*models.TeamMembership, error) {
+	var membership models.TeamMembership
+	if err := r.db.Where("user_id = ?", userID).First(&membership).Error; err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// ListMembers returns a team's visible members (not deleted or banned),
+// highest rated first
+func (r *teamRepository) ListMembers(teamID uint) ([]models.TeamMember, error) {
+	var members []models.TeamMember
+	err := replica(r.db).Table("team_memberships").
+		Select("users.id AS user_id, users.username, users.rating, team_memberships.joined_at").
+		Joins("JOIN users ON users.id = team_memberships.user_id AND users.deleted_at IS NULL AND users.status = ?", models.UserStatusActive).
+		Where("team_memberships.team_id = ?", teamID).
+		Order("users.rating DESC, users.username ASC").
+		Scan(&members).Error
+	return members, err
+}
+
+// CountMembers returns the number of visible members of each team
+func (r *teamRepository) CountMembers(teamIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(teamIDs))
+	if len(teamIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		TeamID  uint
+		Members int64
+	}
+	err := replica(r.db).Table("team_memberships").
+		Select("team_memberships.team_id, COUNT(*) AS members").
+		Joins("JOIN users ON users.id = team_memberships.user_id AND users.deleted_at IS NULL AND users.status = ?", models.UserStatusActive).
+		Where("team_memberships.team_id IN ?", teamIDs).
+		Group("team_memberships.team_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.TeamID] = row.Members
+	}
+	return counts, nil
+}
+
+// ListMemberships returns every membership (team and user IDs only)
+func (r *teamRepository) ListMemberships() ([]models.TeamMembership, error) {
+	var memberships []models.TeamMembership
+	err := r.db.Select("team_id", "user_id").Order("team_id ASC").Find(&memberships).Error
+	return memberships, err
+}
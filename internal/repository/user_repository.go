@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
 type UserRepository interface {
@@ -11,11 +15,25 @@ type UserRepository interface {
 	GetByUsername(username string) (*models.User, error)
 	Update(user *models.User) error
 	UpdateRating(userID uint, newRating int) error
+	ResetRatings(rating int) (int64, error)
+	Delete(id uint) error
+	Purge(id uint) error
 	GetAll(limit, offset int) ([]models.User, error)
 	Count() (int64, error)
-	SearchByUsername(query string, limit int) ([]models.User, error)
+	AverageRating() (float64, error)
+	UpdateStatus(userID uint, status string) error
+	SearchByUsername(query string, limit int, viewerID uint, threshold, weight float64) ([]models.UserMatch, error)
 	GetTopUsers(limit int) ([]models.User, error)
 	GetRandomUserID() (uint, error)
+	GetRandomUserIDs(n int) ([]uint, error)
+	GetBoardRatings(afterID uint, limit int) ([]models.User, error)
+	GetBoardRatingsByIDs(ids []uint) ([]models.User, error)
+	GetRankedTop(limit int) ([]models.LeaderboardEntry, error)
+	CountRatedAbove(rating int) (int64, error)
+	GetCachePage(afterID uint, limit int) ([]models.User, error)
+	GetCacheUsersByIDs(ids []uint) ([]models.User, error)
+	GetIDRange() (uint, uint, error)
+	GetLastActivity(afterID uint, limit int) ([]models.UserActivity, error)
 }
 
 type userRepository struct {
@@ -58,9 +76,59 @@ func (r *userRepository) UpdateRating(userID uint, newRating int) error {
 		Update("rating", newRating).Error
 }
 
+// ResetRatings sets every user's rating and returns how many changed
+func (r *userRepository) ResetRatings(rating int) (int64, error) {
+	result := r.db.Model(&models.User{}).
+		Where("rating <> ?", rating).
+		Update("rating", rating)
+	return result.RowsAffected, result.Error
+}
+
+// Delete soft-deletes a user (score history is kept)
+func (r *userRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge hard-deletes a user (including soft-deleted ones) and every row
+// that references them, in one transaction
+func (r *userRepository) Purge(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		dependents := []interface{}{
+			&models.DigestSubscription{},
+			&models.RankHistory{},
+			&models.ScoreUpdateDaily{},
+			&models.ScoreUpdate{},
+			&models.AdminAdjustment{},
+			&models.DeferredScoreUpdate{},
+			&models.TeamMembership{},
+		}
+		for _, model := range dependents {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().Delete(&models.User{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
 func (r *userRepository) GetAll(limit, offset int) ([]models.User, error) {
 	var users []models.User
-	err := r.db.Order("rating DESC, username ASC").
+	err := replica(r.db).Order("rating DESC, username ASC").
 		Limit(limit).
 		Offset(offset).
 		Find(&users).Error
@@ -73,17 +141,48 @@ func (r *userRepository) Count() (int64, error) {
 	return count, err
 }
 
-// SearchByUsername uses PostgreSQL trigram similarity for fuzzy search
-func (r *userRepository) SearchByUsername(query string, limit int) ([]models.User, error) {
-	var users []models.User
+func (r *userRepository) AverageRating() (float64, error) {
+	var avg float64
+	err := r.db.Model(&models.User{}).
+		Select("COALESCE(AVG(rating), 0)").
+		Scan(&avg).Error
+	return avg, err
+}
 
-	// Use ILIKE for case-insensitive search with trigram index
-	err := r.db.Where("username ILIKE ?", "%"+query+"%").
-		Order("rating DESC").
-		Limit(limit).
-		Find(&users).Error
+func (r *userRepository) UpdateStatus(userID uint, status string) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("status", status).Error
+}
 
-	return users, err
+// SearchByUsername uses PostgreSQL trigram similarity for fuzzy search.
+// A username matches if it contains the query (ILIKE) or its similarity
+// reaches threshold; both use the trigram index. Matches are ordered by
+// weight x similarity + (1 - weight) x rating relative to the best rated
+// match, so a close name beats an unrelated high rating.
+// Banned users are excluded; shadow-banned users only find themselves.
+func (r *userRepository) SearchByUsername(query string, limit int, viewerID uint, threshold, weight float64) ([]models.UserMatch, error) {
+	var matches []models.UserMatch
+
+	err := replica(r.db).Clauses(dbresolver.Read).Transaction(func(tx *gorm.DB) error {
+		// The % operator matches at pg_trgm.similarity_threshold; SET takes
+		// no parameters, and threshold is a validated float
+		if err := tx.Exec(fmt.Sprintf("SET LOCAL pg_trgm.similarity_threshold = %g", threshold)).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).
+			Select(`users.*, similarity(username, ?) AS similarity,
+				? * similarity(username, ?) + ? * rating::float8 / MAX(rating) OVER () AS relevance`,
+				query, weight, query, 1-weight).
+			Where("username ILIKE ? OR username % ?", "%"+query+"%", query).
+			Where("status = ? OR id = ?", models.UserStatusActive, viewerID).
+			Order("relevance DESC, rating DESC, id").
+			Limit(limit).
+			Find(&matches).Error
+	})
+
+	return matches, err
 }
 
 func (r *userRepository) GetTopUsers(limit int) ([]models.User, error) {
@@ -98,6 +197,7 @@ func (r *userRepository) GetTopUsers(limit int) ([]models.User, error) {
 func (r *userRepository) GetRandomUserID() (uint, error) {
 	var user models.User
 	err := r.db.Order("RANDOM()").
+		Where("status = ?", models.UserStatusActive).
 		Select("id").
 		First(&user).Error
 	if err != nil {
@@ -110,6 +210,12 @@ func (r *userRepository) GetRandomUserID() (uint, error) {
 type ScoreUpdateRepository interface {
 	Create(update *models.ScoreUpdate) error
 	GetByUserID(userID uint, limit int) ([]models.ScoreUpdate, error)
+	CountSince(since time.Time) (int64, error)
+	GetByUserSince(userID uint, since time.Time) ([]models.ScoreUpdate, error)
+	OldestBefore(cutoff time.Time) (*time.Time, error)
+	CompactDay(day time.Time) (int64, error)
+	GetDailyByUserSince(userID uint, since time.Time) ([]models.ScoreUpdateDaily, error)
+	GetBandUpdatesSince(since time.Time, excludeUserID uint, minRating, maxRating, limit int) ([]models.ScoreUpdate, error)
 }
 
 type scoreUpdateRepository struct {
@@ -126,9 +232,218 @@ func (r *scoreUpdateRepository) Create(update *models.ScoreUpdate) error {
 
 func (r *scoreUpdateRepository) GetByUserID(userID uint, limit int) ([]models.ScoreUpdate, error) {
 	var updates []models.ScoreUpdate
-	err := r.db.Where("user_id = ?", userID).
+	err := replica(r.db).Where("user_id = ?", userID).
 		Order("updated_at DESC").
 		Limit(limit).
 		Find(&updates).Error
 	return updates, err
 }
+
+func (r *scoreUpdateRepository) CountSince(since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ScoreUpdate{}).
+		Where("updated_at >= ?", since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *scoreUpdateRepository) GetByUserSince(userID uint, since time.Time) ([]models.ScoreUpdate, error) {
+	var updates []models.ScoreUpdate
+	err := replica(r.db).Where("user_id = ? AND updated_at >= ?", userID, since).
+		Order("updated_at ASC, id ASC").
+		Find(&updates).Error
+	return updates, err
+}
+
+// GetBandUpdatesSince returns, oldest first, every update since the given
+// time of the active users (other than excludeUserID) that had a rating
+// within [minRating, maxRating] at some point since then
+func (r *scoreUpdateRepository) GetBandUpdatesSince(since time.Time, excludeUserID uint, minRating, maxRating, limit int) ([]models.ScoreUpdate, error) {
+	var updates []models.ScoreUpdate
+	err := replica(r.db).Raw(`
+		SELECT su.*
+		FROM score_updates su
+		JOIN users u ON u.id = su.user_id
+		WHERE su.updated_at >= ?
+			AND su.user_id <> ?
+			AND u.status = ?
+			AND u.deleted_at IS NULL
+			AND su.user_id IN (
+				SELECT user_id FROM score_updates
+				WHERE updated_at >= ?
+					AND LEAST(old_rating, new_rating) <= ?
+					AND GREATEST(old_rating, new_rating) >= ?
+			)
+		ORDER BY su.updated_at ASC, su.id ASC
+		LIMIT ?
+	`, since, excludeUserID, models.UserStatusActive, since, maxRating, minRating, limit).
+		Scan(&updates).Error
+	return updates, err
+}
+
+// OldestBefore returns the timestamp of the oldest raw update before cutoff (nil if none)
+func (r *scoreUpdateRepository) OldestBefore(cutoff time.Time) (*time.Time, error) {
+	var oldest *time.Time
+	err := r.db.Model(&models.ScoreUpdate{}).
+		Select("MIN(updated_at)").
+		Where("updated_at < ?", cutoff).
+		Scan(&oldest).Error
+	return oldest, err
+}
+
+// CompactDay rolls one UTC day of raw updates into score_update_daily and
+// deletes the raw rows, atomically. Re-running merges into existing rollups.
+func (r *scoreUpdateRepository) CompactDay(day time.Time) (int64, error) {
+	start := day.UTC().Truncate(24 * time.Hour)
+	end := start.Add(24 * time.Hour)
+
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO score_update_daily
+				(user_id, day, open_rating, close_rating, min_rating, max_rating, update_count)
+			SELECT
+				user_id,
+				?::date,
+				(array_agg(old_rating ORDER BY updated_at ASC, id ASC))[1],
+				(array_agg(new_rating ORDER BY updated_at DESC, id DESC))[1],
+				LEAST(MIN(old_rating), MIN(new_rating)),
+				GREATEST(MAX(old_rating), MAX(new_rating)),
+				COUNT(*)
+			FROM score_updates
+			WHERE updated_at >= ? AND updated_at < ?
+			GROUP BY user_id
+			ON CONFLICT (user_id, day) DO UPDATE SET
+				close_rating = EXCLUDED.close_rating,
+				min_rating   = LEAST(score_update_daily.min_rating, EXCLUDED.min_rating),
+				max_rating   = GREATEST(score_update_daily.max_rating, EXCLUDED.max_rating),
+				update_count = score_update_daily.update_count + EXCLUDED.update_count
+		`, start.Format("2006-01-02"), start, end).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("updated_at >= ? AND updated_at < ?", start, end).
+			Delete(&models.ScoreUpdate{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+
+	return deleted, err
+}
+
+func (r *scoreUpdateRepository) GetDailyByUserSince(userID uint, since time.Time) ([]models.ScoreUpdateDaily, error) {
+	var days []models.ScoreUpdateDaily
+	err := replica(r.db).Where("user_id = ? AND day >= ?", userID, since.UTC().Truncate(24*time.Hour)).
+		Order("day ASC").
+		Find(&days).Error
+	return days, err
+}
+
+// GetRandomUserIDs samples up to n active user IDs
+func (r *userRepository) GetRandomUserIDs(n int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.User{}).
+		Where("status = ?", models.UserStatusActive).
+		Order("RANDOM()").
+		Limit(n).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetBoardRatings pages (by ID) through the ratings of users that belong on
+// a Redis board: everyone not banned. Only id and rating are loaded.
+func (r *userRepository) GetBoardRatings(afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Select("id", "rating").
+		Where("id > ? AND status <> ?", afterID, models.UserStatusBanned).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// GetBoardRatingsByIDs loads id and rating of the given users that belong on a board
+func (r *userRepository) GetBoardRatingsByIDs(ids []uint) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Select("id", "rating").
+		Where("id IN ? AND status <> ?", ids, models.UserStatusBanned).
+		Find(&users).Error
+	return users, err
+}
+
+// GetRankedTop reads the top of the public board from PostgreSQL, for when
+// Redis is unavailable. Ranks are tie-aware like the Redis board's.
+func (r *userRepository) GetRankedTop(limit int) ([]models.LeaderboardEntry, error) {
+	var entries []models.LeaderboardEntry
+	err := r.db.Raw(`
+		SELECT
+			RANK() OVER (ORDER BY rating DESC) AS rank,
+			id AS user_id,
+			username,
+			rating
+		FROM users
+		WHERE status = ? AND deleted_at IS NULL
+		ORDER BY rating DESC, id ASC
+		LIMIT ?
+	`, models.UserStatusActive, limit).Scan(&entries).Error
+	return entries, err
+}
+
+// CountRatedAbove counts public board users rated strictly higher
+func (r *userRepository) CountRatedAbove(rating int) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).
+		Where("status = ? AND rating > ?", models.UserStatusActive, rating).
+		Count(&count).Error
+	return count, err
+}
+
+// GetLastActivity pages (by ID) through the users on a board (not banned)
+// with the time of their last score update, or of signing up if they never
+// had one
+func (r *userRepository) GetLastActivity(afterID uint, limit int) ([]models.UserActivity, error) {
+	var activity []models.UserActivity
+	err := r.db.Raw(`
+		SELECT users.id AS user_id, COALESCE(MAX(score_updates.updated_at), users.created_at) AS last_active
+		FROM users
+		LEFT JOIN score_updates ON score_updates.user_id = users.id
+		WHERE users.id > ? AND users.status <> ? AND users.deleted_at IS NULL
+		GROUP BY users.id
+		ORDER BY users.id ASC
+		LIMIT ?
+	`, afterID, models.UserStatusBanned, limit).Scan(&activity).Error
+	return activity, err
+}
+
+// GetCachePage pages (by ID) through every user with the fields the Redis
+// boards and user cache hold
+func (r *userRepository) GetCachePage(afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Select("id", "username", "rating", "status", "timezone").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// GetCacheUsersByIDs loads the GetCachePage fields of the given users
+func (r *userRepository) GetCacheUsersByIDs(ids []uint) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Select("id", "username", "rating", "status", "timezone").
+		Where("id IN ?", ids).
+		Find(&users).Error
+	return users, err
+}
+
+// GetIDRange returns the lowest and highest user ID (0, 0 without users)
+func (r *userRepository) GetIDRange() (uint, uint, error) {
+	var bounds struct {
+		Min uint
+		Max uint
+	}
+	err := r.db.Model(&models.User{}).
+		Select("COALESCE(MIN(id), 0) AS min, COALESCE(MAX(id), 0) AS max").
+		Scan(&bounds).Error
+	return bounds.Min, bounds.Max, err
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookDeliveryFilter narrows a delivery log listing; zero values are ignored
+type WebhookDeliveryFilter struct {
+	SubscriptionID uint
+	UserID         uint
+	Status         string
+	BeforeID       uint // keyset pagination: only deliveries with a smaller ID
+	Limit          int
+}
+
+// WebhookRepository stores webhook subscriptions and their deliveries
+type WebhookRepository interface {
+	Create(sub *models.WebhookSubscription) error
+	Save(sub *models.WebhookSubscription) error
+	GetByID(id uint) (*models.WebhookSubscription, error)
+	List() ([]models.WebhookSubscription, error)
+	Delete(id uint) (bool, error)
+
+	Enqueue(deliveries []models.WebhookDelivery) error
+	ClaimDue(limit int, now time.Time, lease time.Duration) ([]models.WebhookDelivery, error)
+	Record(delivery *models.WebhookDelivery) error
+	ListDeliveries(filter WebhookDeliveryFilter) ([]models.WebhookDelivery, error)
+	PurgeDeliveries(before time.Time) (int64, error)
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(sub *models.WebhookSubscription) error {
+	return r.db.Create(sub).Error
+}
+
+func (r *webhookRepository) Save(sub *models.WebhookSubscription) error {
+	return r.db.Save(sub).Error
+}
+
+func (r *webhookRepository) GetByID(id uint) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	if err := r.db.First(&sub, id).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *webhookRepository) List() ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	err := r.db.Order("id ASC").Find(&subs).Error
+	return subs, err
+}
+
+// Delete removes a subscription and (by cascade) its deliveries; false when
+// there was no such subscription
+func (r *webhookRepository) Delete(id uint) (bool, error) {
+	result := r.db.Delete(&models.WebhookSubscription{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *webhookRepository) Enqueue(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Omit("Subscription").Create(&deliveries).Error
+}
+
+// ClaimDue returns pending deliveries whose attempt is due, with their
+// subscription, and pushes their next attempt lease into the future so no
+// other server claims them while they are in flight. A server that dies
+// mid-delivery leaves them to be retried once the lease runs out.
+func (r *webhookRepository) ClaimDue(limit int, now time.Time, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+
+	// Subscriptions are loaded after the claim commits
+	subIDs := make([]uint, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		subIDs = append(subIDs, delivery.SubscriptionID)
+	}
+	var subs []models.WebhookSubscription
+	if err := r.db.Where("id IN ?", subIDs).Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.WebhookSubscription, len(subs))
+	for _, sub := range subs {
+		byID[sub.ID] = sub
+	}
+
+	claimed := deliveries[:0]
+	for _, delivery := range deliveries {
+		if sub, ok := byID[delivery.SubscriptionID]; ok {
+			delivery.Subscription = sub
+			claimed = append(claimed, delivery)
+		}
+	}
+	return claimed, nil
+}
+
+// Record stores the outcome of a delivery attempt
+func (r *webhookRepository) Record(delivery *models.WebhookDelivery) error {
+	return r.db.Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_error":      delivery.LastError,
+			"delivered_at":    delivery.DeliveredAt,
+		}).Error
+}
+
+// ListDeliveries returns deliveries newest first
+func (r *webhookRepository) ListDeliveries(filter WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	query := r.db.Model(&models.WebhookDelivery{})
+	if filter.SubscriptionID != 0 {
+		query = query.Where("subscription_id = ?", filter.SubscriptionID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := query.Order("id DESC").
+		Limit(filter.Limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// PurgeDeliveries deletes finished deliveries created before the cutoff
+func (r *webhookRepository) PurgeDeliveries(before time.Time) (int64, error) {
+	result := r.db.Where("status <> ? AND created_at < ?", models.WebhookPending, before).
+		Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
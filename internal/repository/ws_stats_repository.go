@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// WSStatsRepository shares each server's WebSocket client count through a
+// Redis hash, so any server can report the whole cluster
+type WSStatsRepository interface {
+	Heartbeat(instance models.InstanceConnections, ttl time.Duration) error
+	List() ([]models.InstanceConnections, error)
+	Remove(nodes ...string) error
+}
+
+type wsStatsRepository struct {
+	redis *redis.Client
+	ctx   context.Context
+}
+
+func NewWSStatsRepository(redisClient *redis.Client) WSStatsRepository {
+	return &wsStatsRepository{
+		redis: redisClient,
+		ctx:   database.Ctx,
+	}
+}
+
+// Heartbeat stores a server's count. The hash expires ttl after the last
+// heartbeat of any server, so it does not outlive the cluster.
+func (r *wsStatsRepository) Heartbeat(instance models.InstanceConnections, ttl time.Duration) error {
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.redis.TxPipeline()
+	pipe.HSet(r.ctx, database.WSConnectionsKey, instance.Node, data)
+	pipe.Expire(r.ctx, database.WSConnectionsKey, ttl)
+	_, err = pipe.Exec(r.ctx)
+	return err
+}
+
+// List returns the last heartbeat of every server, stale ones included
+func (r *wsStatsRepository) List() ([]models.InstanceConnections, error) {
+	all, err := r.redis.HGetAll(r.ctx, database.WSConnectionsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]models.InstanceConnections, 0, len(all))
+	for _, raw := range all {
+		var instance models.InstanceConnections
+		if err := json.Unmarshal([]byte(raw), &instance); err != nil {
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+func (r *wsStatsRepository) Remove(nodes ...string) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	return r.redis.HDel(r.ctx, database.WSConnectionsKey, nodes...).Err()
+}
@@ -0,0 +1,41 @@
+// Package requestid carries the ID of the HTTP request behind a piece of
+// work, so the work it sets off later (DB sync writes, WebSocket
+// broadcasts on other servers) can be traced back to it.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header carries a request's ID: taken from the caller (e.g. a load
+// balancer) when set, generated otherwise, and echoed in the response
+const Header = "X-Request-ID"
+
+// MaxLength is the longest ID accepted from a caller; longer ones are
+// replaced
+const MaxLength = 64
+
+type contextKey struct{}
+
+// New returns a random 16-hex-character request ID
+func New() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// NewContext returns a context carrying id; an empty id carries none
+func NewContext(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or ""
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,91 @@
+// Package schedule resolves leaderboard periods (daily, weekly) into
+// concrete windows. All period arithmetic lives here so boards, handlers
+// and background jobs agree on when a period starts and ends.
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// Period is the length of a periodic board
+type Period string
+
+const (
+	Daily  Period = "daily"
+	Weekly Period = "weekly" // weeks start on Monday
+)
+
+// ParsePeriod validates a period name
+func ParsePeriod(value string) (Period, error) {
+	switch Period(value) {
+	case Daily, Weekly:
+		return Period(value), nil
+	}
+	return "", fmt.Errorf("invalid period %q (use daily or weekly)", value)
+}
+
+// LoadLocation resolves an IANA timezone name; empty means UTC
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+	return loc, nil
+}
+
+// Window is one occurrence of a period in a fixed UTC offset. Users whose
+// timezones share the offset at that moment share a window (bucket).
+type Window struct {
+	Period Period    `json:"period"`
+	Offset string    `json:"offset"` // e.g. "+05:30"
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
+
+// Resolve returns the window containing at, as seen from loc
+func Resolve(period Period, loc *time.Location, at time.Time) Window {
+	local := at.In(loc)
+	_, offsetSeconds := local.Zone()
+	zone := time.FixedZone("", offsetSeconds)
+
+	// Boundaries use the fixed offset so every timezone in the bucket agrees
+	local = at.In(zone)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, zone)
+	end := start.AddDate(0, 0, 1)
+
+	if period == Weekly {
+		daysSinceMonday := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -daysSinceMonday)
+		end = start.AddDate(0, 0, 7)
+	}
+
+	return Window{
+		Period: period,
+		Offset: formatOffset(offsetSeconds),
+		Start:  start,
+		End:    end,
+	}
+}
+
+// ID identifies the window, e.g. "daily:+05:30:2026-10-18"
+func (w Window) ID() string {
+	return fmt.Sprintf("%s:%s:%s", w.Period, w.Offset, w.Start.Format("2006-01-02"))
+}
+
+// Length is the window's duration
+func (w Window) Length() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule yields the occurrences of a job. Occurrences depend only on the
+// wall clock, never on when a server started, so every server computes the
+// same ones and can claim them by time.
+type Schedule interface {
+	// Next is the first occurrence strictly after t
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// Shorthands accepted by Parse
+var descriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// Parse reads a schedule: five cron fields (minute hour day-of-month month
+// day-of-week, in UTC), one of @hourly, @daily, @weekly or @monthly, or
+// "@every <duration>". Empty means no schedule (nil, nil).
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid schedule %q: @every needs a duration of at least 1s", spec)
+		}
+		return Every(d), nil
+	}
+	if expanded, ok := descriptors[spec]; ok {
+		schedule, err := parseCron(expanded)
+		if err != nil {
+			return nil, err
+		}
+		schedule.spec = spec
+		return schedule, nil
+	}
+	if strings.HasPrefix(spec, "@") {
+		return nil, fmt.Errorf("invalid schedule %q: unknown descriptor", spec)
+	}
+	return parseCron(spec)
+}
+
+// interval fires at every multiple of its duration since the zero time
+type interval time.Duration
+
+// Every returns a schedule firing every d, aligned so servers agree on the
+// occurrences (@every 1h fires on the hour). Non-positive d is no schedule.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		return nil
+	}
+	return interval(d)
+}
+
+func (i interval) Next(t time.Time) time.Time {
+	d := time.Duration(i)
+	return t.UTC().Truncate(d).Add(d)
+}
+
+func (i interval) String() string {
+	return "@every " + time.Duration(i).String()
+}
+
+// cron is a parsed five-field expression; each field is a bitmask of the
+// values it matches
+type cron struct {
+	spec                          string
+	minute, hour, dom, month, dow uint64
+	anyDom, anyDow                bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 0 and 7 are Sunday
+}
+
+func parseCron(spec string) (*cron, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("invalid schedule %q: want 5 fields (minute hour day-of-month month day-of-week)", spec)
+	}
+
+	masks := make([]uint64, len(parts))
+	for i, part := range parts {
+		mask, err := parseField(part, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		masks[i] = mask
+	}
+	// Sunday may be written 0 or 7
+	if masks[4]&(1<<7) != 0 {
+		masks[4] |= 1
+	}
+
+	return &cron{
+		spec:   spec,
+		minute: masks[0],
+		hour:   masks[1],
+		dom:    masks[2],
+		month:  masks[3],
+		dow:    masks[4],
+		anyDom: parts[2] == "*",
+		anyDow: parts[4] == "*",
+	}, nil
+}
+
+// parseField reads a comma-separated list of *, n, a-b, each optionally
+// followed by /step
+func parseField(value string, f field) (uint64, error) {
+	var mask uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("bad step %q in %s", stepPart, f.name)
+			}
+			step = n
+		}
+
+		low, high := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var errA, errB error
+			low, errA = strconv.Atoi(a)
+			high, errB = strconv.Atoi(b)
+			if errA != nil || errB != nil || low > high {
+				return 0, fmt.Errorf("bad range %q in %s", rangePart, f.name)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("bad value %q in %s", rangePart, f.name)
+			}
+			low = n
+			if !hasStep {
+				high = n
+			}
+		}
+		if low < f.min || high > f.max {
+			return 0, fmt.Errorf("%s must be between %d and %d, got %q", f.name, f.min, f.max, rangePart)
+		}
+
+		for v := low; v <= high; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+// Next walks forward field by field, skipping whole months, days and hours
+// that cannot match. Expressions that never match (such as February 30)
+// return the zero time.
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron: when both day fields are restricted, a day
+// matching either one matches
+func (c *cron) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.anyDom && c.anyDow:
+		return true
+	case c.anyDom:
+		return dowMatch
+	case c.anyDow:
+		return domMatch
+	}
+	return domMatch || dowMatch
+}
+
+func (c *cron) String() string {
+	return c.spec
+}
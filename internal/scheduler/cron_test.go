@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		spec    string
+		wantErr string
+	}{
+		{"* * * *", "want 5 fields"},
+		{"* * * * * *", "want 5 fields"},
+		{"60 * * * *", "minute must be between 0 and 59"},
+		{"* 24 * * *", "hour must be between 0 and 23"},
+		{"* * 0 * *", "day of month must be between 1 and 31"},
+		{"* * * 13 *", "month must be between 1 and 12"},
+		{"* * * * 8", "day of week must be between 0 and 7"},
+		{"*/0 * * * *", "bad step"},
+		{"*/x * * * *", "bad step"},
+		{"5-1 * * * *", "bad range"},
+		{"a * * * *", "bad value"},
+		{"@yearly", "unknown descriptor"},
+		{"@every 500ms", "at least 1s"},
+		{"@every soon", "at least 1s"},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			_, err := Parse(tc.spec)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Parse(%q) error = %v, want one containing %q", tc.spec, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseEmptyIsNoSchedule(t *testing.T) {
+	for _, spec := range []string{"", "   "} {
+		schedule, err := Parse(spec)
+		if schedule != nil || err != nil {
+			t.Errorf("Parse(%q) = %v, %v; want nil, nil", spec, schedule, err)
+		}
+	}
+}
+
+func TestParseString(t *testing.T) {
+	for _, tc := range []struct {
+		spec, want string
+	}{
+		{"@daily", "@daily"},
+		{"  */15 * * * *  ", "*/15 * * * *"},
+		{"@every 90m", "@every 1h30m0s"},
+	} {
+		schedule, err := Parse(tc.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.spec, err)
+		}
+		if got := schedule.String(); got != tc.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tc.spec, got, tc.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		spec string
+		from string
+		want string
+	}{
+		{"every minute", "* * * * *", "2024-03-10T12:00:30Z", "2024-03-10T12:01:00Z"},
+		{"strictly after", "30 12 * * *", "2024-03-10T12:30:00Z", "2024-03-11T12:30:00Z"},
+		{"step", "*/15 * * * *", "2024-03-10T12:16:00Z", "2024-03-10T12:30:00Z"},
+		{"step wraps the hour", "*/15 * * * *", "2024-03-10T12:50:00Z", "2024-03-10T13:00:00Z"},
+		{"value with step", "5/20 * * * *", "2024-03-10T12:26:00Z", "2024-03-10T12:45:00Z"},
+		{"list", "0 6,18 * * *", "2024-03-10T07:00:00Z", "2024-03-10T18:00:00Z"},
+		{"range", "0 9-17 * * *", "2024-03-10T17:30:00Z", "2024-03-11T09:00:00Z"},
+		{"hourly", "@hourly", "2024-03-10T12:59:59Z", "2024-03-10T13:00:00Z"},
+		{"daily", "@daily", "2024-03-10T00:00:00Z", "2024-03-11T00:00:00Z"},
+		{"weekly on sunday", "@weekly", "2024-03-11T08:00:00Z", "2024-03-17T00:00:00Z"},
+		{"sunday as 7", "0 0 * * 7", "2024-03-11T08:00:00Z", "2024-03-17T00:00:00Z"},
+		{"monthly across the year", "@monthly", "2024-12-15T00:00:00Z", "2025-01-01T00:00:00Z"},
+		{"leap day", "0 0 29 2 *", "2024-03-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"day of month or week", "0 0 13 * 5", "2024-09-01T00:00:00Z", "2024-09-06T00:00:00Z"},
+		{"never matches", "0 0 30 2 *", "2024-01-01T00:00:00Z", "0001-01-01T00:00:00Z"},
+		{"other time zone", "0 0 * * *", "2024-03-10T23:30:00-05:00", "2024-03-12T00:00:00Z"},
+		{"every aligned", "@every 1h", "2024-03-10T12:20:00Z", "2024-03-10T13:00:00Z"},
+		{"every on the boundary", "@every 15m", "2024-03-10T12:15:00Z", "2024-03-10T12:30:00Z"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := Parse(tc.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tc.spec, err)
+			}
+			got := schedule.Next(mustTime(t, tc.from))
+			if want := mustTime(t, tc.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", tc.from, got.Format(time.RFC3339), tc.want)
+			}
+		})
+	}
+}
+
+func TestEveryNonPositiveIsNoSchedule(t *testing.T) {
+	if Every(0) != nil || Every(-time.Minute) != nil {
+		t.Error("Every of a non-positive duration should be nil")
+	}
+}
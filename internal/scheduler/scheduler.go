@@ -0,0 +1,225 @@
+// Package scheduler runs periodic background jobs (decay, reconciliation,
+// snapshots) once per occurrence across all servers. Every server keeps the
+// same timetable; at each occurrence the first server to claim it in Redis
+// runs the job and the others skip it.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+var (
+	jobRuns = metrics.NewCounterVec("scheduler_runs_total",
+		"Scheduled job occurrences by outcome (ok, failed, or skipped when another server claimed them)", "job", "result")
+	jobDuration = metrics.NewHistogramVec("scheduler_run_seconds",
+		"Wall time of scheduled job runs", []float64{0.1, 1, 5, 30, 60, 300, 900, 3600}, "job")
+)
+
+// Func is the work of a job; ctx is cancelled when the scheduler stops
+type Func func(ctx context.Context) error
+
+// Store claims occurrences and keeps the last run of each job, shared by
+// all servers
+type Store interface {
+	// Claim takes the occurrence of job at at; false if a server already has
+	Claim(job string, at time.Time) (bool, error)
+	SaveRun(run *models.ScheduledRun) error
+	LastRuns() (map[string]*models.ScheduledRun, error)
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	run      Func
+
+	mu      sync.Mutex
+	next    time.Time
+	running bool
+}
+
+// Scheduler runs the jobs added to it until stopped
+type Scheduler struct {
+	store   Store
+	node    string
+	enabled bool
+
+	mu   sync.Mutex
+	jobs []*job
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// New builds a scheduler. A disabled one lists its jobs but never runs
+// them, leaving the occurrences to the other servers.
+func New(store Store, node string, enabled bool) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		store:   store,
+		node:    node,
+		enabled: enabled,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Add registers a job; a nil schedule leaves it off. Jobs are added before
+// Start.
+func (s *Scheduler) Add(name string, schedule Schedule, run Func) {
+	if schedule == nil {
+		slog.Info("⏸️  Scheduled job disabled", "job", name)
+		return
+	}
+	s.mu.Lock()
+	s.jobs = append(s.jobs, &job{name: name, schedule: schedule, run: run})
+	s.mu.Unlock()
+}
+
+// Start runs every job on its own goroutine
+func (s *Scheduler) Start() {
+	if !s.enabled {
+		slog.Info("⏸️  Scheduler disabled on this server")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		slog.Info("⏰ Scheduled job", "job", j.name, "schedule", j.schedule.String())
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		s.cancel()
+		s.wg.Wait()
+	})
+}
+
+func (s *Scheduler) loop(j *job) {
+	defer s.wg.Done()
+
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			slog.Warn("⚠️  Scheduled job has no further occurrences", "job", j.name)
+			return
+		}
+		j.mu.Lock()
+		j.next = next
+		j.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Occurrences that pass while a long run is still going are not
+		// made up; another server may take them
+		s.fire(j, next)
+	}
+}
+
+// fire claims one occurrence and, if it won the claim, runs and records it
+func (s *Scheduler) fire(j *job, at time.Time) {
+	claimed, err := s.store.Claim(j.name, at)
+	if err != nil {
+		jobRuns.WithLabelValues(j.name, "failed").Inc()
+		slog.Warn("⚠️  Failed to claim scheduled run", "job", j.name, "at", at, "error", err)
+		return
+	}
+	if !claimed {
+		jobRuns.WithLabelValues(j.name, "skipped").Inc()
+		return
+	}
+
+	j.mu.Lock()
+	j.running = true
+	j.mu.Unlock()
+	defer func() {
+		j.mu.Lock()
+		j.running = false
+		j.mu.Unlock()
+	}()
+
+	run := &models.ScheduledRun{
+		Job:         j.name,
+		Node:        s.node,
+		ScheduledAt: at,
+		StartedAt:   time.Now(),
+	}
+	err = s.call(j)
+	elapsed := time.Since(run.StartedAt)
+	run.Duration = elapsed.Round(time.Millisecond).String()
+	jobDuration.WithLabelValues(j.name).Observe(elapsed.Seconds())
+
+	if err != nil {
+		run.Error = err.Error()
+		jobRuns.WithLabelValues(j.name, "failed").Inc()
+		slog.Error("⚠️  Scheduled job failed", "job", j.name, "error", err)
+	} else {
+		jobRuns.WithLabelValues(j.name, "ok").Inc()
+	}
+	if err := s.store.SaveRun(run); err != nil {
+		slog.Warn("⚠️  Failed to record scheduled run", "job", j.name, "error", err)
+	}
+}
+
+// call runs a job, turning a panic into an error so one bad run does not
+// take the server down
+func (s *Scheduler) call(j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.run(s.ctx)
+}
+
+// Jobs lists the registered jobs with their next occurrence and the last
+// run of any server
+func (s *Scheduler) Jobs() ([]models.ScheduledJob, error) {
+	lastRuns, err := s.store.LastRuns()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	jobs := make([]models.ScheduledJob, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		j.mu.Lock()
+		next := j.next
+		running := j.running
+		j.mu.Unlock()
+		if next.IsZero() {
+			next = j.schedule.Next(time.Now())
+		}
+		jobs = append(jobs, models.ScheduledJob{
+			Name:     j.name,
+			Schedule: j.schedule.String(),
+			NextRun:  next,
+			Running:  running,
+			LastRun:  lastRuns[j.name],
+		})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].Name < jobs[b].Name })
+	return jobs, nil
+}
@@ -0,0 +1,112 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// newSecretRequest builds the GetSecretValue request Fetch sends
+func newSecretRequest(t *testing.T, endpoint string, body []byte) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	return req
+}
+
+// TestSignMatchesSDK checks sign against the AWS SDK's SigV4 signer, with
+// and without the session token of temporary (STS, IRSA, ECS) credentials
+func TestSignMatchesSDK(t *testing.T) {
+	const endpoint = "https://secretsmanager.eu-west-1.amazonaws.com/"
+	body := []byte(`{"SecretId":"leaderboard/prod"}`)
+	now := time.Date(2024, 3, 10, 12, 30, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name          string
+		sessionToken  string
+		signedHeaders string
+	}{
+		{"long-term credentials", "", "content-type;host;x-amz-date;x-amz-target"},
+		{"session token", "FwoGZXIvYXdzEBYaDH7token", "content-type;host;x-amz-date;x-amz-security-token;x-amz-target"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &awsProvider{
+				region:       "eu-west-1",
+				accessKey:    "AKIDEXAMPLE",
+				secretKey:    "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+				sessionToken: tc.sessionToken,
+			}
+			req := newSecretRequest(t, endpoint, body)
+			p.sign(req, body, now)
+
+			want := newSecretRequest(t, endpoint, body)
+			want.ContentLength = 0 // sign does not sign Content-Length
+			creds := aws.Credentials{AccessKeyID: p.accessKey, SecretAccessKey: p.secretKey, SessionToken: tc.sessionToken}
+			if err := v4.NewSigner().SignHTTP(context.Background(), creds, want,
+				sha256Hex(body), "secretsmanager", p.region, now); err != nil {
+				t.Fatal(err)
+			}
+
+			got := req.Header.Get("Authorization")
+			if got != want.Header.Get("Authorization") {
+				t.Errorf("Authorization =\n  %s\nwant\n  %s", got, want.Header.Get("Authorization"))
+			}
+			if !strings.Contains(got, "SignedHeaders="+tc.signedHeaders+",") {
+				t.Errorf("Authorization %q does not sign %s", got, tc.signedHeaders)
+			}
+			if req.Header.Get("X-Amz-Security-Token") != tc.sessionToken {
+				t.Errorf("X-Amz-Security-Token = %q, want %q", req.Header.Get("X-Amz-Security-Token"), tc.sessionToken)
+			}
+		})
+	}
+}
+
+func TestAWSFetch(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		ref     string
+		status  int
+		reply   string
+		want    string
+		wantErr string
+	}{
+		{"plain secret", "db-password", http.StatusOK, `{"SecretString":"hunter2"}`, "hunter2", ""},
+		{"json field", "leaderboard/prod#redis", http.StatusOK, `{"SecretString":"{\"redis\":\"s3cret\"}"}`, "s3cret", ""},
+		{"denied", "db-password", http.StatusBadRequest, `{"__type":"AccessDeniedException"}`, "", "returned 400"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+					t.Errorf("unsigned request: %q", r.Header.Get("Authorization"))
+				}
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.reply))
+			}))
+			defer srv.Close()
+
+			p := &awsProvider{region: "eu-west-1", endpoint: srv.URL + "/", accessKey: "AKIDEXAMPLE",
+				secretKey: "secret", http: srv.Client()}
+			got, err := p.Fetch(context.Background(), tc.ref)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Fetch error = %v, want one containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("Fetch(%q) = %q, %v; want %q", tc.ref, got, err, tc.want)
+			}
+		})
+	}
+}
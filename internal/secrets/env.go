@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envProvider reads secrets straight from environment variables (default)
+type envProvider struct{}
+
+func (p *envProvider) Name() string {
+	return "env"
+}
+
+func (p *envProvider) Fetch(ctx context.Context, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s not set", ref)
+	}
+	return value, nil
+}
+
+// splitRef splits "secret-id#field" into its id and optional JSON field
+func splitRef(ref string) (string, string) {
+	id, field, _ := strings.Cut(ref, "#")
+	return id, field
+}
+
+// extractField returns the raw secret, or a single key when the secret
+// is a JSON object and a field was requested (e.g. "prod/db#password")
+func extractField(raw, field string) (string, error) {
+	if field == "" {
+		return raw, nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+
+	value, ok := values[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found in secret", field)
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+)
+
+const gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// gcpProvider reads secrets from GCP Secret Manager via the REST API.
+// It uses GCP_ACCESS_TOKEN when set, otherwise the GCE/GKE metadata server.
+type gcpProvider struct {
+	project string
+	http    *http.Client
+}
+
+func newGCPProvider(cfg *config.SecretsConfig, httpClient *http.Client) (Provider, error) {
+	if cfg.GCPProject == "" {
+		return nil, fmt.Errorf("SECRETS_GCP_PROJECT is required for the gcp provider")
+	}
+
+	return &gcpProvider{
+		project: cfg.GCPProject,
+		http:    httpClient,
+	}, nil
+}
+
+func (p *gcpProvider) Name() string {
+	return "gcp"
+}
+
+func (p *gcpProvider) Fetch(ctx context.Context, ref string) (string, error) {
+	name, field := splitRef(ref)
+
+	// Allow "name" (latest) or "name/versions/3"
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("https://secretmanager.googleapis.com/v1/projects/%s/secrets/%s:access", p.project, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gcp secret manager returned %d: %s", resp.StatusCode, body)
+	}
+
+	var out struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("failed to decode gcp secret: %w", err)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(out.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode gcp secret payload: %w", err)
+	}
+
+	return extractField(string(raw), field)
+}
+
+func (p *gcpProvider) accessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GCP_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach gcp metadata server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gcp metadata server returned %d", resp.StatusCode)
+	}
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode gcp access token: %w", err)
+	}
+	return out.AccessToken, nil
+}
@@ -0,0 +1,176 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+)
+
+// Secret identifies one of the secrets the backend knows about
+type Secret string
+
+const (
+	DBPassword    Secret = "db_password"
+	RedisPassword Secret = "redis_password"
+	JWTSecret     Secret = "jwt_secret"
+	HMACSecret    Secret = "hmac_secret"
+)
+
+// Manager resolves secrets through a Provider, caches them in-process and
+// re-fetches them periodically so rotated values are picked up without a restart
+type Manager struct {
+	provider Provider
+	refs     map[Secret]string
+	interval time.Duration
+
+	mu        sync.RWMutex
+	values    map[Secret]string
+	listeners map[Secret][]func(string)
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+// NewManager creates a secrets manager from config (does not fetch yet)
+func NewManager(cfg *config.SecretsConfig) (*Manager, error) {
+	provider, err := NewProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := map[Secret]string{}
+	for secret, ref := range map[Secret]string{
+		DBPassword:    cfg.DBPasswordRef,
+		RedisPassword: cfg.RedisPasswordRef,
+		JWTSecret:     cfg.JWTSecretRef,
+		HMACSecret:    cfg.HMACSecretRef,
+	} {
+		if ref != "" {
+			refs[secret] = ref
+		}
+	}
+
+	return &Manager{
+		provider:  provider,
+		refs:      refs,
+		interval:  cfg.RefreshInterval,
+		values:    make(map[Secret]string),
+		listeners: make(map[Secret][]func(string)),
+		stopCh:    make(chan struct{}),
+	}, nil
+}
+
+// Load fetches every configured secret once. Secrets that fail to load are
+// left unset (callers fall back to plain env config); an error is returned
+// only if the provider is not "env", since missing env vars are normal.
+func (m *Manager) Load(ctx context.Context) error {
+	var firstErr error
+
+	for secret, ref := range m.refs {
+		value, err := m.provider.Fetch(ctx, ref)
+		if err != nil {
+			if m.provider.Name() != "env" && firstErr == nil {
+				firstErr = fmt.Errorf("failed to load secret %s: %w", secret, err)
+			}
+			continue
+		}
+
+		m.mu.Lock()
+		m.values[secret] = value
+		m.mu.Unlock()
+	}
+
+	slog.Info("🔐 Secrets loaded", "provider", m.provider.Name(), "configured", len(m.refs))
+	return firstErr
+}
+
+// Get returns the current value of a secret ("" if not loaded)
+func (m *Manager) Get(secret Secret) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.values[secret]
+}
+
+// Has reports whether a secret has been loaded
+func (m *Manager) Has(secret Secret) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.values[secret]
+	return ok
+}
+
+// OnRotate registers a callback invoked when a secret's value changes
+func (m *Manager) OnRotate(secret Secret, fn func(newValue string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners[secret] = append(m.listeners[secret], fn)
+}
+
+// Start begins periodic refresh (no-op when interval is zero)
+func (m *Manager) Start() {
+	if m.interval <= 0 || len(m.refs) == 0 {
+		return
+	}
+
+	slog.Info("🔁 Secret rotation enabled", "interval", m.interval)
+
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.refresh()
+			case <-m.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts periodic refresh
+func (m *Manager) Stop() {
+	m.once.Do(func() { close(m.stopCh) })
+}
+
+func (m *Manager) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for secret, ref := range m.refs {
+		value, err := m.provider.Fetch(ctx, ref)
+		if err != nil {
+			slog.Warn("⚠️  Failed to refresh secret", "secret", secret, "error", err)
+			continue
+		}
+
+		m.mu.Lock()
+		changed := m.values[secret] != value
+		m.values[secret] = value
+		listeners := append([]func(string){}, m.listeners[secret]...)
+		m.mu.Unlock()
+
+		if changed {
+			slog.Info("🔑 Secret rotated", "secret", secret)
+			for _, fn := range listeners {
+				fn(value)
+			}
+		}
+	}
+}
+
+// Lookup returns a func that always yields the latest value of a secret,
+// falling back to the given static value when the secret is not loaded
+func (m *Manager) Lookup(secret Secret, fallback string) func() string {
+	return func() string {
+		if m.Has(secret) {
+			return m.Get(secret)
+		}
+		return fallback
+	}
+}
@@ -0,0 +1,35 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+)
+
+// Provider fetches a single secret value by reference.
+// The meaning of ref is provider specific (env var name, secret id, KV path).
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context, ref string) (string, error)
+}
+
+// NewProvider builds the provider selected in config
+func NewProvider(cfg *config.SecretsConfig) (Provider, error) {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+
+	switch cfg.Provider {
+	case "", "env":
+		return &envProvider{}, nil
+	case "aws":
+		return newAWSProvider(cfg, httpClient)
+	case "gcp":
+		return newGCPProvider(cfg, httpClient)
+	case "vault":
+		return newVaultProvider(cfg, httpClient)
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q", cfg.Provider)
+	}
+}
@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+)
+
+// vaultProvider reads secrets from a HashiCorp Vault KV v2 mount.
+// Refs look like "leaderboard/db#password" (path#field, field defaults to "value").
+type vaultProvider struct {
+	addr  string
+	token string
+	mount string
+	http  *http.Client
+}
+
+func newVaultProvider(cfg *config.SecretsConfig, httpClient *http.Client) (Provider, error) {
+	if cfg.VaultAddr == "" || cfg.VaultToken == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required for the vault provider")
+	}
+
+	return &vaultProvider{
+		addr:  strings.TrimRight(cfg.VaultAddr, "/"),
+		token: cfg.VaultToken,
+		mount: cfg.VaultMount,
+		http:  httpClient,
+	}, nil
+}
+
+func (p *vaultProvider) Name() string {
+	return "vault"
+}
+
+func (p *vaultProvider) Fetch(ctx context.Context, ref string) (string, error) {
+	path, field := splitRef(ref)
+	if field == "" {
+		field = "value"
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.addr, p.mount, strings.TrimLeft(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %d: %s", resp.StatusCode, body)
+	}
+
+	var out struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("failed to decode vault secret: %w", err)
+	}
+
+	value, ok := out.Data.Data[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found at %s", field, path)
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+var achievementsUnlocked = metrics.NewCounterVec("achievements_unlocked_total",
+	"Achievements unlocked, by code", "code")
+
+// AchievementService unlocks achievements for applied score updates, stores
+// them in PostgreSQL and announces each one once
+type AchievementService interface {
+	Evaluate(payload *models.ScoreUpdatePayload)
+	List(userID uint) ([]models.Achievement, error)
+	HandleUserRemoved(event eventbus.Event)
+}
+
+type achievementService struct {
+	cfg             config.AchievementConfig
+	achievementRepo repository.AchievementRepository
+	progressRepo    repository.AchievementProgressRepository
+	bus             *eventbus.Bus
+}
+
+func NewAchievementService(
+	cfg config.AchievementConfig,
+	achievementRepo repository.AchievementRepository,
+	progressRepo repository.AchievementProgressRepository,
+	bus *eventbus.Bus,
+) AchievementService {
+	return &achievementService{
+		cfg:             cfg,
+		achievementRepo: achievementRepo,
+		progressRepo:    progressRepo,
+		bus:             bus,
+	}
+}
+
+// Evaluate updates the user's win streak and unlocks first_win,
+// high_rating and win_streak when the update earns them
+func (s *achievementService) Evaluate(payload *models.ScoreUpdatePayload) {
+	if !s.cfg.Enabled {
+		return
+	}
+
+	delta := payload.NewRating - payload.OldRating
+	streak, err := s.progressRepo.RecordResult(payload.UserID, delta)
+	if err != nil {
+		slog.Warn("⚠️  Failed to record win streak", "user_id", payload.UserID, "error", err)
+	}
+
+	if delta > 0 {
+		s.unlock(payload, models.AchievementFirstWin, 0)
+	}
+	if payload.NewRating >= s.cfg.Rating {
+		s.unlock(payload, models.AchievementHighRating, s.cfg.Rating)
+	}
+	if streak >= int64(s.cfg.Streak) {
+		s.unlock(payload, models.AchievementWinStreak, s.cfg.Streak)
+	}
+}
+
+// unlock stores an achievement the first time a user earns it and
+// publishes achievement_unlocked. The Redis set keeps repeat checks off
+// PostgreSQL; the unique index settles races and a Redis that lost the set.
+func (s *achievementService) unlock(update *models.ScoreUpdatePayload, code string, threshold int) {
+	fresh, err := s.progressRepo.MarkUnlocked(update.UserID, code)
+	if err != nil {
+		slog.Warn("⚠️  Failed to check achievement", "code", code, "user_id", update.UserID, "error", err)
+		return
+	}
+	if !fresh {
+		return
+	}
+
+	now := time.Now()
+	created, err := s.achievementRepo.Create(&models.Achievement{
+		UserID:     update.UserID,
+		Code:       code,
+		Threshold:  threshold,
+		Rating:     update.NewRating,
+		UnlockedAt: now,
+	})
+	if err != nil {
+		slog.Warn("⚠️  Failed to store achievement", "code", code, "user_id", update.UserID, "error", err)
+		// Let a later update try again
+		if err := s.progressRepo.UnmarkUnlocked(update.UserID, code); err != nil {
+			slog.Warn("⚠️  Failed to unmark achievement", "code", code, "user_id", update.UserID, "error", err)
+		}
+		return
+	}
+	if !created {
+		return
+	}
+
+	event := &models.AchievementPayload{
+		UserID:    update.UserID,
+		Username:  update.Username,
+		Code:      code,
+		Threshold: threshold,
+		Rank:      update.NewRank,
+		Rating:    update.NewRating,
+		Timestamp: now.Unix(),
+	}
+	if err := s.bus.Publish(models.EventAchievement, event); err != nil {
+		slog.Warn("⚠️  Failed to publish achievement", "code", code, "user_id", update.UserID, "error", err)
+	}
+	achievementsUnlocked.WithLabelValues(code).Inc()
+}
+
+// List returns a user's achievements, oldest first
+func (s *achievementService) List(userID uint) ([]models.Achievement, error) {
+	achievements, err := s.achievementRepo.ListByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get achievements: %w", err)
+	}
+	return achievements, nil
+}
+
+// HandleUserRemoved drops the streak and unlocked set of a deleted, banned
+// or purged user; their rows go with the user on purge
+func (s *achievementService) HandleUserRemoved(event eventbus.Event) {
+	payload, ok := event.Payload.(*models.UserRemovedPayload)
+	if !ok {
+		return
+	}
+	if err := s.progressRepo.Forget(payload.UserID); err != nil {
+		slog.Warn("⚠️  Failed to forget achievement progress", "user_id", payload.UserID, "error", err)
+	}
+}
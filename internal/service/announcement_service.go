@@ -0,0 +1,56 @@
+package service
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+// maxAnnouncementLength caps an announcement, in bytes
+const maxAnnouncementLength = 500
+
+var ErrInvalidAnnouncement = newValidationError("announcement needs a message of at most 500 bytes and a level of info, warning or critical")
+
+// AnnouncementService sends admin announcements to the WebSocket clients of
+// every server
+type AnnouncementService interface {
+	Announce(actor, message, level string) (*models.AnnouncementPayload, error)
+}
+
+type announcementService struct {
+	bus *eventbus.Bus
+}
+
+func NewAnnouncementService(bus *eventbus.Bus) AnnouncementService {
+	return &announcementService{bus: bus}
+}
+
+// Announce publishes an announcement; level defaults to info
+func (s *announcementService) Announce(actor, message, level string) (*models.AnnouncementPayload, error) {
+	message = strings.TrimSpace(message)
+	if level == "" {
+		level = "info"
+	}
+	if message == "" || len(message) > maxAnnouncementLength {
+		return nil, ErrInvalidAnnouncement
+	}
+	switch level {
+	case "info", "warning", "critical":
+	default:
+		return nil, ErrInvalidAnnouncement
+	}
+
+	payload := &models.AnnouncementPayload{
+		Message:   message,
+		Level:     level,
+		Timestamp: time.Now().Unix(),
+	}
+	if err := s.bus.Publish(models.EventAnnouncement, payload); err != nil {
+		return nil, err
+	}
+	slog.Info("📢 Announcement", "actor", actor, "level", level, "message", message)
+	return payload, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/anticheat"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrFlagNotFound = errors.New("anomaly flag not found")
+
+var (
+	anomalyFlags = metrics.NewCounterVec("anticheat_flags_total",
+		"Score updates flagged by anti-cheat detectors", "rule")
+	anomalyDropped = metrics.NewCounter("anticheat_dropped_total",
+		"Score updates not inspected because the inspection queue was full")
+)
+
+// ScoreInspector is handed every applied score update
+type ScoreInspector interface {
+	Inspect(payload *models.ScoreUpdatePayload)
+}
+
+// AnomalyService runs anti-cheat detectors over score updates in the
+// background, stores flags and lets admins review them
+type AnomalyService interface {
+	ScoreInspector
+	Start()
+	Stop()
+	ListFlags(filter repository.AnomalyFilter) ([]models.AnomalyFlag, error)
+	ReviewFlag(id uint, status, reviewer, note string) (*models.AnomalyFlag, error)
+}
+
+type anomalyService struct {
+	detectors   []anticheat.Detector
+	anomalyRepo repository.AnomalyRepository
+
+	queue  chan anticheat.Observation
+	stopCh chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewAnomalyService(
+	anomalyRepo repository.AnomalyRepository,
+	queueSize int,
+	detectors ...anticheat.Detector,
+) AnomalyService {
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return &anomalyService{
+		detectors:   detectors,
+		anomalyRepo: anomalyRepo,
+		queue:       make(chan anticheat.Observation, queueSize),
+		stopCh:      make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start runs the inspection worker
+func (s *anomalyService) Start() {
+	names := make([]string, 0, len(s.detectors))
+	for _, detector := range s.detectors {
+		names = append(names, detector.Name())
+	}
+	slog.Info("🕵️  Anti-cheat detection started", "detectors", names)
+
+	go func() {
+		defer close(s.done)
+		for {
+			select {
+			case obs := <-s.queue:
+				s.inspect(obs)
+			case <-s.stopCh:
+				slog.Info("⏹️  Anti-cheat detection stopped")
+				return
+			}
+		}
+	}()
+}
+
+func (s *anomalyService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+// Inspect queues an update for the detectors without blocking the hot path
+func (s *anomalyService) Inspect(payload *models.ScoreUpdatePayload) {
+	if len(s.detectors) == 0 {
+		return
+	}
+
+	obs := anticheat.Observation{
+		UserID:    payload.UserID,
+		OldRating: payload.OldRating,
+		NewRating: payload.NewRating,
+		At:        time.Unix(payload.Timestamp, 0),
+	}
+	select {
+	case s.queue <- obs:
+	default:
+		anomalyDropped.Inc()
+	}
+}
+
+// inspect runs every detector and stores the flags they raise
+func (s *anomalyService) inspect(obs anticheat.Observation) {
+	var flags []models.AnomalyFlag
+	for _, detector := range s.detectors {
+		found, err := detector.Inspect(obs)
+		if err != nil {
+			slog.Warn("⚠️  Anti-cheat detector failed", "detector", detector.Name(), "error", err)
+			continue
+		}
+		for _, flag := range found {
+			anomalyFlags.WithLabelValues(flag.Rule).Inc()
+			flags = append(flags, models.AnomalyFlag{
+				UserID:    obs.UserID,
+				Rule:      flag.Rule,
+				Detail:    flag.Detail,
+				OldRating: obs.OldRating,
+				NewRating: obs.NewRating,
+				Status:    models.AnomalyStatusOpen,
+				CreatedAt: obs.At,
+			})
+		}
+	}
+
+	if len(flags) == 0 {
+		return
+	}
+	if err := s.anomalyRepo.CreateBatch(flags); err != nil {
+		slog.Warn("⚠️  Failed to store anti-cheat flags", "user_id", obs.UserID, "error", err)
+		return
+	}
+	slog.Warn("🚩 Flagged user", "user_id", obs.UserID, "detail", flags[0].Detail)
+}
+
+func (s *anomalyService) ListFlags(filter repository.AnomalyFilter) ([]models.AnomalyFlag, error) {
+	return s.anomalyRepo.List(filter)
+}
+
+// ReviewFlag confirms or dismisses a flag
+func (s *anomalyService) ReviewFlag(id uint, status, reviewer, note string) (*models.AnomalyFlag, error) {
+	flag, err := s.anomalyRepo.Review(id, status, reviewer, note)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFlagNotFound
+	}
+	return flag, err
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// Adjustment sources
+const (
+	AdjustmentSingle = "single"
+	AdjustmentBulk   = "bulk"
+	AdjustmentImport = "import"
+	AdjustmentAsync  = "async"
+	AdjustmentRevert = "revert"
+	AdjustmentResult = "result"
+	AdjustmentDecay  = "decay"
+)
+
+// Spooled audit entries written to PostgreSQL per flush
+const auditSpoolBatch = 500
+
+// AuditService records provenance for score changes made through the API.
+// Entries PostgreSQL cannot take (an outage) are spooled in Redis and
+// written once it is back.
+type AuditService interface {
+	Start()
+	Stop()
+	RecordAdjustments(actor, reason, source string, updates []*models.ScoreUpdatePayload) error
+	ListAdjustments(filter repository.AuditFilter) ([]models.AdminAdjustment, error)
+}
+
+type auditService struct {
+	auditRepo  repository.AuditRepository
+	redis      *redis.Client
+	flushEvery time.Duration
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+func NewAuditService(auditRepo repository.AuditRepository, redisClient *redis.Client, flushEvery time.Duration) AuditService {
+	return &auditService{
+		auditRepo:  auditRepo,
+		redis:      redisClient,
+		flushEvery: flushEvery,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start flushes the spool every flushEvery
+func (s *auditService) Start() {
+	if s.flushEvery <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.flushEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.flushSpool()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *auditService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+// RecordAdjustments stores one audit entry per applied update
+func (s *auditService) RecordAdjustments(actor, reason, source string, updates []*models.ScoreUpdatePayload) error {
+	now := time.Now()
+	entries := make([]models.AdminAdjustment, 0, len(updates))
+	for _, update := range updates {
+		if update == nil {
+			continue
+		}
+		entries = append(entries, models.AdminAdjustment{
+			UserID:    update.UserID,
+			Actor:     actor,
+			Reason:    reason,
+			Source:    source,
+			OldRating: update.OldRating,
+			NewRating: update.NewRating,
+			Change:    update.RatingDelta,
+			CreatedAt: now,
+		})
+	}
+
+	err := s.auditRepo.CreateBatch(entries)
+	if err == nil || s.redis == nil {
+		return err
+	}
+	if spoolErr := s.spool(entries); spoolErr != nil {
+		return err
+	}
+	slog.Warn("📥 Spooled audit entries for PostgreSQL", "entries", len(entries), "error", err)
+	return nil
+}
+
+func (s *auditService) ListAdjustments(filter repository.AuditFilter) ([]models.AdminAdjustment, error) {
+	return s.auditRepo.List(filter)
+}
+
+// spool appends entries to the Redis spool, keeping their creation time
+func (s *auditService) spool(entries []models.AdminAdjustment) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	values := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		values = append(values, data)
+	}
+	return s.redis.RPush(database.Ctx, database.AuditSpoolKey, values...).Err()
+}
+
+// flushSpool writes spooled entries to PostgreSQL in order. A batch that
+// fails is put back at the head of the spool for the next flush.
+func (s *auditService) flushSpool() {
+	if s.redis == nil {
+		return
+	}
+
+	written := 0
+	for {
+		raw, err := s.redis.LPopCount(database.Ctx, database.AuditSpoolKey, auditSpoolBatch).Result()
+		if err != nil || len(raw) == 0 {
+			break
+		}
+
+		entries := make([]models.AdminAdjustment, 0, len(raw))
+		for _, item := range raw {
+			var entry models.AdminAdjustment
+			if err := json.Unmarshal([]byte(item), &entry); err != nil {
+				slog.Warn("⚠️  Dropped unreadable spooled audit entry", "error", err)
+				continue
+			}
+			entries = append(entries, entry)
+		}
+
+		if err := s.auditRepo.CreateBatch(entries); err != nil {
+			back := make([]interface{}, len(raw))
+			for i, item := range raw {
+				back[len(raw)-1-i] = item
+			}
+			if err := s.redis.LPush(database.Ctx, database.AuditSpoolKey, back...).Err(); err != nil {
+				slog.Error("❌ Lost spooled audit entries", "entries", len(raw), "error", err)
+			}
+			break
+		}
+		written += len(entries)
+	}
+
+	if written > 0 {
+		slog.Info("📤 Wrote spooled audit entries to PostgreSQL", "entries", written)
+	}
+}
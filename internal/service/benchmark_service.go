@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// Largest benchmark a request may ask for
+	MaxBenchmarkOps = 5000
+
+	// Unmeasured operations per step, so pool dials don't count
+	benchmarkWarmup = 5
+)
+
+// ErrBenchmarkRunning is returned while this server is already benchmarking
+var ErrBenchmarkRunning = errors.New("a benchmark is already running on this server")
+
+// BenchmarkService times a small fixed set of Redis and PostgreSQL
+// operations against this server's stored baseline, to tell slow data
+// stores from slow application code during an incident
+type BenchmarkService interface {
+	Run(ops int, saveBaseline bool) (*models.BenchmarkReport, error)
+	Baseline() (*models.BenchmarkReport, error)
+}
+
+type benchmarkService struct {
+	cfg   config.BenchmarkConfig
+	redis *redis.Client
+	db    *gorm.DB
+	node  string
+
+	running sync.Mutex
+}
+
+func NewBenchmarkService(cfg config.BenchmarkConfig, redisClient *redis.Client, db *gorm.DB, node string) BenchmarkService {
+	return &benchmarkService{
+		cfg:   cfg,
+		redis: redisClient,
+		db:    db,
+		node:  node,
+	}
+}
+
+// benchmarkStep is one timed operation; i is the iteration
+type benchmarkStep struct {
+	name  string
+	store string
+	op    func(ctx context.Context, i int) error
+}
+
+// Run benchmarks ops operations per step (0 = the configured default),
+// compares the result with the baseline and, if asked, stores it as the
+// new baseline
+func (s *benchmarkService) Run(ops int, saveBaseline bool) (*models.BenchmarkReport, error) {
+	if !s.running.TryLock() {
+		return nil, ErrBenchmarkRunning
+	}
+	defer s.running.Unlock()
+
+	if ops <= 0 {
+		ops = s.cfg.Ops
+	}
+	if ops > MaxBenchmarkOps {
+		ops = MaxBenchmarkOps
+	}
+
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+
+	scratch := fmt.Sprintf(database.BenchScratchKey, s.node)
+	defer s.redis.Del(context.Background(), scratch)
+
+	steps := []benchmarkStep{
+		{"redis_zadd", "redis", func(ctx context.Context, i int) error {
+			return s.redis.ZAdd(ctx, scratch, redis.Z{Score: float64(i), Member: strconv.Itoa(i)}).Err()
+		}},
+		{"redis_zrange", "redis", func(ctx context.Context, i int) error {
+			return s.redis.ZRevRangeWithScores(ctx, scratch, 0, 9).Err()
+		}},
+		{"postgres_select", "postgres", func(ctx context.Context, i int) error {
+			var one int
+			return sqlDB.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+		}},
+		{"postgres_select_user", "postgres", func(ctx context.Context, i int) error {
+			var id uint
+			err := sqlDB.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1", i+1).Scan(&id)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}},
+	}
+
+	report := &models.BenchmarkReport{
+		Node:      s.node,
+		StartedAt: time.Now(),
+		Ops:       ops,
+	}
+	for _, step := range steps {
+		report.Results = append(report.Results, runBenchmarkStep(ctx, step, ops))
+	}
+	report.DurationMs = msSince(report.StartedAt)
+
+	baseline, err := s.Baseline()
+	if err != nil {
+		slog.Warn("⚠️  Failed to load benchmark baseline", "error", err)
+	}
+	s.compare(report, baseline)
+
+	if saveBaseline {
+		if err := s.saveBaseline(report); err != nil {
+			return nil, fmt.Errorf("failed to save baseline: %w", err)
+		}
+	}
+
+	slog.Info("⏱️  Benchmark finished", "node", s.node, "ops", ops, "verdict", report.Verdict, "degraded", report.Degraded)
+	return report, nil
+}
+
+// Baseline returns this server's stored baseline, or nil if none was saved
+func (s *benchmarkService) Baseline() (*models.BenchmarkReport, error) {
+	data, err := s.redis.HGet(database.Ctx, database.BenchBaselineKey, s.node).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline models.BenchmarkReport
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, err
+	}
+	return &baseline, nil
+}
+
+// saveBaseline stores the raw measurements (without the comparison)
+func (s *benchmarkService) saveBaseline(report *models.BenchmarkReport) error {
+	baseline := *report
+	baseline.Verdict, baseline.Degraded, baseline.BaselineAt = "", nil, nil
+	baseline.Results = make([]models.BenchmarkResult, len(report.Results))
+	for i, result := range report.Results {
+		result.BaselineP50, result.Ratio, result.Verdict = 0, 0, ""
+		baseline.Results[i] = result
+	}
+
+	data, err := json.Marshal(baseline)
+	if err != nil {
+		return err
+	}
+	return s.redis.HSet(database.Ctx, database.BenchBaselineKey, s.node, data).Err()
+}
+
+// compare sets per-step and overall verdicts against the baseline's p50
+func (s *benchmarkService) compare(report, baseline *models.BenchmarkReport) {
+	baselineP50 := map[string]float64{}
+	if baseline != nil {
+		report.BaselineAt = &baseline.StartedAt
+		for _, result := range baseline.Results {
+			baselineP50[result.Name] = result.P50
+		}
+	}
+
+	degraded := map[string]bool{}
+	report.Verdict = models.BenchmarkOK
+	for i := range report.Results {
+		result := &report.Results[i]
+
+		switch base, ok := baselineP50[result.Name]; {
+		case result.Errors > 0:
+			result.Verdict = models.BenchmarkFailed
+		case !ok || base <= 0:
+			result.Verdict = models.BenchmarkNoBaseline
+		default:
+			result.BaselineP50 = base
+			result.Ratio = result.P50 / base
+			result.Verdict = models.BenchmarkOK
+			if result.Ratio >= s.cfg.DegradedRatio {
+				result.Verdict = models.BenchmarkDegraded
+			}
+		}
+
+		if result.Verdict == models.BenchmarkFailed || result.Verdict == models.BenchmarkDegraded {
+			degraded[result.Store] = true
+		}
+		report.Verdict = worseVerdict(report.Verdict, result.Verdict)
+	}
+
+	for store := range degraded {
+		report.Degraded = append(report.Degraded, store)
+	}
+	sort.Strings(report.Degraded)
+}
+
+// worseVerdict ranks failed > degraded > no_baseline > ok
+func worseVerdict(a, b string) string {
+	rank := map[string]int{
+		models.BenchmarkOK:         0,
+		models.BenchmarkNoBaseline: 1,
+		models.BenchmarkDegraded:   2,
+		models.BenchmarkFailed:     3,
+	}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// runBenchmarkStep runs one step sequentially. Once the run's deadline has
+// passed the remaining operations count as errors.
+func runBenchmarkStep(ctx context.Context, step benchmarkStep, ops int) models.BenchmarkResult {
+	result := models.BenchmarkResult{Name: step.name, Store: step.store}
+
+	for i := 0; i < benchmarkWarmup && ctx.Err() == nil; i++ {
+		step.op(ctx, i)
+	}
+
+	latencies := make([]float64, 0, ops)
+	total := 0.0
+	for i := 0; i < ops; i++ {
+		if ctx.Err() != nil {
+			result.Errors += ops - i
+			break
+		}
+
+		start := time.Now()
+		if err := step.op(ctx, i); err != nil {
+			result.Errors++
+			continue
+		}
+		elapsed := msSince(start)
+		latencies = append(latencies, elapsed)
+		total += elapsed
+	}
+
+	if len(latencies) > 0 {
+		sort.Float64s(latencies)
+		result.Mean = total / float64(len(latencies))
+		result.P50 = percentile(latencies, 0.50)
+		result.P95 = percentile(latencies, 0.95)
+		result.P99 = percentile(latencies, 0.99)
+		result.Max = latencies[len(latencies)-1]
+	}
+	return result
+}
+
+func msSince(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
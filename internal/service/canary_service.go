@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// Probe alternates between two ratings at the very bottom of the board
+	canaryRatingLow  = 100
+	canaryRatingHigh = 101
+
+	canaryCommitPollInterval = 50 * time.Millisecond
+
+	// Usernames are at most 50 characters (users.username)
+	maxUsernameLength = 50
+)
+
+var (
+	canaryBroadcastLatency = metrics.NewHistogram("canary_broadcast_latency_seconds",
+		"Time from synthetic score update to pub/sub broadcast receipt", nil)
+	canaryCommitLatency = metrics.NewHistogram("canary_commit_latency_seconds",
+		"Time from synthetic score update to PostgreSQL commit", nil)
+	canaryFailures = metrics.NewCounterVec("canary_failures_total",
+		"Synthetic probes that failed or timed out", "stage")
+)
+
+// CanaryService runs a synthetic end-to-end probe through the score pipeline
+type CanaryService interface {
+	Start()
+	Stop()
+	ObserveBroadcast(payload *models.ScoreUpdatePayload)
+	Check(ctx context.Context) error
+	Status() CanaryStatus
+}
+
+// CanaryStatus is the outcome of the most recent probe
+type CanaryStatus struct {
+	ProbeUserID      uint      `json:"probe_user_id"`
+	LastRun          time.Time `json:"last_run"`
+	BroadcastLatency string    `json:"broadcast_latency"`
+	CommitLatency    string    `json:"commit_latency"`
+	LastError        string    `json:"last_error,omitempty"`
+}
+
+type canaryService struct {
+	cfg            config.CanaryConfig
+	node           string
+	leaderboardSvc LeaderboardService
+	userRepo       repository.UserRepository
+
+	mu               sync.Mutex
+	probeUserID      uint
+	expectedRating   int
+	startedAt        time.Time
+	broadcastCh      chan time.Duration
+	lastRun          time.Time
+	broadcastLatency time.Duration
+	commitLatency    time.Duration
+	lastErr          error
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+func NewCanaryService(
+	cfg config.CanaryConfig,
+	node string,
+	leaderboardSvc LeaderboardService,
+	userRepo repository.UserRepository,
+) CanaryService {
+	return &canaryService{
+		cfg:            cfg,
+		node:           node,
+		leaderboardSvc: leaderboardSvc,
+		userRepo:       userRepo,
+		stopCh:         make(chan struct{}),
+	}
+}
+
+// Start creates the probe user if needed and begins probing
+func (s *canaryService) Start() {
+	if !s.cfg.Enabled {
+		return
+	}
+
+	user, err := s.ensureProbeUser()
+	if err != nil {
+		slog.Error("❌ Canary disabled, failed to prepare probe user", "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	s.probeUserID = user.ID
+	s.mu.Unlock()
+
+	slog.Info("🐤 Canary started", "user_id", user.ID, "interval", s.cfg.Interval)
+
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.probe()
+			case <-s.stopCh:
+				slog.Info("⏹️  Canary stopped")
+				return
+			}
+		}
+	}()
+}
+
+func (s *canaryService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+// ensureProbeUser prepares this server's probe user. Each server probes its
+// own user, so concurrent probes never flip each other's rating. Probe users
+// are shadow-banned: off the public board and out of search, and their
+// updates reach every server but no WebSocket client.
+func (s *canaryService) ensureProbeUser() (*models.User, error) {
+	username := probeUsername(s.cfg.Username, s.node)
+
+	user, err := s.userRepo.GetByUsername(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user = &models.User{Username: username, Rating: canaryRatingLow, Status: models.UserStatusShadowBanned}
+		if err := s.userRepo.Create(user); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if user.Status != models.UserStatusShadowBanned {
+		if err := s.userRepo.UpdateStatus(user.ID, models.UserStatusShadowBanned); err != nil {
+			return nil, err
+		}
+		user.Status = models.UserStatusShadowBanned
+	}
+
+	if err := s.leaderboardSvc.SyncUserToLeaderboard(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// probeUsername names a server's probe user after the server, hashing the
+// node name when it would not fit
+func probeUsername(base, node string) string {
+	username := base + "-" + node
+	if len(username) <= maxUsernameLength {
+		return username
+	}
+	sum := sha256.Sum256([]byte(node))
+	return base + "-" + hex.EncodeToString(sum[:6])
+}
+
+// probe performs one synthetic update and waits for both downstream effects
+func (s *canaryService) probe() {
+	s.mu.Lock()
+	target := canaryRatingHigh
+	if s.expectedRating == canaryRatingHigh {
+		target = canaryRatingLow
+	}
+	s.expectedRating = target
+	s.broadcastCh = make(chan time.Duration, 1)
+	broadcastCh := s.broadcastCh
+	s.startedAt = time.Now()
+	userID := s.probeUserID
+	s.mu.Unlock()
+
+	start := time.Now()
+	if _, err := s.leaderboardSvc.UpdateUserScore(context.Background(), userID, target); err != nil {
+		s.record(0, 0, fmt.Errorf("update failed: %w", err))
+		canaryFailures.WithLabelValues("update").Inc()
+		return
+	}
+
+	// Wait for (a) pub/sub receipt and (b) Postgres commit in parallel
+	timeout := s.cfg.Interval
+	var (
+		wg               sync.WaitGroup
+		broadcastLatency time.Duration
+		commitLatency    time.Duration
+		broadcastErr     error
+		commitErr        error
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		select {
+		case broadcastLatency = <-broadcastCh:
+			canaryBroadcastLatency.Observe(broadcastLatency.Seconds())
+		case <-time.After(timeout):
+			broadcastErr = fmt.Errorf("broadcast not received within %v", timeout)
+			canaryFailures.WithLabelValues("broadcast").Inc()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		deadline := time.Now().Add(timeout)
+		for time.Now().Before(deadline) {
+			user, err := s.userRepo.GetByID(userID)
+			if err == nil && user.Rating == target {
+				commitLatency = time.Since(start)
+				canaryCommitLatency.Observe(commitLatency.Seconds())
+				return
+			}
+			time.Sleep(canaryCommitPollInterval)
+		}
+		commitErr = fmt.Errorf("postgres commit not observed within %v", timeout)
+		canaryFailures.WithLabelValues("commit").Inc()
+	}()
+	wg.Wait()
+
+	s.record(broadcastLatency, commitLatency, errors.Join(broadcastErr, commitErr))
+}
+
+func (s *canaryService) record(broadcast, commit time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRun = time.Now()
+	s.broadcastLatency = broadcast
+	s.commitLatency = commit
+	s.lastErr = err
+
+	if err != nil {
+		slog.Warn("⚠️  Canary probe failed", "error", err)
+	}
+}
+
+// ObserveBroadcast is called for every shadow score update received by this
+// server over pub/sub
+func (s *canaryService) ObserveBroadcast(payload *models.ScoreUpdatePayload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if payload.UserID != s.probeUserID || payload.NewRating != s.expectedRating || s.broadcastCh == nil {
+		return
+	}
+
+	select {
+	case s.broadcastCh <- time.Since(s.startedAt):
+	default:
+	}
+	s.broadcastCh = nil
+}
+
+// Check is a readiness check: fails if the last probe failed or was too slow
+func (s *canaryService) Check(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastRun.IsZero() {
+		return nil // no probe yet
+	}
+	if s.lastErr != nil {
+		return s.lastErr
+	}
+	if s.broadcastLatency > s.cfg.BroadcastThreshold {
+		return fmt.Errorf("broadcast latency %v exceeds %v", s.broadcastLatency, s.cfg.BroadcastThreshold)
+	}
+	if s.commitLatency > s.cfg.CommitThreshold {
+		return fmt.Errorf("commit latency %v exceeds %v", s.commitLatency, s.cfg.CommitThreshold)
+	}
+	return nil
+}
+
+// Status returns the latest probe result
+func (s *canaryService) Status() CanaryStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := CanaryStatus{
+		ProbeUserID:      s.probeUserID,
+		LastRun:          s.lastRun,
+		BroadcastLatency: s.broadcastLatency.String(),
+		CommitLatency:    s.commitLatency.String(),
+	}
+	if s.lastErr != nil {
+		status.LastError = s.lastErr.Error()
+	}
+	return status
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+type fakeCanaryUsers struct {
+	repository.UserRepository
+	users map[string]*models.User
+}
+
+func (f *fakeCanaryUsers) GetByUsername(username string) (*models.User, error) {
+	if user, ok := f.users[username]; ok {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeCanaryUsers) Create(user *models.User) error {
+	user.ID = uint(len(f.users) + 1)
+	stored := *user
+	f.users[user.Username] = &stored
+	return nil
+}
+
+func (f *fakeCanaryUsers) UpdateStatus(userID uint, status string) error {
+	for _, user := range f.users {
+		if user.ID == userID {
+			user.Status = status
+		}
+	}
+	return nil
+}
+
+type fakeCanaryBoard struct {
+	LeaderboardService
+	synced []models.User
+}
+
+func (f *fakeCanaryBoard) SyncUserToLeaderboard(user *models.User) error {
+	f.synced = append(f.synced, *user)
+	return nil
+}
+
+// TestCanaryProbeUserPerNode checks that each server gets its own probe
+// user, kept off the public board by being shadow-banned
+func TestCanaryProbeUserPerNode(t *testing.T) {
+	users := &fakeCanaryUsers{users: map[string]*models.User{
+		// A probe user created before probes were shadow-banned
+		"__canary__-api-2": {ID: 40, Username: "__canary__-api-2", Rating: 101, Status: models.UserStatusActive},
+	}}
+	cfg := config.CanaryConfig{Username: "__canary__"}
+
+	seen := make(map[uint]bool)
+	for _, node := range []string{"api-1", "api-2", "api-1"} {
+		board := &fakeCanaryBoard{}
+		s := NewCanaryService(cfg, node, board, users).(*canaryService)
+
+		user, err := s.ensureProbeUser()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.Username != "__canary__-"+node || user.Status != models.UserStatusShadowBanned {
+			t.Errorf("node %s probe user = %+v, want __canary__-%s shadow-banned", node, user, node)
+		}
+		if stored := users.users[user.Username]; stored.Status != models.UserStatusShadowBanned {
+			t.Errorf("stored status of %s = %s", user.Username, stored.Status)
+		}
+		if len(board.synced) != 1 || board.synced[0].Status != models.UserStatusShadowBanned {
+			t.Errorf("synced to the board as %+v, want shadow-banned", board.synced)
+		}
+		seen[user.ID] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("probe users = %v, want one per node", seen)
+	}
+}
+
+func TestProbeUsername(t *testing.T) {
+	if got := probeUsername("__canary__", "api-1"); got != "__canary__-api-1" {
+		t.Errorf("probeUsername = %q", got)
+	}
+
+	// Pod names can be longer than a username may be
+	long1 := probeUsername("__canary__", "leaderboard-api-7f9c6d5b8-"+strings.Repeat("x", 40))
+	long2 := probeUsername("__canary__", "leaderboard-api-7f9c6d5b8-"+strings.Repeat("y", 40))
+	if len(long1) > maxUsernameLength || long1 == long2 || !strings.HasPrefix(long1, "__canary__-") {
+		t.Errorf("long node names = %q, %q; want distinct names of at most %d characters", long1, long2, maxUsernameLength)
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+// Smallest batch the catch-up shrinks to after slow or failed commits
+const minCatchUpBatch = 100
+
+var (
+	catchUpActive = metrics.NewGaugeVec("db_catchup_active",
+		"1 while the DB sync drains a backlog after a PostgreSQL outage or slowdown", "tenant")
+	catchUpBacklog = metrics.NewGaugeVec("db_catchup_backlog",
+		"DB sync events not yet written to PostgreSQL, as seen by the catch-up controller", "tenant")
+	catchUpBatchSize = metrics.NewGaugeVec("db_catchup_batch_size",
+		"Events per DB sync transaction chosen by the catch-up controller", "tenant")
+	catchUpSynced = metrics.NewCounterVec("db_catchup_synced_total",
+		"DB sync events written by this server while catching up", "tenant")
+	writePressure = metrics.NewGaugeVec("db_sync_backpressure",
+		"Write backpressure from the DB sync backlog: 0 none, 1 slow down, 2 writes refused", "tenant")
+)
+
+// catchUp tracks PostgreSQL availability for the DB sync worker: it pauses
+// the worker during an outage, then sizes batches while the backlog drains
+// (doubling while commits stay well under the target time, halving when
+// they exceed it or fail) and derives the write backpressure level
+type catchUp struct {
+	cfg       config.PostgresOutageConfig
+	health    PostgresHealth
+	tenant    string
+	baseBatch int // the configured DB sync batch size
+	maxBatch  int
+
+	mu     sync.Mutex
+	status models.CatchUpStatus
+}
+
+func newCatchUp(cfg config.PostgresOutageConfig, health PostgresHealth, tenant string, baseBatch int) *catchUp {
+	maxBatch := cfg.MaxBatch
+	if maxBatch < baseBatch {
+		maxBatch = baseBatch
+	}
+	if maxBatch > MaxSyncBatchSize {
+		maxBatch = MaxSyncBatchSize
+	}
+
+	return &catchUp{
+		cfg:       cfg,
+		health:    health,
+		tenant:    tenant,
+		baseBatch: baseBatch,
+		maxBatch:  maxBatch,
+		status: models.CatchUpStatus{
+			State:      models.SyncStateHealthy,
+			MaxBacklog: cfg.MaxBacklog,
+			Pressure:   models.PressureNone,
+			BatchSize:  baseBatch,
+			ETASeconds: -1,
+		},
+	}
+}
+
+// paused reports whether the DB sync should stop writing: PostgreSQL is down
+func (c *catchUp) paused() bool {
+	return c.cfg.Enabled && c.health != nil && c.health.Down()
+}
+
+func (c *catchUp) batchSize() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status.BatchSize
+}
+
+func (c *catchUp) snapshot() models.CatchUpStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// pressure returns the backpressure level and the backlog it was derived from
+func (c *catchUp) pressure() (string, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status.Pressure, c.status.Backlog
+}
+
+// observe adapts the batch size to a commit of n events that took took
+func (c *catchUp) observe(n int, took time.Duration, committed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.status.State != models.SyncStateCatchingUp {
+		return
+	}
+	batch := c.status.BatchSize
+	switch {
+	case !committed || took > c.cfg.TargetCommit:
+		batch = max(batch/2, min(minCatchUpBatch, c.baseBatch))
+	case took < c.cfg.TargetCommit/2 && n >= batch:
+		batch = min(batch*2, c.maxBatch)
+	}
+	if batch != c.status.BatchSize {
+		c.status.BatchSize = batch
+		catchUpBatchSize.WithLabelValues(c.tenant).Set(float64(batch))
+	}
+	if committed {
+		c.status.Synced += int64(n)
+		catchUpSynced.WithLabelValues(c.tenant).Add(float64(n))
+	}
+}
+
+// refresh moves between the healthy, outage and catching-up states from
+// PostgreSQL's health and the current backlog, and updates progress
+func (c *catchUp) refresh(backlog int64) {
+	now := time.Now()
+	down := c.paused()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := &c.status
+	status.Backlog = backlog
+	status.PostgresDown = down
+	status.UpdatedAt = now
+
+	switch {
+	case down:
+		if status.State != models.SyncStateOutage {
+			status.State = models.SyncStateOutage
+			status.OutageSince = &now
+			status.CatchUpSince = nil
+			status.BatchSize = c.baseBatch
+		}
+	case status.State == models.SyncStateOutage && backlog < int64(status.BatchSize):
+		// Nothing worth catching up on
+		status.State = models.SyncStateHealthy
+		status.OutageSince = nil
+	case status.State == models.SyncStateOutage,
+		status.State == models.SyncStateHealthy && c.cfg.BackpressureAt > 0 && backlog >= c.cfg.BackpressureAt:
+		status.State = models.SyncStateCatchingUp
+		status.CatchUpSince = &now
+		status.BacklogAtStart = backlog
+		status.Synced = 0
+		slog.Info("🏃 DB sync catching up", "backlog", backlog, "tenant", c.tenant)
+	case status.State == models.SyncStateCatchingUp && backlog < int64(status.BatchSize):
+		slog.Info("✅ DB sync caught up", "tenant", c.tenant, "written", status.Synced,
+			"duration", now.Sub(*status.CatchUpSince).Round(time.Second))
+		status.State = models.SyncStateHealthy
+		status.OutageSince = nil
+		status.CatchUpSince = nil
+		status.BacklogAtStart = 0
+		status.BatchSize = c.baseBatch
+	}
+
+	// Progress: this server's write rate, and the ETA from how fast the
+	// backlog shrinks overall (all servers, net of new writes)
+	status.Rate, status.ETASeconds = 0, -1
+	if status.State == models.SyncStateCatchingUp {
+		if elapsed := now.Sub(*status.CatchUpSince).Seconds(); elapsed > 0 {
+			status.Rate = float64(status.Synced) / elapsed
+			if drain := float64(status.BacklogAtStart-backlog) / elapsed; drain > 0 {
+				status.ETASeconds = float64(backlog) / drain
+			}
+		}
+	}
+
+	status.Pressure = models.PressureNone
+	level := 0.0
+	switch {
+	case c.cfg.MaxBacklog > 0 && backlog >= c.cfg.MaxBacklog:
+		status.Pressure, level = models.PressureFull, 2
+	case c.cfg.BackpressureAt > 0 && backlog >= c.cfg.BackpressureAt:
+		status.Pressure, level = models.PressureSlow, 1
+	}
+
+	active := 0.0
+	if status.State == models.SyncStateCatchingUp {
+		active = 1
+	}
+	catchUpActive.WithLabelValues(c.tenant).Set(active)
+	catchUpBacklog.WithLabelValues(c.tenant).Set(float64(backlog))
+	catchUpBatchSize.WithLabelValues(c.tenant).Set(float64(status.BatchSize))
+	writePressure.WithLabelValues(c.tenant).Set(level)
+}
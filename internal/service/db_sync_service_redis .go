@@ -3,11 +3,18 @@ package service
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/logging"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -16,37 +23,91 @@ import (
 const (
 	ScoreUpdateStream = "stream:score_updates"
 	ConsumerGroup     = "db-sync-group"
-	ConsumerName      = "worker-1"
 
-	BatchSize    = 100
 	BlockTimeout = 5 * time.Second
 
-	StreamMaxLen      = 100     // keep last ~100 events
-	TrimEveryNBatches = 10      // trim once every 10 batches
+	// Each history row binds 6 parameters; PostgreSQL allows 65535 per statement
+	MaxSyncBatchSize = 10000
+
+	TrimEveryNBatches = 10 // trim once every 10 batches
+
+	// The worker is considered stuck when a loop iteration (a blocking read
+	// plus a batch write) takes longer than this
+	WorkerStallTimeout = time.Minute
 )
 
 type DBSyncService interface {
 	Start()
 	Stop()
+	Drain(timeout time.Duration) models.StreamDrainReport
 	EnqueueUpdate(item models.DBSyncQueueItem) error
+	HandleScoreUpdate(event eventbus.Event)
+	QueueDepth() (int64, error)
+	PurgeUser(userID uint) (int64, error)
+	CatchUpStatus() models.CatchUpStatus
+	Pressure() (string, int64)
+	Check(ctx context.Context) error
 }
 
 type dbSyncService struct {
 	redis        *redis.Client
+	consumer     string        // this process's consumer name in the group
+	expiry       time.Duration // idle consumers older than this are pruned
+	retainCount  int64
+	retainAge    time.Duration
+	drainTimeout time.Duration
+	flushEvery   time.Duration // wait this long to fill a batch once one event arrived
+	catchUp      *catchUp      // pauses during PostgreSQL outages, sizes batches
 	db           *gorm.DB
 	ctx          context.Context
 	stopCh       chan struct{}
+	stopOnce     sync.Once
 	running      bool
 	mu           sync.Mutex
 	batchCounter int
+
+	// readCtx bounds the blocking XREADGROUP; it is only cancelled when a
+	// drain runs out of time, so a normal drain never abandons a read
+	readCtx    context.Context
+	cancelRead context.CancelFunc
+	loops      sync.WaitGroup
+
+	// heartbeat is when the worker last started a loop iteration (UnixNano)
+	heartbeat atomic.Int64
 }
 
-func NewDBSyncService(redisClient *redis.Client, db *gorm.DB) DBSyncService {
+func NewDBSyncService(
+	redisClient *redis.Client,
+	db *gorm.DB,
+	cfg config.StreamConfig,
+	syncCfg config.DBSyncConfig,
+	outageCfg config.PostgresOutageConfig,
+	health PostgresHealth,
+	tenant string,
+) DBSyncService {
+	batchSize := syncCfg.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	if batchSize > MaxSyncBatchSize {
+		batchSize = MaxSyncBatchSize
+	}
+
+	readCtx, cancelRead := context.WithCancel(database.Ctx)
 	svc := &dbSyncService{
-		redis:  redisClient,
-		db:     db,
-		ctx:    database.Ctx,
-		stopCh: make(chan struct{}),
+		redis:        redisClient,
+		consumer:     cfg.Consumer,
+		expiry:       cfg.ConsumerExpiry,
+		retainCount:  cfg.RetainEntries,
+		retainAge:    cfg.RetainAge,
+		drainTimeout: cfg.DrainTimeout,
+		flushEvery:   syncCfg.FlushInterval,
+		catchUp:      newCatchUp(outageCfg, health, tenant, batchSize),
+		db:           db,
+		ctx:          database.Ctx,
+		stopCh:       make(chan struct{}),
+		readCtx:      readCtx,
+		cancelRead:   cancelRead,
 	}
 
 	svc.initStream()
@@ -63,7 +124,7 @@ func (s *dbSyncService) initStream() {
 	).Err()
 
 	if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
-		log.Fatalf("❌ Failed to create Redis consumer group: %v", err)
+		logging.Fatal("❌ Failed to create Redis consumer group", "error", err)
 	}
 }
 
@@ -76,18 +137,72 @@ func (s *dbSyncService) Start() {
 	}
 	s.running = true
 	s.mu.Unlock()
+	s.heartbeat.Store(time.Now().UnixNano())
 
-	log.Println("🔄 DB Sync Worker started (Redis Streams)")
+	slog.Info("🔄 DB Sync Worker started (Redis Streams)", "consumer", s.consumer)
+	s.loops.Add(2)
 	go s.worker()
+	go s.recoveryLoop()
+	if s.catchUp.cfg.Enabled {
+		s.loops.Add(1)
+		go s.catchUpLoop()
+	}
 }
 
+// Stop drains the worker within the configured deadline
 func (s *dbSyncService) Stop() {
-	close(s.stopCh)
-	log.Println("⏹️ DB Sync Worker stopping...")
+	s.Drain(s.drainTimeout)
+}
+
+// Drain stops reading new batches, waits up to timeout for the batch in
+// flight to be committed and acked, and reports what is left unsynced.
+// Events queued meanwhile stay in the stream for the other servers.
+func (s *dbSyncService) Drain(timeout time.Duration) models.StreamDrainReport {
+	start := time.Now()
+	s.stopOnce.Do(func() { close(s.stopCh) })
+	slog.Info("⏹️ DB Sync Worker draining...")
+
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		close(done)
+	}()
+
+	var report models.StreamDrainReport
+	select {
+	case <-done:
+		report.Completed = true
+	case <-time.After(timeout):
+		// Give up on a blocked read; whatever it was delivered stays
+		// pending on this consumer and is reclaimed by the others
+		slog.Warn("⚠️ DB sync drain deadline reached with a batch in flight", "timeout", timeout)
+	}
+	s.cancelRead()
+	report.Duration = time.Since(start)
+
+	var err error
+	if report.Pending, err = s.consumerPending(); err != nil {
+		slog.Warn("⚠️ Failed to count pending DB sync events", "error", err)
+	}
+	if report.Remaining, err = s.QueueDepth(); err != nil {
+		slog.Warn("⚠️ Failed to count unsynced DB sync events", "error", err)
+	}
+
+	slog.Info("⏹️ DB Sync Worker stopped", "duration", report.Duration.Round(time.Millisecond),
+		"drained", report.Completed, "consumer", s.consumer, "pending", report.Pending, "unsynced", report.Remaining)
+	return report
 }
 
 // Producer: add event to stream
 func (s *dbSyncService) EnqueueUpdate(item models.DBSyncQueueItem) error {
+	if item.EventID == "" {
+		eventID, err := randomHex(16)
+		if err != nil {
+			return err
+		}
+		item.EventID = eventID
+	}
+
 	data, err := json.Marshal(item)
 	if err != nil {
 		return err
@@ -101,90 +216,331 @@ func (s *dbSyncService) EnqueueUpdate(item models.DBSyncQueueItem) error {
 	}).Err()
 }
 
-// Worker loop
+// HandleScoreUpdate queues a published score update for PostgreSQL
+// (subscribed on the event bus of the server that accepted the update)
+func (s *dbSyncService) HandleScoreUpdate(event eventbus.Event) {
+	payload, ok := event.Payload.(*models.ScoreUpdatePayload)
+	if !ok {
+		return
+	}
+
+	err := s.EnqueueUpdate(models.DBSyncQueueItem{
+		UserID:    payload.UserID,
+		OldRating: payload.OldRating,
+		NewRating: payload.NewRating,
+		Timestamp: time.Now(),
+		RequestID: event.RequestID,
+	})
+	if err != nil {
+		// IMPORTANT: do NOT fail user flow
+		slog.Warn("⚠️ Failed to enqueue DB sync", "user_id", payload.UserID, "request_id", event.RequestID, "error", err)
+	}
+}
+
+// QueueDepth returns how many events are not yet synced to PostgreSQL:
+// entries never delivered to the group (lag) plus delivered-but-unacked ones
+func (s *dbSyncService) QueueDepth() (int64, error) {
+	groups, err := s.redis.XInfoGroups(s.ctx, ScoreUpdateStream).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, group := range groups {
+		if group.Name != ConsumerGroup {
+			continue
+		}
+		depth := group.Pending
+		if group.Lag > 0 {
+			depth += group.Lag
+		}
+		return depth, nil
+	}
+
+	return 0, nil
+}
+
+// CatchUpStatus reports PostgreSQL availability and catch-up progress
+func (s *dbSyncService) CatchUpStatus() models.CatchUpStatus {
+	return s.catchUp.snapshot()
+}
+
+// Check fails when the worker is not running or has not started a loop
+// iteration for WorkerStallTimeout, e.g. stuck on a hung write. A worker
+// paused by a PostgreSQL outage still passes: it is alive, waiting.
+func (s *dbSyncService) Check(ctx context.Context) error {
+	s.mu.Lock()
+	running := s.running
+	s.mu.Unlock()
+	if !running || s.stopping() {
+		return fmt.Errorf("worker not running")
+	}
+
+	if idle := time.Since(time.Unix(0, s.heartbeat.Load())); idle > WorkerStallTimeout {
+		return fmt.Errorf("worker stalled for %s", idle.Round(time.Second))
+	}
+	return nil
+}
+
+// Pressure returns the write backpressure level (models.Pressure*) and the
+// backlog it was derived from, as of the last catch-up refresh
+func (s *dbSyncService) Pressure() (string, int64) {
+	return s.catchUp.pressure()
+}
+
+// PurgeUser deletes a user's not-yet-synced events from the stream so a
+// purged user isn't written back to PostgreSQL
+func (s *dbSyncService) PurgeUser(userID uint) (int64, error) {
+	messages, err := s.redis.XRange(s.ctx, ScoreUpdateStream, "-", "+").Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []string
+	for _, msg := range messages {
+		raw, _ := msg.Values["data"].(string)
+
+		var item models.DBSyncQueueItem
+		if err := json.Unmarshal([]byte(raw), &item); err != nil {
+			continue
+		}
+		if item.UserID == userID {
+			ids = append(ids, msg.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Ack first so the entries also leave the pending list
+	pipe := s.redis.TxPipeline()
+	pipe.XAck(s.ctx, ScoreUpdateStream, ConsumerGroup, ids...)
+	deleted := pipe.XDel(s.ctx, ScoreUpdateStream, ids...)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return 0, err
+	}
+	return deleted.Val(), nil
+}
+
+// Worker loop. While PostgreSQL is down nothing is read, so events wait
+// in the stream undelivered instead of failing and being redelivered.
 func (s *dbSyncService) worker() {
+	defer s.loops.Done()
 	for {
 		select {
 		case <-s.stopCh:
 			return
 		default:
-			s.processBatch()
+		}
+		s.heartbeat.Store(time.Now().UnixNano())
+
+		if s.catchUp.paused() {
+			select {
+			case <-s.stopCh:
+				return
+			case <-time.After(s.catchUp.cfg.CheckEvery):
+			}
+			continue
+		}
+		s.processBatch()
+	}
+}
+
+// catchUpLoop refreshes the backlog behind the catch-up state, progress
+// and backpressure level
+func (s *dbSyncService) catchUpLoop() {
+	defer s.loops.Done()
+	ticker := time.NewTicker(s.catchUp.cfg.CheckEvery)
+	defer ticker.Stop()
+
+	for {
+		backlog, err := s.QueueDepth()
+		if err != nil {
+			slog.Warn("⚠️ Failed to read DB sync backlog", "error", err)
+		} else {
+			s.catchUp.refresh(backlog)
+		}
+
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
 		}
 	}
 }
 
-// Read + process messages
+// recoveryLoop periodically takes over events left pending by a server that
+// crashed between XREADGROUP and XACK (or by a batch whose commit failed)
+func (s *dbSyncService) recoveryLoop() {
+	defer s.loops.Done()
+	ticker := time.NewTicker(ClaimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			pruneConsumers(s.ctx, s.redis, ScoreUpdateStream, ConsumerGroup, s.consumer, s.expiry)
+			// Reclaiming counts as a delivery; during an outage it would
+			// push pending events toward the dead-letter stream
+			for !s.stopping() && !s.catchUp.paused() {
+				messages, err := reclaimPending(s.ctx, s.redis, ScoreUpdateStream, ConsumerGroup, s.consumer, int64(s.catchUp.batchSize()))
+				if err != nil {
+					slog.Warn("⚠️ Failed to reclaim pending DB sync events", "error", err)
+					break
+				}
+				if len(messages) == 0 || !s.syncTimed(messages) {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Read + process messages. Once an event arrives, reading continues for up
+// to the flush interval (or until the batch is full) so bursts are written
+// in few large transactions.
 func (s *dbSyncService) processBatch() {
-	streams, err := s.redis.XReadGroup(
-		s.ctx,
-		&redis.XReadGroupArgs{
-			Group:    ConsumerGroup,
-			Consumer: ConsumerName,
-			Streams:  []string{ScoreUpdateStream, ">"},
-			Count:    BatchSize,
-			Block:    BlockTimeout,
-		},
-	).Result()
+	var (
+		messages  []redis.XMessage
+		deadline  time.Time
+		batchSize = s.catchUp.batchSize()
+	)
 
-	if err != nil && err != redis.Nil {
-		log.Printf("⚠️ Redis XREADGROUP error: %v", err)
-		return
+	for len(messages) < batchSize {
+		block := BlockTimeout
+		if len(messages) > 0 {
+			// Block: 0 would wait forever, so stop below a millisecond
+			if block = time.Until(deadline); block < time.Millisecond {
+				break
+			}
+		}
+
+		streams, err := s.redis.XReadGroup(
+			s.readCtx,
+			&redis.XReadGroupArgs{
+				Group:    ConsumerGroup,
+				Consumer: s.consumer,
+				Streams:  []string{ScoreUpdateStream, ">"},
+				Count:    int64(batchSize - len(messages)),
+				Block:    block,
+			},
+		).Result()
+
+		if err != nil && err != redis.Nil {
+			if s.readCtx.Err() == nil {
+				slog.Warn("⚠️ Redis XREADGROUP error", "error", err)
+			}
+			break
+		}
+
+		read := 0
+		for _, stream := range streams {
+			messages = append(messages, stream.Messages...)
+			read += len(stream.Messages)
+		}
+		if read == 0 || s.flushEvery <= 0 || s.stopping() {
+			break
+		}
+		if deadline.IsZero() {
+			deadline = time.Now().Add(s.flushEvery)
+		}
 	}
 
-	if len(streams) == 0 {
+	if len(messages) == 0 || !s.syncTimed(messages) {
 		return
 	}
 
+	// Increment batch counter
+	s.batchCounter++
+
+	// Periodic stream trim (NON-BLOCKING maintenance)
+	if s.batchCounter%TrimEveryNBatches == 0 {
+		go s.trimStream()
+	}
+}
+
+// consumerPending counts the events delivered to this consumer but not acked
+func (s *dbSyncService) consumerPending() (int64, error) {
+	consumers, err := s.redis.XInfoConsumers(s.ctx, ScoreUpdateStream, ConsumerGroup).Result()
+	if err != nil {
+		return 0, err
+	}
+	for _, consumer := range consumers {
+		if consumer.Name == s.consumer {
+			return consumer.Pending, nil
+		}
+	}
+	return 0, nil
+}
+
+func (s *dbSyncService) stopping() bool {
+	select {
+	case <-s.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// syncTimed syncs a batch and reports its commit time to the catch-up
+func (s *dbSyncService) syncTimed(messages []redis.XMessage) bool {
+	start := time.Now()
+	committed := s.syncMessages(messages)
+	s.catchUp.observe(len(messages), time.Since(start), committed)
+	return committed
+}
+
+// syncMessages writes a batch to PostgreSQL in one transaction and acks it.
+// On failure the entries stay pending and are retried by the recovery loop
+// (delivery is at-least-once). Reports whether the batch was committed.
+func (s *dbSyncService) syncMessages(messages []redis.XMessage) bool {
 	var (
 		items      []models.DBSyncQueueItem
 		messageIDs []string
+		seen       = make(map[string]bool, len(messages))
 	)
 
-	for _, stream := range streams {
-		for _, msg := range stream.Messages {
-			raw := msg.Values["data"].(string)
+	for _, msg := range messages {
+		raw, _ := msg.Values["data"].(string)
 
-			var item models.DBSyncQueueItem
-			if err := json.Unmarshal([]byte(raw), &item); err != nil {
-				continue
-			}
+		var item models.DBSyncQueueItem
+		if err := json.Unmarshal([]byte(raw), &item); err != nil {
+			continue // left pending until dead-lettered
+		}
+		// Events queued before event IDs existed are keyed by stream entry
+		if item.EventID == "" {
+			item.EventID = "s-" + msg.ID
+		}
 
+		messageIDs = append(messageIDs, msg.ID)
+		if !seen[item.EventID] {
+			seen[item.EventID] = true
 			items = append(items, item)
-			messageIDs = append(messageIDs, msg.ID)
 		}
 	}
 
 	if len(items) == 0 {
-		return
+		return false
 	}
 
-	// DB transaction
-	err = s.db.Transaction(func(tx *gorm.DB) error {
-		for _, item := range items {
-			if err := tx.Model(&models.User{}).
-				Where("id = ?", item.UserID).
-				Update("rating", item.NewRating).Error; err != nil {
-				return err
-			}
-
-			history := models.ScoreUpdate{
-				UserID:    item.UserID,
-				OldRating: item.OldRating,
-				NewRating: item.NewRating,
-				Change:    item.NewRating - item.OldRating,
-				UpdatedAt: item.Timestamp,
-			}
-
-			if err := tx.Create(&history).Error; err != nil {
-				return err
-			}
+	// DB transaction: one INSERT for the history rows and one UPDATE for
+	// the ratings. An event already written (redelivered after a crash or
+	// reclaimed while still in flight) conflicts on its event ID and is
+	// skipped, so neither its history row nor its rating is applied twice.
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		inserted, err := insertHistory(tx, items)
+		if err != nil {
+			return err
 		}
-		return nil
+		return updateRatings(tx, items, inserted)
 	})
 
 	if err != nil {
-		log.Printf("❌ DB sync failed, retrying later: %v", err)
-		return
+		slog.Error("❌ DB sync failed, retrying later", "items", len(items),
+			"request_ids", batchRequestIDs(items), "error", err)
+		return false
 	}
 
 	// ACK messages ONLY after DB commit
@@ -195,28 +551,200 @@ func (s *dbSyncService) processBatch() {
 		messageIDs...,
 	)
 
-	// Increment batch counter
-	s.batchCounter++
+	// Last commit per consumer, for the sync status
+	s.redis.HSet(s.ctx, database.SyncLastKey, s.consumer, time.Now().UnixMilli())
 
-	// Periodic stream trim (NON-BLOCKING maintenance)
-	if s.batchCounter%TrimEveryNBatches == 0 {
-		go s.trimStream()
+	slog.Debug("💾 DB Sync success", "items", len(items), "request_ids", batchRequestIDs(items))
+	return true
+}
+
+// batchRequestIDs lists the distinct request IDs behind a batch, to trace
+// its writes back to the requests that made them
+func batchRequestIDs(items []models.DBSyncQueueItem) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if item.RequestID != "" && !seen[item.RequestID] {
+			seen[item.RequestID] = true
+			ids = append(ids, item.RequestID)
+		}
+	}
+	return ids
+}
+
+// insertHistory writes one score_updates row per new event and returns the
+// event IDs written. Events already stored, and those of users deleted or
+// purged after the event was queued, are left out.
+func insertHistory(tx *gorm.DB, items []models.DBSyncQueueItem) (map[string]bool, error) {
+	var (
+		query strings.Builder
+		args  = make([]interface{}, 0, len(items)*6)
+	)
+
+	query.WriteString(`INSERT INTO score_updates (user_id, old_rating, new_rating, change, updated_at, event_id)
+		SELECT v.user_id, v.old_rating, v.new_rating, v.change, v.updated_at, v.event_id
+		FROM (VALUES `)
+	for i, item := range items {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(?::bigint, ?::integer, ?::integer, ?::integer, ?::timestamptz, ?::text)")
+		args = append(args, item.UserID, item.OldRating, item.NewRating,
+			item.NewRating-item.OldRating, item.Timestamp, item.EventID)
+	}
+	query.WriteString(`) AS v(user_id, old_rating, new_rating, change, updated_at, event_id)
+		JOIN users u ON u.id = v.user_id
+		ON CONFLICT (event_id) DO NOTHING
+		RETURNING event_id`)
+
+	var eventIDs []string
+	if err := tx.Raw(query.String(), args...).Scan(&eventIDs).Error; err != nil {
+		return nil, err
 	}
 
-	log.Printf("💾 DB Sync success: %d items", len(items))
+	inserted := make(map[string]bool, len(eventIDs))
+	for _, id := range eventIDs {
+		inserted[id] = true
+	}
+	return inserted, nil
 }
 
+// updateRatings sets each user's rating to their newest inserted event
+// (items are in stream order, so the last one per user wins)
+func updateRatings(tx *gorm.DB, items []models.DBSyncQueueItem, inserted map[string]bool) error {
+	latest := make(map[uint]int)
+	var order []uint
+	for _, item := range items {
+		if !inserted[item.EventID] {
+			continue
+		}
+		if _, ok := latest[item.UserID]; !ok {
+			order = append(order, item.UserID)
+		}
+		latest[item.UserID] = item.NewRating
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	var (
+		query strings.Builder
+		args  = make([]interface{}, 0, len(order)*2)
+	)
+	query.WriteString("UPDATE users SET rating = v.rating FROM (VALUES ")
+	for i, userID := range order {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(?::bigint, ?::integer)")
+		args = append(args, userID, latest[userID])
+	}
+	query.WriteString(") AS v(id, rating) WHERE users.id = v.id")
+
+	return tx.Exec(query.String(), args...).Error
+}
+
+// trimStream applies the retention limits to processed entries only: the
+// cut never goes past the oldest pending entry or the group's last-delivered
+// ID, so events not yet in PostgreSQL survive a DB outage of any length
 func (s *dbSyncService) trimStream() {
-	err := s.redis.XTrimMaxLen(
-		s.ctx,
-		ScoreUpdateStream,
-		StreamMaxLen,
-	).Err()
+	safe, err := s.processedBoundary()
+	if err != nil {
+		slog.Warn("⚠️ Failed to trim Redis stream", "error", err)
+		return
+	}
+	if safe == "" {
+		return
+	}
+
+	// Retention cut: whichever limit removes more (none = everything processed)
+	cut := safe
+	if s.retainCount > 0 || s.retainAge > 0 {
+		cut = "0-0"
+		if s.retainAge > 0 {
+			cut = fmt.Sprintf("%d-0", time.Now().Add(-s.retainAge).UnixMilli())
+		}
+		if s.retainCount > 0 {
+			newest, err := s.redis.XRevRangeN(s.ctx, ScoreUpdateStream, "+", "-", s.retainCount).Result()
+			if err != nil {
+				slog.Warn("⚠️ Failed to trim Redis stream", "error", err)
+				return
+			}
+			if int64(len(newest)) == s.retainCount {
+				if oldestKept := newest[len(newest)-1].ID; compareStreamIDs(oldestKept, cut) > 0 {
+					cut = oldestKept
+				}
+			}
+		}
+		if compareStreamIDs(cut, safe) > 0 {
+			cut = safe
+		}
+	}
+	if cut == "0-0" {
+		return
+	}
 
+	trimmed, err := s.redis.XTrimMinID(s.ctx, ScoreUpdateStream, cut).Result()
 	if err != nil {
-		log.Printf("⚠️ Failed to trim Redis stream: %v", err)
+		slog.Warn("⚠️ Failed to trim Redis stream", "error", err)
 		return
 	}
+	if trimmed > 0 {
+		slog.Debug("🧹 Trimmed processed entries from Redis stream", "entries", trimmed)
+	}
+}
+
+// processedBoundary returns the ID below which every entry has been
+// delivered and acked: the oldest pending entry, else the last delivered
+// one ("" when nothing was delivered yet)
+func (s *dbSyncService) processedBoundary() (string, error) {
+	groups, err := s.redis.XInfoGroups(s.ctx, ScoreUpdateStream).Result()
+	if err != nil {
+		return "", err
+	}
+
+	boundary := ""
+	for _, group := range groups {
+		if group.Name == ConsumerGroup {
+			boundary = group.LastDeliveredID
+		}
+	}
+	if boundary == "" || boundary == "0-0" {
+		return "", nil
+	}
+
+	pending, err := s.redis.XPending(s.ctx, ScoreUpdateStream, ConsumerGroup).Result()
+	if err != nil {
+		return "", err
+	}
+	if pending.Count > 0 {
+		boundary = pending.Lower
+	}
+	return boundary, nil
+}
+
+// compareStreamIDs orders two "<ms>-<seq>" stream IDs
+func compareStreamIDs(a, b string) int {
+	aMs, aSeq := splitStreamID(a)
+	bMs, bSeq := splitStreamID(b)
+	switch {
+	case aMs != bMs:
+		if aMs < bMs {
+			return -1
+		}
+		return 1
+	case aSeq != bSeq:
+		if aSeq < bSeq {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
 
-	log.Printf("🧹 Trimmed Redis stream to ~%d entries", StreamMaxLen)
+func splitStreamID(id string) (uint64, uint64) {
+	msPart, seqPart, _ := strings.Cut(id, "-")
+	ms, _ := strconv.ParseUint(msPart, 10, 64)
+	seq, _ := strconv.ParseUint(seqPart, 10, 64)
+	return ms, seq
 }
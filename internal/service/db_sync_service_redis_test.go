@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testConsumer = "server-1"
+
+// newMockDB returns a PostgreSQL-dialect gorm DB backed by sqlmock, so the
+// statements carry $n placeholders as they would in production
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
+
+// newTestSync returns a sync service on miniredis and sqlmock, with the
+// consumer group created but no worker running
+func newTestSync(t *testing.T) (*dbSyncService, *miniredis.Miniredis, sqlmock.Sqlmock) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	db, mock := newMockDB(t)
+	s := &dbSyncService{redis: client, db: db, consumer: testConsumer, ctx: context.Background()}
+	s.initStream()
+	return s, mr, mock
+}
+
+// enqueue adds raw stream entries and delivers them to the test consumer
+func enqueue(t *testing.T, s *dbSyncService, entries ...string) []redis.XMessage {
+	t.Helper()
+	for _, data := range entries {
+		if err := s.redis.XAdd(s.ctx, &redis.XAddArgs{
+			Stream: ScoreUpdateStream,
+			Values: map[string]interface{}{"data": data},
+		}).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	streams, err := s.redis.XReadGroup(s.ctx, &redis.XReadGroupArgs{
+		Group:    ConsumerGroup,
+		Consumer: s.consumer,
+		Streams:  []string{ScoreUpdateStream, ">"},
+		Count:    int64(len(entries)),
+		Block:    -1,
+	}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return streams[0].Messages
+}
+
+func itemJSON(t *testing.T, item models.DBSyncQueueItem) string {
+	t.Helper()
+	data, err := json.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// pendingIDs lists the entries delivered to the group but not acked
+func pendingIDs(t *testing.T, s *dbSyncService) []string {
+	t.Helper()
+	pending, err := s.redis.XPendingExt(s.ctx, &redis.XPendingExtArgs{
+		Stream: ScoreUpdateStream, Group: ConsumerGroup, Start: "-", End: "+", Count: 100,
+	}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, 0, len(pending))
+	for _, p := range pending {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+// placeholders renders n VALUES rows of the given types, numbered from $1
+func placeholders(n int, types ...string) string {
+	rows := make([]string, n)
+	for i := range rows {
+		cols := make([]string, len(types))
+		for j, typ := range types {
+			cols[j] = fmt.Sprintf("$%d::%s", i*len(types)+j+1, typ)
+		}
+		rows[i] = "(" + strings.Join(cols, ", ") + ")"
+	}
+	return strings.Join(rows, ", ")
+}
+
+var historyTypes = []string{"bigint", "integer", "integer", "integer", "timestamptz", "text"}
+
+// expectInsert expects the history INSERT for items, returning the event
+// IDs listed in written
+func expectInsert(mock sqlmock.Sqlmock, items []models.DBSyncQueueItem, written ...string) {
+	var args []driver.Value
+	for _, item := range items {
+		args = append(args, item.UserID, item.OldRating, item.NewRating,
+			item.NewRating-item.OldRating, item.Timestamp, item.EventID)
+	}
+	rows := sqlmock.NewRows([]string{"event_id"})
+	for _, id := range written {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("(VALUES " + placeholders(len(items), historyTypes...) + ") AS v(")).
+		WithArgs(args...).
+		WillReturnRows(rows)
+}
+
+func TestInsertHistoryPlaceholders(t *testing.T) {
+	at := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, n := range []int{1, 2, 3, 10} {
+		t.Run(fmt.Sprintf("%d rows", n), func(t *testing.T) {
+			db, mock := newMockDB(t)
+			items := make([]models.DBSyncQueueItem, n)
+			var written []string
+			for i := range items {
+				items[i] = models.DBSyncQueueItem{
+					EventID: fmt.Sprintf("e%d", i), UserID: uint(i + 1),
+					OldRating: 1000, NewRating: 1000 + 10*i, Timestamp: at,
+				}
+				// The first row conflicts as already written
+				if i > 0 {
+					written = append(written, items[i].EventID)
+				}
+			}
+			expectInsert(mock, items, written...)
+
+			inserted, err := insertHistory(db, items)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(inserted) != len(written) || inserted["e0"] {
+				t.Errorf("inserted = %v, want %v", inserted, written)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestUpdateRatingsPlaceholders(t *testing.T) {
+	items := []models.DBSyncQueueItem{
+		{EventID: "e1", UserID: 7, NewRating: 1100},
+		{EventID: "e2", UserID: 9, NewRating: 1300},
+		{EventID: "e3", UserID: 7, NewRating: 1200}, // the newest for user 7 wins
+		{EventID: "e4", UserID: 9, NewRating: 1400}, // already written, not applied
+		{EventID: "e5", UserID: 3, NewRating: 900},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		inserted []string
+		wantSQL  string
+		wantArgs []driver.Value
+	}{
+		{
+			name:     "users in first-seen order",
+			inserted: []string{"e1", "e2", "e3", "e5"},
+			wantSQL: "UPDATE users SET rating = v.rating FROM (VALUES " +
+				placeholders(3, "bigint", "integer") + ") AS v(id, rating) WHERE users.id = v.id",
+			wantArgs: []driver.Value{7, 1200, 9, 1300, 3, 900},
+		},
+		{
+			name:     "single user",
+			inserted: []string{"e5"},
+			wantSQL: "UPDATE users SET rating = v.rating FROM (VALUES ($1::bigint, $2::integer))" +
+				" AS v(id, rating) WHERE users.id = v.id",
+			wantArgs: []driver.Value{3, 900},
+		},
+		{name: "nothing inserted"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			inserted := make(map[string]bool)
+			for _, id := range tc.inserted {
+				inserted[id] = true
+			}
+			if tc.wantSQL != "" {
+				mock.ExpectExec("^" + regexp.QuoteMeta(tc.wantSQL) + "$").
+					WithArgs(tc.wantArgs...).
+					WillReturnResult(sqlmock.NewResult(0, int64(len(tc.wantArgs)/2)))
+			}
+
+			if err := updateRatings(db, items, inserted); err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestSyncMessagesDedupsByEventID(t *testing.T) {
+	s, _, mock := newTestSync(t)
+	at := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	first := models.DBSyncQueueItem{EventID: "e1", UserID: 7, OldRating: 1000, NewRating: 1100, Timestamp: at}
+	other := models.DBSyncQueueItem{EventID: "e2", UserID: 9, OldRating: 1200, NewRating: 1250, Timestamp: at}
+	legacy := models.DBSyncQueueItem{UserID: 7, OldRating: 1100, NewRating: 1150, Timestamp: at}
+	messages := enqueue(t, s,
+		itemJSON(t, first),
+		itemJSON(t, first), // the same event queued twice
+		itemJSON(t, other),
+		itemJSON(t, legacy), // queued before event IDs, keyed by stream entry
+	)
+	legacy.EventID = "s-" + messages[3].ID
+
+	// Three distinct events; e2 was already written by another server
+	mock.ExpectBegin()
+	expectInsert(mock, []models.DBSyncQueueItem{first, other, legacy}, "e1", legacy.EventID)
+	mock.ExpectExec(regexp.QuoteMeta("(VALUES ($1::bigint, $2::integer)) AS v(id, rating)")).
+		WithArgs(7, 1150).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if !s.syncMessages(messages) {
+		t.Fatal("batch not committed")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	// Every entry is acked, duplicates included
+	if pending := pendingIDs(t, s); len(pending) != 0 {
+		t.Errorf("pending after commit = %v, want none", pending)
+	}
+	if last, err := s.redis.HGet(s.ctx, database.SyncLastKey, testConsumer).Result(); err != nil || last == "" {
+		t.Errorf("last sync for %s = %q, %v", testConsumer, last, err)
+	}
+}
+
+func TestSyncMessagesFailedCommitLeavesPending(t *testing.T) {
+	s, _, mock := newTestSync(t)
+	item := models.DBSyncQueueItem{EventID: "e1", UserID: 7, OldRating: 1000, NewRating: 1100}
+	messages := enqueue(t, s, itemJSON(t, item))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO score_updates")).
+		WillReturnError(fmt.Errorf("connection reset by peer"))
+	mock.ExpectRollback()
+
+	if s.syncMessages(messages) {
+		t.Fatal("failed batch reported as committed")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if pending := pendingIDs(t, s); len(pending) != 1 || pending[0] != messages[0].ID {
+		t.Errorf("pending = %v, want [%s]", pending, messages[0].ID)
+	}
+}
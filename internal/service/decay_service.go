@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/tiers"
+)
+
+// decayActor is the audit actor of decayed ratings
+const decayActor = "system:decay"
+
+var (
+	decayedUsers = metrics.NewCounter("decay_users_total",
+		"Ratings lowered by inactivity decay")
+	decayedPoints = metrics.NewCounter("decay_points_total",
+		"Rating points taken by inactivity decay")
+)
+
+// DecayReport summarises one decay run
+type DecayReport struct {
+	StartedAt time.Time
+	Duration  time.Duration
+	Inactive  int // users past DECAY_INACTIVE_AFTER
+	Decayed   int // of those, ratings lowered
+	Points    int // rating taken in total
+}
+
+// DecayService lowers the ratings of players with no score update for
+// DECAY_INACTIVE_AFTER, on DECAY_SCHEDULE, down to the floor of their tier
+type DecayService interface {
+	Run(ctx context.Context) (*DecayReport, error)
+	HandleScoreUpdate(event eventbus.Event)
+	HandleUserRemoved(event eventbus.Event)
+}
+
+type decayService struct {
+	cfg             config.DecayConfig
+	ladder          *tiers.Ladder
+	tierFloors      map[string]int // canonical tier name -> floor
+	decayRepo       repository.DecayRepository
+	userRepo        repository.UserRepository
+	leaderboardRepo repository.LeaderboardRepository
+	auditSvc        AuditService
+}
+
+func NewDecayService(
+	cfg config.DecayConfig,
+	ladder *tiers.Ladder,
+	decayRepo repository.DecayRepository,
+	userRepo repository.UserRepository,
+	leaderboardRepo repository.LeaderboardRepository,
+	auditSvc AuditService,
+) DecayService {
+	tierFloors := make(map[string]int, len(cfg.TierFloors))
+	for name, floor := range cfg.TierFloors {
+		if tier, ok := ladder.Find(name); ok {
+			tierFloors[tier.Name] = floor
+		}
+	}
+
+	return &decayService{
+		cfg:             cfg,
+		ladder:          ladder,
+		tierFloors:      tierFloors,
+		decayRepo:       decayRepo,
+		userRepo:        userRepo,
+		leaderboardRepo: leaderboardRepo,
+		auditSvc:        auditSvc,
+	}
+}
+
+// backfill seeds last activity from score history the first time decay runs
+// against a Redis (or after it lost its data). Until then every user looks
+// inactive, so a run cannot go ahead without it.
+func (s *decayService) backfill() error {
+	count, err := s.decayRepo.CountActivity()
+	if err != nil || count > 0 {
+		return err
+	}
+
+	var afterID uint
+	total := 0
+	for {
+		activity, err := s.userRepo.GetLastActivity(afterID, s.cfg.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read last activity: %w", err)
+		}
+		if len(activity) == 0 {
+			break
+		}
+		if err := s.decayRepo.Backfill(activity); err != nil {
+			return fmt.Errorf("failed to store last activity: %w", err)
+		}
+		total += len(activity)
+		afterID = activity[len(activity)-1].UserID
+	}
+
+	slog.Info("🍂 Backfilled last activity", "users", total)
+	return nil
+}
+
+// Run decays every inactive user once; the scheduler makes sure only one
+// server runs each occurrence
+func (s *decayService) Run(ctx context.Context) (*DecayReport, error) {
+	if err := s.backfill(); err != nil {
+		return nil, err
+	}
+
+	report := &DecayReport{StartedAt: time.Now()}
+	before := report.StartedAt.Add(-s.cfg.InactiveAfter)
+	reason := fmt.Sprintf("inactive for %v", s.cfg.InactiveAfter)
+
+	// Decay never touches activity, so offsets stay put; users who play
+	// meanwhile leave the range and may shift a few others to the next run
+	for offset := 0; ; offset += s.cfg.BatchSize {
+		userIDs, err := s.decayRepo.GetInactive(before, offset, s.cfg.BatchSize)
+		if err != nil {
+			return report, fmt.Errorf("failed to read inactive users: %w", err)
+		}
+		if len(userIDs) == 0 || ctx.Err() != nil {
+			break
+		}
+		report.Inactive += len(userIDs)
+
+		targets, err := s.targets(userIDs)
+		if err != nil {
+			return report, err
+		}
+		results, err := s.decayRepo.Apply(ScoreUpdateStream, targets, s.cfg.Amount, before)
+		if err != nil {
+			return report, fmt.Errorf("failed to decay ratings: %w", err)
+		}
+
+		updates := make([]*models.ScoreUpdatePayload, 0, len(results))
+		for _, result := range results {
+			updates = append(updates, &models.ScoreUpdatePayload{
+				UserID:      result.UserID,
+				OldRating:   result.OldRating,
+				NewRating:   result.NewRating,
+				RatingDelta: result.NewRating - result.OldRating,
+				Timestamp:   time.Now().Unix(),
+			})
+			report.Points += result.OldRating - result.NewRating
+		}
+		if err := s.auditSvc.RecordAdjustments(decayActor, reason, AdjustmentDecay, updates); err != nil {
+			slog.Warn("⚠️  Failed to audit decayed ratings", "ratings", len(updates), "error", err)
+		}
+		report.Decayed += len(results)
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+	decayedUsers.Add(float64(report.Decayed))
+	decayedPoints.Add(float64(report.Points))
+	slog.Info("🍂 Rating decay", "decayed", report.Decayed, "inactive", report.Inactive,
+		"points", report.Points, "duration", report.Duration.Round(time.Millisecond))
+	return report, nil
+}
+
+// targets pairs each user with their floor: DECAY_FLOOR, or their tier's
+// floor when that is higher. Users at or below it are skipped.
+func (s *decayService) targets(userIDs []uint) ([]repository.DecayTarget, error) {
+	ratings, err := s.leaderboardRepo.GetScores(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ratings: %w", err)
+	}
+
+	targets := make([]repository.DecayTarget, 0, len(userIDs))
+	for _, userID := range userIDs {
+		rating, ok := ratings[userID]
+		if !ok {
+			continue
+		}
+		floor := s.cfg.Floor
+		if tierFloor, ok := s.tierFloors[s.ladder.Place(rating).Tier]; ok && tierFloor > floor {
+			floor = tierFloor
+		}
+		if rating <= floor {
+			continue
+		}
+		eventID, err := randomHex(16)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, repository.DecayTarget{UserID: userID, Floor: floor, EventID: eventID})
+	}
+	return targets, nil
+}
+
+// HandleScoreUpdate marks the user active (subscribed on the server that
+// accepted the update, for public and shadow updates)
+func (s *decayService) HandleScoreUpdate(event eventbus.Event) {
+	payload, ok := event.Payload.(*models.ScoreUpdatePayload)
+	if !ok || !s.cfg.Enabled {
+		return
+	}
+	if err := s.decayRepo.Touch(payload.UserID, time.Unix(payload.Timestamp, 0)); err != nil {
+		slog.Warn("⚠️  Failed to record activity", "user_id", payload.UserID, "error", err)
+	}
+}
+
+// HandleUserRemoved forgets a deleted or purged user's activity
+func (s *decayService) HandleUserRemoved(event eventbus.Event) {
+	payload, ok := event.Payload.(*models.UserRemovedPayload)
+	if !ok || !s.cfg.Enabled {
+		return
+	}
+	if err := s.decayRepo.Forget(payload.UserID); err != nil {
+		slog.Warn("⚠️  Failed to forget activity", "user_id", payload.UserID, "error", err)
+	}
+}
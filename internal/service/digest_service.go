@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/notify"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+// Rank thresholds reported as digest milestones when crossed
+var digestRankMilestones = []int64{10, 100, 1000}
+
+// DigestService compiles and delivers daily rank-change summaries
+type DigestService interface {
+	Start()
+	Stop()
+	Subscribe(sub *models.DigestSubscription) error
+	GetSubscription(userID uint) (*models.DigestSubscription, error)
+	BuildDigest(userID uint, localDate time.Time) (*models.DigestSummary, error)
+}
+
+type digestService struct {
+	cfg             config.DigestConfig
+	digestRepo      repository.DigestRepository
+	rankHistoryRepo repository.RankHistoryRepository
+	scoreUpdateRepo repository.ScoreUpdateRepository
+	leaderboardRepo repository.LeaderboardRepository
+	leaderboardSvc  LeaderboardService
+	gate            NotificationGate
+	webhook         notify.Sender
+	email           notify.Sender
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+func NewDigestService(
+	cfg config.DigestConfig,
+	digestRepo repository.DigestRepository,
+	rankHistoryRepo repository.RankHistoryRepository,
+	scoreUpdateRepo repository.ScoreUpdateRepository,
+	leaderboardRepo repository.LeaderboardRepository,
+	leaderboardSvc LeaderboardService,
+	gate NotificationGate,
+	webhook notify.Sender,
+	email notify.Sender,
+) DigestService {
+	return &digestService{
+		cfg:             cfg,
+		digestRepo:      digestRepo,
+		rankHistoryRepo: rankHistoryRepo,
+		scoreUpdateRepo: scoreUpdateRepo,
+		leaderboardRepo: leaderboardRepo,
+		leaderboardSvc:  leaderboardSvc,
+		gate:            gate,
+		webhook:         webhook,
+		email:           email,
+		stopCh:          make(chan struct{}),
+	}
+}
+
+// Start periodically checks which timezones have reached the send hour
+func (s *digestService) Start() {
+	if !s.cfg.Enabled {
+		return
+	}
+
+	slog.Info("📬 Daily digest scheduler started", "send_hour", s.cfg.SendHour)
+
+	go func() {
+		ticker := time.NewTicker(s.cfg.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.runDue()
+			case <-s.stopCh:
+				slog.Info("⏹️  Digest scheduler stopped")
+				return
+			}
+		}
+	}()
+}
+
+func (s *digestService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+// Subscribe validates and stores a user's digest preferences
+func (s *digestService) Subscribe(sub *models.DigestSubscription) error {
+	if sub.Timezone == "" {
+		sub.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(sub.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q", sub.Timezone)
+	}
+	if sub.OptedIn && sub.WebhookURL == "" && sub.Email == "" {
+		return fmt.Errorf("webhook_url or email is required to opt in")
+	}
+	if sub.WebhookURL != "" {
+		if err := notify.ValidatePublicURL(sub.WebhookURL); err != nil {
+			return err
+		}
+	}
+	return s.digestRepo.Upsert(sub)
+}
+
+func (s *digestService) GetSubscription(userID uint) (*models.DigestSubscription, error) {
+	return s.digestRepo.GetByUserID(userID)
+}
+
+// runDue sends digests for every timezone whose local time is past the send hour
+func (s *digestService) runDue() {
+	timezones, err := s.digestRepo.GetTimezones()
+	if err != nil {
+		slog.Warn("⚠️  Failed to load digest timezones", "error", err)
+		return
+	}
+
+	for _, tz := range timezones {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			continue
+		}
+
+		now := time.Now().In(loc)
+		if now.Hour() < s.cfg.SendHour {
+			continue
+		}
+		localDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+		s.sendTimezone(tz, localDate)
+	}
+}
+
+// sendTimezone delivers digests for one timezone in batches. Each batch is
+// claimed before delivery, so every server may run this at once and each
+// digest still goes out once.
+func (s *digestService) sendTimezone(tz string, localDate time.Time) {
+	total := 0
+
+	for {
+		// Claimed digests count as sent even if delivery fails, so one bad
+		// endpoint isn't retried all day
+		subs, err := s.digestRepo.ClaimDue(tz, localDate, s.cfg.BatchSize)
+		if err != nil {
+			slog.Warn("⚠️  Failed to claim due digests", "timezone", tz, "error", err)
+			return
+		}
+		if len(subs) == 0 {
+			break
+		}
+
+		// Deliver the batch with bounded concurrency
+		var (
+			wg  sync.WaitGroup
+			sem = make(chan struct{}, s.cfg.Workers)
+		)
+		for _, sub := range subs {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(sub models.DigestSubscription) {
+				defer func() { <-sem; wg.Done() }()
+
+				if err := s.deliver(sub, localDate); err != nil {
+					slog.Warn("⚠️  Digest failed", "user_id", sub.UserID, "error", err)
+				}
+			}(sub)
+		}
+		wg.Wait()
+		total += len(subs)
+
+		if len(subs) < s.cfg.BatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("📬 Sent daily digests", "digests", total, "timezone", tz)
+	}
+}
+
+func (s *digestService) deliver(sub models.DigestSubscription, localDate time.Time) error {
+	// Channels the user's preferences (and quiet hours) allow right now.
+	// Skipped digests still count as sent for the day.
+	now := time.Now()
+	webhookURL, email := sub.WebhookURL, sub.Email
+	if webhookURL != "" && !s.gate.Allows(sub.UserID, models.NotifyDailyDigest, models.ChannelWebhook, now) {
+		webhookURL = ""
+	}
+	if email != "" && !s.gate.Allows(sub.UserID, models.NotifyDailyDigest, models.ChannelEmail, now) {
+		email = ""
+	}
+	if webhookURL == "" && email == "" {
+		return nil
+	}
+
+	summary, err := s.BuildDigest(sub.UserID, localDate)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	msg := notify.Message{
+		Event:   models.NotifyDailyDigest,
+		Subject: fmt.Sprintf("Your leaderboard summary for %s", summary.Date),
+		Payload: summary,
+	}
+
+	if webhookURL != "" {
+		msg.To = webhookURL
+		if err := s.webhook.Send(ctx, msg); err != nil {
+			return err
+		}
+	}
+	if email != "" {
+		msg.To = email
+		if err := s.email.Send(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BuildDigest compiles a user's rank change, nearby competitors and milestones
+func (s *digestService) BuildDigest(userID uint, localDate time.Time) (*models.DigestSummary, error) {
+	user, err := s.leaderboardRepo.GetCachedUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user %d not in cache: %w", userID, err)
+	}
+
+	rank, err := s.leaderboardRepo.GetUserRank(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.DigestSummary{
+		UserID:   userID,
+		Username: user.Username,
+		Date:     localDate.Format("2006-01-02"),
+		Rating:   user.Rating,
+		Rank:     rank,
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+
+	// Rating change over the last 24h
+	updates, err := s.scoreUpdateRepo.GetByUserSince(userID, since)
+	if err == nil {
+		for _, update := range updates {
+			summary.RatingChange += update.Change
+		}
+	}
+
+	// Rank change vs the oldest snapshot in the last 24h
+	if previous, err := s.rankHistoryRepo.GetFirstSince(userID, since); err == nil {
+		summary.PreviousRank = previous.Rank
+		summary.RankChange = previous.Rank - rank
+		summary.Milestones = append(summary.Milestones, crossedMilestones(previous.Rank, rank)...)
+	}
+
+	if nearby, err := s.leaderboardSvc.GetNeighbors(userID, s.cfg.NearbyRadius); err == nil {
+		summary.Nearby = nearby
+	}
+
+	return summary, nil
+}
+
+// crossedMilestones lists "entered top N" milestones between two ranks
+func crossedMilestones(previousRank, currentRank int64) []string {
+	var milestones []string
+	for _, threshold := range digestRankMilestones {
+		if previousRank > threshold && currentRank <= threshold {
+			milestones = append(milestones, fmt.Sprintf("entered_top_%d", threshold))
+		}
+	}
+	return milestones
+}
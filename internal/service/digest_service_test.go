@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/notify"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+type fakeDigestRepo struct {
+	repository.DigestRepository
+	upserted []*models.DigestSubscription
+}
+
+func (f *fakeDigestRepo) Upsert(sub *models.DigestSubscription) error {
+	f.upserted = append(f.upserted, sub)
+	return nil
+}
+
+func TestSubscribeRejectsUnsafeWebhooks(t *testing.T) {
+	for _, tc := range []struct {
+		url     string
+		wantErr error
+	}{
+		{"https://hooks.example.com/digest", nil},
+		{"http://hooks.example.com/digest", notify.ErrUnsafeWebhookURL},
+		{"https://169.254.169.254/latest/meta-data", notify.ErrUnsafeWebhookURL},
+		{"https://localhost:6379/", notify.ErrUnsafeWebhookURL},
+	} {
+		repo := &fakeDigestRepo{}
+		svc := NewDigestService(config.DigestConfig{}, repo, nil, nil, nil, nil, nil, nil, nil)
+
+		err := svc.Subscribe(&models.DigestSubscription{UserID: 7, OptedIn: true, WebhookURL: tc.url})
+		if !errors.Is(err, tc.wantErr) || (err == nil) != (len(repo.upserted) == 1) {
+			t.Errorf("Subscribe(%q) = %v, stored %d; want %v", tc.url, err, len(repo.upserted), tc.wantErr)
+		}
+	}
+}
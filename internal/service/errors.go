@@ -0,0 +1,56 @@
+package service
+
+import "errors"
+
+// ErrValidation is matched (errors.Is) by every error about invalid input,
+// such as ErrInvalidTimezone or ErrInvalidWebhook; the API answers them with
+// 400 and the error's own message
+var ErrValidation = errors.New("validation failed")
+
+// validationError is an error about invalid input, with its own message
+type validationError struct {
+	message string
+}
+
+func newValidationError(message string) error {
+	return &validationError{message: message}
+}
+
+func (e *validationError) Error() string {
+	return e.message
+}
+
+func (e *validationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Machine-readable error codes returned to clients (the code field of the
+// error envelope, see apierror)
+const (
+	CodeValidation          = "validation_failed"
+	CodeUserNotFound        = "user_not_found"
+	CodeUserNotOnBoard      = "user_not_on_board"
+	CodeUsernameTaken       = "username_taken"
+	CodeUserBanned          = "user_banned"
+	CodeRatingDeltaExceeded = "rating_delta_exceeded"
+	CodeUserBusy            = "user_busy"
+	CodeRedisUnavailable    = "redis_unavailable"
+	CodeSyncBacklogFull     = "sync_backlog_full"
+	CodeTierNotFound        = "tier_not_found"
+	CodeUpdateNotFound      = "update_not_found"
+	CodeTeamNotFound        = "team_not_found"
+	CodeTeamNameTaken       = "team_name_taken"
+	CodeTeamFull            = "team_full"
+	CodeAlreadyInTeam       = "already_in_team"
+	CodeNotTeamMember       = "not_team_member"
+	CodeJobNotFound         = "job_not_found"
+	CodeJobQueueFull        = "job_queue_full"
+	CodeSpikeNotFound       = "spike_not_found"
+	CodeSpikeRunning        = "spike_running"
+	CodeBenchmarkRunning    = "benchmark_running"
+	CodeReconcileRunning    = "reconcile_running"
+	CodeFlagNotFound        = "flag_not_found"
+	CodeWebhookNotFound     = "webhook_not_found"
+	CodeSessionNotFound     = "session_not_found"
+	CodeNoProtection        = "no_protection_mark"
+)
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// EventStream carries typed events when EVENT_TRANSPORT=stream. It is a
+// key, so a tenant's events are kept apart by its key prefix.
+const EventStream = "stream:events"
+
+// Events read per XREAD
+const eventStreamBatch = 500
+
+var (
+	eventStreamCaughtUp = metrics.NewCounter("event_stream_caught_up_total",
+		"Events a server read from the event stream after losing its Redis connection")
+	eventStreamGaps = metrics.NewCounter("event_stream_gaps_total",
+		"Times a server reconnected after events it missed had been trimmed from the event stream")
+)
+
+type eventStreamService struct {
+	*busState
+	redis        *redis.Client
+	maxLen       int64
+	onGap        func()
+	ctx          context.Context
+	cancelCtx    context.CancelFunc
+	running      bool
+	eventHandler func(*models.PubSubEvent)
+
+	// ID of the last event read; only the reading goroutine touches it
+	lastID string
+}
+
+// NewEventStreamService creates an event transport over a Redis Stream
+// instead of Pub/Sub. Every server reads the whole stream with XREAD from
+// the last event it read, so one that loses Redis for a while catches up
+// on reconnect instead of silently missing events. The stream keeps about
+// maxLen events; onGap runs when a server fell further behind than that.
+func NewEventStreamService(redisClient *redis.Client, maxLen int64, onGap func()) MessageBus {
+	ctx, cancel := context.WithCancel(database.Ctx)
+
+	return &eventStreamService{
+		busState:  newBusState("stream"),
+		redis:     redisClient,
+		maxLen:    maxLen,
+		onGap:     onGap,
+		ctx:       ctx,
+		cancelCtx: cancel,
+	}
+}
+
+// Start reads events published from now on, in a goroutine
+func (s *eventStreamService) Start() {
+	if s.running {
+		slog.Warn("⚠️  Event stream already running")
+		return
+	}
+	s.running = true
+
+	slog.Info("📡 Event stream started", "stream", EventStream)
+	go s.read()
+}
+
+// start finds the newest event, to read from after it. XREAD's "$" would
+// instead skip anything added between two reads.
+func (s *eventStreamService) start() bool {
+	for attempt := 0; s.ctx.Err() == nil; attempt++ {
+		latest, err := s.redis.XRevRangeN(s.ctx, EventStream, "+", "-", 1).Result()
+		if err == nil {
+			s.lastID = "0-0"
+			if len(latest) > 0 {
+				s.lastID = latest[0].ID
+			}
+			return true
+		}
+		s.down(err)
+		slog.Warn("⚠️  Failed to read the event stream position, retrying", "error", err)
+		s.sleep(reconnectBackoff(attempt))
+	}
+	return false
+}
+
+func (s *eventStreamService) read() {
+	defer func() {
+		s.running = false
+	}()
+
+	if !s.start() {
+		return
+	}
+	disconnected := false
+	for attempt := 0; s.ctx.Err() == nil; {
+		streams, err := s.redis.XRead(s.ctx, &redis.XReadArgs{
+			Streams: []string{EventStream, s.lastID},
+			Count:   eventStreamBatch,
+			Block:   BlockTimeout,
+		}).Result()
+		if err != nil && err != redis.Nil {
+			if s.ctx.Err() != nil {
+				break
+			}
+			s.down(err)
+			if !disconnected {
+				slog.Warn("⚠️  Event stream read failed, retrying", "error", err)
+				disconnected = true
+			}
+			s.sleep(reconnectBackoff(attempt))
+			attempt++
+			continue
+		}
+		s.up()
+		attempt = 0
+		if disconnected {
+			s.checkGap()
+		}
+
+		read := 0
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				s.lastID = message.ID
+				if data, ok := message.Values["data"].(string); ok {
+					deliverEvent([]byte(data), s.eventHandler)
+				}
+			}
+			read += len(stream.Messages)
+		}
+		if disconnected {
+			eventStreamCaughtUp.Add(float64(read))
+			slog.Info("✅ Event stream reconnected", "caught_up", read)
+			disconnected = false
+		}
+	}
+	slog.Info("⏹️  Event stream stopped")
+}
+
+// sleep waits d, or until Stop
+func (s *eventStreamService) sleep(d time.Duration) {
+	select {
+	case <-time.After(d):
+	case <-s.ctx.Done():
+	}
+}
+
+// checkGap reports events lost while Redis was unreachable. Trimming
+// removes the oldest events first, so if the last event read is still
+// there, so is everything after it.
+func (s *eventStreamService) checkGap() {
+	if s.lastID == "0-0" {
+		return
+	}
+	last, err := s.redis.XRange(s.ctx, EventStream, s.lastID, s.lastID).Result()
+	if err != nil || len(last) > 0 {
+		return
+	}
+
+	eventStreamGaps.Inc()
+	slog.Warn("⚠️  Event stream trimmed while Redis was unreachable; some events were missed", "last_id", s.lastID)
+	if s.onGap != nil {
+		s.onGap()
+	}
+}
+
+// Stop ends reading; a blocked XREAD returns within BlockTimeout
+func (s *eventStreamService) Stop() {
+	if !s.running {
+		s.cancelCtx() // never started (or already stopped): only release the context
+		return
+	}
+
+	slog.Info("⏹️  Stopping event stream...")
+	s.cancelCtx()
+}
+
+// OnEvent sets the handler for received events (call before Start)
+func (s *eventStreamService) OnEvent(handler func(*models.PubSubEvent)) {
+	s.eventHandler = handler
+}
+
+// PublishEvent appends a typed event to the stream, trimming it to about
+// EVENT_STREAM_MAXLEN events; every server (including this one) reads it
+func (s *eventStreamService) PublishEvent(ctx context.Context, eventType string, payload interface{}) error {
+	data, err := encodeEvent(ctx, eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	return s.redis.XAdd(s.ctx, &redis.XAddArgs{
+		Stream: EventStream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"data": data},
+	}).Err()
+}
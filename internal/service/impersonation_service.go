@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// ImpersonationTokenPrefix starts every impersonation token ("imp_<id>.<secret>")
+const ImpersonationTokenPrefix = "imp_"
+
+var (
+	ErrInvalidImpersonation = errors.New("invalid or expired impersonation token")
+	ErrSessionNotFound      = errors.New("impersonation session not found")
+)
+
+// ImpersonationService issues short-lived, read-only tokens that let an
+// admin see the API as a given user, and records everything done with them
+type ImpersonationService interface {
+	// Start opens a session and returns it with its token (shown once)
+	Start(admin string, userID uint, reason string, ttl time.Duration) (*models.ImpersonationSession, string, error)
+	// Resolve returns the live session a token belongs to
+	Resolve(token string) (*models.ImpersonationSession, error)
+	End(sessionID, admin string) error
+	RecordRequest(session *models.ImpersonationSession, method, path string, status int)
+	ListEvents(filter repository.ImpersonationFilter) ([]models.ImpersonationEvent, error)
+}
+
+type impersonationService struct {
+	cfg       config.ImpersonationConfig
+	redis     *redis.Client
+	userRepo  repository.UserRepository
+	eventRepo repository.ImpersonationRepository
+	ctx       context.Context
+}
+
+// storedSession keeps the token hash next to the session in Redis
+type storedSession struct {
+	models.ImpersonationSession
+	TokenHash string `json:"token_hash"`
+}
+
+func NewImpersonationService(
+	cfg config.ImpersonationConfig,
+	redisClient *redis.Client,
+	userRepo repository.UserRepository,
+	eventRepo repository.ImpersonationRepository,
+) ImpersonationService {
+	return &impersonationService{
+		cfg:       cfg,
+		redis:     redisClient,
+		userRepo:  userRepo,
+		eventRepo: eventRepo,
+		ctx:       database.Ctx,
+	}
+}
+
+func (s *impersonationService) Start(admin string, userID uint, reason string, ttl time.Duration) (*models.ImpersonationSession, string, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrUserNotFound
+		}
+		return nil, "", err
+	}
+
+	if ttl <= 0 {
+		ttl = s.cfg.DefaultTTL
+	}
+	if ttl > s.cfg.MaxTTL {
+		ttl = s.cfg.MaxTTL
+	}
+
+	id := newID()
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	stored := storedSession{
+		ImpersonationSession: models.ImpersonationSession{
+			ID:        id,
+			UserID:    userID,
+			Admin:     admin,
+			Reason:    reason,
+			CreatedAt: now,
+			ExpiresAt: now.Add(ttl),
+		},
+		TokenHash: hashSecret(secret),
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.redis.Set(s.ctx, fmt.Sprintf(database.ImpersonationKey, id), data, ttl).Err(); err != nil {
+		return nil, "", err
+	}
+
+	s.record(&stored.ImpersonationSession, models.ImpersonationEvent{
+		Event:  models.ImpersonationStarted,
+		Reason: reason,
+	})
+	slog.Info("🎭 Impersonation started", "session", id, "admin", admin,
+		"user_id", userID, "expires", stored.ExpiresAt)
+
+	return &stored.ImpersonationSession, ImpersonationTokenPrefix + id + "." + secret, nil
+}
+
+func (s *impersonationService) Resolve(token string) (*models.ImpersonationSession, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(token, ImpersonationTokenPrefix), ".")
+	if !ok || !strings.HasPrefix(token, ImpersonationTokenPrefix) || id == "" || secret == "" {
+		return nil, ErrInvalidImpersonation
+	}
+
+	stored, err := s.load(id)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return nil, ErrInvalidImpersonation
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(stored.TokenHash), []byte(hashSecret(secret))) != 1 {
+		return nil, ErrInvalidImpersonation
+	}
+	return &stored.ImpersonationSession, nil
+}
+
+func (s *impersonationService) End(sessionID, admin string) error {
+	stored, err := s.load(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := s.redis.Del(s.ctx, fmt.Sprintf(database.ImpersonationKey, sessionID)).Err(); err != nil {
+		return err
+	}
+
+	s.record(&stored.ImpersonationSession, models.ImpersonationEvent{
+		Event:  models.ImpersonationEnded,
+		Reason: "ended by " + admin,
+	})
+	slog.Info("🎭 Impersonation ended", "session", sessionID, "admin", admin)
+	return nil
+}
+
+// RecordRequest adds a request made with the session to the audit trail
+func (s *impersonationService) RecordRequest(session *models.ImpersonationSession, method, path string, status int) {
+	if len(path) > 500 {
+		path = path[:500]
+	}
+	s.record(session, models.ImpersonationEvent{
+		Event:  models.ImpersonationRequest,
+		Method: method,
+		Path:   path,
+		Status: status,
+	})
+}
+
+func (s *impersonationService) ListEvents(filter repository.ImpersonationFilter) ([]models.ImpersonationEvent, error) {
+	return s.eventRepo.List(filter)
+}
+
+func (s *impersonationService) load(id string) (*storedSession, error) {
+	data, err := s.redis.Get(s.ctx, fmt.Sprintf(database.ImpersonationKey, id)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stored storedSession
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (s *impersonationService) record(session *models.ImpersonationSession, event models.ImpersonationEvent) {
+	event.SessionID = session.ID
+	event.UserID = session.UserID
+	event.Admin = session.Admin
+	if err := s.eventRepo.Create(&event); err != nil {
+		slog.Warn("⚠️ Failed to record impersonation event", "event", event.Event, "session", session.ID, "error", err)
+	}
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
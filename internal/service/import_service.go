@@ -0,0 +1,320 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+// Import formats
+const (
+	ImportCSV    = "csv"
+	ImportNDJSON = "ndjson"
+)
+
+// JobTypeScoreImport is the job type of score uploads
+const JobTypeScoreImport = "score_import"
+
+const (
+	// Updates applied per bulk call while importing
+	importChunkSize = 500
+	// Rejected rows kept in a job's error report
+	importMaxReportedErrors = 10000
+)
+
+// ImportRowError explains why one input row was rejected
+type ImportRowError struct {
+	Row    int    `json:"row"` // 1-based data row (header excluded)
+	UserID uint   `json:"user_id,omitempty"`
+	Error  string `json:"error"`
+}
+
+// ImportResult is the stored outcome of an import job
+type ImportResult struct {
+	Applied         int              `json:"applied"`
+	Rejected        int              `json:"rejected"`
+	Errors          []ImportRowError `json:"errors"`
+	ErrorsTruncated bool             `json:"errors_truncated,omitempty"`
+}
+
+// ImportService parses score uploads, validates every row and applies the
+// valid ones through the bulk update pipeline as a background job
+type ImportService interface {
+	Start(actor, reason, format string, upload io.Reader) (*models.Job, error)
+	Errors(id string) ([]ImportRowError, error)
+}
+
+type importService struct {
+	cfg            config.ImportConfig
+	leaderboardSvc LeaderboardService
+	auditSvc       AuditService
+	jobSvc         JobService
+}
+
+func NewImportService(
+	cfg config.ImportConfig,
+	leaderboardSvc LeaderboardService,
+	auditSvc AuditService,
+	jobSvc JobService,
+) ImportService {
+	return &importService{
+		cfg:            cfg,
+		leaderboardSvc: leaderboardSvc,
+		auditSvc:       auditSvc,
+		jobSvc:         jobSvc,
+	}
+}
+
+// Start parses and validates the upload, then submits a job applying it.
+// Malformed rows are rejected up front and reported per row.
+func (s *importService) Start(actor, reason, format string, upload io.Reader) (*models.Job, error) {
+	var (
+		updates []models.ScoreUpdateRequest
+		rows    []int
+		rejects []ImportRowError
+		err     error
+	)
+	switch format {
+	case ImportCSV:
+		updates, rows, rejects, err = s.parseCSV(upload)
+	case ImportNDJSON:
+		updates, rows, rejects, err = s.parseNDJSON(upload)
+	default:
+		return nil, fmt.Errorf("unsupported format %q (use csv or ndjson)", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"format": format,
+		"reason": reason,
+		"rows":   len(updates) + len(rejects),
+	}
+	return s.jobSvc.Submit(JobTypeScoreImport, actor, params, func(ctx context.Context, progress *JobProgress) (interface{}, error) {
+		return s.run(ctx, progress, actor, reason, updates, rows, rejects)
+	})
+}
+
+// Errors returns the per-row rejects of an import job
+func (s *importService) Errors(id string) ([]ImportRowError, error) {
+	job, err := s.jobSvc.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Type != JobTypeScoreImport {
+		return nil, ErrJobNotFound
+	}
+
+	var result ImportResult
+	if len(job.Result) > 0 {
+		if err := json.Unmarshal(job.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode import result: %w", err)
+		}
+	}
+	return result.Errors, nil
+}
+
+// run applies validated updates in bulk chunks and audits the applied ones.
+// A cancelled import keeps what it already applied.
+func (s *importService) run(
+	ctx context.Context,
+	progress *JobProgress,
+	actor, reason string,
+	updates []models.ScoreUpdateRequest,
+	rows []int,
+	rejects []ImportRowError,
+) (*ImportResult, error) {
+	result := &ImportResult{}
+	addRejects := func(found []ImportRowError) {
+		result.Rejected += len(found)
+		for _, reject := range found {
+			if len(result.Errors) >= importMaxReportedErrors {
+				result.ErrorsTruncated = true
+				return
+			}
+			result.Errors = append(result.Errors, reject)
+		}
+	}
+
+	progress.SetTotal(int64(len(updates) + len(rejects)))
+	progress.Add(int64(len(rejects)))
+	addRejects(rejects)
+
+	for start := 0; start < len(updates); start += importChunkSize {
+		if ctx.Err() != nil {
+			break
+		}
+
+		end := start + importChunkSize
+		if end > len(updates) {
+			end = len(updates)
+		}
+
+		results := s.leaderboardSvc.BulkUpdateScores(ctx, updates[start:end])
+
+		applied := make([]*models.ScoreUpdatePayload, 0, len(results))
+		var chunkRejects []ImportRowError
+		for i, bulkResult := range results {
+			if bulkResult.Error != "" {
+				chunkRejects = append(chunkRejects, ImportRowError{
+					Row:    rows[start+i],
+					UserID: bulkResult.UserID,
+					Error:  bulkResult.Error,
+				})
+				continue
+			}
+			applied = append(applied, bulkResult.Update)
+		}
+
+		if err := s.auditSvc.RecordAdjustments(actor, reason, AdjustmentImport, applied); err != nil {
+			slog.Warn("⚠️  Failed to audit imported score changes", "changes", len(applied), "error", err)
+		}
+
+		result.Applied += len(applied)
+		addRejects(chunkRejects)
+		progress.Add(int64(len(results)))
+	}
+
+	slog.Info("📥 Import finished", "actor", actor, "applied", result.Applied, "rejected", result.Rejected)
+	return result, nil
+}
+
+// parseCSV reads rows of user_id,new_rating (header required; "rating" is
+// accepted for the second column)
+func (s *importService) parseCSV(upload io.Reader) ([]models.ScoreUpdateRequest, []int, []ImportRowError, error) {
+	reader := csv.NewReader(upload)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	userCol, ratingCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "user_id":
+			userCol = i
+		case "new_rating", "rating":
+			ratingCol = i
+		}
+	}
+	if userCol < 0 || ratingCol < 0 {
+		return nil, nil, nil, fmt.Errorf("CSV header must contain user_id and new_rating")
+	}
+
+	var (
+		updates []models.ScoreUpdateRequest
+		rows    []int
+		rejects []ImportRowError
+	)
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rejects = append(rejects, ImportRowError{Row: row, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if len(updates)+len(rejects) >= s.cfg.MaxRows {
+			return nil, nil, nil, fmt.Errorf("upload exceeds %d rows", s.cfg.MaxRows)
+		}
+		if len(record) <= userCol || len(record) <= ratingCol {
+			rejects = append(rejects, ImportRowError{Row: row, Error: "missing columns"})
+			continue
+		}
+
+		update, reject := validateImportRow(row, record[userCol], record[ratingCol])
+		if reject != nil {
+			rejects = append(rejects, *reject)
+			continue
+		}
+		updates = append(updates, update)
+		rows = append(rows, row)
+	}
+	return updates, rows, rejects, nil
+}
+
+// parseNDJSON reads one {"user_id":..,"new_rating":..} object per line
+func (s *importService) parseNDJSON(upload io.Reader) ([]models.ScoreUpdateRequest, []int, []ImportRowError, error) {
+	scanner := bufio.NewScanner(upload)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var (
+		updates []models.ScoreUpdateRequest
+		rows    []int
+		rejects []ImportRowError
+	)
+	row := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		row++
+		if len(updates)+len(rejects) >= s.cfg.MaxRows {
+			return nil, nil, nil, fmt.Errorf("upload exceeds %d rows", s.cfg.MaxRows)
+		}
+
+		var item struct {
+			UserID    json.Number `json:"user_id"`
+			NewRating json.Number `json:"new_rating"`
+			Rating    json.Number `json:"rating"`
+		}
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			rejects = append(rejects, ImportRowError{Row: row, Error: "invalid JSON"})
+			continue
+		}
+		rating := item.NewRating
+		if rating == "" {
+			rating = item.Rating
+		}
+
+		update, reject := validateImportRow(row, item.UserID.String(), rating.String())
+		if reject != nil {
+			rejects = append(rejects, *reject)
+			continue
+		}
+		updates = append(updates, update)
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read NDJSON: %w", err)
+	}
+	return updates, rows, rejects, nil
+}
+
+// validateImportRow applies the same rules as the bulk score endpoint
+func validateImportRow(row int, userIDStr, ratingStr string) (models.ScoreUpdateRequest, *ImportRowError) {
+	userID, err := strconv.ParseUint(strings.TrimSpace(userIDStr), 10, 32)
+	if err != nil || userID == 0 {
+		return models.ScoreUpdateRequest{}, &ImportRowError{Row: row, Error: "invalid user_id"}
+	}
+
+	rating, err := strconv.Atoi(strings.TrimSpace(ratingStr))
+	if err != nil {
+		return models.ScoreUpdateRequest{}, &ImportRowError{Row: row, UserID: uint(userID), Error: "invalid new_rating"}
+	}
+	if rating < 100 || rating > 5000 {
+		return models.ScoreUpdateRequest{}, &ImportRowError{Row: row, UserID: uint(userID), Error: "new_rating must be between 100 and 5000"}
+	}
+
+	return models.ScoreUpdateRequest{UserID: uint(userID), NewRating: rating}, nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/requestid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	IngestStream        = "stream:score_ingest"
+	IngestConsumerGroup = "score-ingest-group"
+)
+
+var ErrUpdateNotFound = errors.New("score update not found")
+
+// ingestItem is one queued score update
+type ingestItem struct {
+	ID        string `json:"id"`
+	UserID    uint   `json:"user_id"`
+	NewRating int    `json:"new_rating"`
+	Actor     string `json:"actor"`
+	Reason    string `json:"reason,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// IngestService accepts score updates into a Redis stream and applies them
+// in the background, for callers that don't need the rank delta right away.
+// Every server consumes the stream as part of one consumer group.
+type IngestService interface {
+	Start()
+	Stop()
+	Enqueue(ctx context.Context, userID uint, newRating int, actor, reason string) (*models.IngestStatus, error)
+	GetStatus(id string) (*models.IngestStatus, error)
+}
+
+type ingestService struct {
+	cfg            config.IngestConfig
+	streams        config.StreamConfig
+	redis          *redis.Client
+	ctx            context.Context
+	leaderboardSvc LeaderboardService
+	auditSvc       AuditService
+
+	stopCh    chan struct{}
+	once      sync.Once
+	lastClaim time.Time
+}
+
+func NewIngestService(
+	cfg config.IngestConfig,
+	streams config.StreamConfig,
+	redisClient *redis.Client,
+	leaderboardSvc LeaderboardService,
+	auditSvc AuditService,
+) IngestService {
+	return &ingestService{
+		cfg:            cfg,
+		streams:        streams,
+		redis:          redisClient,
+		ctx:            database.Ctx,
+		leaderboardSvc: leaderboardSvc,
+		auditSvc:       auditSvc,
+		stopCh:         make(chan struct{}),
+	}
+}
+
+// Start creates the consumer group (idempotent) and starts the consumer
+func (s *ingestService) Start() {
+	err := s.redis.XGroupCreateMkStream(s.ctx, IngestStream, IngestConsumerGroup, "0").Err()
+	if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
+		slog.Error("❌ Failed to create score ingest consumer group", "error", err)
+		return
+	}
+
+	slog.Info("📮 Score ingest consumer started", "consumer", s.streams.Consumer)
+	go func() {
+		for {
+			select {
+			case <-s.stopCh:
+				slog.Info("⏹️  Score ingest consumer stopped")
+				return
+			default:
+				s.processBatch()
+			}
+		}
+	}()
+}
+
+func (s *ingestService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+// Enqueue queues an update and returns its tracking status; the update is
+// applied under the request ID ctx carries
+func (s *ingestService) Enqueue(ctx context.Context, userID uint, newRating int, actor, reason string) (*models.IngestStatus, error) {
+	item := ingestItem{
+		ID:        newID(),
+		UserID:    userID,
+		NewRating: newRating,
+		Actor:     actor,
+		Reason:    reason,
+		RequestID: requestid.FromContext(ctx),
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.IngestStatus{
+		ID:        item.ID,
+		Status:    models.IngestQueued,
+		UserID:    userID,
+		NewRating: newRating,
+	}
+
+	// Status first, so a fast consumer can't finish before it exists
+	if err := s.saveStatus(status); err != nil {
+		return nil, fmt.Errorf("failed to record update status: %w", err)
+	}
+	if err := s.redis.XAdd(s.ctx, &redis.XAddArgs{
+		Stream: IngestStream,
+		MaxLen: s.cfg.MaxQueued,
+		Approx: true,
+		Values: map[string]interface{}{"data": data},
+	}).Err(); err != nil {
+		return nil, fmt.Errorf("failed to queue update: %w", err)
+	}
+	return status, nil
+}
+
+func (s *ingestService) GetStatus(id string) (*models.IngestStatus, error) {
+	data, err := s.redis.Get(s.ctx, fmt.Sprintf(database.IngestStatusKey, id)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrUpdateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var status models.IngestStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (s *ingestService) saveStatus(status *models.IngestStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(s.ctx, fmt.Sprintf(database.IngestStatusKey, status.ID), data, s.cfg.StatusTTL).Err()
+}
+
+// processBatch applies new entries (and, now and then, entries abandoned by
+// a dead consumer), acking each once its outcome is recorded
+func (s *ingestService) processBatch() {
+	var messages []redis.XMessage
+
+	if time.Since(s.lastClaim) > ClaimInterval {
+		s.lastClaim = time.Now()
+		pruneConsumers(s.ctx, s.redis, IngestStream, IngestConsumerGroup, s.streams.Consumer, s.streams.ConsumerExpiry)
+		claimed, err := reclaimPending(s.ctx, s.redis, IngestStream, IngestConsumerGroup, s.streams.Consumer, int64(s.cfg.BatchSize))
+		if err != nil {
+			slog.Warn("⚠️ Failed to reclaim pending score ingest entries", "error", err)
+		}
+		messages = append(messages, claimed...)
+	}
+
+	streams, err := s.redis.XReadGroup(s.ctx, &redis.XReadGroupArgs{
+		Group:    IngestConsumerGroup,
+		Consumer: s.streams.Consumer,
+		Streams:  []string{IngestStream, ">"},
+		Count:    int64(s.cfg.BatchSize),
+		Block:    BlockTimeout,
+	}).Result()
+	if err != nil && err != redis.Nil {
+		slog.Warn("⚠️ Score ingest XREADGROUP error", "error", err)
+		time.Sleep(time.Second)
+	}
+	for _, stream := range streams {
+		messages = append(messages, stream.Messages...)
+	}
+
+	for _, msg := range messages {
+		s.apply(msg)
+		s.redis.XAck(s.ctx, IngestStream, IngestConsumerGroup, msg.ID)
+		s.redis.XDel(s.ctx, IngestStream, msg.ID)
+	}
+}
+
+// apply runs one queued update through the normal score path. Updates set
+// an absolute rating, so re-applying after a crash is harmless.
+func (s *ingestService) apply(msg redis.XMessage) {
+	raw, _ := msg.Values["data"].(string)
+
+	var item ingestItem
+	if err := json.Unmarshal([]byte(raw), &item); err != nil {
+		slog.Warn("⚠️  Dropping malformed ingest entry", "entry", msg.ID, "error", err)
+		return
+	}
+
+	status := &models.IngestStatus{
+		ID:        item.ID,
+		UserID:    item.UserID,
+		NewRating: item.NewRating,
+	}
+
+	payload, err := s.leaderboardSvc.UpdateUserScore(requestid.NewContext(context.Background(), item.RequestID), item.UserID, item.NewRating)
+	if err != nil {
+		status.Status = models.IngestFailed
+		status.Error = err.Error()
+		switch {
+		case errors.Is(err, ErrRatingDeltaExceeded):
+			status.Code = CodeRatingDeltaExceeded
+		case errors.Is(err, ErrUserBusy):
+			status.Code = CodeUserBusy
+		}
+	} else {
+		status.Status = models.IngestApplied
+		status.Update = payload
+
+		if err := s.auditSvc.RecordAdjustments(item.Actor, item.Reason, AdjustmentAsync,
+			[]*models.ScoreUpdatePayload{payload}); err != nil {
+			slog.Warn("⚠️  Failed to audit async score change", "user_id", item.UserID, "actor", item.Actor, "error", err)
+		}
+	}
+
+	if err := s.saveStatus(status); err != nil {
+		slog.Warn("⚠️  Failed to record status of update", "update", item.ID, "error", err)
+	}
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	integrityPageSize = 10000
+	integritySamples  = 20
+)
+
+var (
+	integrityChecks = metrics.NewCounterVec("integrity_checks_total",
+		"Leaderboard checksum runs by outcome", "result")
+	integrityMismatchedUsers = metrics.NewGauge("integrity_mismatched_users",
+		"Users whose Redis and PostgreSQL ratings differed in the last check")
+	integrityMismatchedChunks = metrics.NewGauge("integrity_mismatched_chunks",
+		"Chunks whose Redis and PostgreSQL checksums differed in the last check")
+	integrityPeerMismatches = metrics.NewGauge("integrity_peer_mismatches",
+		"Servers whose view of the sorted set differed from this one in the last round")
+	integrityLastCheck = metrics.NewGauge("integrity_last_check_timestamp_seconds",
+		"Unix time of the last completed checksum run")
+)
+
+// IntegrityService periodically checksums the Redis sorted sets against
+// PostgreSQL and against the other servers, to catch silent corruption
+type IntegrityService interface {
+	Start()
+	Stop()
+	Check() *models.IntegrityReport
+	LastReport() *models.IntegrityReport
+}
+
+// peerReport is what each server shares about its view of the sorted set
+type peerReport struct {
+	Node          string         `json:"node"`
+	Round         time.Time      `json:"round"`
+	CheckedAt     time.Time      `json:"checked_at"`
+	RedisChecksum string         `json:"redis_checksum"`
+	ChunkHashes   map[int]string `json:"chunk_hashes"`
+}
+
+type integrityService struct {
+	cfg             config.IntegrityConfig
+	node            string
+	redis           *redis.Client
+	ctx             context.Context
+	leaderboardRepo repository.LeaderboardRepository
+	userRepo        repository.UserRepository
+
+	mu     sync.Mutex
+	last   *models.IntegrityReport
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+func NewIntegrityService(
+	cfg config.IntegrityConfig,
+	node string,
+	redisClient *redis.Client,
+	leaderboardRepo repository.LeaderboardRepository,
+	userRepo repository.UserRepository,
+) IntegrityService {
+	return &integrityService{
+		cfg:             cfg,
+		node:            node,
+		redis:           redisClient,
+		ctx:             database.Ctx,
+		leaderboardRepo: leaderboardRepo,
+		userRepo:        userRepo,
+		stopCh:          make(chan struct{}),
+	}
+}
+
+// Start runs a check at every interval boundary, so all servers checksum
+// in the same round and can compare with each other
+func (s *integrityService) Start() {
+	if s.cfg.Interval <= 0 {
+		slog.Info("⏸️  Integrity checks disabled")
+		return
+	}
+
+	slog.Info("🧮 Integrity checks started", "interval", s.cfg.Interval)
+	go func() {
+		for {
+			next := time.Now().Truncate(s.cfg.Interval).Add(s.cfg.Interval)
+			select {
+			case <-s.stopCh:
+				slog.Info("⏹️  Integrity checks stopped")
+				return
+			case <-time.After(time.Until(next)):
+				s.Check()
+			}
+		}
+	}()
+}
+
+func (s *integrityService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+func (s *integrityService) LastReport() *models.IntegrityReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// Check compares chunk checksums of Redis and PostgreSQL, re-checks the
+// users of differing chunks after the settle delay (only users unchanged on
+// both sides count as mismatches), then compares with peers' reports
+func (s *integrityService) Check() *models.IntegrityReport {
+	started := time.Now()
+	report := &models.IntegrityReport{
+		Node:  s.node,
+		Round: started.Truncate(s.interval()).UTC(),
+	}
+	defer func() {
+		report.CheckedAt = time.Now().UTC()
+		report.Duration = time.Since(started).Round(time.Millisecond).String()
+		s.mu.Lock()
+		s.last = report
+		s.mu.Unlock()
+		integrityLastCheck.Set(float64(report.CheckedAt.Unix()))
+	}()
+
+	redisScores, err := s.leaderboardRepo.GetAllScores(integrityPageSize)
+	if err != nil {
+		return s.fail(report, fmt.Errorf("failed to read sorted sets: %w", err))
+	}
+	pgRatings, err := s.loadRatings()
+	if err != nil {
+		return s.fail(report, fmt.Errorf("failed to read ratings: %w", err))
+	}
+
+	redisChunks := s.chunkHashes(redisScores)
+	pgChunks := s.chunkHashes(pgRatings)
+	report.RedisUsers = len(redisScores)
+	report.PostgresUsers = len(pgRatings)
+	report.RedisChecksum = combineChunks(redisChunks)
+	report.PostgresChecksum = combineChunks(pgChunks)
+	report.Chunks = len(redisChunks)
+	if len(pgChunks) > report.Chunks {
+		report.Chunks = len(pgChunks)
+	}
+
+	// Users of differing chunks whose values differ
+	differing := diffChunks(redisChunks, pgChunks)
+	report.MismatchedChunks = len(differing)
+	candidates := make([]uint, 0)
+	for userID := range unionIDs(redisScores, pgRatings) {
+		if !differing[s.chunkOf(userID)] {
+			continue
+		}
+		if r, ok := redisScores[userID]; ok {
+			if p, ok := pgRatings[userID]; ok && r == p {
+				continue
+			}
+		}
+		candidates = append(candidates, userID)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	if len(candidates) > 0 {
+		if err := s.verify(report, candidates, redisScores, pgRatings); err != nil {
+			return s.fail(report, err)
+		}
+	}
+
+	s.comparePeers(report, redisChunks)
+
+	integrityMismatchedUsers.Set(float64(report.Mismatches))
+	integrityMismatchedChunks.Set(float64(report.MismatchedChunks))
+	if report.Mismatches > 0 {
+		integrityChecks.WithLabelValues("mismatch").Inc()
+		slog.Error("🚨 Integrity check: users differ between Redis and PostgreSQL",
+			"mismatches", report.Mismatches, "in_flight", report.InFlight)
+	} else {
+		integrityChecks.WithLabelValues("ok").Inc()
+	}
+	return report
+}
+
+func (s *integrityService) interval() time.Duration {
+	if s.cfg.Interval > 0 {
+		return s.cfg.Interval
+	}
+	return time.Minute
+}
+
+func (s *integrityService) fail(report *models.IntegrityReport, err error) *models.IntegrityReport {
+	report.Error = err.Error()
+	integrityChecks.WithLabelValues("error").Inc()
+	slog.Warn("⚠️  Integrity check failed", "error", err)
+	return report
+}
+
+// loadRatings pages through PostgreSQL ratings of users expected on a board
+func (s *integrityService) loadRatings() (map[uint]int, error) {
+	ratings := make(map[uint]int)
+	var afterID uint
+	for {
+		users, err := s.userRepo.GetBoardRatings(afterID, integrityPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			ratings[user.ID] = user.Rating
+		}
+		if len(users) < integrityPageSize {
+			return ratings, nil
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
+
+// verify re-reads candidates on both sides after the settle delay. A user
+// whose values moved in between was mid-update; one that stayed put and
+// still differs is a real mismatch.
+func (s *integrityService) verify(report *models.IntegrityReport, candidates []uint, redisScores, pgRatings map[uint]int) error {
+	select {
+	case <-s.stopCh:
+		return fmt.Errorf("stopped")
+	case <-time.After(s.cfg.Settle):
+	}
+
+	redisNow, err := s.leaderboardRepo.GetScores(candidates)
+	if err != nil {
+		return fmt.Errorf("failed to re-read sorted sets: %w", err)
+	}
+	users, err := s.userRepo.GetBoardRatingsByIDs(candidates)
+	if err != nil {
+		return fmt.Errorf("failed to re-read ratings: %w", err)
+	}
+	pgNow := make(map[uint]int, len(users))
+	for _, user := range users {
+		pgNow[user.ID] = user.Rating
+	}
+
+	for _, userID := range candidates {
+		rBefore, rOK := redisScores[userID]
+		pBefore, pOK := pgRatings[userID]
+		rAfter, rNowOK := redisNow[userID]
+		pAfter, pNowOK := pgNow[userID]
+
+		if rOK != rNowOK || pOK != pNowOK || rBefore != rAfter || pBefore != pAfter {
+			report.InFlight++
+			continue
+		}
+		report.Mismatches++
+		if len(report.Samples) < integritySamples {
+			mismatch := models.IntegrityMismatch{UserID: userID}
+			if rOK {
+				mismatch.Redis = &rBefore
+			}
+			if pOK {
+				mismatch.Postgres = &pBefore
+			}
+			report.Samples = append(report.Samples, mismatch)
+		}
+	}
+	return nil
+}
+
+// comparePeers publishes this server's chunk hashes and compares them with
+// other servers' reports of the same round. Servers reading one Redis agree
+// unless writes landed between their reads; a sustained difference means a
+// server reads a diverged replica.
+func (s *integrityService) comparePeers(report *models.IntegrityReport, chunks map[int]string) {
+	own := peerReport{
+		Node:          s.node,
+		Round:         report.Round,
+		CheckedAt:     time.Now().UTC(),
+		RedisChecksum: report.RedisChecksum,
+		ChunkHashes:   chunks,
+	}
+	data, err := json.Marshal(own)
+	if err != nil {
+		return
+	}
+
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(s.ctx, database.IntegrityReportKey, s.node, data)
+	pipe.Expire(s.ctx, database.IntegrityReportKey, 3*s.interval())
+	all := pipe.HGetAll(s.ctx, database.IntegrityReportKey)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		slog.Warn("⚠️  Failed to share integrity report", "error", err)
+		return
+	}
+
+	mismatched := 0
+	for node, raw := range all.Val() {
+		if node == s.node {
+			continue
+		}
+		var peer peerReport
+		if err := json.Unmarshal([]byte(raw), &peer); err != nil || !peer.Round.Equal(report.Round) {
+			continue
+		}
+		result := models.IntegrityPeer{
+			Node:             peer.Node,
+			CheckedAt:        peer.CheckedAt,
+			RedisChecksum:    peer.RedisChecksum,
+			Match:            peer.RedisChecksum == report.RedisChecksum,
+			MismatchedChunks: len(diffChunks(chunks, peer.ChunkHashes)),
+		}
+		if !result.Match {
+			mismatched++
+		}
+		report.Peers = append(report.Peers, result)
+	}
+	sort.Slice(report.Peers, func(i, j int) bool { return report.Peers[i].Node < report.Peers[j].Node })
+	integrityPeerMismatches.Set(float64(mismatched))
+}
+
+func (s *integrityService) chunkOf(userID uint) int {
+	size := s.cfg.ChunkSize
+	if size <= 0 {
+		size = 1000
+	}
+	return int(userID) / size
+}
+
+// chunkHashes hashes the "id:rating" lines of each ID chunk in ID order
+func (s *integrityService) chunkHashes(ratings map[uint]int) map[int]string {
+	byChunk := make(map[int][]uint)
+	for userID := range ratings {
+		chunk := s.chunkOf(userID)
+		byChunk[chunk] = append(byChunk[chunk], userID)
+	}
+
+	hashes := make(map[int]string, len(byChunk))
+	for chunk, ids := range byChunk {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		h := fnv.New64a()
+		for _, id := range ids {
+			fmt.Fprintf(h, "%d:%d\n", id, ratings[id])
+		}
+		hashes[chunk] = fmt.Sprintf("%016x", h.Sum64())
+	}
+	return hashes
+}
+
+// combineChunks folds chunk hashes (in chunk order) into one checksum
+func combineChunks(chunks map[int]string) string {
+	keys := make([]int, 0, len(chunks))
+	for chunk := range chunks {
+		keys = append(keys, chunk)
+	}
+	sort.Ints(keys)
+
+	h := fnv.New64a()
+	for _, chunk := range keys {
+		fmt.Fprintf(h, "%d:%s\n", chunk, chunks[chunk])
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// diffChunks returns chunks present on one side only or hashed differently
+func diffChunks(a, b map[int]string) map[int]bool {
+	differing := make(map[int]bool)
+	for chunk, hash := range a {
+		if b[chunk] != hash {
+			differing[chunk] = true
+		}
+	}
+	for chunk := range b {
+		if _, ok := a[chunk]; !ok {
+			differing[chunk] = true
+		}
+	}
+	return differing
+}
+
+func unionIDs(a, b map[uint]int) map[uint]struct{} {
+	ids := make(map[uint]struct{}, len(a))
+	for id := range a {
+		ids[id] = struct{}{}
+	}
+	for id := range b {
+		ids[id] = struct{}{}
+	}
+	return ids
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrJobNotFound  = errors.New("job not found")
+	ErrJobQueueFull = errors.New("job queue is full")
+)
+
+// JobFunc does the work of a job. It should report progress and return
+// promptly once ctx is cancelled; its result is stored as JSON.
+type JobFunc func(ctx context.Context, progress *JobProgress) (interface{}, error)
+
+// JobProgress is updated by a running job and persisted periodically
+type JobProgress struct {
+	processed atomic.Int64
+	total     atomic.Int64
+}
+
+func (p *JobProgress) SetTotal(total int64) { p.total.Store(total) }
+func (p *JobProgress) Add(n int64)          { p.processed.Add(n) }
+
+// JobService runs long-running admin operations on a bounded worker pool,
+// with persisted status and progress, and cancellation from any server
+type JobService interface {
+	Start()
+	Stop()
+	Submit(jobType, actor string, params interface{}, run JobFunc) (*models.Job, error)
+	Get(id string) (*models.Job, error)
+	List(filter repository.JobFilter) ([]models.Job, error)
+	Cancel(id string) error
+}
+
+type queuedJob struct {
+	job *models.Job
+	run JobFunc
+}
+
+type jobService struct {
+	cfg     config.JobConfig
+	node    string
+	jobRepo repository.JobRepository
+
+	queue  chan queuedJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+func NewJobService(cfg config.JobConfig, jobRepo repository.JobRepository) JobService {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &jobService{
+		cfg:     cfg,
+		node:    cfg.Node,
+		jobRepo: jobRepo,
+		queue:   make(chan queuedJob, cfg.QueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+		running: make(map[string]context.CancelFunc),
+	}
+}
+
+// Start fails jobs orphaned by a previous run and starts the workers
+func (s *jobService) Start() {
+	if orphaned, err := s.jobRepo.FailOrphaned(s.node); err != nil {
+		slog.Warn("⚠️  Failed to clean up orphaned jobs", "error", err)
+	} else if orphaned > 0 {
+		slog.Info("🧹 Marked jobs interrupted by the last restart as failed", "jobs", orphaned)
+	}
+
+	for i := 0; i < s.cfg.Workers; i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for {
+				select {
+				case item := <-s.queue:
+					s.execute(item)
+				case <-s.ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	slog.Info("🧰 Job workers started", "workers", s.cfg.Workers, "node", s.node)
+}
+
+// Stop cancels running jobs and waits for the workers to return
+func (s *jobService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+	slog.Info("⏹️  Job workers stopped")
+}
+
+// Submit records a queued job and hands it to a worker
+func (s *jobService) Submit(jobType, actor string, params interface{}, run JobFunc) (*models.Job, error) {
+	var encoded models.RawJSON
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode job params: %w", err)
+		}
+		encoded = data
+	}
+
+	job := &models.Job{
+		ID:     newID(),
+		Type:   jobType,
+		Status: models.JobQueued,
+		Actor:  actor,
+		Node:   s.node,
+		Params: encoded,
+	}
+	if err := s.jobRepo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	select {
+	case s.queue <- queuedJob{job: job, run: run}:
+	default:
+		s.jobRepo.Finish(job.ID, models.JobFailed, nil, ErrJobQueueFull.Error())
+		return nil, ErrJobQueueFull
+	}
+
+	slog.Info("🧰 Job queued", "job", job.ID, "type", jobType, "actor", actor)
+	return job, nil
+}
+
+func (s *jobService) Get(id string) (*models.Job, error) {
+	job, err := s.jobRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrJobNotFound
+	}
+	return job, err
+}
+
+func (s *jobService) List(filter repository.JobFilter) ([]models.Job, error) {
+	return s.jobRepo.List(filter)
+}
+
+// Cancel flags the job in PostgreSQL (seen by whichever server runs it) and
+// stops it right away when it runs here
+func (s *jobService) Cancel(id string) error {
+	if err := s.jobRepo.RequestCancel(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrJobNotFound
+		}
+		return err
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.running[id]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// execute runs one job, flushing progress and polling for cancellation
+func (s *jobService) execute(item queuedJob) {
+	job := item.job
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	s.running[job.ID] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, job.ID)
+		s.mu.Unlock()
+	}()
+
+	// Cancelled while queued
+	if cancelled, err := s.jobRepo.UpdateProgress(job.ID, 0, 0); err == nil && cancelled {
+		s.jobRepo.Finish(job.ID, models.JobCancelled, nil, "")
+		return
+	}
+	if err := s.jobRepo.MarkRunning(job.ID); err != nil {
+		slog.Warn("⚠️  Failed to mark job running", "job", job.ID, "error", err)
+	}
+
+	progress := &JobProgress{}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.cfg.ProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				cancelled, err := s.jobRepo.UpdateProgress(job.ID, progress.processed.Load(), progress.total.Load())
+				if err == nil && cancelled {
+					cancel()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	started := time.Now()
+	result, runErr := s.safeRun(ctx, item.run, progress)
+	close(done)
+
+	// Final progress, then the terminal state
+	s.jobRepo.UpdateProgress(job.ID, progress.processed.Load(), progress.total.Load())
+
+	var encoded models.RawJSON
+	if result != nil {
+		if data, err := json.Marshal(result); err == nil {
+			encoded = data
+		}
+	}
+
+	status, errMsg := models.JobCompleted, ""
+	switch {
+	case ctx.Err() != nil:
+		status = models.JobCancelled
+		if s.ctx.Err() != nil {
+			status, errMsg = models.JobFailed, "interrupted by server shutdown"
+		}
+	case runErr != nil:
+		status, errMsg = models.JobFailed, runErr.Error()
+	}
+
+	if err := s.jobRepo.Finish(job.ID, status, encoded, errMsg); err != nil {
+		slog.Warn("⚠️  Failed to record job result", "job", job.ID, "error", err)
+	}
+	slog.Info("🧰 Job finished", "job", job.ID, "type", job.Type, "status", status, "duration", time.Since(started).Round(time.Millisecond))
+}
+
+// safeRun keeps a panicking job from taking down its worker
+func (s *jobService) safeRun(ctx context.Context, run JobFunc, progress *JobProgress) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run(ctx, progress)
+}
+
+// newID returns a random 12-hex-character identifier (jobs, lock tokens)
+func newID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	// How often an idle Kafka bus checks that the partition leader answers
+	kafkaProbeInterval = 30 * time.Second
+	kafkaWriteTimeout  = 5 * time.Second
+)
+
+type kafkaBus struct {
+	*busState
+	brokers      []string
+	topic        string
+	reader       *kafka.Reader
+	writer       *kafka.Writer
+	ctx          context.Context
+	cancelCtx    context.CancelFunc
+	running      bool
+	eventHandler func(*models.PubSubEvent)
+}
+
+// NewKafkaBus creates an event transport over a Kafka topic, for
+// deployments that already run Kafka. Events go to partition 0, so they
+// keep their order, and every server reads that partition without a
+// consumer group, from the newest event on. A server that loses the
+// brokers resumes from the last event it read, like the stream transport.
+func NewKafkaBus(brokers []string, topic string) (MessageBus, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("kafka: no brokers")
+	}
+	ctx, cancel := context.WithCancel(database.Ctx)
+
+	return &kafkaBus{
+		busState: newBusState("kafka"),
+		brokers:  brokers,
+		topic:    topic,
+		writer: &kafka.Writer{
+			Addr:  kafka.TCP(brokers...),
+			Topic: topic,
+			Balancer: kafka.BalancerFunc(func(kafka.Message, ...int) int {
+				return 0
+			}),
+			RequiredAcks:           kafka.RequireOne,
+			BatchTimeout:           5 * time.Millisecond,
+			WriteTimeout:           kafkaWriteTimeout,
+			AllowAutoTopicCreation: true,
+		},
+		ctx:       ctx,
+		cancelCtx: cancel,
+	}, nil
+}
+
+// Start reads events published from now on, in a goroutine
+func (s *kafkaBus) Start() {
+	if s.running {
+		slog.Warn("⚠️  Kafka bus already running")
+		return
+	}
+	s.running = true
+
+	s.reader = kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   s.brokers,
+		Topic:     s.topic,
+		Partition: 0,
+		MaxWait:   BlockTimeout,
+	})
+	if err := s.reader.SetOffset(kafka.LastOffset); err != nil {
+		slog.Warn("⚠️  Failed to start the Kafka bus at the newest event", "error", err)
+	}
+
+	slog.Info("📡 Kafka bus started", "topic", s.topic)
+	go s.read()
+	go s.probe()
+}
+
+func (s *kafkaBus) read() {
+	disconnected := false
+	for attempt := 0; s.ctx.Err() == nil; {
+		message, err := s.reader.ReadMessage(s.ctx)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.down(err)
+			if !disconnected {
+				slog.Warn("⚠️  Kafka read failed, retrying", "error", err)
+				disconnected = true
+			}
+			s.sleep(reconnectBackoff(attempt))
+			attempt++
+			continue
+		}
+		s.up()
+		attempt = 0
+		if disconnected {
+			slog.Info("✅ Kafka bus reconnected", "offset", message.Offset)
+			disconnected = false
+		}
+		deliverEvent(message.Value, s.eventHandler)
+	}
+}
+
+// probe asks the partition leader for the newest offset now and every
+// kafkaProbeInterval, since an idle reader only notices a lost broker
+// when it next fails a read
+func (s *kafkaBus) probe() {
+	ticker := time.NewTicker(kafkaProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ping(); err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.down(err)
+		} else {
+			s.up()
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *kafkaBus) ping() error {
+	ctx, cancel := context.WithTimeout(s.ctx, kafkaWriteTimeout)
+	defer cancel()
+
+	var err error
+	for _, broker := range s.brokers {
+		var conn *kafka.Conn
+		conn, err = kafka.DialLeader(ctx, "tcp", broker, s.topic, 0)
+		if err != nil {
+			continue
+		}
+		_, err = conn.ReadLastOffset()
+		conn.Close()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// sleep waits d, or until Stop
+func (s *kafkaBus) sleep(d time.Duration) {
+	select {
+	case <-time.After(d):
+	case <-s.ctx.Done():
+	}
+}
+
+// Stop ends reading and flushes pending writes. A bus that was never
+// started still closes its writer, which connects on the first publish.
+func (s *kafkaBus) Stop() {
+	if !s.running {
+		s.cancelCtx()
+		s.writer.Close()
+		return
+	}
+
+	slog.Info("⏹️  Stopping Kafka bus...")
+	s.cancelCtx()
+	if err := s.reader.Close(); err != nil {
+		slog.Warn("⚠️  Failed to close Kafka reader", "error", err)
+	}
+	if err := s.writer.Close(); err != nil {
+		slog.Warn("⚠️  Failed to close Kafka writer", "error", err)
+	}
+	s.running = false
+}
+
+// OnEvent sets the handler for received events (call before Start)
+func (s *kafkaBus) OnEvent(handler func(*models.PubSubEvent)) {
+	s.eventHandler = handler
+}
+
+// PublishEvent appends a typed event to the topic, which every server
+// (including this one) reads; it fails when no broker accepts it within
+// kafkaWriteTimeout
+func (s *kafkaBus) PublishEvent(ctx context.Context, eventType string, payload interface{}) error {
+	data, err := encodeEvent(ctx, eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	writeCtx, cancel := context.WithTimeout(s.ctx, kafkaWriteTimeout)
+	defer cancel()
+	return s.writer.WriteMessages(writeCtx, kafka.Message{Value: data})
+}
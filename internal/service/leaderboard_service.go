@@ -1,86 +1,471 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"time"
 
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/logging"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/ranking"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/tiers"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/workerpool"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUserBanned is returned when a banned user's score would change
+	ErrUserBanned = errors.New("user is banned")
+	// ErrRatingDeltaExceeded is returned when an update moves a rating
+	// further than the configured limits allow (reject mode)
+	ErrRatingDeltaExceeded = errors.New("rating change exceeds the allowed limit")
+	// ErrUserBusy is returned when another update of the same user holds
+	// the lock for longer than an update may wait
+	ErrUserBusy = errors.New("another update of this user is in progress")
+	// ErrRedisUnavailable is returned by score writes in degraded mode;
+	// callers queue the update for replay instead
+	ErrRedisUnavailable = errors.New("redis is unavailable")
+	// ErrSyncBacklogFull is returned by score writes while the DB sync
+	// backlog is at PG_OUTAGE_MAX_BACKLOG
+	ErrSyncBacklogFull = errors.New("too many score updates are waiting for PostgreSQL")
+	// ErrTierNotFound is returned for a tier that is not on the ladder
+	ErrTierNotFound = errors.New("tier not found")
+)
+
+const (
+	// Lock lifetime: bounds how long a crashed updater blocks the user
+	userLockTTL = 5 * time.Second
+	// How long an update waits for a concurrent update of the same user
+	userLockWait = 2 * time.Second
 )
 
 type LeaderboardService interface {
-	GetLeaderboard(limit int) ([]models.LeaderboardEntry, error)
+	GetLeaderboard(limit int, viewerID uint, usernames bool) ([]models.LeaderboardEntry, error)
 	GetUserRank(userID uint) (int64, error)
-	UpdateUserScore(userID uint, newRating int) (*models.ScoreUpdatePayload, error)
+	GetUserRankAs(userID, viewerID uint) (int64, error)
+	GetUserRanks(userIDs []uint) []models.UserRankResult
+	GetUserRanksAs(userIDs []uint, viewerID uint) (map[uint]int64, error)
+	GetNeighbors(userID uint, radius int) ([]models.LeaderboardEntry, error)
+	GetTierBoard(name string, offset, limit int, usernames bool) (*TierBoard, error)
+	Tiers() []tiers.Tier
+	Place(rating int) tiers.Placement
+	UpdateUserScore(ctx context.Context, userID uint, newRating int) (*models.ScoreUpdatePayload, error)
+	UpdateUserScoreFast(ctx context.Context, userID uint, newRating int) (*models.ScoreUpdatePayload, error)
+	RestoreUserScore(ctx context.Context, userID uint, rating int) (*models.ScoreUpdatePayload, error)
+	SubmitResult(ctx context.Context, userID uint, result ranking.Result) (*models.ScoreUpdatePayload, error)
+	BulkUpdateScores(ctx context.Context, updates []models.ScoreUpdateRequest) []models.BulkScoreResult
 	SyncUserToLeaderboard(user *models.User) error
+	RemoveUser(userID uint) error
 	GetLeaderboardStats() (map[string]interface{}, error)
+	Degraded() bool
+	SyncPressure() (string, int64)
+}
+
+// TierBoard is a page of the players in one tier
+type TierBoard struct {
+	Tier    tiers.Tier                `json:"tier"`
+	Total   int64                     `json:"total"` // players in the tier
+	Entries []models.LeaderboardEntry `json:"entries"`
+}
+
+// ConnectionCounter reports locally connected WebSocket clients
+type ConnectionCounter interface {
+	GetClientCount() int
 }
 
 type leaderboardService struct {
+	limits          config.RatingLimitConfig
+	strategy        ranking.Strategy
+	ladder          *tiers.Ladder // nil = no tiers
 	userRepo        repository.UserRepository
 	leaderboardRepo repository.LeaderboardRepository
 	scoreUpdateRepo repository.ScoreUpdateRepository
 	dbSyncService   DBSyncService
-	pubSubService   PubSubService
+	bus             *eventbus.Bus
+	connCounter     ConnectionCounter
+	pool            *workerpool.Pool
+	inspector       ScoreInspector
+	health          RedisHealth
+	enricher        ScoreEnricher
+	milestones      MilestoneDetector  // nil = no milestone events
+	achievements    AchievementService // nil = no achievements
 }
 
 func NewLeaderboardService(
+	limits config.RatingLimitConfig,
+	strategy ranking.Strategy,
+	ladder *tiers.Ladder,
 	userRepo repository.UserRepository,
 	leaderboardRepo repository.LeaderboardRepository,
 	scoreUpdateRepo repository.ScoreUpdateRepository,
 	dbSyncService DBSyncService,
-	pubSubService PubSubService,
+	bus *eventbus.Bus,
+	connCounter ConnectionCounter,
+	pool *workerpool.Pool,
+	inspector ScoreInspector,
+	health RedisHealth,
+	enricher ScoreEnricher,
+	milestones MilestoneDetector,
+	achievements AchievementService,
 ) LeaderboardService {
 	return &leaderboardService{
+		limits:          limits,
+		strategy:        strategy,
+		ladder:          ladder,
 		userRepo:        userRepo,
 		leaderboardRepo: leaderboardRepo,
 		scoreUpdateRepo: scoreUpdateRepo,
 		dbSyncService:   dbSyncService,
-		pubSubService:   pubSubService,
+		bus:             bus,
+		connCounter:     connCounter,
+		pool:            pool,
+		inspector:       inspector,
+		health:          health,
+		enricher:        enricher,
+		milestones:      milestones,
+		achievements:    achievements,
+	}
+}
+
+// Degraded reports whether Redis is down: reads come from PostgreSQL and
+// score writes fail with ErrRedisUnavailable
+func (s *leaderboardService) Degraded() bool {
+	return s.health != nil && s.health.Down()
+}
+
+// SyncPressure returns the write backpressure level from the DB sync
+// backlog (models.Pressure*) and the backlog itself
+func (s *leaderboardService) SyncPressure() (string, int64) {
+	return s.dbSyncService.Pressure()
+}
+
+// checkBacklog refuses score writes while the DB sync backlog is full, so
+// a long PostgreSQL outage cannot exhaust Redis memory
+func (s *leaderboardService) checkBacklog() error {
+	if level, backlog := s.dbSyncService.Pressure(); level == models.PressureFull {
+		return fmt.Errorf("%w (%d unsynced)", ErrSyncBacklogFull, backlog)
 	}
+	return nil
 }
 
-// GetLeaderboard returns top N users with their ranks
-func (s *leaderboardService) GetLeaderboard(limit int) ([]models.LeaderboardEntry, error) {
+// GetLeaderboard returns top N users with their ranks. A shadow-banned
+// viewer also sees themselves, placed where their rating would rank.
+// Without usernames the user cache is not read and entries have none.
+func (s *leaderboardService) GetLeaderboard(limit int, viewerID uint, usernames bool) ([]models.LeaderboardEntry, error) {
+	// Degraded: usernames come with the rows, shadow viewers see the public page
+	if s.Degraded() {
+		entries, err := s.userRepo.GetRankedTop(limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get leaderboard from PostgreSQL: %w", err)
+		}
+		s.placeEntries(entries)
+		return entries, nil
+	}
+
 	// Get top users from Redis sorted set
 	entries, err := s.leaderboardRepo.GetTopUsers(limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get leaderboard: %w", err)
 	}
 
-	// Enrich with usernames
+	if viewerID != 0 {
+		entries = s.withShadowViewer(entries, limit, viewerID)
+	}
+
+	if usernames {
+		s.enrichUsernames(entries)
+	}
+	s.placeEntries(entries)
+
+	return entries, nil
+}
+
+// withShadowViewer splices a shadow-banned viewer into their own view of the page
+func (s *leaderboardService) withShadowViewer(entries []models.LeaderboardEntry, limit int, viewerID uint) []models.LeaderboardEntry {
+	rating, err := s.leaderboardRepo.GetShadowScore(viewerID)
+	if err != nil {
+		return entries // not shadow-banned
+	}
+	above, err := s.leaderboardRepo.CountAbove(rating)
+	if err != nil || above >= int64(limit) {
+		return entries
+	}
+
+	viewer := models.LeaderboardEntry{Rank: above + 1, UserID: viewerID, Rating: rating}
+
+	result := make([]models.LeaderboardEntry, 0, len(entries)+1)
+	inserted := false
+	for _, entry := range entries {
+		if !inserted && entry.Rating < rating {
+			result = append(result, viewer)
+			inserted = true
+		}
+		if inserted && entry.Rating < rating {
+			entry.Rank++ // the viewer ranks ahead of them in their own view
+		}
+		result = append(result, entry)
+	}
+	if !inserted {
+		result = append(result, viewer)
+	}
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// GetNeighbors returns the users ranked just above and below a user
+func (s *leaderboardService) GetNeighbors(userID uint, radius int) ([]models.LeaderboardEntry, error) {
+	entries, err := s.leaderboardRepo.GetNeighbors(userID, radius)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get neighbors: %w", err)
+	}
+
+	s.enrichUsernames(entries)
+	s.placeEntries(entries)
+
+	return entries, nil
+}
+
+// GetTierBoard returns a page of the players in a tier, highest rated
+// first, with their global ranks (and usernames if asked for)
+func (s *leaderboardService) GetTierBoard(name string, offset, limit int, usernames bool) (*TierBoard, error) {
+	tier, ok := s.ladder.Find(name)
+	if !ok {
+		return nil, ErrTierNotFound
+	}
+	if s.Degraded() {
+		return nil, ErrRedisUnavailable
+	}
+
+	min, max := tier.Bounds()
+	total, err := s.leaderboardRepo.CountRatingRange(min, max)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tier %s: %w", tier.Name, err)
+	}
+	entries, err := s.leaderboardRepo.GetRatingRange(min, max, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tier %s: %w", tier.Name, err)
+	}
+
+	if usernames {
+		s.enrichUsernames(entries)
+	}
+	s.placeEntries(entries)
+
+	return &TierBoard{Tier: tier, Total: total, Entries: entries}, nil
+}
+
+// Tiers returns the rating ladder from the lowest tier up (empty when
+// tiers are disabled)
+func (s *leaderboardService) Tiers() []tiers.Tier {
+	return s.ladder.Tiers()
+}
+
+// Place returns the tier and division of a rating
+func (s *leaderboardService) Place(rating int) tiers.Placement {
+	return s.ladder.Place(rating)
+}
+
+// placeEntries fills in each entry's tier and division
+func (s *leaderboardService) placeEntries(entries []models.LeaderboardEntry) {
 	for i := range entries {
+		placement := s.ladder.Place(entries[i].Rating)
+		entries[i].Tier = placement.Tier
+		entries[i].Division = placement.Division
+	}
+}
+
+// enrichUsernames fills in usernames from the user cache (falling back to
+// PostgreSQL), spread over the shared worker pool for large pages
+func (s *leaderboardService) enrichUsernames(entries []models.LeaderboardEntry) {
+	s.pool.Map(len(entries), func(i int) {
 		// Try cache first
 		user, err := s.leaderboardRepo.GetCachedUser(entries[i].UserID)
 		if err != nil {
 			// Fallback to database
 			user, err = s.userRepo.GetByID(entries[i].UserID)
 			if err != nil {
-				log.Printf("Failed to get user %d: %v", entries[i].UserID, err)
-				continue
+				slog.Warn("Failed to get user", "user_id", entries[i].UserID, "error", err)
+				return
 			}
 			// Cache for next time
 			s.leaderboardRepo.CacheUser(user)
 		}
 
 		entries[i].Username = user.Username
-	}
-
-	return entries, nil
+	})
 }
 
 // GetUserRank returns the global rank of a user
 func (s *leaderboardService) GetUserRank(userID uint) (int64, error) {
+	if s.Degraded() {
+		return s.fallbackRank(userID, 0)
+	}
+
 	rank, err := s.leaderboardRepo.GetUserRank(userID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get user rank: %w", err)
+		return 0, rankError(userID, err)
 	}
 	return rank, nil
 }
 
-// UpdateUserScore updates a user's rating and recalculates rank
-func (s *leaderboardService) UpdateUserScore(userID uint, newRating int) (*models.ScoreUpdatePayload, error) {
+// GetUserRankAs returns a user's rank as seen by viewerID: shadow-banned
+// users get a rank only when they look themselves up
+func (s *leaderboardService) GetUserRankAs(userID, viewerID uint) (int64, error) {
+	if s.Degraded() {
+		return s.fallbackRank(userID, viewerID)
+	}
+
+	rank, err := s.leaderboardRepo.GetUserRank(userID)
+	if err == nil || userID != viewerID {
+		if err != nil {
+			return 0, rankError(userID, err)
+		}
+		return rank, nil
+	}
+
+	rating, shadowErr := s.leaderboardRepo.GetShadowScore(userID)
+	if shadowErr != nil {
+		return 0, rankError(userID, err)
+	}
+	above, err := s.leaderboardRepo.CountAbove(rating)
+	if err != nil {
+		return 0, err
+	}
+	return above + 1, nil
+}
+
+// rankError wraps a failed board lookup, turning a user missing from the
+// board into ErrUserNotFound
+func rankError(userID uint, err error) error {
+	if errors.Is(err, repository.ErrNotOnBoard) {
+		return fmt.Errorf("%w: %d is not on the leaderboard", ErrUserNotFound, userID)
+	}
+	return fmt.Errorf("failed to get user rank: %w", err)
+}
+
+// fallbackRank computes a rank from PostgreSQL ratings, with the same
+// visibility rules as the Redis boards
+func (s *leaderboardService) fallbackRank(userID, viewerID uint) (int64, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("%w: %d", ErrUserNotFound, userID)
+		}
+		return 0, fmt.Errorf("failed to get user rank: %w", err)
+	}
+	switch {
+	case user.Status == models.UserStatusBanned,
+		user.Status == models.UserStatusShadowBanned && userID != viewerID:
+		return 0, fmt.Errorf("%w: %d is not on the leaderboard", ErrUserNotFound, userID)
+	}
+
+	above, err := s.userRepo.CountRatedAbove(user.Rating)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user rank from PostgreSQL: %w", err)
+	}
+	return above + 1, nil
+}
+
+// GetUserRanks looks up ranks for many users; missing users are reported per item
+func (s *leaderboardService) GetUserRanks(userIDs []uint) []models.UserRankResult {
+	ranks, err := s.GetUserRanksAs(userIDs, 0)
+	if err != nil {
+		slog.Warn("⚠️  Failed to get user ranks", "users", len(userIDs), "error", err)
+	}
+
+	results := make([]models.UserRankResult, len(userIDs))
+	for i, userID := range userIDs {
+		results[i].UserID = userID
+		rank, ok := ranks[userID]
+		if !ok {
+			results[i].Error = "user not found in leaderboard"
+			continue
+		}
+		results[i].Rank = rank
+	}
+	return results
+}
+
+// GetUserRanksAs returns the ranks of many users as seen by viewerID (see
+// GetUserRankAs), pipelined so the Redis round trips don't grow with the
+// number of users. Users without a rank are left out.
+func (s *leaderboardService) GetUserRanksAs(userIDs []uint, viewerID uint) (map[uint]int64, error) {
+	if s.Degraded() {
+		// One PostgreSQL count per user, spread over the worker pool
+		found := make([]int64, len(userIDs))
+		s.pool.Map(len(userIDs), func(i int) {
+			if rank, err := s.fallbackRank(userIDs[i], viewerID); err == nil {
+				found[i] = rank
+			}
+		})
+		ranks := make(map[uint]int64, len(userIDs))
+		for i, rank := range found {
+			if rank > 0 {
+				ranks[userIDs[i]] = rank
+			}
+		}
+		return ranks, nil
+	}
+
+	ranks, err := s.leaderboardRepo.GetUserRanks(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ranks: %w", err)
+	}
+
+	// A shadow-banned viewer still sees their own rank
+	if _, ok := ranks[viewerID]; !ok && viewerID != 0 {
+		for _, userID := range userIDs {
+			if userID != viewerID {
+				continue
+			}
+			if rank, err := s.GetUserRankAs(viewerID, viewerID); err == nil {
+				ranks[viewerID] = rank
+			}
+			break
+		}
+	}
+	return ranks, nil
+}
+
+// BulkUpdateScores applies score updates in order; failures are reported per item
+func (s *leaderboardService) BulkUpdateScores(ctx context.Context, updates []models.ScoreUpdateRequest) []models.BulkScoreResult {
+	results := make([]models.BulkScoreResult, len(updates))
+	for i, update := range updates {
+		results[i].UserID = update.UserID
+		payload, err := s.UpdateUserScore(ctx, update.UserID, update.NewRating)
+		if err != nil {
+			results[i].Error = err.Error()
+			switch {
+			case errors.Is(err, ErrRatingDeltaExceeded):
+				results[i].Code = CodeRatingDeltaExceeded
+			case errors.Is(err, ErrUserBusy):
+				results[i].Code = CodeUserBusy
+			case errors.Is(err, ErrRedisUnavailable):
+				results[i].Code = CodeRedisUnavailable
+			case errors.Is(err, ErrSyncBacklogFull):
+				results[i].Code = CodeSyncBacklogFull
+			}
+			continue
+		}
+		results[i].Update = payload
+	}
+	return results
+}
+
+// UpdateUserScore updates a user's rating and recalculates rank. Updates of
+// the same user are serialized (across servers) so each one sees the rating
+// the previous one wrote and deltas/history stay consistent. The update
+// event carries the request ID in ctx (requestid.FromContext).
+func (s *leaderboardService) UpdateUserScore(ctx context.Context, userID uint, newRating int) (*models.ScoreUpdatePayload, error) {
 	// Validate rating bounds
 	if newRating < 100 {
 		newRating = 100
@@ -89,15 +474,227 @@ func (s *leaderboardService) UpdateUserScore(userID uint, newRating int) (*model
 		newRating = 5000
 	}
 
-	// STEP 1: Get current state from Redis (fast!)
+	if s.Degraded() {
+		return nil, ErrRedisUnavailable
+	}
+	if err := s.checkBacklog(); err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lockUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return s.updateUserScore(ctx, userID, newRating, false)
+}
+
+// UpdateUserScoreFast applies a rating like UpdateUserScore but returns
+// before any rank is read: the payload's ranks are left at 0, and the
+// enricher computes them and publishes the update moments later
+func (s *leaderboardService) UpdateUserScoreFast(ctx context.Context, userID uint, newRating int) (*models.ScoreUpdatePayload, error) {
+	// Validate rating bounds
+	if newRating < 100 {
+		newRating = 100
+	}
+	if newRating > 5000 {
+		newRating = 5000
+	}
+
+	if s.Degraded() {
+		return nil, ErrRedisUnavailable
+	}
+	if err := s.checkBacklog(); err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lockUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	user, newRating, err := s.prepareUpdate(userID, newRating, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Shadow updates are never broadcast, so there is nothing to defer
+	if user.Status == models.UserStatusShadowBanned {
+		return s.updateShadowScore(ctx, user, newRating, false)
+	}
+
+	oldRating := user.Rating
+	added, err := s.leaderboardRepo.SetUserScore(userID, newRating)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update Redis: %w", err)
+	}
+	s.recordRatingChange(userID, newRating-oldRating, false)
+
+	user.Rating = newRating
+	s.leaderboardRepo.CacheUser(user)
+
+	payload := &models.ScoreUpdatePayload{
+		UserID:      userID,
+		Username:    user.Username,
+		OldRating:   oldRating,
+		NewRating:   newRating,
+		RatingDelta: newRating - oldRating,
+		Timestamp:   time.Now().Unix(),
+	}
+	s.enricher.Enrich(ctx, *payload, added)
+
+	return payload, nil
+}
+
+// RestoreUserScore sets a rating as part of an admin revert. It is locked
+// like any update but skips rating limits and anti-cheat, which would
+// otherwise block or flag undoing a large bad change.
+func (s *leaderboardService) RestoreUserScore(ctx context.Context, userID uint, rating int) (*models.ScoreUpdatePayload, error) {
+	if s.Degraded() {
+		return nil, ErrRedisUnavailable
+	}
+	if err := s.checkBacklog(); err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lockUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return s.updateUserScore(ctx, userID, rating, true)
+}
+
+// SubmitResult turns a game result into the user's new rating with the
+// board's ranking strategy, then applies it like any update. Only the
+// submitting user changes; an opponent named by ID is read, not updated.
+func (s *leaderboardService) SubmitResult(ctx context.Context, userID uint, result ranking.Result) (*models.ScoreUpdatePayload, error) {
+	if result.OpponentID != nil && *result.OpponentID == userID {
+		return nil, fmt.Errorf("%w: a user cannot play themselves", ranking.ErrInvalidResult)
+	}
+	if s.Degraded() {
+		return nil, ErrRedisUnavailable
+	}
+	if err := s.checkBacklog(); err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lockUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	player, err := s.rankingPlayer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var opponent *ranking.Player
+	if s.strategy.NeedsOpponent() {
+		switch {
+		case result.OpponentID != nil:
+			if opponent, err = s.rankingPlayer(*result.OpponentID); err != nil {
+				if errors.Is(err, ErrUserNotFound) {
+					return nil, fmt.Errorf("%w: opponent not found", ranking.ErrInvalidResult)
+				}
+				return nil, err
+			}
+		case result.OpponentRating != nil:
+			opponent = &ranking.Player{Rating: float64(*result.OpponentRating)}
+		}
+	}
+
+	next, err := s.strategy.Apply(*player, opponent, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate rating bounds
+	newRating := int(math.Round(next.Rating))
+	if newRating < 100 {
+		newRating = 100
+	}
+	if newRating > 5000 {
+		newRating = 5000
+	}
+
+	payload, err := s.updateUserScore(ctx, userID, newRating, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if next.Uncertainty != player.Uncertainty {
+		if err := s.leaderboardRepo.SetUncertainty(s.strategy.Name(), userID, next.Uncertainty); err != nil {
+			slog.Warn("⚠️  Failed to store rating uncertainty", "user_id", userID, "error", err)
+		}
+	}
+	return payload, nil
+}
+
+// rankingPlayer reads a user's rating and their uncertainty under the
+// board's strategy
+func (s *leaderboardService) rankingPlayer(userID uint) (*ranking.Player, error) {
 	user, err := s.leaderboardRepo.GetCachedUser(userID)
 	if err != nil {
 		// Fallback to PostgreSQL if not in cache
-		user, err = s.userRepo.GetByID(userID)
+		if user, err = s.userRepo.GetByID(userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrUserNotFound
+			}
+			return nil, err
+		}
+	}
+
+	uncertainty, err := s.leaderboardRepo.GetUncertainty(s.strategy.Name(), userID)
+	if err != nil {
+		return nil, err
+	}
+	return &ranking.Player{Rating: float64(user.Rating), Uncertainty: uncertainty}, nil
+}
+
+// lockUser waits for the per-user update lock, backing off between attempts
+func (s *leaderboardService) lockUser(userID uint) (func(), error) {
+	token := newID()
+	deadline := time.Now().Add(userLockWait)
+	backoff := 2 * time.Millisecond
+
+	for {
+		acquired, err := s.leaderboardRepo.LockUser(userID, token, userLockTTL)
 		if err != nil {
-			return nil, fmt.Errorf("user not found: %w", err)
+			return nil, fmt.Errorf("failed to lock user: %w", err)
+		}
+		if acquired {
+			return func() {
+				if err := s.leaderboardRepo.UnlockUser(userID, token); err != nil {
+					slog.Warn("⚠️  Failed to unlock user", "user_id", userID, "error", err)
+				}
+			}, nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, ErrUserBusy
+		}
+		time.Sleep(backoff)
+		if backoff < 50*time.Millisecond {
+			backoff *= 2
 		}
 	}
+}
+
+// updateUserScore applies an update; the caller holds the user's lock
+func (s *leaderboardService) updateUserScore(ctx context.Context, userID uint, newRating int, restore bool) (*models.ScoreUpdatePayload, error) {
+	user, newRating, err := s.prepareUpdate(userID, newRating, restore)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status == models.UserStatusShadowBanned {
+		return s.updateShadowScore(ctx, user, newRating, restore)
+	}
 
 	oldRating := user.Rating
 	oldRank, err := s.leaderboardRepo.GetUserRank(userID)
@@ -109,6 +706,7 @@ func (s *leaderboardService) UpdateUserScore(userID uint, newRating int) (*model
 	if err := s.leaderboardRepo.UpdateUserScore(userID, newRating); err != nil {
 		return nil, fmt.Errorf("failed to update Redis: %w", err)
 	}
+	s.recordRatingChange(userID, newRating-oldRating, restore)
 
 	// Update cache
 	user.Rating = newRating
@@ -138,36 +736,179 @@ func (s *leaderboardService) UpdateUserScore(userID uint, newRating int) (*model
 		Timestamp:   time.Now().Unix(),
 	}
 
-	// STEP 5: Publish to Redis Pub/Sub (broadcasts to ALL servers)
-	if err := s.pubSubService.Publish(payload); err != nil {
-		log.Printf("⚠️  Failed to publish score update: %v", err)
+	// STEP 5: Publish on the event bus (DB sync here, broadcast on ALL servers)
+	if err := s.bus.PublishContext(ctx, models.EventScoreUpdate, payload); err != nil {
+		logging.FromContext(ctx).Warn("⚠️  Failed to publish score update", "error", err)
 		// Don't fail the request if broadcast fails
 	}
 
-	// STEP 6: Enqueue async DB sync (Redis Stream)
-	err = s.dbSyncService.EnqueueUpdate(models.DBSyncQueueItem{
-		UserID:    userID,
-		OldRating: oldRating,
-		NewRating: newRating,
-		Timestamp: time.Now(),
-	})
+	// STEP 6: Hand to anti-cheat detection (asynchronous, never blocks the update)
+	if s.inspector != nil && !restore {
+		s.inspector.Inspect(payload)
+	}
+
+	// STEP 7: Announce top N entries, personal bests, tier changes and streaks (not for reverts)
+	if s.milestones != nil && !restore {
+		s.milestones.Detect(payload)
+	}
+
+	// STEP 8: Unlock achievements (first win, rating reached, win streak)
+	if s.achievements != nil && !restore {
+		s.achievements.Evaluate(payload)
+	}
 
+	logging.FromContext(ctx).Debug("Updated user score", "user_id", userID, "username", user.Username,
+		"old_rating", oldRating, "new_rating", newRating, "rank", newRank)
+
+	return payload, nil
+}
+
+// prepareUpdate reads the user's current state and applies the rating
+// limits; the caller holds the user's lock
+func (s *leaderboardService) prepareUpdate(userID uint, newRating int, restore bool) (*models.User, int, error) {
+	// STEP 1: Get current state from Redis (fast!)
+	user, err := s.leaderboardRepo.GetCachedUser(userID)
 	if err != nil {
-		// IMPORTANT: do NOT fail user flow
-		log.Printf("⚠️ Failed to enqueue DB sync for user %d: %v", userID, err)
+		// Fallback to PostgreSQL if not in cache
+		user, err = s.userRepo.GetByID(userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, 0, fmt.Errorf("%w: %d", ErrUserNotFound, userID)
+			}
+			return nil, 0, fmt.Errorf("failed to load user %d: %w", userID, err)
+		}
+	}
+
+	if user.Status == models.UserStatusBanned {
+		return nil, 0, ErrUserBanned
+	}
+
+	// Reject or clamp oversized jumps before anything is written
+	if !restore {
+		newRating, err = s.enforceRatingLimits(user, newRating)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return user, newRating, nil
+}
+
+// enforceRatingLimits checks a rating change against the per-update and
+// rolling-window limits. In clamp mode the rating is cut back to the
+// largest allowed change; in reject mode ErrRatingDeltaExceeded is returned.
+// Nothing is recorded here: recordRatingChange counts the change against
+// the window once it has been written.
+func (s *leaderboardService) enforceRatingLimits(user *models.User, newRating int) (int, error) {
+	delta := newRating - user.Rating
+	if delta == 0 || (s.limits.MaxPerUpdate <= 0 && s.limits.MaxPerWindow <= 0) {
+		return newRating, nil
+	}
+
+	allowed := delta
+	if limit := s.limits.MaxPerUpdate; limit > 0 {
+		allowed = clampDelta(allowed, -limit, limit)
 	}
 
-	log.Printf("Updated user %d (%s): %d -> %d (rank: %d)",
-		userID, user.Username, oldRating, newRating, newRank)
+	if limit := s.limits.MaxPerWindow; limit > 0 {
+		recent, err := s.leaderboardRepo.GetRecentRatingChange(user.ID, s.limits.Window)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read rating change window: %w", err)
+		}
+		// Net change over the window must stay within ±limit
+		allowed = clampDelta(allowed, -limit-recent, limit-recent)
+		// Already beyond the limit in this direction: no further movement
+		if (delta > 0 && allowed < 0) || (delta < 0 && allowed > 0) {
+			allowed = 0
+		}
+	}
+
+	if allowed != delta {
+		if s.limits.Mode != config.RatingLimitClamp {
+			return 0, fmt.Errorf("%w: change of %d for user %d", ErrRatingDeltaExceeded, delta, user.ID)
+		}
+		slog.Info("✂️  Clamped rating change", "user_id", user.ID, "delta", delta, "allowed", allowed)
+		newRating = user.Rating + allowed
+	}
+	return newRating, nil
+}
+
+// recordRatingChange counts a written change against the user's rolling
+// window; reverts skip the limits and are not counted
+func (s *leaderboardService) recordRatingChange(userID uint, delta int, restore bool) {
+	if restore || delta == 0 || s.limits.MaxPerWindow <= 0 {
+		return
+	}
+	if err := s.leaderboardRepo.RecordRatingChange(userID, delta, s.limits.Window); err != nil {
+		slog.Warn("⚠️  Failed to record rating change", "user_id", userID, "error", err)
+	}
+}
+
+func clampDelta(delta, min, max int) int {
+	if delta < min {
+		return min
+	}
+	if delta > max {
+		return max
+	}
+	return delta
+}
+
+// updateShadowScore records a shadow-banned user's update so it looks normal
+// to them, without touching the public board or broadcasting it
+func (s *leaderboardService) updateShadowScore(ctx context.Context, user *models.User, newRating int, restore bool) (*models.ScoreUpdatePayload, error) {
+	oldRating := user.Rating
+	oldAbove, _ := s.leaderboardRepo.CountAbove(oldRating)
+
+	if err := s.leaderboardRepo.SetShadowScore(user.ID, newRating); err != nil {
+		return nil, fmt.Errorf("failed to update Redis: %w", err)
+	}
+	s.recordRatingChange(user.ID, newRating-oldRating, restore)
+
+	user.Rating = newRating
+	s.leaderboardRepo.CacheUser(user)
+
+	newAbove, _ := s.leaderboardRepo.CountAbove(newRating)
+
+	payload := &models.ScoreUpdatePayload{
+		UserID:      user.ID,
+		Username:    user.Username,
+		OldRating:   oldRating,
+		NewRating:   newRating,
+		OldRank:     oldAbove + 1,
+		NewRank:     newAbove + 1,
+		RankDelta:   oldAbove - newAbove,
+		RatingDelta: newRating - oldRating,
+		Timestamp:   time.Now().Unix(),
+	}
+
+	// Still persisted (so lifting the ban restores the real rating) but never broadcast
+	if err := s.bus.PublishContext(ctx, models.EventShadowScoreUpdate, payload); err != nil {
+		logging.FromContext(ctx).Warn("⚠️  Failed to publish shadow score update", "error", err)
+	}
+
+	if s.inspector != nil && !restore {
+		s.inspector.Inspect(payload)
+	}
 
 	return payload, nil
 }
 
 // SyncUserToLeaderboard adds/updates user in Redis leaderboard
 func (s *leaderboardService) SyncUserToLeaderboard(user *models.User) error {
-	// Add to leaderboard
-	if err := s.leaderboardRepo.AddUser(user.ID, user.Rating); err != nil {
-		return err
+	// Place on the board matching their moderation status
+	switch user.Status {
+	case models.UserStatusBanned:
+		if err := s.leaderboardRepo.RemoveUser(user.ID); err != nil {
+			return err
+		}
+	case models.UserStatusShadowBanned:
+		if err := s.leaderboardRepo.SetShadowScore(user.ID, user.Rating); err != nil {
+			return err
+		}
+	default:
+		if err := s.leaderboardRepo.AddUser(user.ID, user.Rating); err != nil {
+			return err
+		}
 	}
 
 	// Cache user data
@@ -175,8 +916,21 @@ func (s *leaderboardService) SyncUserToLeaderboard(user *models.User) error {
 		return err
 	}
 
-	// Username search uses PostgreSQL (no Redis indexing needed)
+	// Index the username for autocomplete (RemoveUser dropped a banned user's entry)
+	if user.Status != models.UserStatusBanned {
+		if err := s.leaderboardRepo.IndexUsername(user.ID, user.Username); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
+// RemoveUser drops a user from the Redis leaderboard and user cache
+func (s *leaderboardService) RemoveUser(userID uint) error {
+	if err := s.leaderboardRepo.RemoveUser(userID); err != nil {
+		return fmt.Errorf("failed to remove user from leaderboard: %w", err)
+	}
 	return nil
 }
 
@@ -192,8 +946,42 @@ func (s *leaderboardService) GetLeaderboardStats() (map[string]interface{}, erro
 		return nil, err
 	}
 
+	minRating, maxRating, medianRating, err := s.leaderboardRepo.GetRatingBounds()
+	if err != nil {
+		return nil, err
+	}
+
+	avgRating, err := s.userRepo.AverageRating()
+	if err != nil {
+		return nil, err
+	}
+
+	updatesLastHour, err := s.scoreUpdateRepo.CountSince(time.Now().Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	// Queue depth is best-effort (stream may not exist yet)
+	queueDepth, err := s.dbSyncService.QueueDepth()
+	if err != nil {
+		slog.Warn("⚠️  Failed to read DB sync queue depth", "error", err)
+	}
+
+	connectedClients := 0
+	if s.connCounter != nil {
+		connectedClients = s.connCounter.GetClientCount()
+	}
+
 	return map[string]interface{}{
-		"total_users":      totalUsers,
-		"leaderboard_size": leaderboardSize,
+		"total_users":       totalUsers,
+		"leaderboard_size":  leaderboardSize,
+		"min_rating":        int(minRating),
+		"max_rating":        int(maxRating),
+		"avg_rating":        avgRating,
+		"median_rating":     medianRating,
+		"updates_last_hour": updatesLastHour,
+		"connected_clients": connectedClients,
+		"db_sync_queue":     queueDepth,
+		"ranking_strategy":  s.strategy.Name(),
 	}, nil
 }
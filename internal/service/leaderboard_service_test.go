@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+// fakeBoard is the part of the leaderboard repository a score update
+// touches; any other method panics
+type fakeBoard struct {
+	repository.LeaderboardRepository
+
+	user     models.User
+	writeErr error
+	recorded []int // deltas counted against the rating window
+}
+
+func (f *fakeBoard) GetCachedUser(userID uint) (*models.User, error) {
+	user := f.user
+	return &user, nil
+}
+
+func (f *fakeBoard) GetRecentRatingChange(userID uint, window time.Duration) (int, error) {
+	total := 0
+	for _, delta := range f.recorded {
+		total += delta
+	}
+	return total, nil
+}
+
+func (f *fakeBoard) RecordRatingChange(userID uint, delta int, window time.Duration) error {
+	f.recorded = append(f.recorded, delta)
+	return nil
+}
+
+func (f *fakeBoard) UpdateUserScore(userID uint, rating int) error { return f.writeErr }
+func (f *fakeBoard) SetUserScore(userID uint, rating int) (bool, error) {
+	return false, f.writeErr
+}
+func (f *fakeBoard) SetShadowScore(userID uint, rating int) error { return f.writeErr }
+func (f *fakeBoard) GetUserRank(userID uint) (int64, error)       { return 1, nil }
+func (f *fakeBoard) CountAbove(rating int) (int64, error)         { return 0, nil }
+func (f *fakeBoard) CacheUser(user *models.User) error            { return nil }
+
+func TestRatingWindowCountsOnlyWrittenChanges(t *testing.T) {
+	writeErr := errors.New("redis: connection refused")
+
+	for _, tc := range []struct {
+		name     string
+		status   string
+		writeErr error
+		want     []int
+	}{
+		{"written", models.UserStatusActive, nil, []int{200}},
+		{"write failed", models.UserStatusActive, writeErr, nil},
+		{"shadow written", models.UserStatusShadowBanned, nil, []int{200}},
+		{"shadow write failed", models.UserStatusShadowBanned, writeErr, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			board := &fakeBoard{
+				user:     models.User{ID: 7, Username: "alice", Rating: 1500, Status: tc.status},
+				writeErr: tc.writeErr,
+			}
+			s := &leaderboardService{
+				limits:          config.RatingLimitConfig{MaxPerWindow: 300, Window: time.Hour, Mode: config.RatingLimitReject},
+				leaderboardRepo: board,
+				bus:             eventbus.New(nil),
+			}
+
+			_, err := s.updateUserScore(context.Background(), 7, 1700, false)
+			if !errors.Is(err, tc.writeErr) {
+				t.Fatalf("updateUserScore error = %v, want %v", err, tc.writeErr)
+			}
+			if len(board.recorded) != len(tc.want) || (len(tc.want) > 0 && board.recorded[0] != tc.want[0]) {
+				t.Errorf("recorded %v, want %v", board.recorded, tc.want)
+			}
+		})
+	}
+}
+
+func TestFailedWriteLeavesWindowBudget(t *testing.T) {
+	board := &fakeBoard{user: models.User{ID: 7, Username: "alice", Rating: 1500, Status: models.UserStatusActive}}
+	s := &leaderboardService{
+		limits:          config.RatingLimitConfig{MaxPerWindow: 300, Window: time.Hour, Mode: config.RatingLimitReject},
+		leaderboardRepo: board,
+		bus:             eventbus.New(nil),
+	}
+
+	// Redis refuses the write: the +250 must not count
+	board.writeErr = errors.New("redis: connection refused")
+	if _, err := s.updateUserScore(context.Background(), 7, 1750, false); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+
+	// With the budget untouched, the retried +250 is still allowed
+	board.writeErr = nil
+	if _, err := s.updateUserScore(context.Background(), 7, 1750, false); err != nil {
+		t.Fatalf("retry rejected: %v", err)
+	}
+
+	// The window now holds +250, so a further +100 exceeds it
+	board.user.Rating = 1750
+	if _, err := s.updateUserScore(context.Background(), 7, 1850, false); !errors.Is(err, ErrRatingDeltaExceeded) {
+		t.Fatalf("error = %v, want ErrRatingDeltaExceeded", err)
+	}
+}
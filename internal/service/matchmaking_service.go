@@ -0,0 +1,141 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+var ErrSelfMatch = newValidationError("a user cannot be matched with themselves")
+
+// MatchmakingService suggests opponents rated close to a player, leaving
+// out those they played within MATCHMAKING_RECENT_WINDOW
+type MatchmakingService interface {
+	FindOpponents(userID uint, band, count int) (rating int, candidates []models.MatchCandidate, err error)
+	RecordMatch(userID, opponentID uint) error
+	HandleUserRemoved(event eventbus.Event)
+}
+
+type matchmakingService struct {
+	cfg             config.MatchmakingConfig
+	matchmakingRepo repository.MatchmakingRepository
+	leaderboardRepo repository.LeaderboardRepository
+	userRepo        repository.UserRepository
+	leaderboardSvc  LeaderboardService
+}
+
+func NewMatchmakingService(
+	cfg config.MatchmakingConfig,
+	matchmakingRepo repository.MatchmakingRepository,
+	leaderboardRepo repository.LeaderboardRepository,
+	userRepo repository.UserRepository,
+	leaderboardSvc LeaderboardService,
+) MatchmakingService {
+	return &matchmakingService{
+		cfg:             cfg,
+		matchmakingRepo: matchmakingRepo,
+		leaderboardRepo: leaderboardRepo,
+		userRepo:        userRepo,
+		leaderboardSvc:  leaderboardSvc,
+	}
+}
+
+// FindOpponents returns up to count players on the public board rated
+// within band of the user, closest first. Shadow-banned users are matched
+// from their shadow rating but never suggested to others.
+func (s *matchmakingService) FindOpponents(userID uint, band, count int) (int, []models.MatchCandidate, error) {
+	if s.leaderboardSvc.Degraded() {
+		return 0, nil, ErrRedisUnavailable
+	}
+
+	scores, err := s.leaderboardRepo.GetScores([]uint{userID})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get rating: %w", err)
+	}
+	rating, ok := scores[userID]
+	if !ok {
+		return 0, nil, ErrUserNotFound
+	}
+
+	exclude := map[uint]bool{}
+	if s.cfg.RecentWindow > 0 {
+		if exclude, err = s.matchmakingRepo.GetRecentOpponents(userID, time.Now().Add(-s.cfg.RecentWindow)); err != nil {
+			return 0, nil, fmt.Errorf("failed to get recent opponents: %w", err)
+		}
+	}
+	exclude[userID] = true
+
+	// Enough from each side that the closest count survive the exclusions
+	nearest, err := s.matchmakingRepo.GetNearest(rating, band, count+len(exclude))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to find opponents: %w", err)
+	}
+
+	candidates := make([]models.MatchCandidate, 0, count)
+	for _, candidate := range nearest {
+		if len(candidates) == count {
+			break
+		}
+		if exclude[candidate.UserID] {
+			continue
+		}
+		placement := s.leaderboardSvc.Place(candidate.Rating)
+		candidate.Tier, candidate.Division = placement.Tier, placement.Division
+		candidate.Username = s.username(candidate.UserID)
+		candidates = append(candidates, candidate)
+	}
+	return rating, candidates, nil
+}
+
+// username reads a candidate's name from the user cache, falling back to
+// PostgreSQL
+func (s *matchmakingService) username(userID uint) string {
+	user, err := s.leaderboardRepo.GetCachedUser(userID)
+	if err != nil {
+		if user, err = s.userRepo.GetByID(userID); err != nil {
+			slog.Warn("Failed to get user", "user_id", userID, "error", err)
+			return ""
+		}
+		s.leaderboardRepo.CacheUser(user)
+	}
+	return user.Username
+}
+
+// RecordMatch keeps the two players from being suggested to each other
+// for MATCHMAKING_RECENT_WINDOW
+func (s *matchmakingService) RecordMatch(userID, opponentID uint) error {
+	if userID == opponentID {
+		return ErrSelfMatch
+	}
+	if s.cfg.RecentWindow <= 0 {
+		return nil
+	}
+	if s.leaderboardSvc.Degraded() {
+		return ErrRedisUnavailable
+	}
+
+	scores, err := s.leaderboardRepo.GetScores([]uint{userID, opponentID})
+	if err != nil {
+		return fmt.Errorf("failed to get ratings: %w", err)
+	}
+	if len(scores) != 2 {
+		return ErrUserNotFound
+	}
+	return s.matchmakingRepo.RecordMatch(userID, opponentID, time.Now(), s.cfg.RecentWindow)
+}
+
+// HandleUserRemoved forgets a deleted or purged user's recent matches
+func (s *matchmakingService) HandleUserRemoved(event eventbus.Event) {
+	payload, ok := event.Payload.(*models.UserRemovedPayload)
+	if !ok {
+		return
+	}
+	if err := s.matchmakingRepo.Forget(payload.UserID); err != nil {
+		slog.Warn("⚠️  Failed to forget recent matches", "user_id", payload.UserID, "error", err)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/requestid"
+)
+
+// MessageBus fans events out to every server, itself included. The event
+// bus sits on top of it; EVENT_TRANSPORT picks the implementation: Redis
+// Pub/Sub (the default), a Redis Stream, NATS or Kafka.
+type MessageBus interface {
+	Start()
+	Stop()
+	// PublishEvent sends an event with the request ID ctx carries; ctx
+	// does not cancel the send
+	PublishEvent(ctx context.Context, eventType string, payload interface{}) error
+	OnEvent(handler func(*models.PubSubEvent))
+
+	// Health reports whether events from other servers are arriving;
+	// Check turns it into a readiness check
+	Health() BusHealth
+	Check(ctx context.Context) error
+}
+
+// BusHealth is the state of a server's connection to its message bus
+type BusHealth struct {
+	Transport  string    `json:"transport"`
+	Connected  bool      `json:"connected"`
+	Since      time.Time `json:"since"` // of the last change, or the start
+	LastError  string    `json:"last_error,omitempty"`
+	Reconnects uint64    `json:"reconnects"`
+}
+
+var busReconnects = metrics.NewCounterVec("event_bus_reconnects_total",
+	"Times a server reconnected to its message bus after losing it", "transport")
+
+// busState tracks a transport's connection for Health and Check; the
+// transports embed it
+type busState struct {
+	mu     sync.Mutex
+	health BusHealth
+}
+
+func newBusState(transport string) *busState {
+	return &busState{health: BusHealth{Transport: transport, Since: time.Now().UTC()}}
+}
+
+// up records that the transport is receiving events
+func (b *busState) up() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.health.Connected {
+		return
+	}
+	if b.health.LastError != "" {
+		b.health.Reconnects++
+		busReconnects.WithLabelValues(b.health.Transport).Inc()
+	}
+	b.health.Connected = true
+	b.health.Since = time.Now().UTC()
+}
+
+// down records that the transport lost its connection
+func (b *busState) down(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.health.Connected {
+		b.health.Since = time.Now().UTC()
+	}
+	b.health.Connected = false
+	b.health.LastError = err.Error()
+}
+
+func (b *busState) Health() BusHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.health
+}
+
+// Check fails while the transport is not connected, since events from
+// other servers are then not reaching this one's clients
+func (b *busState) Check(ctx context.Context) error {
+	health := b.Health()
+	if health.Connected {
+		return nil
+	}
+	if health.LastError == "" {
+		return fmt.Errorf("%s not connected yet", health.Transport)
+	}
+	return fmt.Errorf("%s disconnected since %s: %s", health.Transport, health.Since.Format(time.RFC3339), health.LastError)
+}
+
+// reconnectBackoff is how long to wait before the given reconnect attempt:
+// doubling from a second, up to 30s
+func reconnectBackoff(attempt int) time.Duration {
+	return min(time.Second<<min(attempt, 5), 30*time.Second)
+}
+
+// encodeEvent frames an event the same way on every transport, with the
+// request ID ctx carries
+func encodeEvent(ctx context.Context, eventType string, payload interface{}) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(models.PubSubEvent{
+		Type:      eventType,
+		Payload:   body,
+		RequestID: requestid.FromContext(ctx),
+	})
+}
+
+// deliverEvent decodes an event read from a transport and hands it to
+// handler (which dispatches to the event bus subscribers on this server)
+func deliverEvent(raw []byte, handler func(*models.PubSubEvent)) {
+	var event models.PubSubEvent
+	if err := json.Unmarshal(raw, &event); err != nil {
+		slog.Warn("⚠️  Failed to unmarshal event", "error", err)
+		return
+	}
+
+	if handler != nil {
+		handler(&event)
+	}
+}
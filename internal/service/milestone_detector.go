@@ -0,0 +1,149 @@
+package service
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/tiers"
+)
+
+var (
+	milestonesPublished = metrics.NewCounterVec("milestones_total",
+		"Milestone events published, by kind (top_rank, personal_best, streak)", "kind")
+	tierChangesPublished = metrics.NewCounterVec("tier_changes_total",
+		"Promotion and demotion events published", "event")
+)
+
+// MilestoneDetector publishes milestone and promotion/demotion events for
+// applied score updates, so clients can celebrate without knowing the
+// thresholds
+type MilestoneDetector interface {
+	Detect(payload *models.ScoreUpdatePayload)
+}
+
+type milestoneDetector struct {
+	ranks           []int64 // ascending
+	personalBest    bool
+	streakDays      map[int]bool
+	ladder          *tiers.Ladder // nil = no tier changes
+	streaks         StreakService // nil = no streak milestones
+	leaderboardRepo repository.LeaderboardRepository
+	bus             *eventbus.Bus
+}
+
+func NewMilestoneDetector(cfg config.MilestoneConfig, ladder *tiers.Ladder, streaks StreakService, leaderboardRepo repository.LeaderboardRepository, bus *eventbus.Bus) MilestoneDetector {
+	ranks := make([]int64, 0, len(cfg.Ranks))
+	for _, rank := range cfg.Ranks {
+		ranks = append(ranks, int64(rank))
+	}
+	slices.Sort(ranks)
+	streakDays := make(map[int]bool, len(cfg.Streaks))
+	for _, days := range cfg.Streaks {
+		streakDays[days] = true
+	}
+
+	return &milestoneDetector{
+		ranks:           slices.Compact(ranks),
+		personalBest:    cfg.PersonalBest,
+		streakDays:      streakDays,
+		ladder:          ladder,
+		streaks:         streaks,
+		leaderboardRepo: leaderboardRepo,
+		bus:             bus,
+	}
+}
+
+// Detect publishes a top_rank event for the smallest top N the update
+// entered (a jump from #2000 to #5 is one "top 10" event), a promotion or
+// demotion when the update crossed a tier or division boundary, a streak
+// event when it made the user's streak one of MILESTONE_STREAKS days, and a
+// personal_best event when the new rating beats the user's record
+func (d *milestoneDetector) Detect(payload *models.ScoreUpdatePayload) {
+	for _, n := range d.ranks {
+		// An old rank of 0 means the user was not ranked before
+		if payload.NewRank >= 1 && payload.NewRank <= n && (payload.OldRank == 0 || payload.OldRank > n) {
+			d.publish(payload, &models.MilestonePayload{Kind: models.MilestoneTopRank, Threshold: n})
+			break
+		}
+	}
+
+	d.detectTierChange(payload)
+	d.detectStreak(payload)
+
+	if !d.personalBest {
+		return
+	}
+	// Also called for drops, so a user's first record starts from the
+	// rating they had before any tracked update
+	previous, improved, err := d.leaderboardRepo.RecordBestRating(payload.UserID, payload.OldRating, payload.NewRating)
+	if err != nil {
+		slog.Warn("⚠️  Failed to record personal best", "user_id", payload.UserID, "error", err)
+		return
+	}
+	if improved {
+		d.publish(payload, &models.MilestonePayload{Kind: models.MilestonePersonalBest, PreviousBest: previous})
+	}
+}
+
+// detectTierChange publishes one promotion or demotion for an update that
+// moved the user to another tier or division, however many it skipped
+func (d *milestoneDetector) detectTierChange(update *models.ScoreUpdatePayload) {
+	before, after := d.ladder.Place(update.OldRating), d.ladder.Place(update.NewRating)
+	order := d.ladder.Compare(after, before)
+	if order == 0 {
+		return
+	}
+
+	event := models.EventPromotion
+	if order < 0 {
+		event = models.EventDemotion
+	}
+	change := &models.TierChangePayload{
+		UserID:      update.UserID,
+		Username:    update.Username,
+		OldTier:     before.Tier,
+		OldDivision: before.Division,
+		NewTier:     after.Tier,
+		NewDivision: after.Division,
+		Rank:        update.NewRank,
+		Rating:      update.NewRating,
+		Timestamp:   time.Now().Unix(),
+	}
+	if err := d.bus.Publish(event, change); err != nil {
+		slog.Warn("⚠️  Failed to publish tier change", "event", event, "user_id", update.UserID, "error", err)
+		return
+	}
+	tierChangesPublished.WithLabelValues(event).Inc()
+}
+
+// detectStreak counts the update toward the user's daily streak, once per
+// day, and publishes the lengths listed in MILESTONE_STREAKS
+func (d *milestoneDetector) detectStreak(update *models.ScoreUpdatePayload) {
+	if d.streaks == nil {
+		return
+	}
+	current, advanced := d.streaks.Record(update)
+	if advanced && d.streakDays[current] {
+		d.publish(update, &models.MilestonePayload{Kind: models.MilestoneStreak, Threshold: int64(current)})
+	}
+}
+
+func (d *milestoneDetector) publish(update *models.ScoreUpdatePayload, milestone *models.MilestonePayload) {
+	milestone.UserID = update.UserID
+	milestone.Username = update.Username
+	milestone.Rank = update.NewRank
+	milestone.Rating = update.NewRating
+	milestone.Timestamp = time.Now().Unix()
+
+	if err := d.bus.Publish(models.EventMilestone, milestone); err != nil {
+		slog.Warn("⚠️  Failed to publish milestone", "kind", milestone.Kind, "user_id", milestone.UserID, "error", err)
+		return
+	}
+	milestonesPublished.WithLabelValues(milestone.Kind).Inc()
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/nats-io/nats.go"
+)
+
+type natsBus struct {
+	*busState
+	url          string
+	name         string
+	subject      string
+	conn         *nats.Conn
+	running      bool
+	eventHandler func(*models.PubSubEvent)
+}
+
+// NewNATSBus creates an event transport over NATS core publish/subscribe,
+// for deployments that already run NATS. Like Redis Pub/Sub it is
+// fire-and-forget. name identifies this server's connection; subject is
+// NATS_SUBJECT with the tenant's prefix.
+func NewNATSBus(url, name, subject string) (MessageBus, error) {
+	return &natsBus{
+		busState: newBusState("nats"),
+		url:      url,
+		name:     name,
+		subject:  subject,
+	}, nil
+}
+
+// Start connects and subscribes to the subject. The client keeps
+// reconnecting in the background, from the first attempt on.
+func (s *natsBus) Start() {
+	if s.running {
+		slog.Warn("⚠️  NATS bus already running")
+		return
+	}
+
+	conn, err := nats.Connect(s.url,
+		nats.Name(s.name),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.CustomReconnectDelay(reconnectBackoff),
+		// Fail publishes while reconnecting instead of buffering them
+		nats.ReconnectBufSize(-1),
+		nats.PingInterval(30*time.Second),
+		nats.ConnectHandler(func(*nats.Conn) { s.up() }),
+		nats.ReconnectHandler(func(*nats.Conn) { s.up() }),
+		nats.DisconnectErrHandler(s.lost),
+		nats.ReconnectErrHandler(s.lost),
+	)
+	if err != nil {
+		s.down(err)
+		slog.Error("❌ Failed to start NATS bus", "error", err)
+		return
+	}
+	if conn.IsConnected() {
+		s.up()
+	}
+
+	if _, err := conn.Subscribe(s.subject, func(msg *nats.Msg) {
+		deliverEvent(msg.Data, s.eventHandler)
+	}); err != nil {
+		conn.Close()
+		s.down(err)
+		slog.Error("❌ Failed to subscribe to NATS subject", "subject", s.subject, "error", err)
+		return
+	}
+	s.conn = conn
+	s.running = true
+	slog.Info("📡 NATS bus started", "subject", s.subject)
+}
+
+// lost records a dropped connection or a failed reconnect attempt
+func (s *natsBus) lost(conn *nats.Conn, err error) {
+	if conn.IsClosed() {
+		return
+	}
+	if err == nil {
+		err = nats.ErrConnectionClosed
+	}
+	s.down(err)
+	slog.Warn("⚠️  NATS connection lost, reconnecting", "error", err)
+}
+
+// Stop closes the connection
+func (s *natsBus) Stop() {
+	if !s.running {
+		return
+	}
+
+	slog.Info("⏹️  Stopping NATS bus...")
+	s.conn.Close()
+	s.running = false
+}
+
+// OnEvent sets the handler for received events (call before Start)
+func (s *natsBus) OnEvent(handler func(*models.PubSubEvent)) {
+	s.eventHandler = handler
+}
+
+// PublishEvent sends a typed event to every server subscribed to the
+// subject (including this one); it fails while NATS is unreachable
+func (s *natsBus) PublishEvent(ctx context.Context, eventType string, payload interface{}) error {
+	data, err := encodeEvent(ctx, eventType, payload)
+	if err != nil {
+		return err
+	}
+	if s.conn == nil {
+		return nats.ErrConnectionClosed
+	}
+	return s.conn.Publish(s.subject, data)
+}
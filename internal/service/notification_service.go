@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/notify"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/schedule"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+var (
+	notificationEvents   = []string{models.NotifyDailyDigest, models.NotifyScoreUpdate}
+	notificationChannels = []string{models.ChannelWebhook, models.ChannelEmail}
+)
+
+// ErrInvalidPreferences wraps notification preference validation errors
+var ErrInvalidPreferences = newValidationError("invalid notification preferences")
+
+var (
+	notifySuppressed = metrics.NewCounterVec("notify_suppressed_total",
+		"Notifications not sent because of the user's preferences", "event", "reason")
+	notifyDropped = metrics.NewCounter("notify_dropped_total",
+		"Score update notifications dropped because the send queue was full")
+)
+
+// NotificationGate decides whether a notification may be delivered
+type NotificationGate interface {
+	Allows(userID uint, event, channel string, at time.Time) bool
+}
+
+// NotificationService keeps per-user notification preferences (falling back
+// to the tenant defaults) and sends opt-in score update notifications
+type NotificationService interface {
+	NotificationGate
+	Start()
+	Stop()
+	GetPreferences(userID uint) (*models.NotificationPreference, error)
+	UpdatePreferences(pref *models.NotificationPreference) error
+	ResetPreferences(userID uint) error
+	HandleScoreUpdate(event eventbus.Event)
+}
+
+type notificationService struct {
+	cfg             config.NotificationConfig
+	redis           *redis.Client
+	notifyRepo      repository.NotificationRepository
+	digestRepo      repository.DigestRepository
+	userRepo        repository.UserRepository
+	leaderboardRepo repository.LeaderboardRepository
+	webhook         notify.Sender
+	email           notify.Sender
+	ctx             context.Context
+
+	queue  chan *models.ScoreUpdatePayload
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+func NewNotificationService(
+	cfg config.NotificationConfig,
+	redisClient *redis.Client,
+	notifyRepo repository.NotificationRepository,
+	digestRepo repository.DigestRepository,
+	userRepo repository.UserRepository,
+	leaderboardRepo repository.LeaderboardRepository,
+	webhook notify.Sender,
+	email notify.Sender,
+) NotificationService {
+	queueSize := cfg.QueueSize
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return &notificationService{
+		cfg:             cfg,
+		redis:           redisClient,
+		notifyRepo:      notifyRepo,
+		digestRepo:      digestRepo,
+		userRepo:        userRepo,
+		leaderboardRepo: leaderboardRepo,
+		webhook:         webhook,
+		email:           email,
+		ctx:             database.Ctx,
+		queue:           make(chan *models.ScoreUpdatePayload, queueSize),
+		stopCh:          make(chan struct{}),
+	}
+}
+
+// Start runs the score update notification senders
+func (s *notificationService) Start() {
+	workers := s.cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case payload := <-s.queue:
+					s.sendScoreUpdate(payload)
+				case <-s.stopCh:
+					return
+				}
+			}
+		}()
+	}
+	slog.Info("🔔 Score update notifications started", "senders", workers)
+}
+
+func (s *notificationService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+// GetPreferences returns the user's effective preferences: saved ones,
+// else the tenant defaults (Default set)
+func (s *notificationService) GetPreferences(userID uint) (*models.NotificationPreference, error) {
+	pref, err := s.cachedPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pref.Timezone = "UTC"
+	if user, err := s.leaderboardRepo.GetCachedUser(userID); err == nil && user.Timezone != "" {
+		pref.Timezone = user.Timezone
+	}
+	return pref, nil
+}
+
+// UpdatePreferences validates and stores a user's preferences
+func (s *notificationService) UpdatePreferences(pref *models.NotificationPreference) error {
+	for _, event := range pref.Events {
+		if !slices.Contains(notificationEvents, event) {
+			return fmt.Errorf("%w: unknown event %q (use %s)", ErrInvalidPreferences, event, strings.Join(notificationEvents, ", "))
+		}
+	}
+	for _, channel := range pref.Channels {
+		if !slices.Contains(notificationChannels, channel) {
+			return fmt.Errorf("%w: unknown channel %q (use %s)", ErrInvalidPreferences, channel, strings.Join(notificationChannels, ", "))
+		}
+	}
+	if _, _, err := parseQuietHours(pref.QuietHours); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPreferences, err)
+	}
+	if pref.Events == nil {
+		pref.Events = []string{}
+	}
+	if pref.Channels == nil {
+		pref.Channels = []string{}
+	}
+
+	if _, err := s.userRepo.GetByID(pref.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if err := s.notifyRepo.Upsert(pref); err != nil {
+		return err
+	}
+	s.invalidate(pref.UserID)
+	return nil
+}
+
+// ResetPreferences removes saved preferences so the tenant defaults apply
+func (s *notificationService) ResetPreferences(userID uint) error {
+	if err := s.notifyRepo.Delete(userID); err != nil {
+		return err
+	}
+	s.invalidate(userID)
+	return nil
+}
+
+// Allows reports whether event may go out over channel at the given time.
+// When preferences can't be read nothing is sent.
+func (s *notificationService) Allows(userID uint, event, channel string, at time.Time) bool {
+	pref, err := s.GetPreferences(userID)
+	if err != nil {
+		slog.Warn("⚠️ Failed to load notification preferences", "user_id", userID, "error", err)
+		notifySuppressed.WithLabelValues(event, "error").Inc()
+		return false
+	}
+
+	switch {
+	case !slices.Contains(pref.Events, event):
+		notifySuppressed.WithLabelValues(event, "event").Inc()
+		return false
+	case !slices.Contains(pref.Channels, channel):
+		notifySuppressed.WithLabelValues(event, "channel").Inc()
+		return false
+	case inQuietHours(pref.QuietHours, pref.Timezone, at):
+		notifySuppressed.WithLabelValues(event, "quiet_hours").Inc()
+		return false
+	}
+	return true
+}
+
+// HandleScoreUpdate queues a score update notification (subscribed on the
+// event bus of the server that accepted the update, so it is sent once)
+func (s *notificationService) HandleScoreUpdate(event eventbus.Event) {
+	payload, ok := event.Payload.(*models.ScoreUpdatePayload)
+	if !ok {
+		return
+	}
+
+	select {
+	case s.queue <- payload:
+	default:
+		notifyDropped.Inc()
+	}
+}
+
+func (s *notificationService) sendScoreUpdate(payload *models.ScoreUpdatePayload) {
+	// Cheap check first: most users never opt into score updates
+	pref, err := s.GetPreferences(payload.UserID)
+	if err != nil || !slices.Contains(pref.Events, models.NotifyScoreUpdate) {
+		return
+	}
+
+	sub, err := s.digestRepo.GetByUserID(payload.UserID)
+	if err != nil {
+		return // no delivery endpoints registered
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	msg := notify.Message{
+		Event:   models.NotifyScoreUpdate,
+		Subject: fmt.Sprintf("Your rating is now %d", payload.NewRating),
+		Payload: payload,
+	}
+	now := time.Now()
+
+	for _, target := range []struct {
+		channel string
+		to      string
+		sender  notify.Sender
+	}{
+		{models.ChannelWebhook, sub.WebhookURL, s.webhook},
+		{models.ChannelEmail, sub.Email, s.email},
+	} {
+		if target.to == "" || !s.Allows(payload.UserID, models.NotifyScoreUpdate, target.channel, now) {
+			continue
+		}
+		msg.To = target.to
+		if err := target.sender.Send(ctx, msg); err != nil {
+			slog.Warn("⚠️ Score update notification failed", "channel", target.channel, "user_id", payload.UserID, "error", err)
+		}
+	}
+}
+
+// cachedPreferences reads preferences through the Redis cache
+func (s *notificationService) cachedPreferences(userID uint) (*models.NotificationPreference, error) {
+	key := fmt.Sprintf(database.NotifyPrefsKey, userID)
+
+	if data, err := s.redis.Get(s.ctx, key).Bytes(); err == nil {
+		var pref models.NotificationPreference
+		if err := json.Unmarshal(data, &pref); err == nil {
+			return &pref, nil
+		}
+	}
+
+	pref, err := s.notifyRepo.GetByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		pref = &models.NotificationPreference{
+			UserID:     userID,
+			Events:     s.cfg.DefaultEvents,
+			Channels:   s.cfg.DefaultChannels,
+			QuietHours: s.cfg.QuietHours,
+			Default:    true,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(pref); err == nil {
+		s.redis.Set(s.ctx, key, data, s.cfg.CacheTTL)
+	}
+	return pref, nil
+}
+
+func (s *notificationService) invalidate(userID uint) {
+	if err := s.redis.Del(s.ctx, fmt.Sprintf(database.NotifyPrefsKey, userID)).Err(); err != nil {
+		slog.Warn("⚠️ Failed to invalidate notification preferences", "user_id", userID, "error", err)
+	}
+}
+
+// parseQuietHours parses "HH:MM-HH:MM" into minutes of the day ("" = none)
+func parseQuietHours(value string) (int, int, error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+
+	startStr, endStr, ok := strings.Cut(value, "-")
+	start, startErr := time.Parse("15:04", startStr)
+	end, endErr := time.Parse("15:04", endStr)
+	if !ok || startErr != nil || endErr != nil || start.Equal(end) {
+		return 0, 0, fmt.Errorf("invalid quiet_hours %q (use HH:MM-HH:MM, e.g. 22:00-07:00)", value)
+	}
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute(), nil
+}
+
+// inQuietHours reports whether at falls in the window, in the given zone;
+// windows may wrap past midnight
+func inQuietHours(window, timezone string, at time.Time) bool {
+	start, end, err := parseQuietHours(window)
+	if err != nil || window == "" {
+		return false
+	}
+
+	loc, err := schedule.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := at.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/schedule"
+)
+
+// PeriodBoardService keeps daily and weekly boards of rating gained. With
+// local time enabled each user's gains land in the window of their own
+// timezone, so every region's day starts at its own midnight. It also keeps
+// the most improved board: rating gained over a rolling window of hours.
+type PeriodBoardService interface {
+	HandleScoreUpdate(event eventbus.Event)
+	GetBoard(period schedule.Period, timezone string, viewerID uint, limit int) (schedule.Window, []models.PeriodEntry, error)
+	GetMostImproved(limit int) ([]models.PeriodEntry, error)
+}
+
+type periodBoardService struct {
+	cfg             config.PeriodConfig
+	leaderboardRepo repository.LeaderboardRepository
+	userRepo        repository.UserRepository
+}
+
+func NewPeriodBoardService(
+	cfg config.PeriodConfig,
+	leaderboardRepo repository.LeaderboardRepository,
+	userRepo repository.UserRepository,
+) PeriodBoardService {
+	return &periodBoardService{
+		cfg:             cfg,
+		leaderboardRepo: leaderboardRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// HandleScoreUpdate adds a public score change to the user's current daily
+// and weekly windows (subscribed on the server that accepted the update)
+func (s *periodBoardService) HandleScoreUpdate(event eventbus.Event) {
+	payload, ok := event.Payload.(*models.ScoreUpdatePayload)
+	if !ok || payload.RatingDelta == 0 {
+		return
+	}
+
+	loc := s.userLocation(payload.UserID)
+	at := time.Unix(payload.Timestamp, 0)
+
+	for _, period := range []schedule.Period{schedule.Daily, schedule.Weekly} {
+		window := schedule.Resolve(period, loc, at)
+		// Keep the board readable for the retention period after it closes
+		ttl := time.Until(window.End) + s.cfg.Retention
+		if err := s.leaderboardRepo.AddPeriodGain(window.ID(), payload.UserID, payload.RatingDelta, ttl); err != nil {
+			slog.Warn("⚠️  Failed to update period board", "period", period, "user_id", payload.UserID, "error", err)
+		}
+	}
+
+	// An hourly bucket is needed until the window no longer covers it
+	if err := s.leaderboardRepo.AddImprovedGain(payload.UserID, payload.RatingDelta, at, s.cfg.ImprovedWindow+time.Hour); err != nil {
+		slog.Warn("⚠️  Failed to update most improved board", "user_id", payload.UserID, "error", err)
+	}
+}
+
+// userLocation is the timezone whose windows a user's gains count toward
+func (s *periodBoardService) userLocation(userID uint) *time.Location {
+	return userLocation(s.leaderboardRepo, s.cfg.LocalTime, userID)
+}
+
+// userLocation is a user's cached timezone when local time is on, else UTC
+func userLocation(leaderboardRepo repository.LeaderboardRepository, localTime bool, userID uint) *time.Location {
+	if !localTime {
+		return time.UTC
+	}
+
+	tz, err := leaderboardRepo.GetCachedTimezone(userID)
+	if err != nil {
+		return time.UTC
+	}
+	loc, err := schedule.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// GetBoard returns the current window of a period with its top gainers. The
+// window is that of timezone, else of the viewer's own timezone, else UTC.
+func (s *periodBoardService) GetBoard(period schedule.Period, timezone string, viewerID uint, limit int) (schedule.Window, []models.PeriodEntry, error) {
+	loc := time.UTC
+	switch {
+	case !s.cfg.LocalTime:
+	case timezone != "":
+		var err error
+		if loc, err = schedule.LoadLocation(timezone); err != nil {
+			return schedule.Window{}, nil, ErrInvalidTimezone
+		}
+	case viewerID != 0:
+		loc = s.userLocation(viewerID)
+	}
+	window := schedule.Resolve(period, loc, time.Now())
+
+	entries, err := s.leaderboardRepo.GetPeriodTop(window.ID(), limit)
+	if err != nil {
+		return window, nil, fmt.Errorf("failed to get %s board: %w", period, err)
+	}
+
+	s.fillUsernames(entries)
+	return window, entries, nil
+}
+
+// GetMostImproved returns the biggest rating gainers of the last
+// MOST_IMPROVED_WINDOW
+func (s *periodBoardService) GetMostImproved(limit int) ([]models.PeriodEntry, error) {
+	entries, err := s.leaderboardRepo.GetMostImproved(s.cfg.ImprovedWindow, limit, s.cfg.ImprovedCacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get most improved board: %w", err)
+	}
+
+	s.fillUsernames(entries)
+	return entries, nil
+}
+
+// fillUsernames enriches usernames from cache, falling back to PostgreSQL
+func (s *periodBoardService) fillUsernames(entries []models.PeriodEntry) {
+	for i := range entries {
+		if user, err := s.leaderboardRepo.GetCachedUser(entries[i].UserID); err == nil {
+			entries[i].Username = user.Username
+			continue
+		}
+		if user, err := s.userRepo.GetByID(entries[i].UserID); err == nil {
+			entries[i].Username = user.Username
+		}
+	}
+}
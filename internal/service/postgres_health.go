@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"gorm.io/gorm"
+)
+
+var postgresDown = metrics.NewGaugeVec("postgres_down",
+	"1 while PostgreSQL is unreachable and the DB sync is paused", "tenant")
+
+// PostgresHealth pings PostgreSQL and reports when it has been unreachable
+// for several checks in a row, so the DB sync can pause instead of failing
+// (and eventually dead-lettering) every batch
+type PostgresHealth interface {
+	Start()
+	Stop()
+	Down() bool
+}
+
+type postgresHealth struct {
+	cfg    config.PostgresOutageConfig
+	db     *gorm.DB
+	tenant string
+
+	down     atomic.Bool
+	failures int
+	stopCh   chan struct{}
+	once     sync.Once
+}
+
+func NewPostgresHealth(cfg config.PostgresOutageConfig, db *gorm.DB, tenant string) PostgresHealth {
+	return &postgresHealth{
+		cfg:    cfg,
+		db:     db,
+		tenant: tenant,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start runs the health checks; when disabled PostgreSQL is never reported
+// down
+func (h *postgresHealth) Start() {
+	if !h.cfg.Enabled {
+		return
+	}
+	postgresDown.WithLabelValues(h.tenant).Set(0)
+
+	go func() {
+		ticker := time.NewTicker(h.cfg.CheckEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				h.check()
+			case <-h.stopCh:
+				return
+			}
+		}
+	}()
+	slog.Info("🩺 PostgreSQL health checks started", "tenant", h.tenant, "interval", h.cfg.CheckEvery)
+}
+
+func (h *postgresHealth) Stop() {
+	h.once.Do(func() { close(h.stopCh) })
+}
+
+// Down reports whether PostgreSQL is considered unreachable
+func (h *postgresHealth) Down() bool {
+	return h.down.Load()
+}
+
+func (h *postgresHealth) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), h.cfg.CheckEvery)
+	defer cancel()
+
+	err := h.ping(ctx)
+	if err != nil {
+		h.failures++
+		if h.failures >= h.cfg.FailAfter && !h.down.Swap(true) {
+			postgresDown.WithLabelValues(h.tenant).Set(1)
+			slog.Error("🚨 PostgreSQL unreachable: pausing DB sync, score history accumulates in Redis", "tenant", h.tenant, "error", err)
+		}
+		return
+	}
+
+	h.failures = 0
+	if h.down.Swap(false) {
+		postgresDown.WithLabelValues(h.tenant).Set(0)
+		slog.Info("✅ PostgreSQL reachable again: catching up on the DB sync backlog", "tenant", h.tenant)
+	}
+}
+
+func (h *postgresHealth) ping(ctx context.Context) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
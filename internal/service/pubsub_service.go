@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
+	"net"
+	"time"
 
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
@@ -11,99 +13,133 @@ import (
 )
 
 const (
-	ScoreUpdateChannel = "leaderboard:score_updates"
-)
+	EventChannel = "leaderboard:events" // typed events (score updates, renames, removals, ...)
 
-type PubSubService interface {
-	Start(messageHandler func(*models.ScoreUpdatePayload))
-	Stop()
-	Publish(payload *models.ScoreUpdatePayload) error
-}
+	// A subscription with no message for this long is pinged, and dropped
+	// when the next wait passes without the pong
+	pubSubIdle = 30 * time.Second
+)
 
 type pubSubService struct {
-	redis     *redis.Client
-	ctx       context.Context
-	cancelCtx context.CancelFunc
-	pubsub    *redis.PubSub
-	running   bool
+	*busState
+	redis        *redis.Client
+	channel      string
+	ctx          context.Context
+	cancelCtx    context.CancelFunc
+	running      bool
+	eventHandler func(*models.PubSubEvent)
 }
 
-func NewPubSubService(redisClient *redis.Client) PubSubService {
+// NewPubSubService creates the default event transport, over Redis
+// Pub/Sub. channelPrefix keeps a tenant's events apart (pub/sub channels
+// are not keys, so the sandbox key prefix does not apply to them);
+// production passes "".
+func NewPubSubService(redisClient *redis.Client, channelPrefix string) MessageBus {
 	ctx, cancel := context.WithCancel(database.Ctx)
 
 	return &pubSubService{
+		busState:  newBusState("pubsub"),
 		redis:     redisClient,
+		channel:   channelPrefix + EventChannel,
 		ctx:       ctx,
 		cancelCtx: cancel,
 		running:   false,
 	}
 }
 
-// Start subscribes to Redis channel and handles incoming messages
-func (s *pubSubService) Start(messageHandler func(*models.ScoreUpdatePayload)) {
+// Start subscribes to Redis channel and handles incoming messages,
+// subscribing again with backoff whenever the subscription fails
+func (s *pubSubService) Start() {
 	if s.running {
-		log.Println("⚠️  PubSub service already running")
+		slog.Warn("⚠️  PubSub service already running")
 		return
 	}
-
-	// Subscribe to channel
-	s.pubsub = s.redis.Subscribe(s.ctx, ScoreUpdateChannel)
 	s.running = true
 
-	log.Printf("📡 PubSub service started (subscribed to: %s)", ScoreUpdateChannel)
-
-	// Start listening in goroutine
-	go func() {
-		defer func() {
-			s.pubsub.Close()
-			s.running = false
-		}()
-
-		// Receive messages
-		ch := s.pubsub.Channel()
-
-		for {
-			select {
-			case msg := <-ch:
-				// Parse message
-				var payload models.ScoreUpdatePayload
-				if err := json.Unmarshal([]byte(msg.Payload), &payload); err != nil {
-					log.Printf("⚠️  Failed to unmarshal PubSub message: %v", err)
-					continue
-				}
+	slog.Info("📡 PubSub service started", "channel", s.channel)
+	go s.run()
+}
 
-				// Call handler (broadcasts to local WebSocket clients)
-				if messageHandler != nil {
-					messageHandler(&payload)
-				}
+func (s *pubSubService) run() {
+	defer func() {
+		s.running = false
+	}()
+
+	for attempt := 0; s.ctx.Err() == nil; attempt++ {
+		pubsub := s.redis.Subscribe(s.ctx, s.channel)
+		// The first reply confirms the subscription
+		_, err := pubsub.Receive(s.ctx)
+		if err == nil {
+			s.up()
+			attempt = 0
+			slog.Info("📡 PubSub subscribed", "channel", s.channel)
+			err = s.receive(pubsub)
+		}
+		pubsub.Close()
+		if s.ctx.Err() != nil {
+			break
+		}
 
-			case <-s.ctx.Done():
-				log.Println("⏹️  PubSub subscription stopped")
-				return
+		wait := reconnectBackoff(attempt)
+		s.down(err)
+		slog.Warn("⚠️  PubSub subscription failed, resubscribing", "wait", wait, "error", err)
+		select {
+		case <-time.After(wait):
+		case <-s.ctx.Done():
+		}
+	}
+	slog.Info("⏹️  PubSub subscription stopped")
+}
+
+// receive delivers messages until the subscription fails. An idle
+// subscription is pinged, so a dead connection is noticed even when no
+// events are published.
+func (s *pubSubService) receive(pubsub *redis.PubSub) error {
+	pinged := false
+	for {
+		msg, err := pubsub.ReceiveTimeout(s.ctx, pubSubIdle)
+		if err != nil {
+			var netErr net.Error
+			if !pinged && errors.As(err, &netErr) && netErr.Timeout() {
+				if err := pubsub.Ping(s.ctx); err != nil {
+					return err
+				}
+				pinged = true
+				continue
 			}
+			return err
 		}
-	}()
+		pinged = false
+
+		if message, ok := msg.(*redis.Message); ok {
+			deliverEvent([]byte(message.Payload), s.eventHandler)
+		}
+	}
 }
 
 // Stop unsubscribes and closes the subscription
 func (s *pubSubService) Stop() {
 	if !s.running {
+		s.cancelCtx() // never started (or already stopped): only release the context
 		return
 	}
 
-	log.Println("⏹️  Stopping PubSub service...")
+	slog.Info("⏹️  Stopping PubSub service...")
 	s.cancelCtx()
 }
 
-// Publish sends a score update to Redis channel (broadcasts to ALL servers)
-func (s *pubSubService) Publish(payload *models.ScoreUpdatePayload) error {
-	// Serialize payload
-	data, err := json.Marshal(payload)
+// OnEvent sets the handler for received events (call before Start)
+func (s *pubSubService) OnEvent(handler func(*models.PubSubEvent)) {
+	s.eventHandler = handler
+}
+
+// PublishEvent sends a typed event to Redis channel (broadcasts to ALL servers)
+func (s *pubSubService) PublishEvent(ctx context.Context, eventType string, payload interface{}) error {
+	data, err := encodeEvent(ctx, eventType, payload)
 	if err != nil {
 		return err
 	}
 
-	// Publish to Redis channel
 	// All subscribed servers (including this one) will receive it
-	return s.redis.Publish(s.ctx, ScoreUpdateChannel, data).Err()
+	return s.redis.Publish(s.ctx, s.channel, data).Err()
 }
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// Most updates of other players an explanation may replay
+	MaxExplainEvents = 100000
+
+	explainOwnUpdates = 100 // newest own updates listed
+	explainPlayers    = 20  // players with the largest impact listed
+
+	// How far apart an audit entry and the update it recorded may be
+	explainAuditSlack = time.Minute
+)
+
+var (
+	// ErrExplainWindow is returned when since reaches back into compacted history
+	ErrExplainWindow = newValidationError("explanations only cover raw score history")
+	// ErrExplainTooBusy is returned when too many updates happened near the user's rating
+	ErrExplainTooBusy = newValidationError("too many score updates near this rating, use a later since")
+	// ErrUserNotOnBoard is returned for banned users
+	ErrUserNotOnBoard = errors.New("user is not on the leaderboard")
+)
+
+// RankExplainService explains a user's rank change since a point in time
+// from the score update log: their own updates (and which admin
+// adjustments or results they were) and other players passing them
+type RankExplainService interface {
+	Explain(userID uint, since time.Time) (*models.RankExplanation, error)
+}
+
+type rankExplainService struct {
+	cfg             config.HistoryConfig
+	userRepo        repository.UserRepository
+	scoreUpdateRepo repository.ScoreUpdateRepository
+	auditRepo       repository.AuditRepository
+	leaderboardRepo repository.LeaderboardRepository
+}
+
+func NewRankExplainService(
+	cfg config.HistoryConfig,
+	userRepo repository.UserRepository,
+	scoreUpdateRepo repository.ScoreUpdateRepository,
+	auditRepo repository.AuditRepository,
+	leaderboardRepo repository.LeaderboardRepository,
+) RankExplainService {
+	return &rankExplainService{
+		cfg:             cfg,
+		userRepo:        userRepo,
+		scoreUpdateRepo: scoreUpdateRepo,
+		auditRepo:       auditRepo,
+		leaderboardRepo: leaderboardRepo,
+	}
+}
+
+// explainer replays the window. Players without updates in it (static)
+// are counted on the current board; players with updates (moving) are
+// tracked one update at a time.
+type explainer struct {
+	leaderboardRepo repository.LeaderboardRepository
+	onBoard         bool // the user is on the public board (counted by CountAbove)
+	ratingNow       int
+	current         map[uint]int // moving players' rating now
+}
+
+// staticAbove counts static players rated above rating
+func (e *explainer) staticAbove(rating int) (int64, error) {
+	above, err := e.leaderboardRepo.CountAbove(rating)
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range e.current {
+		if r > rating {
+			above--
+		}
+	}
+	if e.onBoard && e.ratingNow > rating {
+		above--
+	}
+	return above, nil
+}
+
+// Explain replays the user's and nearby players' updates since the given
+// time. Each own update moves the rank by the players between its old
+// and new rating; each update of another player that crosses the user's
+// rating moves it by one.
+func (s *rankExplainService) Explain(userID uint, since time.Time) (*models.RankExplanation, error) {
+	now := time.Now()
+	if s.cfg.CompactAfter > 0 && since.Before(now.Add(-s.cfg.CompactAfter)) {
+		return nil, fmt.Errorf("%w (at most %v back)", ErrExplainWindow, s.cfg.CompactAfter)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Status == models.UserStatusBanned {
+		return nil, ErrUserNotOnBoard
+	}
+
+	ratingNow := user.Rating
+	if scores, err := s.leaderboardRepo.GetScores([]uint{userID}); err == nil {
+		if score, ok := scores[userID]; ok {
+			ratingNow = score
+		}
+	}
+
+	own, err := s.scoreUpdateRepo.GetByUserSince(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Band of ratings the user held; only players within it can pass them
+	ratingThen := ratingNow
+	if len(own) > 0 {
+		ratingThen = own[0].OldRating
+	}
+	minRating, maxRating := ratingThen, ratingThen
+	for _, rating := range append(ratingValues(own), ratingNow) {
+		minRating = min(minRating, rating)
+		maxRating = max(maxRating, rating)
+	}
+
+	others, err := s.scoreUpdateRepo.GetBandUpdatesSince(since, userID, minRating, maxRating, MaxExplainEvents+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(others) > MaxExplainEvents {
+		return nil, ErrExplainTooBusy
+	}
+
+	// Moving players' ratings at since and now
+	rating := make(map[uint]int)
+	current := make(map[uint]int)
+	for _, update := range others {
+		if _, ok := rating[update.UserID]; !ok {
+			rating[update.UserID] = update.OldRating
+		}
+		current[update.UserID] = update.NewRating
+	}
+
+	e := &explainer{
+		leaderboardRepo: s.leaderboardRepo,
+		onBoard:         user.Status == models.UserStatusActive,
+		ratingNow:       ratingNow,
+		current:         current,
+	}
+
+	rankThen, err := e.staticAbove(ratingThen)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rating {
+		if r > ratingThen {
+			rankThen++
+		}
+	}
+	rankThen++
+
+	audits, err := s.auditsSince(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &models.RankExplanation{
+		UserID:     userID,
+		Since:      since.UTC(),
+		Until:      now.UTC(),
+		RatingThen: ratingThen,
+		RatingNow:  ratingNow,
+		RankThen:   rankThen,
+		OwnUpdates: []models.OwnRankChange{},
+		Players:    []models.PlayerRankImpact{},
+	}
+	impacts := make(map[uint]*models.PlayerRankImpact)
+	var explained int64
+
+	userRating := ratingThen
+	i, j := 0, 0
+	for i < len(own) || j < len(others) {
+		// Own updates go first within the same instant
+		if i < len(own) && (j == len(others) || !others[j].UpdatedAt.Before(own[i].UpdatedAt)) {
+			update := own[i]
+			i++
+
+			lo, hi := update.OldRating, update.NewRating
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			loAbove, err := e.staticAbove(lo)
+			if err != nil {
+				return nil, err
+			}
+			hiAbove, err := e.staticAbove(hi)
+			if err != nil {
+				return nil, err
+			}
+			between := loAbove - hiAbove
+			for _, r := range rating {
+				if r > lo && r <= hi {
+					between++
+				}
+			}
+			change := between
+			if update.NewRating < update.OldRating {
+				change = -between
+			}
+
+			entry := models.OwnRankChange{
+				At:         update.UpdatedAt.UTC(),
+				OldRating:  update.OldRating,
+				NewRating:  update.NewRating,
+				RankChange: change,
+				Cause:      models.CauseScoreUpdate,
+			}
+			if audit := matchAudit(audits, update); audit != nil {
+				entry.Source = audit.Source
+				entry.Reason = audit.Reason
+				switch audit.Source {
+				case AdjustmentResult:
+					entry.Cause = models.CauseMatchResult
+				case AdjustmentRevert, AdjustmentImport:
+					entry.Cause = models.CauseAdminAdjustment
+				case AdjustmentDecay:
+					entry.Cause = models.CauseDecay
+				}
+			}
+			switch entry.Cause {
+			case models.CauseAdminAdjustment:
+				explanation.Summary.AdminAdjustments += change
+			case models.CauseDecay:
+				explanation.Summary.Decay += change
+			default:
+				explanation.Summary.OwnUpdates += change
+			}
+			explained += change
+			explanation.OwnUpdates = append(explanation.OwnUpdates, entry)
+			userRating = update.NewRating
+			continue
+		}
+
+		update := others[j]
+		j++
+		rating[update.UserID] = update.NewRating
+
+		var change int64
+		switch {
+		case update.OldRating <= userRating && update.NewRating > userRating:
+			change = -1
+			explanation.Summary.Overtaken--
+		case update.NewRating <= userRating && update.OldRating > userRating:
+			change = 1
+			explanation.Summary.OthersDropped++
+		default:
+			continue
+		}
+		explained += change
+
+		impact := impacts[update.UserID]
+		if impact == nil {
+			impact = &models.PlayerRankImpact{UserID: update.UserID}
+			impacts[update.UserID] = impact
+		}
+		if change < 0 {
+			impact.Overtook++
+		} else {
+			impact.FellBehind++
+		}
+		impact.RankChange += change
+		impact.LastAt = update.UpdatedAt.UTC()
+	}
+
+	rankNow, err := s.leaderboardRepo.CountAbove(ratingNow)
+	if err != nil {
+		return nil, err
+	}
+	explanation.RankNow = rankNow + 1
+	explanation.RankChange = explanation.RankThen - explanation.RankNow
+	explanation.Summary.Unexplained = explanation.RankChange - explained
+
+	if len(explanation.OwnUpdates) > explainOwnUpdates {
+		explanation.OwnUpdates = explanation.OwnUpdates[len(explanation.OwnUpdates)-explainOwnUpdates:]
+		explanation.Truncated = true
+	}
+	if err := s.addPlayers(explanation, impacts); err != nil {
+		return nil, err
+	}
+	return explanation, nil
+}
+
+// auditsSince loads the user's audit entries of the window (newest first)
+func (s *rankExplainService) auditsSince(userID uint, since time.Time) ([]models.AdminAdjustment, error) {
+	var audits []models.AdminAdjustment
+	filter := repository.AuditFilter{UserID: userID, Limit: 500}
+	for {
+		page, err := s.auditRepo.List(filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, audit := range page {
+			if audit.CreatedAt.Before(since.Add(-explainAuditSlack)) {
+				return audits, nil
+			}
+			audits = append(audits, audit)
+		}
+		if len(page) < filter.Limit {
+			return audits, nil
+		}
+		filter.BeforeID = page[len(page)-1].ID
+	}
+}
+
+// matchAudit finds (and consumes) the audit entry recording an update
+func matchAudit(audits []models.AdminAdjustment, update models.ScoreUpdate) *models.AdminAdjustment {
+	for i := range audits {
+		audit := &audits[i]
+		if audit.UserID == 0 || audit.OldRating != update.OldRating || audit.NewRating != update.NewRating {
+			continue
+		}
+		gap := audit.CreatedAt.Sub(update.UpdatedAt)
+		if gap < -explainAuditSlack || gap > explainAuditSlack {
+			continue
+		}
+		matched := *audit
+		audit.UserID = 0
+		return &matched
+	}
+	return nil
+}
+
+// addPlayers lists the players with the largest impact, with usernames
+func (s *rankExplainService) addPlayers(explanation *models.RankExplanation, impacts map[uint]*models.PlayerRankImpact) error {
+	players := make([]models.PlayerRankImpact, 0, len(impacts))
+	for _, impact := range impacts {
+		players = append(players, *impact)
+	}
+	sort.Slice(players, func(a, b int) bool {
+		ia, ib := abs64(players[a].RankChange), abs64(players[b].RankChange)
+		if ia != ib {
+			return ia > ib
+		}
+		if ta, tb := players[a].Overtook+players[a].FellBehind, players[b].Overtook+players[b].FellBehind; ta != tb {
+			return ta > tb
+		}
+		return players[a].UserID < players[b].UserID
+	})
+	if len(players) > explainPlayers {
+		players = players[:explainPlayers]
+		explanation.Truncated = true
+	}
+	if len(players) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(players))
+	for i, player := range players {
+		ids[i] = player.UserID
+	}
+	users, err := s.userRepo.GetCacheUsersByIDs(ids)
+	if err != nil {
+		return err
+	}
+	names := make(map[uint]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Username
+	}
+	for i := range players {
+		players[i].Username = names[players[i].UserID]
+	}
+	explanation.Players = players
+	return nil
+}
+
+func ratingValues(updates []models.ScoreUpdate) []int {
+	values := make([]int, 0, 2*len(updates))
+	for _, update := range updates {
+		values = append(values, update.OldRating, update.NewRating)
+	}
+	return values
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+// RankHistoryService snapshots ranks periodically and serves rank-over-time
+type RankHistoryService interface {
+	Run(ctx context.Context) error
+	Snapshot() error
+	GetRankHistory(userID uint, period time.Duration) ([]models.RankHistory, error)
+}
+
+type rankHistoryService struct {
+	cfg             config.HistoryConfig
+	leaderboardRepo repository.LeaderboardRepository
+	rankHistoryRepo repository.RankHistoryRepository
+}
+
+func NewRankHistoryService(
+	cfg config.HistoryConfig,
+	leaderboardRepo repository.LeaderboardRepository,
+	rankHistoryRepo repository.RankHistoryRepository,
+) RankHistoryService {
+	return &rankHistoryService{
+		cfg:             cfg,
+		leaderboardRepo: leaderboardRepo,
+		rankHistoryRepo: rankHistoryRepo,
+	}
+}
+
+// Run is the scheduled job: one snapshot, then snapshots past retention are
+// pruned
+func (s *rankHistoryService) Run(ctx context.Context) error {
+	err := s.Snapshot()
+	s.prune()
+	return err
+}
+
+// Snapshot records the rank of the top N users plus any tracked users
+func (s *rankHistoryService) Snapshot() error {
+	capturedAt := time.Now()
+
+	top, err := s.leaderboardRepo.GetTopUsers(s.cfg.RankSnapshotTopN)
+	if err != nil {
+		return fmt.Errorf("failed to read top users: %w", err)
+	}
+
+	entries := make([]models.RankHistory, 0, len(top))
+	seen := make(map[uint]bool, len(top))
+	for _, entry := range top {
+		entries = append(entries, models.RankHistory{
+			UserID:     entry.UserID,
+			Rank:       entry.Rank,
+			Rating:     entry.Rating,
+			CapturedAt: capturedAt,
+		})
+		seen[entry.UserID] = true
+	}
+
+	// Users outside the top N whose history has been requested recently
+	tracked, err := s.leaderboardRepo.GetTrackedUsers(capturedAt.Add(-s.cfg.RankTrackedTTL))
+	if err != nil {
+		slog.Warn("⚠️  Failed to read tracked users", "error", err)
+	}
+	extra := make([]uint, 0, len(tracked))
+	for _, userID := range tracked {
+		if !seen[userID] {
+			extra = append(extra, userID)
+		}
+	}
+	board, err := s.leaderboardRepo.GetBoardEntries(extra)
+	if err != nil {
+		slog.Warn("⚠️  Failed to read tracked users' ranks", "error", err)
+	}
+	for _, entry := range board {
+		entries = append(entries, models.RankHistory{
+			UserID:     entry.UserID,
+			Rank:       entry.Rank,
+			Rating:     entry.Rating,
+			CapturedAt: capturedAt,
+		})
+	}
+
+	if err := s.rankHistoryRepo.CreateBatch(entries); err != nil {
+		return fmt.Errorf("failed to store rank snapshot: %w", err)
+	}
+
+	slog.Info("📸 Rank snapshot stored", "users", len(entries))
+	return nil
+}
+
+func (s *rankHistoryService) prune() {
+	if s.cfg.RankRetention <= 0 {
+		return
+	}
+	deleted, err := s.rankHistoryRepo.DeleteOlderThan(time.Now().Add(-s.cfg.RankRetention))
+	if err != nil {
+		slog.Warn("⚠️  Failed to prune rank history", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("🧹 Pruned rank history", "rows", deleted)
+	}
+}
+
+// GetRankHistory returns snapshots for a user within the period and starts
+// tracking the user so future snapshots include them even outside the top N
+func (s *rankHistoryService) GetRankHistory(userID uint, period time.Duration) ([]models.RankHistory, error) {
+	s.track(userID)
+
+	history, err := s.rankHistoryRepo.GetByUserSince(userID, time.Now().Add(-period))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rank history: %w", err)
+	}
+	return history, nil
+}
+
+// track adds a user on the board to the snapshotted users; IDs of users who
+// are not on it are never stored
+func (s *rankHistoryService) track(userID uint) {
+	if s.cfg.RankTrackedMax <= 0 {
+		return
+	}
+	board, err := s.leaderboardRepo.GetBoardEntries([]uint{userID})
+	if err != nil || len(board) == 0 {
+		return
+	}
+	if err := s.leaderboardRepo.TrackUser(userID, s.cfg.RankTrackedMax); err != nil {
+		slog.Warn("⚠️  Failed to track user", "user_id", userID, "error", err)
+	}
+}
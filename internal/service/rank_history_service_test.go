@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type fakeRankHistoryRepo struct {
+	repository.RankHistoryRepository
+	stored []models.RankHistory
+}
+
+func (f *fakeRankHistoryRepo) CreateBatch(entries []models.RankHistory) error {
+	f.stored = append(f.stored, entries...)
+	return nil
+}
+
+func (f *fakeRankHistoryRepo) GetByUserSince(userID uint, since time.Time) ([]models.RankHistory, error) {
+	return nil, nil
+}
+
+// TestRankHistoryTracksOnlyBoardUsers checks that history requests for
+// unknown IDs are not stored, and that tracked users outside the top N are
+// snapshotted
+func TestRankHistoryTracksOnlyBoardUsers(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	board := repository.NewLeaderboardRepository(client)
+	for userID, rating := range map[uint]int{1: 2000, 2: 1800, 3: 1500, 4: 1500} {
+		if err := board.AddUser(userID, rating); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history := &fakeRankHistoryRepo{}
+	s := NewRankHistoryService(config.HistoryConfig{
+		RankSnapshotTopN: 1, RankTrackedMax: 100, RankTrackedTTL: time.Hour,
+	}, board, history)
+
+	for _, userID := range []uint{4, 1, 12345, 999999} {
+		if _, err := s.GetRankHistory(userID, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tracked, err := board.GetTrackedUsers(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 2 {
+		t.Errorf("tracked = %v, want users 1 and 4 only", tracked)
+	}
+
+	if err := s.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[uint]models.RankHistory)
+	for _, entry := range history.stored {
+		got[entry.UserID] = entry
+	}
+	if len(history.stored) != 2 || got[1].Rank != 1 || got[4].Rank != 3 || got[4].Rating != 1500 {
+		t.Errorf("snapshot = %+v, want user 1 (top N) once and user 4 at rank 3", history.stored)
+	}
+}
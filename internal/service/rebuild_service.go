@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+// Job types of admin Redis rebuilds and leaderboard resets
+const (
+	JobTypeRedisRebuild     = "redis_rebuild"
+	JobTypeLeaderboardReset = "leaderboard_reset"
+)
+
+const (
+	// Largest batch a rebuild may ask for
+	MaxRebuildBatch = 50000
+
+	// Bounds how long a crashed rebuild blocks the next one
+	rebuildLockTTL = 10 * time.Minute
+)
+
+// ErrRebuildRunning is returned while another server is rebuilding
+var ErrRebuildRunning = errors.New("a Redis rebuild is already running")
+
+// RebuildResult is the outcome of a rebuild
+type RebuildResult struct {
+	Overwrite  bool    `json:"overwrite"`
+	Users      int     `json:"users"`             // read from PostgreSQL
+	Board      int     `json:"board"`             // written to the public board
+	Shadow     int     `json:"shadow"`            // written to the shadow board
+	Cached     int     `json:"cached"`            // user cache entries written
+	Swapped    bool    `json:"swapped"`           // built under staging keys and swapped in
+	Archive    string  `json:"archive,omitempty"` // Redis key a reset renamed the board to
+	Reset      int64   `json:"reset,omitempty"`   // PostgreSQL ratings changed by a reset
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// RebuildService repopulates the Redis boards and user cache from
+// PostgreSQL, after Redis lost its data or to repair it
+type RebuildService interface {
+	Rebuild(ctx context.Context, overwrite bool, progress *JobProgress) (*RebuildResult, error)
+	Start(actor string, overwrite bool) (*models.Job, error)
+	Reset(ctx context.Context, rating *int, progress *JobProgress) (*RebuildResult, error)
+	StartReset(actor string, rating *int) (*models.Job, error)
+	EnsureWarm() error
+}
+
+type rebuildService struct {
+	cfg             config.RebuildConfig
+	userRepo        repository.UserRepository
+	leaderboardRepo repository.LeaderboardRepository
+	jobSvc          JobService
+	bus             *eventbus.Bus
+}
+
+// NewRebuildService builds the rebuilder; jobSvc may be nil when rebuilds
+// only run in the foreground (the rebuild command), and bus when resets
+// need not be announced
+func NewRebuildService(
+	cfg config.RebuildConfig,
+	userRepo repository.UserRepository,
+	leaderboardRepo repository.LeaderboardRepository,
+	jobSvc JobService,
+	bus *eventbus.Bus,
+) RebuildService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 5000
+	}
+	if cfg.BatchSize > MaxRebuildBatch {
+		cfg.BatchSize = MaxRebuildBatch
+	}
+	return &rebuildService{
+		cfg:             cfg,
+		userRepo:        userRepo,
+		leaderboardRepo: leaderboardRepo,
+		jobSvc:          jobSvc,
+		bus:             bus,
+	}
+}
+
+// Rebuild writes every user onto the board matching their status and into
+// the user cache. Without overwrite only missing entries are added, so it
+// is safe while updates are being served; with it, Redis ratings and cache
+// entries are replaced by PostgreSQL's.
+func (s *rebuildService) Rebuild(ctx context.Context, overwrite bool, progress *JobProgress) (*RebuildResult, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return s.rebuild(ctx, overwrite, progress, nil)
+}
+
+// Start runs a rebuild as an admin job
+func (s *rebuildService) Start(actor string, overwrite bool) (*models.Job, error) {
+	params := map[string]interface{}{"overwrite": overwrite}
+	return s.jobSvc.Submit(JobTypeRedisRebuild, actor, params, func(ctx context.Context, progress *JobProgress) (interface{}, error) {
+		return s.Rebuild(ctx, overwrite, progress)
+	})
+}
+
+// Reset archives the global board (RENAME to leaderboard:archive:<time>),
+// sets every user's rating in PostgreSQL when rating is given, re-seeds
+// Redis from PostgreSQL and announces a leaderboard_reset event. With
+// swap the board is archived just before the staged one replaces it, so
+// readers never see it empty. DB sync events still queued would write
+// older ratings back, so the caller checks that the backlog is empty first.
+func (s *rebuildService) Reset(ctx context.Context, rating *int, progress *JobProgress) (*RebuildResult, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var changed int64
+	if rating != nil {
+		if changed, err = s.userRepo.ResetRatings(*rating); err != nil {
+			return nil, err
+		}
+		slog.Info("🧹 Leaderboard reset in PostgreSQL", "changed", changed, "rating", *rating)
+	}
+
+	var archive string
+	result, err := s.rebuild(ctx, true, progress, func() error {
+		key, err := s.leaderboardRepo.ArchiveBoard(time.Now(), s.cfg.ArchiveTTL)
+		if err != nil {
+			return fmt.Errorf("failed to archive the leaderboard: %w", err)
+		}
+		archive = key
+		slog.Info("🗄️  Leaderboard archived", "archive", archive)
+
+		// Personal bests start over with the new board
+		if err := s.leaderboardRepo.ClearBestRatings(); err != nil {
+			slog.Warn("⚠️  Failed to clear personal bests", "error", err)
+		}
+		return nil
+	})
+	if result != nil {
+		result.Archive = archive
+		result.Reset = changed
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if s.bus != nil {
+		payload := &models.LeaderboardResetPayload{Archive: archive, Rating: rating, Timestamp: time.Now().Unix()}
+		if err := s.bus.Publish(models.EventLeaderboardReset, payload); err != nil {
+			slog.Warn("⚠️  Failed to announce leaderboard reset", "error", err)
+		}
+	}
+	return result, nil
+}
+
+// StartReset runs a leaderboard reset as an admin job
+func (s *rebuildService) StartReset(actor string, rating *int) (*models.Job, error) {
+	params := map[string]interface{}{"rating": rating}
+	return s.jobSvc.Submit(JobTypeLeaderboardReset, actor, params, func(ctx context.Context, progress *JobProgress) (interface{}, error) {
+		return s.Reset(ctx, rating, progress)
+	})
+}
+
+// EnsureWarm rebuilds Redis when the server starts against an empty
+// leaderboard while PostgreSQL has users. If another server is already
+// rebuilding it waits (up to the configured time) for it to finish.
+func (s *rebuildService) EnsureWarm() error {
+	deadline := time.Now().Add(s.cfg.Wait)
+	for {
+		unlock, err := s.lock()
+		if errors.Is(err, ErrRebuildRunning) {
+			if time.Now().After(deadline) {
+				slog.Warn("⚠️  Gave up waiting for another server's Redis rebuild, starting anyway")
+				return nil
+			}
+			time.Sleep(time.Second)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		size, err := s.leaderboardRepo.GetLeaderboardSize()
+		if err != nil {
+			return err
+		}
+		users, err := s.userRepo.Count()
+		if err != nil {
+			return err
+		}
+		if size > 0 || users == 0 {
+			return nil
+		}
+
+		slog.Warn("🧊 Leaderboard is empty but PostgreSQL has users: rebuilding Redis", "users", users)
+		_, err = s.rebuild(context.Background(), false, &JobProgress{}, nil)
+		return err
+	}
+}
+
+func (s *rebuildService) lock() (func(), error) {
+	token := newID()
+	acquired, err := s.leaderboardRepo.LockRebuild(token, rebuildLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrRebuildRunning
+	}
+	return func() {
+		if err := s.leaderboardRepo.UnlockRebuild(token); err != nil {
+			slog.Warn("⚠️  Failed to release the rebuild lock", "error", err)
+		}
+	}, nil
+}
+
+// rebuild pages through users by ID; the caller holds the lock. With
+// overwrite and Swap the boards are built under staging keys and renamed
+// over the live ones at the end, so readers never see a partial board
+// (updates served meanwhile are replaced too). beforeSwap, if set, runs
+// right before the boards change: before the swap, or else before the
+// first page.
+func (s *rebuildService) rebuild(ctx context.Context, overwrite bool, progress *JobProgress, beforeSwap func() error) (*RebuildResult, error) {
+	started := time.Now()
+	result := &RebuildResult{Overwrite: overwrite, Swapped: overwrite && s.cfg.Swap}
+
+	var token string
+	if result.Swapped {
+		token = newID()
+		defer func() {
+			// No-op once swapped; otherwise the abandoned staging boards
+			if err := s.leaderboardRepo.DropStaged(token, database.LeaderboardKey, database.ShadowBoardKey, database.UsernameIndexKey); err != nil {
+				slog.Warn("⚠️  Failed to drop staged boards", "error", err)
+			}
+		}()
+	} else if beforeSwap != nil {
+		if err := beforeSwap(); err != nil {
+			return result, err
+		}
+	}
+
+	if total, err := s.userRepo.Count(); err == nil {
+		progress.SetTotal(total)
+	}
+
+	var afterID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		users, err := s.userRepo.GetCachePage(afterID, s.cfg.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		var counts *repository.RestoreCounts
+		if result.Swapped {
+			counts, err = s.leaderboardRepo.StageUsers(token, users)
+		} else {
+			counts, err = s.leaderboardRepo.RestoreUsers(users, overwrite)
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Users += len(users)
+		result.Board += counts.Board
+		result.Shadow += counts.Shadow
+		result.Cached += counts.Cached
+		progress.Add(int64(len(users)))
+
+		afterID = users[len(users)-1].ID
+	}
+
+	if result.Swapped {
+		if beforeSwap != nil {
+			if err := beforeSwap(); err != nil {
+				return result, err
+			}
+		}
+		if err := s.leaderboardRepo.SwapStaged(token, database.LeaderboardKey, database.ShadowBoardKey, database.UsernameIndexKey); err != nil {
+			return result, fmt.Errorf("failed to swap in the rebuilt boards: %w", err)
+		}
+	}
+
+	result.DurationMs = msSince(started)
+	slog.Info("🧱 Redis rebuilt from PostgreSQL", "users", result.Users, "board", result.Board,
+		"shadow", result.Shadow, "cached", result.Cached, "duration_ms", result.DurationMs)
+	return result, nil
+}
@@ -0,0 +1,522 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+// JobTypeReconcile is the job type of admin reconcile runs
+const JobTypeReconcile = "reconcile"
+
+const (
+	// Largest sample or page a run may use
+	MaxReconcileBatch = 50000
+
+	// Bounds how long a crashed run blocks the next one
+	reconcileLockTTL = 30 * time.Minute
+	reconcileSamples = 20
+)
+
+var (
+	// ErrReconcileRunning is returned while another server is reconciling
+	ErrReconcileRunning = errors.New("a reconcile run is already in progress")
+	// ErrInvalidReconcile is returned for an unknown mode or source
+	ErrInvalidReconcile = newValidationError("invalid reconcile options")
+)
+
+var (
+	reconcileRuns = metrics.NewCounterVec("reconcile_runs_total",
+		"Reconciler runs by outcome", "result")
+	reconcileMismatches = metrics.NewGaugeVec("reconcile_mismatches",
+		"Users drifted between Redis and PostgreSQL in the last reconcile run, by kind", "kind")
+	reconcileRepaired = metrics.NewCounterVec("reconcile_repaired_total",
+		"Drifted users repaired by the reconciler, by kind", "kind")
+	reconcileChecked = metrics.NewGauge("reconcile_checked_users",
+		"Users compared by the last reconcile run")
+	reconcileLastRun = metrics.NewGauge("reconcile_last_run_timestamp_seconds",
+		"Unix time of the last completed reconcile run")
+)
+
+// ReconcileOptions override the configured mode and source of one run
+type ReconcileOptions struct {
+	Mode   string `json:"mode"`
+	Source string `json:"source"`
+}
+
+// ReconcileService compares PostgreSQL users with the Redis boards, by
+// sample or full scan, and repairs the drift it confirms
+type ReconcileService interface {
+	Run(ctx context.Context) error
+	Reconcile(ctx context.Context, opts ReconcileOptions, trigger string, progress *JobProgress) (*models.ReconcileReport, error)
+	Submit(actor string, opts ReconcileOptions) (*models.Job, error)
+	LastReport() (*models.ReconcileReport, error)
+}
+
+// observed is one user as read from both sides (nil = missing)
+type observed struct {
+	score *int
+	user  *models.User
+}
+
+type reconcileService struct {
+	cfg             config.ReconcileConfig
+	node            string
+	userRepo        repository.UserRepository
+	leaderboardRepo repository.LeaderboardRepository
+	dbSyncService   DBSyncService
+	jobSvc          JobService
+}
+
+// NewReconcileService builds the reconciler; jobSvc may be nil when only
+// scheduled runs are used (the sandbox)
+func NewReconcileService(
+	cfg config.ReconcileConfig,
+	node string,
+	userRepo repository.UserRepository,
+	leaderboardRepo repository.LeaderboardRepository,
+	dbSyncService DBSyncService,
+	jobSvc JobService,
+) ReconcileService {
+	if cfg.SampleSize <= 0 {
+		cfg.SampleSize = 1000
+	}
+	if cfg.SampleSize > MaxReconcileBatch {
+		cfg.SampleSize = MaxReconcileBatch
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	if cfg.BatchSize > MaxReconcileBatch {
+		cfg.BatchSize = MaxReconcileBatch
+	}
+	return &reconcileService{
+		cfg:             cfg,
+		node:            node,
+		userRepo:        userRepo,
+		leaderboardRepo: leaderboardRepo,
+		dbSyncService:   dbSyncService,
+		jobSvc:          jobSvc,
+	}
+}
+
+// Run is the scheduled job: one run with the configured mode and source
+func (s *reconcileService) Run(ctx context.Context) error {
+	_, err := s.Reconcile(ctx, ReconcileOptions{}, "schedule", &JobProgress{})
+	return err
+}
+
+// Submit runs a reconcile as an admin job
+func (s *reconcileService) Submit(actor string, opts ReconcileOptions) (*models.Job, error) {
+	opts, err := s.options(opts)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{"mode": opts.Mode, "source": opts.Source}
+	return s.jobSvc.Submit(JobTypeReconcile, actor, params, func(ctx context.Context, progress *JobProgress) (interface{}, error) {
+		return s.Reconcile(ctx, opts, actor, progress)
+	})
+}
+
+// LastReport returns the latest run of any server
+func (s *reconcileService) LastReport() (*models.ReconcileReport, error) {
+	return s.leaderboardRepo.GetReconcileReport()
+}
+
+// options fills in the configured mode and source and validates them
+func (s *reconcileService) options(opts ReconcileOptions) (ReconcileOptions, error) {
+	if opts.Mode == "" {
+		opts.Mode = s.cfg.Mode
+	}
+	if opts.Source == "" {
+		opts.Source = s.cfg.Source
+	}
+	switch opts.Mode {
+	case config.ReconcileSample, config.ReconcileFull:
+	default:
+		return opts, fmt.Errorf("%w: mode must be sample or full", ErrInvalidReconcile)
+	}
+	switch opts.Source {
+	case config.ReconcileFromRedis, config.ReconcileFromPostgres, config.ReconcileReportOnly:
+	default:
+		return opts, fmt.Errorf("%w: source must be redis, postgres or none", ErrInvalidReconcile)
+	}
+	return opts, nil
+}
+
+// Reconcile reads users from both sides, re-checks the ones that differ
+// after the settle delay (only users unchanged on both sides count as
+// drift) and repairs them from the chosen source
+func (s *reconcileService) Reconcile(ctx context.Context, opts ReconcileOptions, trigger string, progress *JobProgress) (*models.ReconcileReport, error) {
+	opts, err := s.options(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	started := time.Now()
+	report := &models.ReconcileReport{
+		Node:       s.node,
+		Trigger:    trigger,
+		Mode:       opts.Mode,
+		Source:     opts.Source,
+		StartedAt:  started.UTC(),
+		Mismatches: make(map[string]int),
+	}
+	defer s.finish(report, started)
+
+	var candidates map[uint]observed
+	if opts.Mode == config.ReconcileFull {
+		candidates, err = s.scanFull(ctx, report, progress)
+	} else {
+		candidates, err = s.scanSample(report, progress)
+	}
+	if err == nil && len(candidates) > 0 {
+		err = s.settle(ctx, report, candidates, opts.Source)
+	}
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+	return report, nil
+}
+
+func (s *reconcileService) lock() (func(), error) {
+	token := newID()
+	acquired, err := s.leaderboardRepo.LockReconcile(token, reconcileLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrReconcileRunning
+	}
+	return func() {
+		if err := s.leaderboardRepo.UnlockReconcile(token); err != nil {
+			slog.Warn("⚠️  Failed to release the reconcile lock", "error", err)
+		}
+	}, nil
+}
+
+// finish records the run's metrics and shares its report
+func (s *reconcileService) finish(report *models.ReconcileReport, started time.Time) {
+	report.Duration = time.Since(started).Round(time.Millisecond).String()
+	if err := s.leaderboardRepo.SaveReconcileReport(report); err != nil {
+		slog.Warn("⚠️  Failed to store reconcile report", "error", err)
+	}
+
+	reconcileChecked.Set(float64(report.Checked))
+	reconcileLastRun.Set(float64(time.Now().Unix()))
+	total := 0
+	for _, kind := range []string{models.DriftRating, models.DriftMissingRedis, models.DriftBannedOnBoard, models.DriftOrphan} {
+		reconcileMismatches.WithLabelValues(kind).Set(float64(report.Mismatches[kind]))
+		total += report.Mismatches[kind]
+	}
+
+	switch {
+	case report.Error != "":
+		reconcileRuns.WithLabelValues("error").Inc()
+	case total > 0:
+		reconcileRuns.WithLabelValues("mismatch").Inc()
+		slog.Warn("🔧 Reconcile found drift", "mode", report.Mode, "drifted", total, "checked", report.Checked,
+			"repaired", report.Repaired, "failed", report.Failed, "in_flight", report.InFlight)
+	default:
+		reconcileRuns.WithLabelValues("ok").Inc()
+	}
+}
+
+// scanFull compares every PostgreSQL user with a snapshot of both boards;
+// board members left over once all users are read are orphans
+func (s *reconcileService) scanFull(ctx context.Context, report *models.ReconcileReport, progress *JobProgress) (map[uint]observed, error) {
+	scores, err := s.leaderboardRepo.GetAllScores(int64(s.cfg.BatchSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sorted sets: %w", err)
+	}
+	if total, err := s.userRepo.Count(); err == nil {
+		progress.SetTotal(total)
+	}
+
+	candidates := make(map[uint]observed)
+	var afterID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		users, err := s.userRepo.GetCachePage(afterID, s.cfg.BatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read users: %w", err)
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		for i := range users {
+			user := &users[i]
+			obs := observed{user: user}
+			if score, ok := scores[user.ID]; ok {
+				obs.score = &score
+				delete(scores, user.ID)
+			}
+			if driftOf(obs) != "" {
+				candidates[user.ID] = obs
+			}
+		}
+		report.Checked += len(users)
+		progress.Add(int64(len(users)))
+		afterID = users[len(users)-1].ID
+	}
+
+	for userID, score := range scores {
+		score := score
+		candidates[userID] = observed{score: &score}
+		report.Checked++
+	}
+	return candidates, nil
+}
+
+// scanSample compares a block of consecutive users starting at a random ID,
+// plus random members of both boards (the only way to find orphans)
+func (s *reconcileService) scanSample(report *models.ReconcileReport, progress *JobProgress) (map[uint]observed, error) {
+	minID, maxID, err := s.userRepo.GetIDRange()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user IDs: %w", err)
+	}
+
+	var users []models.User
+	if maxID > 0 {
+		afterID := minID - 1 + uint(rand.Int63n(int64(maxID-minID)+1))
+		if users, err = s.userRepo.GetCachePage(afterID, s.cfg.SampleSize); err != nil {
+			return nil, fmt.Errorf("failed to read users: %w", err)
+		}
+		// Near the end of the ID range: wrap around to the start
+		if missing := s.cfg.SampleSize - len(users); missing > 0 && afterID >= minID {
+			more, err := s.userRepo.GetCachePage(0, missing)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read users: %w", err)
+			}
+			for _, user := range more {
+				if user.ID > afterID {
+					break
+				}
+				users = append(users, user)
+			}
+		}
+	}
+
+	sampled, err := s.leaderboardRepo.SampleBoardUsers(s.cfg.SampleSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample sorted sets: %w", err)
+	}
+	seen := make(map[uint]bool, len(users))
+	for _, user := range users {
+		seen[user.ID] = true
+	}
+	var boardOnly []uint
+	for _, userID := range sampled {
+		if !seen[userID] {
+			seen[userID] = true
+			boardOnly = append(boardOnly, userID)
+		}
+	}
+	if len(boardOnly) > 0 {
+		more, err := s.userRepo.GetCacheUsersByIDs(boardOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read users: %w", err)
+		}
+		users = append(users, more...)
+	}
+
+	ids := make([]uint, 0, len(seen))
+	for userID := range seen {
+		ids = append(ids, userID)
+	}
+	observations, err := s.observe(ids, users)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[uint]observed)
+	for userID, obs := range observations {
+		if driftOf(obs) != "" {
+			candidates[userID] = obs
+		}
+	}
+	report.Checked = len(ids)
+	progress.SetTotal(int64(len(ids)))
+	progress.Add(int64(len(ids)))
+	return candidates, nil
+}
+
+// observe reads the Redis scores of ids and pairs them with users
+func (s *reconcileService) observe(ids []uint, users []models.User) (map[uint]observed, error) {
+	scores, err := s.leaderboardRepo.GetScores(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sorted sets: %w", err)
+	}
+
+	observations := make(map[uint]observed, len(ids))
+	for _, userID := range ids {
+		var obs observed
+		if score, ok := scores[userID]; ok {
+			obs.score = &score
+		}
+		observations[userID] = obs
+	}
+	for i := range users {
+		obs := observations[users[i].ID]
+		obs.user = &users[i]
+		observations[users[i].ID] = obs
+	}
+	return observations, nil
+}
+
+// settle re-reads the candidates after the settle delay and repairs those
+// that did not move in between
+func (s *reconcileService) settle(ctx context.Context, report *models.ReconcileReport, candidates map[uint]observed, source string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(s.cfg.Settle):
+	}
+
+	ids := make([]uint, 0, len(candidates))
+	for userID := range candidates {
+		ids = append(ids, userID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// Writing PostgreSQL's rating over Redis's is only safe once every
+	// accepted update has reached PostgreSQL
+	holdRatings := false
+	if source == config.ReconcileFromPostgres {
+		depth, err := s.dbSyncService.QueueDepth()
+		if err != nil || depth > 0 {
+			slog.Info("⏳ DB sync backlog not known to be empty: rating drift is reported, not repaired", "backlog", depth, "error", err)
+			holdRatings = true
+		}
+	}
+
+	for start := 0; start < len(ids); start += s.cfg.BatchSize {
+		end := start + s.cfg.BatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+
+		users, err := s.userRepo.GetCacheUsersByIDs(batch)
+		if err != nil {
+			return fmt.Errorf("failed to re-read users: %w", err)
+		}
+		now, err := s.observe(batch, users)
+		if err != nil {
+			return err
+		}
+
+		for _, userID := range batch {
+			before, after := candidates[userID], now[userID]
+			if !sameObservation(before, after) {
+				report.InFlight++
+				continue
+			}
+			s.repair(report, userID, after, source, holdRatings)
+		}
+	}
+	return nil
+}
+
+// repair fixes one confirmed drift under the user's update lock, so it
+// never races a score update. holdRatings leaves rating drift as it is.
+func (s *reconcileService) repair(report *models.ReconcileReport, userID uint, obs observed, source string, holdRatings bool) {
+	token := newID()
+	acquired, err := s.leaderboardRepo.LockUser(userID, token, userLockTTL)
+	if err != nil || !acquired {
+		report.InFlight++
+		return
+	}
+	defer func() {
+		if err := s.leaderboardRepo.UnlockUser(userID, token); err != nil {
+			slog.Warn("⚠️  Failed to unlock user", "user_id", userID, "error", err)
+		}
+	}()
+
+	kind := driftOf(obs)
+	report.Mismatches[kind]++
+	mismatch := models.ReconcileMismatch{UserID: userID, Kind: kind, Redis: obs.score}
+	if obs.user != nil {
+		rating := obs.user.Rating
+		mismatch.Postgres = &rating
+	}
+
+	switch {
+	case source == config.ReconcileReportOnly:
+	case kind == models.DriftRating && holdRatings:
+	case kind == models.DriftOrphan:
+		err = s.leaderboardRepo.RemoveUser(userID)
+		mismatch.Repaired = true
+	case kind == models.DriftRating && source == config.ReconcileFromRedis:
+		err = s.userRepo.UpdateRating(userID, *obs.score)
+		mismatch.Repaired = true
+	default:
+		// Redis side rewritten from PostgreSQL: rating, board and cache
+		_, err = s.leaderboardRepo.RestoreUsers([]models.User{*obs.user}, true)
+		mismatch.Repaired = true
+	}
+
+	if err != nil {
+		mismatch.Repaired = false
+		report.Failed++
+		slog.Warn("⚠️  Failed to repair drift", "kind", kind, "user_id", userID, "error", err)
+	} else if mismatch.Repaired {
+		report.Repaired++
+		reconcileRepaired.WithLabelValues(kind).Inc()
+	}
+	if len(report.Samples) < reconcileSamples {
+		report.Samples = append(report.Samples, mismatch)
+	}
+}
+
+// driftOf classifies an observation; "" when both sides agree
+func driftOf(obs observed) string {
+	switch {
+	case obs.user == nil && obs.score == nil:
+		return ""
+	case obs.user == nil:
+		return models.DriftOrphan
+	case obs.user.Status == models.UserStatusBanned:
+		if obs.score != nil {
+			return models.DriftBannedOnBoard
+		}
+		return ""
+	case obs.score == nil:
+		return models.DriftMissingRedis
+	case *obs.score != obs.user.Rating:
+		return models.DriftRating
+	}
+	return ""
+}
+
+func sameObservation(a, b observed) bool {
+	if (a.score == nil) != (b.score == nil) || (a.user == nil) != (b.user == nil) {
+		return false
+	}
+	if a.score != nil && *a.score != *b.score {
+		return false
+	}
+	if a.user != nil && (a.user.Rating != b.user.Rating || a.user.Status != b.user.Status) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+var redisDegraded = metrics.NewGaugeVec("redis_degraded",
+	"1 while Redis is unreachable and the API serves from PostgreSQL", "tenant")
+
+// RedisHealth pings Redis and reports when it has been unreachable for
+// several checks in a row, so the leaderboard can fall back to PostgreSQL
+type RedisHealth interface {
+	Start()
+	Stop()
+	Down() bool
+}
+
+type redisHealth struct {
+	cfg    config.FallbackConfig
+	redis  *redis.Client
+	tenant string
+
+	down     atomic.Bool
+	failures int
+	stopCh   chan struct{}
+	once     sync.Once
+}
+
+func NewRedisHealth(cfg config.FallbackConfig, redisClient *redis.Client, tenant string) RedisHealth {
+	return &redisHealth{
+		cfg:    cfg,
+		redis:  redisClient,
+		tenant: tenant,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start runs the health checks; with the fallback disabled Redis is never
+// reported down
+func (h *redisHealth) Start() {
+	if !h.cfg.Enabled {
+		return
+	}
+	redisDegraded.WithLabelValues(h.tenant).Set(0)
+
+	go func() {
+		ticker := time.NewTicker(h.cfg.CheckEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				h.check()
+			case <-h.stopCh:
+				return
+			}
+		}
+	}()
+	slog.Info("🩺 Redis health checks started", "tenant", h.tenant, "interval", h.cfg.CheckEvery)
+}
+
+func (h *redisHealth) Stop() {
+	h.once.Do(func() { close(h.stopCh) })
+}
+
+// Down reports whether Redis is considered unreachable
+func (h *redisHealth) Down() bool {
+	return h.down.Load()
+}
+
+func (h *redisHealth) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), h.cfg.CheckEvery)
+	defer cancel()
+
+	if err := h.redis.Ping(ctx).Err(); err != nil {
+		h.failures++
+		if h.failures >= h.cfg.FailAfter && !h.down.Swap(true) {
+			redisDegraded.WithLabelValues(h.tenant).Set(1)
+			slog.Error("🚨 Redis unreachable: serving from PostgreSQL, queueing score updates", "tenant", h.tenant, "error", err)
+		}
+		return
+	}
+
+	h.failures = 0
+	if h.down.Swap(false) {
+		redisDegraded.WithLabelValues(h.tenant).Set(0)
+		slog.Info("✅ Redis reachable again: leaving degraded mode", "tenant", h.tenant)
+	}
+}
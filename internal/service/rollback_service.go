@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+var ErrNoProtection = errors.New("no protection mark is set")
+
+// RevertRequest asks to put users back to the rating they had at To
+type RevertRequest struct {
+	To       time.Time
+	UserIDs  []uint
+	Override bool // allow going below the protection floor
+	Reason   string
+}
+
+// RollbackService reverts ratings during incident rollback, guarded by an
+// optional point-in-time protection mark: while a mark is set, a revert
+// never takes a user below the rating they had at the mark unless the
+// caller explicitly overrides it.
+type RollbackService interface {
+	Mark(actor, reason string) (*models.ProtectionMark, error)
+	GetMark() (*models.ProtectionMark, error)
+	ClearMark(actor string) error
+	Revert(ctx context.Context, actor string, req RevertRequest) ([]models.RevertResult, error)
+}
+
+type rollbackService struct {
+	protectionRepo  repository.ProtectionRepository
+	scoreUpdateRepo repository.ScoreUpdateRepository
+	leaderboardSvc  LeaderboardService
+	auditSvc        AuditService
+}
+
+func NewRollbackService(
+	protectionRepo repository.ProtectionRepository,
+	scoreUpdateRepo repository.ScoreUpdateRepository,
+	leaderboardSvc LeaderboardService,
+	auditSvc AuditService,
+) RollbackService {
+	return &rollbackService{
+		protectionRepo:  protectionRepo,
+		scoreUpdateRepo: scoreUpdateRepo,
+		leaderboardSvc:  leaderboardSvc,
+		auditSvc:        auditSvc,
+	}
+}
+
+// Mark snapshots every current rating as the floor for later reverts
+func (s *rollbackService) Mark(actor, reason string) (*models.ProtectionMark, error) {
+	mark := &models.ProtectionMark{
+		MarkedAt: time.Now().UTC(),
+		Actor:    actor,
+		Reason:   reason,
+	}
+	if err := s.protectionRepo.Mark(mark); err != nil {
+		return nil, fmt.Errorf("failed to set protection mark: %w", err)
+	}
+
+	slog.Info("🛡️  Rollback protection marked", "actor", actor, "ratings", mark.Users)
+	return mark, nil
+}
+
+func (s *rollbackService) GetMark() (*models.ProtectionMark, error) {
+	return s.protectionRepo.GetMark()
+}
+
+func (s *rollbackService) ClearMark(actor string) error {
+	mark, err := s.protectionRepo.GetMark()
+	if err != nil {
+		return err
+	}
+	if mark == nil {
+		return ErrNoProtection
+	}
+	if err := s.protectionRepo.Clear(); err != nil {
+		return err
+	}
+
+	slog.Info("🛡️  Rollback protection cleared", "marked_at", mark.MarkedAt, "actor", actor)
+	return nil
+}
+
+// Revert restores each user's rating as of req.To, taken from their raw
+// score history. Every applied revert is audited; the note says whether
+// the protection floor held it up or was overridden.
+func (s *rollbackService) Revert(ctx context.Context, actor string, req RevertRequest) ([]models.RevertResult, error) {
+	mark, err := s.protectionRepo.GetMark()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read protection mark: %w", err)
+	}
+
+	results := make([]models.RevertResult, len(req.UserIDs))
+	for i, userID := range req.UserIDs {
+		results[i] = s.revertUser(ctx, actor, userID, mark, req)
+	}
+	return results, nil
+}
+
+func (s *rollbackService) revertUser(ctx context.Context, actor string, userID uint, mark *models.ProtectionMark, req RevertRequest) models.RevertResult {
+	result := models.RevertResult{UserID: userID}
+
+	// The first change after To started from the rating at To
+	updates, err := s.scoreUpdateRepo.GetByUserSince(userID, req.To)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if len(updates) == 0 {
+		result.Skipped = "no score changes since the revert time"
+		return result
+	}
+	result.Target = updates[0].OldRating
+	target := result.Target
+
+	// Guard: never below the rating held at the protection mark
+	if mark != nil {
+		floor, ok, err := s.protectionRepo.GetFloor(userID)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to read protection floor: %v", err)
+			return result
+		}
+		if ok && target < floor {
+			result.Floor = floor
+			if req.Override {
+				result.Override = true
+			} else {
+				result.Held = true
+				target = floor
+			}
+		}
+	}
+
+	payload, err := s.leaderboardSvc.RestoreUserScore(ctx, userID, target)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Update = payload
+	if payload.RatingDelta == 0 {
+		return result
+	}
+
+	reason := req.Reason
+	switch {
+	case result.Held:
+		reason = auditNote(reason, fmt.Sprintf("held at protection floor %d (target %d)", result.Floor, result.Target))
+		slog.Info("🛡️  Revert held at floor", "user_id", userID, "actor", actor, "floor", result.Floor, "target", result.Target)
+	case result.Override:
+		reason = auditNote(reason, fmt.Sprintf("protection floor %d overridden", result.Floor))
+		slog.Info("🛡️  Revert overrode floor", "user_id", userID, "actor", actor, "floor", result.Floor, "rating", target)
+	}
+	if err := s.auditSvc.RecordAdjustments(actor, reason, AdjustmentRevert,
+		[]*models.ScoreUpdatePayload{payload}); err != nil {
+		slog.Warn("⚠️  Failed to audit revert", "user_id", userID, "actor", actor, "error", err)
+	}
+	return result
+}
+
+// auditNote appends a note to an audit reason, within the column size
+func auditNote(reason, note string) string {
+	note = "[" + note + "]"
+	if reason == "" {
+		return note
+	}
+	if room := 500 - len(note) - 1; len(reason) > room {
+		reason = reason[:room]
+	}
+	return reason + " " + note
+}
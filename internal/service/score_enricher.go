@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/requestid"
+)
+
+var (
+	enrichLatency = metrics.NewHistogram("score_enrich_seconds",
+		"Time from applying a fast-mode score update to publishing it with its ranks", nil)
+	enrichInline = metrics.NewCounter("score_enrich_inline_total",
+		"Fast-mode score updates enriched on the request because the queue was full")
+)
+
+// ScoreEnricher computes the old and new rank of fast-mode score updates
+// after the caller has been answered, then publishes them like any update
+// (DB sync, WebSocket broadcast) and hands them to anti-cheat and the
+// milestone detector
+type ScoreEnricher interface {
+	Start()
+	Stop()
+	// Enrich queues an applied update; added means the user was not on
+	// the board before it, so there is no old rank. The update is
+	// published with the request ID ctx carries.
+	Enrich(ctx context.Context, payload models.ScoreUpdatePayload, added bool)
+}
+
+type enrichTask struct {
+	payload   models.ScoreUpdatePayload
+	added     bool
+	applied   time.Time
+	requestID string
+}
+
+type scoreEnricher struct {
+	leaderboardRepo repository.LeaderboardRepository
+	bus             *eventbus.Bus
+	inspector       ScoreInspector
+	milestones      MilestoneDetector
+	achievements    AchievementService
+
+	mu      sync.RWMutex
+	queues  []chan enrichTask
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func NewScoreEnricher(
+	cfg config.IngestConfig,
+	leaderboardRepo repository.LeaderboardRepository,
+	bus *eventbus.Bus,
+	inspector ScoreInspector,
+	milestones MilestoneDetector,
+	achievements AchievementService,
+) ScoreEnricher {
+	workers := cfg.EnrichWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	queueSize := cfg.EnrichQueue
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	queues := make([]chan enrichTask, workers)
+	for i := range queues {
+		queues[i] = make(chan enrichTask, queueSize)
+	}
+	return &scoreEnricher{
+		leaderboardRepo: leaderboardRepo,
+		bus:             bus,
+		inspector:       inspector,
+		milestones:      milestones,
+		achievements:    achievements,
+		queues:          queues,
+	}
+}
+
+// Start runs one worker per queue
+func (e *scoreEnricher) Start() {
+	for _, queue := range e.queues {
+		e.wg.Add(1)
+		go func(queue chan enrichTask) {
+			defer e.wg.Done()
+			for task := range queue {
+				e.enrich(task)
+			}
+		}(queue)
+	}
+}
+
+// Stop waits for queued updates to be published, so they still reach the
+// DB sync; later updates are enriched inline
+func (e *scoreEnricher) Stop() {
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return
+	}
+	e.stopped = true
+	for _, queue := range e.queues {
+		close(queue)
+	}
+	e.mu.Unlock()
+
+	e.wg.Wait()
+}
+
+func (e *scoreEnricher) Enrich(ctx context.Context, payload models.ScoreUpdatePayload, added bool) {
+	task := enrichTask{payload: payload, added: added, applied: time.Now(), requestID: requestid.FromContext(ctx)}
+
+	e.mu.RLock()
+	if !e.stopped {
+		select {
+		case e.queues[payload.UserID%uint(len(e.queues))] <- task:
+			e.mu.RUnlock()
+			return
+		default:
+		}
+	}
+	e.mu.RUnlock()
+
+	enrichInline.Inc()
+	e.enrich(task)
+}
+
+// enrich ranks the update's old and new rating on the board as it is now
+// (tie-aware, like GetUserRank) and publishes it
+func (e *scoreEnricher) enrich(task enrichTask) {
+	payload := &task.payload
+	ctx := requestid.NewContext(context.Background(), task.requestID)
+
+	if newAbove, err := e.leaderboardRepo.CountAbove(payload.NewRating); err == nil {
+		payload.NewRank = newAbove + 1
+	} else {
+		slog.Warn("⚠️  Failed to rank fast score update", "user_id", payload.UserID, "error", err)
+	}
+	if !task.added {
+		if oldAbove, err := e.leaderboardRepo.CountAbove(payload.OldRating); err == nil {
+			payload.OldRank = oldAbove + 1
+			// The user now sits above their old rating: don't count them
+			if payload.NewRating > payload.OldRating {
+				payload.OldRank--
+			}
+		}
+	}
+	payload.RankDelta = payload.OldRank - payload.NewRank
+
+	if err := e.bus.PublishContext(ctx, models.EventScoreUpdate, payload); err != nil {
+		slog.Warn("⚠️  Failed to publish score update", "error", err)
+	}
+	if e.inspector != nil {
+		e.inspector.Inspect(payload)
+	}
+	if e.milestones != nil {
+		e.milestones.Detect(payload)
+	}
+	if e.achievements != nil {
+		e.achievements.Evaluate(payload)
+	}
+	enrichLatency.Observe(time.Since(task.applied).Seconds())
+
+	slog.Debug("Updated user score (fast)", "user_id", payload.UserID, "username", payload.Username,
+		"old_rating", payload.OldRating, "new_rating", payload.NewRating, "rank", payload.NewRank)
+}
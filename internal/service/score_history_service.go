@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+// ScoreHistoryService compacts old raw score updates into daily aggregates
+// and serves a stitched history (aggregates for old days, raw rows after)
+type ScoreHistoryService interface {
+	Compact(ctx context.Context) (int64, error)
+	GetHistory(userID uint, period time.Duration) ([]models.HistoryEntry, error)
+}
+
+type scoreHistoryService struct {
+	cfg             config.HistoryConfig
+	scoreUpdateRepo repository.ScoreUpdateRepository
+}
+
+func NewScoreHistoryService(
+	cfg config.HistoryConfig,
+	scoreUpdateRepo repository.ScoreUpdateRepository,
+) ScoreHistoryService {
+	return &scoreHistoryService{
+		cfg:             cfg,
+		scoreUpdateRepo: scoreUpdateRepo,
+	}
+}
+
+// compactionCutoff is the start of the first UTC day that stays raw
+func (s *scoreHistoryService) compactionCutoff() time.Time {
+	return time.Now().UTC().Add(-s.cfg.CompactAfter).Truncate(24 * time.Hour)
+}
+
+// Compact rolls whole UTC days older than the cutoff, one day per
+// transaction, until done or ctx is cancelled
+func (s *scoreHistoryService) Compact(ctx context.Context) (int64, error) {
+	if s.cfg.CompactAfter <= 0 {
+		return 0, nil
+	}
+
+	cutoff := s.compactionCutoff()
+	var total int64
+
+	for {
+		oldest, err := s.scoreUpdateRepo.OldestBefore(cutoff)
+		if err != nil {
+			return total, err
+		}
+		if oldest == nil {
+			break
+		}
+
+		deleted, err := s.scoreUpdateRepo.CompactDay(*oldest)
+		if err != nil {
+			return total, fmt.Errorf("failed to compact %s: %w", oldest.Format("2006-01-02"), err)
+		}
+		total += deleted
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("🗜️  Compacted raw score updates into daily aggregates", "updates", total)
+	}
+	return total, nil
+}
+
+// GetHistory returns daily aggregates up to the compaction cutoff followed
+// by raw updates, as one chronologically ordered list
+func (s *scoreHistoryService) GetHistory(userID uint, period time.Duration) ([]models.HistoryEntry, error) {
+	since := time.Now().Add(-period)
+
+	days, err := s.scoreUpdateRepo.GetDailyByUserSince(userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily history: %w", err)
+	}
+
+	raw, err := s.scoreUpdateRepo.GetByUserSince(userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get score history: %w", err)
+	}
+
+	entries := make([]models.HistoryEntry, 0, len(days)+len(raw))
+	for _, day := range days {
+		entries = append(entries, models.HistoryEntry{
+			Type:        "daily",
+			Timestamp:   day.Day,
+			OpenRating:  day.OpenRating,
+			CloseRating: day.CloseRating,
+			MinRating:   day.MinRating,
+			MaxRating:   day.MaxRating,
+			UpdateCount: day.UpdateCount,
+		})
+	}
+	for _, update := range raw {
+		entries = append(entries, models.HistoryEntry{
+			Type:      "update",
+			Timestamp: update.UpdatedAt,
+			OldRating: update.OldRating,
+			NewRating: update.NewRating,
+			Change:    update.Change,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+// ScoreModel produces simulated ratings from each user's current rating.
+//
+// walk (default) adds a bounded random delta with an Ornstein-Uhlenbeck
+// pull toward a mean, so a seeded distribution keeps its shape.
+//
+// elo plays one match against an opponent rated near the player, won with
+// a probability set by the player's hidden skill and scored by the Elo
+// expectation from their visible rating. Over time ratings spread out
+// toward the skill distribution.
+type ScoreModel struct {
+	cfg             config.SimulatorConfig
+	leaderboardRepo repository.LeaderboardRepository
+	userRepo        repository.UserRepository
+}
+
+func NewScoreModel(
+	cfg config.SimulatorConfig,
+	leaderboardRepo repository.LeaderboardRepository,
+	userRepo repository.UserRepository,
+) *ScoreModel {
+	if cfg.CurveScale <= 0 {
+		cfg.CurveScale = 400
+	}
+	if cfg.MaxDelta <= 0 {
+		cfg.MaxDelta = 25
+	}
+	return &ScoreModel{
+		cfg:             cfg,
+		leaderboardRepo: leaderboardRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// NextRating simulates one update for the user and returns their new rating
+func (m *ScoreModel) NextRating(userID uint) (int, error) {
+	current, err := m.currentRating(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var newRating int
+	if m.cfg.Model == config.SimModelElo {
+		newRating = current + m.eloDelta(userID, current)
+	} else {
+		newRating = current + m.walkDelta(current)
+	}
+
+	// Ensure within bounds
+	if newRating < 100 {
+		newRating = 100
+	}
+	if newRating > 5000 {
+		newRating = 5000
+	}
+	return newRating, nil
+}
+
+// walkDelta is a uniform step in ±MaxDelta plus the mean-reverting pull
+// θ(mean − rating). For uniform steps of variance σ² = MaxDelta²/3 the
+// ratings' stationary spread is σ/√(θ(2−θ)), so θ is solved for the
+// configured spread.
+func (m *ScoreModel) walkDelta(current int) int {
+	step := float64(m.cfg.MaxDelta)
+	delta := (2*rand.Float64() - 1) * step
+
+	if spread := m.cfg.ReversionSpread; spread > 0 {
+		variance := step * step / 3
+		theta := 1 - math.Sqrt(max(0, 1-variance/(spread*spread)))
+		delta += theta * (m.cfg.ReversionMean - float64(current))
+	}
+
+	delta = max(-step, min(step, delta))
+	return int(math.Round(delta))
+}
+
+// eloDelta plays one simulated match and returns the rating change
+func (m *ScoreModel) eloDelta(userID uint, current int) int {
+	opponent := float64(current) + rand.NormFloat64()*m.cfg.OpponentSpread
+	expected := m.winProbability(float64(current) - opponent)
+	actual := m.winProbability(m.skill(userID) - opponent)
+
+	score := 0.0
+	if rand.Float64() < actual {
+		score = 1
+	}
+	return int(math.Round(float64(m.cfg.KFactor) * (score - expected)))
+}
+
+func (m *ScoreModel) currentRating(userID uint) (int, error) {
+	user, err := m.leaderboardRepo.GetCachedUser(userID)
+	if err != nil {
+		// Fallback to PostgreSQL if not in cache
+		if user, err = m.userRepo.GetByID(userID); err != nil {
+			return 0, fmt.Errorf("user not found: %w", err)
+		}
+	}
+	if user.Status == models.UserStatusBanned {
+		return 0, ErrUserBanned
+	}
+	return user.Rating, nil
+}
+
+// winProbability maps a rating difference to a win chance on the configured curve
+func (m *ScoreModel) winProbability(diff float64) float64 {
+	switch m.cfg.WinCurve {
+	case config.WinCurveFlat:
+		return 0.5
+	case config.WinCurveNormal:
+		return 0.5 * (1 + math.Erf(diff/(m.cfg.CurveScale*math.Sqrt2)))
+	default:
+		return 1 / (1 + math.Pow(10, -diff/m.cfg.CurveScale))
+	}
+}
+
+// skill is the user's hidden true strength: a fixed normal draw seeded by
+// their ID, so it is stable across servers and restarts without storage
+func (m *ScoreModel) skill(userID uint) float64 {
+	if m.cfg.SkillSpread <= 0 {
+		return m.cfg.SkillMean
+	}
+	u1 := float64(splitmix64(uint64(userID))>>11+1) / (1 << 53)
+	u2 := float64(splitmix64(uint64(userID)^0x9e3779b97f4a7c15)>>11) / (1 << 53)
+	z := math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2) // Box-Muller
+	return m.cfg.SkillMean + z*m.cfg.SkillSpread
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
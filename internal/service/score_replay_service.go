@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	scoreDeferred = metrics.NewCounter("score_deferred_total",
+		"Score updates queued in PostgreSQL because Redis was unavailable")
+	scoreReplayed = metrics.NewCounterVec("score_replayed_total",
+		"Queued score updates replayed once Redis was back, by outcome", "outcome")
+	scoreDeferredPending = metrics.NewGaugeVec("score_deferred_pending",
+		"Score updates waiting to be replayed", "tenant")
+)
+
+// ScoreReplayService queues score updates while Redis is down and replays
+// them, in order and through the normal update path, once it is back
+type ScoreReplayService interface {
+	Start()
+	Stop()
+	Defer(userID uint, newRating int, actor, reason, source string) (*models.DeferredScoreUpdate, error)
+	// ShouldDefer reports whether writes must be queued: Redis is down or
+	// older queued writes have not been replayed yet
+	ShouldDefer() bool
+}
+
+type scoreReplayService struct {
+	cfg            config.FallbackConfig
+	deferredRepo   repository.DeferredRepository
+	userRepo       repository.UserRepository
+	leaderboardSvc LeaderboardService
+	auditSvc       AuditService
+	tenant         string
+
+	backlog atomic.Bool
+	stopCh  chan struct{}
+	once    sync.Once
+}
+
+func NewScoreReplayService(
+	cfg config.FallbackConfig,
+	deferredRepo repository.DeferredRepository,
+	userRepo repository.UserRepository,
+	leaderboardSvc LeaderboardService,
+	auditSvc AuditService,
+	tenant string,
+) ScoreReplayService {
+	return &scoreReplayService{
+		cfg:            cfg,
+		deferredRepo:   deferredRepo,
+		userRepo:       userRepo,
+		leaderboardSvc: leaderboardSvc,
+		auditSvc:       auditSvc,
+		tenant:         tenant,
+		stopCh:         make(chan struct{}),
+	}
+}
+
+// Start replays queued updates left from before (or by other servers)
+// and then checks for new ones every ReplayEvery
+func (s *scoreReplayService) Start() {
+	if !s.cfg.Enabled {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.cfg.ReplayEvery)
+		defer ticker.Stop()
+
+		for {
+			s.replay()
+			select {
+			case <-ticker.C:
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *scoreReplayService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+func (s *scoreReplayService) ShouldDefer() bool {
+	return s.cfg.Enabled && (s.leaderboardSvc.Degraded() || s.backlog.Load())
+}
+
+// Defer queues an update for replay; the user must exist and not be banned
+func (s *scoreReplayService) Defer(userID uint, newRating int, actor, reason, source string) (*models.DeferredScoreUpdate, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Status == models.UserStatusBanned {
+		return nil, ErrUserBanned
+	}
+
+	update := &models.DeferredScoreUpdate{
+		UserID:    userID,
+		NewRating: newRating,
+		Actor:     actor,
+		Reason:    reason,
+		Source:    source,
+	}
+	if err := s.deferredRepo.Create(update); err != nil {
+		return nil, err
+	}
+
+	s.backlog.Store(true)
+	scoreDeferred.Inc()
+	return update, nil
+}
+
+// replay drains the queue batch by batch while Redis is up
+func (s *scoreReplayService) replay() {
+	defer s.refreshBacklog()
+
+	total := 0
+	for !s.leaderboardSvc.Degraded() {
+		done, err := s.deferredRepo.Replay(s.cfg.ReplayBatch, s.apply)
+		if err != nil {
+			slog.Warn("⚠️  Failed to replay queued score updates", "error", err)
+			return
+		}
+		if done == 0 {
+			break
+		}
+		total += done
+	}
+	if total > 0 {
+		slog.Info("🔁 Replayed queued score updates", "updates", total, "tenant", s.tenant)
+	}
+}
+
+// apply replays updates in order and returns the IDs that are finished
+// with. It stops at the first update that can't be applied yet (user
+// locked, Redis gone again) so later updates stay behind it.
+func (s *scoreReplayService) apply(updates []models.DeferredScoreUpdate) []uint {
+	done := make([]uint, 0, len(updates))
+	for _, update := range updates {
+		payload, err := s.leaderboardSvc.UpdateUserScore(context.Background(), update.UserID, update.NewRating)
+		switch {
+		case errors.Is(err, ErrRatingDeltaExceeded), errors.Is(err, ErrUserBanned),
+			errors.Is(err, gorm.ErrRecordNotFound):
+			// Rejected by the rating limits, banned or deleted meanwhile: dropped
+			slog.Warn("⚠️  Dropped queued score update", "update", update.ID, "user_id", update.UserID, "error", err)
+			scoreReplayed.WithLabelValues("dropped").Inc()
+		case err != nil:
+			return done // retried on the next run
+		default:
+			if err := s.auditSvc.RecordAdjustments(update.Actor, update.Reason, update.Source,
+				[]*models.ScoreUpdatePayload{payload}); err != nil {
+				slog.Warn("⚠️  Failed to audit replayed score change", "user_id", update.UserID, "actor", update.Actor, "error", err)
+			}
+			scoreReplayed.WithLabelValues("applied").Inc()
+		}
+		done = append(done, update.ID)
+	}
+	return done
+}
+
+func (s *scoreReplayService) refreshBacklog() {
+	pending, err := s.deferredRepo.Count()
+	if err != nil {
+		slog.Warn("⚠️  Failed to count queued score updates", "error", err)
+		return
+	}
+	s.backlog.Store(pending > 0)
+	scoreDeferredPending.WithLabelValues(s.tenant).Set(float64(pending))
+}
@@ -3,27 +3,37 @@ package service
 import (
 	"fmt"
 	"sort"
+	"strings"
 
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
 )
 
 type SearchService interface {
-	SearchUsers(query string, limit int) ([]models.SearchResult, error)
+	SearchUsers(query string, limit int, viewerID uint) ([]models.SearchResult, error)
+	Autocomplete(prefix string, limit int, viewerID uint) ([]models.Suggestion, error)
 }
 
+// Alphabetical matches an autocomplete reads from the username index; the
+// best rated of them are suggested
+const autocompleteScan = 500
+
 type searchService struct {
+	cfg             config.SearchConfig
 	userRepo        repository.UserRepository
 	leaderboardRepo repository.LeaderboardRepository
 	leaderboardSvc  LeaderboardService
 }
 
 func NewSearchService(
+	cfg config.SearchConfig,
 	userRepo repository.UserRepository,
 	leaderboardRepo repository.LeaderboardRepository,
 	leaderboardSvc LeaderboardService,
 ) SearchService {
 	return &searchService{
+		cfg:             cfg,
 		userRepo:        userRepo,
 		leaderboardRepo: leaderboardRepo,
 		leaderboardSvc:  leaderboardSvc,
@@ -32,35 +42,37 @@ func NewSearchService(
 
 // SearchUsers searches for users by username and returns results with global ranks
 // OPTIMIZED: Uses PostgreSQL only (no Redis prefix search)
-func (s *searchService) SearchUsers(query string, limit int) ([]models.SearchResult, error) {
+// Results are ordered by a blend of username similarity and rating (see
+// SearchByUsername), so an exact match is not buried under high-rated users.
+// Banned users never appear; shadow-banned users only appear to themselves
+func (s *searchService) SearchUsers(query string, limit int, viewerID uint) ([]models.SearchResult, error) {
 	if len(query) < 1 {
 		return []models.SearchResult{}, nil
 	}
 
 	// Use PostgreSQL fuzzy search with trigram index (fast enough!)
-	users, err := s.userRepo.SearchByUsername(query, limit)
+	users, err := s.userRepo.SearchByUsername(query, limit, viewerID, s.cfg.SimilarityThreshold, s.cfg.SimilarityWeight)
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
-	// Sort by rating (descending)
-	sort.Slice(users, func(i, j int) bool {
-		return users[i].Rating > users[j].Rating
-	})
-
-	// Limit results
-	if len(users) > limit {
-		users = users[:limit]
+	// Look up global ranks in one pipelined batch, not a round trip per user
+	userIDs := make([]uint, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+	ranks, err := s.leaderboardSvc.GetUserRanksAs(userIDs, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
 	// Build search results with global ranks
 	results := make([]models.SearchResult, 0, len(users))
 
 	for _, user := range users {
-		// Get global rank for each user from Redis
-		rank, err := s.leaderboardSvc.GetUserRank(user.ID)
-		if err != nil {
-			// If rank not found, skip this user
+		// If rank not found, skip this user
+		rank, ok := ranks[user.ID]
+		if !ok {
 			continue
 		}
 
@@ -69,13 +81,62 @@ func (s *searchService) SearchUsers(query string, limit int) ([]models.SearchRes
 			UserID:     user.ID,
 			Username:   user.Username,
 			Rating:     user.Rating,
+			Similarity: user.Similarity,
 		})
 	}
 
-	// Sort by rating descending (maintain rank order)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Rating > results[j].Rating
-	})
-
 	return results, nil
 }
+
+// Autocomplete suggests users whose username starts with prefix
+// (case-insensitive), best rated first. Matches come from the Redis username
+// index (ZRANGEBYLEX), not PostgreSQL, so it stays fast on every keystroke.
+// Only the first autocompleteScan matches in alphabetical order are ranked:
+// a short prefix on a large board suggests the best of those, and typing on
+// narrows it down. Users off the public board are left out, except a
+// shadow-banned viewer themselves.
+func (s *searchService) Autocomplete(prefix string, limit int, viewerID uint) ([]models.Suggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return []models.Suggestion{}, nil
+	}
+
+	matches, err := s.leaderboardRepo.SearchUsernames(prefix, autocompleteScan)
+	if err != nil {
+		return nil, fmt.Errorf("autocomplete failed: %w", err)
+	}
+	if len(matches) == 0 {
+		return []models.Suggestion{}, nil
+	}
+
+	userIDs := make([]uint, len(matches))
+	for i, match := range matches {
+		userIDs[i] = match.UserID
+	}
+	ratings, err := s.leaderboardRepo.GetBoardScores(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("autocomplete failed: %w", err)
+	}
+
+	suggestions := make([]models.Suggestion, 0, len(matches))
+	for _, match := range matches {
+		rating, ok := ratings[match.UserID]
+		if !ok && viewerID != 0 && match.UserID == viewerID {
+			rating, err = s.leaderboardRepo.GetShadowScore(viewerID)
+			ok = err == nil
+		}
+		if !ok {
+			continue // deleted, banned or shadow-banned
+		}
+		match.Rating = rating
+		suggestions = append(suggestions, match)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Rating > suggestions[j].Rating
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
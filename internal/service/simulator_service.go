@@ -1,111 +1,427 @@
 package service
 
 import (
-	"log"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
 )
 
+// Shortest tick the simulator accepts
+const minSimulatorInterval = 10 * time.Millisecond
+
+// ErrInvalidSimulator is returned for out-of-range simulator settings
+var ErrInvalidSimulator = newValidationError("invalid simulator settings")
+
+var (
+	simulatorUpdates = metrics.NewCounterVec("simulator_updates_total",
+		"Score updates generated by the simulator, by result", "result")
+	simulatorSkipped = metrics.NewCounter("simulator_skipped_ticks_total",
+		"Simulator ticks skipped while the previous tick was still running")
+)
+
+// SimulatorRequest changes the simulator's settings; zero values keep the
+// current ones (Burst and Users are pointers because 0 is meaningful)
+type SimulatorRequest struct {
+	Interval    time.Duration
+	Concurrency int
+	Burst       *int
+	Users       *int
+}
+
+// SimulatorService plays simulated matches for random users. Its settings
+// live in Redis, so starting or stopping it through any server applies to
+// all of them (each runs its own simulator, within SyncEvery).
 type SimulatorService interface {
 	Start()
 	Stop()
+	Enable(actor string, req SimulatorRequest) (*models.SimulatorStatus, error)
+	Disable(actor string) (*models.SimulatorStatus, error)
+	Status() *models.SimulatorStatus
 }
 
 type UserRepository interface {
 	GetRandomUserID() (uint, error)
+	GetRandomUserIDs(n int) ([]uint, error)
+}
+
+// simulatorRun is one run with fixed settings; changing them starts a new run
+type simulatorRun struct {
+	state     models.SimulatorState
+	interval  time.Duration
+	pool      []uint
+	startedAt time.Time
+	stopCh    chan struct{}
+	done      chan struct{}
+
+	updates  atomic.Int64
+	failures atomic.Int64
+	skipped  atomic.Int64
 }
 
 type simulatorService struct {
+	cfg            config.SimulatorConfig
+	redis          *redis.Client
 	leaderboardSvc LeaderboardService
 	userRepo       UserRepository
-	ticker         *time.Ticker
-	stopCh         chan bool
-	running        bool
+	model          *ScoreModel
+	node           string
+
+	mu  sync.Mutex
+	run *simulatorRun // nil while stopped here
+
+	stopCh chan struct{}
+	once   sync.Once
 }
 
 func NewSimulatorService(
+	cfg config.SimulatorConfig,
+	redisClient *redis.Client,
 	leaderboardSvc LeaderboardService,
 	userRepo UserRepository,
+	model *ScoreModel,
+	node string,
 ) SimulatorService {
+	if cfg.MaxConcurrency < 1 {
+		cfg.MaxConcurrency = 1
+	}
+	if cfg.SyncEvery <= 0 {
+		cfg.SyncEvery = 5 * time.Second
+	}
 	return &simulatorService{
+		cfg:            cfg,
+		redis:          redisClient,
 		leaderboardSvc: leaderboardSvc,
 		userRepo:       userRepo,
-		stopCh:         make(chan bool),
-		running:        false,
+		model:          model,
+		node:           node,
+		stopCh:         make(chan struct{}),
 	}
 }
 
-// Start begins the score update simulation
+// Start applies the shared settings (or, before any admin change, the
+// configured defaults) and keeps following them
 func (s *simulatorService) Start() {
-	if s.running {
-		log.Println("⚠️  Simulator already running")
-		return
-	}
-
-	interval := 3 * time.Second // Default 3 seconds
-	if config.AppCfg != nil {
-		interval = config.AppCfg.App.ScoreUpdateInterval
-	}
-	s.ticker = time.NewTicker(interval)
-	s.running = true
-
-	log.Printf("🎮 Score simulator started (interval: %v)", interval)
+	s.sync()
 
 	go func() {
+		ticker := time.NewTicker(s.cfg.SyncEvery)
+		defer ticker.Stop()
+
 		for {
 			select {
-			case <-s.ticker.C:
-				s.simulateScoreUpdate()
+			case <-ticker.C:
+				s.sync()
 			case <-s.stopCh:
-				log.Println("⏹️  Score simulator stopped")
 				return
 			}
 		}
 	}()
 }
 
-// Stop halts the score update simulation
+// Stop halts the simulator on this server only
 func (s *simulatorService) Stop() {
-	if !s.running {
-		return
+	s.once.Do(func() { close(s.stopCh) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.halt()
+}
+
+// Enable starts the simulator on every server, with req applied over the
+// current settings
+func (s *simulatorService) Enable(actor string, req SimulatorRequest) (*models.SimulatorStatus, error) {
+	state := s.load()
+	if req.Interval != 0 {
+		state.Interval = req.Interval.String()
+	}
+	if req.Concurrency != 0 {
+		state.Concurrency = req.Concurrency
+	}
+	if req.Burst != nil {
+		state.Burst = *req.Burst
 	}
+	if req.Users != nil {
+		state.Users = *req.Users
+	}
+	state.Running = true
+	if _, err := s.validate(state); err != nil {
+		return nil, err
+	}
+	return s.save(actor, state)
+}
 
-	s.ticker.Stop()
-	s.stopCh <- true
-	s.running = false
+// Disable stops the simulator on every server
+func (s *simulatorService) Disable(actor string) (*models.SimulatorStatus, error) {
+	state := s.load()
+	state.Running = false
+	return s.save(actor, state)
 }
 
-// simulateScoreUpdate updates a random user's score
-func (s *simulatorService) simulateScoreUpdate() {
-	// Get random user
-	userID, err := s.userRepo.GetRandomUserID()
+// Status reports the shared settings and this server's run
+func (s *simulatorService) Status() *models.SimulatorStatus {
+	state := s.load()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := &models.SimulatorStatus{SimulatorState: state, Node: s.node}
+	if run := s.run; run != nil {
+		startedAt := run.startedAt
+		status.Active = true
+		status.PoolSize = len(run.pool)
+		status.StartedAt = &startedAt
+		status.Updates = run.updates.Load()
+		status.Failures = run.failures.Load()
+		status.Skipped = run.skipped.Load()
+		if elapsed := time.Since(startedAt).Seconds(); elapsed > 0 {
+			status.Rate = float64(status.Updates) / elapsed
+		}
+	}
+	return status
+}
+
+func (s *simulatorService) save(actor string, state models.SimulatorState) (*models.SimulatorStatus, error) {
+	state.UpdatedBy = actor
+	state.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(state)
 	if err != nil {
-		log.Printf("❌ Failed to get random user: %v", err)
+		return nil, err
+	}
+	if err := s.redis.Set(database.Ctx, database.SimulatorStateKey, data, 0).Err(); err != nil {
+		return nil, fmt.Errorf("failed to save simulator settings: %w", err)
+	}
+	slog.Info("🎮 Simulator settings changed", "actor", actor, "running", state.Running,
+		"interval", state.Interval, "concurrency", state.Concurrency, "burst", state.Burst, "users", state.Users)
+
+	// Applied here at once, elsewhere within SyncEvery
+	s.apply(state)
+	return s.Status(), nil
+}
+
+// load returns the shared settings, or the configured defaults when no
+// admin has changed them (or Redis cannot be read)
+func (s *simulatorService) load() models.SimulatorState {
+	state := models.SimulatorState{
+		Running:     s.cfg.Enabled,
+		Interval:    s.cfg.Interval.String(),
+		Concurrency: s.cfg.Concurrency,
+		Burst:       s.cfg.Burst,
+		Users:       s.cfg.Users,
+	}
+
+	data, err := s.redis.Get(database.Ctx, database.SimulatorStateKey).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			slog.Warn("⚠️  Failed to read simulator settings", "error", err)
+		}
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		slog.Warn("⚠️  Ignoring unreadable simulator settings", "error", err)
+	}
+	return state
+}
+
+func (s *simulatorService) validate(state models.SimulatorState) (time.Duration, error) {
+	interval, err := time.ParseDuration(state.Interval)
+	if err != nil || interval < minSimulatorInterval || interval > time.Hour {
+		return 0, fmt.Errorf("%w: interval must be between %v and 1h", ErrInvalidSimulator, minSimulatorInterval)
+	}
+	if state.Concurrency < 1 || state.Concurrency > s.cfg.MaxConcurrency {
+		return 0, fmt.Errorf("%w: concurrency must be between 1 and %d", ErrInvalidSimulator, s.cfg.MaxConcurrency)
+	}
+	if state.Burst < 0 || state.Burst > s.cfg.MaxBurst {
+		return 0, fmt.Errorf("%w: burst must be between 0 and %d", ErrInvalidSimulator, s.cfg.MaxBurst)
+	}
+	if state.Users < 0 || state.Users > s.cfg.MaxUsers {
+		return 0, fmt.Errorf("%w: users must be between 0 and %d", ErrInvalidSimulator, s.cfg.MaxUsers)
+	}
+	return interval, nil
+}
+
+func (s *simulatorService) sync() {
+	s.apply(s.load())
+}
+
+// apply starts, restarts or stops this server's run to match state
+func (s *simulatorService) apply(state models.SimulatorState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.stopCh:
+		return // shutting down
+	default:
+	}
+
+	if run := s.run; run != nil {
+		current := run.state
+		if state.Running && current.Interval == state.Interval &&
+			current.Concurrency == state.Concurrency && current.Burst == state.Burst &&
+			current.Users == state.Users {
+			return
+		}
+		s.halt()
+	}
+	if !state.Running {
 		return
 	}
 
-	// Generate random rating change (-100 to +100)
-	change := rand.Intn(201) - 100
+	interval, err := s.validate(state)
+	if err != nil {
+		slog.Warn("⚠️  Simulator not started", "error", err)
+		return
+	}
+
+	run := &simulatorRun{
+		state:     state,
+		interval:  interval,
+		startedAt: time.Now().UTC(),
+		stopCh:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if state.Users > 0 {
+		if run.pool, err = s.userRepo.GetRandomUserIDs(state.Users); err != nil {
+			slog.Error("❌ Simulator not started, failed to sample users", "error", err)
+			return
+		}
+		if len(run.pool) == 0 {
+			slog.Warn("⚠️  Simulator not started: no active users")
+			return
+		}
+	}
 
-	// Get current rating (simulated - would get from DB in real scenario)
-	// For simplicity, we'll generate a new rating in valid range
-	newRating := 1500 + change
+	s.run = run
+	go s.loop(run)
+	slog.Info("🎮 Score simulator started", "interval", interval, "concurrency", state.Concurrency,
+		"updates_per_tick", run.perTick(), "users", poolLabel(len(run.pool)))
+}
 
-	// Ensure within bounds
-	if newRating < 100 {
-		newRating = 100
+// halt stops the current run and waits for its updates; s.mu must be held
+func (s *simulatorService) halt() {
+	if s.run == nil {
+		return
 	}
-	if newRating > 5000 {
-		newRating = 5000
+	close(s.run.stopCh)
+	<-s.run.done
+	s.run = nil
+	slog.Info("⏹️  Score simulator stopped")
+}
+
+// loop fires perTick updates per tick. A tick is skipped while the
+// previous tick's updates are still running, so a slow store is not piled on.
+func (s *simulatorService) loop(run *simulatorRun) {
+	defer close(run.done)
+
+	ticker := time.NewTicker(run.interval)
+	defer ticker.Stop()
+
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
+	busy := atomic.Bool{}
+
+	for {
+		select {
+		case <-ticker.C:
+			if !busy.CompareAndSwap(false, true) {
+				run.skipped.Add(1)
+				simulatorSkipped.Inc()
+				continue
+			}
+			inFlight.Add(1)
+			go func() {
+				defer inFlight.Done()
+				defer busy.Store(false)
+				s.tick(run)
+			}()
+		case <-run.stopCh:
+			return
+		}
+	}
+}
+
+// perTick is the number of updates per tick: Burst, or one per worker
+func (run *simulatorRun) perTick() int {
+	if run.state.Burst > 0 {
+		return run.state.Burst
+	}
+	return run.state.Concurrency
+}
+
+// tick runs perTick updates on Concurrency workers
+func (s *simulatorService) tick(run *simulatorRun) {
+	var remaining atomic.Int64
+	remaining.Store(int64(run.perTick()))
+
+	var wg sync.WaitGroup
+	for i := 0; i < run.state.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for remaining.Add(-1) >= 0 {
+				select {
+				case <-run.stopCh:
+					return
+				default:
+				}
+				if s.simulateScoreUpdate(run) {
+					run.updates.Add(1)
+					simulatorUpdates.WithLabelValues("ok").Inc()
+				} else {
+					run.failures.Add(1)
+					simulatorUpdates.WithLabelValues("failed").Inc()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// simulateScoreUpdate updates a random user's score
+func (s *simulatorService) simulateScoreUpdate(run *simulatorRun) bool {
+	// Get random user, from the pool when there is one
+	var userID uint
+	if len(run.pool) > 0 {
+		userID = run.pool[rand.Intn(len(run.pool))]
+	} else {
+		var err error
+		if userID, err = s.userRepo.GetRandomUserID(); err != nil {
+			slog.Error("❌ Failed to get random user", "error", err)
+			return false
+		}
+	}
+
+	// Play one simulated match from their current rating
+	newRating, err := s.model.NextRating(userID)
+	if err != nil {
+		slog.Error("❌ Failed to simulate match", "user_id", userID, "error", err)
+		return false
 	}
 
 	// Update score
-	if _, err := s.leaderboardSvc.UpdateUserScore(userID, newRating); err != nil {
-		log.Printf("❌ Failed to update user %d: %v", userID, err)
-		return
+	if _, err := s.leaderboardSvc.UpdateUserScore(context.Background(), userID, newRating); err != nil {
+		slog.Error("❌ Failed to update user", "user_id", userID, "error", err)
+		return false
 	}
 
 	// Success is logged in UpdateUserScore
-}
\ No newline at end of file
+	return true
+}
+
+func poolLabel(size int) string {
+	if size == 0 {
+		return "any active user"
+	}
+	return fmt.Sprintf("%d sampled", size)
+}
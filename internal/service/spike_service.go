@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+// Cap on latency samples kept per spike (percentiles use the first N)
+const spikeMaxSamples = 1_000_000
+
+var (
+	ErrSpikeRunning  = errors.New("a spike is already running")
+	ErrSpikeNotFound = errors.New("spike not found")
+)
+
+// DropCounter reports WebSocket messages dropped for slow clients
+type DropCounter interface {
+	DroppedMessages() uint64
+}
+
+// SpikeRequest configures a traffic spike
+type SpikeRequest struct {
+	Multiplier int           `json:"multiplier"` // × the simulator rate
+	Duration   time.Duration `json:"-"`
+	Users      int           `json:"users"` // size of the random user subset
+}
+
+// SpikeReport summarises a spike for capacity planning
+type SpikeReport struct {
+	ID         string    `json:"id"`
+	Status     string    `json:"status"` // queued | running | completed | stopped | failed
+	Multiplier int       `json:"multiplier"`
+	Users      int       `json:"users"`
+	Duration   string    `json:"duration"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Error      string    `json:"error,omitempty"`
+
+	TargetRate   float64 `json:"target_rate_per_sec"`
+	AchievedRate float64 `json:"achieved_rate_per_sec"`
+	Attempted    int64   `json:"attempted"`
+	Succeeded    int64   `json:"succeeded"`
+	Failed       int64   `json:"failed"`
+	// Ticks skipped because every worker was busy (generator saturated)
+	Skipped int64 `json:"skipped"`
+
+	LatencyMs struct {
+		P50 float64 `json:"p50"`
+		P95 float64 `json:"p95"`
+		P99 float64 `json:"p99"`
+		Max float64 `json:"max"`
+	} `json:"update_latency_ms"`
+
+	QueueDepth struct {
+		Start int64   `json:"start"`
+		Max   int64   `json:"max"`
+		End   int64   `json:"end"`
+		Trace []int64 `json:"trace"` // one sample per second
+	} `json:"db_sync_queue_depth"`
+
+	DroppedWSMessages uint64 `json:"dropped_ws_messages"`
+}
+
+// JobTypeTrafficSpike is the job type of traffic spikes
+const JobTypeTrafficSpike = "traffic_spike"
+
+// SpikeService runs admin-triggered load spikes through the real score path,
+// as jobs (listed and cancellable through the job API)
+type SpikeService interface {
+	Start(actor string, req SpikeRequest) (*SpikeReport, error)
+	Stop(id string) error
+	Get(id string) (*SpikeReport, error)
+}
+
+type spikeService struct {
+	cfg            config.SpikeConfig
+	leaderboardSvc LeaderboardService
+	userRepo       repository.UserRepository
+	dbSyncService  DBSyncService
+	drops          DropCounter
+	jobSvc         JobService
+	model          *ScoreModel
+
+	mu      sync.Mutex
+	current *SpikeReport // live report of the queued or running spike
+}
+
+func NewSpikeService(
+	cfg config.SpikeConfig,
+	leaderboardSvc LeaderboardService,
+	userRepo repository.UserRepository,
+	dbSyncService DBSyncService,
+	drops DropCounter,
+	jobSvc JobService,
+	model *ScoreModel,
+) SpikeService {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	return &spikeService{
+		cfg:            cfg,
+		leaderboardSvc: leaderboardSvc,
+		userRepo:       userRepo,
+		dbSyncService:  dbSyncService,
+		drops:          drops,
+		jobSvc:         jobSvc,
+		model:          model,
+	}
+}
+
+// Start validates the request, samples the user subset and submits the spike job
+func (s *spikeService) Start(actor string, req SpikeRequest) (*SpikeReport, error) {
+	if req.Multiplier < 1 || req.Multiplier > s.cfg.MaxMultiplier {
+		return nil, fmt.Errorf("multiplier must be between 1 and %d", s.cfg.MaxMultiplier)
+	}
+	if req.Duration <= 0 || req.Duration > s.cfg.MaxDuration {
+		return nil, fmt.Errorf("duration must be between 1s and %v", s.cfg.MaxDuration)
+	}
+	if req.Users < 1 {
+		return nil, fmt.Errorf("users must be at least 1")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		// A spike cancelled before it started never clears itself
+		if job, err := s.jobSvc.Get(s.current.ID); err != nil || !job.Finished() {
+			return nil, ErrSpikeRunning
+		}
+		s.current = nil
+	}
+
+	userIDs, err := s.userRepo.GetRandomUserIDs(req.Users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample users: %w", err)
+	}
+	if len(userIDs) == 0 {
+		return nil, fmt.Errorf("no active users to spike")
+	}
+
+	baseInterval := 3 * time.Second
+	if config.AppCfg != nil {
+		baseInterval = config.AppCfg.App.ScoreUpdateInterval
+	}
+
+	report := &SpikeReport{
+		Status:     "queued",
+		Multiplier: req.Multiplier,
+		Users:      len(userIDs),
+		Duration:   req.Duration.String(),
+		TargetRate: float64(req.Multiplier) / baseInterval.Seconds(),
+	}
+	interval := baseInterval / time.Duration(req.Multiplier)
+
+	job, err := s.jobSvc.Submit(JobTypeTrafficSpike, actor, report, func(ctx context.Context, progress *JobProgress) (interface{}, error) {
+		return s.run(ctx, progress, report, userIDs, interval, req.Duration), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.ID = job.ID
+	s.current = report
+
+	snapshot := *report
+	return &snapshot, nil
+}
+
+// Stop ends a running spike early (its report is kept)
+func (s *spikeService) Stop(id string) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	return s.jobSvc.Cancel(id)
+}
+
+// Get returns the live report of the current spike, or the stored report
+// of a finished one
+func (s *spikeService) Get(id string) (*SpikeReport, error) {
+	s.mu.Lock()
+	if s.current != nil && s.current.ID == id {
+		snapshot := *s.current
+		s.mu.Unlock()
+		return &snapshot, nil
+	}
+	s.mu.Unlock()
+
+	job, err := s.jobSvc.Get(id)
+	if err != nil || job.Type != JobTypeTrafficSpike {
+		return nil, ErrSpikeNotFound
+	}
+
+	var report SpikeReport
+	source := job.Result
+	if len(source) == 0 {
+		source = job.Params // never ran (e.g. interrupted by a restart)
+	}
+	if err := json.Unmarshal(source, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode spike report: %w", err)
+	}
+	report.ID = job.ID
+	if len(job.Result) == 0 {
+		report.Status = job.Status
+		report.Error = job.Error
+	}
+	return &report, nil
+}
+
+// run drives updates at the spike rate through a bounded set of workers and
+// samples queue depth once a second
+func (s *spikeService) run(ctx context.Context, progress *JobProgress, report *SpikeReport, userIDs []uint, interval, duration time.Duration) *SpikeReport {
+	var (
+		attempted, succeeded, failed, skipped atomic.Int64
+		latMu                                 sync.Mutex
+		latencies                             []float64
+		wg                                    sync.WaitGroup
+	)
+
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+
+	s.mu.Lock()
+	report.Status = "running"
+	report.StartedAt = time.Now()
+	s.mu.Unlock()
+
+	slog.Info("📈 Spike started", "spike", report.ID, "multiplier", report.Multiplier,
+		"target_rate", report.TargetRate, "users", len(userIDs), "duration", duration)
+	progress.SetTotal(int64(duration / interval))
+
+	dropsBefore := s.drops.DroppedMessages()
+	depthStart, _ := s.dbSyncService.QueueDepth()
+	depthMax := depthStart
+	var depthTrace []int64
+
+	// Workers pull from a small buffer; a full buffer means we can't keep up
+	work := make(chan uint, s.cfg.Workers)
+	for i := 0; i < s.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for userID := range work {
+				newRating, err := s.model.NextRating(userID)
+				started := time.Now()
+				if err == nil {
+					_, err = s.leaderboardSvc.UpdateUserScore(ctx, userID, newRating)
+				}
+				elapsed := time.Since(started)
+
+				attempted.Add(1)
+				progress.Add(1)
+				if err != nil {
+					failed.Add(1)
+					continue
+				}
+				succeeded.Add(1)
+
+				latMu.Lock()
+				if len(latencies) < spikeMaxSamples {
+					latencies = append(latencies, float64(elapsed.Microseconds())/1000)
+				}
+				latMu.Unlock()
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(interval)
+	sampler := time.NewTicker(time.Second)
+	deadline := time.NewTimer(duration)
+	defer ticker.Stop()
+	defer sampler.Stop()
+	defer deadline.Stop()
+
+	status := "completed"
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			select {
+			case work <- userIDs[rand.Intn(len(userIDs))]:
+			default:
+				skipped.Add(1)
+			}
+		case <-sampler.C:
+			if depth, err := s.dbSyncService.QueueDepth(); err == nil {
+				depthTrace = append(depthTrace, depth)
+				if depth > depthMax {
+					depthMax = depth
+				}
+			}
+		case <-deadline.C:
+			break loop
+		case <-ctx.Done():
+			status = "stopped"
+			break loop
+		}
+	}
+
+	close(work)
+	wg.Wait()
+
+	depthEnd, _ := s.dbSyncService.QueueDepth()
+	finished := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report.Status = status
+	report.FinishedAt = finished
+	report.Attempted = attempted.Load()
+	report.Succeeded = succeeded.Load()
+	report.Failed = failed.Load()
+	report.Skipped = skipped.Load()
+	if elapsed := finished.Sub(report.StartedAt).Seconds(); elapsed > 0 {
+		report.AchievedRate = float64(report.Succeeded) / elapsed
+	}
+
+	sort.Float64s(latencies)
+	report.LatencyMs.P50 = percentile(latencies, 0.50)
+	report.LatencyMs.P95 = percentile(latencies, 0.95)
+	report.LatencyMs.P99 = percentile(latencies, 0.99)
+	if len(latencies) > 0 {
+		report.LatencyMs.Max = latencies[len(latencies)-1]
+	}
+
+	report.QueueDepth.Start = depthStart
+	report.QueueDepth.Max = depthMax
+	report.QueueDepth.End = depthEnd
+	report.QueueDepth.Trace = depthTrace
+	report.DroppedWSMessages = s.drops.DroppedMessages() - dropsBefore
+
+	s.current = nil
+
+	slog.Info("📉 Spike finished", "spike", report.ID, "status", status,
+		"succeeded", report.Succeeded, "attempted", report.Attempted, "achieved_rate", report.AchievedRate,
+		"p99_ms", report.LatencyMs.P99, "max_queue", report.QueueDepth.Max, "ws_drops", report.DroppedWSMessages)
+
+	snapshot := *report
+	return &snapshot
+}
+
+// percentile reads the p-th quantile from sorted samples (nearest rank)
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(float64(len(sorted))*p+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
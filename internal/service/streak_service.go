@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/schedule"
+)
+
+const dayFormat = "2006-01-02"
+
+// StreakService counts each user's consecutive days with a score update
+// that raised their rating, in the user's timezone when
+// PERIOD_BOARDS_LOCAL_TIME is on. Redis does the counting; PostgreSQL keeps
+// the streaks for when Redis loses them.
+type StreakService interface {
+	// Record counts the update's day; advanced is false when the day was
+	// already counted or the update did not raise the rating
+	Record(payload *models.ScoreUpdatePayload) (current int, advanced bool)
+	Get(userID uint) (current, longest int, err error)
+	HandleUserRemoved(event eventbus.Event)
+}
+
+type streakService struct {
+	cfg             config.StreakConfig
+	localTime       bool
+	streakRepo      repository.StreakRepository
+	cacheRepo       repository.StreakCacheRepository
+	leaderboardRepo repository.LeaderboardRepository
+}
+
+func NewStreakService(
+	cfg config.StreakConfig,
+	localTime bool,
+	streakRepo repository.StreakRepository,
+	cacheRepo repository.StreakCacheRepository,
+	leaderboardRepo repository.LeaderboardRepository,
+) StreakService {
+	return &streakService{
+		cfg:             cfg,
+		localTime:       localTime,
+		streakRepo:      streakRepo,
+		cacheRepo:       cacheRepo,
+		leaderboardRepo: leaderboardRepo,
+	}
+}
+
+// day returns the user's day at t and the day before it
+func (s *streakService) day(userID uint, t time.Time) (today, yesterday string) {
+	start := schedule.Resolve(schedule.Daily, userLocation(s.leaderboardRepo, s.localTime, userID), t).Start
+	return start.Format(dayFormat), start.AddDate(0, 0, -1).Format(dayFormat)
+}
+
+func (s *streakService) Record(payload *models.ScoreUpdatePayload) (int, bool) {
+	if !s.cfg.Enabled || payload.NewRating <= payload.OldRating {
+		return 0, false
+	}
+
+	today, yesterday := s.day(payload.UserID, time.Unix(payload.Timestamp, 0))
+	streak, advanced, err := s.advance(payload.UserID, today, yesterday)
+	if err != nil {
+		slog.Warn("⚠️  Failed to record streak", "user_id", payload.UserID, "error", err)
+		return 0, false
+	}
+	if !advanced {
+		return streak.Current, false
+	}
+
+	// At most once per user and day, so the write stays off the hot path's
+	// budget
+	if err := s.streakRepo.Upsert(streak); err != nil {
+		slog.Warn("⚠️  Failed to store streak", "user_id", payload.UserID, "error", err)
+	}
+	return streak.Current, true
+}
+
+// advance counts today in Redis, seeding the cache from PostgreSQL first
+// when Redis has no streak for the user
+func (s *streakService) advance(userID uint, today, yesterday string) (*models.Streak, bool, error) {
+	streak, advanced, err := s.cacheRepo.Advance(userID, today, yesterday)
+	if err != nil || streak != nil {
+		return streak, advanced, err
+	}
+
+	stored, err := s.streakRepo.Get(userID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read stored streak: %w", err)
+	}
+	if err := s.cacheRepo.Seed(stored); err != nil {
+		return nil, false, fmt.Errorf("failed to cache streak: %w", err)
+	}
+	streak, advanced, err = s.cacheRepo.Advance(userID, today, yesterday)
+	if err == nil && streak == nil {
+		err = fmt.Errorf("streak cache missing after seeding")
+	}
+	return streak, advanced, err
+}
+
+// Get returns the user's current and longest streak. A streak whose last
+// day is before yesterday is broken, so its current length is 0.
+func (s *streakService) Get(userID uint) (int, int, error) {
+	if !s.cfg.Enabled {
+		return 0, 0, nil
+	}
+
+	streak, err := s.cacheRepo.Get(userID)
+	if err != nil || streak == nil {
+		if streak, err = s.streakRepo.Get(userID); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	today, yesterday := s.day(userID, time.Now())
+	if streak.LastDay != today && streak.LastDay != yesterday {
+		return 0, streak.Longest, nil
+	}
+	return streak.Current, streak.Longest, nil
+}
+
+// HandleUserRemoved drops a deleted or purged user's cached streak; the
+// row goes with the user
+func (s *streakService) HandleUserRemoved(event eventbus.Event) {
+	payload, ok := event.Payload.(*models.UserRemovedPayload)
+	if !ok || !s.cfg.Enabled {
+		return
+	}
+	if err := s.cacheRepo.Forget(payload.UserID); err != nil {
+		slog.Warn("⚠️  Failed to forget streak", "user_id", payload.UserID, "error", err)
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/database"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	syncStreamLength = metrics.NewGauge("db_sync_stream_length",
+		"Entries in the DB sync stream, synced ones included until trimmed")
+	syncPending = metrics.NewGauge("db_sync_pending",
+		"DB sync events delivered to a consumer but not yet written to PostgreSQL")
+	syncLag = metrics.NewGauge("db_sync_lag",
+		"DB sync events not yet delivered to any consumer")
+	syncOldestPendingAge = metrics.NewGauge("db_sync_oldest_pending_age_seconds",
+		"Age of the oldest DB sync event delivered but not yet written")
+	syncLastSuccess = metrics.NewGauge("db_sync_last_success_timestamp_seconds",
+		"Unix time of the newest DB sync commit by any server (0 = none yet)")
+)
+
+// StreamMonitor reports the consumer groups of the Redis streams this
+// service consumes, with per-consumer pending counts, and how far the DB
+// sync is behind (also exported as db_sync_* gauges while started)
+type StreamMonitor interface {
+	Start()
+	Stop()
+	Groups() ([]models.StreamGroupInfo, error)
+	SyncStatus() (*models.SyncStatus, error)
+}
+
+type streamMonitor struct {
+	redis    *redis.Client
+	ctx      context.Context
+	consumer string
+	interval time.Duration
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+func NewStreamMonitor(redisClient *redis.Client, consumer string, interval time.Duration) StreamMonitor {
+	return &streamMonitor{
+		redis:    redisClient,
+		ctx:      database.Ctx,
+		consumer: consumer,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start refreshes the db_sync_* gauges every interval
+func (m *streamMonitor) Start() {
+	if m.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			m.refreshGauges()
+			select {
+			case <-ticker.C:
+			case <-m.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (m *streamMonitor) Stop() {
+	m.once.Do(func() { close(m.stopCh) })
+}
+
+func (m *streamMonitor) refreshGauges() {
+	status, err := m.SyncStatus()
+	if err != nil {
+		slog.Warn("⚠️ Failed to read DB sync status", "error", err)
+		return
+	}
+
+	syncStreamLength.Set(float64(status.Length))
+	syncPending.Set(float64(status.Pending))
+	syncLag.Set(float64(max(status.Lag, 0)))
+	syncOldestPendingAge.Set(status.OldestPendingAge)
+	if status.LastSyncAt != nil {
+		syncLastSuccess.Set(float64(status.LastSyncAt.UnixMilli()) / 1000)
+	}
+}
+
+// SyncStatus reports the DB sync backlog and the last commit of each live
+// consumer. Commit times of consumers no longer in the group are dropped.
+func (m *streamMonitor) SyncStatus() (*models.SyncStatus, error) {
+	now := time.Now()
+	status := &models.SyncStatus{Stream: ScoreUpdateStream, Lag: -1, LastSyncAge: -1}
+
+	info, err := m.group(ScoreUpdateStream, ConsumerGroup)
+	if err != nil {
+		return nil, err
+	}
+	live := map[string]bool{}
+	if info != nil {
+		status.Length = info.Length
+		status.Pending = info.Pending
+		status.Lag = info.Lag
+		for _, consumer := range info.Consumers {
+			live[consumer.Name] = true
+		}
+	}
+	status.Unsynced = status.Pending + max(status.Lag, 0)
+
+	if status.Pending > 0 {
+		summary, err := m.redis.XPending(m.ctx, ScoreUpdateStream, ConsumerGroup).Result()
+		if err != nil {
+			return nil, err
+		}
+		status.OldestPendingID = summary.Lower
+		if ms, _ := splitStreamID(summary.Lower); ms > 0 {
+			status.OldestPendingAge = now.Sub(time.UnixMilli(int64(ms))).Seconds()
+		}
+	}
+
+	commits, err := m.redis.HGetAll(m.ctx, database.SyncLastKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	status.Consumers = make([]models.SyncConsumerStatus, 0, len(commits))
+	for name, raw := range commits {
+		if !live[name] {
+			m.redis.HDel(m.ctx, database.SyncLastKey, name)
+			continue
+		}
+		ms, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+		at := time.UnixMilli(ms)
+		status.Consumers = append(status.Consumers, models.SyncConsumerStatus{
+			Name:       name,
+			LastSyncAt: at,
+			Self:       name == m.consumer,
+		})
+		if status.LastSyncAt == nil || at.After(*status.LastSyncAt) {
+			status.LastSyncAt = &at
+		}
+	}
+	sort.Slice(status.Consumers, func(i, j int) bool {
+		return status.Consumers[i].LastSyncAt.After(status.Consumers[j].LastSyncAt)
+	})
+	if status.LastSyncAt != nil {
+		status.LastSyncAge = now.Sub(*status.LastSyncAt).Seconds()
+	}
+
+	return status, nil
+}
+
+func (m *streamMonitor) Groups() ([]models.StreamGroupInfo, error) {
+	pairs := [][2]string{
+		{ScoreUpdateStream, ConsumerGroup},
+		{IngestStream, IngestConsumerGroup},
+	}
+
+	infos := make([]models.StreamGroupInfo, 0, len(pairs))
+	for _, pair := range pairs {
+		info, err := m.group(pair[0], pair[1])
+		if err != nil {
+			return nil, err
+		}
+		if info != nil {
+			infos = append(infos, *info)
+		}
+	}
+	return infos, nil
+}
+
+// group describes one consumer group (nil if the stream doesn't exist yet)
+func (m *streamMonitor) group(stream, group string) (*models.StreamGroupInfo, error) {
+	length, err := m.redis.XLen(m.ctx, stream).Result()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := m.redis.XInfoGroups(m.ctx, stream).Result()
+	if err != nil {
+		if length == 0 {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	info := &models.StreamGroupInfo{Stream: stream, Group: group, Length: length, Lag: -1}
+	found := false
+	for _, g := range groups {
+		if g.Name == group {
+			info.Pending = g.Pending
+			info.Lag = g.Lag
+			info.LastDeliveredID = g.LastDeliveredID
+			found = true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	consumers, err := m.redis.XInfoConsumers(m.ctx, stream, group).Result()
+	if err != nil {
+		return nil, err
+	}
+	info.Consumers = make([]models.StreamConsumerInfo, 0, len(consumers))
+	for _, c := range consumers {
+		info.Consumers = append(info.Consumers, models.StreamConsumerInfo{
+			Name:    c.Name,
+			Pending: c.Pending,
+			Idle:    c.Idle.Round(time.Second).String(),
+			Self:    c.Name == m.consumer,
+		})
+	}
+	return info, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Entries pending this long belong to a consumer that crashed (or failed
+	// them) and are taken over by whichever server reclaims first
+	ClaimMinIdle  = time.Minute
+	ClaimInterval = 30 * time.Second
+
+	// Entries delivered more often than this are moved to <stream>:dead
+	MaxDeliveries    = 5
+	DeadLetterSuffix = ":dead"
+)
+
+var (
+	streamReclaimed = metrics.NewCounterVec("stream_reclaimed_total",
+		"Pending stream entries taken over from an idle consumer", "stream")
+	streamDeadLettered = metrics.NewCounterVec("stream_dead_lettered_total",
+		"Stream entries moved to the dead-letter stream after too many deliveries", "stream")
+)
+
+// reclaimPending takes over entries left pending by any consumer of the
+// group for at least ClaimMinIdle (XAUTOCLAIM), and returns them for
+// reprocessing. Entries that keep failing (XPENDING delivery count above
+// MaxDeliveries) are dead-lettered and acked instead of retried forever.
+func reclaimPending(ctx context.Context, rdb *redis.Client, stream, group, consumer string, count int64) ([]redis.XMessage, error) {
+	messages, _, err := rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  ClaimMinIdle,
+		Start:    "0-0",
+		Count:    count,
+	}).Result()
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+
+	// Delivery counts of what we just claimed (the claim itself counts one)
+	pending, err := rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream:   stream,
+		Group:    group,
+		Start:    messages[0].ID,
+		End:      messages[len(messages)-1].ID,
+		Count:    int64(len(messages)),
+		Consumer: consumer,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make(map[string]int64, len(pending))
+	for _, p := range pending {
+		deliveries[p.ID] = p.RetryCount
+	}
+
+	retry := make([]redis.XMessage, 0, len(messages))
+	for _, msg := range messages {
+		if deliveries[msg.ID] <= MaxDeliveries {
+			retry = append(retry, msg)
+			continue
+		}
+		if err := deadLetter(ctx, rdb, stream, group, msg, deliveries[msg.ID]); err != nil {
+			slog.Warn("⚠️  Failed to dead-letter entry", "stream", stream, "entry", msg.ID, "error", err)
+			continue
+		}
+		streamDeadLettered.WithLabelValues(stream).Inc()
+		slog.Warn("☠️  Dead-lettered entry", "stream", stream, "entry", msg.ID, "deliveries", deliveries[msg.ID])
+	}
+
+	if len(retry) > 0 {
+		streamReclaimed.WithLabelValues(stream).Add(float64(len(retry)))
+		slog.Info("♻️  Reclaimed abandoned entries", "stream", stream, "entries", len(retry))
+	}
+	return retry, nil
+}
+
+// deadLetter copies an entry to <stream>:dead and acks it in one transaction
+func deadLetter(ctx context.Context, rdb *redis.Client, stream, group string, msg redis.XMessage, deliveries int64) error {
+	values := make(map[string]interface{}, len(msg.Values)+2)
+	for k, v := range msg.Values {
+		values[k] = v
+	}
+	values["original_id"] = msg.ID
+	values["deliveries"] = deliveries
+
+	pipe := rdb.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{Stream: stream + DeadLetterSuffix, Values: values})
+	pipe.XAck(ctx, stream, group, msg.ID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// pruneConsumers removes consumers that hold nothing pending and have been
+// idle longer than expiry (restarted processes leave their old name behind)
+func pruneConsumers(ctx context.Context, rdb *redis.Client, stream, group, self string, expiry time.Duration) {
+	if expiry <= 0 {
+		return
+	}
+	consumers, err := rdb.XInfoConsumers(ctx, stream, group).Result()
+	if err != nil {
+		return
+	}
+	for _, c := range consumers {
+		if c.Name == self || c.Pending > 0 || c.Idle < expiry {
+			continue
+		}
+		if err := rdb.XGroupDelConsumer(ctx, stream, group, c.Name).Err(); err == nil {
+			slog.Info("🧹 Removed idle consumer", "consumer", c.Name, "stream", stream)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+// TestPoisonMessageDeadLettered follows an entry the DB sync cannot decode
+// until reclaimPending moves it to the dead-letter stream
+func TestPoisonMessageDeadLettered(t *testing.T) {
+	s, mr, mock := newTestSync(t)
+	now := time.Now()
+	mr.SetTime(now)
+
+	good := models.DBSyncQueueItem{EventID: "e1", UserID: 7, OldRating: 1000, NewRating: 1100, Timestamp: now.UTC()}
+	messages := enqueue(t, s, itemJSON(t, good), "{not json")
+	poison := messages[1]
+
+	// The good event is written and acked; the poison one is skipped
+	mock.ExpectBegin()
+	expectInsert(mock, []models.DBSyncQueueItem{good}, "e1")
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users")).WithArgs(7, 1100).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if !s.syncMessages(messages) {
+		t.Fatal("batch not committed")
+	}
+	if pending := pendingIDs(t, s); len(pending) != 1 || pending[0] != poison.ID {
+		t.Fatalf("pending = %v, want only the poison entry %s", pending, poison.ID)
+	}
+
+	// Each reclaim redelivers it until it has been delivered MaxDeliveries
+	// times; a batch of only poison never reaches PostgreSQL
+	retries := 0
+	for range MaxDeliveries + 2 {
+		now = now.Add(ClaimMinIdle)
+		mr.SetTime(now)
+		reclaimed, err := reclaimPending(s.ctx, s.redis, ScoreUpdateStream, ConsumerGroup, testConsumer, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reclaimed) == 0 {
+			break
+		}
+		retries++
+		if reclaimed[0].ID != poison.ID || s.syncMessages(reclaimed) {
+			t.Fatalf("reclaimed %v, want the poison entry left pending", reclaimed)
+		}
+	}
+	if retries != MaxDeliveries-1 {
+		t.Errorf("poison entry retried %d times, want %d", retries, MaxDeliveries-1)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	if pending := pendingIDs(t, s); len(pending) != 0 {
+		t.Errorf("pending after dead-lettering = %v, want none", pending)
+	}
+	dead, err := s.redis.XRange(s.ctx, ScoreUpdateStream+DeadLetterSuffix, "-", "+").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 {
+		t.Fatalf("dead-letter stream holds %d entries, want 1", len(dead))
+	}
+	if values := dead[0].Values; values["original_id"] != poison.ID || values["data"] != "{not json" ||
+		values["deliveries"] != fmt.Sprint(MaxDeliveries+1) {
+		t.Errorf("dead-letter entry = %v", values)
+	}
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTeamNotFound  = errors.New("team not found")
+	ErrTeamNameTaken = errors.New("team name already taken")
+	ErrTeamFull      = errors.New("team is full")
+	// ErrAlreadyInTeam is returned when a user joining a team is in one already
+	ErrAlreadyInTeam = errors.New("user is already in a team")
+	ErrNotTeamMember = errors.New("user is not a member of this team")
+)
+
+// TeamService manages teams and keeps the team leaderboard, whose scores
+// aggregate member ratings, in step with score changes
+type TeamService interface {
+	Start()
+	Stop()
+	CreateTeam(name, description string) (*models.Team, error)
+	UpdateTeam(id uint, name, description *string) (*models.Team, error)
+	DeleteTeam(id uint) error
+	GetTeam(id uint) (*models.TeamProfile, error)
+	AddMember(teamID, userID uint) (*models.TeamMembership, error)
+	RemoveMember(teamID, userID uint) error
+	GetLeaderboard(limit int) ([]models.TeamEntry, error)
+	Resync() error
+	HandleScoreUpdate(event eventbus.Event)
+	HandleUserRemoved(event eventbus.Event)
+	HandleReset(event eventbus.Event)
+}
+
+type teamService struct {
+	cfg             config.TeamConfig
+	teamRepo        repository.TeamRepository
+	teamBoardRepo   repository.TeamBoardRepository
+	userRepo        repository.UserRepository
+	leaderboardRepo repository.LeaderboardRepository
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+func NewTeamService(
+	cfg config.TeamConfig,
+	teamRepo repository.TeamRepository,
+	teamBoardRepo repository.TeamBoardRepository,
+	userRepo repository.UserRepository,
+	leaderboardRepo repository.LeaderboardRepository,
+) TeamService {
+	return &teamService{
+		cfg:             cfg,
+		teamRepo:        teamRepo,
+		teamBoardRepo:   teamBoardRepo,
+		userRepo:        userRepo,
+		leaderboardRepo: leaderboardRepo,
+		stopCh:          make(chan struct{}),
+	}
+}
+
+// Start rebuilds the team board from PostgreSQL now and then every
+// ResyncEvery, which repairs scores that drifted (e.g. members deleted
+// without an event)
+func (s *teamService) Start() {
+	slog.Info("👥 Team leaderboard started", "scoring", s.cfg.Scoring)
+
+	go func() {
+		if err := s.Resync(); err != nil {
+			slog.Warn("⚠️  Team resync failed", "error", err)
+		}
+		if s.cfg.ResyncEvery <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(s.cfg.ResyncEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Resync(); err != nil {
+					slog.Warn("⚠️  Team resync failed", "error", err)
+				}
+			case <-s.stopCh:
+				slog.Info("⏹️  Team leaderboard stopped")
+				return
+			}
+		}
+	}()
+}
+
+func (s *teamService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+// Resync reloads every membership from PostgreSQL into Redis and
+// recomputes every team's score
+func (s *teamService) Resync() error {
+	teamIDs, err := s.teamRepo.ListIDs()
+	if err != nil {
+		return fmt.Errorf("failed to list teams: %w", err)
+	}
+	memberships, err := s.teamRepo.ListMemberships()
+	if err != nil {
+		return fmt.Errorf("failed to list team members: %w", err)
+	}
+
+	members := make(map[uint][]uint, len(teamIDs))
+	for _, teamID := range teamIDs {
+		members[teamID] = nil
+	}
+	for _, membership := range memberships {
+		members[membership.TeamID] = append(members[membership.TeamID], membership.UserID)
+	}
+
+	if err := s.teamBoardRepo.Resync(members); err != nil {
+		return fmt.Errorf("failed to resync team members: %w", err)
+	}
+	for _, teamID := range teamIDs {
+		s.recompute(teamID)
+	}
+	return nil
+}
+
+// recompute refreshes a team's score; failures are healed by the next resync
+func (s *teamService) recompute(teamID uint) {
+	if _, err := s.teamBoardRepo.Recompute(teamID, s.cfg.Scoring == config.TeamScoreAverage); err != nil {
+		slog.Warn("⚠️  Failed to recompute team score", "team_id", teamID, "error", err)
+	}
+}
+
+// CreateTeam inserts a team and puts it on the team board
+func (s *teamService) CreateTeam(name, description string) (*models.Team, error) {
+	if _, err := s.teamRepo.GetByName(name); err == nil {
+		return nil, ErrTeamNameTaken
+	}
+
+	team := &models.Team{Name: name, Description: description}
+	if err := s.teamRepo.Create(team); err != nil {
+		return nil, fmt.Errorf("failed to create team: %w", err)
+	}
+	s.recompute(team.ID)
+
+	slog.Info("👥 Created team", "team_id", team.ID, "name", team.Name)
+	return team, nil
+}
+
+// UpdateTeam renames a team and/or changes its description
+func (s *teamService) UpdateTeam(id uint, name, description *string) (*models.Team, error) {
+	team, err := s.getTeam(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != nil && !strings.EqualFold(*name, team.Name) {
+		if _, err := s.teamRepo.GetByName(*name); err == nil {
+			return nil, ErrTeamNameTaken
+		}
+	}
+	if name != nil {
+		team.Name = *name
+	}
+	if description != nil {
+		team.Description = *description
+	}
+
+	if err := s.teamRepo.Save(team); err != nil {
+		return nil, fmt.Errorf("failed to update team: %w", err)
+	}
+	return team, nil
+}
+
+// DeleteTeam removes a team, its memberships and its place on the board
+func (s *teamService) DeleteTeam(id uint) error {
+	deleted, err := s.teamRepo.Delete(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	if !deleted {
+		return ErrTeamNotFound
+	}
+
+	if err := s.teamBoardRepo.RemoveTeam(id); err != nil {
+		slog.Warn("⚠️  Failed to remove team from the team board", "team_id", id, "error", err)
+	}
+
+	slog.Info("🗑️  Deleted team", "team_id", id)
+	return nil
+}
+
+// GetTeam returns a team with its score, rank and members. Member ratings
+// come from Redis, which may be ahead of PostgreSQL.
+func (s *teamService) GetTeam(id uint) (*models.TeamProfile, error) {
+	team, err := s.getTeam(id)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.teamRepo.ListMembers(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team members: %w", err)
+	}
+
+	userIDs := make([]uint, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+	if ratings, err := s.leaderboardRepo.GetScores(userIDs); err == nil {
+		for i := range members {
+			if rating, ok := ratings[members[i].UserID]; ok {
+				members[i].Rating = rating
+			}
+		}
+	}
+
+	profile := &models.TeamProfile{Team: *team, Members: members}
+	profile.Score, profile.Rank, err = s.teamBoardRepo.GetStanding(id)
+	if err != nil {
+		slog.Warn("⚠️  Failed to read team standing", "team_id", id, "error", err)
+	}
+	return profile, nil
+}
+
+// AddMember puts a user in a team; a user in another team must leave it first
+func (s *teamService) AddMember(teamID, userID uint) (*models.TeamMembership, error) {
+	if _, err := s.getTeam(teamID); err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.teamRepo.GetMembership(userID); err == nil {
+		return nil, ErrAlreadyInTeam
+	}
+
+	membership := &models.TeamMembership{TeamID: teamID, UserID: userID}
+	added, err := s.teamRepo.AddMember(membership, s.cfg.MaxMembers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add team member: %w", err)
+	}
+	if !added {
+		return nil, ErrTeamFull
+	}
+
+	if err := s.teamBoardRepo.AddMember(teamID, userID); err != nil {
+		slog.Warn("⚠️  Failed to index team member", "user_id", userID, "team_id", teamID, "error", err)
+	}
+	s.recompute(teamID)
+
+	return membership, nil
+}
+
+// RemoveMember takes a user out of a team
+func (s *teamService) RemoveMember(teamID, userID uint) error {
+	if _, err := s.getTeam(teamID); err != nil {
+		return err
+	}
+
+	removed, err := s.teamRepo.RemoveMember(teamID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+	if !removed {
+		return ErrNotTeamMember
+	}
+
+	if err := s.teamBoardRepo.RemoveMember(teamID, userID); err != nil {
+		slog.Warn("⚠️  Failed to unindex team member", "user_id", userID, "team_id", teamID, "error", err)
+	}
+	s.recompute(teamID)
+
+	return nil
+}
+
+// GetLeaderboard returns the top teams with their names and member counts
+func (s *teamService) GetLeaderboard(limit int) ([]models.TeamEntry, error) {
+	entries, err := s.teamBoardRepo.GetTopTeams(limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team leaderboard: %w", err)
+	}
+
+	teamIDs := make([]uint, len(entries))
+	for i, entry := range entries {
+		teamIDs[i] = entry.TeamID
+	}
+	teams, err := s.teamRepo.GetByIDs(teamIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teams: %w", err)
+	}
+	counts, err := s.teamRepo.CountMembers(teamIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count team members: %w", err)
+	}
+
+	names := make(map[uint]string, len(teams))
+	for _, team := range teams {
+		names[team.ID] = team.Name
+	}
+
+	result := entries[:0]
+	for _, entry := range entries {
+		name, ok := names[entry.TeamID]
+		if !ok {
+			continue // deleted since the board was read
+		}
+		entry.Name = name
+		entry.Members = counts[entry.TeamID]
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// HandleScoreUpdate recomputes the score of the updated user's team
+// (subscribed on the server that accepted the update)
+func (s *teamService) HandleScoreUpdate(event eventbus.Event) {
+	payload, ok := event.Payload.(*models.ScoreUpdatePayload)
+	if !ok || payload.RatingDelta == 0 {
+		return
+	}
+	s.refreshUserTeam(payload.UserID)
+}
+
+// HandleUserRemoved drops a banned user's rating from their team's score,
+// and a purged user from the team index
+func (s *teamService) HandleUserRemoved(event eventbus.Event) {
+	payload, ok := event.Payload.(*models.UserRemovedPayload)
+	if !ok {
+		return
+	}
+
+	teamID, ok, err := s.teamBoardRepo.GetUserTeam(payload.UserID)
+	if err != nil || !ok {
+		return
+	}
+	if _, err := s.teamRepo.GetMembership(payload.UserID); errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := s.teamBoardRepo.RemoveMember(teamID, payload.UserID); err != nil {
+			slog.Warn("⚠️  Failed to unindex team member", "user_id", payload.UserID, "team_id", teamID, "error", err)
+		}
+	}
+	s.recompute(teamID)
+}
+
+// HandleReset rebuilds every team score after the leaderboard was reset
+func (s *teamService) HandleReset(event eventbus.Event) {
+	if err := s.Resync(); err != nil {
+		slog.Warn("⚠️  Team resync after reset failed", "error", err)
+	}
+}
+
+func (s *teamService) refreshUserTeam(userID uint) {
+	teamID, ok, err := s.teamBoardRepo.GetUserTeam(userID)
+	if err != nil {
+		slog.Warn("⚠️  Failed to look up team of user", "user_id", userID, "error", err)
+		return
+	}
+	if ok {
+		s.recompute(teamID)
+	}
+}
+
+func (s *teamService) getTeam(id uint) (*models.Team, error) {
+	team, err := s.teamRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, err
+	}
+	return team, nil
+}
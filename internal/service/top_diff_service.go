@@ -0,0 +1,136 @@
+package service
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+var topDiffRows = metrics.NewCounter("leaderboard_diff_rows_total",
+	"Top-N rows sent in leaderboard_diff messages (changed and removed)")
+
+// EventBroadcaster sends a message to this server's WebSocket clients
+type EventBroadcaster interface {
+	BroadcastEvent(eventType string, payload interface{})
+}
+
+// TopDiffService keeps this server's copy of the top N and sends its
+// WebSocket clients only the rows that changed, so they can patch the
+// board they show instead of refetching it. Every server receives every
+// score update, so each one diffs for its own clients.
+type TopDiffService interface {
+	Start()
+	Stop()
+	HandleEvent(event eventbus.Event)
+}
+
+type topDiffService struct {
+	cfg            config.TopDiffConfig
+	leaderboardSvc LeaderboardService
+	broadcaster    EventBroadcaster
+
+	dirty    atomic.Bool
+	snapshot map[uint]models.LeaderboardEntry // nil until the first read
+	stopCh   chan struct{}
+	once     sync.Once
+}
+
+func NewTopDiffService(cfg config.TopDiffConfig, leaderboardSvc LeaderboardService, broadcaster EventBroadcaster) TopDiffService {
+	return &topDiffService{
+		cfg:            cfg,
+		leaderboardSvc: leaderboardSvc,
+		broadcaster:    broadcaster,
+		stopCh:         make(chan struct{}),
+	}
+}
+
+// Start diffs the top N every TOP_DIFF_INTERVAL after an event may have
+// changed it, and every TOP_DIFF_RESYNC regardless, which catches changes
+// that send no event (decay, rebuilds)
+func (s *topDiffService) Start() {
+	if !s.cfg.Enabled {
+		return
+	}
+
+	slog.Info("📋 Top diffs started", "size", s.cfg.Size, "interval", s.cfg.Interval)
+	s.dirty.Store(true)
+
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		lastRead := time.Now()
+
+		for {
+			select {
+			case <-ticker.C:
+				resync := s.cfg.Resync > 0 && time.Since(lastRead) >= s.cfg.Resync
+				if s.dirty.Swap(false) || resync {
+					s.diff()
+					lastRead = time.Now()
+				}
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *topDiffService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+// HandleEvent marks the top N for a diff on the next tick (subscribed
+// cluster-wide, so bursts of updates from all servers fold into one diff)
+func (s *topDiffService) HandleEvent(event eventbus.Event) {
+	s.dirty.Store(true)
+}
+
+// diff reads the top N and broadcasts the rows that differ from the last
+// read. The first read only sets the baseline; clients fetch the board when
+// they connect.
+func (s *topDiffService) diff() {
+	entries, err := s.leaderboardSvc.GetLeaderboard(s.cfg.Size, 0, true)
+	if err != nil {
+		// Keep the old snapshot and try again on the next tick
+		s.dirty.Store(true)
+		slog.Warn("⚠️  Failed to read top entries for diff", "size", s.cfg.Size, "error", err)
+		return
+	}
+
+	current := make(map[uint]models.LeaderboardEntry, len(entries))
+	for _, entry := range entries {
+		current[entry.UserID] = entry
+	}
+	previous := s.snapshot
+	s.snapshot = current
+	if previous == nil {
+		return
+	}
+
+	payload := &models.LeaderboardDiffPayload{Size: s.cfg.Size, Changed: []models.LeaderboardEntry{}}
+	for _, entry := range entries {
+		if old, ok := previous[entry.UserID]; !ok || old != entry {
+			payload.Changed = append(payload.Changed, entry)
+		}
+	}
+	for userID := range previous {
+		if _, ok := current[userID]; !ok {
+			payload.Removed = append(payload.Removed, userID)
+		}
+	}
+	if len(payload.Changed) == 0 && len(payload.Removed) == 0 {
+		return
+	}
+	slices.Sort(payload.Removed)
+
+	payload.Timestamp = time.Now().Unix()
+	s.broadcaster.BroadcastEvent(models.MessageLeaderboardDiff, payload)
+	topDiffRows.Add(float64(len(payload.Changed) + len(payload.Removed)))
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/schedule"
+	"gorm.io/gorm"
+)
+
+// Number of score updates included in a profile
+const profileHistoryLimit = 10
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrUsernameTaken   = errors.New("username already taken")
+	ErrInvalidTimezone = newValidationError("invalid timezone")
+)
+
+// UserService manages users and assembles user-centric views across Redis
+// and PostgreSQL
+type UserService interface {
+	CreateUser(username string, rating int, timezone string) (*models.User, error)
+	UpdateUser(ctx context.Context, userID uint, username *string, rating *int, timezone *string) (*models.User, error)
+	RenameUser(userID uint, newUsername string) (*models.User, error)
+	DeleteUser(userID uint) error
+	PurgeUser(userID uint) error
+	SetStatus(userID uint, status string) (*models.User, error)
+	GetProfile(userID uint) (*models.UserProfile, error)
+}
+
+type userService struct {
+	userRepo        repository.UserRepository
+	leaderboardRepo repository.LeaderboardRepository
+	scoreUpdateRepo repository.ScoreUpdateRepository
+	rankHistoryRepo repository.RankHistoryRepository
+	leaderboardSvc  LeaderboardService
+	bus             *eventbus.Bus
+	dbSyncService   DBSyncService
+	achievementSvc  AchievementService
+	streakSvc       StreakService
+}
+
+func NewUserService(
+	userRepo repository.UserRepository,
+	leaderboardRepo repository.LeaderboardRepository,
+	scoreUpdateRepo repository.ScoreUpdateRepository,
+	rankHistoryRepo repository.RankHistoryRepository,
+	leaderboardSvc LeaderboardService,
+	bus *eventbus.Bus,
+	dbSyncService DBSyncService,
+	achievementSvc AchievementService,
+	streakSvc StreakService,
+) UserService {
+	return &userService{
+		userRepo:        userRepo,
+		leaderboardRepo: leaderboardRepo,
+		scoreUpdateRepo: scoreUpdateRepo,
+		rankHistoryRepo: rankHistoryRepo,
+		leaderboardSvc:  leaderboardSvc,
+		bus:             bus,
+		dbSyncService:   dbSyncService,
+		achievementSvc:  achievementSvc,
+		streakSvc:       streakSvc,
+	}
+}
+
+// CreateUser inserts a user into PostgreSQL and adds them to the leaderboard
+func (s *userService) CreateUser(username string, rating int, timezone string) (*models.User, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := schedule.LoadLocation(timezone); err != nil {
+		return nil, ErrInvalidTimezone
+	}
+
+	if _, err := s.userRepo.GetByUsername(username); err == nil {
+		return nil, ErrUsernameTaken
+	}
+
+	user := &models.User{Username: username, Rating: rating, Timezone: timezone}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if err := s.leaderboardSvc.SyncUserToLeaderboard(user); err != nil {
+		// PostgreSQL is the source of truth; a re-seed or the next score update repairs Redis
+		slog.Warn("⚠️  Failed to sync new user to leaderboard", "user_id", user.ID, "error", err)
+	}
+
+	slog.Info("👤 Created user", "user_id", user.ID, "username", user.Username, "rating", user.Rating)
+	return user, nil
+}
+
+// UpdateUser renames a user, sets their rating and/or timezone. Rating
+// changes go through the normal score update path so they are broadcast and
+// recorded. A new timezone applies to gains from the next update on.
+func (s *userService) UpdateUser(ctx context.Context, userID uint, username *string, rating *int, timezone *string) (*models.User, error) {
+	if timezone != nil {
+		if _, err := schedule.LoadLocation(*timezone); err != nil || *timezone == "" {
+			return nil, ErrInvalidTimezone
+		}
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if timezone != nil && *timezone != user.Timezone {
+		user.Timezone = *timezone
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, fmt.Errorf("failed to update timezone: %w", err)
+		}
+		if err := s.leaderboardRepo.CacheUser(user); err != nil {
+			slog.Warn("⚠️  Failed to update user cache", "user_id", user.ID, "error", err)
+		}
+	}
+
+	if username != nil && *username != user.Username {
+		if user, err = s.rename(user, *username); err != nil {
+			return nil, err
+		}
+	}
+
+	if rating != nil && *rating != user.Rating {
+		payload, err := s.leaderboardSvc.UpdateUserScore(ctx, userID, *rating)
+		if err != nil {
+			return nil, err
+		}
+		user.Rating = payload.NewRating
+	}
+
+	return user, nil
+}
+
+// RenameUser changes a username in PostgreSQL, rewrites the Redis user cache
+// and broadcasts a user_renamed event to every server and client
+func (s *userService) RenameUser(userID uint, newUsername string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if newUsername == user.Username {
+		return user, nil
+	}
+	return s.rename(user, newUsername)
+}
+
+func (s *userService) rename(user *models.User, newUsername string) (*models.User, error) {
+	if _, err := s.userRepo.GetByUsername(newUsername); err == nil {
+		return nil, ErrUsernameTaken
+	}
+
+	oldUsername := user.Username
+	user.Username = newUsername
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to rename user: %w", err)
+	}
+
+	// Rewrite the cache hash so enrichment stops returning the old name
+	if err := s.leaderboardRepo.CacheUser(user); err != nil {
+		slog.Warn("⚠️  Failed to update user cache", "user_id", user.ID, "error", err)
+	}
+
+	// Autocomplete suggests the new name only
+	if err := s.leaderboardRepo.UnindexUsername(user.ID, oldUsername); err != nil {
+		slog.Warn("⚠️  Failed to update username index", "user_id", user.ID, "error", err)
+	}
+	if user.Status != models.UserStatusBanned {
+		if err := s.leaderboardRepo.IndexUsername(user.ID, newUsername); err != nil {
+			slog.Warn("⚠️  Failed to update username index", "user_id", user.ID, "error", err)
+		}
+	}
+
+	if err := s.bus.Publish(models.EventUserRenamed, &models.UserRenamedPayload{
+		UserID:      user.ID,
+		OldUsername: oldUsername,
+		NewUsername: newUsername,
+		Timestamp:   time.Now().Unix(),
+	}); err != nil {
+		slog.Warn("⚠️  Failed to publish rename", "user_id", user.ID, "error", err)
+	}
+
+	slog.Info("✏️  Renamed user", "user_id", user.ID, "old_username", oldUsername, "username", newUsername)
+	return user, nil
+}
+
+// DeleteUser soft-deletes a user and removes them from the leaderboard
+func (s *userService) DeleteUser(userID uint) error {
+	if err := s.userRepo.Delete(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if err := s.leaderboardSvc.RemoveUser(userID); err != nil {
+		return err
+	}
+
+	slog.Info("🗑️  Deleted user", "user_id", userID)
+	return nil
+}
+
+// GetProfile returns username, rating, tier, rank, percentile, 24h rank
+// delta, recent history, achievements and streaks in one call
+func (s *userService) GetProfile(userID uint) (*models.UserProfile, error) {
+	snapshot, err := s.leaderboardRepo.GetUserSnapshot(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user rank: %w", err)
+	}
+
+	// Username fallback when the cache hash is missing
+	if snapshot.Username == "" {
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			return nil, fmt.Errorf("user not found: %w", err)
+		}
+		snapshot.Username = user.Username
+		s.leaderboardRepo.CacheUser(user)
+	}
+
+	profile := &models.UserProfile{
+		UserID:       userID,
+		Username:     snapshot.Username,
+		Rating:       snapshot.Rating,
+		GlobalRank:   snapshot.Rank,
+		TotalPlayers: snapshot.Total,
+	}
+
+	placement := s.leaderboardSvc.Place(snapshot.Rating)
+	profile.Tier, profile.Division = placement.Tier, placement.Division
+
+	if snapshot.Total > 0 {
+		percentile := float64(snapshot.Total-snapshot.Rank+1) / float64(snapshot.Total) * 100
+		profile.Percentile = math.Round(percentile*100) / 100
+	}
+
+	// Rank delta vs the oldest snapshot in the last 24h
+	if previous, err := s.rankHistoryRepo.GetFirstSince(userID, time.Now().Add(-24*time.Hour)); err == nil {
+		profile.RankDelta24h = previous.Rank - snapshot.Rank
+	}
+
+	history, err := s.scoreUpdateRepo.GetByUserID(userID, profileHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent history: %w", err)
+	}
+	profile.RecentHistory = history
+
+	achievements, err := s.achievementSvc.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	profile.Achievements = achievements
+
+	if profile.CurrentStreak, profile.LongestStreak, err = s.streakSvc.Get(userID); err != nil {
+		return nil, fmt.Errorf("failed to get streak: %w", err)
+	}
+
+	return profile, nil
+}
+
+// SetStatus bans, shadow-bans or reinstates a user and moves them to the
+// matching Redis board. Other clients are told to drop hidden users.
+func (s *userService) SetStatus(userID uint, status string) (*models.User, error) {
+	switch status {
+	case models.UserStatusActive, models.UserStatusBanned, models.UserStatusShadowBanned:
+	default:
+		return nil, fmt.Errorf("invalid status %q", status)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	// Redis may hold a newer rating than PostgreSQL (async sync)
+	if cached, err := s.leaderboardRepo.GetCachedUser(userID); err == nil {
+		user.Rating = cached.Rating
+	}
+
+	if err := s.userRepo.UpdateStatus(userID, status); err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
+	}
+	user.Status = status
+
+	if err := s.leaderboardSvc.SyncUserToLeaderboard(user); err != nil {
+		return nil, err
+	}
+
+	if status != models.UserStatusActive {
+		if err := s.bus.Publish(models.EventUserRemoved, &models.UserRemovedPayload{
+			UserID:    userID,
+			Timestamp: time.Now().Unix(),
+		}); err != nil {
+			slog.Warn("⚠️  Failed to publish user removal", "user_id", userID, "error", err)
+		}
+	}
+
+	slog.Info("🛡️  User status set", "user_id", userID, "status", status)
+	return user, nil
+}
+
+// PurgeUser erases a user everywhere (right to erasure): Redis leaderboard,
+// cache and tracking, queued DB sync events, and all PostgreSQL rows
+// (hard delete). Clients are told to drop the user via user_removed.
+func (s *userService) PurgeUser(userID uint) error {
+	// Stop ranking first so no new score updates are accepted from cache
+	if err := s.leaderboardSvc.RemoveUser(userID); err != nil {
+		return err
+	}
+
+	dropped, err := s.dbSyncService.PurgeUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to purge queued updates: %w", err)
+	}
+
+	if err := s.userRepo.Purge(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to purge user: %w", err)
+	}
+
+	if err := s.bus.Publish(models.EventUserRemoved, &models.UserRemovedPayload{
+		UserID:    userID,
+		Timestamp: time.Now().Unix(),
+	}); err != nil {
+		slog.Warn("⚠️  Failed to publish user removal", "user_id", userID, "error", err)
+	}
+
+	slog.Info("🧨 Purged user", "user_id", userID, "dropped", dropped)
+	return nil
+}
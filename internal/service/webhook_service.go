@@ -0,0 +1,498 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/eventbus"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/notify"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var webhookEvents = []string{models.WebhookRankMilestone, models.WebhookTopEntry, models.WebhookUserUpdate}
+
+var (
+	// ErrWebhookNotFound is returned for unknown subscription IDs
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+	// ErrInvalidWebhook wraps subscription validation errors
+	ErrInvalidWebhook = newValidationError("invalid webhook subscription")
+)
+
+var (
+	webhookDeliveries = metrics.NewCounterVec("webhook_deliveries_total",
+		"Webhook delivery attempts by outcome (delivered, retry, failed)", "result")
+	webhookDropped = metrics.NewCounter("webhook_events_dropped_total",
+		"Matched webhook events dropped because the queue was full")
+)
+
+// How often delivered and failed deliveries past the retention are deleted
+const webhookPurgeEvery = time.Hour
+
+// webhookBody is the JSON POSTed to subscribers
+type webhookBody struct {
+	DeliveryID     uint           `json:"delivery_id"`
+	SubscriptionID uint           `json:"subscription_id"`
+	Event          string         `json:"event"`
+	Attempt        int            `json:"attempt"`
+	Milestone      int64          `json:"milestone,omitempty"`
+	Update         models.RawJSON `json:"update"`
+	Timestamp      int64          `json:"timestamp"`
+}
+
+// WebhookService manages admin webhook subscriptions, matches score updates
+// against their filters and delivers the matches as signed POSTs, retrying
+// with exponential backoff
+type WebhookService interface {
+	Start()
+	Stop()
+	Create(sub *models.WebhookSubscription) error
+	Update(sub *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	Get(id uint) (*models.WebhookSubscription, error)
+	List() ([]models.WebhookSubscription, error)
+	Delete(id uint) error
+	Deliveries(filter repository.WebhookDeliveryFilter) ([]models.WebhookDelivery, error)
+	HandleScoreUpdate(event eventbus.Event)
+}
+
+type webhookService struct {
+	cfg    config.WebhookConfig
+	repo   repository.WebhookRepository
+	sender *notify.WebhookSender
+
+	mu   sync.RWMutex
+	subs []models.WebhookSubscription // active subscriptions
+
+	queue  chan []models.WebhookDelivery
+	wake   chan struct{}
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+// NewWebhookService creates the webhook service; every subscription signs
+// with its own secret
+func NewWebhookService(cfg config.WebhookConfig, repo repository.WebhookRepository) WebhookService {
+	queueSize := cfg.QueueSize
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return &webhookService{
+		cfg:    cfg,
+		repo:   repo,
+		sender: notify.NewWebhookSender(cfg.Timeout, nil),
+		queue:  make(chan []models.WebhookDelivery, queueSize),
+		wake:   make(chan struct{}, 1),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start loads the subscriptions and runs the queue writer and the delivery worker
+func (s *webhookService) Start() {
+	if !s.cfg.Enabled {
+		slog.Info("🪝 Webhooks disabled")
+		return
+	}
+	s.refresh()
+
+	go s.writeLoop()
+	go s.deliverLoop()
+	slog.Info("🪝 Webhook delivery started", "workers", s.cfg.Workers, "attempts", s.cfg.MaxAttempts)
+}
+
+func (s *webhookService) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+// Create validates and stores a subscription. A secret is generated when
+// none is given; the returned subscription is the only place it is shown.
+func (s *webhookService) Create(sub *models.WebhookSubscription) error {
+	if sub.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		sub.Secret = hex.EncodeToString(secret)
+	}
+	if err := validateWebhook(sub); err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(sub); err != nil {
+		return err
+	}
+	s.refresh()
+	return nil
+}
+
+// Update replaces a subscription's URL, filters and state; an empty secret
+// keeps the current one
+func (s *webhookService) Update(sub *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	current, err := s.repo.GetByID(sub.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	if sub.Secret == "" {
+		sub.Secret = current.Secret
+	}
+	if err := validateWebhook(sub); err != nil {
+		return nil, err
+	}
+
+	sub.CreatedBy = current.CreatedBy
+	sub.CreatedAt = current.CreatedAt
+	if err := s.repo.Save(sub); err != nil {
+		return nil, err
+	}
+	s.refresh()
+
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (s *webhookService) Get(id uint) (*models.WebhookSubscription, error) {
+	sub, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (s *webhookService) List() ([]models.WebhookSubscription, error) {
+	subs, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+// Delete removes a subscription together with its delivery log
+func (s *webhookService) Delete(id uint) error {
+	deleted, err := s.repo.Delete(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrWebhookNotFound
+	}
+	s.refresh()
+	return nil
+}
+
+func (s *webhookService) Deliveries(filter repository.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	return s.repo.ListDeliveries(filter)
+}
+
+// HandleScoreUpdate queues a delivery for every subscription the update
+// matches (subscribed on the event bus of the server that accepted the
+// update, so each event is delivered once)
+func (s *webhookService) HandleScoreUpdate(event eventbus.Event) {
+	if !s.cfg.Enabled {
+		return
+	}
+	payload, ok := event.Payload.(*models.ScoreUpdatePayload)
+	if !ok {
+		return
+	}
+
+	s.mu.RLock()
+	var deliveries []models.WebhookDelivery
+	for i := range s.subs {
+		deliveries = append(deliveries, matchWebhook(&s.subs[i], payload)...)
+	}
+	s.mu.RUnlock()
+	if len(deliveries) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for i := range deliveries {
+		deliveries[i].Payload = data
+		deliveries[i].Status = models.WebhookPending
+		deliveries[i].NextAttemptAt = now
+	}
+
+	select {
+	case s.queue <- deliveries:
+	default:
+		webhookDropped.Add(float64(len(deliveries)))
+	}
+}
+
+// matchWebhook returns the deliveries a score update triggers for one
+// subscription: at most one per event type
+func matchWebhook(sub *models.WebhookSubscription, p *models.ScoreUpdatePayload) []models.WebhookDelivery {
+	if len(sub.UserIDs) > 0 && !slices.Contains(sub.UserIDs, p.UserID) {
+		return nil
+	}
+
+	// reached reports whether the update moved the user into the top n;
+	// an old rank of 0 means the user was not ranked before
+	reached := func(n int64) bool {
+		return p.NewRank >= 1 && p.NewRank <= n && (p.OldRank == 0 || p.OldRank > n)
+	}
+
+	var matches []models.WebhookDelivery
+	for _, event := range sub.Events {
+		delivery := models.WebhookDelivery{SubscriptionID: sub.ID, Event: event, UserID: p.UserID}
+		switch event {
+		case models.WebhookUserUpdate:
+			matches = append(matches, delivery)
+		case models.WebhookTopEntry:
+			if reached(sub.TopN) {
+				delivery.Milestone = sub.TopN
+				matches = append(matches, delivery)
+			}
+		case models.WebhookRankMilestone:
+			// The best milestone reached, when a jump passes several
+			for _, milestone := range sub.Milestones {
+				if reached(milestone) && (delivery.Milestone == 0 || milestone < delivery.Milestone) {
+					delivery.Milestone = milestone
+				}
+			}
+			if delivery.Milestone != 0 {
+				matches = append(matches, delivery)
+			}
+		}
+	}
+	return matches
+}
+
+// writeLoop stores queued deliveries and wakes the delivery worker
+func (s *webhookService) writeLoop() {
+	for {
+		select {
+		case deliveries := <-s.queue:
+			if err := s.repo.Enqueue(deliveries); err != nil {
+				slog.Warn("⚠️ Failed to queue webhook deliveries", "deliveries", len(deliveries), "error", err)
+				continue
+			}
+			select {
+			case s.wake <- struct{}{}:
+			default:
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// deliverLoop claims due deliveries from every server's queue and sends
+// them; it also reloads subscriptions and purges the old delivery log
+func (s *webhookService) deliverLoop() {
+	poll := time.NewTicker(s.cfg.PollEvery)
+	defer poll.Stop()
+	refresh := time.NewTicker(s.cfg.RefreshEvery)
+	defer refresh.Stop()
+	purge := time.NewTicker(webhookPurgeEvery)
+	defer purge.Stop()
+
+	for {
+		select {
+		case <-poll.C:
+		case <-s.wake:
+		case <-refresh.C:
+			s.refresh()
+			continue
+		case <-purge.C:
+			s.purge()
+			continue
+		case <-s.stopCh:
+			return
+		}
+
+		// Keep claiming while full batches come back
+		for s.deliverBatch() == s.workers() {
+			select {
+			case <-s.stopCh:
+				return
+			default:
+			}
+		}
+	}
+}
+
+func (s *webhookService) workers() int {
+	if s.cfg.Workers < 1 {
+		return 1
+	}
+	return s.cfg.Workers
+}
+
+// deliverBatch sends up to one delivery per worker in parallel and returns
+// how many were claimed
+func (s *webhookService) deliverBatch() int {
+	// The lease outlasts a timed-out POST, so a slow endpoint is not sent twice
+	deliveries, err := s.repo.ClaimDue(s.workers(), time.Now(), 2*s.cfg.Timeout)
+	if err != nil {
+		slog.Warn("⚠️ Failed to claim webhook deliveries", "error", err)
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			s.attempt(delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+	return len(deliveries)
+}
+
+// attempt POSTs one delivery and records the outcome, scheduling a retry
+// with exponential backoff until MaxAttempts is reached
+func (s *webhookService) attempt(delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+	body, err := json.Marshal(webhookBody{
+		DeliveryID:     delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		Event:          delivery.Event,
+		Attempt:        delivery.Attempts,
+		Milestone:      delivery.Milestone,
+		Update:         delivery.Payload,
+		Timestamp:      time.Now().Unix(),
+	})
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+		err = s.sender.Post(ctx, delivery.Subscription.URL, delivery.Event, body, delivery.Subscription.Secret)
+		cancel()
+	}
+
+	now := time.Now()
+	if err != nil {
+		delivery.LastError = err.Error()
+		if len(delivery.LastError) > 500 {
+			delivery.LastError = delivery.LastError[:500]
+		}
+	}
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		webhookDeliveries.WithLabelValues("delivered").Inc()
+	case delivery.Attempts >= s.cfg.MaxAttempts:
+		delivery.Status = models.WebhookFailed
+		webhookDeliveries.WithLabelValues("failed").Inc()
+		slog.Warn("⚠️ Webhook delivery failed", "delivery", delivery.ID,
+			"subscription", delivery.SubscriptionID, "attempts", delivery.Attempts, "error", err)
+	default:
+		delivery.NextAttemptAt = now.Add(s.backoff(delivery.Attempts))
+		webhookDeliveries.WithLabelValues("retry").Inc()
+	}
+
+	if err := s.repo.Record(delivery); err != nil {
+		slog.Warn("⚠️ Failed to record webhook delivery", "delivery", delivery.ID, "error", err)
+	}
+}
+
+// backoff is the delay after the given failed attempt: Backoff doubled per
+// earlier failure, capped at MaxBackoff
+func (s *webhookService) backoff(attempts int) time.Duration {
+	delay := s.cfg.Backoff
+	for i := 1; i < attempts && delay < s.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, s.cfg.MaxBackoff)
+}
+
+// refresh reloads the active subscriptions
+func (s *webhookService) refresh() {
+	subs, err := s.repo.List()
+	if err != nil {
+		slog.Warn("⚠️ Failed to load webhook subscriptions", "error", err)
+		return
+	}
+	active := subs[:0]
+	for _, sub := range subs {
+		if sub.Active {
+			active = append(active, sub)
+		}
+	}
+
+	s.mu.Lock()
+	s.subs = active
+	s.mu.Unlock()
+}
+
+func (s *webhookService) purge() {
+	if s.cfg.Retention <= 0 {
+		return
+	}
+	deleted, err := s.repo.PurgeDeliveries(time.Now().Add(-s.cfg.Retention))
+	if err != nil {
+		slog.Warn("⚠️ Failed to purge the webhook delivery log", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("🧹 Purged old webhook deliveries", "deliveries", deleted, "retention", s.cfg.Retention)
+	}
+}
+
+// validateWebhook checks a subscription's URL and filters, defaulting the top size
+func validateWebhook(sub *models.WebhookSubscription) error {
+	target, err := url.Parse(sub.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if len(sub.Secret) < 16 {
+		return fmt.Errorf("%w: secret must be at least 16 characters", ErrInvalidWebhook)
+	}
+	if len(sub.Events) == 0 {
+		return fmt.Errorf("%w: events must not be empty (use %s)", ErrInvalidWebhook, strings.Join(webhookEvents, ", "))
+	}
+	for _, event := range sub.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return fmt.Errorf("%w: unknown event %q (use %s)", ErrInvalidWebhook, event, strings.Join(webhookEvents, ", "))
+		}
+	}
+	sub.Events = slices.Compact(slices.Sorted(slices.Values(sub.Events)))
+
+	if slices.Contains(sub.Events, models.WebhookRankMilestone) && len(sub.Milestones) == 0 {
+		return fmt.Errorf("%w: rank_milestone needs milestones (e.g. [100, 10, 1])", ErrInvalidWebhook)
+	}
+	for _, milestone := range sub.Milestones {
+		if milestone < 1 {
+			return fmt.Errorf("%w: milestones must be ranks of at least 1, got %d", ErrInvalidWebhook, milestone)
+		}
+	}
+	if slices.Contains(sub.Events, models.WebhookUserUpdate) && len(sub.UserIDs) == 0 {
+		return fmt.Errorf("%w: user_update needs user_ids", ErrInvalidWebhook)
+	}
+
+	if sub.TopN == 0 {
+		sub.TopN = 10
+	}
+	if sub.TopN < 1 {
+		return fmt.Errorf("%w: top_n must be at least 1, got %d", ErrInvalidWebhook, sub.TopN)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/repository"
+)
+
+// ConnectionStatsService heartbeats this server's WebSocket client count
+// every WS_STATS_INTERVAL and sums the counts of all servers, so stats are
+// right behind a load balancer
+type ConnectionStatsService interface {
+	Start()
+	Stop()
+	Node() string
+	Cluster() (*models.ClusterConnections, error)
+}
+
+type connectionStatsService struct {
+	node      string
+	interval  time.Duration
+	counter   ConnectionCounter
+	statsRepo repository.WSStatsRepository
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+func NewConnectionStatsService(node string, interval time.Duration, counter ConnectionCounter, statsRepo repository.WSStatsRepository) ConnectionStatsService {
+	return &connectionStatsService{
+		node:      node,
+		interval:  interval,
+		counter:   counter,
+		statsRepo: statsRepo,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+func (s *connectionStatsService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.heartbeat()
+			select {
+			case <-ticker.C:
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the heartbeats and drops this server's count, so the cluster
+// total does not wait for it to go stale
+func (s *connectionStatsService) Stop() {
+	s.once.Do(func() {
+		close(s.stopCh)
+		if err := s.statsRepo.Remove(s.node); err != nil {
+			slog.Warn("⚠️  Failed to remove WebSocket stats", "node", s.node, "error", err)
+		}
+	})
+}
+
+func (s *connectionStatsService) Node() string {
+	return s.node
+}
+
+func (s *connectionStatsService) heartbeat() {
+	err := s.statsRepo.Heartbeat(models.InstanceConnections{
+		Node:      s.node,
+		Clients:   s.counter.GetClientCount(),
+		UpdatedAt: time.Now().UTC(),
+	}, s.staleAfter())
+	if err != nil {
+		slog.Warn("⚠️  Failed to share WebSocket stats", "error", err)
+	}
+}
+
+// staleAfter is how long a server's count is trusted without a heartbeat
+func (s *connectionStatsService) staleAfter() time.Duration {
+	return 3 * s.interval
+}
+
+// Cluster sums the counts of every server that sent a heartbeat within
+// three intervals, using this server's live count, and forgets the rest
+func (s *connectionStatsService) Cluster() (*models.ClusterConnections, error) {
+	instances, err := s.statsRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	cluster := &models.ClusterConnections{
+		Instances: []models.InstanceConnections{{Node: s.node, Clients: s.counter.GetClientCount(), UpdatedAt: now}},
+	}
+	var stale []string
+	for _, instance := range instances {
+		switch {
+		case instance.Node == s.node:
+		case now.Sub(instance.UpdatedAt) > s.staleAfter():
+			stale = append(stale, instance.Node)
+		default:
+			cluster.Instances = append(cluster.Instances, instance)
+		}
+	}
+	if err := s.statsRepo.Remove(stale...); err != nil {
+		slog.Warn("⚠️  Failed to remove stale WebSocket stats", "error", err)
+	}
+
+	sort.Slice(cluster.Instances, func(i, j int) bool { return cluster.Instances[i].Node < cluster.Instances[j].Node })
+	for _, instance := range cluster.Instances {
+		cluster.Clients += instance.Clients
+	}
+	return cluster, nil
+}
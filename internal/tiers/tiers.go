@@ -0,0 +1,152 @@
+// Package tiers places ratings on a ladder of named tiers (Bronze, Silver,
+// ...), each but the top one split into equal divisions, so clients can
+// show a player's standing without knowing the boundaries.
+package tiers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+)
+
+// Tier is one step of the ladder: ratings from MinRating up to MaxRating
+type Tier struct {
+	Name      string `json:"name"`
+	MinRating int    `json:"min_rating"`
+	MaxRating int    `json:"max_rating,omitempty"` // inclusive; 0 = no upper bound (top tier)
+	Divisions int    `json:"divisions,omitempty"`  // 0 = not divided (top tier)
+}
+
+// Placement is where a rating sits on the ladder. Division 1 is the
+// highest division of a tier; 0 means the tier is not divided.
+type Placement struct {
+	Tier     string `json:"tier"`
+	Division int    `json:"division,omitempty"`
+}
+
+// Ladder is an ordered set of tiers. A nil ladder places nothing.
+type Ladder struct {
+	tiers []Tier // ascending
+}
+
+// New builds the ladder from "Name:min_rating" boundaries in ascending
+// order; ratings below the first boundary count as the first tier. An
+// empty list disables tiers (nil ladder).
+func New(cfg config.TierConfig) (*Ladder, error) {
+	if len(cfg.Tiers) == 0 {
+		return nil, nil
+	}
+	if cfg.Divisions < 1 {
+		cfg.Divisions = 1
+	}
+
+	ladder := &Ladder{tiers: make([]Tier, 0, len(cfg.Tiers))}
+	for i, spec := range cfg.Tiers {
+		name, minStr, ok := strings.Cut(spec, ":")
+		name = strings.TrimSpace(name)
+		minRating, err := strconv.Atoi(strings.TrimSpace(minStr))
+		if !ok || name == "" || err != nil {
+			return nil, fmt.Errorf("invalid tier %q (use Name:min_rating)", spec)
+		}
+		if _, exists := ladder.Find(name); exists {
+			return nil, fmt.Errorf("duplicate tier %q", name)
+		}
+		if i > 0 {
+			previous := &ladder.tiers[i-1]
+			if minRating <= previous.MinRating {
+				return nil, fmt.Errorf("tier %s must start above %s (%d), got %d", name, previous.Name, previous.MinRating, minRating)
+			}
+			previous.MaxRating = minRating - 1
+			// A division must span at least one rating point
+			previous.Divisions = max(1, min(cfg.Divisions, minRating-previous.MinRating))
+		}
+		ladder.tiers = append(ladder.tiers, Tier{Name: name, MinRating: minRating})
+	}
+
+	// Splitting one division into one is no split
+	for i := range ladder.tiers {
+		if ladder.tiers[i].Divisions == 1 {
+			ladder.tiers[i].Divisions = 0
+		}
+	}
+	return ladder, nil
+}
+
+// Tiers returns the ladder from the lowest tier up
+func (l *Ladder) Tiers() []Tier {
+	if l == nil {
+		return nil
+	}
+	return append([]Tier(nil), l.tiers...)
+}
+
+// Find looks a tier up by name, ignoring case
+func (l *Ladder) Find(name string) (Tier, bool) {
+	if l == nil {
+		return Tier{}, false
+	}
+	for _, tier := range l.tiers {
+		if strings.EqualFold(tier.Name, name) {
+			return tier, true
+		}
+	}
+	return Tier{}, false
+}
+
+// Place returns the tier and division of a rating; the zero Placement
+// when tiers are disabled
+func (l *Ladder) Place(rating int) Placement {
+	if l == nil || len(l.tiers) == 0 {
+		return Placement{}
+	}
+
+	tier := l.tiers[0]
+	for _, t := range l.tiers[1:] {
+		if rating < t.MinRating {
+			break
+		}
+		tier = t
+	}
+	if tier.Divisions == 0 {
+		return Placement{Tier: tier.Name}
+	}
+
+	// Equal slices of the tier, numbered from the top: I is the highest
+	span := tier.MaxRating - tier.MinRating + 1
+	slice := (max(rating, tier.MinRating) - tier.MinRating) * tier.Divisions / span
+	return Placement{Tier: tier.Name, Division: tier.Divisions - slice}
+}
+
+// Compare orders two placements: negative when a is below b, 0 when equal
+func (l *Ladder) Compare(a, b Placement) int {
+	if a == b || l == nil {
+		return 0
+	}
+	indexA, indexB := l.index(a.Tier), l.index(b.Tier)
+	if indexA != indexB {
+		return indexA - indexB
+	}
+	// Within a tier, a lower division number is higher
+	return b.Division - a.Division
+}
+
+func (l *Ladder) index(name string) int {
+	for i, tier := range l.tiers {
+		if tier.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Bounds returns the rating range of a tier, with math.MaxInt32 as the
+// top tier's upper bound
+func (t Tier) Bounds() (int, int) {
+	if t.MaxRating == 0 {
+		return t.MinRating, math.MaxInt32
+	}
+	return t.MinRating, t.MaxRating
+}
@@ -1,7 +1,11 @@
 package websocket
 
 import (
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -17,26 +21,98 @@ const (
 	// Send pings to peer with this period (must be less than pongWait)
 	pingPeriod = (pongWait * 9) / 10
 
-	// Maximum message size allowed from peer
-	maxMessageSize = 512
+	// Maximum message size allowed from peer (a command)
+	maxMessageSize = 4096
 )
 
 // Client represents a WebSocket client connection
 type Client struct {
-	hub  *Hub
-	conn *websocket.Conn
-	send chan []byte
+	hub      *Hub
+	shard    *shard // set by Register
+	conn     *websocket.Conn
+	send     chan []byte
+	encoding Encoding // of the messages in send
+
+	id          string
+	remoteAddr  string
+	userID      uint // end user named by a keyed caller; 0 = unknown
+	connectedAt time.Time
+
+	// Users whose every score update the client receives, even those kept
+	// off the public stream. Set at connect, then changed by subscribe and
+	// unsubscribe commands on the shard's goroutine.
+	watching map[uint]bool
+
+	commands *Commands
+
+	// Messages discarded because the send buffer was full; only touched on
+	// the shard's goroutine
+	dropped uint64
+
+	// Round trip of the last answered ping, in nanoseconds (0 = none yet);
+	// written by ReadPump
+	rtt atomic.Int64
+
+	// Closed when WritePump returns
+	finished chan struct{}
 }
 
-// NewClient creates a new WebSocket client
-func NewClient(hub *Hub, conn *websocket.Conn) *Client {
+// NewClient creates a new WebSocket client for the peer at remoteAddr,
+// acting for userID (0 = unknown), watching the given users and sent
+// messages in encoding. Messages it receives are run as commands.
+func NewClient(hub *Hub, commands *Commands, conn *websocket.Conn, encoding Encoding, remoteAddr string, userID uint, watch []uint) *Client {
+	if hub.cfg.Compression {
+		conn.SetCompressionLevel(hub.cfg.CompressionLevel)
+	}
+
+	watching := make(map[uint]bool, len(watch))
+	for _, userID := range watch {
+		watching[userID] = true
+	}
+
 	return &Client{
-		hub:  hub,
-		conn: conn,
-		send: make(chan []byte, 256),
+		hub:         hub,
+		commands:    commands,
+		conn:        conn,
+		send:        make(chan []byte, hub.cfg.SendBuffer),
+		encoding:    encoding,
+		id:          newClientID(),
+		remoteAddr:  remoteAddr,
+		userID:      userID,
+		connectedAt: time.Now().UTC(),
+		watching:    watching,
+		finished:    make(chan struct{}),
 	}
 }
 
+// closeFrame is the payload of the close frame sent when the hub closes
+// the client: a reconnect hint while the hub shuts down, none otherwise
+func (c *Client) closeFrame() []byte {
+	if !c.hub.closing.Load() {
+		return []byte{}
+	}
+	return websocket.FormatCloseMessage(websocket.CloseServiceRestart, "server restarting, reconnect")
+}
+
+// watchList returns the watched users in ascending order
+func (c *Client) watchList() []uint {
+	if len(c.watching) == 0 {
+		return nil
+	}
+	users := make([]uint, 0, len(c.watching))
+	for userID := range c.watching {
+		users = append(users, userID)
+	}
+	slices.Sort(users)
+	return users
+}
+
+func newClientID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 // ReadPump pumps messages from the WebSocket connection to the hub
 func (c *Client) ReadPump() {
 	defer func() {
@@ -46,54 +122,67 @@ func (c *Client) ReadPump() {
 
 	c.conn.SetReadLimit(maxMessageSize)
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
-	c.conn.SetPongHandler(func(string) error {
+	c.conn.SetPongHandler(func(payload string) error {
 		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.handlePong(payload)
 		return nil
 	})
 
 	for {
-		_, message, err := c.conn.ReadMessage()
+		kind, message, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("⚠️  WebSocket error: %v", err)
+				slog.Debug("⚠️  WebSocket closed unexpectedly", "client", c.id, "error", err)
 			}
 			break
 		}
+		if kind == websocket.BinaryMessage {
+			message = msgpackToJSON(message)
+		}
 
-		// Handle incoming messages if needed
-		// For now, we only broadcast from server to clients
-		log.Printf("📩 Received message from client: %s", message)
+		c.commands.handle(c, message)
 	}
 }
 
-// WritePump pumps messages from the hub to the WebSocket connection
+// WritePump pumps messages from the hub to the WebSocket connection. Pings
+// carry their send time, so the pongs measure the round trip; the first
+// goes out straight away.
 func (c *Client) WritePump() {
 	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
+		c.hub.Release(c.remoteAddr)
+		close(c.finished)
 	}()
 
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := c.conn.WriteMessage(websocket.PingMessage, pingPayload()); err != nil {
+		return
+	}
+
 	for {
 		select {
 		case message, ok := <-c.send:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// Hub closed the channel
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				c.conn.WriteMessage(websocket.CloseMessage, c.closeFrame())
 				return
 			}
 
-			// Send ONE complete JSON message per WebSocket frame
-			// DO NOT batch multiple JSON objects together
-			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
-				log.Printf("⚠️  Failed to write message: %v", err)
+			// Send ONE complete message per WebSocket frame
+			// DO NOT batch multiple messages together. Small messages are
+			// not worth compressing (no-op unless the client negotiated it).
+			c.conn.EnableWriteCompression(len(message) >= c.hub.cfg.CompressionMinSize)
+			if err := c.conn.WriteMessage(c.encoding.frameType(), message); err != nil {
+				slog.Debug("⚠️  Failed to write WebSocket message", "client", c.id, "error", err)
 				return
 			}
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			if err := c.conn.WriteMessage(websocket.PingMessage, pingPayload()); err != nil {
 				return
 			}
 		}
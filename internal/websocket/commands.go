@@ -0,0 +1,173 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+// Commands a client may send as {"id": "...", "type": "...", "payload": {...}}.
+// Each is answered on the same connection with a command_result or a
+// command_error carrying the same id.
+const (
+	CommandGetTop      = "get_top"     // {"limit": 10}; default 100, at most 1000
+	CommandGetMyRank   = "get_my_rank" // {"user_id": 42}; defaults to the connection's user
+	CommandSubscribe   = "subscribe"   // {"user_ids": [1, 2]}: add to the watch list
+	CommandUnsubscribe = "unsubscribe" // {"user_ids": [1, 2]}: remove from the watch list
+	CommandPing        = "ping"
+)
+
+// Codes of command_error messages
+const (
+	CommandErrInvalid    = "invalid_command"
+	CommandErrUnknown    = "unknown_command"
+	CommandErrNotFound   = "not_found"
+	CommandErrWatchLimit = "watch_limit"
+	CommandErrInternal   = "internal_error"
+)
+
+var commandsRun = metrics.NewCounterVec("websocket_commands_total",
+	"Commands received from WebSocket clients, by command and outcome", "command", "outcome")
+
+// Leaderboard answers the read commands; implemented by
+// service.LeaderboardService
+type Leaderboard interface {
+	GetLeaderboard(limit int, viewerID uint, usernames bool) ([]models.LeaderboardEntry, error)
+	GetUserRankAs(userID, viewerID uint) (int64, error)
+}
+
+// Commands runs the commands clients send, so lightweight clients need no
+// REST calls
+type Commands struct {
+	leaderboard Leaderboard
+	maxWatch    int
+}
+
+func NewCommands(leaderboard Leaderboard, maxWatch int) *Commands {
+	return &Commands{
+		leaderboard: leaderboard,
+		maxWatch:    maxWatch,
+	}
+}
+
+// commandError is a failed command's code and message
+type commandError struct {
+	code    string
+	message string
+}
+
+// handle runs a message received from the client and queues the reply
+func (cs *Commands) handle(client *Client, message []byte) {
+	var cmd models.ClientCommand
+	if err := json.Unmarshal(message, &cmd); err != nil || cmd.Type == "" {
+		cs.reply(client, cmd, nil, &commandError{CommandErrInvalid, `Commands are objects like {"id": "1", "type": "ping"}`})
+		return
+	}
+
+	data, cmdErr := cs.run(client, cmd)
+	cs.reply(client, cmd, data, cmdErr)
+}
+
+func (cs *Commands) run(client *Client, cmd models.ClientCommand) (interface{}, *commandError) {
+	switch cmd.Type {
+	case CommandPing:
+		return map[string]int64{"time": time.Now().UnixMilli()}, nil
+
+	case CommandGetTop:
+		var req struct {
+			Limit int `json:"limit"`
+		}
+		if !decode(cmd.Payload, &req) {
+			return nil, &commandError{CommandErrInvalid, "Invalid payload. limit must be a number"}
+		}
+		if req.Limit <= 0 {
+			req.Limit = 100
+		}
+		if req.Limit > 1000 {
+			req.Limit = 1000
+		}
+		entries, err := cs.leaderboard.GetLeaderboard(req.Limit, client.userID, true)
+		if err != nil {
+			return nil, &commandError{CommandErrInternal, "Failed to fetch leaderboard"}
+		}
+		return map[string]interface{}{"count": len(entries), "entries": entries}, nil
+
+	case CommandGetMyRank:
+		var req struct {
+			UserID uint `json:"user_id"`
+		}
+		if !decode(cmd.Payload, &req) {
+			return nil, &commandError{CommandErrInvalid, "Invalid payload. user_id must be a number"}
+		}
+		if req.UserID == 0 {
+			req.UserID = client.userID
+		}
+		if req.UserID == 0 {
+			return nil, &commandError{CommandErrInvalid, "user_id is required when the connection is not for a user"}
+		}
+		rank, err := cs.leaderboard.GetUserRankAs(req.UserID, client.userID)
+		if err != nil {
+			return nil, &commandError{CommandErrNotFound, "User not found in leaderboard"}
+		}
+		return map[string]interface{}{"user_id": req.UserID, "rank": rank}, nil
+
+	case CommandSubscribe, CommandUnsubscribe:
+		var req struct {
+			UserIDs []uint `json:"user_ids"`
+		}
+		if !decode(cmd.Payload, &req) || len(req.UserIDs) == 0 {
+			return nil, &commandError{CommandErrInvalid, "Invalid payload. user_ids must be a non-empty list of user IDs"}
+		}
+		watching, ok := client.hub.updateWatch(client, req.UserIDs, cmd.Type == CommandSubscribe, cs.maxWatch)
+		if !ok {
+			return nil, &commandError{CommandErrWatchLimit, fmt.Sprintf("At most %d users can be watched", cs.maxWatch)}
+		}
+		return map[string]interface{}{"watching": watching}, nil
+
+	default:
+		return nil, &commandError{CommandErrUnknown, "Unknown command " + cmd.Type}
+	}
+}
+
+// reply queues a command_result, or a command_error when cmdErr is set
+func (cs *Commands) reply(client *Client, cmd models.ClientCommand, data interface{}, cmdErr *commandError) {
+	label := cmd.Type
+	switch label {
+	case CommandGetTop, CommandGetMyRank, CommandSubscribe, CommandUnsubscribe, CommandPing:
+	default:
+		label = "unknown" // keep client-chosen names out of the metric
+	}
+
+	message := models.WebSocketMessage{
+		Type:    models.MessageCommandResult,
+		Payload: &models.CommandResultPayload{ID: cmd.ID, Command: cmd.Type, Data: data},
+	}
+	outcome := "ok"
+	if cmdErr != nil {
+		message = models.WebSocketMessage{
+			Type:    models.MessageCommandError,
+			Payload: &models.CommandErrorPayload{ID: cmd.ID, Command: cmd.Type, Code: cmdErr.code, Error: cmdErr.message},
+		}
+		outcome = "error"
+	}
+	commandsRun.WithLabelValues(label, outcome).Inc()
+
+	encoded, err := client.encoding.encode(message)
+	if err != nil {
+		slog.Warn("⚠️  Failed to marshal WebSocket message", "error", err)
+		return
+	}
+	client.hub.reply(client, encoded)
+}
+
+// decode reads an optional command payload
+func decode(payload json.RawMessage, v interface{}) bool {
+	if len(payload) == 0 || string(payload) == "null" {
+		return true
+	}
+	return json.Unmarshal(payload, v) == nil
+}
@@ -0,0 +1,85 @@
+package websocket
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+	"github.com/gorilla/websocket"
+	"github.com/ugorji/go/codec"
+)
+
+// Encoding is how messages are framed on a connection, chosen when it
+// opens with ?encoding= or the Sec-WebSocket-Protocol header
+type Encoding string
+
+const (
+	// EncodingJSON sends JSON text frames (the default)
+	EncodingJSON Encoding = "json"
+
+	// EncodingMsgpack sends MessagePack binary frames: the same {type,
+	// payload} envelope with the same field names as JSON, decodable by
+	// any MessagePack library. Commands may be sent as MessagePack binary
+	// frames or JSON text frames.
+	EncodingMsgpack Encoding = "msgpack"
+)
+
+// Subprotocols are the Sec-WebSocket-Protocol values the server accepts,
+// one per encoding
+var Subprotocols = []string{"leaderboard.json", "leaderboard.msgpack"}
+
+// msgpackHandle writes time.Time as the MessagePack timestamp extension and
+// reads maps as map[string]interface{}, so a decoded command converts back
+// to JSON
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{WriteExt: true}
+	h.RawToString = true
+	h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	return h
+}()
+
+// ParseEncoding reads an encoding name or subprotocol; "" is JSON
+func ParseEncoding(name string) (Encoding, bool) {
+	switch name {
+	case "", string(EncodingJSON), "leaderboard.json":
+		return EncodingJSON, true
+	case string(EncodingMsgpack), "leaderboard.msgpack":
+		return EncodingMsgpack, true
+	}
+	return "", false
+}
+
+// frameType is the WebSocket frame type messages are sent in
+func (e Encoding) frameType() int {
+	if e == EncodingMsgpack {
+		return websocket.BinaryMessage
+	}
+	return websocket.TextMessage
+}
+
+// encode serializes a message in the encoding
+func (e Encoding) encode(message models.WebSocketMessage) ([]byte, error) {
+	if e != EncodingMsgpack {
+		return json.Marshal(message)
+	}
+	var data []byte
+	if err := codec.NewEncoderBytes(&data, msgpackHandle).Encode(message); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// msgpackToJSON converts a MessagePack command to JSON, so commands are
+// handled alike whatever the client sent them in; nil when data is not a
+// MessagePack map
+func msgpackToJSON(data []byte) []byte {
+	var command map[string]interface{}
+	if err := codec.NewDecoderBytes(data, msgpackHandle).Decode(&command); err != nil {
+		return nil
+	}
+	converted, err := json.Marshal(command)
+	if err != nil {
+		return nil
+	}
+	return converted
+}
@@ -1,82 +1,179 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
 	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
 )
 
-// Hub maintains active WebSocket connections and broadcasts messages
+var (
+	batchSize = metrics.NewHistogram("websocket_batch_updates",
+		"Score updates per score_update_batch message", []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000})
+	filteredUpdates = metrics.NewCounter("websocket_filtered_updates_total",
+		"Score updates kept off the public stream and sent only to clients watching the user")
+	droppedMessages = metrics.NewCounter("websocket_dropped_messages_total",
+		"Messages discarded because a client's send buffer was full")
+	slowClients = metrics.NewCounterVec("websocket_slow_clients_total",
+		"Times a client's send buffer was full, by the policy applied", "policy")
+)
+
+// Hub maintains active WebSocket connections and broadcasts messages.
+// Clients are spread over WS_HUB_SHARDS shards, each run by its own
+// goroutine, and a broadcast is handed to every shard at once.
 type Hub struct {
-	// Registered clients
-	clients map[*Client]bool
+	shards []*shard
+
+	// Round-robin counter assigning new clients to shards
+	next atomic.Uint64
+
+	// Clients across all shards
+	connected atomic.Int64
 
-	// Inbound messages from clients
-	broadcast chan []byte
+	// Messages not delivered because a client's send buffer was full
+	dropped atomic.Uint64
 
-	// Register requests from clients
-	register chan *Client
+	// Connection slots reserved by Admit, in total and per client IP
+	admitMu      sync.Mutex
+	admitted     int
+	admittedByIP map[string]int
 
-	// Unregister requests from clients
-	unregister chan *Client
+	// close sets closing and closes stop, ending the shards and the flush
+	// loop
+	stop      chan struct{}
+	closing   atomic.Bool
+	closeOnce sync.Once
+
+	cfg config.WebSocketConfig
+
+	// Score updates waiting for the next flush (WS_COALESCE_WINDOW)
+	pendingMu sync.Mutex
+	pending   []*models.ScoreUpdatePayload
+
+	// Held while a message is handed to the shards, and taken before
+	// pendingMu is let go, so a batch always goes out before any message
+	// that followed its updates and every shard sees messages in the same
+	// order. Updates keep queueing while a slow shard is waited for.
+	sendMu sync.Mutex
+}
 
-	// Mutex for thread-safe operations
-	mu sync.RWMutex
+// outbound is a message for every client, or only for the clients
+// watching userID. data is the message as JSON; shards encode it for
+// MessagePack clients on first use.
+type outbound struct {
+	message models.WebSocketMessage
+	data    []byte
+	packed  []byte
+	userID  uint // 0 = every client
 }
 
-// NewHub creates a new WebSocket hub
-func NewHub() *Hub {
-	return &Hub{
-		clients:    make(map[*Client]bool),
-		broadcast:  make(chan []byte, 256),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+// encoded returns the message in a client's encoding; nil when it cannot
+// be encoded
+func (m *outbound) encoded(encoding Encoding) []byte {
+	if encoding != EncodingMsgpack {
+		return m.data
 	}
+	if m.packed == nil {
+		data, err := encoding.encode(m.message)
+		if err != nil {
+			slog.Warn("⚠️  Failed to encode WebSocket message", "error", err)
+			data = []byte{}
+		}
+		m.packed = data
+	}
+	if len(m.packed) == 0 {
+		return nil
+	}
+	return m.packed
 }
 
-// Run starts the hub's main loop
-func (h *Hub) Run() {
-	for {
+// ClientInfo describes a connected client for administration
+type ClientInfo struct {
+	ID          string    `json:"id"`
+	RemoteAddr  string    `json:"remote_addr"`
+	UserID      uint      `json:"user_id,omitempty"` // user it connected for, when known
+	ConnectedAt time.Time `json:"connected_at"`
+	Encoding    Encoding  `json:"encoding"`
+	Queued      int       `json:"queued"`             // messages waiting in its send buffer
+	Watching    []uint    `json:"watching,omitempty"` // users whose every update it receives
+	Dropped     uint64    `json:"dropped"`            // messages discarded because it fell behind
+	RTT         float64   `json:"rtt_ms,omitempty"`   // last ping round trip, once measured
+}
+
+// NewHub creates a new WebSocket hub. With a coalescing window, score
+// updates are sent as one score_update_batch per window instead of a frame
+// each. With WS_BROADCAST_TOP_N or WS_BROADCAST_MIN_RANK_DELTA, updates
+// that pass neither only reach the clients watching their user.
+func NewHub(cfg config.WebSocketConfig) *Hub {
+	h := &Hub{cfg: cfg, stop: make(chan struct{}), admittedByIP: make(map[string]int)}
+	h.shards = make([]*shard, max(cfg.Shards, 1))
+	for i := range h.shards {
+		h.shards[i] = newShard(h)
+	}
+	return h
+}
+
+// Run starts the hub's shards and blocks until ctx is cancelled or
+// Shutdown is called. Either way every client is sent the reconnect close
+// frame, and Run returns once all shards have stopped; it does not wait
+// for the frames to be written, Shutdown does.
+func (h *Hub) Run(ctx context.Context) {
+	go func() {
 		select {
-		case client := <-h.register:
-			h.mu.Lock()
-			h.clients[client] = true
-			count := len(h.clients)
-			h.mu.Unlock()
-			log.Printf("✅ WebSocket client connected (total: %d)", count)
-
-		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-			}
-			count := len(h.clients)
-			h.mu.Unlock()
-			log.Printf("❌ WebSocket client disconnected (total: %d)", count)
-
-		case message := <-h.broadcast:
-			h.mu.Lock()
-			// We're potentially modifying the map (deleting failed clients)
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-					// Successfully sent
-				default:
-					// Client's send buffer is full, remove client
-					close(client.send)
-					delete(h.clients, client)
-				}
-			}
-			h.mu.Unlock()
+		case <-ctx.Done():
+			h.close()
+		case <-h.stop:
 		}
+	}()
+
+	if h.cfg.CoalesceWindow > 0 {
+		go h.flushLoop()
+	}
+	for _, shard := range h.shards[1:] {
+		go shard.run()
+	}
+	h.shards[0].run()
+	for _, shard := range h.shards[1:] {
+		<-shard.done
 	}
 }
 
-// BroadcastScoreUpdate sends score update to all connected clients
+// close sends the pending batch and stops the shards, once
+func (h *Hub) close() {
+	h.closeOnce.Do(func() {
+		h.closing.Store(true)
+		h.flush(nil)
+		close(h.stop)
+	})
+}
+
+// BroadcastScoreUpdate sends score update to all connected clients, or
+// queues it for the next batch when coalescing. Updates filtered out of
+// the public stream go only to the clients watching the user, unbatched.
 func (h *Hub) BroadcastScoreUpdate(payload *models.ScoreUpdatePayload) {
+	public := h.public(payload)
+	if !public {
+		filteredUpdates.Inc()
+	}
+	if public && h.cfg.CoalesceWindow > 0 {
+		h.pendingMu.Lock()
+		h.pending = append(h.pending, payload)
+		full := len(h.pending) >= h.cfg.MaxBatch
+		h.pendingMu.Unlock()
+		if full {
+			h.flush(nil)
+		}
+		return
+	}
+
 	message := models.WebSocketMessage{
 		Type:    "score_update",
 		Payload: payload,
@@ -84,42 +181,306 @@ func (h *Hub) BroadcastScoreUpdate(payload *models.ScoreUpdatePayload) {
 
 	data, err := json.Marshal(message)
 	if err != nil {
-		log.Printf("⚠️  Failed to marshal WebSocket message: %v", err)
+		slog.Warn("⚠️  Failed to marshal WebSocket message", "type", message.Type, "error", err)
 		return
 	}
 
-	h.broadcast <- data
+	if public {
+		h.send(outbound{message: message, data: data})
+	} else {
+		h.send(outbound{message: message, data: data, userID: payload.UserID})
+	}
+}
+
+// public reports whether an update goes to every client: it moved a user
+// into, within or out of the top WS_BROADCAST_TOP_N, or moved them at
+// least WS_BROADCAST_MIN_RANK_DELTA places. Without either setting every
+// update is public.
+func (h *Hub) public(payload *models.ScoreUpdatePayload) bool {
+	topN, minDelta := int64(h.cfg.BroadcastTopN), int64(h.cfg.MinRankDelta)
+	if topN == 0 && minDelta == 0 {
+		return true
+	}
+
+	// Rank 0 means unranked (not on the public board)
+	inTop := func(rank int64) bool { return rank >= 1 && rank <= topN }
+	if topN > 0 && (inTop(payload.NewRank) || inTop(payload.OldRank)) {
+		return true
+	}
+	delta := payload.RankDelta
+	if delta < 0 {
+		delta = -delta
+	}
+	return minDelta > 0 && delta >= minDelta
+}
+
+// flushLoop sends the queued score updates once per coalesce window. It
+// runs apart from the shards, which drain the channels it writes to.
+func (h *Hub) flushLoop() {
+	ticker := time.NewTicker(h.cfg.CoalesceWindow)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.flush(nil)
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// flush sends the pending score updates as one score_update_batch, then
+// message when there is one. The batch is taken under pendingMu and
+// handed to the shards under sendMu only.
+func (h *Hub) flush(message *outbound) {
+	h.pendingMu.Lock()
+	batch := h.pending
+	h.pending = nil
+	h.sendMu.Lock()
+	h.pendingMu.Unlock()
+	defer h.sendMu.Unlock()
+
+	if len(batch) > 0 {
+		batchMessage := models.WebSocketMessage{
+			Type:    models.MessageScoreUpdateBatch,
+			Payload: batch,
+		}
+		if data, err := json.Marshal(batchMessage); err != nil {
+			slog.Warn("⚠️  Failed to marshal WebSocket message", "type", batchMessage.Type, "error", err)
+		} else {
+			batchSize.Observe(float64(len(batch)))
+			h.fanOut(outbound{message: batchMessage, data: data})
+		}
+	}
+	if message != nil {
+		h.fanOut(*message)
+	}
+}
+
+// send queues a message after any pending batch
+func (h *Hub) send(message outbound) {
+	h.flush(&message)
+}
+
+// fanOut hands a message to every shard; sendMu must be held
+func (h *Hub) fanOut(message outbound) {
+	for _, shard := range h.shards {
+		select {
+		case shard.broadcast <- message:
+		case <-shard.done:
+		}
+	}
 }
 
 // BroadcastLeaderboardUpdate sends full leaderboard refresh signal
 func (h *Hub) BroadcastLeaderboardUpdate() {
 	message := models.WebSocketMessage{
 		Type:    "leaderboard_refresh",
-		Payload: map[string]string{"action": "refresh"},
+		Payload: models.LeaderboardRefreshPayload{Action: "refresh"},
 	}
 
 	data, err := json.Marshal(message)
 	if err != nil {
-		log.Printf("⚠️  Failed to marshal WebSocket message: %v", err)
+		slog.Warn("⚠️  Failed to marshal WebSocket message", "type", message.Type, "error", err)
 		return
 	}
 
-	h.broadcast <- data
+	h.send(outbound{message: message, data: data})
+}
+
+// BroadcastEvent sends a typed event to all connected clients
+func (h *Hub) BroadcastEvent(eventType string, payload interface{}) {
+	message := models.WebSocketMessage{
+		Type:    eventType,
+		Payload: payload,
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		slog.Warn("⚠️  Failed to marshal WebSocket message", "type", message.Type, "error", err)
+		return
+	}
+
+	h.send(outbound{message: message, data: data})
 }
 
 // GetClientCount returns the number of connected clients
 func (h *Hub) GetClientCount() int {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	return len(h.clients)
+	return int(h.connected.Load())
+}
+
+// DroppedMessages returns how many messages were dropped for slow clients
+func (h *Hub) DroppedMessages() uint64 {
+	return h.dropped.Load()
+}
+
+// each calls fn for every connected client, one shard at a time on the
+// shard's goroutine
+func (h *Hub) each(fn func(client *Client)) {
+	for _, shard := range h.shards {
+		shard.do(func(clients map[*Client]bool) {
+			for client := range clients {
+				fn(client)
+			}
+		})
+	}
+}
+
+// Clients lists the connected clients, oldest first
+func (h *Hub) Clients() []ClientInfo {
+	clients := make([]ClientInfo, 0, h.GetClientCount())
+	h.each(func(client *Client) {
+		clients = append(clients, ClientInfo{
+			ID:          client.id,
+			RemoteAddr:  client.remoteAddr,
+			UserID:      client.userID,
+			ConnectedAt: client.connectedAt,
+			Encoding:    client.encoding,
+			Queued:      len(client.send),
+			Watching:    client.watchList(),
+			Dropped:     client.dropped,
+			RTT:         round(float64(client.rtt.Load()) / float64(time.Millisecond)),
+		})
+	})
+
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ConnectedAt.Before(clients[j].ConnectedAt)
+	})
+	return clients
+}
+
+// Disconnect closes the client with the given ID; false if it is not connected
+func (h *Hub) Disconnect(id string) bool {
+	var target *Client
+	h.each(func(client *Client) {
+		if client.id == id {
+			target = client
+		}
+	})
+
+	if target == nil {
+		return false
+	}
+	h.Unregister(target) // closing send makes WritePump close the connection
+	return true
+}
+
+// reply queues a message for one client on its shard, unless it has left
+func (h *Hub) reply(client *Client, data []byte) {
+	client.shard.do(func(clients map[*Client]bool) {
+		if !clients[client] {
+			return
+		}
+		select {
+		case client.send <- data:
+		default:
+			client.shard.slow(client, data)
+		}
+	})
 }
 
-// Register adds a client to the hub
+// updateWatch adds users to or removes them from a client's watch list, on
+// its shard, and returns the new list. Nothing changes when adding would
+// take the list past max.
+func (h *Hub) updateWatch(client *Client, userIDs []uint, add bool, max int) ([]uint, bool) {
+	var watching []uint
+	ok := true
+	client.shard.do(func(clients map[*Client]bool) {
+		next := make(map[uint]bool, len(client.watching)+len(userIDs))
+		for userID := range client.watching {
+			next[userID] = true
+		}
+		for _, userID := range userIDs {
+			if add && userID != 0 {
+				next[userID] = true
+			} else {
+				delete(next, userID)
+			}
+		}
+		if add && len(next) > max {
+			ok = false
+		} else {
+			client.watching = next
+		}
+		watching = client.watchList()
+	})
+	return watching, ok
+}
+
+// DisconnectUser closes every client connected for userID and returns how
+// many there were
+func (h *Hub) DisconnectUser(userID uint) int {
+	var clients []*Client
+	h.each(func(client *Client) {
+		if client.userID == userID {
+			clients = append(clients, client)
+		}
+	})
+
+	for _, client := range clients {
+		h.Unregister(client)
+	}
+	return len(clients)
+}
+
+// DisconnectAll closes every client connection and returns how many there were
+func (h *Hub) DisconnectAll() int {
+	var clients []*Client
+	h.each(func(client *Client) {
+		clients = append(clients, client)
+	})
+
+	for _, client := range clients {
+		h.Unregister(client)
+	}
+	return len(clients)
+}
+
+// Register adds a client to the next shard in turn. After Shutdown the
+// client is closed straight away, with the same reconnect hint.
 func (h *Hub) Register(client *Client) {
-	h.register <- client
+	client.shard = h.shards[(h.next.Add(1)-1)%uint64(len(h.shards))]
+	select {
+	case client.shard.register <- client:
+	case <-client.shard.done:
+		close(client.send)
+	}
 }
 
 // Unregister removes a client from the hub
 func (h *Hub) Unregister(client *Client) {
-	h.unregister <- client
-}
\ No newline at end of file
+	select {
+	case client.shard.unregister <- client:
+	case <-client.shard.done:
+	}
+}
+
+// Shutdown sends every client a close frame asking it to reconnect (1012
+// service restart) once the messages already queued for it are written,
+// and waits for their WritePumps to finish or ctx to end. It stops the
+// hub's goroutines, so Run returns. Calling it after Run's context was
+// cancelled only waits.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.close()
+
+	var clients []*Client
+	for _, shard := range h.shards {
+		select {
+		case <-shard.done:
+			clients = append(clients, shard.closed...)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for i, client := range clients {
+		select {
+		case <-client.finished:
+		case <-ctx.Done():
+			return fmt.Errorf("%d of %d WebSocket clients still closing: %w", len(clients)-i, len(clients), ctx.Err())
+		}
+	}
+	slog.Info("👋 Closed WebSocket clients", "clients", len(clients))
+	return nil
+}
@@ -0,0 +1,145 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+// testClient is a client with no connection; what the hub sends it waits
+// in its send buffer
+func testClient(hub *Hub, buffer int) *Client {
+	return &Client{
+		hub:      hub,
+		send:     make(chan []byte, buffer),
+		encoding: EncodingJSON,
+		id:       newClientID(),
+		finished: make(chan struct{}),
+	}
+}
+
+// runHub starts hub and returns a func that stops it and waits until every
+// shard has delivered what it was handed
+func runHub(hub *Hub) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		hub.Run(ctx)
+		close(done)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestHubSendsBatchBeforeLaterMessage(t *testing.T) {
+	hub := NewHub(config.WebSocketConfig{
+		CoalesceWindow:   time.Hour,
+		MaxBatch:         100,
+		Shards:           4,
+		SlowClientPolicy: config.SlowClientDisconnect,
+	})
+	stop := runHub(hub)
+
+	clients := make([]*Client, 8)
+	for i := range clients {
+		clients[i] = testClient(hub, 16)
+		hub.Register(clients[i])
+	}
+	hub.BroadcastScoreUpdate(&models.ScoreUpdatePayload{UserID: 1, NewRating: 1500})
+	hub.BroadcastScoreUpdate(&models.ScoreUpdatePayload{UserID: 2, NewRating: 1600})
+	hub.BroadcastLeaderboardUpdate()
+	stop()
+
+	for _, client := range clients {
+		var types []string
+		for data := range client.send {
+			var message struct{ Type string }
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("client %s got invalid JSON: %v", client.id, err)
+			}
+			types = append(types, message.Type)
+		}
+		if len(types) != 2 || types[0] != models.MessageScoreUpdateBatch || types[1] != "leaderboard_refresh" {
+			t.Errorf("client %s got %v, want [%s leaderboard_refresh]", client.id, types, models.MessageScoreUpdateBatch)
+		}
+	}
+}
+
+func TestHubFlushesFullBatch(t *testing.T) {
+	hub := NewHub(config.WebSocketConfig{
+		CoalesceWindow:   time.Hour,
+		MaxBatch:         3,
+		Shards:           2,
+		SlowClientPolicy: config.SlowClientDisconnect,
+	})
+	stop := runHub(hub)
+
+	client := testClient(hub, 16)
+	hub.Register(client)
+	for userID := uint(1); userID <= 3; userID++ {
+		hub.BroadcastScoreUpdate(&models.ScoreUpdatePayload{UserID: userID})
+	}
+
+	select {
+	case data := <-client.send:
+		var message struct {
+			Type    string
+			Payload []models.ScoreUpdatePayload
+		}
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatal(err)
+		}
+		if message.Type != models.MessageScoreUpdateBatch || len(message.Payload) != 3 {
+			t.Errorf("got %s with %d updates, want %s with 3", message.Type, len(message.Payload), models.MessageScoreUpdateBatch)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("full batch was not sent")
+	}
+	stop()
+}
+
+// BenchmarkHubBroadcast measures score updates broadcast to 50k clients,
+// one frame each and coalesced, from parallel publishers. Clients keep
+// one queued message (drop_oldest), so the shards never wait on them.
+func BenchmarkHubBroadcast(b *testing.B) {
+	const connections = 50000
+
+	for _, bc := range []struct {
+		name   string
+		window time.Duration
+	}{
+		{"direct", 0},
+		{"coalesced", 50 * time.Millisecond},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			hub := NewHub(config.WebSocketConfig{
+				CoalesceWindow:   bc.window,
+				MaxBatch:         500,
+				Shards:           runtime.GOMAXPROCS(0),
+				SendBuffer:       1,
+				SlowClientPolicy: config.SlowClientDropOldest,
+			})
+			stop := runHub(hub)
+			for range connections {
+				hub.Register(testClient(hub, 1))
+			}
+			payload := &models.ScoreUpdatePayload{UserID: 1, NewRating: 1500, NewRank: 1}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					hub.BroadcastScoreUpdate(payload)
+				}
+			})
+			stop()
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package websocket
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+)
+
+var pingRTT = metrics.NewHistogram("websocket_ping_rtt_seconds",
+	"Round trip time of WebSocket pings, from ping sent to pong received",
+	[]float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10})
+
+// LatencyStats summarises the last ping round trip of every client that
+// has answered one, in milliseconds
+type LatencyStats struct {
+	Measured int     `json:"measured"` // clients with a round trip so far
+	Mean     float64 `json:"mean_ms"`
+	P50      float64 `json:"p50_ms"`
+	P95      float64 `json:"p95_ms"`
+	P99      float64 `json:"p99_ms"`
+	Max      float64 `json:"max_ms"`
+}
+
+// pingPayload stamps a ping with the time it was sent, which the peer
+// echoes in its pong
+func pingPayload() []byte {
+	return strconv.AppendInt(nil, time.Now().UnixNano(), 10)
+}
+
+// handlePong records the round trip of the ping a pong answers. Pongs
+// that do not echo a ping sent within pongWait are ignored, so a client
+// cannot report a made-up latency.
+func (c *Client) handlePong(payload string) {
+	sent, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return
+	}
+	rtt := time.Since(time.Unix(0, sent))
+	if rtt < 0 || rtt > pongWait {
+		return
+	}
+	c.rtt.Store(int64(rtt))
+	pingRTT.Observe(rtt.Seconds())
+}
+
+// Latency returns the ping round trip stats of this server's clients.
+// Together with canary_broadcast_latency_seconds, which times the server
+// side, it tells network lag from slow broadcasts.
+func (h *Hub) Latency() LatencyStats {
+	var rtts []float64
+	h.each(func(client *Client) {
+		if rtt := client.rtt.Load(); rtt > 0 {
+			rtts = append(rtts, float64(rtt)/float64(time.Millisecond))
+		}
+	})
+	if len(rtts) == 0 {
+		return LatencyStats{}
+	}
+
+	slices.Sort(rtts)
+	sum := 0.0
+	for _, rtt := range rtts {
+		sum += rtt
+	}
+	return LatencyStats{
+		Measured: len(rtts),
+		Mean:     round(sum / float64(len(rtts))),
+		P50:      round(quantile(rtts, 0.50)),
+		P95:      round(quantile(rtts, 0.95)),
+		P99:      round(quantile(rtts, 0.99)),
+		Max:      round(rtts[len(rtts)-1]),
+	}
+}
+
+// quantile reads the q-th quantile from sorted samples (nearest rank)
+func quantile(sorted []float64, q float64) float64 {
+	index := int(math.Ceil(float64(len(sorted))*q)) - 1
+	return sorted[max(index, 0)]
+}
+
+// round keeps two decimals of a millisecond figure
+func round(ms float64) float64 {
+	return math.Round(ms*100) / 100
+}
@@ -0,0 +1,56 @@
+package websocket
+
+import (
+	"errors"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+)
+
+var (
+	ErrServerFull        = errors.New("too many WebSocket connections on this server")
+	ErrTooManyFromClient = errors.New("too many WebSocket connections from this address")
+)
+
+// Machine-readable reasons of refused connections
+const (
+	CodeServerFull        = "ws_server_full"
+	CodeTooManyFromClient = "ws_too_many_connections"
+)
+
+var rejectedConnections = metrics.NewCounterVec("websocket_rejected_connections_total",
+	"WebSocket connections refused by WS_MAX_CONNECTIONS or WS_MAX_CONNECTIONS_PER_IP", "reason")
+
+// Admit reserves a connection slot for a client at ip, within
+// WS_MAX_CONNECTIONS and WS_MAX_CONNECTIONS_PER_IP (0 = no limit). The slot
+// is freed by Release, which WritePump calls when the connection ends;
+// call it yourself if the upgrade fails.
+func (h *Hub) Admit(ip string) error {
+	h.admitMu.Lock()
+	defer h.admitMu.Unlock()
+
+	if h.cfg.MaxConnections > 0 && h.admitted >= h.cfg.MaxConnections {
+		rejectedConnections.WithLabelValues(CodeServerFull).Inc()
+		return ErrServerFull
+	}
+	if h.cfg.MaxConnectionsPerIP > 0 && h.admittedByIP[ip] >= h.cfg.MaxConnectionsPerIP {
+		rejectedConnections.WithLabelValues(CodeTooManyFromClient).Inc()
+		return ErrTooManyFromClient
+	}
+	h.admitted++
+	h.admittedByIP[ip]++
+	return nil
+}
+
+// Release frees the slot Admit reserved for ip
+func (h *Hub) Release(ip string) {
+	h.admitMu.Lock()
+	defer h.admitMu.Unlock()
+
+	if h.admittedByIP[ip] == 0 {
+		return
+	}
+	h.admitted--
+	if h.admittedByIP[ip]--; h.admittedByIP[ip] == 0 {
+		delete(h.admittedByIP, ip)
+	}
+}
@@ -0,0 +1,137 @@
+package websocket
+
+import (
+	"sync"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/jsonschema"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+// ProtocolVersion is the version of the server -> client message protocol.
+// Bump the minor version when adding message types or optional fields and
+// the major version for anything a client could trip over.
+const ProtocolVersion = "1.11.0"
+
+// MessageType documents one message type sent to WebSocket clients
+type MessageType struct {
+	Type        string
+	Description string
+	Payload     interface{}
+}
+
+// Messages lists every message type the hub sends. Keep in sync with the
+// events relayed to clients in cmd/server/main.go.
+var Messages = []MessageType{
+	{
+		Type:        models.EventScoreUpdate,
+		Description: "A user's rating changed; ranks are tie-aware and rank_delta > 0 means the user moved up. With WS_BROADCAST_TOP_N or WS_BROADCAST_MIN_RANK_DELTA set, updates outside them only reach clients watching the user (?watch=)",
+		Payload:     models.ScoreUpdatePayload{},
+	},
+	{
+		Type:        models.MessageScoreUpdateBatch,
+		Description: "Score updates of one coalescing window (WS_COALESCE_WINDOW), oldest first; sent instead of score_update when coalescing is on, and always before any message that followed them",
+		Payload:     []models.ScoreUpdatePayload{},
+	},
+	{
+		Type:        models.MessageLagged,
+		Description: "The client fell behind and its queued messages were discarded (WS_SLOW_CLIENT_POLICY=lagged); refetch GET /api/leaderboard and any ranks shown, then carry on applying updates",
+		Payload:     models.LaggedPayload{},
+	},
+	{
+		Type:        models.MessageCommandResult,
+		Description: "The answer to a command the client sent (get_top, get_my_rank, subscribe, unsubscribe, ping), carrying the command's id",
+		Payload:     models.CommandResultPayload{},
+	},
+	{
+		Type:        models.MessageCommandError,
+		Description: "A command the client sent failed (codes: invalid_command, unknown_command, not_found, watch_limit, internal_error), carrying the command's id",
+		Payload:     models.CommandErrorPayload{},
+	},
+	{
+		Type:        "leaderboard_refresh",
+		Description: "The leaderboard changed in bulk; refetch GET /api/leaderboard",
+		Payload:     models.LeaderboardRefreshPayload{},
+	},
+	{
+		Type:        models.MessageLeaderboardDiff,
+		Description: "Rows of the top N that changed since the previous diff (new to the top, or with a new rank, rating or username) and the users who left it; patch the board instead of refetching. Changes are folded over a short interval.",
+		Payload:     models.LeaderboardDiffPayload{},
+	},
+	{
+		Type:        models.EventUserRenamed,
+		Description: "A user changed their username; relabel any rows showing user_id",
+		Payload:     models.UserRenamedPayload{},
+	},
+	{
+		Type:        models.EventUserRemoved,
+		Description: "A user was deleted, banned or purged; drop them from any list",
+		Payload:     models.UserRemovedPayload{},
+	},
+	{
+		Type:        models.EventLeaderboardReset,
+		Description: "An admin reset the leaderboard (ratings may all have changed); refetch GET /api/leaderboard and any ranks shown",
+		Payload:     models.LeaderboardResetPayload{},
+	},
+	{
+		Type:        models.EventMilestone,
+		Description: "A score update took a user into a top N (kind top_rank, threshold = N; only the smallest N entered) past their highest rating so far (kind personal_best) or to an N-day streak of rating gains (kind streak, threshold = N); follows that update's score_update",
+		Payload:     models.MilestonePayload{},
+	},
+	{
+		Type:        models.EventPromotion,
+		Description: "A score update moved a user up to a higher tier or division (division 1 is the highest; omitted for undivided tiers); follows that update's score_update",
+		Payload:     models.TierChangePayload{},
+	},
+	{
+		Type:        models.EventDemotion,
+		Description: "A score update moved a user down to a lower tier or division; follows that update's score_update",
+		Payload:     models.TierChangePayload{},
+	},
+	{
+		Type:        models.EventAchievement,
+		Description: "A score update unlocked an achievement for the first time (first_win, high_rating or win_streak; threshold is the rating or streak reached); follows that update's score_update",
+		Payload:     models.AchievementPayload{},
+	},
+	{
+		Type:        models.EventAnnouncement,
+		Description: "A message from an admin to every client, e.g. planned maintenance; show it to the player",
+		Payload:     models.AnnouncementPayload{},
+	},
+}
+
+var (
+	schemaOnce sync.Once
+	schemaDoc  jsonschema.Schema
+)
+
+// Schema returns a JSON Schema that validates any message sent to clients:
+// a `oneOf` over the {type, payload} envelope of each message type
+func Schema() jsonschema.Schema {
+	schemaOnce.Do(func() {
+		defs := jsonschema.Schema{}
+		oneOf := make([]jsonschema.Schema, 0, len(Messages))
+
+		for _, msg := range Messages {
+			defs[msg.Type] = jsonschema.Schema{
+				"description": msg.Description,
+				"type":        "object",
+				"properties": jsonschema.Schema{
+					"type":    jsonschema.Schema{"const": msg.Type},
+					"payload": jsonschema.Reflect(msg.Payload),
+				},
+				"required":             []string{"type", "payload"},
+				"additionalProperties": false,
+			}
+			oneOf = append(oneOf, jsonschema.Schema{"$ref": "#/$defs/" + msg.Type})
+		}
+
+		schemaDoc = jsonschema.Schema{
+			"$schema": jsonschema.Draft,
+			"title":   "Leaderboard WebSocket messages",
+			"version": ProtocolVersion,
+			"oneOf":   oneOf,
+			"$defs":   defs,
+		}
+	})
+	return schemaDoc
+}
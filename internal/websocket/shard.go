@@ -0,0 +1,178 @@
+package websocket
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/config"
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/models"
+)
+
+// shard owns a share of the hub's clients. Only its own goroutine touches
+// its client map, so shards deliver a broadcast concurrently and no lock is
+// held across the clients while writing to them.
+type shard struct {
+	hub        *Hub
+	clients    map[*Client]bool
+	broadcast  chan outbound
+	register   chan *Client
+	unregister chan *Client
+	inspect    chan func(clients map[*Client]bool)
+
+	// Closed once the shard has stopped; closed lists the clients it
+	// closed on the way out
+	done   chan struct{}
+	closed []*Client
+}
+
+func newShard(hub *Hub) *shard {
+	return &shard{
+		hub:        hub,
+		clients:    make(map[*Client]bool),
+		broadcast:  make(chan outbound, 256),
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		inspect:    make(chan func(clients map[*Client]bool)),
+		done:       make(chan struct{}),
+	}
+}
+
+// run is the shard's loop; it owns the client map
+func (s *shard) run() {
+	for {
+		select {
+		case client := <-s.register:
+			s.clients[client] = true
+			slog.Debug("✅ WebSocket client connected", "client", client.id, "total", s.hub.connected.Add(1))
+
+		case client := <-s.unregister:
+			if _, ok := s.clients[client]; ok {
+				s.remove(client)
+			}
+			slog.Debug("❌ WebSocket client disconnected", "client", client.id, "total", s.hub.connected.Load())
+
+		case message := <-s.broadcast:
+			s.deliver(message)
+
+		case fn := <-s.inspect:
+			fn(s.clients)
+
+		case <-s.hub.stop:
+			s.shutdown()
+			return
+		}
+	}
+}
+
+// deliver queues a message for its clients, each in its own encoding
+func (s *shard) deliver(message outbound) {
+	for client := range s.clients {
+		if message.userID != 0 && !client.watching[message.userID] {
+			continue
+		}
+		data := message.encoded(client.encoding)
+		if data == nil {
+			continue
+		}
+		select {
+		case client.send <- data:
+			// Successfully sent
+		default:
+			s.slow(client, data)
+		}
+	}
+}
+
+// shutdown delivers the messages already handed to the shard, then closes
+// every client's send channel so its WritePump sends the close frame
+func (s *shard) shutdown() {
+drain:
+	for {
+		select {
+		case message := <-s.broadcast:
+			s.deliver(message)
+		default:
+			break drain
+		}
+	}
+
+	for client := range s.clients {
+		s.closed = append(s.closed, client)
+		s.remove(client)
+	}
+	close(s.done)
+}
+
+// slow applies WS_SLOW_CLIENT_POLICY to a client whose send buffer is
+// full. The shard is the only sender on client.send, so once a slot is
+// freed the next send cannot block.
+func (s *shard) slow(client *Client, data []byte) {
+	policy := s.hub.cfg.SlowClientPolicy
+	slowClients.WithLabelValues(policy).Inc()
+
+	switch policy {
+	case config.SlowClientDropOldest:
+		select {
+		case <-client.send:
+		default:
+			// WritePump emptied the buffer meanwhile
+		}
+		client.send <- data
+		s.countDropped(client, 1)
+
+	case config.SlowClientLagged:
+		dropped := 1 // data itself
+	drain:
+		for {
+			select {
+			case <-client.send:
+				dropped++
+			default:
+				break drain
+			}
+		}
+		notice, err := client.encoding.encode(models.WebSocketMessage{
+			Type:    models.MessageLagged,
+			Payload: models.LaggedPayload{Dropped: dropped, Timestamp: time.Now().Unix()},
+		})
+		if err != nil {
+			slog.Warn("⚠️  Failed to marshal WebSocket message", "error", err)
+			return
+		}
+		client.send <- notice
+		s.countDropped(client, dropped)
+
+	default:
+		s.countDropped(client, 1)
+		s.remove(client)
+	}
+}
+
+// countDropped counts messages discarded for a client
+func (s *shard) countDropped(client *Client, n int) {
+	client.dropped += uint64(n)
+	s.hub.dropped.Add(uint64(n))
+	droppedMessages.Add(float64(n))
+}
+
+// remove closes a client's send channel, which makes WritePump close the
+// connection
+func (s *shard) remove(client *Client) {
+	close(client.send)
+	delete(s.clients, client)
+	s.hub.connected.Add(-1)
+}
+
+// do runs fn on the shard's goroutine and waits for it; a stopped shard
+// has no clients, so fn is skipped
+func (s *shard) do(fn func(clients map[*Client]bool)) {
+	finished := make(chan struct{})
+	select {
+	case s.inspect <- func(clients map[*Client]bool) {
+		fn(clients)
+		close(finished)
+	}:
+		<-finished
+	case <-s.done:
+	}
+}
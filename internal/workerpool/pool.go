@@ -0,0 +1,126 @@
+// Package workerpool runs per-item work for large responses on a fixed set
+// of goroutines shared by all requests.
+package workerpool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SSujoy-Samanta/leaderboard-backend/internal/metrics"
+)
+
+// Below this many items a Map runs inline; coordination would cost more than it saves
+const minParallelItems = 32
+
+var (
+	poolBusy = metrics.NewGauge("workerpool_busy_workers",
+		"Worker pool goroutines currently running a chunk")
+	poolInline = metrics.NewCounter("workerpool_inline_chunks_total",
+		"Chunks run on the calling goroutine because the pool was saturated")
+	poolMapDuration = metrics.NewHistogram("workerpool_map_seconds",
+		"Wall time of Map calls that used the pool", nil)
+)
+
+// Pool is a fixed-size set of workers. Each Map call is split into at most
+// `parallelism` chunks, so one large request can use only part of the pool.
+type Pool struct {
+	tasks       chan func()
+	parallelism int
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+// New starts a pool with the given number of workers and per-call parallelism
+func New(workers, parallelism int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	p := &Pool{
+		tasks:       make(chan func()),
+		parallelism: parallelism,
+		stopCh:      make(chan struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+func (p *Pool) worker() {
+	for {
+		select {
+		case task := <-p.tasks:
+			poolBusy.Inc()
+			task()
+			poolBusy.Dec()
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+// Stop shuts the workers down; later Map calls run inline
+func (p *Pool) Stop() {
+	p.once.Do(func() { close(p.stopCh) })
+}
+
+// Map calls fn(i) for every i in [0, n) and returns when all calls are done.
+// Items are split into contiguous chunks; the caller runs the first chunk
+// itself, and any chunk no idle worker can take is also run by the caller
+// (so nested or concurrent calls never deadlock or spawn goroutines).
+func (p *Pool) Map(n int, fn func(i int)) {
+	if p == nil || n < minParallelItems || p.parallelism == 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	start := time.Now()
+	defer func() { poolMapDuration.Observe(time.Since(start).Seconds()) }()
+
+	chunks := p.parallelism
+	if chunks > n {
+		chunks = n
+	}
+	size := (n + chunks - 1) / chunks
+
+	var wg sync.WaitGroup
+	runChunk := func(from, to int) {
+		for i := from; i < to; i++ {
+			fn(i)
+		}
+	}
+
+	for from := size; from < n; from += size {
+		to := from + size
+		if to > n {
+			to = n
+		}
+
+		wg.Add(1)
+		task := func(from, to int) func() {
+			return func() {
+				defer wg.Done()
+				runChunk(from, to)
+			}
+		}(from, to)
+
+		select {
+		case p.tasks <- task:
+		case <-p.stopCh:
+			task()
+		default:
+			poolInline.Inc()
+			task()
+		}
+	}
+
+	runChunk(0, min(size, n))
+	wg.Wait()
+}
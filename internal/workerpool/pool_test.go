@@ -0,0 +1,155 @@
+package workerpool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mapCounts runs Map and returns how often each item was visited
+func mapCounts(p *Pool, n int) []int32 {
+	counts := make([]int32, n)
+	p.Map(n, func(i int) {
+		atomic.AddInt32(&counts[i], 1)
+	})
+	return counts
+}
+
+func checkOnce(t *testing.T, counts []int32) {
+	t.Helper()
+	for i, count := range counts {
+		if count != 1 {
+			t.Fatalf("item %d visited %d times, want 1", i, count)
+		}
+	}
+}
+
+func TestMapVisitsEveryItemOnce(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		workers     int
+		parallelism int
+		n           int
+	}{
+		{"empty", 4, 4, 0},
+		{"below parallel threshold", 4, 4, minParallelItems - 1},
+		{"at parallel threshold", 4, 4, minParallelItems},
+		{"uneven chunks", 4, 3, 1001},
+		{"more chunks than workers", 2, 16, 500},
+		{"no parallelism", 4, 1, 500},
+		{"zero sizes clamped", 0, 0, 100},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := New(tc.workers, tc.parallelism)
+			defer p.Stop()
+			checkOnce(t, mapCounts(p, tc.n))
+		})
+	}
+}
+
+func TestMapNilPoolRunsInline(t *testing.T) {
+	var p *Pool
+	checkOnce(t, mapCounts(p, 100))
+}
+
+func TestMapRunsInlineWhenSaturated(t *testing.T) {
+	p := New(1, 4)
+	defer p.Stop()
+
+	// Occupy the only worker until the test is done
+	release := make(chan struct{})
+	p.tasks <- func() { <-release }
+	defer close(release)
+
+	done := make(chan []int32)
+	go func() { done <- mapCounts(p, 1000) }()
+	select {
+	case counts := <-done:
+		checkOnce(t, counts)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Map waited for a busy worker instead of running inline")
+	}
+}
+
+func TestMapNestedDoesNotDeadlock(t *testing.T) {
+	p := New(2, 4)
+	defer p.Stop()
+
+	var visited atomic.Int64
+	done := make(chan struct{})
+	go func() {
+		p.Map(64, func(int) {
+			p.Map(64, func(int) { visited.Add(1) })
+		})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("nested Map deadlocked")
+	}
+	if got := visited.Load(); got != 64*64 {
+		t.Errorf("visited %d items, want %d", got, 64*64)
+	}
+}
+
+func TestStop(t *testing.T) {
+	p := New(4, 4)
+	p.Stop()
+	p.Stop() // a second Stop is a no-op
+
+	// Workers exit, so nothing takes a task any more
+	deadline := time.Now().Add(time.Second)
+	for {
+		select {
+		case p.tasks <- func() {}:
+			if time.Now().After(deadline) {
+				t.Fatal("a worker still takes tasks after Stop")
+			}
+			time.Sleep(time.Millisecond)
+			continue
+		case <-time.After(20 * time.Millisecond):
+		}
+		break
+	}
+
+	// Map still visits every item, on the calling goroutine
+	checkOnce(t, mapCounts(p, 1000))
+}
+
+func BenchmarkMap(b *testing.B) {
+	const items = 10000
+	for _, bc := range []struct {
+		name        string
+		parallelism int
+	}{
+		{"inline", 1},
+		{"parallel", 8},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			p := New(8, bc.parallelism)
+			defer p.Stop()
+			out := make([]int, items)
+
+			b.ResetTimer()
+			for range b.N {
+				p.Map(items, func(i int) { out[i] = i * i })
+			}
+		})
+	}
+}
+
+// BenchmarkMapSaturated runs many concurrent callers against a small
+// pool, so most chunks run inline on the callers
+func BenchmarkMapSaturated(b *testing.B) {
+	const items = 1000
+	p := New(4, 8)
+	defer p.Stop()
+
+	b.RunParallel(func(pb *testing.PB) {
+		out := make([]int, items)
+		for pb.Next() {
+			p.Map(items, func(i int) { out[i] = i * i })
+		}
+	})
+}